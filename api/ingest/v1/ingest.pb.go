@@ -0,0 +1,630 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: ingest/v1/ingest.proto
+
+// The SCRIBE gRPC ingestion service, served by `scribe serve` when built with
+// -tags grpc and server.grpc_port is set. Logs take the same path as
+// POST /api/logs: pattern matching, body normalization and redaction, the
+// severity floor and the per-source rate limit all apply.
+
+package ingestv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// LogHeader is the "header" object of POST /api/logs.
+type LogHeader struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Severity      string                 `protobuf:"bytes,2,opt,name=severity,proto3" json:"severity,omitempty"`
+	Source        string                 `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Color         string                 `protobuf:"bytes,4,opt,name=color,proto3" json:"color,omitempty"`
+	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogHeader) Reset() {
+	*x = LogHeader{}
+	mi := &file_ingest_v1_ingest_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogHeader) ProtoMessage() {}
+
+func (x *LogHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_v1_ingest_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogHeader.ProtoReflect.Descriptor instead.
+func (*LogHeader) Descriptor() ([]byte, []int) {
+	return file_ingest_v1_ingest_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LogHeader) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *LogHeader) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *LogHeader) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *LogHeader) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *LogHeader) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+// Log is the request body of POST /api/logs.
+type Log struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Header *LogHeader             `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	// body is the free-form "body" object.
+	Body *structpb.Struct `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+	// timestamp is the original event time; it defaults to the time of
+	// receipt.
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// expires_in overrides the retention window, e.g. "12h" or "365d".
+	ExpiresIn     string `protobuf:"bytes,4,opt,name=expires_in,json=expiresIn,proto3" json:"expires_in,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Log) Reset() {
+	*x = Log{}
+	mi := &file_ingest_v1_ingest_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Log) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Log) ProtoMessage() {}
+
+func (x *Log) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_v1_ingest_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Log.ProtoReflect.Descriptor instead.
+func (*Log) Descriptor() ([]byte, []int) {
+	return file_ingest_v1_ingest_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Log) GetHeader() *LogHeader {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *Log) GetBody() *structpb.Struct {
+	if x != nil {
+		return x.Body
+	}
+	return nil
+}
+
+func (x *Log) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Log) GetExpiresIn() string {
+	if x != nil {
+		return x.ExpiresIn
+	}
+	return ""
+}
+
+type CreateLogRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Log   *Log                   `protobuf:"bytes,1,opt,name=log,proto3" json:"log,omitempty"`
+	// analyze overrides logging.auto_analyze, like ?analyze= on the HTTP API.
+	Analyze       *bool `protobuf:"varint,2,opt,name=analyze,proto3,oneof" json:"analyze,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateLogRequest) Reset() {
+	*x = CreateLogRequest{}
+	mi := &file_ingest_v1_ingest_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLogRequest) ProtoMessage() {}
+
+func (x *CreateLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_v1_ingest_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLogRequest.ProtoReflect.Descriptor instead.
+func (*CreateLogRequest) Descriptor() ([]byte, []int) {
+	return file_ingest_v1_ingest_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateLogRequest) GetLog() *Log {
+	if x != nil {
+		return x.Log
+	}
+	return nil
+}
+
+func (x *CreateLogRequest) GetAnalyze() bool {
+	if x != nil && x.Analyze != nil {
+		return *x.Analyze
+	}
+	return false
+}
+
+type CreateLogResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title     string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Severity  string                 `protobuf:"bytes,3,opt,name=severity,proto3" json:"severity,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// dropped is set when the log was dropped by the severity floor or rate
+	// limit; no other field is set then.
+	Dropped       bool `protobuf:"varint,5,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateLogResponse) Reset() {
+	*x = CreateLogResponse{}
+	mi := &file_ingest_v1_ingest_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLogResponse) ProtoMessage() {}
+
+func (x *CreateLogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_v1_ingest_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLogResponse.ProtoReflect.Descriptor instead.
+func (*CreateLogResponse) Descriptor() ([]byte, []int) {
+	return file_ingest_v1_ingest_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateLogResponse) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *CreateLogResponse) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateLogResponse) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *CreateLogResponse) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *CreateLogResponse) GetDropped() bool {
+	if x != nil {
+		return x.Dropped
+	}
+	return false
+}
+
+type CreateLogBatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// logs holds at most 1000 logs.
+	Logs          []*Log `protobuf:"bytes,1,rep,name=logs,proto3" json:"logs,omitempty"`
+	Analyze       *bool  `protobuf:"varint,2,opt,name=analyze,proto3,oneof" json:"analyze,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateLogBatchRequest) Reset() {
+	*x = CreateLogBatchRequest{}
+	mi := &file_ingest_v1_ingest_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLogBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLogBatchRequest) ProtoMessage() {}
+
+func (x *CreateLogBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_v1_ingest_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLogBatchRequest.ProtoReflect.Descriptor instead.
+func (*CreateLogBatchRequest) Descriptor() ([]byte, []int) {
+	return file_ingest_v1_ingest_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateLogBatchRequest) GetLogs() []*Log {
+	if x != nil {
+		return x.Logs
+	}
+	return nil
+}
+
+func (x *CreateLogBatchRequest) GetAnalyze() bool {
+	if x != nil && x.Analyze != nil {
+		return *x.Analyze
+	}
+	return false
+}
+
+type CreateLogBatchResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Received int32                  `protobuf:"varint,1,opt,name=received,proto3" json:"received,omitempty"`
+	Created  int32                  `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	Dropped  int32                  `protobuf:"varint,3,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	Failed   int32                  `protobuf:"varint,4,opt,name=failed,proto3" json:"failed,omitempty"`
+	// ids are the IDs of the created logs, in batch order.
+	Ids []int64 `protobuf:"varint,5,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	// errors describes the failed logs, at most 100 of them.
+	Errors        []*BatchItemError `protobuf:"bytes,6,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateLogBatchResponse) Reset() {
+	*x = CreateLogBatchResponse{}
+	mi := &file_ingest_v1_ingest_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLogBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLogBatchResponse) ProtoMessage() {}
+
+func (x *CreateLogBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_v1_ingest_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLogBatchResponse.ProtoReflect.Descriptor instead.
+func (*CreateLogBatchResponse) Descriptor() ([]byte, []int) {
+	return file_ingest_v1_ingest_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateLogBatchResponse) GetReceived() int32 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *CreateLogBatchResponse) GetCreated() int32 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+func (x *CreateLogBatchResponse) GetDropped() int32 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+func (x *CreateLogBatchResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *CreateLogBatchResponse) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *CreateLogBatchResponse) GetErrors() []*BatchItemError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+// BatchItemError describes a log of a batch that could not be created.
+type BatchItemError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// index is the log's zero-based position in the batch.
+	Index int32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// code is the API error code, e.g. "invalid_request" or "rate_limited".
+	Code          string `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchItemError) Reset() {
+	*x = BatchItemError{}
+	mi := &file_ingest_v1_ingest_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchItemError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchItemError) ProtoMessage() {}
+
+func (x *BatchItemError) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_v1_ingest_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchItemError.ProtoReflect.Descriptor instead.
+func (*BatchItemError) Descriptor() ([]byte, []int) {
+	return file_ingest_v1_ingest_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchItemError) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BatchItemError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *BatchItemError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+var File_ingest_v1_ingest_proto protoreflect.FileDescriptor
+
+const file_ingest_v1_ingest_proto_rawDesc = "" +
+	"\n" +
+	"\x16ingest/v1/ingest.proto\x12\x10scribe.ingest.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8d\x01\n" +
+	"\tLogHeader\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x1a\n" +
+	"\bseverity\x18\x02 \x01(\tR\bseverity\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12\x14\n" +
+	"\x05color\x18\x04 \x01(\tR\x05color\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\"\xc0\x01\n" +
+	"\x03Log\x123\n" +
+	"\x06header\x18\x01 \x01(\v2\x1b.scribe.ingest.v1.LogHeaderR\x06header\x12+\n" +
+	"\x04body\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x04body\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x1d\n" +
+	"\n" +
+	"expires_in\x18\x04 \x01(\tR\texpiresIn\"f\n" +
+	"\x10CreateLogRequest\x12'\n" +
+	"\x03log\x18\x01 \x01(\v2\x15.scribe.ingest.v1.LogR\x03log\x12\x1d\n" +
+	"\aanalyze\x18\x02 \x01(\bH\x00R\aanalyze\x88\x01\x01B\n" +
+	"\n" +
+	"\b_analyze\"\xaa\x01\n" +
+	"\x11CreateLogResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x1a\n" +
+	"\bseverity\x18\x03 \x01(\tR\bseverity\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x18\n" +
+	"\adropped\x18\x05 \x01(\bR\adropped\"m\n" +
+	"\x15CreateLogBatchRequest\x12)\n" +
+	"\x04logs\x18\x01 \x03(\v2\x15.scribe.ingest.v1.LogR\x04logs\x12\x1d\n" +
+	"\aanalyze\x18\x02 \x01(\bH\x00R\aanalyze\x88\x01\x01B\n" +
+	"\n" +
+	"\b_analyze\"\xcc\x01\n" +
+	"\x16CreateLogBatchResponse\x12\x1a\n" +
+	"\breceived\x18\x01 \x01(\x05R\breceived\x12\x18\n" +
+	"\acreated\x18\x02 \x01(\x05R\acreated\x12\x18\n" +
+	"\adropped\x18\x03 \x01(\x05R\adropped\x12\x16\n" +
+	"\x06failed\x18\x04 \x01(\x05R\x06failed\x12\x10\n" +
+	"\x03ids\x18\x05 \x03(\x03R\x03ids\x128\n" +
+	"\x06errors\x18\x06 \x03(\v2 .scribe.ingest.v1.BatchItemErrorR\x06errors\"T\n" +
+	"\x0eBatchItemError\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage2\xca\x01\n" +
+	"\rIngestService\x12T\n" +
+	"\tCreateLog\x12\".scribe.ingest.v1.CreateLogRequest\x1a#.scribe.ingest.v1.CreateLogResponse\x12c\n" +
+	"\x0eCreateLogBatch\x12'.scribe.ingest.v1.CreateLogBatchRequest\x1a(.scribe.ingest.v1.CreateLogBatchResponseB4Z2github.com/mx-scribe/scribe/api/ingest/v1;ingestv1b\x06proto3"
+
+var (
+	file_ingest_v1_ingest_proto_rawDescOnce sync.Once
+	file_ingest_v1_ingest_proto_rawDescData []byte
+)
+
+func file_ingest_v1_ingest_proto_rawDescGZIP() []byte {
+	file_ingest_v1_ingest_proto_rawDescOnce.Do(func() {
+		file_ingest_v1_ingest_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ingest_v1_ingest_proto_rawDesc), len(file_ingest_v1_ingest_proto_rawDesc)))
+	})
+	return file_ingest_v1_ingest_proto_rawDescData
+}
+
+var file_ingest_v1_ingest_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_ingest_v1_ingest_proto_goTypes = []any{
+	(*LogHeader)(nil),              // 0: scribe.ingest.v1.LogHeader
+	(*Log)(nil),                    // 1: scribe.ingest.v1.Log
+	(*CreateLogRequest)(nil),       // 2: scribe.ingest.v1.CreateLogRequest
+	(*CreateLogResponse)(nil),      // 3: scribe.ingest.v1.CreateLogResponse
+	(*CreateLogBatchRequest)(nil),  // 4: scribe.ingest.v1.CreateLogBatchRequest
+	(*CreateLogBatchResponse)(nil), // 5: scribe.ingest.v1.CreateLogBatchResponse
+	(*BatchItemError)(nil),         // 6: scribe.ingest.v1.BatchItemError
+	(*structpb.Struct)(nil),        // 7: google.protobuf.Struct
+	(*timestamppb.Timestamp)(nil),  // 8: google.protobuf.Timestamp
+}
+var file_ingest_v1_ingest_proto_depIdxs = []int32{
+	0, // 0: scribe.ingest.v1.Log.header:type_name -> scribe.ingest.v1.LogHeader
+	7, // 1: scribe.ingest.v1.Log.body:type_name -> google.protobuf.Struct
+	8, // 2: scribe.ingest.v1.Log.timestamp:type_name -> google.protobuf.Timestamp
+	1, // 3: scribe.ingest.v1.CreateLogRequest.log:type_name -> scribe.ingest.v1.Log
+	8, // 4: scribe.ingest.v1.CreateLogResponse.created_at:type_name -> google.protobuf.Timestamp
+	1, // 5: scribe.ingest.v1.CreateLogBatchRequest.logs:type_name -> scribe.ingest.v1.Log
+	6, // 6: scribe.ingest.v1.CreateLogBatchResponse.errors:type_name -> scribe.ingest.v1.BatchItemError
+	2, // 7: scribe.ingest.v1.IngestService.CreateLog:input_type -> scribe.ingest.v1.CreateLogRequest
+	4, // 8: scribe.ingest.v1.IngestService.CreateLogBatch:input_type -> scribe.ingest.v1.CreateLogBatchRequest
+	3, // 9: scribe.ingest.v1.IngestService.CreateLog:output_type -> scribe.ingest.v1.CreateLogResponse
+	5, // 10: scribe.ingest.v1.IngestService.CreateLogBatch:output_type -> scribe.ingest.v1.CreateLogBatchResponse
+	9, // [9:11] is the sub-list for method output_type
+	7, // [7:9] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_ingest_v1_ingest_proto_init() }
+func file_ingest_v1_ingest_proto_init() {
+	if File_ingest_v1_ingest_proto != nil {
+		return
+	}
+	file_ingest_v1_ingest_proto_msgTypes[2].OneofWrappers = []any{}
+	file_ingest_v1_ingest_proto_msgTypes[4].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ingest_v1_ingest_proto_rawDesc), len(file_ingest_v1_ingest_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ingest_v1_ingest_proto_goTypes,
+		DependencyIndexes: file_ingest_v1_ingest_proto_depIdxs,
+		MessageInfos:      file_ingest_v1_ingest_proto_msgTypes,
+	}.Build()
+	File_ingest_v1_ingest_proto = out.File
+	file_ingest_v1_ingest_proto_goTypes = nil
+	file_ingest_v1_ingest_proto_depIdxs = nil
+}
@@ -22,7 +22,8 @@ func NewCleanupLogsHandler(logRepo *sqlite.LogRepository) *CleanupLogsHandler {
 
 // CleanupLogsRequest represents the input for cleanup operation.
 type CleanupLogsRequest struct {
-	RetentionDays int `json:"retention_days"`
+	RetentionDays int  `json:"retention_days"`
+	DryRun        bool `json:"dry_run,omitempty"`
 }
 
 // CleanupLogsResponse represents the output of cleanup operation.
@@ -30,6 +31,7 @@ type CleanupLogsResponse struct {
 	DeletedCount int       `json:"deleted_count"`
 	CutoffDate   time.Time `json:"cutoff_date"`
 	Message      string    `json:"message"`
+	DryRun       bool      `json:"dry_run"`
 }
 
 // Handle performs the log cleanup operation.
@@ -42,8 +44,12 @@ func (h *CleanupLogsHandler) Handle(ctx context.Context, request CleanupLogsRequ
 	// Calculate cutoff date
 	cutoffDate := time.Now().AddDate(0, 0, -request.RetentionDays)
 
+	if request.DryRun {
+		return h.preview(ctx, request.RetentionDays, cutoffDate)
+	}
+
 	// Delete old logs
-	deletedCount, err := h.logRepo.DeleteOlderThan(cutoffDate)
+	deletedCount, err := h.logRepo.DeleteOlderThanContext(ctx, cutoffDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete old logs: %w", err)
 	}
@@ -62,3 +68,23 @@ func (h *CleanupLogsHandler) Handle(ctx context.Context, request CleanupLogsRequ
 
 	return response, nil
 }
+
+// preview counts the logs a cleanup would delete without deleting anything.
+func (h *CleanupLogsHandler) preview(ctx context.Context, retentionDays int, cutoffDate time.Time) (*CleanupLogsResponse, error) {
+	count, err := h.logRepo.CountDeletableContext(ctx, cutoffDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count old logs: %w", err)
+	}
+
+	message := fmt.Sprintf("Dry run: would clean up %d logs older than %d days", count, retentionDays)
+	if count == 0 {
+		message = fmt.Sprintf("Dry run: no logs older than %d days to clean up", retentionDays)
+	}
+
+	return &CleanupLogsResponse{
+		DeletedCount: count,
+		CutoffDate:   cutoffDate,
+		Message:      message,
+		DryRun:       true,
+	}, nil
+}
@@ -185,6 +185,117 @@ func TestCleanupLogsHandler_Handle_InvalidRetentionDays(t *testing.T) {
 	}
 }
 
+func TestCleanupLogsHandler_Handle_DryRun(t *testing.T) {
+	handler, repo, db := setupCleanupTest(t)
+	defer db.Close()
+
+	now := time.Now()
+
+	for i := 0; i < 4; i++ {
+		if err := createLogWithTimestamp(repo, now.AddDate(0, 0, -40)); err != nil {
+			t.Fatalf("Failed to create old log: %v", err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := createLogWithTimestamp(repo, now.AddDate(0, 0, -10)); err != nil {
+			t.Fatalf("Failed to create recent log: %v", err)
+		}
+	}
+
+	preview, err := handler.Handle(context.Background(), CleanupLogsRequest{
+		RetentionDays: 30,
+		DryRun:        true,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if !preview.DryRun {
+		t.Error("Expected DryRun to be set on response")
+	}
+	if preview.DeletedCount != 4 {
+		t.Errorf("Expected 4 logs reported, got %d", preview.DeletedCount)
+	}
+
+	expectedMessage := "Dry run: would clean up 4 logs older than 30 days"
+	if preview.Message != expectedMessage {
+		t.Errorf("Expected message '%s', got '%s'", expectedMessage, preview.Message)
+	}
+
+	// Nothing should have been deleted
+	count, err := repo.Count()
+	if err != nil {
+		t.Fatalf("Failed to count logs: %v", err)
+	}
+	if count != 6 {
+		t.Errorf("Expected 6 logs after dry run, got %d", count)
+	}
+
+	// A real cleanup deletes exactly what the preview reported
+	response, err := handler.Handle(context.Background(), CleanupLogsRequest{RetentionDays: 30})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if response.DeletedCount != preview.DeletedCount {
+		t.Errorf("Expected %d deleted logs, got %d", preview.DeletedCount, response.DeletedCount)
+	}
+}
+
+func TestCleanupLogsHandler_Handle_ExpiresAt(t *testing.T) {
+	handler, repo, db := setupCleanupTest(t)
+	defer db.Close()
+
+	now := time.Now()
+	create := func(title string, createdAt time.Time, expiresAt *time.Time) {
+		t.Helper()
+		log := entities.NewLog(entities.LogHeader{Title: title}, nil)
+		log.CreatedAt = createdAt
+		log.ExpiresAt = expiresAt
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("Failed to create log: %v", err)
+		}
+	}
+
+	expired := now.Add(-time.Hour)
+	farFuture := now.AddDate(1, 0, 0)
+	create("short ttl", now.AddDate(0, 0, -1), &expired)
+	create("long ttl", now.AddDate(0, 0, -40), &farFuture)
+	create("old", now.AddDate(0, 0, -40), nil)
+	create("recent", now.AddDate(0, 0, -10), nil)
+
+	preview, err := handler.Handle(context.Background(), CleanupLogsRequest{RetentionDays: 30, DryRun: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if preview.DeletedCount != 2 {
+		t.Errorf("Expected 2 logs reported, got %d", preview.DeletedCount)
+	}
+
+	response, err := handler.Handle(context.Background(), CleanupLogsRequest{RetentionDays: 30})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if response.DeletedCount != 2 {
+		t.Errorf("Expected 2 deleted logs, got %d", response.DeletedCount)
+	}
+
+	// The expired log goes before the window, the long-lived one outlives it
+	logs, _, err := repo.FindAll(sqlite.LogFilters{})
+	if err != nil {
+		t.Fatalf("Failed to list logs: %v", err)
+	}
+	var titles []string
+	for _, log := range logs {
+		titles = append(titles, log.Header.Title)
+	}
+	if len(titles) != 2 || titles[0] != "recent" || titles[1] != "long ttl" {
+		t.Errorf("Expected recent and long ttl logs to remain, got %v", titles)
+	}
+	if logs[1].ExpiresAt == nil || !logs[1].ExpiresAt.Equal(farFuture) {
+		t.Errorf("Expected expires_at %v to round-trip, got %v", farFuture, logs[1].ExpiresAt)
+	}
+}
+
 func TestCleanupLogsHandler_Handle_EmptyDatabase(t *testing.T) {
 	handler, _, db := setupCleanupTest(t)
 	defer db.Close()
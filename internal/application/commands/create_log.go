@@ -1,6 +1,9 @@
 package commands
 
 import (
+	"context"
+	"time"
+
 	"github.com/mx-scribe/scribe/internal/domain/entities"
 	"github.com/mx-scribe/scribe/internal/domain/services"
 	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
@@ -14,6 +17,33 @@ type CreateLogInput struct {
 	Color       string         `json:"color,omitempty"`
 	Description string         `json:"description,omitempty"`
 	Body        map[string]any `json:"body,omitempty"`
+
+	// Timestamp is the event time for logs imported or forwarded with their
+	// original time. Nil uses the ingestion time.
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+
+	// ExpiresIn, when positive, makes retention delete the log this long
+	// after it is received, whatever the global retention window.
+	ExpiresIn time.Duration `json:"-"`
+
+	// SkipAnalysis stores the log as given, without running the pattern
+	// matcher, leaving derived severity, source and category empty.
+	SkipAnalysis bool `json:"-"`
+
+	// Normalizer, when set, copies aliased body fields to their canonical
+	// keys before the log is validated and analyzed.
+	Normalizer *services.BodyNormalizer `json:"-"`
+
+	// Redactor, when set, masks secrets in the body after normalization and
+	// before analysis, so they never reach storage or derived fields.
+	Redactor *services.BodyRedactor `json:"-"`
+
+	// Matcher derives metadata for the log. Nil uses the built-in rules.
+	Matcher *services.PatternMatcher `json:"-"`
+
+	// Colorizer, when set, derives a color from the effective severity for
+	// logs sent without one. Explicit colors are kept.
+	Colorizer *services.SeverityColorizer `json:"-"`
 }
 
 // CreateLogOutput represents the output after creating a log.
@@ -26,7 +56,7 @@ type CreateLogOutput struct {
 
 // LogRepository defines the interface for log persistence.
 type LogRepository interface {
-	Create(log *entities.Log) error
+	CreateContext(ctx context.Context, log *entities.Log) error
 }
 
 // CreateLogHandler handles the create log command.
@@ -41,6 +71,46 @@ func NewCreateLogHandler(repo LogRepository) *CreateLogHandler {
 
 // Handle executes the create log command.
 func (h *CreateLogHandler) Handle(input CreateLogInput) (*CreateLogOutput, error) {
+	log, err := h.Build(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.Save(log)
+}
+
+// Save persists a log returned by Build.
+func (h *CreateLogHandler) Save(log *entities.Log) (*CreateLogOutput, error) {
+	return h.SaveContext(context.Background(), log)
+}
+
+// SaveContext persists a log returned by Build, honoring ctx cancellation
+// and its tenant.
+func (h *CreateLogHandler) SaveContext(ctx context.Context, log *entities.Log) (*CreateLogOutput, error) {
+	if err := h.repo.CreateContext(ctx, log); err != nil {
+		return nil, err
+	}
+
+	// Return output
+	return &CreateLogOutput{
+		ID:        log.ID,
+		Title:     log.Header.Title,
+		Severity:  log.EffectiveSeverity().String(),
+		CreatedAt: log.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}, nil
+}
+
+// Build validates the input and returns a log entity with derived metadata applied,
+// without persisting it. Used by callers that batch inserts themselves.
+func (h *CreateLogHandler) Build(input CreateLogInput) (*entities.Log, error) {
+	log, _, err := h.BuildAnalyzed(input)
+	return log, err
+}
+
+// BuildAnalyzed is Build returning the metadata the pattern matcher derived
+// as well, including a severity it suggested that the supplied severity
+// took precedence over. The analysis is empty with SkipAnalysis.
+func (h *CreateLogHandler) BuildAnalyzed(input CreateLogInput) (*entities.Log, entities.LogMetadata, error) {
 	// Build header
 	header := entities.LogHeader{
 		Title:       input.Title,
@@ -55,17 +125,54 @@ func (h *CreateLogHandler) Handle(input CreateLogInput) (*CreateLogOutput, error
 	if body == nil {
 		body = make(map[string]any)
 	}
+	if input.Normalizer != nil {
+		input.Normalizer.Normalize(body)
+	}
+	if input.Redactor != nil {
+		input.Redactor.Redact(body)
+	}
 
 	// Create log entity
 	log := entities.NewLog(header, body)
+	if input.Timestamp != nil && !input.Timestamp.IsZero() {
+		// Stored timestamps compare as text, so they must share one zone
+		log.CreatedAt = input.Timestamp.UTC()
+	}
+	if input.ExpiresIn > 0 {
+		expiresAt := log.IngestedAt.Add(input.ExpiresIn)
+		log.ExpiresAt = &expiresAt
+	}
 
 	// Validate
 	if err := log.Validate(); err != nil {
-		return nil, err
+		return nil, entities.LogMetadata{}, err
 	}
 
-	// Run pattern matching to derive metadata
-	matcher := services.NewPatternMatcher()
+	var analysis entities.LogMetadata
+	if !input.SkipAnalysis {
+		analysis = analyze(log, input.Matcher)
+	}
+
+	colorize(log, input.Colorizer)
+
+	return log, analysis, nil
+}
+
+// colorize sets the derived color of a log without an explicit color from
+// its effective severity. A nil colorizer leaves the log unchanged.
+func colorize(log *entities.Log, colorizer *services.SeverityColorizer) {
+	if colorizer != nil && log.Header.Color == "" {
+		log.Metadata.DerivedColor = colorizer.Color(log.EffectiveSeverity()).String()
+	}
+}
+
+// analyze runs matcher, or the built-in rules when nil, on log and applies
+// the derived metadata the header does not already set. It returns the
+// metadata matcher derived.
+func analyze(log *entities.Log, matcher *services.PatternMatcher) entities.LogMetadata {
+	if matcher == nil {
+		matcher = services.NewPatternMatcher()
+	}
 	metadata := matcher.AnalyzeLog(log)
 
 	// Apply derived metadata only if not already set
@@ -80,17 +187,5 @@ func (h *CreateLogHandler) Handle(input CreateLogInput) (*CreateLogOutput, error
 	if metadata.DerivedCategory != "" {
 		log.Metadata.DerivedCategory = metadata.DerivedCategory
 	}
-
-	// Persist
-	if err := h.repo.Create(log); err != nil {
-		return nil, err
-	}
-
-	// Return output
-	return &CreateLogOutput{
-		ID:        log.ID,
-		Title:     log.Header.Title,
-		Severity:  log.EffectiveSeverity().String(),
-		CreatedAt: log.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	}, nil
+	return metadata
 }
@@ -1,9 +1,11 @@
 package commands
 
 import (
+	"context"
 	"testing"
 
 	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/domain/services"
 )
 
 // mockLogRepository implements LogRepository for testing.
@@ -20,7 +22,7 @@ func newMockLogRepository() *mockLogRepository {
 	}
 }
 
-func (m *mockLogRepository) Create(log *entities.Log) error {
+func (m *mockLogRepository) CreateContext(_ context.Context, log *entities.Log) error {
 	log.ID = m.nextID
 	m.nextID++
 	m.logs = append(m.logs, log)
@@ -125,3 +127,127 @@ func TestCreateLogHandler_Handle_WithColor(t *testing.T) {
 		t.Errorf("expected color 'blue', got %q", repo.lastLog.Header.Color.String())
 	}
 }
+
+func TestCreateLogHandler_Handle_SkipAnalysis(t *testing.T) {
+	repo := newMockLogRepository()
+	handler := NewCreateLogHandler(repo)
+
+	input := CreateLogInput{
+		Title: "[payments] POST /charge returned HTTP 500",
+		Body:  map[string]any{"service": "payments"},
+	}
+
+	if _, err := handler.Handle(input); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+	analyzed := repo.lastLog.Metadata
+	if analyzed.DerivedSeverity == "" || analyzed.DerivedSource == "" || analyzed.DerivedCategory == "" {
+		t.Errorf("expected derived metadata with analysis enabled, got %+v", analyzed)
+	}
+
+	input.SkipAnalysis = true
+	if _, err := handler.Handle(input); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+	if skipped := repo.lastLog.Metadata; skipped != (entities.LogMetadata{}) {
+		t.Errorf("expected empty derived metadata with analysis skipped, got %+v", skipped)
+	}
+}
+
+func TestCreateLogHandler_BuildAnalyzed(t *testing.T) {
+	handler := NewCreateLogHandler(nil)
+
+	// The analysis keeps the derived severity the supplied one wins over
+	log, analysis, err := handler.BuildAnalyzed(CreateLogInput{Title: "Deadlock detected", Severity: "warning"})
+	if err != nil {
+		t.Fatalf("failed to build log: %v", err)
+	}
+	if analysis.DerivedSeverity != "critical" {
+		t.Errorf("expected derived severity critical in the analysis, got %+v", analysis)
+	}
+	if log.Metadata.DerivedSeverity != "" || log.EffectiveSeverity() != "warning" {
+		t.Errorf("expected the supplied severity to stay effective, got %+v", log.Metadata)
+	}
+
+	_, analysis, err = handler.BuildAnalyzed(CreateLogInput{Title: "Deadlock detected", SkipAnalysis: true})
+	if err != nil {
+		t.Fatalf("failed to build log: %v", err)
+	}
+	if analysis != (entities.LogMetadata{}) {
+		t.Errorf("expected an empty analysis with analysis skipped, got %+v", analysis)
+	}
+}
+
+func TestCreateLogHandler_Handle_ColorFromSeverity(t *testing.T) {
+	repo := newMockLogRepository()
+	handler := NewCreateLogHandler(repo)
+	colorizer, err := services.NewSeverityColorizer(map[string]string{"critical": "rose"})
+	if err != nil {
+		t.Fatalf("NewSeverityColorizer failed: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		input       CreateLogInput
+		wantDerived string
+		wantColor   string
+	}{
+		{"severity given", CreateLogInput{Title: "Disk full", Severity: "critical", SkipAnalysis: true}, "rose", "rose"},
+		{"severity derived", CreateLogInput{Title: "POST /charge returned HTTP 500"}, "red", "red"},
+		{"default severity", CreateLogInput{Title: "Nightly job", SkipAnalysis: true}, "blue", "blue"},
+		{"explicit color wins", CreateLogInput{Title: "Disk full", Severity: "critical", Color: "violet"}, "", "violet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input
+			input.Colorizer = colorizer
+			if _, err := handler.Handle(input); err != nil {
+				t.Fatalf("failed to create log: %v", err)
+			}
+			if got := repo.lastLog.Metadata.DerivedColor; got != tt.wantDerived {
+				t.Errorf("expected derived color %q, got %q", tt.wantDerived, got)
+			}
+			if got := repo.lastLog.EffectiveColor().String(); got != tt.wantColor {
+				t.Errorf("expected effective color %q, got %q", tt.wantColor, got)
+			}
+		})
+	}
+
+	// Without a colorizer nothing is derived
+	if _, err := handler.Handle(CreateLogInput{Title: "Disk full", Severity: "critical"}); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+	if got := repo.lastLog.Metadata.DerivedColor; got != "" {
+		t.Errorf("expected no derived color without a colorizer, got %q", got)
+	}
+}
+
+func BenchmarkCreateLogHandler_Handle(b *testing.B) {
+	input := CreateLogInput{
+		Title:       "[checkout] Payment gateway timeout after 30s",
+		Severity:    "warning",
+		Source:      "checkout",
+		Description: "POST /api/orders returned HTTP 504",
+		Body: map[string]any{
+			"order_id": "ord_123",
+			"duration": 30123,
+		},
+	}
+
+	for _, skip := range []bool{false, true} {
+		name := "analyze"
+		if skip {
+			name = "skip_analysis"
+		}
+		b.Run(name, func(b *testing.B) {
+			handler := NewCreateLogHandler(newMockLogRepository())
+			input.SkipAnalysis = skip
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := handler.Handle(input); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
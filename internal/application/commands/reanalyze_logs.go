@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/domain/services"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// ReanalyzeBatchSize is the number of logs scanned between progress reports,
+// and the most changed logs written per transaction.
+const ReanalyzeBatchSize = 500
+
+// ReanalyzeLogsHandler re-runs pattern matching over stored logs, so rule
+// changes apply to logs ingested before them.
+type ReanalyzeLogsHandler struct {
+	logRepo *sqlite.LogRepository
+}
+
+// NewReanalyzeLogsHandler creates a new ReanalyzeLogsHandler.
+func NewReanalyzeLogsHandler(logRepo *sqlite.LogRepository) *ReanalyzeLogsHandler {
+	return &ReanalyzeLogsHandler{
+		logRepo: logRepo,
+	}
+}
+
+// ReanalyzeLogsRequest represents the input for a reanalysis.
+type ReanalyzeLogsRequest struct {
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// Matcher derives the new metadata. Nil uses the built-in rules.
+	Matcher *services.PatternMatcher `json:"-"`
+
+	// Colorizer, when set, rederives the color of logs without an explicit
+	// one. Nil keeps their derived color.
+	Colorizer *services.SeverityColorizer `json:"-"`
+}
+
+// ReanalyzeProgress reports how far a reanalysis has got. Changed counts
+// the logs whose derived metadata differs from the stored one; in a dry
+// run nothing is written.
+type ReanalyzeProgress struct {
+	Scanned int `json:"scanned"`
+	Changed int `json:"changed"`
+	Total   int `json:"total"`
+}
+
+// ReanalyzeLogsResponse represents the output of a reanalysis.
+type ReanalyzeLogsResponse struct {
+	ReanalyzeProgress
+	DryRun bool `json:"dry_run"`
+}
+
+// Handle re-runs pattern matching on every log that exists when it starts,
+// newest first, writing changed metadata in batches. It calls onProgress
+// after each ReanalyzeBatchSize logs scanned and once at the end, and stops
+// when ctx is cancelled; batches already written are kept.
+func (h *ReanalyzeLogsHandler) Handle(ctx context.Context, request ReanalyzeLogsRequest, onProgress func(ReanalyzeProgress)) (*ReanalyzeLogsResponse, error) {
+	response := &ReanalyzeLogsResponse{DryRun: request.DryRun}
+	progress := &response.ReanalyzeProgress
+
+	// Pin the run to the logs that exist now, so the total holds
+	maxID, err := h.logRepo.MaxIDContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filters := sqlite.LogFilters{MaxID: maxID, Limit: 1}
+	if _, progress.Total, err = h.logRepo.FindIDsContext(ctx, filters); err != nil {
+		return nil, err
+	}
+	filters.Limit = 0
+
+	report := func() {
+		if onProgress != nil {
+			onProgress(*progress)
+		}
+	}
+
+	changed := make([]*entities.Log, 0, ReanalyzeBatchSize)
+	flush := func() error {
+		if len(changed) == 0 || request.DryRun {
+			changed = changed[:0]
+			return nil
+		}
+		if err := h.logRepo.UpdateDerivedMetadataContext(ctx, changed); err != nil {
+			return fmt.Errorf("failed to update derived metadata: %w", err)
+		}
+		changed = changed[:0]
+		return nil
+	}
+
+	_, err = h.logRepo.EachContext(ctx, filters, func(log *entities.Log) error {
+		if metadata := reanalyze(log, request.Matcher, request.Colorizer); metadata != log.Metadata {
+			log.Metadata = metadata
+			changed = append(changed, log)
+			progress.Changed++
+		}
+		progress.Scanned++
+
+		if progress.Scanned%ReanalyzeBatchSize == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			report()
+		}
+		return ctx.Err()
+	})
+	if err != nil {
+		return response, err
+	}
+	if err := flush(); err != nil {
+		return response, err
+	}
+	if progress.Scanned%ReanalyzeBatchSize != 0 || progress.Scanned == 0 {
+		report()
+	}
+
+	return response, nil
+}
+
+// reanalyze returns the derived metadata Build would give log today.
+func reanalyze(log *entities.Log, matcher *services.PatternMatcher, colorizer *services.SeverityColorizer) entities.LogMetadata {
+	fresh := *log
+	fresh.Metadata = entities.LogMetadata{}
+	if colorizer == nil {
+		fresh.Metadata.DerivedColor = log.Metadata.DerivedColor
+	}
+
+	analyze(&fresh, matcher)
+	colorize(&fresh, colorizer)
+	return fresh.Metadata
+}
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mx-scribe/scribe/internal/domain/services"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+func setupReanalyzeTest(t *testing.T, titles ...string) (*ReanalyzeLogsHandler, *sqlite.LogRepository, *sqlite.Database) {
+	t.Helper()
+
+	db, err := sqlite.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	if err := sqlite.RunMigrations(db.Conn()); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	repo := sqlite.NewLogRepository(db)
+	create := NewCreateLogHandler(repo)
+	for _, title := range titles {
+		log, err := create.Build(CreateLogInput{Title: title})
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	return NewReanalyzeLogsHandler(repo), repo, db
+}
+
+func strictNotFound(t *testing.T) *services.PatternMatcher {
+	t.Helper()
+	matcher, err := services.NewPatternMatcherWithStatusSeverity(map[string]string{"404": "error"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return matcher
+}
+
+func TestReanalyzeLogsHandler_Handle(t *testing.T) {
+	handler, repo, db := setupReanalyzeTest(t, "API returned 404 not found", "Request returned status 200")
+	defer db.Close()
+
+	var reports []ReanalyzeProgress
+	response, err := handler.Handle(context.Background(), ReanalyzeLogsRequest{Matcher: strictNotFound(t)}, func(p ReanalyzeProgress) {
+		reports = append(reports, p)
+	})
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	want := ReanalyzeProgress{Scanned: 2, Changed: 1, Total: 2}
+	if response.ReanalyzeProgress != want {
+		t.Errorf("expected %+v, got %+v", want, response.ReanalyzeProgress)
+	}
+	if len(reports) != 1 || reports[0] != want {
+		t.Errorf("expected a single final report, got %+v", reports)
+	}
+
+	logs, _, _ := repo.FindAll(sqlite.LogFilters{})
+	for _, log := range logs {
+		want := "success"
+		if log.Header.Title == "API returned 404 not found" {
+			want = "error"
+		}
+		if log.Metadata.DerivedSeverity != want {
+			t.Errorf("%q: expected derived severity %q, got %q", log.Header.Title, want, log.Metadata.DerivedSeverity)
+		}
+	}
+
+	// Running again finds nothing left to change
+	response, err = handler.Handle(context.Background(), ReanalyzeLogsRequest{Matcher: strictNotFound(t)}, nil)
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if response.Changed != 0 {
+		t.Errorf("expected a second run to change nothing, got %d", response.Changed)
+	}
+}
+
+func TestReanalyzeLogsHandler_Handle_DryRun(t *testing.T) {
+	handler, repo, db := setupReanalyzeTest(t, "API returned 404 not found")
+	defer db.Close()
+
+	response, err := handler.Handle(context.Background(), ReanalyzeLogsRequest{DryRun: true, Matcher: strictNotFound(t)}, nil)
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if !response.DryRun || response.Changed != 1 {
+		t.Errorf("expected a dry run reporting 1 change, got %+v", response)
+	}
+
+	logs, _, _ := repo.FindAll(sqlite.LogFilters{})
+	if logs[0].Metadata.DerivedSeverity != "warning" {
+		t.Errorf("expected dry run to keep derived severity warning, got %q", logs[0].Metadata.DerivedSeverity)
+	}
+}
+
+func TestReanalyzeLogsHandler_Handle_Batches(t *testing.T) {
+	titles := make([]string, 1200)
+	for i := range titles {
+		titles[i] = fmt.Sprintf("Call %d returned 404", i)
+	}
+	handler, repo, db := setupReanalyzeTest(t, titles...)
+	defer db.Close()
+
+	var scanned []int
+	response, err := handler.Handle(context.Background(), ReanalyzeLogsRequest{Matcher: strictNotFound(t)}, func(p ReanalyzeProgress) {
+		scanned = append(scanned, p.Scanned)
+	})
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if response.Changed != 1200 || response.Total != 1200 {
+		t.Errorf("expected 1200 changed of 1200, got %+v", response.ReanalyzeProgress)
+	}
+	if fmt.Sprint(scanned) != "[500 1000 1200]" {
+		t.Errorf("expected reports at 500, 1000 and 1200, got %v", scanned)
+	}
+
+	counts, _ := repo.CountBySeverity()
+	if count := counts["error"]; count != 1200 {
+		t.Errorf("expected 1200 error logs, got %d", count)
+	}
+}
+
+func TestReanalyzeLogsHandler_Handle_Cancelled(t *testing.T) {
+	titles := make([]string, 1200)
+	for i := range titles {
+		titles[i] = fmt.Sprintf("Call %d returned 404", i)
+	}
+	handler, repo, db := setupReanalyzeTest(t, titles...)
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := handler.Handle(ctx, ReanalyzeLogsRequest{Matcher: strictNotFound(t)}, func(ReanalyzeProgress) {
+		cancel()
+	})
+	if err == nil {
+		t.Fatal("expected an error after cancellation")
+	}
+
+	// The first batch was written before the run stopped
+	counts, _ := repo.CountBySeverity()
+	if count := counts["error"]; count != ReanalyzeBatchSize {
+		t.Errorf("expected %d error logs, got %d", ReanalyzeBatchSize, count)
+	}
+}
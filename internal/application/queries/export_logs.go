@@ -12,80 +12,137 @@ import (
 type ExportFormat string
 
 const (
-	ExportFormatCSV  ExportFormat = "csv"
-	ExportFormatJSON ExportFormat = "json"
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatJSON   ExportFormat = "json"
+	ExportFormatNDJSON ExportFormat = "ndjson"
+
+	// ExportFormatGob is a compact binary encoding of full log records for
+	// transfer between scribe instances; see export.NewLogDecoder.
+	ExportFormatGob ExportFormat = "gob"
 )
 
+// Validate checks that f is a supported export format.
+func (f ExportFormat) Validate() error {
+	switch f {
+	case ExportFormatCSV, ExportFormatJSON, ExportFormatNDJSON, ExportFormatGob:
+		return nil
+	}
+	return fmt.Errorf("invalid export format: %s (must be csv, json, ndjson or gob)", f)
+}
+
 // ExportLogsHandler handles export of logs in various formats.
 type ExportLogsHandler struct {
-	logRepo *sqlite.LogRepository
+	logRepo  *sqlite.LogRepository
+	pageSize PageSize
 }
 
 // NewExportLogsHandler creates a new ExportLogsHandler.
 func NewExportLogsHandler(logRepo *sqlite.LogRepository) *ExportLogsHandler {
 	return &ExportLogsHandler{
-		logRepo: logRepo,
+		logRepo:  logRepo,
+		pageSize: DefaultPagination().Export,
 	}
 }
 
+// SetPageSize overrides the default and maximum number of logs exported.
+func (h *ExportLogsHandler) SetPageSize(pageSize PageSize) {
+	h.pageSize = pageSize.normalize(DefaultPagination().Export)
+}
+
 // ExportLogsRequest represents the input for exporting logs.
 type ExportLogsRequest struct {
-	Format   ExportFormat `json:"format"`
-	Search   string       `json:"search,omitempty"`
-	Severity string       `json:"severity,omitempty"`
-	Source   string       `json:"source,omitempty"`
-	Color    string       `json:"color,omitempty"`
-	FromDate string       `json:"from_date,omitempty"`
-	ToDate   string       `json:"to_date,omitempty"`
-	Limit    int          `json:"limit,omitempty"`
+	Format      ExportFormat `json:"format"`
+	Search      string       `json:"search,omitempty"`
+	TitleSearch string       `json:"title_search,omitempty"`
+	BodySearch  string       `json:"body_search,omitempty"`
+	Severity    string       `json:"severity,omitempty"`
+	MinSeverity string       `json:"min_severity,omitempty"`
+	Source      string       `json:"source,omitempty"`
+	Color       string       `json:"color,omitempty"`
+	FromDate    string       `json:"from_date,omitempty"`
+	ToDate      string       `json:"to_date,omitempty"`
+	Limit       int          `json:"limit,omitempty"`
 }
 
 // ExportLogsResponse represents the output of log export.
+// TotalMatched counts every log matching the filters; Truncated is set when
+// the limit cut the export short of that total.
 type ExportLogsResponse struct {
-	Logs   []*entities.Log `json:"logs"`
-	Format ExportFormat    `json:"format"`
-	Count  int             `json:"count"`
+	Logs         []*entities.Log `json:"logs"`
+	Format       ExportFormat    `json:"format"`
+	Count        int             `json:"count"`
+	TotalMatched int             `json:"total_matched"`
+	Truncated    bool            `json:"truncated"`
 }
 
 // Handle retrieves logs for export with optional filters.
 func (h *ExportLogsHandler) Handle(ctx context.Context, request ExportLogsRequest) (*ExportLogsResponse, error) {
 	// Validate format
-	if request.Format != ExportFormatCSV && request.Format != ExportFormatJSON {
-		return nil, fmt.Errorf("invalid export format: %s (must be csv or json)", request.Format)
+	if err := request.Format.Validate(); err != nil {
+		return nil, err
 	}
 
-	// Set default limit for exports
-	if request.Limit <= 0 {
-		request.Limit = 10000 // Higher limit for exports
-	}
-	if request.Limit > 100000 {
-		request.Limit = 100000 // Maximum export limit
-	}
-
-	// Build filters
-	filters := sqlite.LogFilters{
-		Search:   request.Search,
-		Severity: request.Severity,
-		Source:   request.Source,
-		Color:    request.Color,
-		FromDate: request.FromDate,
-		ToDate:   request.ToDate,
-		Limit:    request.Limit,
-		Offset:   0, // Exports always start from beginning
-	}
+	request.Limit = h.pageSize.Clamp(request.Limit)
 
 	// Retrieve logs
-	logs, _, err := h.logRepo.FindAll(filters)
+	logs, total, err := h.logRepo.FindAllContext(ctx, request.filters())
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve logs for export: %w", err)
 	}
 
 	// Build response
 	response := &ExportLogsResponse{
-		Logs:   logs,
-		Format: request.Format,
-		Count:  len(logs),
+		Logs:         logs,
+		Format:       request.Format,
+		Count:        len(logs),
+		TotalMatched: total,
+		Truncated:    total > len(logs),
 	}
 
 	return response, nil
 }
+
+// Stream calls fn for each log matching the request, newest first in
+// (created_at, id) order, without holding the export in memory. Unlike
+// Handle it is not bound by the export page size: every matching log is
+// streamed unless request.Limit caps the export, in which case the response
+// reports the total and whether the cap truncated it. Logs created while
+// the export runs are not included. The returned response has no Logs.
+func (h *ExportLogsHandler) Stream(ctx context.Context, request ExportLogsRequest, fn func(*entities.Log) error) (*ExportLogsResponse, error) {
+	if err := request.Format.Validate(); err != nil {
+		return nil, err
+	}
+
+	count := 0
+	total, err := h.logRepo.EachContext(ctx, request.filters(), func(log *entities.Log) error {
+		count++
+		return fn(log)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream logs for export: %w", err)
+	}
+
+	return &ExportLogsResponse{
+		Format:       request.Format,
+		Count:        count,
+		TotalMatched: total,
+		Truncated:    total > count,
+	}, nil
+}
+
+// filters returns the repository filters for the request.
+func (r ExportLogsRequest) filters() sqlite.LogFilters {
+	return sqlite.LogFilters{
+		Search:      r.Search,
+		TitleSearch: r.TitleSearch,
+		BodySearch:  r.BodySearch,
+		Severity:    r.Severity,
+		MinSeverity: r.MinSeverity,
+		Source:      r.Source,
+		Color:       r.Color,
+		FromDate:    r.FromDate,
+		ToDate:      r.ToDate,
+		Limit:       r.Limit,
+		Offset:      0, // Exports always start from beginning
+	}
+}
@@ -437,3 +437,126 @@ func TestExportLogsHandler_Handle_OffsetAlwaysZero(t *testing.T) {
 		t.Errorf("Expected all 10 logs from beginning, got %d", len(response.Logs))
 	}
 }
+
+func TestExportLogsHandler_Truncated(t *testing.T) {
+	handler, repo, db := setupExportLogsTest(t)
+	defer db.Close()
+
+	for i := 0; i < 5; i++ {
+		if err := createExportTestLog(repo, "error", "Error log", ""); err != nil {
+			t.Fatalf("Failed to create log: %v", err)
+		}
+	}
+	if err := createExportTestLog(repo, "info", "Info log", ""); err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+
+	response, err := handler.Handle(t.Context(), ExportLogsRequest{
+		Format:   ExportFormatJSON,
+		Severity: "error",
+		Limit:    2,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if response.Count != 2 {
+		t.Errorf("Expected 2 exported logs, got %d", response.Count)
+	}
+	if response.TotalMatched != 5 {
+		t.Errorf("Expected 5 matching logs, got %d", response.TotalMatched)
+	}
+	if !response.Truncated {
+		t.Error("Expected export to be truncated")
+	}
+
+	response, err = handler.Handle(t.Context(), ExportLogsRequest{
+		Format:   ExportFormatJSON,
+		Severity: "error",
+		Limit:    10,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if response.TotalMatched != 5 || response.Truncated {
+		t.Errorf("Expected 5 matching logs without truncation, got %d (truncated=%v)", response.TotalMatched, response.Truncated)
+	}
+}
+
+func TestExportLogsHandler_Stream_BeyondExportCap(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping large dataset test in short mode")
+	}
+
+	handler, repo, db := setupExportLogsTest(t)
+	defer db.Close()
+
+	// One more log than the export page size allows Handle to return
+	count := DefaultPagination().Export.Max + 1
+	created := time.Now().UTC().Truncate(time.Second)
+	logs := make([]*entities.Log, 0, 5000)
+	for i := 0; i < count; i++ {
+		logs = append(logs, &entities.Log{
+			Header:    entities.LogHeader{Severity: valueobjects.SeverityInfo, Title: "Bulk log"},
+			Body:      map[string]any{},
+			CreatedAt: created.Add(time.Duration(i/10) * time.Second),
+		})
+		if len(logs) == cap(logs) || i == count-1 {
+			if err := repo.CreateBatchContext(context.Background(), logs); err != nil {
+				t.Fatalf("Failed to create logs: %v", err)
+			}
+			logs = logs[:0]
+		}
+	}
+
+	streamed := 0
+	var prev *entities.Log
+	response, err := handler.Stream(context.Background(), ExportLogsRequest{Format: ExportFormatNDJSON}, func(log *entities.Log) error {
+		if prev != nil && (log.CreatedAt.After(prev.CreatedAt) || log.CreatedAt.Equal(prev.CreatedAt) && log.ID >= prev.ID) {
+			t.Fatalf("log %d out of (created_at, id) order after log %d", log.ID, prev.ID)
+		}
+		prev = log
+		streamed++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if streamed != count || response.Count != count {
+		t.Errorf("Expected all %d logs streamed, got %d (count %d)", count, streamed, response.Count)
+	}
+	if response.TotalMatched != count || response.Truncated {
+		t.Errorf("Expected %d matching logs without truncation, got %d (truncated=%v)", count, response.TotalMatched, response.Truncated)
+	}
+	if response.Logs != nil {
+		t.Error("Expected streamed response to hold no logs")
+	}
+}
+
+func TestExportLogsHandler_Stream_Limit(t *testing.T) {
+	handler, repo, db := setupExportLogsTest(t)
+	defer db.Close()
+
+	for i := 0; i < 5; i++ {
+		if err := createExportTestLog(repo, valueobjects.SeverityInfo, "Test log", valueobjects.ColorFromString("blue")); err != nil {
+			t.Fatalf("Failed to create log: %v", err)
+		}
+	}
+
+	streamed := 0
+	response, err := handler.Stream(context.Background(), ExportLogsRequest{Format: ExportFormatCSV, Limit: 2}, func(*entities.Log) error {
+		streamed++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if streamed != 2 || response.TotalMatched != 5 || !response.Truncated {
+		t.Errorf("Expected 2 of 5 logs with truncation, got %d of %d (truncated=%v)", streamed, response.TotalMatched, response.Truncated)
+	}
+
+	if _, err := handler.Stream(context.Background(), ExportLogsRequest{Format: "xml"}, func(*entities.Log) error { return nil }); err == nil {
+		t.Error("Expected error for invalid format")
+	}
+}
@@ -0,0 +1,70 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// GetErrorsPerSourceHandler handles the per-source error rate breakdown.
+type GetErrorsPerSourceHandler struct {
+	logRepo *sqlite.LogRepository
+}
+
+// NewGetErrorsPerSourceHandler creates a new GetErrorsPerSourceHandler.
+func NewGetErrorsPerSourceHandler(logRepo *sqlite.LogRepository) *GetErrorsPerSourceHandler {
+	return &GetErrorsPerSourceHandler{
+		logRepo: logRepo,
+	}
+}
+
+// GetErrorsPerSourceRequest represents the input for the errors per source
+// query.
+type GetErrorsPerSourceRequest struct {
+	Since time.Duration `json:"since"`
+}
+
+// SourceErrorRate is the share of error and critical logs from one source.
+type SourceErrorRate struct {
+	Source     string  `json:"source"`
+	ErrorCount int     `json:"error_count"`
+	TotalCount int     `json:"total_count"`
+	ErrorRate  float64 `json:"error_rate"`
+}
+
+// Handle counts recent logs and error logs per effective source. Sources are
+// sorted by error rate, then error count, highest first.
+func (h *GetErrorsPerSourceHandler) Handle(ctx context.Context, request GetErrorsPerSourceRequest) ([]SourceErrorRate, error) {
+	if request.Since <= 0 {
+		request.Since = time.Hour
+	}
+
+	counts, err := h.logRepo.CountErrorsBySourceSinceContext(ctx, time.Now().Add(-request.Since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count errors by source: %w", err)
+	}
+
+	rates := make([]SourceErrorRate, 0, len(counts))
+	for _, count := range counts {
+		rates = append(rates, SourceErrorRate{
+			Source:     count.Source,
+			ErrorCount: count.ErrorCount,
+			TotalCount: count.TotalCount,
+			ErrorRate:  float64(count.ErrorCount) / float64(count.TotalCount),
+		})
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].ErrorRate != rates[j].ErrorRate {
+			return rates[i].ErrorRate > rates[j].ErrorRate
+		}
+		if rates[i].ErrorCount != rates[j].ErrorCount {
+			return rates[i].ErrorCount > rates[j].ErrorCount
+		}
+		return rates[i].Source < rates[j].Source
+	})
+
+	return rates, nil
+}
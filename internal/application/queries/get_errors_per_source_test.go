@@ -0,0 +1,98 @@
+package queries
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+func createSourceTestLog(t *testing.T, repo *sqlite.LogRepository, source string, severity valueobjects.Severity, derivedSeverity string, createdAt time.Time) {
+	t.Helper()
+
+	log := &entities.Log{
+		Header: entities.LogHeader{
+			Severity: severity,
+			Source:   source,
+			Title:    "event from " + source,
+		},
+		Body:      map[string]any{},
+		Metadata:  entities.LogMetadata{DerivedSeverity: derivedSeverity},
+		CreatedAt: createdAt,
+	}
+	if err := repo.Create(log); err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+}
+
+func TestGetErrorsPerSourceHandler_Handle(t *testing.T) {
+	db, err := sqlite.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+	if err := sqlite.RunMigrations(db.Conn()); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	repo := sqlite.NewLogRepository(db)
+
+	now := time.Now()
+	recent := now.Add(-10 * time.Minute)
+
+	// billing: 3 of 4 are errors by effective severity
+	createSourceTestLog(t, repo, "billing", valueobjects.SeverityError, "", recent)
+	createSourceTestLog(t, repo, "billing", valueobjects.SeverityCritical, "", recent)
+	createSourceTestLog(t, repo, "billing", valueobjects.SeverityInfo, "error", recent)
+	createSourceTestLog(t, repo, "billing", valueobjects.SeverityInfo, "", recent)
+
+	// auth: 1 of 2; the error derived down to a warning does not count
+	createSourceTestLog(t, repo, "auth", valueobjects.SeverityError, "", recent)
+	createSourceTestLog(t, repo, "auth", valueobjects.SeverityError, "warning", recent)
+
+	// search: 1 of 4, more errors than none but the lowest rate
+	createSourceTestLog(t, repo, "search", valueobjects.SeverityError, "", recent)
+	for i := 0; i < 3; i++ {
+		createSourceTestLog(t, repo, "search", valueobjects.SeveritySuccess, "", recent)
+	}
+
+	// cache: no errors
+	createSourceTestLog(t, repo, "cache", valueobjects.SeverityDebug, "", recent)
+
+	// Outside the window
+	createSourceTestLog(t, repo, "cache", valueobjects.SeverityCritical, "", now.Add(-2*time.Hour))
+
+	handler := NewGetErrorsPerSourceHandler(repo)
+	rates, err := handler.Handle(context.Background(), GetErrorsPerSourceRequest{Since: time.Hour})
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+
+	want := []SourceErrorRate{
+		{Source: "billing", ErrorCount: 3, TotalCount: 4, ErrorRate: 0.75},
+		{Source: "auth", ErrorCount: 1, TotalCount: 2, ErrorRate: 0.5},
+		{Source: "search", ErrorCount: 1, TotalCount: 4, ErrorRate: 0.25},
+		{Source: "cache", ErrorCount: 0, TotalCount: 1, ErrorRate: 0},
+	}
+	if len(rates) != len(want) {
+		t.Fatalf("expected %d sources, got %d: %+v", len(want), len(rates), rates)
+	}
+	for i := range want {
+		if rates[i] != want[i] {
+			t.Errorf("source %d: expected %+v, got %+v", i, want[i], rates[i])
+		}
+	}
+
+	// A wider window includes the older critical log
+	rates, err = handler.Handle(context.Background(), GetErrorsPerSourceRequest{Since: 3 * time.Hour})
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	for _, rate := range rates {
+		if rate.Source == "cache" && (rate.ErrorCount != 1 || rate.TotalCount != 2) {
+			t.Errorf("expected cache to count the older error, got %+v", rate)
+		}
+	}
+}
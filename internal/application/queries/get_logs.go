@@ -10,26 +10,37 @@ import (
 
 // GetLogsHandler handles retrieval of logs with filtering.
 type GetLogsHandler struct {
-	logRepo *sqlite.LogRepository
+	logRepo  *sqlite.LogRepository
+	pageSize PageSize
 }
 
 // NewGetLogsHandler creates a new GetLogsHandler.
 func NewGetLogsHandler(logRepo *sqlite.LogRepository) *GetLogsHandler {
 	return &GetLogsHandler{
-		logRepo: logRepo,
+		logRepo:  logRepo,
+		pageSize: DefaultPagination().Query,
 	}
 }
 
+// SetPageSize overrides the default and maximum number of logs returned.
+func (h *GetLogsHandler) SetPageSize(pageSize PageSize) {
+	h.pageSize = pageSize.normalize(DefaultPagination().Query)
+}
+
 // GetLogsRequest represents the input for retrieving logs.
 type GetLogsRequest struct {
-	Search   string `json:"search,omitempty"`
-	Severity string `json:"severity,omitempty"`
-	Source   string `json:"source,omitempty"`
-	Color    string `json:"color,omitempty"`
-	FromDate string `json:"from_date,omitempty"`
-	ToDate   string `json:"to_date,omitempty"`
-	Limit    int    `json:"limit,omitempty"`
-	Offset   int    `json:"offset,omitempty"`
+	Search      string `json:"search,omitempty"`
+	TitleSearch string `json:"title_search,omitempty"`
+	BodySearch  string `json:"body_search,omitempty"`
+	Severity    string `json:"severity,omitempty"`
+	MinSeverity string `json:"min_severity,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Color       string `json:"color,omitempty"`
+	FromDate    string `json:"from_date,omitempty"`
+	ToDate      string `json:"to_date,omitempty"`
+	Limit       int    `json:"limit,omitempty"`
+	Offset      int    `json:"offset,omitempty"`
+	IncludeBody *bool  `json:"include_body,omitempty"`
 }
 
 // GetLogsResponse represents the output of log retrieval.
@@ -42,28 +53,27 @@ type GetLogsResponse struct {
 
 // Handle retrieves logs with optional filters.
 func (h *GetLogsHandler) Handle(ctx context.Context, request GetLogsRequest) (*GetLogsResponse, error) {
-	if request.Limit <= 0 {
-		request.Limit = 100
-	}
-	if request.Limit > 1000 {
-		request.Limit = 1000
-	}
+	request.Limit = h.pageSize.Clamp(request.Limit)
 	if request.Offset < 0 {
 		request.Offset = 0
 	}
 
 	filters := sqlite.LogFilters{
-		Search:   request.Search,
-		Severity: request.Severity,
-		Source:   request.Source,
-		Color:    request.Color,
-		FromDate: request.FromDate,
-		ToDate:   request.ToDate,
-		Limit:    request.Limit,
-		Offset:   request.Offset,
+		Search:      request.Search,
+		TitleSearch: request.TitleSearch,
+		BodySearch:  request.BodySearch,
+		Severity:    request.Severity,
+		MinSeverity: request.MinSeverity,
+		Source:      request.Source,
+		Color:       request.Color,
+		FromDate:    request.FromDate,
+		ToDate:      request.ToDate,
+		Limit:       request.Limit,
+		Offset:      request.Offset,
+		IncludeBody: request.IncludeBody,
 	}
 
-	logs, totalCount, err := h.logRepo.FindAll(filters)
+	logs, totalCount, err := h.logRepo.FindAllContext(ctx, filters)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve logs: %w", err)
 	}
@@ -1,5 +1,7 @@
 package queries
 
+import "context"
+
 // StatsOutput represents log statistics.
 type StatsOutput struct {
 	Total       int            `json:"total"`
@@ -10,10 +12,10 @@ type StatsOutput struct {
 
 // StatsRepository defines the interface for stats queries.
 type StatsRepository interface {
-	Count() (int, error)
-	CountLast24Hours() (int, error)
-	CountBySeverity() (map[string]int, error)
-	CountBySource() (map[string]int, error)
+	CountContext(ctx context.Context) (int, error)
+	CountLast24HoursContext(ctx context.Context) (int, error)
+	CountBySeverityContext(ctx context.Context) (map[string]int, error)
+	CountByEffectiveSourceContext(ctx context.Context) (map[string]int, error)
 }
 
 // GetStatsHandler handles the get stats query.
@@ -28,22 +30,29 @@ func NewGetStatsHandler(repo StatsRepository) *GetStatsHandler {
 
 // Handle executes the get stats query.
 func (h *GetStatsHandler) Handle() (*StatsOutput, error) {
-	total, err := h.repo.Count()
+	return h.HandleContext(context.Background())
+}
+
+// HandleContext executes the get stats query, honoring ctx cancellation and
+// its tenant.
+func (h *GetStatsHandler) HandleContext(ctx context.Context) (*StatsOutput, error) {
+	total, err := h.repo.CountContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	last24h, err := h.repo.CountLast24Hours()
+	last24h, err := h.repo.CountLast24HoursContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	bySeverity, err := h.repo.CountBySeverity()
+	bySeverity, err := h.repo.CountBySeverityContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	bySource, err := h.repo.CountBySource()
+	// Sources are counted as logs display them, including derived sources
+	bySource, err := h.repo.CountByEffectiveSourceContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -170,6 +170,35 @@ func TestGetStatsHandler_Handle_BySource(t *testing.T) {
 	}
 }
 
+func TestGetStatsHandler_Handle_BySource_Derived(t *testing.T) {
+	handler, logRepo, db := setupGetStatsTest(t)
+	defer db.Close()
+
+	createStatsTestLog(t, logRepo, "info", "api-service")
+	for range 2 {
+		log := entities.NewLog(entities.LogHeader{Title: "Test log", Severity: valueobjects.SeverityInfo}, nil)
+		log.Metadata.DerivedSource = "payments"
+		if err := logRepo.Create(log); err != nil {
+			t.Fatalf("Failed to create log: %v", err)
+		}
+	}
+
+	output, err := handler.Handle()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if output.BySource["payments"] != 2 {
+		t.Errorf("Expected 2 payments logs from derived source, got %d", output.BySource["payments"])
+	}
+	if output.BySource["api-service"] != 1 {
+		t.Errorf("Expected 1 api-service log, got %d", output.BySource["api-service"])
+	}
+	if _, ok := output.BySource["unknown"]; ok {
+		t.Errorf("Expected no unknown sources, got %v", output.BySource)
+	}
+}
+
 func TestGetStatsHandler_Handle_MultipleCalls(t *testing.T) {
 	handler, logRepo, db := setupGetStatsTest(t)
 	defer db.Close()
@@ -0,0 +1,126 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// maxTopErrorsScan bounds how many recent error logs are grouped per request.
+const maxTopErrorsScan = 10000
+
+var (
+	uuidPattern   = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	hexIDPattern  = regexp.MustCompile(`(?i)\b(?:0x)?[0-9a-f]*[0-9][0-9a-f]*\b`)
+	numberPattern = regexp.MustCompile(`\d+`)
+	spacePattern  = regexp.MustCompile(`\s+`)
+)
+
+// GetTopErrorsHandler handles aggregation of the most frequent recent errors.
+type GetTopErrorsHandler struct {
+	logRepo *sqlite.LogRepository
+}
+
+// NewGetTopErrorsHandler creates a new GetTopErrorsHandler.
+func NewGetTopErrorsHandler(logRepo *sqlite.LogRepository) *GetTopErrorsHandler {
+	return &GetTopErrorsHandler{
+		logRepo: logRepo,
+	}
+}
+
+// GetTopErrorsRequest represents the input for the top errors query.
+type GetTopErrorsRequest struct {
+	Since time.Duration `json:"since"`
+	Limit int           `json:"limit,omitempty"`
+}
+
+// TopErrorGroup represents errors sharing the same normalized title.
+type TopErrorGroup struct {
+	Title    string `json:"title"`
+	Count    int    `json:"count"`
+	SampleID int64  `json:"sample_id"`
+	LastSeen string `json:"last_seen"`
+}
+
+// GetTopErrorsResponse represents the output of the top errors query.
+type GetTopErrorsResponse struct {
+	Groups  []TopErrorGroup `json:"groups"`
+	Since   string          `json:"since"`
+	Scanned int             `json:"scanned"`
+}
+
+// Handle groups recent error and critical logs by normalized title.
+func (h *GetTopErrorsHandler) Handle(ctx context.Context, request GetTopErrorsRequest) (*GetTopErrorsResponse, error) {
+	if request.Since <= 0 {
+		request.Since = time.Hour
+	}
+	if request.Limit <= 0 {
+		request.Limit = 10
+	}
+	if request.Limit > 100 {
+		request.Limit = 100
+	}
+
+	since := time.Now().Add(-request.Since)
+
+	samples, err := h.logRepo.FindErrorsSinceContext(ctx, since, maxTopErrorsScan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve error logs: %w", err)
+	}
+
+	// Samples are newest first, so the first one seen per group is the latest
+	groups := make(map[string]*TopErrorGroup)
+	for _, sample := range samples {
+		key := NormalizeErrorTitle(sample.Title)
+		if group, ok := groups[key]; ok {
+			group.Count++
+			continue
+		}
+		groups[key] = &TopErrorGroup{
+			Title:    key,
+			Count:    1,
+			SampleID: sample.ID,
+			LastSeen: sample.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	result := make([]TopErrorGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Title < result[j].Title
+	})
+	if len(result) > request.Limit {
+		result = result[:request.Limit]
+	}
+
+	return &GetTopErrorsResponse{
+		Groups:  result,
+		Since:   since.Format(time.RFC3339),
+		Scanned: len(samples),
+	}, nil
+}
+
+// NormalizeErrorTitle strips ids and numbers from a title so that errors
+// differing only by identifiers collapse into the same group.
+func NormalizeErrorTitle(title string) string {
+	normalized := uuidPattern.ReplaceAllString(title, "<id>")
+	normalized = hexIDPattern.ReplaceAllStringFunc(normalized, func(match string) string {
+		if len(match) >= 8 {
+			return "<id>"
+		}
+		return match
+	})
+	normalized = numberPattern.ReplaceAllString(normalized, "<n>")
+	normalized = spacePattern.ReplaceAllString(normalized, " ")
+	return strings.TrimSpace(normalized)
+}
@@ -0,0 +1,129 @@
+package queries
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+func setupGetTopErrorsTest(t *testing.T) (*GetTopErrorsHandler, *sqlite.LogRepository, *sqlite.Database) {
+	t.Helper()
+
+	db, err := sqlite.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+
+	if err := sqlite.RunMigrations(db.Conn()); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	logRepo := sqlite.NewLogRepository(db)
+	handler := NewGetTopErrorsHandler(logRepo)
+
+	return handler, logRepo, db
+}
+
+func createTopErrorsTestLog(t *testing.T, repo *sqlite.LogRepository, severity valueobjects.Severity, title string, createdAt time.Time) int64 {
+	t.Helper()
+
+	log := &entities.Log{
+		Header: entities.LogHeader{
+			Severity: severity,
+			Title:    title,
+		},
+		Body:      map[string]any{},
+		CreatedAt: createdAt,
+	}
+	if err := repo.Create(log); err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+	return log.ID
+}
+
+func TestNormalizeErrorTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Order 123 failed", "Order <n> failed"},
+		{"Order 98765 failed", "Order <n> failed"},
+		{"User 550e8400-e29b-41d4-a716-446655440000 not found", "User <id> not found"},
+		{"Request a1b2c3d4e5f6 timed out", "Request <id> timed out"},
+		{"Connection refused", "Connection refused"},
+		{"  Job   42  crashed ", "Job <n> crashed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := NormalizeErrorTitle(tt.title); got != tt.want {
+				t.Errorf("NormalizeErrorTitle(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTopErrorsHandler_Handle_GroupsByNormalizedTitle(t *testing.T) {
+	handler, repo, db := setupGetTopErrorsTest(t)
+	defer db.Close()
+
+	now := time.Now()
+	createTopErrorsTestLog(t, repo, valueobjects.SeverityError, "Order 1 failed", now.Add(-3*time.Minute))
+	createTopErrorsTestLog(t, repo, valueobjects.SeverityError, "Order 22 failed", now.Add(-2*time.Minute))
+	latestID := createTopErrorsTestLog(t, repo, valueobjects.SeverityCritical, "Order 333 failed", now.Add(-1*time.Minute))
+	createTopErrorsTestLog(t, repo, valueobjects.SeverityError, "Disk full", now.Add(-1*time.Minute))
+
+	// Not errors, or outside the window
+	createTopErrorsTestLog(t, repo, valueobjects.SeverityInfo, "Order 4 failed", now)
+	createTopErrorsTestLog(t, repo, valueobjects.SeverityError, "Order 5 failed", now.Add(-2*time.Hour))
+
+	response, err := handler.Handle(context.Background(), GetTopErrorsRequest{Since: time.Hour})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(response.Groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %d: %+v", len(response.Groups), response.Groups)
+	}
+
+	top := response.Groups[0]
+	if top.Title != "Order <n> failed" {
+		t.Errorf("Expected top group 'Order <n> failed', got %q", top.Title)
+	}
+	if top.Count != 3 {
+		t.Errorf("Expected top group count 3, got %d", top.Count)
+	}
+	if top.SampleID != latestID {
+		t.Errorf("Expected sample id %d (most recent), got %d", latestID, top.SampleID)
+	}
+
+	if response.Groups[1].Count != 1 {
+		t.Errorf("Expected second group count 1, got %d", response.Groups[1].Count)
+	}
+	if response.Scanned != 4 {
+		t.Errorf("Expected 4 scanned logs, got %d", response.Scanned)
+	}
+}
+
+func TestGetTopErrorsHandler_Handle_Limit(t *testing.T) {
+	handler, repo, db := setupGetTopErrorsTest(t)
+	defer db.Close()
+
+	now := time.Now()
+	for _, title := range []string{"Alpha failed", "Beta failed", "Gamma failed"} {
+		createTopErrorsTestLog(t, repo, valueobjects.SeverityError, title, now)
+	}
+
+	response, err := handler.Handle(context.Background(), GetTopErrorsRequest{Since: time.Hour, Limit: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(response.Groups) != 2 {
+		t.Errorf("Expected 2 groups with limit, got %d", len(response.Groups))
+	}
+}
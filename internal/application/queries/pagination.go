@@ -0,0 +1,76 @@
+package queries
+
+// PageSize bounds how many logs a single request returns.
+type PageSize struct {
+	Default int `json:"default"`
+	Max     int `json:"max"`
+}
+
+// Clamp returns the limit to use for a requested limit n: Default when n is
+// not positive, capped at Max.
+func (p PageSize) Clamp(n int) int {
+	if n <= 0 {
+		n = p.Default
+	}
+	if n > p.Max {
+		n = p.Max
+	}
+	return n
+}
+
+// Pagination holds the page sizes for each kind of log listing.
+type Pagination struct {
+	// List applies to the dashboard's GET /api/logs.
+	List PageSize `json:"list"`
+	// Query applies to GetLogsHandler.
+	Query PageSize `json:"query"`
+	// Export applies to ExportLogsHandler and the export endpoints.
+	Export PageSize `json:"export"`
+	// IDs applies to GET /api/logs/ids.
+	IDs PageSize `json:"ids"`
+	// MaxOffset is the deepest offset GET /api/logs serves with ?page=;
+	// clients reading past it must continue with a cursor instead.
+	MaxOffset int `json:"max_offset"`
+}
+
+// DefaultMaxOffset is the built-in Pagination.MaxOffset.
+const DefaultMaxOffset = 10000
+
+// DefaultPagination returns the built-in page sizes.
+func DefaultPagination() Pagination {
+	return Pagination{
+		List:   PageSize{Default: 20, Max: 100},
+		Query:  PageSize{Default: 100, Max: 1000},
+		Export: PageSize{Default: 10000, Max: 100000},
+		IDs:    PageSize{Default: 10000, Max: 100000},
+
+		MaxOffset: DefaultMaxOffset,
+	}
+}
+
+// Normalize replaces unset or inconsistent page sizes and an unset
+// MaxOffset with the defaults, and raises any Max below its Default.
+func (p Pagination) Normalize() Pagination {
+	defaults := DefaultPagination()
+	p.List = p.List.normalize(defaults.List)
+	p.Query = p.Query.normalize(defaults.Query)
+	p.Export = p.Export.normalize(defaults.Export)
+	p.IDs = p.IDs.normalize(defaults.IDs)
+	if p.MaxOffset <= 0 {
+		p.MaxOffset = defaults.MaxOffset
+	}
+	return p
+}
+
+func (p PageSize) normalize(fallback PageSize) PageSize {
+	if p.Default <= 0 {
+		p.Default = fallback.Default
+	}
+	if p.Max <= 0 {
+		p.Max = fallback.Max
+	}
+	if p.Max < p.Default {
+		p.Max = p.Default
+	}
+	return p
+}
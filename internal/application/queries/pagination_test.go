@@ -0,0 +1,105 @@
+package queries
+
+import "testing"
+
+func TestPageSize_Clamp(t *testing.T) {
+	pageSize := PageSize{Default: 20, Max: 100}
+
+	tests := []struct {
+		requested int
+		want      int
+	}{
+		{0, 20},
+		{-5, 20},
+		{50, 50},
+		{100, 100},
+		{500, 100},
+	}
+
+	for _, tt := range tests {
+		if got := pageSize.Clamp(tt.requested); got != tt.want {
+			t.Errorf("Clamp(%d) = %d, want %d", tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestPagination_Normalize(t *testing.T) {
+	defaults := DefaultPagination()
+
+	normalized := Pagination{
+		List:  PageSize{Default: 50},
+		Query: PageSize{Default: 500, Max: 200},
+	}.Normalize()
+
+	if normalized.List != (PageSize{Default: 50, Max: defaults.List.Max}) {
+		t.Errorf("expected list max to fall back to default, got %+v", normalized.List)
+	}
+	if normalized.Query != (PageSize{Default: 500, Max: 500}) {
+		t.Errorf("expected query max raised to default, got %+v", normalized.Query)
+	}
+	if normalized.Export != defaults.Export {
+		t.Errorf("expected unset export to use defaults, got %+v", normalized.Export)
+	}
+}
+
+func TestGetLogsHandler_SetPageSize(t *testing.T) {
+	handler, repo, db := setupGetLogsTest(t)
+	defer db.Close()
+
+	for i := 0; i < 5; i++ {
+		if err := createTestLogEntry(repo, "info", "Log", ""); err != nil {
+			t.Fatalf("Failed to create log: %v", err)
+		}
+	}
+
+	handler.SetPageSize(PageSize{Default: 2, Max: 3})
+
+	tests := []struct {
+		limit     int
+		wantLimit int
+		wantLogs  int
+	}{
+		{0, 2, 2},
+		{10, 3, 3},
+	}
+
+	for _, tt := range tests {
+		response, err := handler.Handle(t.Context(), GetLogsRequest{Limit: tt.limit})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if response.Limit != tt.wantLimit || len(response.Logs) != tt.wantLogs {
+			t.Errorf("limit %d: expected limit %d with %d logs, got limit %d with %d logs",
+				tt.limit, tt.wantLimit, tt.wantLogs, response.Limit, len(response.Logs))
+		}
+	}
+}
+
+func TestExportLogsHandler_SetPageSize(t *testing.T) {
+	handler, repo, db := setupExportLogsTest(t)
+	defer db.Close()
+
+	for i := 0; i < 5; i++ {
+		if err := createExportTestLog(repo, "info", "Log", ""); err != nil {
+			t.Fatalf("Failed to create log: %v", err)
+		}
+	}
+
+	handler.SetPageSize(PageSize{Default: 3, Max: 4})
+
+	response, err := handler.Handle(t.Context(), ExportLogsRequest{Format: ExportFormatJSON})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if response.Count != 3 {
+		t.Errorf("Expected default of 3 logs, got %d", response.Count)
+	}
+
+	response, err = handler.Handle(t.Context(), ExportLogsRequest{Format: ExportFormatJSON, Limit: 100})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if response.Count != 4 {
+		t.Errorf("Expected max of 4 logs, got %d", response.Count)
+	}
+}
@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+// Annotation is a triage note attached to a log.
+type Annotation struct {
+	ID        int64     `json:"id"`
+	LogID     int64     `json:"log_id"`
+	Author    string    `json:"author"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewAnnotation creates a new annotation for the given log.
+func NewAnnotation(logID int64, author, text string) *Annotation {
+	return &Annotation{
+		LogID:     logID,
+		Author:    strings.TrimSpace(author),
+		Text:      strings.TrimSpace(text),
+		CreatedAt: time.Now(),
+	}
+}
+
+// Validate checks if the annotation is valid.
+func (a *Annotation) Validate() error {
+	if a.Text == "" {
+		return ErrMissingAnnotationText
+	}
+	return nil
+}
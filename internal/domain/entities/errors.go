@@ -8,4 +8,7 @@ var (
 
 	// ErrLogNotFound is returned when a log cannot be found.
 	ErrLogNotFound = errors.New("log not found")
+
+	// ErrMissingAnnotationText is returned when an annotation has no text.
+	ErrMissingAnnotationText = errors.New("annotation text is required")
 )
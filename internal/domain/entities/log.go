@@ -7,12 +7,26 @@ import (
 )
 
 // Log represents a complete log entry with structured header and flexible body.
+// AnnotationCount is read-only and filled in by the repository.
+// CreatedAt is the event time, which clients may supply for imported or
+// forwarded logs; IngestedAt is when the server received the log.
 type Log struct {
-	ID        int64          `json:"id"`
-	Header    LogHeader      `json:"header"`
-	Body      map[string]any `json:"body"`
-	Metadata  LogMetadata    `json:"metadata"`
-	CreatedAt time.Time      `json:"created_at"`
+	ID              int64          `json:"id"`
+	Header          LogHeader      `json:"header"`
+	Body            map[string]any `json:"body"`
+	Metadata        LogMetadata    `json:"metadata"`
+	Pinned          bool           `json:"pinned"`
+	AnnotationCount int            `json:"annotation_count"`
+	CreatedAt       time.Time      `json:"created_at"`
+	IngestedAt      time.Time      `json:"ingested_at"`
+
+	// ExpiresAt, when set, is when retention deletes the log, overriding
+	// the global retention window.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// BodySize is the length in bytes of the serialized body, recorded by
+	// the repository when the log is stored.
+	BodySize int64 `json:"body_size,omitempty"`
 }
 
 // LogHeader contains structured metadata - only title is required.
@@ -29,15 +43,19 @@ type LogMetadata struct {
 	DerivedSeverity string `json:"derived_severity,omitempty"`
 	DerivedSource   string `json:"derived_source,omitempty"`
 	DerivedCategory string `json:"derived_category,omitempty"`
+	DerivedColor    string `json:"derived_color,omitempty"`
 }
 
 // NewLog creates a new log entry with the given header and body.
+// Both timestamps start as the current time, in UTC.
 func NewLog(header LogHeader, body map[string]any) *Log {
+	now := time.Now().UTC()
 	return &Log{
-		Header:    header,
-		Body:      body,
-		Metadata:  LogMetadata{},
-		CreatedAt: time.Now(),
+		Header:     header,
+		Body:       body,
+		Metadata:   LogMetadata{},
+		CreatedAt:  now,
+		IngestedAt: now,
 	}
 }
 
@@ -73,10 +91,15 @@ func (l *Log) EffectiveSource() string {
 	return l.Header.Source
 }
 
-// EffectiveColor returns the color to use for this log.
+// EffectiveColor returns the color to use for this log: the explicit color,
+// otherwise the color derived on ingestion, otherwise one assigned from the
+// effective severity.
 func (l *Log) EffectiveColor() valueobjects.Color {
 	if l.Header.Color != "" && l.Header.Color.IsValid() {
 		return l.Header.Color
 	}
+	if derived := valueobjects.Color(l.Metadata.DerivedColor); derived.IsValid() {
+		return derived
+	}
 	return valueobjects.AutoAssignColor(l.EffectiveSeverity())
 }
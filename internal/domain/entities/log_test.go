@@ -125,3 +125,12 @@ func TestLog_EffectiveColor(t *testing.T) {
 		})
 	}
 }
+
+func TestAnnotation_Validate(t *testing.T) {
+	if err := NewAnnotation(1, "alex", "  note ").Validate(); err != nil {
+		t.Errorf("expected valid annotation, got %v", err)
+	}
+	if err := NewAnnotation(1, "alex", "   ").Validate(); err != ErrMissingAnnotationText {
+		t.Errorf("expected ErrMissingAnnotationText, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package services
+
+// DefaultBodyAliases maps canonical body keys to the alias keys different
+// services use for the same value, in order of preference.
+func DefaultBodyAliases() map[string][]string {
+	return map[string][]string{
+		"duration_ms": {"elapsed_ms", "latency_ms", "time_ms", "duration", "elapsed", "latency"},
+		"status_code": {"status", "http_status", "statusCode"},
+		"request_id":  {"requestId", "req_id", "x_request_id"},
+		"user_id":     {"userId", "uid"},
+	}
+}
+
+// BodyNormalizer copies aliased body fields to their canonical keys so logs
+// from different services can be filtered on the same key.
+type BodyNormalizer struct {
+	aliases map[string][]string
+}
+
+// NewBodyNormalizer creates a body normalizer for the given canonical key to
+// alias mapping. A nil mapping uses DefaultBodyAliases.
+func NewBodyNormalizer(aliases map[string][]string) *BodyNormalizer {
+	if aliases == nil {
+		aliases = DefaultBodyAliases()
+	}
+	return &BodyNormalizer{aliases: aliases}
+}
+
+// Normalize sets each canonical key missing from body to the value of its
+// first alias present. Alias keys are left in place, and canonical keys that
+// are already set are never overwritten. It returns the canonical keys added.
+func (bn *BodyNormalizer) Normalize(body map[string]any) []string {
+	var added []string
+	for canonical, aliases := range bn.aliases {
+		if _, ok := body[canonical]; ok {
+			continue
+		}
+		for _, alias := range aliases {
+			if val, ok := body[alias]; ok && val != nil {
+				body[canonical] = val
+				added = append(added, canonical)
+				break
+			}
+		}
+	}
+	return added
+}
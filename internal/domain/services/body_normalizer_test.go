@@ -0,0 +1,55 @@
+package services
+
+import "testing"
+
+func TestBodyNormalizer_Normalize(t *testing.T) {
+	bn := NewBodyNormalizer(nil)
+
+	tests := []struct {
+		name string
+		body map[string]any
+		want any
+	}{
+		{"elapsed_ms", map[string]any{"elapsed_ms": float64(120)}, float64(120)},
+		{"latency", map[string]any{"latency": "250"}, "250"},
+		{"preference order", map[string]any{"latency": float64(9), "elapsed_ms": float64(7)}, float64(7)},
+		{"canonical kept", map[string]any{"duration_ms": float64(5), "elapsed_ms": float64(7)}, float64(5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := make(map[string]any, len(tt.body))
+			for k, v := range tt.body {
+				original[k] = v
+			}
+
+			bn.Normalize(tt.body)
+
+			if got := tt.body["duration_ms"]; got != tt.want {
+				t.Errorf("duration_ms = %v, want %v", got, tt.want)
+			}
+			for k, v := range original {
+				if tt.body[k] != v {
+					t.Errorf("original key %q changed to %v", k, tt.body[k])
+				}
+			}
+		})
+	}
+}
+
+func TestBodyNormalizer_CustomAliases(t *testing.T) {
+	bn := NewBodyNormalizer(map[string][]string{"tenant": {"org", "account"}})
+
+	body := map[string]any{"account": "acme", "elapsed_ms": float64(3), "nothing": nil}
+	added := bn.Normalize(body)
+
+	if body["tenant"] != "acme" {
+		t.Errorf("expected tenant from account alias, got %v", body["tenant"])
+	}
+	if _, ok := body["duration_ms"]; ok {
+		t.Error("expected custom aliases to replace the defaults")
+	}
+	if len(added) != 1 || added[0] != "tenant" {
+		t.Errorf("expected added [tenant], got %v", added)
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces redacted body values.
+const RedactedValue = "***"
+
+// DefaultRedactKeys returns the body keys whose values are redacted by
+// default. Keys match case-insensitively anywhere in a body key, so
+// "token" also covers "access_token".
+func DefaultRedactKeys() []string {
+	return []string{"password", "passwd", "secret", "token", "api_key", "apikey", "authorization", "ssn", "card_number"}
+}
+
+// DefaultRedactPatterns returns the value patterns redacted by default:
+// payment card numbers of 13 to 19 digits, optionally grouped by spaces or
+// dashes.
+func DefaultRedactPatterns() []string {
+	return []string{`\b\d(?:[ -]?\d){12,18}\b`}
+}
+
+// BodyRedactor masks secrets in log bodies before they are stored.
+type BodyRedactor struct {
+	keys     []string
+	patterns []*regexp.Regexp
+}
+
+// NewBodyRedactor creates a body redactor for the given key denylist and
+// value patterns. Nil keys or patterns use the defaults.
+func NewBodyRedactor(keys, patterns []string) (*BodyRedactor, error) {
+	if keys == nil {
+		keys = DefaultRedactKeys()
+	}
+	if patterns == nil {
+		patterns = DefaultRedactPatterns()
+	}
+
+	br := &BodyRedactor{}
+	for _, key := range keys {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			br.keys = append(br.keys, key)
+		}
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		br.patterns = append(br.patterns, re)
+	}
+	return br, nil
+}
+
+// Redact masks, in place and at any depth, the values of denylisted keys and
+// the parts of string values matching a pattern. It returns the number of
+// values changed.
+func (br *BodyRedactor) Redact(body map[string]any) int {
+	redacted := 0
+	for key, val := range body {
+		if br.deniedKey(key) {
+			if val != nil {
+				body[key] = RedactedValue
+				redacted++
+			}
+			continue
+		}
+		var changed int
+		body[key], changed = br.redactValue(val)
+		redacted += changed
+	}
+	return redacted
+}
+
+// redactValue returns val with pattern matches masked, recursing into
+// nested objects and arrays.
+func (br *BodyRedactor) redactValue(val any) (any, int) {
+	switch v := val.(type) {
+	case string:
+		masked := v
+		for _, re := range br.patterns {
+			masked = re.ReplaceAllLiteralString(masked, RedactedValue)
+		}
+		if masked != v {
+			return masked, 1
+		}
+		return v, 0
+	case map[string]any:
+		return v, br.Redact(v)
+	case []any:
+		redacted := 0
+		for i, item := range v {
+			var changed int
+			v[i], changed = br.redactValue(item)
+			redacted += changed
+		}
+		return v, redacted
+	}
+	return val, 0
+}
+
+// deniedKey reports whether key contains a denylisted key.
+func (br *BodyRedactor) deniedKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, denied := range br.keys {
+		if strings.Contains(key, denied) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBodyRedactor_Redact(t *testing.T) {
+	br, err := NewBodyRedactor(nil, nil)
+	if err != nil {
+		t.Fatalf("NewBodyRedactor failed: %v", err)
+	}
+
+	body := map[string]any{
+		"user":         "alice",
+		"Password":     "hunter2",
+		"access_token": "abc123",
+		"attempts":     float64(3),
+		"request": map[string]any{
+			"Authorization": "Bearer xyz",
+			"path":          "/login",
+		},
+		"items": []any{
+			map[string]any{"api_key": "k-1"},
+			"card 4111 1111 1111 1111 declined",
+		},
+		"note":  "paid with 4111-1111-1111-1111",
+		"empty": nil,
+	}
+
+	if n := br.Redact(body); n != 6 {
+		t.Errorf("expected 6 redacted values, got %d", n)
+	}
+
+	for key, want := range map[string]any{
+		"user":         "alice",
+		"Password":     RedactedValue,
+		"access_token": RedactedValue,
+		"attempts":     float64(3),
+		"note":         "paid with " + RedactedValue,
+		"empty":        nil,
+	} {
+		if body[key] != want {
+			t.Errorf("%s = %v, want %v", key, body[key], want)
+		}
+	}
+
+	request := body["request"].(map[string]any)
+	if request["Authorization"] != RedactedValue || request["path"] != "/login" {
+		t.Errorf("unexpected nested object: %v", request)
+	}
+	items := body["items"].([]any)
+	if items[0].(map[string]any)["api_key"] != RedactedValue {
+		t.Errorf("expected api_key in array to be redacted, got %v", items[0])
+	}
+	if items[1] != "card "+RedactedValue+" declined" {
+		t.Errorf("expected card number in array to be redacted, got %v", items[1])
+	}
+}
+
+func TestBodyRedactor_ShortNumbersKept(t *testing.T) {
+	br, _ := NewBodyRedactor(nil, nil)
+
+	body := map[string]any{"order": "order 123456789012 shipped", "phone": "555 0100"}
+	if n := br.Redact(body); n != 0 {
+		t.Errorf("expected nothing redacted, got %d: %v", n, body)
+	}
+}
+
+func TestBodyRedactor_Custom(t *testing.T) {
+	br, err := NewBodyRedactor([]string{" Session "}, []string{`\d{3}-\d{2}-\d{4}`})
+	if err != nil {
+		t.Fatalf("NewBodyRedactor failed: %v", err)
+	}
+
+	body := map[string]any{
+		"session_id": "s-1",
+		"password":   "kept",
+		"msg":        "ssn 123-45-6789",
+	}
+	br.Redact(body)
+
+	if body["session_id"] != RedactedValue {
+		t.Errorf("expected session_id to be redacted, got %v", body["session_id"])
+	}
+	if body["password"] != "kept" {
+		t.Errorf("expected custom keys to replace the defaults, got %v", body["password"])
+	}
+	if body["msg"] != "ssn "+RedactedValue {
+		t.Errorf("expected custom pattern to apply, got %v", body["msg"])
+	}
+}
+
+func TestBodyRedactor_InvalidPattern(t *testing.T) {
+	_, err := NewBodyRedactor(nil, []string{"("})
+	if err == nil || !strings.Contains(err.Error(), "invalid redaction pattern") {
+		t.Errorf("expected invalid pattern error, got %v", err)
+	}
+}
@@ -2,7 +2,9 @@ package services
 
 import (
 	"encoding/json"
+	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -13,20 +15,153 @@ import (
 
 // PatternMatcher analyzes log content and extracts intelligent metadata.
 type PatternMatcher struct {
-	sourceDeriver *SourceDeriver
+	sourceDeriver      *SourceDeriver
+	httpStatusSeverity map[string]string
+
+	// keywordWeights enables keyword scoring when set; nil keeps
+	// first-match keyword detection.
+	keywordWeights map[string]float64
+
+	// textLimits bounds the serialized body matched against.
+	textLimits textLimits
+}
+
+// PatternMatcherOptions configures a pattern matcher beyond the built-in
+// rules.
+type PatternMatcherOptions struct {
+	// HTTPStatusSeverity is merged over the built-in HTTP status-to-severity
+	// mapping, e.g. {"404": "error", "429": "critical"}. Codes must be
+	// three-digit HTTP statuses and severities standard ones.
+	HTTPStatusSeverity map[string]string
+
+	// KeywordScoring tallies keyword hits of every severity class, each
+	// scaled by its class weight, and picks the highest-scoring severity
+	// instead of the first class with a hit. Ties go to the class that
+	// first-match detection checks first (error, warning, success, debug).
+	KeywordScoring bool
+
+	// KeywordWeights sets the weight of the "error", "warning", "success"
+	// and "debug" keyword classes in scoring mode; classes left out keep
+	// their default weight of 1.
+	KeywordWeights map[string]float64
+
+	// MaxBodyBytes caps the serialized body text patterns are matched
+	// against, and MaxBodyDepth how deeply nested objects and arrays are
+	// serialized, so analysis time is bounded whatever the payload. Zero
+	// uses DefaultAnalysisMaxBodyBytes and DefaultAnalysisMaxBodyDepth.
+	// The stored body is never truncated.
+	MaxBodyBytes int
+	MaxBodyDepth int
+}
+
+// keywordClass is a severity with the keywords that indicate it.
+type keywordClass struct {
+	severity string
+	keywords []string
+}
+
+// keywordClasses lists the keyword classes in first-match priority order.
+var keywordClasses = []keywordClass{
+	{"error", rules.ErrorKeywords},
+	{"warning", rules.WarningKeywords},
+	{"success", rules.SuccessKeywords},
+	{"debug", rules.DebugKeywords},
+}
+
+// DefaultKeywordWeights returns the keyword class weights used by scoring
+// mode when none are configured.
+func DefaultKeywordWeights() map[string]float64 {
+	weights := make(map[string]float64, len(keywordClasses))
+	for _, class := range keywordClasses {
+		weights[class.severity] = 1
+	}
+	return weights
 }
 
 // NewPatternMatcher creates a new pattern matcher service.
 func NewPatternMatcher() *PatternMatcher {
 	return &PatternMatcher{
-		sourceDeriver: NewSourceDeriver(),
+		sourceDeriver:      NewSourceDeriver(),
+		httpStatusSeverity: rules.HTTPStatusSeverity,
+		textLimits:         defaultTextLimits(),
 	}
 }
 
+// NewPatternMatcherWithStatusSeverity creates a pattern matcher whose HTTP
+// status-to-severity mapping is the built-in one with overrides merged over
+// it. See PatternMatcherOptions.HTTPStatusSeverity.
+func NewPatternMatcherWithStatusSeverity(overrides map[string]string) (*PatternMatcher, error) {
+	return NewPatternMatcherWithOptions(PatternMatcherOptions{HTTPStatusSeverity: overrides})
+}
+
+// NewPatternMatcherWithOptions creates a pattern matcher configured by opts.
+// It returns an error for an invalid status code, severity or keyword
+// weight, or a negative body limit.
+func NewPatternMatcherWithOptions(opts PatternMatcherOptions) (*PatternMatcher, error) {
+	if opts.MaxBodyBytes < 0 {
+		return nil, fmt.Errorf("max body bytes must not be negative, got %d", opts.MaxBodyBytes)
+	}
+	if opts.MaxBodyDepth < 0 {
+		return nil, fmt.Errorf("max body depth must not be negative, got %d", opts.MaxBodyDepth)
+	}
+
+	codes := make([]string, 0, len(opts.HTTPStatusSeverity))
+	for code := range opts.HTTPStatusSeverity {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	merged := make(map[string]string, len(rules.HTTPStatusSeverity)+len(opts.HTTPStatusSeverity))
+	for code, severity := range rules.HTTPStatusSeverity {
+		merged[code] = severity
+	}
+	for _, code := range codes {
+		if n, err := strconv.Atoi(code); err != nil || len(code) != 3 || n < 100 || n > 599 {
+			return nil, fmt.Errorf("invalid HTTP status code %q", code)
+		}
+		severity := opts.HTTPStatusSeverity[code]
+		if valueobjects.Severity(severity).Rank() == 0 {
+			return nil, fmt.Errorf("status %s: %q is not a standard severity", code, severity)
+		}
+		merged[code] = severity
+	}
+
+	weights := DefaultKeywordWeights()
+	classes := make([]string, 0, len(opts.KeywordWeights))
+	for class := range opts.KeywordWeights {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		if _, ok := weights[class]; !ok {
+			return nil, fmt.Errorf("unknown keyword class %q (must be error, warning, success or debug)", class)
+		}
+		weight := opts.KeywordWeights[class]
+		if weight < 0 {
+			return nil, fmt.Errorf("keyword class %s: weight must not be negative, got %g", class, weight)
+		}
+		weights[class] = weight
+	}
+
+	pm := NewPatternMatcher()
+	pm.httpStatusSeverity = merged
+	if opts.MaxBodyBytes > 0 {
+		pm.textLimits.maxBytes = opts.MaxBodyBytes
+	}
+	if opts.MaxBodyDepth > 0 {
+		pm.textLimits.maxDepth = opts.MaxBodyDepth
+	}
+	pm.sourceDeriver.textLimits = pm.textLimits
+	if opts.KeywordScoring {
+		pm.keywordWeights = weights
+	}
+	return pm, nil
+}
+
 // AnalyzeLog performs comprehensive pattern matching on a log entry.
 func (pm *PatternMatcher) AnalyzeLog(log *entities.Log) entities.LogMetadata {
 	// Combine all searchable text
-	allText := pm.getSearchableText(log)
+	allText := searchableText(log, pm.textLimits)
 	textLower := strings.ToLower(allText)
 
 	metadata := entities.LogMetadata{}
@@ -44,7 +179,7 @@ func (pm *PatternMatcher) AnalyzeLog(log *entities.Log) entities.LogMetadata {
 
 	// 3. Check business patterns
 	if severity := pm.checkBusinessPatterns(textLower); severity != "" {
-		metadata.DerivedSeverity = severity
+		metadata.DerivedSeverity = pm.categorySeverity(valueobjects.CategoryBusiness, textLower, severity)
 		metadata.DerivedCategory = valueobjects.CategoryBusiness.String()
 		metadata.DerivedSource = pm.sourceDeriver.DeriveSource(log)
 		return metadata
@@ -52,7 +187,7 @@ func (pm *PatternMatcher) AnalyzeLog(log *entities.Log) entities.LogMetadata {
 
 	// 4. Check performance patterns
 	if severity := pm.checkPerformancePatterns(log, textLower); severity != "" {
-		metadata.DerivedSeverity = severity
+		metadata.DerivedSeverity = pm.categorySeverity(valueobjects.CategoryPerformance, textLower, severity)
 		metadata.DerivedCategory = valueobjects.CategoryPerformance.String()
 		metadata.DerivedSource = pm.sourceDeriver.DeriveSource(log)
 		return metadata
@@ -60,8 +195,8 @@ func (pm *PatternMatcher) AnalyzeLog(log *entities.Log) entities.LogMetadata {
 
 	// 5. Extract HTTP status code and map to severity
 	if statusCode := pm.extractHTTPStatusCode(allText); statusCode != "" {
-		if severity, ok := rules.HTTPStatusSeverity[statusCode]; ok {
-			metadata.DerivedSeverity = severity
+		if severity, ok := pm.httpStatusSeverity[statusCode]; ok {
+			metadata.DerivedSeverity = pm.categorySeverity(valueobjects.CategoryHTTP, textLower, severity)
 			metadata.DerivedCategory = valueobjects.CategoryHTTP.String()
 			metadata.DerivedSource = pm.sourceDeriver.DeriveSource(log)
 			return metadata
@@ -70,7 +205,7 @@ func (pm *PatternMatcher) AnalyzeLog(log *entities.Log) entities.LogMetadata {
 
 	// 6. Check for stack traces (indicates error)
 	if pm.hasStackTrace(allText) {
-		metadata.DerivedSeverity = "error"
+		metadata.DerivedSeverity = pm.categorySeverity(valueobjects.Category(metadata.DerivedCategory), textLower, "error")
 		metadata.DerivedSource = pm.sourceDeriver.DeriveSource(log)
 		return metadata
 	}
@@ -78,7 +213,7 @@ func (pm *PatternMatcher) AnalyzeLog(log *entities.Log) entities.LogMetadata {
 	// 7. Check database patterns
 	for pattern, severity := range rules.DatabasePatterns {
 		if strings.Contains(textLower, pattern) {
-			metadata.DerivedSeverity = severity
+			metadata.DerivedSeverity = pm.categorySeverity(valueobjects.CategoryDatabase, textLower, severity)
 			metadata.DerivedCategory = valueobjects.CategoryDatabase.String()
 			metadata.DerivedSource = pm.sourceDeriver.DeriveSource(log)
 			return metadata
@@ -88,7 +223,7 @@ func (pm *PatternMatcher) AnalyzeLog(log *entities.Log) entities.LogMetadata {
 	// 8. Check system error codes
 	for code, severity := range rules.SystemErrorCodes {
 		if strings.Contains(allText, code) {
-			metadata.DerivedSeverity = severity
+			metadata.DerivedSeverity = pm.categorySeverity(valueobjects.CategorySystem, textLower, severity)
 			metadata.DerivedCategory = valueobjects.CategorySystem.String()
 			metadata.DerivedSource = pm.sourceDeriver.DeriveSource(log)
 			return metadata
@@ -96,7 +231,8 @@ func (pm *PatternMatcher) AnalyzeLog(log *entities.Log) entities.LogMetadata {
 	}
 
 	// 9. Check keyword-based severity detection
-	metadata.DerivedSeverity = pm.detectSeverityFromKeywords(textLower)
+	metadata.DerivedSeverity = pm.categorySeverity(valueobjects.Category(metadata.DerivedCategory), textLower,
+		pm.detectSeverityFromKeywords(textLower))
 
 	// 10. Extract source from content
 	metadata.DerivedSource = pm.sourceDeriver.DeriveSource(log)
@@ -154,6 +290,17 @@ func (pm *PatternMatcher) detectCategory(textLower string) valueobjects.Category
 	return valueobjects.CategoryGeneral
 }
 
+// categorySeverity returns the severity set by a category override rule
+// matching textLower, or severity when no rule for category matches.
+func (pm *PatternMatcher) categorySeverity(category valueobjects.Category, textLower, severity string) string {
+	for pattern, override := range rules.CategorySeverityOverrides[category.String()] {
+		if strings.Contains(textLower, pattern) {
+			return override
+		}
+	}
+	return severity
+}
+
 // checkBusinessPatterns checks for business-related patterns.
 func (pm *PatternMatcher) checkBusinessPatterns(textLower string) string {
 	for pattern, severity := range rules.BusinessPatterns {
@@ -182,6 +329,10 @@ func (pm *PatternMatcher) checkPerformancePatterns(log *entities.Log, textLower
 				switch v := val.(type) {
 				case float64:
 					durationMs = int(v)
+				case json.Number:
+					if parsed, err := v.Float64(); err == nil {
+						durationMs = int(parsed)
+					}
 				case int:
 					durationMs = v
 				case string:
@@ -200,26 +351,6 @@ func (pm *PatternMatcher) checkPerformancePatterns(log *entities.Log, textLower
 	return ""
 }
 
-// getSearchableText combines all text content from the log.
-func (pm *PatternMatcher) getSearchableText(log *entities.Log) string {
-	var parts []string
-
-	parts = append(parts, log.Header.Title)
-
-	if log.Header.Description != "" {
-		parts = append(parts, log.Header.Description)
-	}
-
-	// Convert body to string if not empty
-	if len(log.Body) > 0 {
-		if bodyJSON, err := json.Marshal(log.Body); err == nil {
-			parts = append(parts, string(bodyJSON))
-		}
-	}
-
-	return strings.Join(parts, " ")
-}
-
 // extractHTTPStatusCode extracts HTTP status codes from text.
 func (pm *PatternMatcher) extractHTTPStatusCode(text string) string {
 	patterns := []string{
@@ -270,6 +401,10 @@ func (pm *PatternMatcher) detectSecurityIssue(textLower string) bool {
 
 // detectSeverityFromKeywords detects severity from keyword analysis.
 func (pm *PatternMatcher) detectSeverityFromKeywords(textLower string) string {
+	if pm.keywordWeights != nil {
+		return pm.scoreSeverityFromKeywords(textLower)
+	}
+
 	// Check for error keywords (highest priority)
 	for _, keyword := range rules.ErrorKeywords {
 		if strings.Contains(textLower, keyword) {
@@ -301,3 +436,23 @@ func (pm *PatternMatcher) detectSeverityFromKeywords(textLower string) string {
 	// Default to info if no match
 	return "info"
 }
+
+// scoreSeverityFromKeywords picks the keyword class with the highest
+// weighted number of keyword hits, so "failed once, then completed
+// successfully" reads as success. Ties keep first-match priority, and text
+// without any hit is info.
+func (pm *PatternMatcher) scoreSeverityFromKeywords(textLower string) string {
+	best, bestScore := "info", 0.0
+	for _, class := range keywordClasses {
+		hits := 0
+		for _, keyword := range class.keywords {
+			if strings.Contains(textLower, keyword) {
+				hits++
+			}
+		}
+		if score := float64(hits) * pm.keywordWeights[class.severity]; score > bestScore {
+			best, bestScore = class.severity, score
+		}
+	}
+	return best
+}
@@ -1,7 +1,10 @@
 package services
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mx-scribe/scribe/internal/domain/entities"
 	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
@@ -57,6 +60,126 @@ func TestPatternMatcher_AnalyzeLog_HTTPStatus(t *testing.T) {
 	}
 }
 
+func TestPatternMatcher_StatusSeverityOverrides(t *testing.T) {
+	pm, err := NewPatternMatcherWithStatusSeverity(map[string]string{"404": "error", "429": "critical"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		// Overridden codes
+		{"API returned 404 not found", "error"},
+		{"Upstream returned 429", "critical"},
+		// Codes without an override keep the built-in mapping
+		{"Request returned status 200", "success"},
+		{"Server error: status 500", "error"},
+		{"Gateway timeout: HTTP 504", "critical"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			meta := pm.AnalyzeLog(createTestLog(tt.title))
+			if meta.DerivedSeverity != tt.expected {
+				t.Errorf("got %q, want %q", meta.DerivedSeverity, tt.expected)
+			}
+			if meta.DerivedCategory != "http" {
+				t.Errorf("expected http category, got %q", meta.DerivedCategory)
+			}
+		})
+	}
+
+	// The built-in mapping is left untouched for other matchers
+	if meta := NewPatternMatcher().AnalyzeLog(createTestLog("API returned 404 not found")); meta.DerivedSeverity != "warning" {
+		t.Errorf("expected default matcher to keep 404 as warning, got %q", meta.DerivedSeverity)
+	}
+}
+
+func TestNewPatternMatcherWithStatusSeverity_Invalid(t *testing.T) {
+	for _, overrides := range []map[string]string{
+		{"4040": "error"},
+		{"abc": "error"},
+		{"099": "error"},
+		{"404": "fatal"},
+		{"404": ""},
+	} {
+		if _, err := NewPatternMatcherWithStatusSeverity(overrides); err == nil {
+			t.Errorf("expected error for %v", overrides)
+		}
+	}
+
+	if _, err := NewPatternMatcherWithStatusSeverity(nil); err != nil {
+		t.Errorf("expected nil overrides to be valid, got %v", err)
+	}
+}
+
+func TestPatternMatcher_KeywordScoring(t *testing.T) {
+	firstMatch := NewPatternMatcher()
+	scoring, err := NewPatternMatcherWithOptions(PatternMatcherOptions{KeywordScoring: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		title          string
+		wantFirstMatch string
+		wantScoring    string
+	}{
+		// Success outweighs a single passing failure
+		{"Upload failed once, then completed successfully after retry", "error", "success"},
+		// Failures still dominate when they make up most of the evidence
+		{"Backup failed: disk unavailable, could not write", "error", "error"},
+		// Single-class text reads the same in both modes
+		{"Deprecated flag used", "warning", "warning"},
+		{"Cache warmed", "info", "info"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			log := createTestLog(tt.title)
+			if got := firstMatch.AnalyzeLog(log).DerivedSeverity; got != tt.wantFirstMatch {
+				t.Errorf("first match: got %q, want %q", got, tt.wantFirstMatch)
+			}
+			if got := scoring.AnalyzeLog(log).DerivedSeverity; got != tt.wantScoring {
+				t.Errorf("scoring: got %q, want %q", got, tt.wantScoring)
+			}
+		})
+	}
+}
+
+func TestPatternMatcher_KeywordScoringWeights(t *testing.T) {
+	log := createTestLog("Upload failed once, then completed successfully after retry")
+
+	// Discounting success hands the mixed title back to error
+	pm, err := NewPatternMatcherWithOptions(PatternMatcherOptions{
+		KeywordScoring: true,
+		KeywordWeights: map[string]float64{"success": 0.1},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := pm.AnalyzeLog(log).DerivedSeverity; got != "error" {
+		t.Errorf("got %q, want error", got)
+	}
+
+	// Weights are ignored unless scoring is enabled
+	pm, err = NewPatternMatcherWithOptions(PatternMatcherOptions{KeywordWeights: map[string]float64{"error": 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := pm.AnalyzeLog(log).DerivedSeverity; got != "error" {
+		t.Errorf("got %q, want error", got)
+	}
+
+	for _, weights := range []map[string]float64{{"fatal": 1}, {"error": -1}} {
+		if _, err := NewPatternMatcherWithOptions(PatternMatcherOptions{KeywordScoring: true, KeywordWeights: weights}); err == nil {
+			t.Errorf("expected error for weights %v", weights)
+		}
+	}
+}
+
 func TestPatternMatcher_AnalyzeLog_StackTrace(t *testing.T) {
 	pm := NewPatternMatcher()
 
@@ -105,6 +228,39 @@ func TestPatternMatcher_AnalyzeLog_DatabasePatterns(t *testing.T) {
 	}
 }
 
+func TestPatternMatcher_AnalyzeLog_CategoryOverrides(t *testing.T) {
+	pm := NewPatternMatcher()
+
+	tests := []struct {
+		title            string
+		expectedSeverity string
+		expectedCategory string
+	}{
+		// Database logs mentioning replica lag are warnings, overriding
+		// both keyword detection and the database patterns
+		{"Replica lag error on postgres db-2", "warning", "database"},
+		{"Deadlock on postgres while replica lag grew", "warning", "database"},
+		// Logs of other categories are left alone
+		{"Order failed during replica lag", "error", "business"},
+		{"Replica lag error", "error", "general"},
+		// Database logs without the pattern keep their severity
+		{"Postgres query error", "error", "database"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			log := createTestLog(tt.title)
+			meta := pm.AnalyzeLog(log)
+			if meta.DerivedSeverity != tt.expectedSeverity {
+				t.Errorf("severity: got %q, want %q", meta.DerivedSeverity, tt.expectedSeverity)
+			}
+			if meta.DerivedCategory != tt.expectedCategory {
+				t.Errorf("category: got %q, want %q", meta.DerivedCategory, tt.expectedCategory)
+			}
+		})
+	}
+}
+
 func TestPatternMatcher_AnalyzeLog_Keywords(t *testing.T) {
 	pm := NewPatternMatcher()
 
@@ -246,6 +402,12 @@ func TestPatternMatcher_AnalyzeLog_PerformancePatterns(t *testing.T) {
 			body:     map[string]any{"duration_ms": float64(2500)},
 			expected: "warning",
 		},
+		{
+			name:     "duration from body json.Number",
+			title:    "Request completed",
+			body:     map[string]any{"duration_ms": json.Number("2500")},
+			expected: "warning",
+		},
 		{
 			name:     "duration from body int",
 			title:    "Request completed",
@@ -563,3 +725,91 @@ func TestSourceDeriver_DeriveSource_FromTitlePrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchableText_MatchesJSONWithinLimits(t *testing.T) {
+	log := createTestLog("Order placed")
+	log.Body = map[string]any{
+		"status": json.Number("201"),
+		"items":  []any{map[string]any{"sku": "A-1", "qty": 2.0}, "<gift>"},
+		"user":   map[string]any{"id": "u1", "tags": []any{}},
+		"note":   nil,
+	}
+
+	want, _ := json.Marshal(log.Body)
+	if got := searchableText(log, defaultTextLimits()); got != "Order placed "+string(want) {
+		t.Errorf("searchableText() = %q, want title and %s", got, want)
+	}
+}
+
+// deepBody nests a map depth levels deep with value at the bottom.
+func deepBody(depth int, value string) map[string]any {
+	body := map[string]any{"message": value}
+	for i := 0; i < depth; i++ {
+		body = map[string]any{"nested": body}
+	}
+	return body
+}
+
+func TestPatternMatcher_AnalyzeLog_LargeBody(t *testing.T) {
+	pm := NewPatternMatcher()
+
+	items := make([]any, 200000)
+	for i := range items {
+		items[i] = map[string]any{"id": i, "name": strings.Repeat("x", 32)}
+	}
+	log := createTestLog("Batch job finished")
+	log.Body = map[string]any{
+		"a_result": "database connection failed",
+		"items":    items,
+		"payload":  strings.Repeat("y", 8<<20),
+		"tree":     deepBody(5000, "ok"),
+	}
+
+	start := time.Now()
+	metadata := pm.AnalyzeLog(log)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected bounded analysis, took %v", elapsed)
+	}
+	if metadata.DerivedSeverity != "error" {
+		t.Errorf("expected keyword in the first part of the body to match, got severity %q", metadata.DerivedSeverity)
+	}
+
+	if text := searchableText(log, pm.textLimits); len(text) > len("Batch job finished ")+DefaultAnalysisMaxBodyBytes {
+		t.Errorf("expected searchable text capped at %d body bytes, got %d bytes", DefaultAnalysisMaxBodyBytes, len(text))
+	}
+	if got := len(log.Body["payload"].(string)); got != 8<<20 {
+		t.Errorf("expected the body to be left whole, payload is %d bytes", got)
+	}
+}
+
+func TestPatternMatcher_MaxBodyLimits(t *testing.T) {
+	log := createTestLog("Worker report")
+	log.Body = deepBody(6, "connection failed")
+
+	if got := NewPatternMatcher().AnalyzeLog(log).DerivedSeverity; got != "error" {
+		t.Fatalf("expected keyword within default depth to match, got %q", got)
+	}
+
+	shallow, err := NewPatternMatcherWithOptions(PatternMatcherOptions{MaxBodyDepth: 3})
+	if err != nil {
+		t.Fatalf("NewPatternMatcherWithOptions failed: %v", err)
+	}
+	if got := shallow.AnalyzeLog(log).DerivedSeverity; got == "error" {
+		t.Error("expected keyword nested beyond max depth to be skipped")
+	}
+
+	log.Body = map[string]any{"padding": strings.Repeat("z", 200), "result": "failed"}
+	short, err := NewPatternMatcherWithOptions(PatternMatcherOptions{MaxBodyBytes: 100})
+	if err != nil {
+		t.Fatalf("NewPatternMatcherWithOptions failed: %v", err)
+	}
+	if got := short.AnalyzeLog(log).DerivedSeverity; got == "error" {
+		t.Error("expected keyword past max bytes to be skipped")
+	}
+
+	for _, opts := range []PatternMatcherOptions{{MaxBodyBytes: -1}, {MaxBodyDepth: -1}} {
+		if _, err := NewPatternMatcherWithOptions(opts); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+)
+
+// Defaults for the body text pattern analysis matches against.
+const (
+	// DefaultAnalysisMaxBodyBytes caps the serialized body used for
+	// matching.
+	DefaultAnalysisMaxBodyBytes = 64 << 10
+
+	// DefaultAnalysisMaxBodyDepth caps how deeply nested body objects and
+	// arrays are serialized for matching.
+	DefaultAnalysisMaxBodyDepth = 10
+)
+
+// elidedValue replaces objects and arrays nested beyond the depth limit.
+const elidedValue = `"…"`
+
+// textLimits bounds the body text analysis matches against, so the time
+// spent on a log does not grow with its payload. The stored body is never
+// affected.
+type textLimits struct {
+	maxBytes int
+	maxDepth int
+}
+
+// defaultTextLimits returns the limits used when none are configured.
+func defaultTextLimits() textLimits {
+	return textLimits{maxBytes: DefaultAnalysisMaxBodyBytes, maxDepth: DefaultAnalysisMaxBodyDepth}
+}
+
+// searchableText combines the title, description and serialized body of log
+// into the text patterns are matched against. The body is serialized as
+// JSON with sorted keys, like json.Marshal, until it reaches limits.maxBytes;
+// objects and arrays nested deeper than limits.maxDepth are elided.
+func searchableText(log *entities.Log, limits textLimits) string {
+	var parts []string
+
+	parts = append(parts, log.Header.Title)
+
+	if log.Header.Description != "" {
+		parts = append(parts, log.Header.Description)
+	}
+
+	if len(log.Body) > 0 {
+		w := boundedJSON{limits: limits}
+		w.value(log.Body, 0)
+		parts = append(parts, w.String())
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// boundedJSON serializes values as JSON within textLimits. Writing stops
+// once the byte limit is reached, so the remainder of a large body is never
+// visited.
+type boundedJSON struct {
+	buf    bytes.Buffer
+	limits textLimits
+}
+
+// full reports whether the byte limit has been reached.
+func (w *boundedJSON) full() bool {
+	return w.buf.Len() >= w.limits.maxBytes
+}
+
+// value writes v at the given nesting depth.
+func (w *boundedJSON) value(v any, depth int) {
+	if w.full() {
+		return
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		if depth >= w.limits.maxDepth {
+			w.buf.WriteString(elidedValue)
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		w.buf.WriteByte('{')
+		for i, key := range keys {
+			if w.full() {
+				return
+			}
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+			w.scalar(key)
+			w.buf.WriteByte(':')
+			w.value(v[key], depth+1)
+		}
+		w.buf.WriteByte('}')
+	case []any:
+		if depth >= w.limits.maxDepth {
+			w.buf.WriteString(elidedValue)
+			return
+		}
+		w.buf.WriteByte('[')
+		for i, item := range v {
+			if w.full() {
+				return
+			}
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+			w.value(item, depth+1)
+		}
+		w.buf.WriteByte(']')
+	default:
+		w.scalar(v)
+	}
+}
+
+// scalar writes v with json.Marshal; values that cannot be marshaled are
+// written as null. Strings longer than the remaining budget are cut first,
+// so a single huge value is not escaped in full.
+func (w *boundedJSON) scalar(v any) {
+	if str, ok := v.(string); ok {
+		if remaining := w.limits.maxBytes - w.buf.Len(); len(str) > remaining {
+			v = str[:max(remaining, 0)]
+		}
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		data = []byte("null")
+	}
+	w.buf.Write(data)
+}
+
+// String returns the serialized text, cut to the byte limit without
+// splitting a rune.
+func (w *boundedJSON) String() string {
+	data := w.buf.Bytes()
+	if len(data) > w.limits.maxBytes {
+		data = data[:w.limits.maxBytes]
+		for len(data) > 0 {
+			if r, size := utf8.DecodeLastRune(data); r != utf8.RuneError || size > 1 {
+				break
+			}
+			data = data[:len(data)-1]
+		}
+	}
+	return string(data)
+}
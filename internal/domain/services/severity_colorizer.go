@@ -0,0 +1,47 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+)
+
+// SeverityColorizer picks a color for logs sent without one from their
+// effective severity, so a critical log is not shown in an arbitrary color.
+type SeverityColorizer struct {
+	overrides map[valueobjects.Severity]valueobjects.Color
+}
+
+// NewSeverityColorizer creates a colorizer using the built-in mapping of
+// valueobjects.AutoAssignColor with overrides (severity to color name)
+// merged over it. It returns an error for a non-standard severity or an
+// invalid color.
+func NewSeverityColorizer(overrides map[string]string) (*SeverityColorizer, error) {
+	severities := make([]string, 0, len(overrides))
+	for severity := range overrides {
+		severities = append(severities, severity)
+	}
+	sort.Strings(severities)
+
+	c := &SeverityColorizer{overrides: make(map[valueobjects.Severity]valueobjects.Color, len(overrides))}
+	for _, severity := range severities {
+		if valueobjects.Severity(severity).Rank() == 0 {
+			return nil, fmt.Errorf("%q is not a standard severity", severity)
+		}
+		color := valueobjects.ColorFromString(overrides[severity])
+		if color == "" {
+			return nil, fmt.Errorf("severity %s: %q is not a valid color", severity, overrides[severity])
+		}
+		c.overrides[valueobjects.Severity(severity)] = color
+	}
+	return c, nil
+}
+
+// Color returns the color for logs of the given severity.
+func (c *SeverityColorizer) Color(severity valueobjects.Severity) valueobjects.Color {
+	if color, ok := c.overrides[severity]; ok {
+		return color
+	}
+	return valueobjects.AutoAssignColor(severity)
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+)
+
+func TestSeverityColorizer_Color(t *testing.T) {
+	builtIn, err := NewSeverityColorizer(nil)
+	if err != nil {
+		t.Fatalf("NewSeverityColorizer failed: %v", err)
+	}
+	custom, err := NewSeverityColorizer(map[string]string{"critical": "Rose", "warning": "amber"})
+	if err != nil {
+		t.Fatalf("NewSeverityColorizer failed: %v", err)
+	}
+
+	tests := []struct {
+		severity   valueobjects.Severity
+		builtIn    valueobjects.Color
+		withCustom valueobjects.Color
+	}{
+		{valueobjects.SeverityCritical, "red", "rose"},
+		{valueobjects.SeverityError, "red", "red"},
+		{valueobjects.SeverityWarning, "yellow", "amber"},
+		{valueobjects.SeverityInfo, "blue", "blue"},
+		{valueobjects.SeverityDebug, "gray", "gray"},
+	}
+	for _, tt := range tests {
+		if got := builtIn.Color(tt.severity); got != tt.builtIn {
+			t.Errorf("%s: expected built-in %q, got %q", tt.severity, tt.builtIn, got)
+		}
+		if got := custom.Color(tt.severity); got != tt.withCustom {
+			t.Errorf("%s: expected override %q, got %q", tt.severity, tt.withCustom, got)
+		}
+	}
+}
+
+func TestNewSeverityColorizer_Invalid(t *testing.T) {
+	tests := []struct {
+		overrides map[string]string
+		wantErr   string
+	}{
+		{map[string]string{"fatal": "red"}, `"fatal" is not a standard severity`},
+		{map[string]string{"error": "crimson"}, `severity error: "crimson" is not a valid color`},
+	}
+	for _, tt := range tests {
+		_, err := NewSeverityColorizer(tt.overrides)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%v: expected error containing %q, got %v", tt.overrides, tt.wantErr, err)
+		}
+	}
+}
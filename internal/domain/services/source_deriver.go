@@ -1,18 +1,20 @@
 package services
 
 import (
-	"encoding/json"
 	"strings"
 
 	"github.com/mx-scribe/scribe/internal/domain/entities"
 )
 
 // SourceDeriver intelligently derives service names from log content.
-type SourceDeriver struct{}
+type SourceDeriver struct {
+	// textLimits bounds the serialized body matched against.
+	textLimits textLimits
+}
 
 // NewSourceDeriver creates a new source deriver service.
 func NewSourceDeriver() *SourceDeriver {
-	return &SourceDeriver{}
+	return &SourceDeriver{textLimits: defaultTextLimits()}
 }
 
 // DeriveSource intelligently extracts or derives the source/service name from log content.
@@ -33,7 +35,7 @@ func (sd *SourceDeriver) DeriveSource(log *entities.Log) string {
 	}
 
 	// 4. Smart extraction from all content
-	allText := sd.getSearchableText(log)
+	allText := searchableText(log, sd.textLimits)
 	return sd.smartSourceExtraction(allText)
 }
 
@@ -73,25 +75,6 @@ func (sd *SourceDeriver) extractFromTitlePrefix(title string) string {
 	return ""
 }
 
-// getSearchableText combines all text content from the log.
-func (sd *SourceDeriver) getSearchableText(log *entities.Log) string {
-	var parts []string
-
-	parts = append(parts, log.Header.Title)
-
-	if log.Header.Description != "" {
-		parts = append(parts, log.Header.Description)
-	}
-
-	if len(log.Body) > 0 {
-		if bodyJSON, err := json.Marshal(log.Body); err == nil {
-			parts = append(parts, string(bodyJSON))
-		}
-	}
-
-	return strings.Join(parts, " ")
-}
-
 // smartSourceExtraction intelligently derives service names from log content.
 func (sd *SourceDeriver) smartSourceExtraction(allText string) string {
 	textLower := strings.ToLower(allText)
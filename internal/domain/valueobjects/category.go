@@ -24,6 +24,22 @@ var validCategories = map[Category]bool{
 	CategoryGeneral:     true,
 }
 
+// orderedCategories lists the categories in display order.
+var orderedCategories = []Category{
+	CategoryHTTP,
+	CategoryDatabase,
+	CategorySecurity,
+	CategoryPerformance,
+	CategoryBusiness,
+	CategorySystem,
+	CategoryGeneral,
+}
+
+// AllCategories returns every valid category in display order.
+func AllCategories() []Category {
+	return append([]Category(nil), orderedCategories...)
+}
+
 // IsValid checks if the category is valid.
 func (c Category) IsValid() bool {
 	return validCategories[c]
@@ -80,3 +80,15 @@ func TestCategoryFromString(t *testing.T) {
 		})
 	}
 }
+
+func TestAllCategories(t *testing.T) {
+	got := AllCategories()
+	if len(got) != len(validCategories) {
+		t.Fatalf("AllCategories() returned %d categories, want %d", len(got), len(validCategories))
+	}
+	for _, c := range got {
+		if !c.IsValid() {
+			t.Errorf("AllCategories() returned invalid category %q", c)
+		}
+	}
+}
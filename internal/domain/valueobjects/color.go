@@ -22,6 +22,24 @@ var validColorsMap = func() map[string]bool {
 	return m
 }()
 
+// colorHex maps each valid color to the hex value of its Tailwind CSS 500
+// shade, the shade the dashboard draws it with.
+var colorHex = map[Color]string{
+	"slate": "#64748b", "gray": "#6b7280", "zinc": "#71717a", "neutral": "#737373", "stone": "#78716c",
+	"red": "#ef4444", "orange": "#f97316", "amber": "#f59e0b", "yellow": "#eab308", "lime": "#84cc16", "green": "#22c55e",
+	"emerald": "#10b981", "teal": "#14b8a6", "cyan": "#06b6d4", "sky": "#0ea5e9", "blue": "#3b82f6", "indigo": "#6366f1",
+	"violet": "#8b5cf6", "purple": "#a855f7", "fuchsia": "#d946ef", "pink": "#ec4899", "rose": "#f43f5e",
+}
+
+// hexColors maps each palette hex value back to its color name.
+var hexColors = func() map[string]Color {
+	m := make(map[string]Color, len(colorHex))
+	for c, hex := range colorHex {
+		m[hex] = c
+	}
+	return m
+}()
+
 // IsValid checks if the color is a valid Tailwind CSS color.
 func (c Color) IsValid() bool {
 	return validColorsMap[strings.ToLower(string(c))]
@@ -32,6 +50,25 @@ func (c Color) String() string {
 	return string(c)
 }
 
+// Hex returns the palette hex value of the color, e.g. "#ef4444" for red,
+// or "" if the color is not valid.
+func (c Color) Hex() string {
+	return colorHex[Color(strings.ToLower(string(c)))]
+}
+
+// ParseColor returns the color named by s or by its palette hex value
+// ("#ef4444" or "ef4444", any case), or empty if s is neither.
+func ParseColor(s string) Color {
+	if color := ColorFromString(s); color != "" {
+		return color
+	}
+	hex := strings.ToLower(s)
+	if !strings.HasPrefix(hex, "#") {
+		hex = "#" + hex
+	}
+	return hexColors[hex]
+}
+
 // ColorFromString creates a Color from a string, returns empty if invalid.
 func ColorFromString(s string) Color {
 	color := Color(strings.ToLower(s))
@@ -47,6 +47,47 @@ func TestColorFromString(t *testing.T) {
 	}
 }
 
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Color
+	}{
+		{"red", "red"},
+		{"Rose", "rose"},
+		{"#ef4444", "red"},
+		{"#EF4444", "red"},
+		{"ef4444", "red"},
+		{"#3b82f6", "blue"},
+		{"#ff0000", ""},
+		{"invalid", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ParseColor(tt.input); got != tt.want {
+				t.Errorf("ParseColor(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColor_Hex(t *testing.T) {
+	for _, name := range ValidColors {
+		hex := Color(name).Hex()
+		if len(hex) != 7 || hex[0] != '#' {
+			t.Errorf("%s: expected a #rrggbb hex value, got %q", name, hex)
+			continue
+		}
+		if got := ParseColor(hex); got != Color(name) {
+			t.Errorf("%s: hex %s parses back as %q", name, hex, got)
+		}
+	}
+	if hex := Color("invalid").Hex(); hex != "" {
+		t.Errorf("expected no hex for an invalid color, got %q", hex)
+	}
+}
+
 func TestAutoAssignColor(t *testing.T) {
 	tests := []struct {
 		severity Severity
@@ -23,6 +23,27 @@ var standardSeverities = map[Severity]bool{
 	SeverityDebug:    true,
 }
 
+// orderedSeverities lists the standard severities from least to most severe.
+var orderedSeverities = []Severity{
+	SeverityDebug,
+	SeverityInfo,
+	SeveritySuccess,
+	SeverityWarning,
+	SeverityError,
+	SeverityCritical,
+}
+
+// severityRanks maps standard severities to their logical rank.
+// Success is informational, so it shares the info rank.
+var severityRanks = map[Severity]int{
+	SeverityDebug:    1,
+	SeverityInfo:     2,
+	SeveritySuccess:  2,
+	SeverityWarning:  3,
+	SeverityError:    4,
+	SeverityCritical: 5,
+}
+
 // IsValid checks if the severity is non-empty (all custom severities are valid).
 func (s Severity) IsValid() bool {
 	return s != ""
@@ -33,6 +54,19 @@ func (s Severity) IsStandard() bool {
 	return standardSeverities[s]
 }
 
+// Rank returns the logical rank of the severity (higher is more severe).
+// Custom severities have no rank and return 0.
+func (s Severity) Rank() int {
+	return severityRanks[s]
+}
+
+// AtLeast reports whether s ranks at or above other. Severities without a
+// rank never compare, so AtLeast is false when either is custom.
+func (s Severity) AtLeast(other Severity) bool {
+	rank, otherRank := s.Rank(), other.Rank()
+	return rank != 0 && otherRank != 0 && rank >= otherRank
+}
+
 // String returns the string representation of the severity.
 func (s Severity) String() string {
 	return string(s)
@@ -52,3 +86,24 @@ func SeverityFromString(s string) Severity {
 	}
 	return Severity(s)
 }
+
+// StandardSeverities returns the standard severities from least to most severe.
+func StandardSeverities() []Severity {
+	return append([]Severity(nil), orderedSeverities...)
+}
+
+// SeveritiesAtLeast returns the standard severities ranked at or above min.
+// Returns nil if min is not a standard severity.
+func SeveritiesAtLeast(min Severity) []Severity {
+	if min.Rank() == 0 {
+		return nil
+	}
+
+	var result []Severity
+	for _, s := range orderedSeverities {
+		if s.AtLeast(min) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
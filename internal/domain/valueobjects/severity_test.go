@@ -92,3 +92,105 @@ func TestSeverity_String(t *testing.T) {
 		})
 	}
 }
+
+func TestSeverity_Rank(t *testing.T) {
+	if SeverityDebug.Rank() >= SeverityInfo.Rank() {
+		t.Error("expected debug to rank below info")
+	}
+	if SeverityInfo.Rank() != SeveritySuccess.Rank() {
+		t.Error("expected success to share the info rank")
+	}
+	if SeverityWarning.Rank() >= SeverityError.Rank() || SeverityError.Rank() >= SeverityCritical.Rank() {
+		t.Error("expected warning < error < critical")
+	}
+	if Severity("custom").Rank() != 0 {
+		t.Error("expected custom severity to have rank 0")
+	}
+}
+
+func TestSeverity_RankOrdering(t *testing.T) {
+	// Each severity ranks strictly above the previous group; success is
+	// informational and shares the info rank.
+	ordering := [][]Severity{
+		{SeverityDebug},
+		{SeverityInfo, SeveritySuccess},
+		{SeverityWarning},
+		{SeverityError},
+		{SeverityCritical},
+	}
+	for i, group := range ordering {
+		for _, s := range group {
+			if s.Rank() != group[0].Rank() {
+				t.Errorf("expected %q to share the rank of %q", s, group[0])
+			}
+			if i > 0 && s.Rank() <= ordering[i-1][0].Rank() {
+				t.Errorf("expected %q to rank above %q", s, ordering[i-1][0])
+			}
+		}
+	}
+}
+
+func TestSeverity_AtLeast(t *testing.T) {
+	tests := []struct {
+		s, other Severity
+		want     bool
+	}{
+		{SeverityWarning, SeverityWarning, true},
+		{SeverityError, SeverityWarning, true},
+		{SeverityInfo, SeverityWarning, false},
+		{SeverityCritical, SeverityError, true},
+		{SeverityError, SeverityCritical, false},
+		{SeverityDebug, SeverityDebug, true},
+		{SeverityDebug, SeverityInfo, false},
+		{SeveritySuccess, SeverityInfo, true},
+		{SeverityInfo, SeveritySuccess, true},
+		{Severity("custom"), SeverityDebug, false},
+		{SeverityCritical, Severity("custom"), false},
+		{Severity("custom"), Severity("custom"), false},
+	}
+	for _, tt := range tests {
+		if got := tt.s.AtLeast(tt.other); got != tt.want {
+			t.Errorf("%q.AtLeast(%q) = %v, want %v", tt.s, tt.other, got, tt.want)
+		}
+	}
+}
+
+func TestSeveritiesAtLeast(t *testing.T) {
+	got := SeveritiesAtLeast(SeverityWarning)
+	want := []Severity{SeverityWarning, SeverityError, SeverityCritical}
+	if len(got) != len(want) {
+		t.Fatalf("SeveritiesAtLeast(warning) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("SeveritiesAtLeast(warning)[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if got := SeveritiesAtLeast(SeverityInfo); len(got) != 5 {
+		t.Errorf("expected 5 severities at least info, got %v", got)
+	}
+	if got := SeveritiesAtLeast(Severity("custom")); got != nil {
+		t.Errorf("expected nil for custom severity, got %v", got)
+	}
+}
+
+func TestStandardSeverities(t *testing.T) {
+	got := StandardSeverities()
+	if len(got) != len(standardSeverities) {
+		t.Fatalf("StandardSeverities() returned %d severities, want %d", len(got), len(standardSeverities))
+	}
+	for i, s := range got {
+		if !s.IsStandard() {
+			t.Errorf("StandardSeverities() returned non-standard severity %q", s)
+		}
+		if i > 0 && s.Rank() < got[i-1].Rank() {
+			t.Errorf("rank of %q is below rank of %q", s, got[i-1])
+		}
+	}
+
+	got[0] = "mutated"
+	if StandardSeverities()[0] != SeverityDebug {
+		t.Error("expected StandardSeverities to return a copy")
+	}
+}
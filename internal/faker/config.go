@@ -2,6 +2,10 @@ package faker
 
 import "time"
 
+// DefaultLatencySamples is the number of request latencies kept for
+// percentiles when Config.LatencySamples is not set.
+const DefaultLatencySamples = 10000
+
 // Config holds the configuration for the faker.
 type Config struct {
 	// Connection
@@ -25,6 +29,17 @@ type Config struct {
 	// Stress mode
 	StressRate int
 
+	// LatencySamples caps the latencies kept for percentiles, so memory
+	// stays constant however long a run lasts. Past it, a uniform random
+	// sample of all latencies is kept. Zero uses DefaultLatencySamples.
+	LatencySamples int
+
+	// Delivery: transient failures (connection errors, 429, 5xx) are
+	// retried up to MaxRetries times with exponential backoff.
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
 	// Reproducibility
 	Seed int64
 
@@ -35,22 +50,29 @@ type Config struct {
 // DefaultConfig returns a config with sensible defaults.
 func DefaultConfig() Config {
 	return Config{
-		Endpoint:   "http://localhost:8080",
-		MinDelay:   3 * time.Second,
-		MaxDelay:   30 * time.Second,
-		Duration:   0, // infinite
-		Count:      0, // infinite
-		Chaos:      false,
-		Stress:     false,
-		StressRate: 100,
-		DryRun:     false,
-		Seed:       0, // random
-		Categories: nil,
-		Quiet:      false,
-		Verbose:    false,
+		Endpoint:       "http://localhost:8080",
+		MinDelay:       3 * time.Second,
+		MaxDelay:       30 * time.Second,
+		Duration:       0, // infinite
+		Count:          0, // infinite
+		Chaos:          false,
+		Stress:         false,
+		StressRate:     100,
+		LatencySamples: DefaultLatencySamples,
+		MaxRetries:     3,
+		RetryBaseDelay: 100 * time.Millisecond,
+		RetryMaxDelay:  5 * time.Second,
+		DryRun:         false,
+		Seed:           0, // random
+		Categories:     nil,
+		Quiet:          false,
+		Verbose:        false,
 	}
 }
 
+// Categories lists the categories accepted by Config.Categories.
+var Categories = []string{"http", "application", "database", "security", "system", "business", "chaos"}
+
 // Category distribution weights (must sum to 100).
 const (
 	WeightHTTP        = 25
@@ -0,0 +1,63 @@
+package faker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// ErrorClass is the category of a failed send, used to tell a struggling
+// server (5xx, timeouts) from a misconfigured client (4xx, refused
+// connections).
+type ErrorClass string
+
+// Failure categories recorded in Stats.
+const (
+	ErrorConnectionRefused ErrorClass = "connection refused"
+	ErrorTimeout           ErrorClass = "timeout"
+	ErrorClient            ErrorClass = "4xx"
+	ErrorServer            ErrorClass = "5xx"
+	ErrorOther             ErrorClass = "other"
+)
+
+// ErrorClasses lists every ErrorClass in the order summaries print them.
+var ErrorClasses = []ErrorClass{
+	ErrorConnectionRefused,
+	ErrorTimeout,
+	ErrorClient,
+	ErrorServer,
+	ErrorOther,
+}
+
+// httpStatusError is an HTTP error response from the API.
+type httpStatusError struct {
+	code int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d", e.code)
+}
+
+// classifyError returns the category of a send failure.
+func classifyError(err error) ErrorClass {
+	var status *httpStatusError
+	if errors.As(err, &status) {
+		if status.code >= 500 {
+			return ErrorServer
+		}
+		return ErrorClient
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorConnectionRefused
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorTimeout
+	}
+
+	return ErrorOther
+}
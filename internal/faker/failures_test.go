@@ -0,0 +1,124 @@
+package faker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFaker_ErrorBreakdown(t *testing.T) {
+	statusServer := func(status int) string {
+		srv, _ := flakyServer(t, 1<<30, status, nil)
+		return srv.URL
+	}
+
+	tests := []struct {
+		name     string
+		endpoint func() string
+		timeout  time.Duration
+		want     ErrorClass
+	}{
+		{"bad request", func() string { return statusServer(http.StatusBadRequest) }, 0, ErrorClient},
+		{"too many requests", func() string { return statusServer(http.StatusTooManyRequests) }, 0, ErrorClient},
+		{"internal error", func() string { return statusServer(http.StatusInternalServerError) }, 0, ErrorServer},
+		{"unavailable", func() string { return statusServer(http.StatusServiceUnavailable) }, 0, ErrorServer},
+		{"connection refused", func() string {
+			srv := httptest.NewServer(http.NotFoundHandler())
+			srv.Close()
+			return srv.URL
+		}, 0, ErrorConnectionRefused},
+		{"timeout", func() string {
+			release := make(chan struct{})
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-release:
+				case <-r.Context().Done():
+				}
+			}))
+			t.Cleanup(srv.Close)
+			t.Cleanup(func() { close(release) })
+			return srv.URL
+		}, 20 * time.Millisecond, ErrorTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := retryTestConfig(tt.endpoint())
+			cfg.MaxRetries = 0
+			sink := NewHTTPSink(cfg)
+			if tt.timeout > 0 {
+				sink.client.Timeout = tt.timeout
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			f := New(cfg)
+			_ = f.Run(ctx, sink, nil)
+
+			errs := f.Stats().Errors.Load()
+			if errs == 0 {
+				t.Fatal("expected failed sends")
+			}
+			breakdown := f.Stats().ErrorBreakdown()
+			if len(breakdown) != 1 || breakdown[tt.want] != errs {
+				t.Errorf("expected all %d errors as %q, got %v", errs, tt.want, breakdown)
+			}
+		})
+	}
+}
+
+func TestFaker_ErrorBreakdownStress(t *testing.T) {
+	// Cycle through success, 400 and 503
+	var hits atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch hits.Add(1) % 3 {
+		case 1:
+			w.WriteHeader(http.StatusCreated)
+		case 2:
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := retryTestConfig(srv.URL)
+	cfg.MaxRetries = 0
+	cfg.Count = 20
+	cfg.StressRate = 1000
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	f := New(cfg)
+	if err := f.RunStress(ctx, NewHTTPSink(cfg), nil); err != nil {
+		t.Fatalf("RunStress failed: %v", err)
+	}
+
+	stats := f.Stats()
+	breakdown := stats.ErrorBreakdown()
+	if breakdown[ErrorClient] == 0 || breakdown[ErrorServer] == 0 {
+		t.Errorf("expected both 4xx and 5xx failures, got %v", breakdown)
+	}
+	if got := breakdown[ErrorClient] + breakdown[ErrorServer]; got != stats.Errors.Load() || len(breakdown) != 2 {
+		t.Errorf("expected breakdown to add up to %d errors, got %v", stats.Errors.Load(), breakdown)
+	}
+	if got := stats.Sent.Load() + stats.Errors.Load(); got != hits.Load() {
+		t.Errorf("expected %d requests accounted for, got %d", hits.Load(), got)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	if got := classifyError(errors.New("rejected")); got != ErrorOther {
+		t.Errorf("expected %q for a sink error, got %q", ErrorOther, got)
+	}
+	if got := classifyError(context.DeadlineExceeded); got != ErrorTimeout {
+		t.Errorf("expected %q for a deadline, got %q", ErrorTimeout, got)
+	}
+	if got := classifyError(&retryableError{err: &httpStatusError{code: 502}}); got != ErrorServer {
+		t.Errorf("expected %q for a wrapped 502, got %q", ErrorServer, got)
+	}
+}
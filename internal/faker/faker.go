@@ -1,34 +1,87 @@
 package faker
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
+	"errors"
+	"math/rand/v2"
 	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
-// Stats tracks faker statistics.
+// Stats tracks faker statistics. Latencies are kept in a bounded reservoir
+// (see Config.LatencySamples), so percentiles are estimates once more
+// requests than that have been made; Max stays exact.
 type Stats struct {
 	Sent      atomic.Int64
 	Errors    atomic.Int64
 	StartTime time.Time
 	mu        sync.Mutex
 	latencies []time.Duration
+	samples   int
+	observed  int64
+	max       time.Duration
+	failures  map[ErrorClass]int64
 }
 
-// AddLatency records a request latency.
-func (s *Stats) AddLatency(d time.Duration) {
+// interrupted reports whether a send failed only because ctx ended while it
+// was in flight, which is the run stopping rather than a delivery failure.
+func interrupted(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err())
+}
+
+// recordError counts a failed send, both in Errors and in its class.
+func (s *Stats) recordError(err error) {
+	s.Errors.Add(1)
+
+	class := classifyError(err)
 	s.mu.Lock()
-	s.latencies = append(s.latencies, d)
+	if s.failures == nil {
+		s.failures = make(map[ErrorClass]int64)
+	}
+	s.failures[class]++
 	s.mu.Unlock()
 }
 
+// ErrorBreakdown returns the number of failed sends per ErrorClass. Classes
+// without failures are omitted; the counts add up to Errors.
+func (s *Stats) ErrorBreakdown() map[ErrorClass]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	breakdown := make(map[ErrorClass]int64, len(s.failures))
+	for class, n := range s.failures {
+		breakdown[class] = n
+	}
+	return breakdown
+}
+
+// AddLatency records a request latency. Once the reservoir is full, d
+// replaces a random sample with the probability that keeps the reservoir a
+// uniform sample of every latency recorded (Algorithm R).
+func (s *Stats) AddLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.observed++
+	if d > s.max {
+		s.max = d
+	}
+
+	size := s.samples
+	if size <= 0 {
+		size = DefaultLatencySamples
+	}
+	if len(s.latencies) < size {
+		s.latencies = append(s.latencies, d)
+		return
+	}
+	if i := rand.Int64N(s.observed); i < int64(size) { //nolint:gosec // Sampling, not for cryptographic use
+		s.latencies[i] = d
+	}
+}
+
 // Percentile returns the nth percentile latency.
 func (s *Stats) Percentile(n int) time.Duration {
 	s.mu.Lock()
@@ -53,14 +106,7 @@ func (s *Stats) Percentile(n int) time.Duration {
 func (s *Stats) Max() time.Duration {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-
-	var max time.Duration
-	for _, d := range s.latencies {
-		if d > max {
-			max = d
-		}
-	}
-	return max
+	return s.max
 }
 
 // Rate returns logs per second.
@@ -72,10 +118,13 @@ func (s *Stats) Rate() float64 {
 	return float64(s.Sent.Load()) / elapsed
 }
 
-// Faker generates and sends fake logs.
+// ErrNoSink is returned by Run and RunStress when no sink is given outside of
+// a dry run.
+var ErrNoSink = errors.New("faker: no sink to send logs to")
+
+// Faker generates fake logs and sends them to a Sink.
 type Faker struct {
 	config    Config
-	client    *http.Client
 	generator *Generator
 	stats     *Stats
 }
@@ -83,12 +132,9 @@ type Faker struct {
 // New creates a new Faker.
 func New(cfg Config) *Faker {
 	return &Faker{
-		config: cfg,
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		config:    cfg,
 		generator: NewGenerator(cfg.Seed, cfg.Chaos),
-		stats:     &Stats{StartTime: time.Now()},
+		stats:     &Stats{StartTime: time.Now(), samples: cfg.LatencySamples},
 	}
 }
 
@@ -97,8 +143,13 @@ func (f *Faker) Stats() *Stats {
 	return f.stats
 }
 
-// Run executes the faker in realistic mode.
-func (f *Faker) Run(ctx context.Context, onLog func(LogEntry, time.Duration, error)) error {
+// Run executes the faker in realistic mode, sending each log to sink. The
+// sink may be nil for a dry run.
+func (f *Faker) Run(ctx context.Context, sink Sink, onLog func(LogEntry, time.Duration, error)) error {
+	if sink == nil && !f.config.DryRun {
+		return ErrNoSink
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -113,10 +164,13 @@ func (f *Faker) Run(ctx context.Context, onLog func(LogEntry, time.Duration, err
 
 		// Generate and send log
 		log := f.generateLog()
-		err := f.sendLog(log)
+		err := f.sendLog(ctx, sink, log)
+		if interrupted(ctx, err) {
+			return ctx.Err()
+		}
 
 		if err != nil {
-			f.stats.Errors.Add(1)
+			f.stats.recordError(err)
 		} else {
 			f.stats.Sent.Add(1)
 		}
@@ -137,8 +191,14 @@ func (f *Faker) Run(ctx context.Context, onLog func(LogEntry, time.Duration, err
 	}
 }
 
-// RunStress executes the faker in stress test mode.
-func (f *Faker) RunStress(ctx context.Context, onProgress func(sent, errors int64, rate float64, p95 time.Duration)) error {
+// RunStress executes the faker in stress test mode, sending logs to sink
+// concurrently. The sink must be safe for concurrent use; it may be nil for
+// a dry run.
+func (f *Faker) RunStress(ctx context.Context, sink Sink, onProgress func(sent, errors int64, rate float64, p95 time.Duration)) error {
+	if sink == nil && !f.config.DryRun {
+		return ErrNoSink
+	}
+
 	interval := time.Second / time.Duration(f.config.StressRate)
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
@@ -176,13 +236,16 @@ func (f *Faker) RunStress(ctx context.Context, onProgress func(sent, errors int6
 
 				log := f.generateLog()
 				start := time.Now()
-				err := f.sendLog(log)
+				err := f.sendLog(ctx, sink, log)
 				latency := time.Since(start)
+				if interrupted(ctx, err) {
+					return
+				}
 
 				f.stats.AddLatency(latency)
 
 				if err != nil {
-					f.stats.Errors.Add(1)
+					f.stats.recordError(err)
 				} else {
 					f.stats.Sent.Add(1)
 				}
@@ -201,38 +264,16 @@ func (f *Faker) generateLog() LogEntry {
 	return f.generator.Generate()
 }
 
-// sendLog sends a log to the API endpoint.
-func (f *Faker) sendLog(log LogEntry) error {
+// sendLog delivers a log to sink. Dry runs skip delivery. Sinks that accept
+// a context are given ctx so cancellation interrupts a pending retry.
+func (f *Faker) sendLog(ctx context.Context, sink Sink, log LogEntry) error {
 	if f.config.DryRun {
 		return nil
 	}
-
-	body, err := json.Marshal(log)
-	if err != nil {
-		return err
-	}
-
-	url := f.config.Endpoint + "/api/logs"
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := f.client.Do(req)
-	if err != nil {
-		return err
+	if cs, ok := sink.(contextSink); ok {
+		return cs.SendContext(ctx, log)
 	}
-	defer resp.Body.Close()
-
-	// Drain body to reuse connection
-	_, _ = io.Copy(io.Discard, resp.Body)
-
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("HTTP %d", resp.StatusCode)
-	}
-
-	return nil
+	return sink.Send(log)
 }
 
 // randomDelay returns a random delay between min and max.
@@ -194,7 +194,7 @@ func TestFaker_DryRun(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err := f.Run(ctx, nil)
+	err := f.Run(ctx, nil, nil)
 	if err != nil && err != context.DeadlineExceeded {
 		t.Errorf("Dry run should not fail: %v", err)
 	}
@@ -218,7 +218,7 @@ func TestFaker_CountLimit(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err := f.Run(ctx, nil)
+	err := f.Run(ctx, nil, nil)
 	if err != nil {
 		t.Errorf("Count limited run should not fail: %v", err)
 	}
@@ -242,7 +242,7 @@ func TestFaker_IntervalRange(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err := f.Run(ctx, func(log LogEntry, delay time.Duration, sendErr error) {
+	err := f.Run(ctx, nil, func(log LogEntry, delay time.Duration, sendErr error) {
 		delays = append(delays, delay)
 	})
 	if err != nil {
@@ -290,6 +290,47 @@ func TestStats_Percentile(t *testing.T) {
 	}
 }
 
+func TestStats_LatencyReservoirBounded(t *testing.T) {
+	s := &Stats{samples: 1000}
+
+	const n = 200000
+	for i := 1; i <= n; i++ {
+		s.AddLatency(time.Duration(i) * time.Microsecond)
+	}
+
+	if len(s.latencies) != 1000 || cap(s.latencies) > 2048 {
+		t.Errorf("expected 1000 samples in a bounded buffer, got len=%d cap=%d", len(s.latencies), cap(s.latencies))
+	}
+	if s.observed != n {
+		t.Errorf("expected %d latencies observed, got %d", n, s.observed)
+	}
+	if got := s.Max(); got != n*time.Microsecond {
+		t.Errorf("expected exact max %v, got %v", n*time.Microsecond, got)
+	}
+}
+
+func TestStats_LatencyReservoirPercentiles(t *testing.T) {
+	s := &Stats{}
+
+	// Uniform latencies 1..100000µs, added in increasing order so a
+	// reservoir biased toward early or late samples would show
+	const n = 100000
+	for i := 1; i <= n; i++ {
+		s.AddLatency(time.Duration(i) * time.Microsecond)
+	}
+
+	if len(s.latencies) != DefaultLatencySamples {
+		t.Errorf("expected %d samples, got %d", DefaultLatencySamples, len(s.latencies))
+	}
+	for _, p := range []int{50, 95, 99} {
+		exact := time.Duration(n*p/100) * time.Microsecond
+		got := s.Percentile(p)
+		if diff := (got - exact).Abs(); diff > n/50*time.Microsecond {
+			t.Errorf("p%d: expected about %v, got %v", p, exact, got)
+		}
+	}
+}
+
 func TestRandomHelpers(t *testing.T) {
 	g := NewGenerator(12345, false)
 
@@ -0,0 +1,94 @@
+package faker_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/application/commands"
+	"github.com/mx-scribe/scribe/internal/faker"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// repositorySink stores generated logs through the create log command, the
+// same path POST /api/logs takes, without going over HTTP.
+func repositorySink(t *testing.T, repo *sqlite.LogRepository) faker.Sink {
+	t.Helper()
+	handler := commands.NewCreateLogHandler(repo)
+	return faker.SinkFunc(func(log faker.LogEntry) error {
+		data, err := json.Marshal(log)
+		if err != nil {
+			return err
+		}
+		var input struct {
+			Header commands.CreateLogInput `json:"header"`
+			Body   map[string]any          `json:"body"`
+		}
+		if err := json.Unmarshal(data, &input); err != nil {
+			return err
+		}
+		input.Header.Body = input.Body
+		_, err = handler.Handle(input.Header)
+		return err
+	})
+}
+
+func TestFaker_PopulatesRepository(t *testing.T) {
+	db, err := sqlite.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer db.Close()
+	if err := sqlite.RunMigrations(db.Conn()); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	repo := sqlite.NewLogRepository(db)
+
+	cfg := faker.DefaultConfig()
+	cfg.Count = 25
+	cfg.MinDelay = time.Millisecond
+	cfg.MaxDelay = time.Millisecond
+	cfg.Seed = 12345
+
+	memory := faker.NewMemorySink()
+	stored := repositorySink(t, repo)
+	both := faker.SinkFunc(func(log faker.LogEntry) error {
+		_ = memory.Send(log)
+		return stored.Send(log)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	f := faker.New(cfg)
+	if err := f.Run(ctx, both, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got := f.Stats().Errors.Load(); got != 0 {
+		t.Fatalf("expected every log to be stored, got %d errors", got)
+	}
+
+	count, err := repo.Count()
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if count != 25 {
+		t.Fatalf("expected 25 logs in repository, got %d", count)
+	}
+
+	logs, _, err := repo.FindAll(sqlite.LogFilters{Limit: 100})
+	if err != nil {
+		t.Fatalf("FindAll failed: %v", err)
+	}
+	titles := make(map[string]int)
+	for _, log := range logs {
+		titles[log.Header.Title]++
+	}
+	for _, log := range memory.Logs() {
+		if titles[log.Header.Title] == 0 {
+			t.Errorf("generated log %q not found in repository", log.Header.Title)
+			continue
+		}
+		titles[log.Header.Title]--
+	}
+}
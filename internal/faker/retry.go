@@ -0,0 +1,104 @@
+package faker
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retryableError marks a send failure as transient. RetryAfter carries the
+// server's Retry-After hint, if any.
+type retryableError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// statusError classifies an HTTP error status. 429 and 5xx are transient;
+// any other 4xx is permanent and returned as-is.
+func statusError(resp *http.Response) error {
+	err := &httpStatusError{code: resp.StatusCode}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return &retryableError{err: err, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+	return err
+}
+
+// parseRetryAfter reads a Retry-After header given either as delay seconds
+// or as an HTTP date. Returns 0 if the header is missing or invalid.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// backoff returns the delay before retry number attempt (starting at 0):
+// exponential from RetryBaseDelay up to RetryMaxDelay, with jitter over the
+// upper half. A longer Retry-After hint from the server wins, but is capped
+// at RetryMaxDelay too so a server cannot stall the run.
+func (s *HTTPSink) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	d := s.config.RetryBaseDelay
+	for i := 0; i < attempt && d < s.config.RetryMaxDelay; i++ {
+		d *= 2
+	}
+	if s.config.RetryMaxDelay > 0 && d > s.config.RetryMaxDelay {
+		d = s.config.RetryMaxDelay
+	}
+	if half := d / 2; half > 0 {
+		d = half + rand.N(half+1) //nolint:gosec // Jitter, not for cryptographic use
+	}
+	if s.config.RetryMaxDelay > 0 && retryAfter > s.config.RetryMaxDelay {
+		retryAfter = s.config.RetryMaxDelay
+	}
+	if retryAfter > d {
+		d = retryAfter
+	}
+	return d
+}
+
+// sendWithRetry calls send until it succeeds, fails permanently, or runs out
+// of retries. Each retry is counted in Retries.
+func (s *HTTPSink) sendWithRetry(ctx context.Context, send func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := send()
+		if err == nil {
+			return nil
+		}
+
+		var transient *retryableError
+		if !errors.As(err, &transient) || attempt >= s.config.MaxRetries {
+			return err
+		}
+
+		timer := time.NewTimer(s.backoff(attempt, transient.retryAfter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		s.retries.Add(1)
+	}
+}
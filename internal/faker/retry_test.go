@@ -0,0 +1,240 @@
+package faker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first failures requests with status, then accepts.
+func flakyServer(t *testing.T, failures int64, status int, header http.Header) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var hits atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func retryTestConfig(endpoint string) Config {
+	cfg := DefaultConfig()
+	cfg.Endpoint = endpoint
+	cfg.Count = 1
+	cfg.MinDelay = time.Millisecond
+	cfg.MaxDelay = time.Millisecond
+	cfg.Seed = 12345
+	cfg.MaxRetries = 3
+	cfg.RetryBaseDelay = time.Millisecond
+	cfg.RetryMaxDelay = 5 * time.Millisecond
+	return cfg
+}
+
+func testLogEntry() LogEntry {
+	return NewGenerator(12345, false).Generate()
+}
+
+func runOnce(t *testing.T, f *Faker, sink Sink) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = f.Run(ctx, sink, nil)
+}
+
+func TestFaker_RetriesTransientFailures(t *testing.T) {
+	srv, hits := flakyServer(t, 2, http.StatusServiceUnavailable, nil)
+
+	cfg := retryTestConfig(srv.URL)
+	f := New(cfg)
+	sink := NewHTTPSink(cfg)
+	runOnce(t, f, sink)
+
+	if got := f.Stats().Sent.Load(); got != 1 {
+		t.Errorf("expected 1 log delivered, got %d", got)
+	}
+	if got := f.Stats().Errors.Load(); got != 0 {
+		t.Errorf("expected 0 errors, got %d", got)
+	}
+	if got := sink.Retries(); got != 2 {
+		t.Errorf("expected 2 retries, got %d", got)
+	}
+	if got := hits.Load(); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+}
+
+func TestFaker_DoesNotRetryPermanentFailures(t *testing.T) {
+	srv, hits := flakyServer(t, 100, http.StatusBadRequest, nil)
+
+	cfg := retryTestConfig(srv.URL)
+	sink := NewHTTPSink(cfg)
+	if err := sink.SendContext(context.Background(), testLogEntry()); err == nil {
+		t.Fatal("expected error for 400 response")
+	}
+
+	if got := sink.Retries(); got != 0 {
+		t.Errorf("expected 0 retries, got %d", got)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("expected 1 request, got %d", got)
+	}
+}
+
+func TestFaker_GivesUpAfterMaxRetries(t *testing.T) {
+	srv, hits := flakyServer(t, 100, http.StatusInternalServerError, nil)
+
+	cfg := retryTestConfig(srv.URL)
+	cfg.MaxRetries = 2
+	sink := NewHTTPSink(cfg)
+	if err := sink.SendContext(context.Background(), testLogEntry()); err == nil {
+		t.Fatal("expected error once retries are exhausted")
+	}
+
+	if got := sink.Retries(); got != 2 {
+		t.Errorf("expected 2 retries, got %d", got)
+	}
+	if got := hits.Load(); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+}
+
+func TestFaker_HonorsRetryAfter(t *testing.T) {
+	header := http.Header{"Retry-After": []string{"1"}}
+	srv, hits := flakyServer(t, 1, http.StatusTooManyRequests, header)
+
+	cfg := retryTestConfig(srv.URL)
+	cfg.RetryMaxDelay = 2 * time.Second
+	sink := NewHTTPSink(cfg)
+	start := time.Now()
+	if err := sink.SendContext(context.Background(), testLogEntry()); err != nil {
+		t.Fatalf("expected eventual success, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected retry to wait for Retry-After, waited %v", elapsed)
+	}
+	if got := sink.Retries(); got != 1 {
+		t.Errorf("expected 1 retry, got %d", got)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("expected 2 requests, got %d", got)
+	}
+}
+
+func TestFaker_RetriesConnectionRefused(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	endpoint := srv.URL
+	srv.Close()
+
+	cfg := retryTestConfig(endpoint)
+	cfg.MaxRetries = 2
+	sink := NewHTTPSink(cfg)
+	if err := sink.SendContext(context.Background(), testLogEntry()); err == nil {
+		t.Fatal("expected error for unreachable endpoint")
+	}
+
+	if got := sink.Retries(); got != 2 {
+		t.Errorf("expected 2 retries, got %d", got)
+	}
+}
+
+func TestFaker_RetryStopsOnCancel(t *testing.T) {
+	header := http.Header{"Retry-After": []string{"60"}}
+	srv, _ := flakyServer(t, 100, http.StatusTooManyRequests, header)
+
+	sink := NewHTTPSink(retryTestConfig(srv.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := sink.SendContext(ctx, testLogEntry()); err == nil {
+		t.Fatal("expected error when cancelled during backoff")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected cancellation to interrupt backoff, took %v", elapsed)
+	}
+}
+
+func TestFaker_CancelInterruptsRequest(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	sink := NewHTTPSink(retryTestConfig(srv.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := sink.SendContext(ctx, testLogEntry()); err == nil {
+		t.Fatal("expected error when cancelled during a request")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected cancellation to interrupt the request, took %v", elapsed)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", 0}, // in the past
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got <= 0 || got > time.Hour {
+		t.Errorf("parseRetryAfter(future date) = %v, want (0, 1h]", got)
+	}
+}
+
+func TestFaker_BackoffBounds(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.RetryBaseDelay = 100 * time.Millisecond
+	cfg.RetryMaxDelay = 400 * time.Millisecond
+	sink := NewHTTPSink(cfg)
+
+	for attempt := 0; attempt < 6; attempt++ {
+		ceiling := cfg.RetryBaseDelay << attempt
+		if ceiling > cfg.RetryMaxDelay {
+			ceiling = cfg.RetryMaxDelay
+		}
+		for i := 0; i < 50; i++ {
+			d := sink.backoff(attempt, 0)
+			if d < ceiling/2 || d > ceiling {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", attempt, d, ceiling/2, ceiling)
+			}
+		}
+	}
+
+	if d := sink.backoff(0, 300*time.Millisecond); d != 300*time.Millisecond {
+		t.Errorf("expected Retry-After to override backoff, got %v", d)
+	}
+	if d := sink.backoff(0, time.Hour); d != cfg.RetryMaxDelay {
+		t.Errorf("expected Retry-After capped at %v, got %v", cfg.RetryMaxDelay, d)
+	}
+}
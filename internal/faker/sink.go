@@ -0,0 +1,183 @@
+package faker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sink receives generated logs. Sinks passed to RunStress must be safe for
+// concurrent use; all sinks in this package are.
+type Sink interface {
+	Send(LogEntry) error
+}
+
+// contextSink is implemented by sinks whose delivery can be cancelled.
+type contextSink interface {
+	SendContext(context.Context, LogEntry) error
+}
+
+// SinkFunc adapts a function to a Sink, e.g. to write generated logs
+// straight into a repository.
+type SinkFunc func(LogEntry) error
+
+// Send calls fn(log).
+func (fn SinkFunc) Send(log LogEntry) error {
+	return fn(log)
+}
+
+// HTTPSink posts logs to the SCRIBE API, retrying transient failures
+// (connection errors, 429, 5xx) as configured by Config.
+type HTTPSink struct {
+	config  Config
+	client  *http.Client
+	retries atomic.Int64
+}
+
+// NewHTTPSink creates a sink posting to cfg.Endpoint.
+func NewHTTPSink(cfg Config) *HTTPSink {
+	return &HTTPSink{
+		config: cfg,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Retries returns the number of retries made so far.
+func (s *HTTPSink) Retries() int64 {
+	return s.retries.Load()
+}
+
+// Send posts a log to the API endpoint, retrying transient failures.
+func (s *HTTPSink) Send(log LogEntry) error {
+	return s.SendContext(context.Background(), log)
+}
+
+// SendContext is Send with a context that interrupts in-flight requests and
+// retry backoff.
+func (s *HTTPSink) SendContext(ctx context.Context, log LogEntry) error {
+	body, err := json.Marshal(log)
+	if err != nil {
+		return err
+	}
+
+	return s.sendWithRetry(ctx, func() error {
+		return s.post(ctx, body)
+	})
+}
+
+// post makes a single delivery attempt. Transport failures, 429 and 5xx
+// responses are returned as retryable.
+func (s *HTTPSink) post(ctx context.Context, body []byte) error {
+	url := s.config.Endpoint + "/api/logs"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return &retryableError{err: err}
+	}
+	defer resp.Body.Close()
+
+	// Drain body to reuse connection
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 400 {
+		return statusError(resp)
+	}
+
+	return nil
+}
+
+// FileSink writes logs as newline-delimited JSON, one API request body per
+// line, so a run can be replayed later.
+type FileSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewFileSink creates (or truncates) the file at path and writes logs to it.
+// The sink must be closed to release the file.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create faker output: %w", err)
+	}
+	return &FileSink{w: file, closer: file}, nil
+}
+
+// NewWriterSink writes logs to w. Closing the sink does not close w.
+func NewWriterSink(w io.Writer) *FileSink {
+	return &FileSink{w: w}
+}
+
+// Send appends a log as one JSON line.
+func (s *FileSink) Send(log LogEntry) error {
+	line, err := json.Marshal(log)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(line)
+	return err
+}
+
+// Close closes the underlying file, if the sink opened one.
+func (s *FileSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// MemorySink keeps every log in memory, for tests that assert on what the
+// faker generated.
+type MemorySink struct {
+	mu   sync.Mutex
+	logs []LogEntry
+}
+
+// NewMemorySink creates an empty in-memory sink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+// Send records the log.
+func (s *MemorySink) Send(log LogEntry) error {
+	s.mu.Lock()
+	s.logs = append(s.logs, log)
+	s.mu.Unlock()
+	return nil
+}
+
+// Logs returns a copy of the logs received so far, in order.
+func (s *MemorySink) Logs() []LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	logs := make([]LogEntry, len(s.logs))
+	copy(logs, s.logs)
+	return logs
+}
+
+// Len returns the number of logs received so far.
+func (s *MemorySink) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.logs)
+}
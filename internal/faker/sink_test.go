@@ -0,0 +1,147 @@
+package faker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func sinkTestConfig(count int) Config {
+	cfg := DefaultConfig()
+	cfg.Count = count
+	cfg.MinDelay = time.Millisecond
+	cfg.MaxDelay = time.Millisecond
+	cfg.Seed = 12345
+	return cfg
+}
+
+func TestFaker_RunMemorySink(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sink := NewMemorySink()
+	f := New(sinkTestConfig(20))
+	if err := f.Run(ctx, sink, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if sink.Len() != 20 {
+		t.Fatalf("expected 20 logs in sink, got %d", sink.Len())
+	}
+	if got := f.Stats().Sent.Load(); got != 20 {
+		t.Errorf("expected 20 logs sent, got %d", got)
+	}
+	for i, log := range sink.Logs() {
+		if log.Header.Title == "" {
+			t.Errorf("log %d has no title", i)
+		}
+	}
+
+	// The same seed reproduces the same logs
+	again := NewMemorySink()
+	if err := New(sinkTestConfig(20)).Run(ctx, again, nil); err != nil {
+		t.Fatalf("second Run failed: %v", err)
+	}
+	if !reflect.DeepEqual(sink.Logs(), again.Logs()) {
+		t.Error("expected seeded runs to produce identical logs")
+	}
+}
+
+func TestFaker_RunStressMemorySink(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cfg := sinkTestConfig(50)
+	cfg.StressRate = 1000
+
+	sink := NewMemorySink()
+	f := New(cfg)
+	if err := f.RunStress(ctx, sink, nil); err != nil {
+		t.Fatalf("RunStress failed: %v", err)
+	}
+
+	if got := int64(sink.Len()); got != f.Stats().Sent.Load() || got < 50 {
+		t.Errorf("expected sink to hold every sent log (at least 50), got %d of %d", got, f.Stats().Sent.Load())
+	}
+}
+
+func TestFaker_RunSinkErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var calls atomic.Int64
+	sink := SinkFunc(func(LogEntry) error {
+		if calls.Add(1)%2 == 0 {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+
+	f := New(sinkTestConfig(3))
+	if err := f.Run(ctx, sink, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if got := f.Stats().Sent.Load(); got != 3 {
+		t.Errorf("expected 3 logs sent, got %d", got)
+	}
+	if got := f.Stats().Errors.Load(); got != 2 {
+		t.Errorf("expected 2 errors, got %d", got)
+	}
+}
+
+func TestFaker_RunRequiresSink(t *testing.T) {
+	f := New(sinkTestConfig(1))
+	if err := f.Run(context.Background(), nil, nil); !errors.Is(err, ErrNoSink) {
+		t.Errorf("expected ErrNoSink, got %v", err)
+	}
+	if err := f.RunStress(context.Background(), nil, nil); !errors.Is(err, ErrNoSink) {
+		t.Errorf("expected ErrNoSink from RunStress, got %v", err)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.ndjson")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := New(sinkTestConfig(5)).Run(ctx, sink, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open output: %v", err)
+	}
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var log LogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &log); err != nil {
+			t.Fatalf("line %d is not a log: %v", lines+1, err)
+		}
+		if log.Header.Title == "" {
+			t.Errorf("line %d has no title", lines+1)
+		}
+		lines++
+	}
+	if lines != 5 {
+		t.Errorf("expected 5 lines, got %d", lines)
+	}
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+	"github.com/mx-scribe/scribe/internal/faker"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate shell completion scripts",
+	Long: `Generate a shell completion script for scribe and write it to stdout.
+
+Examples:
+  # Bash (current session)
+  source <(scribe completion bash)
+
+  # Bash (persistent, Linux)
+  scribe completion bash > /etc/bash_completion.d/scribe
+
+  # Zsh
+  scribe completion zsh > "${fpath[1]}/_scribe"
+
+  # Fish
+  scribe completion fish > ~/.config/fish/completions/scribe.fish
+
+  # PowerShell
+  scribe completion powershell | Out-String | Invoke-Expression`,
+	DisableFlagsInUseLine: true,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		root := cmd.Root()
+
+		switch args[0] {
+		case "bash":
+			return root.GenBashCompletionV2(out, true)
+		case "zsh":
+			return root.GenZshCompletion(out)
+		case "fish":
+			return root.GenFishCompletion(out, true)
+		case "powershell":
+			return root.GenPowerShellCompletionWithDesc(out)
+		default:
+			return fmt.Errorf("unsupported shell: %s", args[0])
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+// completeSeverities completes a severity flag with the standard severities.
+func completeSeverities(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	severities := valueobjects.StandardSeverities()
+	values := make([]string, 0, len(severities))
+	for _, s := range severities {
+		values = append(values, s.String())
+	}
+	return values, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFakerCategories completes a comma-separated --categories list,
+// offering only categories not already given.
+func completeFakerCategories(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := ""
+	given := map[string]bool{}
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+		for _, c := range strings.Split(toComplete[:i], ",") {
+			given[strings.TrimSpace(c)] = true
+		}
+	}
+
+	values := make([]string, 0, len(faker.Categories))
+	for _, c := range faker.Categories {
+		if !given[c] {
+			values = append(values, prefix+c)
+		}
+	}
+	return values, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
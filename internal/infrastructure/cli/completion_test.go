@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// executeRoot runs the root command with args and returns its output.
+func executeRoot(t *testing.T, args ...string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs(args)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("scribe %s failed: %v", strings.Join(args, " "), err)
+	}
+	return buf.String()
+}
+
+func TestCompletionCommand(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  string
+	}{
+		{"bash", "# bash completion V2 for scribe"},
+		{"zsh", "#compdef scribe"},
+		{"fish", "complete -c scribe"},
+		{"powershell", "Register-ArgumentCompleter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			output := executeRoot(t, "completion", tt.shell)
+			if output == "" {
+				t.Fatal("expected completion script, got empty output")
+			}
+			if !strings.Contains(output, tt.want) {
+				t.Errorf("expected %s script to contain %q", tt.shell, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompletionCommand_InvalidShell(t *testing.T) {
+	rootCmd.SetArgs([]string{"completion", "tcsh"})
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetErr(&bytes.Buffer{})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+}
+
+func TestCompletion_Severities(t *testing.T) {
+	output := executeRoot(t, "__complete", "logs", "--severity", "")
+	for _, severity := range []string{"debug", "info", "warning", "error", "critical"} {
+		if !strings.Contains(output, severity) {
+			t.Errorf("expected severity %q in completions, got %q", severity, output)
+		}
+	}
+}
+
+func TestCompletion_FakerCategories(t *testing.T) {
+	output := executeRoot(t, "__complete", "faker", "--categories", "http,d")
+	if !strings.Contains(output, "http,database") {
+		t.Errorf("expected comma-prefixed category completion, got %q", output)
+	}
+	if strings.Contains(output, "http,http") {
+		t.Errorf("expected already given category to be skipped, got %q", output)
+	}
+}
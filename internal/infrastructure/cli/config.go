@@ -5,8 +5,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/application/queries"
+	"github.com/mx-scribe/scribe/internal/domain/services"
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+	"github.com/mx-scribe/scribe/internal/infrastructure/http"
+	"github.com/mx-scribe/scribe/internal/infrastructure/http/handlers"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
 )
 
 // Config holds all application configuration.
@@ -20,28 +30,206 @@ type Config struct {
 	// Logging settings
 	Logging LoggingConfig `json:"logging"`
 
+	// Pagination settings (default and max page sizes)
+	Pagination queries.Pagination `json:"pagination"`
+
 	// Output settings
 	Output OutputConfig `json:"output"`
+
+	// Metrics settings
+	Metrics MetricsConfig `json:"metrics"`
 }
 
 // ServerConfig holds server configuration.
 type ServerConfig struct {
 	Port         int    `json:"port"`
 	Host         string `json:"host"`
-	ReadTimeout  int    `json:"read_timeout"`
-	WriteTimeout int    `json:"write_timeout"`
+	MaxBodyBytes int64  `json:"max_body_bytes"`
+
+	// GRPCPort serves the gRPC ingestion service on this port alongside the
+	// HTTP server, in binaries built with -tags grpc. Zero disables it.
+	GRPCPort int `json:"grpc_port"`
+
+	// Timeouts in seconds; zero disables one. ReadHeaderTimeout guards
+	// against slow-header (slowloris) clients. Long-lived streams such as
+	// /api/events are exempt from WriteTimeout. OperationTimeout bounds
+	// exports, cleanup and reanalysis, which answer 504 when it passes.
+	ReadTimeout       int `json:"read_timeout"`
+	ReadHeaderTimeout int `json:"read_header_timeout"`
+	WriteTimeout      int `json:"write_timeout"`
+	IdleTimeout       int `json:"idle_timeout"`
+	OperationTimeout  int `json:"operation_timeout"`
+
+	// SSECoalesceThreshold is the log_created events per second above which
+	// live events are batched. Zero disables batching.
+	SSECoalesceThreshold int `json:"sse_coalesce_threshold"`
+
+	// SSEReplayTTL is how many seconds broadcast live events are stored in
+	// the database for Last-Event-ID replay, which survives restarts. Zero
+	// disables event persistence.
+	SSEReplayTTL int `json:"sse_replay_ttl"`
+
+	// AdminUser and AdminPassword enable HTTP Basic Auth on /api/admin/*.
+	// Both must be set for the check to apply.
+	AdminUser     string `json:"admin_user,omitempty"`
+	AdminPassword string `json:"admin_password,omitempty"`
+
+	// ExportSigningKey is the HMAC key of signed export URLs
+	// (POST /api/export/sign). Empty uses a random key per process, so
+	// signed URLs stop working on restart.
+	ExportSigningKey string `json:"export_signing_key,omitempty"`
+
+	// DisabledRoutes lists routes that answer 404 instead of being served,
+	// e.g. "/api/export/*", "/api/admin/*" or "DELETE /api/logs".
+	DisabledRoutes []string `json:"disabled_routes,omitempty"`
+
+	// RateLimitExempt lists clients that bypass the request rate limiter:
+	// IP addresses, CIDR ranges, or API keys as "key:<key>" sent in the
+	// X-API-Key header.
+	RateLimitExempt []string `json:"rate_limit_exempt,omitempty"`
+
+	// Tenancy scopes each request to the tenant named by its X-Tenant
+	// header, isolating logs, stats, exports and live events per tenant.
+	// Requests without the header use the default tenant.
+	Tenancy bool `json:"tenancy"`
+
+	// ReadOnly puts the server in maintenance mode: every /api request
+	// other than GET, HEAD and OPTIONS answers 503, while reads, exports
+	// and the dashboard keep working. serve re-reads it on SIGHUP.
+	ReadOnly bool `json:"read_only"`
 }
 
 // DatabaseConfig holds database configuration.
 type DatabaseConfig struct {
 	Path          string `json:"path"`
 	RetentionDays int    `json:"retention_days"`
+
+	// StatsTime and RetentionTime pick the timestamp ("event_time" or
+	// "ingested_at") used by recency (the last-24-hours counts and the
+	// stats ?since= windows) and by retention. They differ only for logs
+	// sent with their own timestamp.
+	StatsTime     string `json:"stats_time"`
+	RetentionTime string `json:"retention_time"`
+
+	// CompressBodies stores new log bodies gzip-compressed. Existing rows
+	// stay readable either way.
+	CompressBodies bool `json:"compress_bodies"`
+
+	// UnknownSourceLabel is the source stats report for logs with neither
+	// an explicit nor a derived source, and a source filter on it matches
+	// them. Defaults to "unknown".
+	UnknownSourceLabel string `json:"unknown_source_label,omitempty"`
+
+	// SlowQueryMS is the duration in milliseconds above which a repository
+	// call made while serving a request is logged as a slow query. Zero
+	// disables the warnings.
+	SlowQueryMS int `json:"slow_query_ms"`
 }
 
 // LoggingConfig holds logging defaults.
 type LoggingConfig struct {
 	DefaultSeverity string `json:"default_severity"`
 	DefaultSource   string `json:"default_source"`
+
+	// AutoAnalyze runs pattern matching on ingested logs by default.
+	AutoAnalyze bool `json:"auto_analyze"`
+
+	// SourceRateLimit caps ingested logs per second per source; zero
+	// disables it. SourceRateBurst is the burst allowed per source
+	// (defaults to the rate). With SourceRateDrop, excess logs are dropped
+	// and counted instead of rejected with 429.
+	SourceRateLimit float64 `json:"source_rate_limit"`
+	SourceRateBurst int     `json:"source_rate_burst"`
+	SourceRateDrop  bool    `json:"source_rate_drop"`
+
+	// MinIngestSeverity turns away logs whose effective severity ranks
+	// below it on POST /api/logs; empty admits all. With
+	// MinIngestSeverityDrop they are dropped and counted (204) instead of
+	// rejected (422).
+	MinIngestSeverity     string `json:"min_ingest_severity,omitempty"`
+	MinIngestSeverityDrop bool   `json:"min_ingest_severity_drop"`
+
+	// AllowedSources, when set, restricts POST /api/logs to logs whose
+	// effective source matches one of its patterns; DeniedSources turns
+	// away matching sources and wins over AllowedSources. Patterns may use
+	// * as a wildcard, e.g. "internal-*". With SourceFilterDrop, turned
+	// away logs are dropped and counted (204) instead of rejected (403).
+	AllowedSources   []string `json:"allowed_sources,omitempty"`
+	DeniedSources    []string `json:"denied_sources,omitempty"`
+	SourceFilterDrop bool     `json:"source_filter_drop"`
+
+	// MaxEventAge is how many seconds old a log's supplied timestamp may be
+	// on ingestion; older logs are rejected (422) so replays cannot pass
+	// for recent. Logs without a timestamp are exempt. Zero disables it.
+	MaxEventAge int `json:"max_event_age"`
+
+	// NormalizeBody copies aliased body fields (elapsed_ms, latency, ...)
+	// to canonical keys (duration_ms) on ingest, keeping the originals.
+	// BodyAliases maps canonical keys to their aliases and replaces the
+	// built-in mapping when set.
+	NormalizeBody bool                `json:"normalize_body"`
+	BodyAliases   map[string][]string `json:"body_aliases,omitempty"`
+
+	// Redact masks secrets in log bodies on ingest, before they are stored
+	// or analyzed. RedactKeys lists key substrings whose values are
+	// replaced and RedactPatterns regexes whose matches in string values
+	// are replaced; each replaces the built-in list when set.
+	Redact         bool     `json:"redact"`
+	RedactKeys     []string `json:"redact_keys,omitempty"`
+	RedactPatterns []string `json:"redact_patterns,omitempty"`
+
+	// HTTPStatusSeverity overrides the severity pattern matching assigns to
+	// HTTP status codes, e.g. {"404": "error"}; other codes keep the
+	// built-in mapping.
+	HTTPStatusSeverity map[string]string `json:"http_status_severity,omitempty"`
+
+	// KeywordScoring derives severity from weighted keyword hits across
+	// classes instead of the first class with a hit. KeywordWeights sets
+	// the weight of the error, warning, success and debug classes.
+	KeywordScoring bool               `json:"keyword_scoring"`
+	KeywordWeights map[string]float64 `json:"keyword_weights,omitempty"`
+
+	// AnalysisMaxBodyBytes and AnalysisMaxBodyDepth bound the serialized
+	// body pattern matching reads, so large or deeply nested bodies cannot
+	// slow ingestion; stored bodies are kept whole. Zero uses the defaults
+	// (64 KiB, depth 10).
+	AnalysisMaxBodyBytes int `json:"analysis_max_body_bytes"`
+	AnalysisMaxBodyDepth int `json:"analysis_max_body_depth"`
+
+	// ColorFromSeverity gives logs ingested without a color one derived
+	// from their effective severity, shown as derived_color. SeverityColorMap
+	// overrides the color per severity, e.g. {"critical": "rose"}; other
+	// severities keep the built-in mapping.
+	ColorFromSeverity bool              `json:"color_from_severity"`
+	SeverityColorMap  map[string]string `json:"severity_color_map,omitempty"`
+
+	// EscalationThresholds raises the severity of logs that recur: it maps
+	// severities to how many times the same title from the same source
+	// must be ingested within EscalationWindow seconds to be stored with
+	// that severity, e.g. {"error": 10, "critical": 50}. Empty disables it.
+	EscalationWindow     int            `json:"escalation_window"`
+	EscalationThresholds map[string]int `json:"escalation_thresholds,omitempty"`
+}
+
+// PatternMatcherOptions returns the pattern matching options set by c.
+func (c LoggingConfig) PatternMatcherOptions() services.PatternMatcherOptions {
+	return services.PatternMatcherOptions{
+		HTTPStatusSeverity: c.HTTPStatusSeverity,
+		KeywordScoring:     c.KeywordScoring,
+		KeywordWeights:     c.KeywordWeights,
+		MaxBodyBytes:       c.AnalysisMaxBodyBytes,
+		MaxBodyDepth:       c.AnalysisMaxBodyDepth,
+	}
+}
+
+// MetricsConfig holds Prometheus metrics settings.
+type MetricsConfig struct {
+	// Prefix is prepended to every series name.
+	Prefix string `json:"prefix"`
+
+	// Labels are static labels added to every series, e.g.
+	// {"instance": "scribe-1", "env": "prod"}.
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // OutputConfig holds output settings.
@@ -50,6 +238,11 @@ type OutputConfig struct {
 	NoColor    bool   `json:"no_color"`
 	Verbose    bool   `json:"verbose"`
 	TimeFormat string `json:"time_format"`
+
+	// SeverityColors overrides the CLI color per severity, e.g.
+	// {"warning": "magenta"}. Colors: red, green, yellow, blue, magenta,
+	// cyan, white, gray, bold.
+	SeverityColors map[string]string `json:"severity_colors,omitempty"`
 }
 
 // DefaultConfig returns the default configuration.
@@ -59,23 +252,40 @@ func DefaultConfig() *Config {
 		Server: ServerConfig{
 			Port:         8080,
 			Host:         "0.0.0.0",
-			ReadTimeout:  15,
-			WriteTimeout: 15,
+			MaxBodyBytes: 1 << 20,
+
+			ReadTimeout:       15,
+			ReadHeaderTimeout: 5,
+			WriteTimeout:      15,
+			IdleTimeout:       60,
+			OperationTimeout:  300,
+
+			SSECoalesceThreshold: 50,
 		},
 		Database: DatabaseConfig{
 			Path:          filepath.Join(homeDir, ".scribe", "scribe.db"),
 			RetentionDays: 90,
+			StatsTime:     string(sqlite.IngestedAt),
+			RetentionTime: string(sqlite.EventTime),
+			SlowQueryMS:   int(sqlite.DefaultSlowQueryThreshold / time.Millisecond),
 		},
 		Logging: LoggingConfig{
 			DefaultSeverity: "info",
 			DefaultSource:   "",
+			AutoAnalyze:     true,
+
+			EscalationWindow: 60,
 		},
+		Pagination: queries.DefaultPagination(),
 		Output: OutputConfig{
 			Format:     "table",
 			NoColor:    false,
 			Verbose:    false,
 			TimeFormat: "2006-01-02 15:04:05",
 		},
+		Metrics: MetricsConfig{
+			Prefix: handlers.DefaultMetricPrefix,
+		},
 	}
 }
 
@@ -89,16 +299,10 @@ func LoadConfig(configPath string) (*Config, error) {
 		if err := loadConfigFile(config, configPath); err != nil {
 			return nil, fmt.Errorf("failed to load config file: %w", err)
 		}
-	} else {
-		// Try default config locations
-		defaultPaths := getDefaultConfigPaths()
-		for _, path := range defaultPaths {
-			if _, err := os.Stat(path); err == nil {
-				if err := loadConfigFile(config, path); err != nil {
-					return nil, fmt.Errorf("failed to load config from %s: %w", path, err)
-				}
-				break
-			}
+	} else if path := ResolveConfigPath(""); path != "" {
+		// Found in a default config location
+		if err := loadConfigFile(config, path); err != nil {
+			return nil, fmt.Errorf("failed to load config from %s: %w", path, err)
 		}
 	}
 
@@ -108,6 +312,20 @@ func LoadConfig(configPath string) (*Config, error) {
 	return config, nil
 }
 
+// ResolveConfigPath returns configPath if set, otherwise the first default
+// config location that exists, or "" if there is none.
+func ResolveConfigPath(configPath string) string {
+	if configPath != "" {
+		return configPath
+	}
+	for _, path := range getDefaultConfigPaths() {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
 // getDefaultConfigPaths returns paths to check for config files.
 func getDefaultConfigPaths() []string {
 	homeDir, _ := os.UserHomeDir()
@@ -142,9 +360,55 @@ func loadEnvConfig(config *Config) {
 			config.Server.Port = port
 		}
 	}
+	if v := os.Getenv("SCRIBE_GRPC_PORT"); v != "" {
+		if port, err := strconv.Atoi(v); err == nil {
+			config.Server.GRPCPort = port
+		}
+	}
 	if v := os.Getenv("SCRIBE_HOST"); v != "" {
 		config.Server.Host = v
 	}
+	if v := os.Getenv("SCRIBE_MAX_BODY_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			config.Server.MaxBodyBytes = n
+		}
+	}
+	if v := os.Getenv("SCRIBE_SSE_COALESCE_THRESHOLD"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			config.Server.SSECoalesceThreshold = n
+		}
+	}
+	if v := os.Getenv("SCRIBE_SSE_REPLAY_TTL"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			config.Server.SSEReplayTTL = n
+		}
+	}
+	if v := os.Getenv("SCRIBE_ADMIN_USER"); v != "" {
+		config.Server.AdminUser = v
+	}
+	if v := os.Getenv("SCRIBE_ADMIN_PASSWORD"); v != "" {
+		config.Server.AdminPassword = v
+	}
+	if v := os.Getenv("SCRIBE_EXPORT_SIGNING_KEY"); v != "" {
+		config.Server.ExportSigningKey = v
+	}
+	if v := os.Getenv("SCRIBE_TENANCY"); v != "" {
+		config.Server.Tenancy = strings.EqualFold(v, "true") || v == "1"
+	}
+	if v := os.Getenv("SCRIBE_READ_ONLY"); v != "" {
+		config.Server.ReadOnly = strings.EqualFold(v, "true") || v == "1"
+	}
+	if v := os.Getenv("SCRIBE_DISABLED_ROUTES"); v != "" {
+		config.Server.DisabledRoutes = parseList(v)
+	}
+	if v := os.Getenv("SCRIBE_RATE_LIMIT_EXEMPT"); v != "" {
+		config.Server.RateLimitExempt = nil
+		for _, entry := range strings.Split(v, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				config.Server.RateLimitExempt = append(config.Server.RateLimitExempt, entry)
+			}
+		}
+	}
 
 	// Database
 	if v := os.Getenv("SCRIBE_DB_PATH"); v != "" {
@@ -155,6 +419,23 @@ func loadEnvConfig(config *Config) {
 			config.Database.RetentionDays = days
 		}
 	}
+	if v := os.Getenv("SCRIBE_STATS_TIME"); v != "" {
+		config.Database.StatsTime = v
+	}
+	if v := os.Getenv("SCRIBE_RETENTION_TIME"); v != "" {
+		config.Database.RetentionTime = v
+	}
+	if v := os.Getenv("SCRIBE_COMPRESS_BODIES"); v != "" {
+		config.Database.CompressBodies = strings.EqualFold(v, "true") || v == "1"
+	}
+	if v := os.Getenv("SCRIBE_UNKNOWN_SOURCE_LABEL"); v != "" {
+		config.Database.UnknownSourceLabel = v
+	}
+	if v := os.Getenv("SCRIBE_SLOW_QUERY_MS"); v != "" {
+		if ms, err := strconv.Atoi(v); err == nil {
+			config.Database.SlowQueryMS = ms
+		}
+	}
 
 	// Logging
 	if v := os.Getenv("SCRIBE_DEFAULT_SEVERITY"); v != "" {
@@ -163,11 +444,99 @@ func loadEnvConfig(config *Config) {
 	if v := os.Getenv("SCRIBE_DEFAULT_SOURCE"); v != "" {
 		config.Logging.DefaultSource = v
 	}
+	if v := os.Getenv("SCRIBE_AUTO_ANALYZE"); v != "" {
+		config.Logging.AutoAnalyze = strings.EqualFold(v, "true") || v == "1"
+	}
+	if v := os.Getenv("SCRIBE_SOURCE_RATE_LIMIT"); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			config.Logging.SourceRateLimit = n
+		}
+	}
+	if v := os.Getenv("SCRIBE_SOURCE_RATE_BURST"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			config.Logging.SourceRateBurst = n
+		}
+	}
+	if v := os.Getenv("SCRIBE_SOURCE_RATE_DROP"); v != "" {
+		config.Logging.SourceRateDrop = strings.EqualFold(v, "true") || v == "1"
+	}
+	if v := os.Getenv("SCRIBE_MIN_INGEST_SEVERITY"); v != "" {
+		config.Logging.MinIngestSeverity = v
+	}
+	if v := os.Getenv("SCRIBE_MIN_INGEST_SEVERITY_DROP"); v != "" {
+		config.Logging.MinIngestSeverityDrop = strings.EqualFold(v, "true") || v == "1"
+	}
+	if v := os.Getenv("SCRIBE_ALLOWED_SOURCES"); v != "" {
+		config.Logging.AllowedSources = parseList(v)
+	}
+	if v := os.Getenv("SCRIBE_DENIED_SOURCES"); v != "" {
+		config.Logging.DeniedSources = parseList(v)
+	}
+	if v := os.Getenv("SCRIBE_SOURCE_FILTER_DROP"); v != "" {
+		config.Logging.SourceFilterDrop = strings.EqualFold(v, "true") || v == "1"
+	}
+	if v := os.Getenv("SCRIBE_MAX_EVENT_AGE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			config.Logging.MaxEventAge = n
+		}
+	}
+	if v := os.Getenv("SCRIBE_NORMALIZE_BODY"); v != "" {
+		config.Logging.NormalizeBody = strings.EqualFold(v, "true") || v == "1"
+	}
+	if v := os.Getenv("SCRIBE_REDACT"); v != "" {
+		config.Logging.Redact = strings.EqualFold(v, "true") || v == "1"
+	}
+	if v := os.Getenv("SCRIBE_HTTP_STATUS_SEVERITY"); v != "" {
+		config.Logging.HTTPStatusSeverity = parseLabels(v)
+	}
+	if v := os.Getenv("SCRIBE_KEYWORD_SCORING"); v != "" {
+		config.Logging.KeywordScoring = strings.EqualFold(v, "true") || v == "1"
+	}
+	if v := os.Getenv("SCRIBE_ANALYSIS_MAX_BODY_BYTES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			config.Logging.AnalysisMaxBodyBytes = n
+		}
+	}
+	if v := os.Getenv("SCRIBE_ANALYSIS_MAX_BODY_DEPTH"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			config.Logging.AnalysisMaxBodyDepth = n
+		}
+	}
+	if v := os.Getenv("SCRIBE_COLOR_FROM_SEVERITY"); v != "" {
+		config.Logging.ColorFromSeverity = strings.EqualFold(v, "true") || v == "1"
+	}
+	if v := os.Getenv("SCRIBE_SEVERITY_COLOR_MAP"); v != "" {
+		config.Logging.SeverityColorMap = parseLabels(v)
+	}
+	if v := os.Getenv("SCRIBE_ESCALATION_WINDOW"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			config.Logging.EscalationWindow = n
+		}
+	}
+	if v := os.Getenv("SCRIBE_ESCALATION_THRESHOLDS"); v != "" {
+		config.Logging.EscalationThresholds = make(map[string]int)
+		for severity, count := range parseLabels(v) {
+			if n, err := strconv.Atoi(count); err == nil {
+				config.Logging.EscalationThresholds[severity] = n
+			}
+		}
+	}
+
+	// Metrics
+	if v, ok := os.LookupEnv("SCRIBE_METRICS_PREFIX"); ok {
+		config.Metrics.Prefix = v
+	}
+	if v := os.Getenv("SCRIBE_METRICS_LABELS"); v != "" {
+		config.Metrics.Labels = parseLabels(v)
+	}
 
 	// Output
 	if v := os.Getenv("SCRIBE_OUTPUT_FORMAT"); v != "" {
 		config.Output.Format = v
 	}
+	if os.Getenv("NO_COLOR") != "" { // https://no-color.org
+		config.Output.NoColor = true
+	}
 	if v := os.Getenv("SCRIBE_NO_COLOR"); v != "" {
 		config.Output.NoColor = strings.EqualFold(v, "true") || v == "1"
 	}
@@ -176,6 +545,254 @@ func loadEnvConfig(config *Config) {
 	}
 }
 
+// parseList parses a comma-separated list, skipping empty items.
+func parseList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseLabels parses comma-separated name=value pairs, skipping malformed ones.
+func parseLabels(s string) map[string]string {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if name = strings.TrimSpace(name); ok && name != "" {
+			labels[name] = strings.TrimSpace(value)
+		}
+	}
+	return labels
+}
+
+// isColorName reports whether name is a color accepted by severity_colors.
+func isColorName(name string) bool {
+	_, ok := ParseColorName(name)
+	return ok
+}
+
+// ConfigErrors lists every problem found by Config.Validate.
+type ConfigErrors []string
+
+func (e ConfigErrors) Error() string {
+	return strings.Join(e, "; ")
+}
+
+// Validate checks the configuration for values the server would reject or
+// silently replace. It returns ConfigErrors listing every problem, or nil.
+func (c *Config) Validate() error {
+	var problems ConfigErrors
+	addf := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	// Server
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		addf("server.port must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	if c.Server.GRPCPort < 0 || c.Server.GRPCPort > 65535 {
+		addf("server.grpc_port must be between 0 and 65535, got %d", c.Server.GRPCPort)
+	} else if c.Server.GRPCPort != 0 && c.Server.GRPCPort == c.Server.Port {
+		addf("server.grpc_port must differ from server.port %d", c.Server.Port)
+	}
+	if c.Server.Host == "" {
+		addf("server.host must not be empty")
+	}
+	timeouts := []struct {
+		name    string
+		seconds int
+	}{
+		{"read_timeout", c.Server.ReadTimeout},
+		{"read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"write_timeout", c.Server.WriteTimeout},
+		{"idle_timeout", c.Server.IdleTimeout},
+		{"operation_timeout", c.Server.OperationTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.seconds < 0 {
+			addf("server.%s must not be negative, got %d", timeout.name, timeout.seconds)
+		}
+	}
+	if c.Server.SSECoalesceThreshold < 0 {
+		addf("server.sse_coalesce_threshold must not be negative, got %d", c.Server.SSECoalesceThreshold)
+	}
+	if c.Server.SSEReplayTTL < 0 {
+		addf("server.sse_replay_ttl must not be negative, got %d", c.Server.SSEReplayTTL)
+	}
+	if (c.Server.AdminUser == "") != (c.Server.AdminPassword == "") {
+		addf("server.admin_user and server.admin_password must be set together")
+	}
+	for _, route := range c.Server.DisabledRoutes {
+		if err := http.ValidateDisabledRoute(route); err != nil {
+			addf("server.disabled_routes: %v", err)
+		}
+	}
+	for _, entry := range c.Server.RateLimitExempt {
+		if err := http.ValidateRateLimitExempt(entry); err != nil {
+			addf("server.rate_limit_exempt: %v", err)
+		}
+	}
+
+	// Database
+	if c.Database.Path == "" {
+		addf("database.path must not be empty")
+	}
+	if c.Database.RetentionDays < 0 {
+		addf("database.retention_days must not be negative, got %d", c.Database.RetentionDays)
+	}
+	if _, err := sqlite.ParseTimeField(c.Database.StatsTime); err != nil {
+		addf("database.stats_time: %v", err)
+	}
+	if _, err := sqlite.ParseTimeField(c.Database.RetentionTime); err != nil {
+		addf("database.retention_time: %v", err)
+	}
+	if c.Database.SlowQueryMS < 0 {
+		addf("database.slow_query_ms must not be negative, got %d", c.Database.SlowQueryMS)
+	}
+
+	// Logging
+	if c.Logging.SourceRateLimit < 0 {
+		addf("logging.source_rate_limit must not be negative, got %g", c.Logging.SourceRateLimit)
+	}
+	if c.Logging.SourceRateBurst < 0 {
+		addf("logging.source_rate_burst must not be negative, got %d", c.Logging.SourceRateBurst)
+	}
+	if c.Logging.MinIngestSeverity != "" && valueobjects.Severity(c.Logging.MinIngestSeverity).Rank() == 0 {
+		addf("logging.min_ingest_severity must be a standard severity, got %q", c.Logging.MinIngestSeverity)
+	}
+	sourceLists := []struct {
+		name     string
+		patterns []string
+	}{
+		{"allowed_sources", c.Logging.AllowedSources},
+		{"denied_sources", c.Logging.DeniedSources},
+	}
+	for _, list := range sourceLists {
+		if slices.ContainsFunc(list.patterns, func(p string) bool { return strings.TrimSpace(p) == "" }) {
+			addf("logging.%s must not contain empty patterns", list.name)
+		}
+	}
+	if c.Logging.MaxEventAge < 0 {
+		addf("logging.max_event_age must not be negative, got %d", c.Logging.MaxEventAge)
+	}
+	canonicalKeys := make([]string, 0, len(c.Logging.BodyAliases))
+	for key := range c.Logging.BodyAliases {
+		canonicalKeys = append(canonicalKeys, key)
+	}
+	sort.Strings(canonicalKeys)
+	for _, key := range canonicalKeys {
+		if !sqlite.ValidBodyField(key) {
+			addf("logging.body_aliases: invalid canonical key %q", key)
+		}
+		if len(c.Logging.BodyAliases[key]) == 0 {
+			addf("logging.body_aliases.%s must list at least one alias", key)
+		}
+	}
+	for _, pattern := range c.Logging.RedactPatterns {
+		if _, err := services.NewBodyRedactor(nil, []string{pattern}); err != nil {
+			addf("logging.redact_patterns: %v", err)
+		}
+	}
+	if _, err := services.NewPatternMatcherWithStatusSeverity(c.Logging.HTTPStatusSeverity); err != nil {
+		addf("logging.http_status_severity: %v", err)
+	}
+	if _, err := services.NewPatternMatcherWithOptions(services.PatternMatcherOptions{KeywordWeights: c.Logging.KeywordWeights}); err != nil {
+		addf("logging.keyword_weights: %v", err)
+	}
+	if c.Logging.AnalysisMaxBodyBytes < 0 {
+		addf("logging.analysis_max_body_bytes must not be negative, got %d", c.Logging.AnalysisMaxBodyBytes)
+	}
+	if c.Logging.AnalysisMaxBodyDepth < 0 {
+		addf("logging.analysis_max_body_depth must not be negative, got %d", c.Logging.AnalysisMaxBodyDepth)
+	}
+	if _, err := services.NewSeverityColorizer(c.Logging.SeverityColorMap); err != nil {
+		addf("logging.severity_color_map: %v", err)
+	}
+	if c.Logging.EscalationWindow < 0 {
+		addf("logging.escalation_window must not be negative, got %d", c.Logging.EscalationWindow)
+	}
+	if _, err := handlers.NewEscalator(time.Minute, c.Logging.EscalationThresholds); err != nil {
+		addf("logging.escalation_thresholds: %v", err)
+	}
+
+	// Pagination
+	pageSizes := []struct {
+		name string
+		size queries.PageSize
+	}{
+		{"list", c.Pagination.List},
+		{"query", c.Pagination.Query},
+		{"export", c.Pagination.Export},
+		{"ids", c.Pagination.IDs},
+	}
+	for _, p := range pageSizes {
+		if p.size.Default < 0 || p.size.Max < 0 {
+			addf("pagination.%s sizes must not be negative", p.name)
+		} else if p.size.Default > 0 && p.size.Max > 0 && p.size.Max < p.size.Default {
+			addf("pagination.%s.max (%d) must not be below default (%d)", p.name, p.size.Max, p.size.Default)
+		}
+	}
+	if c.Pagination.MaxOffset < 0 {
+		addf("pagination.max_offset must not be negative, got %d", c.Pagination.MaxOffset)
+	}
+
+	// Metrics
+	metrics := handlers.PrometheusOptions{Prefix: c.Metrics.Prefix, Labels: c.Metrics.Labels}
+	if err := metrics.Validate(); err != nil {
+		addf("metrics: %v", err)
+	}
+
+	// Output
+	switch OutputFormat(c.Output.Format) {
+	case FormatTable, FormatJSON, FormatPlain:
+	default:
+		addf("output.format must be table, json or plain, got %q", c.Output.Format)
+	}
+	severities := make([]string, 0, len(c.Output.SeverityColors))
+	for severity := range c.Output.SeverityColors {
+		severities = append(severities, severity)
+	}
+	sort.Strings(severities)
+	for _, severity := range severities {
+		if name := c.Output.SeverityColors[severity]; !isColorName(name) {
+			addf("output.severity_colors.%s: unknown color %q", severity, name)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
+
+// Redacted returns a copy of the configuration with secrets masked.
+func (c Config) Redacted() Config {
+	if c.Server.AdminPassword != "" {
+		c.Server.AdminPassword = redactedValue
+	}
+	if c.Server.ExportSigningKey != "" {
+		c.Server.ExportSigningKey = redactedValue
+	}
+	// API key exemptions are credentials; IP and CIDR entries are not
+	if len(c.Server.RateLimitExempt) > 0 {
+		exempt := make([]string, len(c.Server.RateLimitExempt))
+		for i, entry := range c.Server.RateLimitExempt {
+			if strings.HasPrefix(entry, "key:") {
+				entry = "key:" + redactedValue
+			}
+			exempt[i] = entry
+		}
+		c.Server.RateLimitExempt = exempt
+	}
+	return c
+}
+
+// redactedValue replaces secrets in displayed configuration.
+const redactedValue = "********"
+
 // SaveConfig saves configuration to a file.
 func SaveConfig(config *Config, path string) error {
 	// Ensure directory exists
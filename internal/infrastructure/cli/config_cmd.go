@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+var configJSON bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and validate configuration",
+	Long: `Inspect and validate SCRIBE configuration.
+
+Examples:
+  scribe config validate                 # validate the config that would be loaded
+  scribe config validate ./scribe.json   # validate a specific file
+  scribe config show                     # print the effective config (file + env)
+  scribe config show --json`,
+}
+
+// configValidation is the --json output of config validate.
+type configValidation struct {
+	Path   string   `json:"path,omitempty"`
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:          "validate [path]",
+	Short:        "Validate a config file",
+	Long:         `Load a config file (merged with environment variables) and report every problem found. Exits non-zero if the config is invalid.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := GetConfigPath()
+		if len(args) > 0 {
+			path = args[0]
+		}
+		path = ResolveConfigPath(path)
+
+		config, err := LoadConfig(path)
+		if err != nil {
+			return err
+		}
+
+		result := configValidation{Path: path, Valid: true}
+		var problems ConfigErrors
+		if err := config.Validate(); err != nil {
+			if !errors.As(err, &problems) {
+				return err
+			}
+			result.Valid = false
+			result.Errors = problems
+		}
+
+		if err := writeConfigValidation(cmd.OutOrStdout(), result); err != nil {
+			return err
+		}
+		if !result.Valid {
+			return fmt.Errorf("config has %d problem(s)", len(problems))
+		}
+		return nil
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration",
+	Long:  `Print the effective configuration (defaults, config file and environment variables merged) with secrets redacted.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config := GetConfig().Redacted()
+
+		if configJSON {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(config)
+		}
+		return writeConfigFlat(cmd.OutOrStdout(), config)
+	},
+}
+
+func init() {
+	configCmd.PersistentFlags().BoolVar(&configJSON, "json", false, "output as JSON")
+	configCmd.AddCommand(configValidateCmd, configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// writeConfigValidation prints the validation result as JSON or as a list
+// of problems.
+func writeConfigValidation(w io.Writer, result configValidation) error {
+	if configJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	out := NewOutput()
+	out.Writer = w
+
+	source := result.Path
+	if source == "" {
+		source = "defaults (no config file found)"
+	}
+	if result.Valid {
+		out.Success("%s is valid", source)
+		return nil
+	}
+	out.Error("%s is invalid:", source)
+	for _, problem := range result.Errors {
+		_, _ = fmt.Fprintf(w, "  - %s\n", problem)
+	}
+	return nil
+}
+
+// writeConfigFlat prints the config as sorted "key = value" lines.
+func writeConfigFlat(w io.Writer, config Config) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	var tree map[string]any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return err
+	}
+
+	values := map[string]string{}
+	flattenConfig("", tree, values)
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if _, err := fmt.Fprintf(w, "%s = %s\n", key, values[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flattenConfig collects leaf values of a decoded JSON object under dotted keys.
+func flattenConfig(prefix string, value any, values map[string]string) {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		encoded, _ := json.Marshal(value)
+		values[prefix] = string(encoded)
+		return
+	}
+	for key, child := range obj {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		flattenConfig(key, child, values)
+	}
+}
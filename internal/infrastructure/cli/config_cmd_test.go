@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+// executeRootErr runs the root command with args, returning its output and error.
+func executeRootErr(args ...string) (string, error) {
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs(args)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+		configJSON = false
+		configPath = ""
+	}()
+
+	err := rootCmd.Execute()
+	return buf.String(), err
+}
+
+func TestConfigValidate_Valid(t *testing.T) {
+	path := writeConfigFile(t, `{"server": {"port": 9090}}`)
+
+	output, err := executeRootErr("config", "validate", path, "--no-color")
+	if err != nil {
+		t.Fatalf("expected valid config, got %v: %s", err, output)
+	}
+	if !strings.Contains(output, "is valid") {
+		t.Errorf("expected success message, got %q", output)
+	}
+}
+
+func TestConfigValidate_Invalid(t *testing.T) {
+	path := writeConfigFile(t, `{"server": {"port": 0}, "output": {"format": "yaml"}}`)
+
+	output, err := executeRootErr("config", "validate", path, "--no-color")
+	if err == nil {
+		t.Fatalf("expected invalid config to fail, got output %q", output)
+	}
+	for _, want := range []string{"is invalid", "server.port", "output.format"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got %q", want, output)
+		}
+	}
+}
+
+func TestConfigValidate_JSON(t *testing.T) {
+	path := writeConfigFile(t, `{"server": {"port": 0}}`)
+
+	output, err := executeRootErr("config", "validate", path, "--json")
+	if err == nil {
+		t.Fatal("expected invalid config to fail")
+	}
+
+	// The JSON document precedes cobra's error line
+	var result configValidation
+	if err := json.NewDecoder(strings.NewReader(output)).Decode(&result); err != nil {
+		t.Fatalf("failed to decode JSON output %q: %v", output, err)
+	}
+	if result.Valid || result.Path != path || len(result.Errors) != 1 {
+		t.Errorf("unexpected validation result: %+v", result)
+	}
+}
+
+func TestConfigShow_RedactsSecrets(t *testing.T) {
+	path := writeConfigFile(t, `{"server": {"admin_user": "ops", "admin_password": "s3cret"}}`)
+
+	for _, args := range [][]string{
+		{"config", "show", "--config", path},
+		{"config", "show", "--config", path, "--json"},
+	} {
+		output, err := executeRootErr(args...)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", args, err)
+		}
+		if strings.Contains(output, "s3cret") {
+			t.Errorf("%v: expected password to be redacted, got %q", args, output)
+		}
+		if !strings.Contains(output, redactedValue) || !strings.Contains(output, "ops") {
+			t.Errorf("%v: expected redacted password and admin user, got %q", args, output)
+		}
+	}
+
+	output, _ := executeRootErr("config", "show", "--config", path)
+	if !strings.Contains(output, "server.admin_user = \"ops\"") {
+		t.Errorf("expected flattened key/value output, got %q", output)
+	}
+}
+
+func TestConfigShow_RedactsRateLimitExemptKeys(t *testing.T) {
+	path := writeConfigFile(t, `{"server": {"rate_limit_exempt": ["10.0.0.0/8", "key:monitoring-agent-key", "192.168.1.7"]}}`)
+
+	for _, args := range [][]string{
+		{"config", "show", "--config", path},
+		{"config", "show", "--config", path, "--json"},
+	} {
+		output, err := executeRootErr(args...)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", args, err)
+		}
+		if strings.Contains(output, "monitoring-agent-key") {
+			t.Errorf("%v: expected the API key to be redacted, got %q", args, output)
+		}
+		for _, want := range []string{"key:" + redactedValue, "10.0.0.0/8", "192.168.1.7"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("%v: expected %q in output, got %q", args, want, output)
+			}
+		}
+	}
+
+	// The loaded configuration keeps the real key
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	_ = config.Redacted()
+	if config.Server.RateLimitExempt[1] != "key:monitoring-agent-key" {
+		t.Errorf("expected Redacted to leave the original untouched, got %v", config.Server.RateLimitExempt)
+	}
+}
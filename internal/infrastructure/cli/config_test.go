@@ -1,9 +1,13 @@
 package cli
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/mx-scribe/scribe/internal/application/queries"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -23,12 +27,27 @@ func TestDefaultConfig(t *testing.T) {
 	if config.Server.ReadTimeout != 15 {
 		t.Errorf("expected read timeout 15, got %d", config.Server.ReadTimeout)
 	}
+	if config.Server.MaxBodyBytes != 1<<20 {
+		t.Errorf("expected max body bytes 1MiB, got %d", config.Server.MaxBodyBytes)
+	}
 
 	// Database defaults
 	if config.Database.RetentionDays != 90 {
 		t.Errorf("expected retention 90 days, got %d", config.Database.RetentionDays)
 	}
 
+	// Pagination defaults
+	if config.Pagination.List.Default != 20 || config.Pagination.List.Max != 100 {
+		t.Errorf("expected list page size 20/100, got %+v", config.Pagination.List)
+	}
+	if config.Pagination.Export.Max != 100000 {
+		t.Errorf("expected export max 100000, got %d", config.Pagination.Export.Max)
+	}
+
+	if !config.Logging.AutoAnalyze {
+		t.Error("expected auto analyze to be enabled by default")
+	}
+
 	// Logging defaults
 	if config.Logging.DefaultSeverity != "info" {
 		t.Errorf("expected severity info, got %s", config.Logging.DefaultSeverity)
@@ -127,8 +146,16 @@ func TestLoadEnvConfig(t *testing.T) {
 	// Set environment variables
 	os.Setenv("SCRIBE_PORT", "3000")
 	os.Setenv("SCRIBE_HOST", "localhost")
+	os.Setenv("SCRIBE_GRPC_PORT", "9090")
+	os.Setenv("SCRIBE_MAX_BODY_BYTES", "2048")
+	os.Setenv("SCRIBE_ADMIN_USER", "ops")
+	os.Setenv("SCRIBE_ADMIN_PASSWORD", "s3cret")
+	os.Setenv("SCRIBE_TENANCY", "1")
+	os.Setenv("SCRIBE_READ_ONLY", "true")
 	os.Setenv("SCRIBE_DB_PATH", "/tmp/test.db")
 	os.Setenv("SCRIBE_RETENTION_DAYS", "7")
+	os.Setenv("SCRIBE_COMPRESS_BODIES", "true")
+	os.Setenv("SCRIBE_UNKNOWN_SOURCE_LABEL", "uncategorized")
 	os.Setenv("SCRIBE_DEFAULT_SEVERITY", "debug")
 	os.Setenv("SCRIBE_OUTPUT_FORMAT", "plain")
 	os.Setenv("SCRIBE_NO_COLOR", "true")
@@ -136,8 +163,16 @@ func TestLoadEnvConfig(t *testing.T) {
 	defer func() {
 		os.Unsetenv("SCRIBE_PORT")
 		os.Unsetenv("SCRIBE_HOST")
+		os.Unsetenv("SCRIBE_GRPC_PORT")
+		os.Unsetenv("SCRIBE_MAX_BODY_BYTES")
+		os.Unsetenv("SCRIBE_ADMIN_USER")
+		os.Unsetenv("SCRIBE_ADMIN_PASSWORD")
+		os.Unsetenv("SCRIBE_TENANCY")
+		os.Unsetenv("SCRIBE_READ_ONLY")
 		os.Unsetenv("SCRIBE_DB_PATH")
 		os.Unsetenv("SCRIBE_RETENTION_DAYS")
+		os.Unsetenv("SCRIBE_COMPRESS_BODIES")
+		os.Unsetenv("SCRIBE_UNKNOWN_SOURCE_LABEL")
 		os.Unsetenv("SCRIBE_DEFAULT_SEVERITY")
 		os.Unsetenv("SCRIBE_OUTPUT_FORMAT")
 		os.Unsetenv("SCRIBE_NO_COLOR")
@@ -152,12 +187,33 @@ func TestLoadEnvConfig(t *testing.T) {
 	if config.Server.Host != "localhost" {
 		t.Errorf("expected host localhost, got %s", config.Server.Host)
 	}
+	if config.Server.GRPCPort != 9090 {
+		t.Errorf("expected gRPC port 9090, got %d", config.Server.GRPCPort)
+	}
+	if config.Server.MaxBodyBytes != 2048 {
+		t.Errorf("expected max body bytes 2048, got %d", config.Server.MaxBodyBytes)
+	}
+	if config.Server.AdminUser != "ops" || config.Server.AdminPassword != "s3cret" {
+		t.Errorf("expected admin credentials ops/s3cret, got %s/%s", config.Server.AdminUser, config.Server.AdminPassword)
+	}
+	if !config.Server.Tenancy {
+		t.Error("expected Tenancy true")
+	}
+	if !config.Server.ReadOnly {
+		t.Error("expected ReadOnly true")
+	}
 	if config.Database.Path != "/tmp/test.db" {
 		t.Errorf("expected db path /tmp/test.db, got %s", config.Database.Path)
 	}
 	if config.Database.RetentionDays != 7 {
 		t.Errorf("expected retention 7, got %d", config.Database.RetentionDays)
 	}
+	if !config.Database.CompressBodies {
+		t.Error("expected CompressBodies true")
+	}
+	if config.Database.UnknownSourceLabel != "uncategorized" {
+		t.Errorf("expected unknown source label uncategorized, got %s", config.Database.UnknownSourceLabel)
+	}
 	if config.Logging.DefaultSeverity != "debug" {
 		t.Errorf("expected severity debug, got %s", config.Logging.DefaultSeverity)
 	}
@@ -172,6 +228,259 @@ func TestLoadEnvConfig(t *testing.T) {
 	}
 }
 
+func TestLoadEnvConfig_SourceRateLimit(t *testing.T) {
+	config := DefaultConfig()
+	if config.Logging.SourceRateLimit != 0 {
+		t.Errorf("expected source rate limit disabled by default, got %g", config.Logging.SourceRateLimit)
+	}
+
+	os.Setenv("SCRIBE_SOURCE_RATE_LIMIT", "12.5")
+	os.Setenv("SCRIBE_SOURCE_RATE_BURST", "40")
+	os.Setenv("SCRIBE_SOURCE_RATE_DROP", "true")
+	defer func() {
+		os.Unsetenv("SCRIBE_SOURCE_RATE_LIMIT")
+		os.Unsetenv("SCRIBE_SOURCE_RATE_BURST")
+		os.Unsetenv("SCRIBE_SOURCE_RATE_DROP")
+	}()
+
+	loadEnvConfig(config)
+
+	if config.Logging.SourceRateLimit != 12.5 || config.Logging.SourceRateBurst != 40 || !config.Logging.SourceRateDrop {
+		t.Errorf("unexpected source rate limit settings: %+v", config.Logging)
+	}
+}
+
+func TestLoadEnvConfig_SourceFilter(t *testing.T) {
+	config := DefaultConfig()
+	if len(config.Logging.AllowedSources) != 0 || len(config.Logging.DeniedSources) != 0 {
+		t.Errorf("expected no source restriction by default, got %+v", config.Logging)
+	}
+
+	os.Setenv("SCRIBE_ALLOWED_SOURCES", "api, internal-*,")
+	os.Setenv("SCRIBE_DENIED_SOURCES", "internal-sandbox")
+	os.Setenv("SCRIBE_SOURCE_FILTER_DROP", "1")
+	defer func() {
+		os.Unsetenv("SCRIBE_ALLOWED_SOURCES")
+		os.Unsetenv("SCRIBE_DENIED_SOURCES")
+		os.Unsetenv("SCRIBE_SOURCE_FILTER_DROP")
+	}()
+
+	loadEnvConfig(config)
+
+	allowed := config.Logging.AllowedSources
+	if len(allowed) != 2 || allowed[0] != "api" || allowed[1] != "internal-*" {
+		t.Errorf("expected allowed sources [api internal-*], got %v", allowed)
+	}
+	if len(config.Logging.DeniedSources) != 1 || config.Logging.DeniedSources[0] != "internal-sandbox" {
+		t.Errorf("expected denied sources [internal-sandbox], got %v", config.Logging.DeniedSources)
+	}
+	if !config.Logging.SourceFilterDrop {
+		t.Error("expected SourceFilterDrop true")
+	}
+}
+
+func TestLoadEnvConfig_Escalation(t *testing.T) {
+	config := DefaultConfig()
+	if config.Logging.EscalationWindow != 60 || len(config.Logging.EscalationThresholds) != 0 {
+		t.Errorf("expected a 60s window and no thresholds by default, got %+v", config.Logging)
+	}
+
+	os.Setenv("SCRIBE_ESCALATION_WINDOW", "300")
+	os.Setenv("SCRIBE_ESCALATION_THRESHOLDS", "error=10, critical=50,warning=many")
+	defer func() {
+		os.Unsetenv("SCRIBE_ESCALATION_WINDOW")
+		os.Unsetenv("SCRIBE_ESCALATION_THRESHOLDS")
+	}()
+
+	loadEnvConfig(config)
+
+	if config.Logging.EscalationWindow != 300 {
+		t.Errorf("expected window 300, got %d", config.Logging.EscalationWindow)
+	}
+	thresholds := config.Logging.EscalationThresholds
+	if len(thresholds) != 2 || thresholds["error"] != 10 || thresholds["critical"] != 50 {
+		t.Errorf("unexpected thresholds: %v", thresholds)
+	}
+}
+
+func TestLoadEnvConfig_Metrics(t *testing.T) {
+	config := DefaultConfig()
+	if config.Metrics.Prefix != "scribe_" || len(config.Metrics.Labels) != 0 {
+		t.Errorf("expected scribe_ prefix and no labels by default, got %+v", config.Metrics)
+	}
+
+	os.Setenv("SCRIBE_METRICS_PREFIX", "logs_")
+	os.Setenv("SCRIBE_METRICS_LABELS", "instance=scribe-1, env=prod,malformed")
+	defer func() {
+		os.Unsetenv("SCRIBE_METRICS_PREFIX")
+		os.Unsetenv("SCRIBE_METRICS_LABELS")
+	}()
+
+	loadEnvConfig(config)
+
+	if config.Metrics.Prefix != "logs_" {
+		t.Errorf("expected prefix logs_, got %q", config.Metrics.Prefix)
+	}
+	if len(config.Metrics.Labels) != 2 || config.Metrics.Labels["instance"] != "scribe-1" || config.Metrics.Labels["env"] != "prod" {
+		t.Errorf("unexpected labels: %v", config.Metrics.Labels)
+	}
+}
+
+func TestLoadEnvConfig_DisabledRoutes(t *testing.T) {
+	config := DefaultConfig()
+
+	os.Setenv("SCRIBE_DISABLED_ROUTES", "/api/export/*, /api/admin/*,")
+	defer os.Unsetenv("SCRIBE_DISABLED_ROUTES")
+
+	loadEnvConfig(config)
+
+	want := []string{"/api/export/*", "/api/admin/*"}
+	if len(config.Server.DisabledRoutes) != len(want) {
+		t.Fatalf("expected %v, got %v", want, config.Server.DisabledRoutes)
+	}
+	for i, route := range want {
+		if config.Server.DisabledRoutes[i] != route {
+			t.Errorf("route %d: expected %q, got %q", i, route, config.Server.DisabledRoutes[i])
+		}
+	}
+}
+
+func TestLoadEnvConfig_NoColorStandard(t *testing.T) {
+	config := DefaultConfig()
+
+	os.Setenv("NO_COLOR", "1")
+	defer os.Unsetenv("NO_COLOR")
+
+	loadEnvConfig(config)
+
+	if !config.Output.NoColor {
+		t.Error("expected NO_COLOR to disable colors")
+	}
+}
+
+func TestLoadConfig_SeverityColors(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	content := `{"output": {"severity_colors": {"warning": "magenta"}}}`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Output.SeverityColors["warning"] != "magenta" {
+		t.Errorf("expected warning color magenta, got %v", config.Output.SeverityColors)
+	}
+	if config.Output.Format != "table" {
+		t.Errorf("expected other output defaults to be kept, got format %q", config.Output.Format)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("expected default config to be valid, got %v", err)
+	}
+
+	config := DefaultConfig()
+	config.Server.Port = 70000
+	config.Server.GRPCPort = -1
+	config.Server.ReadHeaderTimeout = -1
+	config.Server.SSEReplayTTL = -1
+	config.Server.AdminUser = "ops"
+	config.Server.DisabledRoutes = []string{"/api/export/*", "api/admin"}
+	config.Server.RateLimitExempt = []string{"10.0.0.0/8", "scraper"}
+	config.Database.RetentionDays = -1
+	config.Database.StatsTime = "created_at"
+	config.Database.SlowQueryMS = -1
+	config.Logging.MinIngestSeverity = "verbose"
+	config.Logging.DeniedSources = []string{"rogue", " "}
+	config.Logging.MaxEventAge = -1
+	config.Logging.BodyAliases = map[string][]string{"duration ms": {"elapsed_ms"}, "status_code": {}}
+	config.Logging.RedactPatterns = []string{`\d{16}`, "("}
+	config.Logging.HTTPStatusSeverity = map[string]string{"404": "error", "429": "fatal"}
+	config.Logging.KeywordWeights = map[string]float64{"success": 2, "fatal": 1}
+	config.Logging.AnalysisMaxBodyDepth = -1
+	config.Logging.SeverityColorMap = map[string]string{"critical": "crimson"}
+	config.Logging.EscalationWindow = -1
+	config.Logging.EscalationThresholds = map[string]int{"critical": 1}
+	config.Pagination.List = queries.PageSize{Default: 50, Max: 10}
+	config.Pagination.MaxOffset = -1
+	config.Output.Format = "yaml"
+	config.Output.SeverityColors = map[string]string{"warning": "purple"}
+	config.Metrics.Labels = map[string]string{"env-name": "prod"}
+
+	err := config.Validate()
+	var problems ConfigErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+
+	want := []string{
+		"server.port",
+		"server.grpc_port",
+		"server.read_header_timeout",
+		"server.sse_replay_ttl",
+		"server.admin_user and server.admin_password",
+		"server.disabled_routes",
+		"server.rate_limit_exempt",
+		"database.retention_days",
+		"database.stats_time",
+		"database.slow_query_ms",
+		"logging.min_ingest_severity",
+		"logging.denied_sources",
+		"logging.max_event_age",
+		"logging.body_aliases: invalid canonical key",
+		"logging.body_aliases.status_code",
+		"logging.redact_patterns: invalid redaction pattern",
+		"logging.http_status_severity: status 429",
+		"logging.keyword_weights: unknown keyword class",
+		"logging.analysis_max_body_depth",
+		"logging.severity_color_map: severity critical",
+		"logging.escalation_window",
+		"logging.escalation_thresholds: severity critical",
+		"pagination.list.max",
+		"pagination.max_offset",
+		"metrics:",
+		"output.format",
+		"output.severity_colors.warning",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("expected %d problems, got %d: %v", len(want), len(problems), problems)
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(problems[i], prefix) {
+			t.Errorf("problem %d: expected prefix %q, got %q", i, prefix, problems[i])
+		}
+	}
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	config := DefaultConfig()
+	config.Server.AdminUser = "ops"
+	config.Server.AdminPassword = "s3cret"
+	config.Server.ExportSigningKey = "signing-s3cret"
+
+	redacted := config.Redacted()
+	if redacted.Server.AdminPassword == "s3cret" {
+		t.Error("expected admin password to be redacted")
+	}
+	if redacted.Server.ExportSigningKey == "signing-s3cret" {
+		t.Error("expected export signing key to be redacted")
+	}
+	if redacted.Server.AdminUser != "ops" {
+		t.Errorf("expected admin user to be kept, got %q", redacted.Server.AdminUser)
+	}
+	if config.Server.AdminPassword != "s3cret" {
+		t.Error("expected original config to be unchanged")
+	}
+
+	if got := DefaultConfig().Redacted().Server.AdminPassword; got != "" {
+		t.Errorf("expected unset password to stay empty, got %q", got)
+	}
+}
+
 func TestSaveConfig(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "subdir", "config.json")
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mx-scribe/scribe/internal/application/queries"
+	"github.com/mx-scribe/scribe/internal/infrastructure/export"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+var (
+	exportFormat      string
+	exportOut         string
+	exportGzip        bool
+	exportSeverity    string
+	exportMinSeverity string
+	exportSource      string
+	exportSearch      string
+	exportFrom        string
+	exportTo          string
+	exportLimit       int
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export logs to a file or bucket",
+	Long: `Export logs from the local SCRIBE database to a destination.
+
+Destinations:
+  -                  standard output (default)
+  ./path/logs.json   local file
+  s3://bucket/key    S3 bucket (requires a build with -tags s3)`,
+	Example: `  scribe export --format ndjson --out logs.ndjson
+  scribe export --format csv --severity error --out errors.csv
+  scribe export --format gob --gzip --out backup.gob.gz
+  scribe export --format ndjson --gzip --out s3://archive/scribe/logs.ndjson.gz`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dest, err := export.ParseDestination(exportOut)
+		if err != nil {
+			return err
+		}
+
+		// Connect to database
+		db, err := sqlite.NewDatabase(GetDBPath())
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+		db.SetUnknownSourceLabel(GetConfig().Database.UnknownSourceLabel)
+
+		// Run migrations (ensures table exists)
+		if err := sqlite.RunMigrations(db.Conn()); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+
+		request := queries.ExportLogsRequest{
+			Format:      queries.ExportFormat(exportFormat),
+			Severity:    exportSeverity,
+			MinSeverity: exportMinSeverity,
+			Source:      exportSource,
+			Search:      exportSearch,
+			FromDate:    exportFrom,
+			ToDate:      exportTo,
+			Limit:       exportLimit,
+		}
+
+		result, err := runExport(cmd.Context(), sqlite.NewLogRepository(db), request, dest, exportGzip)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := dest.(export.StdoutDestination); !ok {
+			fmt.Fprintf(os.Stderr, "Exported %d logs to %s\n", result.Count, dest)
+		}
+		if result.Truncated {
+			fmt.Fprintf(os.Stderr, "Warning: export truncated, %d of %d matching logs written (raise --limit)\n",
+				result.Count, result.TotalMatched)
+		}
+		return nil
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "json", "export format (json, ndjson, csv, gob)")
+	exportCmd.Flags().StringVarP(&exportOut, "out", "o", "-", "destination path, s3://bucket/key, or - for stdout")
+	exportCmd.Flags().BoolVar(&exportGzip, "gzip", false, "gzip-compress the export")
+	exportCmd.Flags().StringVarP(&exportSeverity, "severity", "s", "", "filter by severity")
+	exportCmd.Flags().StringVar(&exportMinSeverity, "min-severity", "", "filter by minimum severity")
+	exportCmd.Flags().StringVar(&exportSource, "source", "", "filter by source")
+	exportCmd.Flags().StringVar(&exportSearch, "search", "", "search in title and body")
+	exportCmd.Flags().StringVar(&exportFrom, "from", "", "only logs created on or after this date")
+	exportCmd.Flags().StringVar(&exportTo, "to", "", "only logs created on or before this date")
+	exportCmd.Flags().IntVarP(&exportLimit, "limit", "l", 0, "maximum number of logs to export (default: all)")
+	_ = exportCmd.RegisterFlagCompletionFunc("severity", completeSeverities)
+	_ = exportCmd.RegisterFlagCompletionFunc("min-severity", completeSeverities)
+
+	rootCmd.AddCommand(exportCmd)
+}
+
+// runExport streams logs matching request to dest. Every matching log is
+// exported unless request.Limit caps it. It returns the export result,
+// including whether it was truncated.
+func runExport(ctx context.Context, repo *sqlite.LogRepository, request queries.ExportLogsRequest, dest export.Destination, compress bool) (*queries.ExportLogsResponse, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// Reject a bad format before the destination is created
+	if err := request.Format.Validate(); err != nil {
+		return nil, err
+	}
+
+	writer, err := dest.Create(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := writeExport(ctx, writer, queries.NewExportLogsHandler(repo), request, compress)
+	if err != nil {
+		writer.Abort()
+		return nil, fmt.Errorf("failed to write export: %w", err)
+	}
+
+	if err := writer.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func writeExport(ctx context.Context, writer export.Writer, handler *queries.ExportLogsHandler, request queries.ExportLogsRequest, compress bool) (*queries.ExportLogsResponse, error) {
+	var out io.Writer = writer
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(writer)
+		out = gz
+	}
+
+	encoder, err := export.NewLogEncoder(out, request.Format)
+	if err != nil {
+		return nil, err
+	}
+	result, err := handler.Stream(ctx, request, encoder.Encode)
+	if err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mx-scribe/scribe/internal/application/queries"
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+	"github.com/mx-scribe/scribe/internal/infrastructure/export"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+func setupExportTest(t *testing.T) *sqlite.LogRepository {
+	t.Helper()
+
+	db, err := sqlite.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := sqlite.RunMigrations(db.Conn()); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	repo := sqlite.NewLogRepository(db)
+	for _, h := range []entities.LogHeader{
+		{Title: "Checkout started", Severity: valueobjects.SeverityInfo, Source: "shop"},
+		{Title: "Checkout aborted", Severity: valueobjects.SeverityError, Source: "shop"},
+		{Title: "Token refreshed", Severity: valueobjects.SeverityInfo, Source: "auth"},
+	} {
+		if err := repo.Create(entities.NewLog(h, nil)); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	return repo
+}
+
+func readNDJSONTitles(t *testing.T, file *os.File, compressed bool) []string {
+	t.Helper()
+
+	var scanner *bufio.Scanner
+	if compressed {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("export is not gzip: %v", err)
+		}
+		defer gz.Close()
+		scanner = bufio.NewScanner(gz)
+	} else {
+		scanner = bufio.NewScanner(file)
+	}
+
+	var titles []string
+	for scanner.Scan() {
+		var log entities.Log
+		if err := json.Unmarshal(scanner.Bytes(), &log); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", scanner.Text(), err)
+		}
+		titles = append(titles, log.Header.Title)
+	}
+	return titles
+}
+
+func TestRunExport_LocalFileHonorsFilters(t *testing.T) {
+	repo := setupExportTest(t)
+	path := filepath.Join(t.TempDir(), "shop.ndjson")
+
+	result, err := runExport(context.Background(), repo, queries.ExportLogsRequest{
+		Format: queries.ExportFormatNDJSON,
+		Source: "shop",
+	}, export.LocalDestination{Path: path}, false)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if result.Count != 2 || result.Truncated {
+		t.Errorf("expected 2 exported logs without truncation, got %d (truncated=%v)", result.Count, result.Truncated)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open export: %v", err)
+	}
+	defer file.Close()
+
+	titles := readNDJSONTitles(t, file, false)
+	if len(titles) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(titles))
+	}
+	for _, title := range titles {
+		if title == "Token refreshed" {
+			t.Error("source filter was not applied")
+		}
+	}
+}
+
+func TestRunExport_Gzip(t *testing.T) {
+	repo := setupExportTest(t)
+	path := filepath.Join(t.TempDir(), "errors.ndjson.gz")
+
+	result, err := runExport(context.Background(), repo, queries.ExportLogsRequest{
+		Format:      queries.ExportFormatNDJSON,
+		MinSeverity: "error",
+	}, export.LocalDestination{Path: path}, true)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if result.Count != 1 {
+		t.Errorf("expected 1 exported log, got %d", result.Count)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open export: %v", err)
+	}
+	defer file.Close()
+
+	titles := readNDJSONTitles(t, file, true)
+	if len(titles) != 1 || titles[0] != "Checkout aborted" {
+		t.Errorf("unexpected titles: %v", titles)
+	}
+}
+
+func TestRunExport_InvalidFormatWritesNothing(t *testing.T) {
+	repo := setupExportTest(t)
+	path := filepath.Join(t.TempDir(), "logs.xml")
+
+	_, err := runExport(context.Background(), repo, queries.ExportLogsRequest{
+		Format: "xml",
+	}, export.LocalDestination{Path: path}, false)
+	if err == nil {
+		t.Fatal("expected error for invalid format")
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected no file to be written")
+	}
+}
+
+func TestRunImport_RestoresGobExport(t *testing.T) {
+	source := setupExportTest(t)
+	if err := source.SetPinned(2, true); err != nil {
+		t.Fatalf("failed to pin log: %v", err)
+	}
+	want, _, err := source.FindAll(sqlite.LogFilters{Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to read source logs: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "backup.gob.gz")
+	if _, err := runExport(context.Background(), source, queries.ExportLogsRequest{
+		Format: queries.ExportFormatGob,
+	}, export.LocalDestination{Path: path}, true); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	db, err := sqlite.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create target database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := sqlite.RunMigrations(db.Conn()); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	target := sqlite.NewLogRepository(db)
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open export: %v", err)
+	}
+	defer file.Close()
+
+	count, err := runImport(context.Background(), target, file)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if count != len(want) {
+		t.Fatalf("expected %d imported logs, got %d", len(want), count)
+	}
+
+	got, _, err := target.FindAll(sqlite.LogFilters{Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to read imported logs: %v", err)
+	}
+	for i := range want {
+		if got[i].Header != want[i].Header || got[i].Metadata != want[i].Metadata || got[i].Pinned != want[i].Pinned {
+			t.Errorf("log %d differs after import: got %+v, want %+v", i, got[i], want[i])
+		}
+		if !got[i].CreatedAt.Equal(want[i].CreatedAt) || !got[i].IngestedAt.Equal(want[i].IngestedAt) {
+			t.Errorf("log %d timestamps differ after import", i)
+		}
+	}
+}
+
+func TestRunImport_RejectsNonBinaryInput(t *testing.T) {
+	db, err := sqlite.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := sqlite.RunMigrations(db.Conn()); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	_, err = runImport(context.Background(), sqlite.NewLogRepository(db), strings.NewReader(`{"title":"x"}`+"\n"))
+	if !errors.Is(err, export.ErrNotBinaryExport) {
+		t.Errorf("expected ErrNotBinaryExport, got %v", err)
+	}
+}
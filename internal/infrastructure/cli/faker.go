@@ -28,6 +28,9 @@ var (
 	fakerSeed       int64
 	fakerCategories string
 	fakerQuiet      bool
+	fakerRetries    int
+	fakerOut        string
+	fakerSamples    int
 )
 
 var fakerCmd = &cobra.Command{
@@ -43,6 +46,7 @@ Examples:
   scribe faker --count 100              # stop after 100 logs
   scribe faker --stress --rate 500      # 500 logs/second
   scribe faker --dry-run                # print logs without sending
+  scribe faker --count 500 --out logs.ndjson  # write logs to a file
   scribe faker --categories http,database  # only specific categories
 
 Categories: http, application, database, security, system, business, chaos`,
@@ -61,8 +65,13 @@ func init() {
 	fakerCmd.Flags().BoolVar(&fakerDryRun, "dry-run", false, "print logs without sending")
 	fakerCmd.Flags().Int64Var(&fakerSeed, "seed", 0, "random seed for reproducibility (0 = random)")
 	fakerCmd.Flags().StringVar(&fakerCategories, "categories", "", "comma-separated categories to generate")
+	fakerCmd.Flags().IntVar(&fakerRetries, "max-retries", 3, "retries per log for transient failures (connection errors, 429, 5xx)")
+	fakerCmd.Flags().StringVar(&fakerOut, "out", "", "write logs as NDJSON to this file instead of sending them")
+	fakerCmd.Flags().IntVar(&fakerSamples, "latency-samples", faker.DefaultLatencySamples, "latencies kept for percentiles (stress mode); bounds memory on long runs")
 	fakerCmd.Flags().BoolVarP(&fakerQuiet, "quiet", "q", false, "minimal output")
 
+	_ = fakerCmd.RegisterFlagCompletionFunc("categories", completeFakerCategories)
+
 	rootCmd.AddCommand(fakerCmd)
 }
 
@@ -77,25 +86,45 @@ func runFaker(cmd *cobra.Command, args []string) error {
 	}
 
 	// Build config
+	defaults := faker.DefaultConfig()
 	cfg := faker.Config{
-		Endpoint:   fakerEndpoint,
-		MinDelay:   time.Duration(fakerMinDelay) * time.Second,
-		MaxDelay:   time.Duration(fakerMaxDelay) * time.Second,
-		Duration:   time.Duration(fakerDuration) * time.Second,
-		Count:      fakerCount,
-		Chaos:      fakerChaos,
-		Stress:     fakerStress,
-		StressRate: fakerRate,
-		DryRun:     fakerDryRun,
-		Seed:       fakerSeed,
-		Categories: categories,
-		Quiet:      fakerQuiet,
-		Verbose:    IsVerbose(),
+		Endpoint:       fakerEndpoint,
+		MinDelay:       time.Duration(fakerMinDelay) * time.Second,
+		MaxDelay:       time.Duration(fakerMaxDelay) * time.Second,
+		Duration:       time.Duration(fakerDuration) * time.Second,
+		Count:          fakerCount,
+		Chaos:          fakerChaos,
+		Stress:         fakerStress,
+		StressRate:     fakerRate,
+		LatencySamples: fakerSamples,
+		MaxRetries:     fakerRetries,
+		RetryBaseDelay: defaults.RetryBaseDelay,
+		RetryMaxDelay:  defaults.RetryMaxDelay,
+		DryRun:         fakerDryRun,
+		Seed:           fakerSeed,
+		Categories:     categories,
+		Quiet:          fakerQuiet,
+		Verbose:        IsVerbose(),
 	}
 
-	// Create faker
+	// Create faker and pick where logs go
 	f := faker.New(cfg)
 
+	var sink faker.Sink
+	switch {
+	case cfg.DryRun:
+		// Nothing is sent; logs are printed by the callback
+	case fakerOut != "":
+		fileSink, err := faker.NewFileSink(fakerOut)
+		if err != nil {
+			return err
+		}
+		defer fileSink.Close()
+		sink = fileSink
+	default:
+		sink = faker.NewHTTPSink(cfg)
+	}
+
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -118,12 +147,12 @@ func runFaker(cmd *cobra.Command, args []string) error {
 
 	// Run appropriate mode
 	if cfg.Stress {
-		return runStressMode(ctx, f, cfg)
+		return runStressMode(ctx, f, sink, cfg)
 	}
-	return runRealisticMode(ctx, f, cfg)
+	return runRealisticMode(ctx, f, sink, cfg)
 }
 
-func runRealisticMode(ctx context.Context, f *faker.Faker, cfg faker.Config) error {
+func runRealisticMode(ctx context.Context, f *faker.Faker, sink faker.Sink, cfg faker.Config) error {
 	// Print header
 	if !cfg.Quiet {
 		mode := "realistic"
@@ -136,7 +165,7 @@ func runRealisticMode(ctx context.Context, f *faker.Faker, cfg faker.Config) err
 
 		fmt.Println()
 		fmt.Println("🎭 SCRIBE Faker starting...")
-		fmt.Printf("   Endpoint:  %s\n", cfg.Endpoint)
+		printFakerTarget(cfg)
 		fmt.Printf("   Interval:  %ds - %ds\n", int(cfg.MinDelay.Seconds()), int(cfg.MaxDelay.Seconds()))
 		fmt.Printf("   Mode:      %s\n", mode)
 		if cfg.Count > 0 {
@@ -149,7 +178,7 @@ func runRealisticMode(ctx context.Context, f *faker.Faker, cfg faker.Config) err
 	}
 
 	// Run
-	err := f.Run(ctx, func(log faker.LogEntry, nextDelay time.Duration, sendErr error) {
+	err := f.Run(ctx, sink, func(log faker.LogEntry, nextDelay time.Duration, sendErr error) {
 		if cfg.DryRun && !cfg.Quiet {
 			// Print full JSON in dry-run mode
 			data, _ := json.MarshalIndent(log, "", "  ")
@@ -196,6 +225,10 @@ func runRealisticMode(ctx context.Context, f *faker.Faker, cfg faker.Config) err
 		fmt.Printf("   Duration:  %s\n", time.Since(stats.StartTime).Truncate(time.Second))
 		fmt.Printf("   Sent:      %d logs\n", stats.Sent.Load())
 		fmt.Printf("   Errors:    %d failed requests\n", stats.Errors.Load())
+		printErrorBreakdown(stats)
+		if httpSink, ok := sink.(*faker.HTTPSink); ok {
+			fmt.Printf("   Retries:   %d\n", httpSink.Retries())
+		}
 		fmt.Printf("   Rate:      %.2f logs/s average\n", stats.Rate())
 	}
 
@@ -205,12 +238,33 @@ func runRealisticMode(ctx context.Context, f *faker.Faker, cfg faker.Config) err
 	return err
 }
 
-func runStressMode(ctx context.Context, f *faker.Faker, cfg faker.Config) error {
+// printErrorBreakdown prints the failed sends per error class, if any, so a
+// summary shows whether the server struggled (5xx, timeouts) or the client
+// is misconfigured (4xx, refused connections).
+func printErrorBreakdown(stats *faker.Stats) {
+	breakdown := stats.ErrorBreakdown()
+	for _, class := range faker.ErrorClasses {
+		if n := breakdown[class]; n > 0 {
+			fmt.Printf("     %-19s %d\n", string(class)+":", n)
+		}
+	}
+}
+
+// printFakerTarget prints where generated logs go.
+func printFakerTarget(cfg faker.Config) {
+	if fakerOut != "" && !cfg.DryRun {
+		fmt.Printf("   Output:    %s\n", fakerOut)
+		return
+	}
+	fmt.Printf("   Endpoint:  %s\n", cfg.Endpoint)
+}
+
+func runStressMode(ctx context.Context, f *faker.Faker, sink faker.Sink, cfg faker.Config) error {
 	// Print header
 	if !cfg.Quiet {
 		fmt.Println()
 		fmt.Println("🔥 SCRIBE Faker STRESS TEST")
-		fmt.Printf("   Endpoint:  %s\n", cfg.Endpoint)
+		printFakerTarget(cfg)
 		fmt.Printf("   Rate:      %d logs/s\n", cfg.StressRate)
 		if cfg.Duration > 0 {
 			fmt.Printf("   Duration:  %ds\n", int(cfg.Duration.Seconds()))
@@ -223,7 +277,7 @@ func runStressMode(ctx context.Context, f *faker.Faker, cfg faker.Config) error
 	}
 
 	// Run
-	err := f.RunStress(ctx, func(sent, errors int64, rate float64, p95 time.Duration) {
+	err := f.RunStress(ctx, sink, func(sent, errors int64, rate float64, p95 time.Duration) {
 		if cfg.Quiet {
 			return
 		}
@@ -248,8 +302,12 @@ func runStressMode(ctx context.Context, f *faker.Faker, cfg faker.Config) error
 			successRate := float64(stats.Sent.Load()) / float64(total) * 100
 			fmt.Printf("   Success:     %d (%.1f%%)\n", stats.Sent.Load(), successRate)
 			fmt.Printf("   Failed:      %d (%.1f%%)\n", stats.Errors.Load(), 100-successRate)
+			printErrorBreakdown(stats)
 		}
 
+		if httpSink, ok := sink.(*faker.HTTPSink); ok {
+			fmt.Printf("   Retries:     %d\n", httpSink.Retries())
+		}
 		fmt.Printf("   Rate:        %.1f logs/s average\n", stats.Rate())
 		fmt.Println("   Latency:")
 		fmt.Printf("     p50:  %s\n", stats.Percentile(50).Truncate(time.Millisecond))
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/infrastructure/export"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// importBatchSize is the number of logs inserted per transaction.
+const importBatchSize = 500
+
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import a binary export into the database",
+	Long: `Import logs from a binary export (scribe export --format gob) into the
+local SCRIBE database. Reads standard input when no file or "-" is given;
+gzip-compressed exports are detected automatically.
+
+Logs keep their derived metadata, pin state and timestamps. They are given
+new IDs, so an export can be imported into a database that already has logs.`,
+	Example: `  scribe export --format gob --out backup.gob
+  scribe --db restored.db import backup.gob`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var in io.Reader = os.Stdin
+		name := "standard input"
+		if len(args) == 1 && args[0] != "-" {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open import: %w", err)
+			}
+			defer file.Close()
+			in, name = file, args[0]
+		}
+
+		// Connect to database
+		db, err := sqlite.NewDatabase(GetDBPath())
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+		db.SetCompressBodies(GetConfig().Database.CompressBodies)
+
+		// Run migrations (ensures table exists)
+		if err := sqlite.RunMigrations(db.Conn()); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+
+		count, err := runImport(cmd.Context(), sqlite.NewLogRepository(db), in)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Imported %d logs from %s\n", count, name)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+}
+
+// runImport inserts every log of the binary export read from r in batched
+// transactions and restores their pin state. It returns the number of logs
+// imported; batches committed before an error are kept.
+func runImport(ctx context.Context, repo *sqlite.LogRepository, r io.Reader) (int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// Exports written with --gzip are read transparently
+	buffered := bufio.NewReader(r)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read gzip import: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = buffered
+	}
+
+	decoder, err := export.NewLogDecoder(r)
+	if err != nil {
+		return 0, err
+	}
+
+	imported := 0
+	batch := make([]*entities.Log, 0, importBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := repo.CreateBatchContext(ctx, batch); err != nil {
+			return fmt.Errorf("failed to import logs: %w", err)
+		}
+		for _, log := range batch {
+			if log.Pinned {
+				if err := repo.SetPinnedContext(ctx, log.ID, true); err != nil {
+					return fmt.Errorf("failed to restore pin on log %d: %w", log.ID, err)
+				}
+			}
+		}
+		imported += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		log, err := decoder.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return imported, err
+		}
+
+		batch = append(batch, log)
+		if len(batch) >= importBatchSize {
+			if err := flush(); err != nil {
+				return imported, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return imported, err
+	}
+	return imported, nil
+}
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/mx-scribe/scribe/internal/application/commands"
+	"github.com/mx-scribe/scribe/internal/domain/services"
 	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
 )
 
@@ -40,20 +42,36 @@ var logCmd = &cobra.Command{
 			return fmt.Errorf("failed to connect to database: %w", err)
 		}
 		defer db.Close()
+		db.SetCompressBodies(GetConfig().Database.CompressBodies)
 
 		// Run migrations
 		if err := sqlite.RunMigrations(db.Conn()); err != nil {
 			return fmt.Errorf("failed to run migrations: %w", err)
 		}
 
-		// Parse body JSON if provided
+		// Parse body JSON if provided, keeping large integers exact
 		var body map[string]any
 		if logBody != "" {
-			if err := json.Unmarshal([]byte(logBody), &body); err != nil {
+			dec := json.NewDecoder(strings.NewReader(logBody))
+			dec.UseNumber()
+			if err := dec.Decode(&body); err != nil {
 				return fmt.Errorf("invalid JSON body: %w", err)
 			}
 		}
 
+		matcher, err := services.NewPatternMatcherWithOptions(GetConfig().Logging.PatternMatcherOptions())
+		if err != nil {
+			return fmt.Errorf("invalid pattern matching config: %w", err)
+		}
+
+		var colorizer *services.SeverityColorizer
+		if GetConfig().Logging.ColorFromSeverity {
+			colorizer, err = services.NewSeverityColorizer(GetConfig().Logging.SeverityColorMap)
+			if err != nil {
+				return fmt.Errorf("invalid severity color config: %w", err)
+			}
+		}
+
 		// Create handler and execute
 		repo := sqlite.NewLogRepository(db)
 		handler := commands.NewCreateLogHandler(repo)
@@ -65,6 +83,8 @@ var logCmd = &cobra.Command{
 			Color:       logColor,
 			Description: logDescription,
 			Body:        body,
+			Matcher:     matcher,
+			Colorizer:   colorizer,
 		}
 
 		output, err := handler.Handle(input)
@@ -87,6 +107,7 @@ func init() {
 	logCmd.Flags().StringVarP(&logColor, "color", "c", "", "log color (tailwind color name)")
 	logCmd.Flags().StringVarP(&logDescription, "description", "d", "", "log description")
 	logCmd.Flags().StringVarP(&logBody, "body", "b", "", "log body as JSON")
+	_ = logCmd.RegisterFlagCompletionFunc("severity", completeSeverities)
 
 	rootCmd.AddCommand(logCmd)
 }
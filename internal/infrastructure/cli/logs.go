@@ -1,11 +1,13 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
@@ -34,6 +36,7 @@ var logsCmd = &cobra.Command{
 			return fmt.Errorf("failed to connect to database: %w", err)
 		}
 		defer db.Close()
+		db.SetUnknownSourceLabel(GetConfig().Database.UnknownSourceLabel)
 
 		// Run migrations (ensures table exists)
 		if err := sqlite.RunMigrations(db.Conn()); err != nil {
@@ -84,12 +87,21 @@ func init() {
 	logsCmd.Flags().StringVar(&logsSearch, "search", "", "search in title and body")
 	logsCmd.Flags().StringVarP(&logsFormat, "format", "f", "table", "output format (table, json, csv)")
 
+	_ = logsCmd.RegisterFlagCompletionFunc("severity", completeSeverities)
+
 	rootCmd.AddCommand(logsCmd)
 }
 
 //nolint:unparam // error return for consistency with outputLogsJSON/CSV
 func outputLogsTable(logs []*entities.Log, total int) error {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	return writeLogsTable(NewOutput(), logs, total)
+}
+
+// writeLogsTable writes logs as an aligned table, coloring each row by
+// severity. Rows are colored after alignment so ANSI codes don't skew it.
+func writeLogsTable(out *Output, logs []*entities.Log, total int) error {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
 	_, _ = fmt.Fprintln(w, "ID\tSEVERITY\tSOURCE\tTITLE\tCREATED")
 	_, _ = fmt.Fprintln(w, "--\t--------\t------\t-----\t-------")
 
@@ -111,12 +123,22 @@ func outputLogsTable(logs []*entities.Log, total int) error {
 			created,
 		)
 	}
-	_ = w.Flush()
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for i, line := range lines {
+		if i >= 2 {
+			line = out.colorize(line, out.SeverityColor(string(logs[i-2].EffectiveSeverity())))
+		}
+		_, _ = fmt.Fprintln(out.Writer, line)
+	}
 
 	// Show pagination info
 	showing := len(logs)
 	if logsOffset > 0 || total > showing {
-		fmt.Printf("\nShowing %d-%d of %d logs\n", logsOffset+1, logsOffset+showing, total)
+		_, _ = fmt.Fprintf(out.Writer, "\nShowing %d-%d of %d logs\n", logsOffset+1, logsOffset+showing, total)
 	}
 
 	return nil
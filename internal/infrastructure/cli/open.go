@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"net"
+	"os/exec"
+	"runtime"
+	"strconv"
+)
+
+// openBrowser launches the default browser on url. It is a variable so tests
+// can observe calls without starting a browser.
+var openBrowser = func(url string) error {
+	name, args := browserCommand(runtime.GOOS, url)
+	return exec.Command(name, args...).Start()
+}
+
+// browserCommand returns the command that opens url in the default browser
+// on goos.
+func browserCommand(goos, url string) (string, []string) {
+	switch goos {
+	case "darwin":
+		return "open", []string{url}
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", url}
+	default:
+		return "xdg-open", []string{url}
+	}
+}
+
+// dashboardURL returns the dashboard URL for a server bound to host and
+// listening on addr. Wildcard and empty hosts are reached via localhost.
+func dashboardURL(host string, addr net.Addr) string {
+	port := 0
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		port = tcp.Port
+	}
+
+	switch host {
+	case "", "0.0.0.0", "::", "[::]":
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(port)) + "/"
+}
+
+// dashboardOpener returns the listen callback for serve --open: it opens
+// the dashboard once the server accepts connections and only warns when no
+// browser can be started, as on headless machines. Returns nil when open
+// is false.
+func dashboardOpener(open bool, host string, out *Output) func(net.Addr) {
+	if !open {
+		return nil
+	}
+	return func(addr net.Addr) {
+		url := dashboardURL(host, addr)
+		if err := openBrowser(url); err != nil {
+			out.Warning("Could not open browser at %s: %v", url, err)
+			return
+		}
+		out.Verbose("Opened dashboard at %s", url)
+	}
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestDashboardURL(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.IPv4zero, Port: 8080}
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"0.0.0.0", "http://localhost:8080/"},
+		{"", "http://localhost:8080/"},
+		{"::", "http://localhost:8080/"},
+		{"127.0.0.1", "http://127.0.0.1:8080/"},
+		{"scribe.internal", "http://scribe.internal:8080/"},
+		{"::1", "http://[::1]:8080/"},
+	}
+	for _, tt := range tests {
+		if got := dashboardURL(tt.host, addr); got != tt.want {
+			t.Errorf("dashboardURL(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestBrowserCommand(t *testing.T) {
+	tests := []struct {
+		goos string
+		want string
+	}{
+		{"linux", "xdg-open http://localhost:8080/"},
+		{"freebsd", "xdg-open http://localhost:8080/"},
+		{"darwin", "open http://localhost:8080/"},
+		{"windows", "rundll32 url.dll,FileProtocolHandler http://localhost:8080/"},
+	}
+	for _, tt := range tests {
+		name, args := browserCommand(tt.goos, "http://localhost:8080/")
+		if got := strings.Join(append([]string{name}, args...), " "); got != tt.want {
+			t.Errorf("browserCommand(%q) = %q, want %q", tt.goos, got, tt.want)
+		}
+	}
+}
+
+// stubOpenBrowser replaces openBrowser for the test, recording the URLs it
+// is called with and returning err.
+func stubOpenBrowser(t *testing.T, err error) *[]string {
+	t.Helper()
+	var opened []string
+	original := openBrowser
+	openBrowser = func(url string) error {
+		opened = append(opened, url)
+		return err
+	}
+	t.Cleanup(func() { openBrowser = original })
+	return &opened
+}
+
+func TestDashboardOpener(t *testing.T) {
+	opened := stubOpenBrowser(t, nil)
+	out := &Output{Writer: &bytes.Buffer{}, NoColor: true}
+
+	if dashboardOpener(false, "0.0.0.0", out) != nil {
+		t.Fatal("expected no listen callback without --open")
+	}
+
+	onListen := dashboardOpener(true, "0.0.0.0", out)
+	if onListen == nil {
+		t.Fatal("expected a listen callback with --open")
+	}
+	if len(*opened) != 0 {
+		t.Fatal("expected no browser before the listener is up")
+	}
+
+	onListen(&net.TCPAddr{IP: net.IPv4zero, Port: 9090})
+	if len(*opened) != 1 || (*opened)[0] != "http://localhost:9090/" {
+		t.Errorf("expected dashboard opened once at localhost:9090, got %v", *opened)
+	}
+}
+
+func TestDashboardOpener_NoBrowser(t *testing.T) {
+	stubOpenBrowser(t, errors.New(`exec: "xdg-open": executable file not found in $PATH`))
+	var buf bytes.Buffer
+	out := &Output{Writer: &buf, NoColor: true}
+
+	dashboardOpener(true, "127.0.0.1", out)(&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080})
+
+	if !strings.Contains(buf.String(), "Could not open browser at http://127.0.0.1:8080/") {
+		t.Errorf("expected a warning when no browser is available, got %q", buf.String())
+	}
+}
@@ -24,16 +24,21 @@ type Output struct {
 	Format     OutputFormat
 	NoColor    bool
 	TimeFormat string
+
+	// SeverityColors overrides the default ANSI color per severity
+	// (lowercase severity to ANSI code).
+	SeverityColors map[string]string
 }
 
 // NewOutput creates a new Output with the current settings.
 func NewOutput() *Output {
 	config := GetConfig()
 	return &Output{
-		Writer:     os.Stdout,
-		Format:     OutputFormat(GetOutputFormat()),
-		NoColor:    IsNoColor(),
-		TimeFormat: config.Output.TimeFormat,
+		Writer:         os.Stdout,
+		Format:         OutputFormat(GetOutputFormat()),
+		NoColor:        IsNoColor(),
+		TimeFormat:     config.Output.TimeFormat,
+		SeverityColors: SeverityColorOverrides(config.Output.SeverityColors),
 	}
 }
 
@@ -100,19 +105,16 @@ func (o *Output) writeTable(headers []string, rows []TableRow) error {
 	// Write headers if provided
 	if len(headers) > 0 {
 		headerLine := strings.Join(headers, "\t")
-		if !o.NoColor {
-			headerLine = colorize(headerLine, ColorBold)
-		}
-		fmt.Fprintln(w, headerLine)
+		fmt.Fprintln(w, o.colorize(headerLine, ColorBold))
 	}
 
 	// Write rows
 	for _, row := range rows {
 		values := row.Values
-		if !o.NoColor && row.Color != "" {
+		if row.Color != "" {
 			// Apply color to the entire row
 			for i, v := range values {
-				values[i] = colorize(v, row.Color)
+				values[i] = o.colorize(v, row.Color)
 			}
 		}
 		fmt.Fprintln(w, strings.Join(values, "\t"))
@@ -147,7 +149,50 @@ const (
 	ColorGray    = "\033[90m"
 )
 
-// SeverityColor returns the ANSI color code for a severity level.
+// colorNames maps the color names accepted in config to ANSI codes.
+var colorNames = map[string]string{
+	"bold":    ColorBold,
+	"red":     ColorRed,
+	"green":   ColorGreen,
+	"yellow":  ColorYellow,
+	"blue":    ColorBlue,
+	"magenta": ColorMagenta,
+	"cyan":    ColorCyan,
+	"white":   ColorWhite,
+	"gray":    ColorGray,
+}
+
+// ParseColorName returns the ANSI code for a color name such as "magenta".
+func ParseColorName(name string) (string, bool) {
+	code, ok := colorNames[strings.ToLower(strings.TrimSpace(name))]
+	return code, ok
+}
+
+// SeverityColorOverrides converts configured severity colors (severity to
+// color name) into ANSI codes. Unknown color names are skipped.
+func SeverityColorOverrides(names map[string]string) map[string]string {
+	if len(names) == 0 {
+		return nil
+	}
+	overrides := make(map[string]string, len(names))
+	for severity, name := range names {
+		if code, ok := ParseColorName(name); ok {
+			overrides[strings.ToLower(severity)] = code
+		}
+	}
+	return overrides
+}
+
+// SeverityColor returns the ANSI color code for a severity level, using the
+// configured overrides before the defaults.
+func (o *Output) SeverityColor(severity string) string {
+	if code, ok := o.SeverityColors[strings.ToLower(severity)]; ok {
+		return code
+	}
+	return SeverityColor(severity)
+}
+
+// SeverityColor returns the default ANSI color code for a severity level.
 func SeverityColor(severity string) string {
 	switch strings.ToLower(severity) {
 	case "critical":
@@ -175,6 +220,14 @@ func colorize(text, color string) string {
 	return color + text + ColorReset
 }
 
+// colorize wraps text with ANSI color codes unless colors are disabled.
+func (o *Output) colorize(text, color string) string {
+	if o.NoColor {
+		return text
+	}
+	return colorize(text, color)
+}
+
 // Success prints a success message.
 func (o *Output) Success(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
 )
 
 func TestNewOutput(t *testing.T) {
@@ -270,6 +273,83 @@ func TestSeverityColor(t *testing.T) {
 	}
 }
 
+func TestOutput_SeverityColorOverride(t *testing.T) {
+	out := &Output{
+		SeverityColors: SeverityColorOverrides(map[string]string{
+			"Warning": "magenta",
+			"info":    "not-a-color",
+		}),
+	}
+
+	if got := out.SeverityColor("warning"); got != ColorMagenta {
+		t.Errorf("expected overridden warning color %q, got %q", ColorMagenta, got)
+	}
+	if got := out.SeverityColor("info"); got != ColorBlue {
+		t.Errorf("expected unknown color name to fall back to default %q, got %q", ColorBlue, got)
+	}
+	if got := out.SeverityColor("error"); got != ColorRed {
+		t.Errorf("expected default error color %q, got %q", ColorRed, got)
+	}
+}
+
+func TestWriteLogsTable_SeverityColors(t *testing.T) {
+	warning := entities.NewLog(entities.LogHeader{Title: "Disk almost full", Severity: valueobjects.SeverityWarning}, nil)
+	info := entities.NewLog(entities.LogHeader{Title: "Started", Severity: valueobjects.SeverityInfo}, nil)
+	logs := []*entities.Log{warning, info}
+
+	var buf bytes.Buffer
+	out := &Output{
+		Writer:         &buf,
+		SeverityColors: SeverityColorOverrides(map[string]string{"warning": "magenta"}),
+	}
+	if err := writeLogsTable(out, logs, len(logs)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d: %q", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[2], ColorMagenta) {
+		t.Errorf("expected warning row in magenta, got %q", lines[2])
+	}
+	if !strings.HasPrefix(lines[3], ColorBlue) {
+		t.Errorf("expected info row in blue, got %q", lines[3])
+	}
+
+	buf.Reset()
+	out.NoColor = true
+	if err := writeLogsTable(out, logs, len(logs)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("expected no ANSI codes with NoColor, got %q", buf.String())
+	}
+}
+
+func TestOutput_NoColorSuppressesAllCodes(t *testing.T) {
+	var buf bytes.Buffer
+	out := &Output{
+		Writer:         &buf,
+		Format:         FormatTable,
+		NoColor:        true,
+		SeverityColors: map[string]string{"warning": ColorMagenta},
+	}
+
+	_ = out.Print(TableData{
+		Headers: []string{"Severity"},
+		Rows:    []TableRow{{Values: []string{"warning"}, Color: out.SeverityColor("warning")}},
+	})
+	out.Success("ok")
+	out.Error("failed")
+	out.Info("note")
+	out.Warning("careful")
+
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("expected no ANSI codes with NoColor, got %q", buf.String())
+	}
+}
+
 func TestColorize(t *testing.T) {
 	result := colorize("test", ColorRed)
 	if !strings.HasPrefix(result, ColorRed) {
@@ -40,12 +40,72 @@ Configuration:
   Environment variables (override config file):
     SCRIBE_PORT             Server port
     SCRIBE_HOST             Server host
+    SCRIBE_GRPC_PORT        gRPC ingestion port, -tags grpc builds (0 disables)
+    SCRIBE_MAX_BODY_BYTES   Request body limit in bytes (0 disables)
+    SCRIBE_SSE_COALESCE_THRESHOLD
+                            Live events/sec before batching (0 disables)
+    SCRIBE_SSE_REPLAY_TTL   Seconds live events are kept for Last-Event-ID
+                            replay across restarts (0 disables)
+    SCRIBE_ADMIN_USER       Basic Auth user for /api/admin endpoints
+    SCRIBE_ADMIN_PASSWORD   Basic Auth password for /api/admin endpoints
+    SCRIBE_EXPORT_SIGNING_KEY
+                            HMAC key for signed export URLs (default: random)
+    SCRIBE_TENANCY          Scope requests to the X-Tenant header (true/1)
+    SCRIBE_READ_ONLY        Reject writes to /api with 503 (true/1)
+    SCRIBE_DISABLED_ROUTES  Routes answering 404, e.g. /api/export/*,/api/admin/*
+    SCRIBE_RATE_LIMIT_EXEMPT
+                            IPs, CIDRs or key:<key> API keys bypassing the
+                            request rate limit
     SCRIBE_DB_PATH          Database file path
     SCRIBE_RETENTION_DAYS   Log retention in days
+    SCRIBE_STATS_TIME       Time used by stats: event_time or ingested_at
+    SCRIBE_RETENTION_TIME   Time used by retention: event_time or ingested_at
+    SCRIBE_COMPRESS_BODIES  Store new log bodies gzip-compressed (true/1)
+    SCRIBE_UNKNOWN_SOURCE_LABEL
+                            Source reported for logs without one
+                            (default: unknown)
     SCRIBE_DEFAULT_SEVERITY Default log severity
     SCRIBE_DEFAULT_SOURCE   Default log source
+    SCRIBE_AUTO_ANALYZE     Run pattern matching on ingested logs (true/1)
+    SCRIBE_SOURCE_RATE_LIMIT
+                            Logs/sec allowed per source (0 disables)
+    SCRIBE_SOURCE_RATE_BURST
+                            Burst allowed per source (default: the rate)
+    SCRIBE_SOURCE_RATE_DROP Drop excess logs instead of returning 429 (true/1)
+    SCRIBE_MIN_INGEST_SEVERITY
+                            Reject logs below this severity on ingestion
+    SCRIBE_MIN_INGEST_SEVERITY_DROP
+                            Drop those logs (204) instead of rejecting (true/1)
+    SCRIBE_ALLOWED_SOURCES  Only accept logs from these sources, e.g. api,internal-*
+    SCRIBE_DENIED_SOURCES   Reject logs from these sources (wins over allowed)
+    SCRIBE_SOURCE_FILTER_DROP
+                            Drop those logs (204) instead of rejecting (true/1)
+    SCRIBE_MAX_EVENT_AGE    Reject logs whose timestamp is older (seconds, 0 disables)
+    SCRIBE_NORMALIZE_BODY   Copy aliased body fields to canonical keys (true/1)
+    SCRIBE_REDACT           Mask secrets in log bodies on ingest (true/1)
+    SCRIBE_HTTP_STATUS_SEVERITY
+                            HTTP status severity overrides, e.g. 404=error
+    SCRIBE_KEYWORD_SCORING  Weighted keyword scoring instead of first match (true/1)
+    SCRIBE_ANALYSIS_MAX_BODY_BYTES
+                            Body bytes read by pattern matching (0 = 64 KiB)
+    SCRIBE_ANALYSIS_MAX_BODY_DEPTH
+                            Body nesting read by pattern matching (0 = 10)
+    SCRIBE_COLOR_FROM_SEVERITY
+                            Derive a color from severity for logs without
+                            one (true/1)
+    SCRIBE_SEVERITY_COLOR_MAP
+                            Derived color overrides, e.g. critical=rose
+    SCRIBE_ESCALATION_WINDOW
+                            Window for escalation thresholds in seconds
+                            (default: 60)
+    SCRIBE_ESCALATION_THRESHOLDS
+                            Occurrences raising a recurring log's severity,
+                            e.g. error=10,critical=50
+    SCRIBE_METRICS_PREFIX   Prometheus series prefix (default: scribe_)
+    SCRIBE_METRICS_LABELS   Prometheus labels, e.g. instance=a,env=prod
     SCRIBE_OUTPUT_FORMAT    Output format (table, json, plain)
     SCRIBE_NO_COLOR         Disable colors (true/1)
+    NO_COLOR                Disable colors (any value)
     SCRIBE_VERBOSE          Verbose output (true/1)`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Load configuration
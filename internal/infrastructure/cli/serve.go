@@ -2,19 +2,19 @@ package cli
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
 
 	"github.com/mx-scribe/scribe/internal/infrastructure/http"
-	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+	"github.com/mx-scribe/scribe/internal/infrastructure/rpc"
 	"github.com/mx-scribe/scribe/web"
 )
 
 var (
-	servePort int
-	serveHost string
+	servePort                int
+	serveHost                string
+	serveAllowSchemaMismatch bool
+	serveOpen                bool
 )
 
 var serveCmd = &cobra.Command{
@@ -33,36 +33,55 @@ var serveCmd = &cobra.Command{
 			serveHost = config.Server.Host
 		}
 
-		// Ensure database directory exists
+		// Connect to the database and run migrations
 		dbPath := GetDBPath()
-		if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
-			return fmt.Errorf("failed to create database directory: %w", err)
-		}
-
 		out.Verbose("Database path: %s", dbPath)
 
-		// Connect to database
-		db, err := sqlite.NewDatabase(dbPath)
+		db, schema, err := OpenDatabase(config, dbPath)
 		if err != nil {
-			return fmt.Errorf("failed to connect to database: %w", err)
+			return err
 		}
 		defer db.Close()
 
-		// Run migrations
-		if err := sqlite.RunMigrations(db.Conn()); err != nil {
-			return fmt.Errorf("failed to run migrations: %w", err)
+		// Refuse to serve against a schema the binary doesn't expect
+		if err := schema.Err(); err != nil {
+			if !serveAllowSchemaMismatch {
+				return fmt.Errorf("%w (use --allow-schema-mismatch to start anyway)", err)
+			}
+			out.Warning("%v; starting anyway", err)
 		}
 
-		out.Verbose("Database initialized")
+		out.Verbose("Database initialized (schema version %d)", schema.Current)
 
 		// Create and start server
-		server := http.NewServer(db)
+		server, err := NewServer(db, config)
+		if err != nil {
+			return err
+		}
+		server.SetAllowSchemaMismatch(serveAllowSchemaMismatch)
+		server.SetOnReload(configReloader(server, out))
 
 		// Set embedded web assets
 		server.SetStaticFS(web.DistFS)
+		server.SetOnListen(dashboardOpener(serveOpen, serveHost, out))
+
+		if config.Server.GRPCPort > 0 {
+			stop, err := rpc.Start(fmt.Sprintf("%s:%d", serveHost, config.Server.GRPCPort), db, rpc.Options{
+				Ingest:   server.IngestOptions(),
+				Hub:      server.SSEHub(),
+				Tenancy:  config.Server.Tenancy,
+				ReadOnly: server.ReadOnly,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to start gRPC ingestion: %w", err)
+			}
+			defer stop()
+			out.Info("Serving gRPC ingestion on %s:%d", serveHost, config.Server.GRPCPort)
+		}
 
 		out.Info("Starting SCRIBE server on %s:%d", serveHost, servePort)
-		out.Verbose("Read timeout: %ds, Write timeout: %ds", config.Server.ReadTimeout, config.Server.WriteTimeout)
+		out.Verbose("Read timeout: %ds, Read header timeout: %ds, Write timeout: %ds, Idle timeout: %ds, Operation timeout: %ds",
+			config.Server.ReadTimeout, config.Server.ReadHeaderTimeout, config.Server.WriteTimeout, config.Server.IdleTimeout, config.Server.OperationTimeout)
 
 		return server.Start(servePort)
 	},
@@ -71,5 +90,33 @@ var serveCmd = &cobra.Command{
 func init() {
 	serveCmd.Flags().IntVarP(&servePort, "port", "p", 8080, "port to listen on")
 	serveCmd.Flags().StringVar(&serveHost, "host", "0.0.0.0", "host to bind to")
+	serveCmd.Flags().BoolVar(&serveAllowSchemaMismatch, "allow-schema-mismatch", false, "start even if the database schema version differs from the binary's")
+	serveCmd.Flags().BoolVar(&serveOpen, "open", false, "open the dashboard in the default browser once the server is up")
 	rootCmd.AddCommand(serveCmd)
 }
+
+// configReloader returns the SIGHUP handler for serve: it re-reads the config
+// file and environment and applies the settings that can change while
+// serving. A config that fails to load or validate is reported and ignored.
+func configReloader(server *http.Server, out *Output) func() {
+	return func() {
+		config, err := LoadConfig(GetConfigPath())
+		if err == nil {
+			err = config.Validate()
+		}
+		if err != nil {
+			out.Warning("Config reload failed, keeping current settings: %v", err)
+			return
+		}
+		SetConfig(config)
+
+		if config.Server.ReadOnly != server.ReadOnly() {
+			server.SetReadOnly(config.Server.ReadOnly)
+			if config.Server.ReadOnly {
+				out.Info("Read-only mode on: writes to /api answer 503")
+			} else {
+				out.Info("Read-only mode off: writes accepted")
+			}
+		}
+	}
+}
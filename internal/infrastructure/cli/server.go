@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/infrastructure/http"
+	"github.com/mx-scribe/scribe/internal/infrastructure/http/handlers"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// OpenDatabase connects to the database at dbPath, creating its directory
+// if needed, applies the database settings of config and runs migrations.
+// The returned schema status tells whether the schema matches the binary;
+// what to do about a mismatch is left to the caller.
+func OpenDatabase(config *Config, dbPath string) (*sqlite.Database, sqlite.SchemaStatus, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, sqlite.SchemaStatus{}, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	db, err := sqlite.NewDatabase(dbPath)
+	if err != nil {
+		return nil, sqlite.SchemaStatus{}, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	db.SetCompressBodies(config.Database.CompressBodies)
+	db.SetUnknownSourceLabel(config.Database.UnknownSourceLabel)
+
+	if err := sqlite.RunMigrations(db.Conn()); err != nil {
+		db.Close()
+		return nil, sqlite.SchemaStatus{}, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	schema, err := sqlite.CheckSchema(db.Conn())
+	if err != nil {
+		db.Close()
+		return nil, sqlite.SchemaStatus{}, fmt.Errorf("failed to check schema version: %w", err)
+	}
+
+	return db, schema, nil
+}
+
+// NewServer returns a server for db configured from config: routes, limits,
+// timeouts, ingestion, pattern matching, metrics and SSE settings. Listening,
+// static assets and reloads are left to the caller.
+func NewServer(db *sqlite.Database, config *Config) (*http.Server, error) {
+	metrics := handlers.PrometheusOptions{Prefix: config.Metrics.Prefix, Labels: config.Metrics.Labels}
+	if err := metrics.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid metrics config: %w", err)
+	}
+	statsTime, err := sqlite.ParseTimeField(config.Database.StatsTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database.stats_time: %w", err)
+	}
+	retentionTime, err := sqlite.ParseTimeField(config.Database.RetentionTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database.retention_time: %w", err)
+	}
+	for _, route := range config.Server.DisabledRoutes {
+		if err := http.ValidateDisabledRoute(route); err != nil {
+			return nil, fmt.Errorf("invalid server config: %w", err)
+		}
+	}
+
+	server := http.NewServerWithDisabledRoutes(db, config.Server.DisabledRoutes)
+	server.SetMaxBodyBytes(config.Server.MaxBodyBytes)
+	server.SetTimeouts(http.Timeouts{
+		Read:       time.Duration(config.Server.ReadTimeout) * time.Second,
+		ReadHeader: time.Duration(config.Server.ReadHeaderTimeout) * time.Second,
+		Write:      time.Duration(config.Server.WriteTimeout) * time.Second,
+		Idle:       time.Duration(config.Server.IdleTimeout) * time.Second,
+		Operation:  time.Duration(config.Server.OperationTimeout) * time.Second,
+	})
+	server.SetPagination(config.Pagination)
+	server.SetAdminAuth(config.Server.AdminUser, config.Server.AdminPassword)
+	server.SetExportSigningKey(config.Server.ExportSigningKey)
+	server.SetTenancy(config.Server.Tenancy)
+	server.SetReadOnly(config.Server.ReadOnly)
+	if err := server.SetRateLimitExempt(config.Server.RateLimitExempt); err != nil {
+		return nil, fmt.Errorf("invalid rate limit exemption: %w", err)
+	}
+	server.SetAutoAnalyze(config.Logging.AutoAnalyze)
+	server.SetSourceRateLimit(config.Logging.SourceRateLimit, config.Logging.SourceRateBurst, config.Logging.SourceRateDrop)
+	server.SetMinIngestSeverity(config.Logging.MinIngestSeverity, config.Logging.MinIngestSeverityDrop)
+	server.SetSourceFilter(config.Logging.AllowedSources, config.Logging.DeniedSources, config.Logging.SourceFilterDrop)
+	server.SetMaxEventAge(time.Duration(config.Logging.MaxEventAge) * time.Second)
+	server.SetBodyNormalization(config.Logging.NormalizeBody, config.Logging.BodyAliases)
+	if err := server.SetPatternMatching(config.Logging.PatternMatcherOptions()); err != nil {
+		return nil, fmt.Errorf("invalid pattern matching config: %w", err)
+	}
+	if err := server.SetBodyRedaction(config.Logging.Redact, config.Logging.RedactKeys, config.Logging.RedactPatterns); err != nil {
+		return nil, fmt.Errorf("invalid redaction config: %w", err)
+	}
+	if err := server.SetColorFromSeverity(config.Logging.ColorFromSeverity, config.Logging.SeverityColorMap); err != nil {
+		return nil, fmt.Errorf("invalid severity color config: %w", err)
+	}
+	if err := server.SetEscalation(time.Duration(config.Logging.EscalationWindow)*time.Second, config.Logging.EscalationThresholds); err != nil {
+		return nil, fmt.Errorf("invalid escalation config: %w", err)
+	}
+	server.SetTimeFields(statsTime, retentionTime)
+	server.SetSlowQueryThreshold(time.Duration(config.Database.SlowQueryMS) * time.Millisecond)
+	server.SetMetricsNaming(metrics.Prefix, metrics.Labels)
+	server.SSEHub().SetCoalescing(config.Server.SSECoalesceThreshold, handlers.DefaultCoalesceInterval)
+	if config.Server.SSEReplayTTL > 0 {
+		server.SSEHub().SetEventPersistence(sqlite.NewEventRepository(db), time.Duration(config.Server.SSEReplayTTL)*time.Second)
+	}
+
+	return server, nil
+}
@@ -23,14 +23,20 @@ var statsCmd = &cobra.Command{
 			return fmt.Errorf("failed to connect to database: %w", err)
 		}
 		defer db.Close()
+		db.SetUnknownSourceLabel(GetConfig().Database.UnknownSourceLabel)
 
 		// Run migrations
 		if err := sqlite.RunMigrations(db.Conn()); err != nil {
 			return fmt.Errorf("failed to run migrations: %w", err)
 		}
 
+		statsTime, err := sqlite.ParseTimeField(GetConfig().Database.StatsTime)
+		if err != nil {
+			return fmt.Errorf("invalid database.stats_time: %w", err)
+		}
+
 		// Get stats
-		repo := sqlite.NewLogRepository(db)
+		repo := sqlite.NewLogRepository(db).WithTimeField(statsTime)
 		handler := queries.NewGetStatsHandler(repo)
 
 		stats, err := handler.Handle()
@@ -0,0 +1,143 @@
+package export
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/application/queries"
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+)
+
+func diverseLogs() []*entities.Log {
+	created := time.Date(2025, 3, 4, 5, 6, 7, 123456789, time.UTC)
+	offset := time.Date(2025, 3, 4, 8, 6, 7, 1, time.FixedZone("", 3*60*60))
+
+	return []*entities.Log{
+		{
+			ID:     7,
+			Header: entities.LogHeader{Title: "Payment failed", Severity: valueobjects.SeverityError, Source: "billing", Color: valueobjects.Color("red"), Description: "card declined"},
+			Body: map[string]any{
+				"order_id": json.Number("9007199254740993"),
+				"amount":   json.Number("12.50"),
+				"tags":     []any{"card", "retry", nil, true},
+				"customer": map[string]any{"id": "c-1", "vip": false},
+			},
+			Metadata:   entities.LogMetadata{DerivedSeverity: "error", DerivedSource: "billing", DerivedCategory: "business"},
+			Pinned:     true,
+			CreatedAt:  created,
+			IngestedAt: created.Add(1500 * time.Millisecond),
+		},
+		{
+			ID:         8,
+			Header:     entities.LogHeader{Title: "Bare log"},
+			Body:       map[string]any{},
+			CreatedAt:  offset,
+			IngestedAt: created,
+		},
+		{
+			ID:         9,
+			Header:     entities.LogHeader{Title: "Custom severity ✓", Severity: "notice", Description: strings.Repeat("multi\nline ", 50)},
+			Body:       map[string]any{"message": "line one\nline two", "empty": ""},
+			Metadata:   entities.LogMetadata{DerivedCategory: "general"},
+			CreatedAt:  created.Add(-time.Hour),
+			IngestedAt: created,
+		},
+	}
+}
+
+// assertLogsEqual compares logs field by field, comparing timestamps by
+// instant and offset rather than location pointer.
+func assertLogsEqual(t *testing.T, got, want *entities.Log) {
+	t.Helper()
+
+	if got.ID != want.ID {
+		t.Errorf("ID: got %d, want %d", got.ID, want.ID)
+	}
+	if got.Header != want.Header {
+		t.Errorf("log %d header: got %+v, want %+v", want.ID, got.Header, want.Header)
+	}
+	if !reflect.DeepEqual(got.Body, want.Body) {
+		t.Errorf("log %d body: got %#v, want %#v", want.ID, got.Body, want.Body)
+	}
+	if got.Metadata != want.Metadata {
+		t.Errorf("log %d metadata: got %+v, want %+v", want.ID, got.Metadata, want.Metadata)
+	}
+	if got.Pinned != want.Pinned {
+		t.Errorf("log %d pinned: got %v, want %v", want.ID, got.Pinned, want.Pinned)
+	}
+	for _, ts := range []struct {
+		name      string
+		got, want time.Time
+	}{
+		{"created_at", got.CreatedAt, want.CreatedAt},
+		{"ingested_at", got.IngestedAt, want.IngestedAt},
+	} {
+		_, gotOffset := ts.got.Zone()
+		_, wantOffset := ts.want.Zone()
+		if !ts.got.Equal(ts.want) || gotOffset != wantOffset {
+			t.Errorf("log %d %s: got %v, want %v", want.ID, ts.name, ts.got, ts.want)
+		}
+	}
+}
+
+func TestBinaryExport_RoundTrip(t *testing.T) {
+	want := diverseLogs()
+
+	var buf bytes.Buffer
+	if err := WriteLogs(&buf, queries.ExportFormatGob, want); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	got, err := ReadLogs(&buf)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d logs, got %d", len(want), len(got))
+	}
+	for i := range want {
+		assertLogsEqual(t, got[i], want[i])
+	}
+}
+
+func TestBinaryExport_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteLogs(&buf, queries.ExportFormatGob, nil); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	dec, err := NewLogDecoder(&buf)
+	if err != nil {
+		t.Fatalf("failed to read header: %v", err)
+	}
+	if dec.Version() != binaryVersion {
+		t.Errorf("expected version %d, got %d", binaryVersion, dec.Version())
+	}
+	if _, err := dec.Decode(); err == nil {
+		t.Error("expected io.EOF for an empty export")
+	}
+}
+
+func TestNewLogDecoder_RejectsOtherInput(t *testing.T) {
+	var ndjson bytes.Buffer
+	if err := WriteLogs(&ndjson, queries.ExportFormatNDJSON, testLogs()); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if _, err := NewLogDecoder(&ndjson); !errors.Is(err, ErrNotBinaryExport) {
+		t.Errorf("expected ErrNotBinaryExport for NDJSON, got %v", err)
+	}
+
+	var future bytes.Buffer
+	_ = gob.NewEncoder(&future).Encode(binaryHeader{Magic: binaryMagic, Version: binaryVersion + 1})
+	_, err := NewLogDecoder(&future)
+	if err == nil || !strings.Contains(err.Error(), "unsupported binary export version") {
+		t.Errorf("expected unsupported version error, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+// Package export writes log exports to local files or remote object storage.
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrS3Unsupported is returned for s3:// destinations when the binary was
+// built without the s3 build tag.
+var ErrS3Unsupported = errors.New("s3 destinations require a build with -tags s3")
+
+// Writer receives an export and publishes it once Commit is called.
+type Writer interface {
+	io.Writer
+
+	// Commit makes the written export visible at the destination.
+	Commit() error
+
+	// Abort discards anything written so far.
+	Abort()
+}
+
+// Destination is a place an export can be written to.
+type Destination interface {
+	Create(ctx context.Context) (Writer, error)
+	String() string
+}
+
+// ParseDestination resolves an --out value into a Destination.
+// Supported forms are "-" for stdout, "s3://bucket/key" and local paths
+// (optionally prefixed with "file://").
+func ParseDestination(out string) (Destination, error) {
+	switch {
+	case out == "" || out == "-":
+		return StdoutDestination{}, nil
+	case strings.HasPrefix(out, "s3://"):
+		bucket, key, ok := strings.Cut(strings.TrimPrefix(out, "s3://"), "/")
+		if !ok || bucket == "" || key == "" {
+			return nil, fmt.Errorf("invalid s3 destination %q (expected s3://bucket/key)", out)
+		}
+		return newS3Destination(bucket, key)
+	case strings.Contains(out, "://") && !strings.HasPrefix(out, "file://"):
+		return nil, fmt.Errorf("unsupported destination %q", out)
+	default:
+		return LocalDestination{Path: strings.TrimPrefix(out, "file://")}, nil
+	}
+}
+
+// LocalDestination writes exports to a file on the local filesystem.
+// The file is written under a temporary name and renamed on commit, so an
+// interrupted export never replaces an existing archive.
+type LocalDestination struct {
+	Path string
+}
+
+// Create opens a temporary file next to the target path.
+func (d LocalDestination) Create(_ context.Context) (Writer, error) {
+	dir := filepath.Dir(d.Path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(d.Path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+
+	return &localWriter{file: tmp, path: d.Path}, nil
+}
+
+func (d LocalDestination) String() string {
+	return d.Path
+}
+
+type localWriter struct {
+	file *os.File
+	path string
+}
+
+func (w *localWriter) Write(p []byte) (int, error) {
+	return w.file.Write(p)
+}
+
+func (w *localWriter) Commit() error {
+	if err := w.file.Close(); err != nil {
+		_ = os.Remove(w.file.Name())
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	if err := os.Chmod(w.file.Name(), 0o644); err != nil {
+		_ = os.Remove(w.file.Name())
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	if err := os.Rename(w.file.Name(), w.path); err != nil {
+		_ = os.Remove(w.file.Name())
+		return fmt.Errorf("failed to move export into place: %w", err)
+	}
+	return nil
+}
+
+func (w *localWriter) Abort() {
+	_ = w.file.Close()
+	_ = os.Remove(w.file.Name())
+}
+
+// StdoutDestination writes exports to standard output.
+type StdoutDestination struct{}
+
+// Create returns a writer backed by os.Stdout.
+func (StdoutDestination) Create(_ context.Context) (Writer, error) {
+	return stdoutWriter{}, nil
+}
+
+func (StdoutDestination) String() string {
+	return "stdout"
+}
+
+type stdoutWriter struct{}
+
+func (stdoutWriter) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
+
+func (stdoutWriter) Commit() error { return nil }
+
+func (stdoutWriter) Abort() {}
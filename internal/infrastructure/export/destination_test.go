@@ -0,0 +1,193 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/application/queries"
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+)
+
+func testLogs() []*entities.Log {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []*entities.Log{
+		{ID: 1, Header: entities.LogHeader{Title: "First", Severity: valueobjects.SeverityInfo, Source: "api"}, CreatedAt: created},
+		{ID: 2, Header: entities.LogHeader{Title: "Second", Severity: valueobjects.SeverityError}, CreatedAt: created},
+	}
+}
+
+func TestParseDestination(t *testing.T) {
+	tests := []struct {
+		out     string
+		want    Destination
+		wantErr bool
+	}{
+		{out: "-", want: StdoutDestination{}},
+		{out: "", want: StdoutDestination{}},
+		{out: "logs.json", want: LocalDestination{Path: "logs.json"}},
+		{out: "file:///tmp/logs.json", want: LocalDestination{Path: "/tmp/logs.json"}},
+		{out: "s3://bucket", wantErr: true},
+		{out: "s3:///key", wantErr: true},
+		{out: "ftp://host/logs.json", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.out, func(t *testing.T) {
+			got, err := ParseDestination(tt.out)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %q", tt.out)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDestination(%q) = %#v, want %#v", tt.out, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalDestination_WritesOnCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "logs.ndjson")
+	dest := LocalDestination{Path: path}
+
+	writer, err := dest.Create(context.Background())
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	if err := WriteLogs(writer, queries.ExportFormatNDJSON, testLogs()); err != nil {
+		t.Fatalf("failed to write logs: %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("expected file to be absent before commit")
+	}
+
+	if err := writer.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open export: %v", err)
+	}
+	defer file.Close()
+
+	var titles []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var log entities.Log
+		if err := json.Unmarshal(scanner.Bytes(), &log); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", scanner.Text(), err)
+		}
+		titles = append(titles, log.Header.Title)
+	}
+	if len(titles) != 2 || titles[0] != "First" || titles[1] != "Second" {
+		t.Errorf("unexpected titles: %v", titles)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the export file in directory, got %d entries", len(entries))
+	}
+}
+
+func TestLocalDestination_AbortKeepsExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.json")
+	if err := os.WriteFile(path, []byte("previous"), 0o644); err != nil {
+		t.Fatalf("failed to seed file: %v", err)
+	}
+
+	writer, err := LocalDestination{Path: path}.Create(context.Background())
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	_, _ = writer.Write([]byte("partial"))
+	writer.Abort()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(data) != "previous" {
+		t.Errorf("expected existing file to be untouched, got %q", data)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected temporary file to be removed, got %d entries", len(entries))
+	}
+}
+
+func TestWriteLogs_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteLogs(&buf, queries.ExportFormatJSON, testLogs()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var logs []entities.Log
+	if err := json.Unmarshal(buf.Bytes(), &logs); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(logs) != 2 {
+		t.Errorf("expected 2 logs, got %d", len(logs))
+	}
+}
+
+func TestWriteLogs_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteLogs(&buf, queries.ExportFormatCSV, testLogs()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected header and 2 rows, got %d records", len(records))
+	}
+	if records[2][1] != "error" || records[1][2] != "api" {
+		t.Errorf("unexpected rows: %v", records[1:])
+	}
+}
+
+func TestWriteLogs_InvalidFormat(t *testing.T) {
+	if err := WriteLogs(&bytes.Buffer{}, "xml", testLogs()); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestLogEncoder_JSONMatchesArrayEncoding(t *testing.T) {
+	var want bytes.Buffer
+	encoder := json.NewEncoder(&want)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(testLogs())
+
+	var got bytes.Buffer
+	if err := WriteLogs(&got, queries.ExportFormatJSON, testLogs()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("streamed JSON differs from array encoding:\n%s\nwant:\n%s", got.String(), want.String())
+	}
+
+	got.Reset()
+	if err := WriteLogs(&got, queries.ExportFormatJSON, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.String() != "[]\n" {
+		t.Errorf("expected empty array, got %q", got.String())
+	}
+}
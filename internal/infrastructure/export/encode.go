@@ -0,0 +1,118 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/mx-scribe/scribe/internal/application/queries"
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+)
+
+// WriteLogs encodes logs to w in the given format.
+func WriteLogs(w io.Writer, format queries.ExportFormat, logs []*entities.Log) error {
+	encoder, err := NewLogEncoder(w, format)
+	if err != nil {
+		return err
+	}
+	for _, log := range logs {
+		if err := encoder.Encode(log); err != nil {
+			return err
+		}
+	}
+	return encoder.Close()
+}
+
+// LogEncoder writes logs to an export one at a time, so an export can be
+// streamed without holding every log in memory. Close must be called after
+// the last log to complete the output.
+type LogEncoder struct {
+	w      io.Writer
+	format queries.ExportFormat
+	json   *json.Encoder
+	csv    *csv.Writer
+	gob    *gob.Encoder
+	count  int // JSON array elements written
+}
+
+// NewLogEncoder creates an encoder writing logs to w in the given format.
+func NewLogEncoder(w io.Writer, format queries.ExportFormat) (*LogEncoder, error) {
+	e := &LogEncoder{w: w, format: format}
+	switch format {
+	case queries.ExportFormatJSON:
+	case queries.ExportFormatNDJSON:
+		e.json = json.NewEncoder(w)
+	case queries.ExportFormatCSV:
+		e.csv = csv.NewWriter(w)
+		if err := e.csv.Write([]string{"id", "severity", "source", "title", "description", "created_at"}); err != nil {
+			return nil, err
+		}
+	case queries.ExportFormatGob:
+		e.gob = gob.NewEncoder(w)
+		if err := writeBinaryHeader(e.gob); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+	return e, nil
+}
+
+// Encode writes a single log.
+func (e *LogEncoder) Encode(log *entities.Log) error {
+	switch e.format {
+	case queries.ExportFormatJSON:
+		// Written as elements of an indented array, opened by the first log
+		data, err := json.MarshalIndent(log, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		sep := ",\n  "
+		if e.count == 0 {
+			sep = "[\n  "
+		}
+		if _, err := io.WriteString(e.w, sep+string(data)); err != nil {
+			return err
+		}
+		e.count++
+		return nil
+	case queries.ExportFormatNDJSON:
+		return e.json.Encode(log)
+	case queries.ExportFormatGob:
+		record, err := toBinaryLog(log)
+		if err != nil {
+			return err
+		}
+		return e.gob.Encode(record)
+	default:
+		return e.csv.Write([]string{
+			strconv.FormatInt(log.ID, 10),
+			string(log.EffectiveSeverity()),
+			log.Header.Source,
+			log.Header.Title,
+			log.Header.Description,
+			log.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+}
+
+// Close completes the output, closing the JSON array and flushing CSV rows.
+// It does not close the underlying writer.
+func (e *LogEncoder) Close() error {
+	switch e.format {
+	case queries.ExportFormatJSON:
+		end := "\n]\n"
+		if e.count == 0 {
+			end = "[]\n"
+		}
+		_, err := io.WriteString(e.w, end)
+		return err
+	case queries.ExportFormatCSV:
+		e.csv.Flush()
+		return e.csv.Error()
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+//go:build s3
+
+package export
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// S3Destination uploads exports to an S3-compatible bucket using a single
+// SigV4-signed PUT. Credentials and region are read from the standard
+// AWS_* environment variables; AWS_ENDPOINT_URL selects a path-style
+// endpoint for S3-compatible stores.
+type S3Destination struct {
+	Bucket string
+	Key    string
+
+	region       string
+	endpoint     string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	client       *http.Client
+}
+
+func newS3Destination(bucket, key string) (Destination, error) {
+	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, errors.New("s3 destination requires AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &S3Destination{
+		Bucket:       bucket,
+		Key:          key,
+		region:       region,
+		endpoint:     strings.TrimSuffix(os.Getenv("AWS_ENDPOINT_URL"), "/"),
+		accessKey:    accessKey,
+		secretKey:    secretKey,
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		client:       &http.Client{Timeout: 10 * time.Minute},
+	}, nil
+}
+
+// Create buffers the export in a temporary file; Commit uploads it.
+func (d *S3Destination) Create(ctx context.Context) (Writer, error) {
+	tmp, err := os.CreateTemp("", "scribe-export-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create buffer file: %w", err)
+	}
+	return &s3Writer{ctx: ctx, dest: d, file: tmp, hash: sha256.New()}, nil
+}
+
+func (d *S3Destination) String() string {
+	return "s3://" + d.Bucket + "/" + d.Key
+}
+
+// objectURL returns the URL and canonical path for the object.
+func (d *S3Destination) objectURL() (string, string) {
+	key := awsURIEncode(d.Key, false)
+	if d.endpoint != "" {
+		path := "/" + awsURIEncode(d.Bucket, true) + "/" + key
+		return d.endpoint + path, path
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", d.Bucket, d.region, key), "/" + key
+}
+
+type s3Writer struct {
+	ctx  context.Context
+	dest *S3Destination
+	file *os.File
+	hash interface {
+		io.Writer
+		Sum([]byte) []byte
+	}
+	size int64
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	n, err := w.file.Write(p)
+	w.hash.Write(p[:n])
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *s3Writer) Commit() error {
+	defer w.Abort()
+
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind buffer file: %w", err)
+	}
+
+	url, path := w.dest.objectURL()
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPut, url, w.file)
+	if err != nil {
+		return fmt.Errorf("failed to build upload request: %w", err)
+	}
+	req.ContentLength = w.size
+	w.dest.sign(req, path, hex.EncodeToString(w.hash.Sum(nil)), time.Now().UTC())
+
+	resp, err := w.dest.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload to %s: %w", w.dest, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload to %s failed: %s: %s", w.dest, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (w *s3Writer) Abort() {
+	_ = w.file.Close()
+	_ = os.Remove(w.file.Name())
+}
+
+// sign adds AWS Signature Version 4 headers to req.
+func (d *S3Destination) sign(req *http.Request, path, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if d.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", d.sessionToken)
+	}
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	if d.sessionToken != "" {
+		signedHeaders += ";x-amz-security-token"
+		canonicalHeaders += "x-amz-security-token:" + d.sessionToken + "\n"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		"",
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + d.region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+d.secretKey), date)
+	key = hmacSHA256(key, d.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		d.accessKey, scope, signedHeaders, signature,
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// awsURIEncode percent-encodes s as SigV4 requires. Slashes are kept
+// unless encodeSlash is set.
+func awsURIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
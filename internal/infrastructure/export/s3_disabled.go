@@ -0,0 +1,7 @@
+//go:build !s3
+
+package export
+
+func newS3Destination(_, _ string) (Destination, error) {
+	return nil, ErrS3Unsupported
+}
@@ -0,0 +1,15 @@
+//go:build !s3
+
+package export
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseDestination_S3RequiresBuildTag(t *testing.T) {
+	_, err := ParseDestination("s3://bucket/logs.ndjson")
+	if !errors.Is(err, ErrS3Unsupported) {
+		t.Errorf("expected ErrS3Unsupported, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// AlertStream handles GET /api/alerts/stream, a long-lived SSE stream for
+// alerting scripts that carries only the new logs matching the severity,
+// min_severity, source and search filters, one "alert" event per log.
+// Other hub events are left out, apart from the initial "connected" event
+// and a "ping" every 30 seconds.
+//
+// When the hub persists events, alerts carry the ID of the event they came
+// from, and a client reconnecting with the last ID it saw as Last-Event-ID
+// is first sent the matching logs it missed. Delivery is at least once: a
+// log can be sent again after a reconnect, never skipped while its event is
+// still persisted.
+func AlertStream(hub *SSEHub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := eventFilterFromRequest(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		filter.search = r.URL.Query().Get("search")
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, r, http.StatusInternalServerError, "streaming unsupported")
+			return
+		}
+
+		// The stream outlives the server's write timeout
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+		// Subscribe before replaying so no event falls between the two;
+		// live events already replayed are skipped by ID below
+		client := hub.subscribe(r.Context(), 100)
+		defer func() { hub.unregister <- client }()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		sendSSEEvent(w, flusher, SSEEvent{
+			Type: "connected",
+			Data: map[string]any{
+				"message":   "Connected to SCRIBE alert stream",
+				"timestamp": time.Now().Format(time.RFC3339),
+			},
+		})
+
+		var replayed int64
+		if lastEventID, err := strconv.ParseInt(r.Header.Get("Last-Event-ID"), 10, 64); err == nil && lastEventID > 0 {
+			replayed = hub.replay(r.Context(), lastEventID, func(event SSEEvent) {
+				sendAlerts(w, flusher, filter, event)
+			})
+		}
+
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event, ok := <-client:
+				if !ok {
+					return
+				}
+				if event.ID != 0 && event.ID <= replayed {
+					continue
+				}
+				sendAlerts(w, flusher, filter, event)
+
+			case <-ticker.C:
+				sendSSEEvent(w, flusher, SSEEvent{
+					Type: "ping",
+					Data: map[string]string{"timestamp": time.Now().Format(time.RFC3339)},
+				})
+
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
+
+// sendAlerts sends an "alert" event for each log of a log_created or
+// logs_created_batch event that matches filter. Only the last alert carries
+// the event's ID, so a client resuming from it has been sent every alert of
+// the event.
+func sendAlerts(w http.ResponseWriter, flusher http.Flusher, filter eventFilter, event SSEEvent) {
+	// Replayed events hold their data as stored
+	if raw, ok := event.Data.(json.RawMessage); ok {
+		var data any
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return
+		}
+		event.Data = data
+	}
+
+	event, ok := filter.apply(event)
+	if !ok {
+		return
+	}
+
+	var logs []any
+	switch event.Type {
+	case "log_created":
+		logs = []any{event.Data}
+	case "logs_created_batch":
+		batch, _ := event.Data.(map[string]any)
+		logs, _ = batch["logs"].([]any)
+	}
+
+	for i, log := range logs {
+		alert := SSEEvent{Type: "alert", Data: log}
+		if i == len(logs)-1 {
+			alert.ID = event.ID
+		}
+		sendSSEEvent(w, flusher, alert)
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/mx-scribe/scribe/internal/application/commands"
+)
+
+// AnalyzeLog handles POST /api/analyze. It runs the pattern matcher on a log
+// in the POST /api/logs format and returns the derived metadata without
+// storing anything, for tuning rules.
+func AnalyzeLog(w http.ResponseWriter, r *http.Request) {
+	AnalyzeLogWithOptions(nil)(w, r)
+}
+
+// AnalyzeLogWithOptions handles POST /api/analyze like AnalyzeLog, using the
+// pattern matcher of opts so configured rule overrides apply.
+func AnalyzeLogWithOptions(opts *IngestOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		analyzeLog(w, r, opts)
+	}
+}
+
+func analyzeLog(w http.ResponseWriter, r *http.Request, opts *IngestOptions) {
+	var req CreateLogRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.Header.Title == "" {
+		writeError(w, r, http.StatusBadRequest, "title is required")
+		return
+	}
+
+	input, err := req.Input()
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	input.SkipAnalysis = true
+
+	// Build only validates and assembles the entity; nothing is persisted
+	log, err := commands.NewCreateLogHandler(nil).Build(input)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	metadata := opts.matcher().AnalyzeLog(log)
+
+	writeJSON(w, r, http.StatusOK, MetaResponse{
+		DerivedSeverity: metadata.DerivedSeverity,
+		DerivedSource:   metadata.DerivedSource,
+		DerivedCategory: metadata.DerivedCategory,
+	})
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// maxAnnotationLength caps the size of a single annotation's text.
+const maxAnnotationLength = 10000
+
+// CreateAnnotationRequest represents the request body for annotating a log.
+type CreateAnnotationRequest struct {
+	Author string `json:"author,omitempty"`
+	Text   string `json:"text"`
+}
+
+// AnnotationResponse represents an annotation in API responses.
+type AnnotationResponse struct {
+	ID        int64  `json:"id"`
+	LogID     int64  `json:"log_id"`
+	Author    string `json:"author"`
+	Text      string `json:"text"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ListAnnotations handles GET /api/logs/{id}/annotations.
+func ListAnnotations(db *sqlite.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := annotatedLogID(w, r, db)
+		if !ok {
+			return
+		}
+
+		repo := sqlite.NewAnnotationRepository(db)
+		annotations, err := repo.FindByLogIDContext(r.Context(), id)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		response := make([]AnnotationResponse, 0, len(annotations))
+		for _, annotation := range annotations {
+			response = append(response, annotationToResponse(annotation))
+		}
+
+		writeJSON(w, r, http.StatusOK, map[string]any{
+			"annotations": response,
+			"total":       len(response),
+		})
+	}
+}
+
+// CreateAnnotation handles POST /api/logs/{id}/annotations.
+func CreateAnnotation(db *sqlite.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := annotatedLogID(w, r, db)
+		if !ok {
+			return
+		}
+
+		var req CreateAnnotationRequest
+		if !decodeJSONBody(w, r, &req) {
+			return
+		}
+
+		annotation := entities.NewAnnotation(id, req.Author, req.Text)
+		if err := annotation.Validate(); err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(annotation.Text) > maxAnnotationLength {
+			writeError(w, r, http.StatusBadRequest, "annotation text is too long")
+			return
+		}
+
+		repo := sqlite.NewAnnotationRepository(db)
+		if err := repo.CreateContext(r.Context(), annotation); err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeJSON(w, r, http.StatusCreated, annotationToResponse(annotation))
+	}
+}
+
+// annotatedLogID parses the log ID from the URL and checks the log exists,
+// writing an error response and returning false otherwise.
+func annotatedLogID(w http.ResponseWriter, r *http.Request, db *sqlite.Database) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid log ID")
+		return 0, false
+	}
+
+	if _, err := sqlite.NewLogRepository(db).FindByIDContext(r.Context(), id); err != nil {
+		if err == entities.ErrLogNotFound {
+			writeError(w, r, http.StatusNotFound, "log not found")
+			return 0, false
+		}
+		writeError(w, r, http.StatusInternalServerError, err.Error())
+		return 0, false
+	}
+
+	return id, true
+}
+
+// annotationToResponse converts an Annotation entity to an AnnotationResponse.
+func annotationToResponse(annotation *entities.Annotation) AnnotationResponse {
+	return AnnotationResponse{
+		ID:        annotation.ID,
+		LogID:     annotation.LogID,
+		Author:    annotation.Author,
+		Text:      annotation.Text,
+		CreatedAt: annotation.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
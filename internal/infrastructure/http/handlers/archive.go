@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/application/queries"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+const (
+	// archiveDataFile is the name of the NDJSON export inside an archive.
+	archiveDataFile = "logs.ndjson"
+
+	// archiveManifestFile is the name of the manifest inside an archive.
+	archiveManifestFile = "manifest.json"
+)
+
+// exportFilterParams are the query parameters that filter an export.
+var exportFilterParams = []string{
+	"severity", "min_severity", "source", "color", "category", "search", "title", "body_contains", "from", "to",
+}
+
+// ExportManifest describes the data file of an export archive so its
+// integrity can be verified later.
+type ExportManifest struct {
+	DataFile     string            `json:"data_file"`
+	Format       string            `json:"format"`
+	RowCount     int               `json:"row_count"`
+	TotalMatched int               `json:"total_matched"`
+	Truncated    bool              `json:"truncated"`
+	Filters      map[string]string `json:"filters"`
+	ExportedAt   string            `json:"exported_at"`
+	SHA256       string            `json:"sha256"`
+}
+
+// ExportArchive handles GET /api/export/archive.
+func ExportArchive(db *sqlite.Database) http.HandlerFunc {
+	return ExportArchiveWithPagination(db, nil)
+}
+
+// ExportArchiveWithPagination handles GET /api/export/archive, streaming a
+// zip with the matching logs as NDJSON and a manifest recording the row
+// count, filters, export time and SHA-256 of the data file. Accepts the same
+// filters as the other exports, up to the configured export page size.
+func ExportArchiveWithPagination(db *sqlite.Database, pagination *queries.Pagination) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		loc, err := timezoneParam(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		logs, total, err := getAllLogs(db, r, pageSizes(pagination).Export)
+		if err != nil {
+			writeExportError(w, r, err)
+			return
+		}
+		setExportTotals(w, total, len(logs))
+
+		exportedAt := time.Now().UTC()
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", "attachment; filename=scribe-logs-"+exportedAt.Format("20060102T150405Z")+".zip")
+
+		archive := zip.NewWriter(w)
+		defer archive.Close()
+
+		data, err := archive.CreateHeader(&zip.FileHeader{Name: archiveDataFile, Method: zip.Deflate, Modified: exportedAt})
+		if err != nil {
+			return
+		}
+
+		// Hash the data as it is written so the export is read only once
+		hash := sha256.New()
+		encoder := json.NewEncoder(io.MultiWriter(data, hash))
+		for _, log := range logs {
+			if err := encoder.Encode(logToResponse(log, loc)); err != nil {
+				return
+			}
+		}
+
+		manifest := ExportManifest{
+			DataFile:     archiveDataFile,
+			Format:       "ndjson",
+			RowCount:     len(logs),
+			TotalMatched: total,
+			Truncated:    total > len(logs),
+			Filters:      exportFilters(r),
+			ExportedAt:   exportedAt.Format(time.RFC3339),
+			SHA256:       hex.EncodeToString(hash.Sum(nil)),
+		}
+
+		manifestFile, err := archive.CreateHeader(&zip.FileHeader{Name: archiveManifestFile, Method: zip.Deflate, Modified: exportedAt})
+		if err != nil {
+			return
+		}
+		encoder = json.NewEncoder(manifestFile)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(manifest)
+	}
+}
+
+// exportFilters returns the filter query parameters applied to an export.
+func exportFilters(r *http.Request) map[string]string {
+	filters := make(map[string]string)
+	query := r.URL.Query()
+	for _, name := range exportFilterParams {
+		if v := query.Get(name); v != "" {
+			filters[name] = v
+		}
+	}
+	for name, values := range query {
+		if strings.HasPrefix(name, "body.") {
+			filters[name] = values[0]
+		}
+	}
+	if or := query["or"]; len(or) > 0 {
+		filters["or"] = strings.Join(or, " OR ")
+	}
+	return filters
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// ErrorCode is a stable, machine-readable identifier for an API error.
+type ErrorCode string
+
+// Error codes returned in the "code" field of error responses.
+const (
+	CodeInvalidRequest  ErrorCode = "invalid_request"
+	CodeUnauthorized    ErrorCode = "unauthorized"
+	CodeForbidden       ErrorCode = "forbidden"
+	CodeNotFound        ErrorCode = "not_found"
+	CodePayloadTooLarge ErrorCode = "payload_too_large"
+	CodeUnsupportedType ErrorCode = "unsupported_media_type"
+	CodeUpgradeRequired ErrorCode = "upgrade_required"
+	CodeRateLimited     ErrorCode = "rate_limited"
+	CodeBelowMinimum    ErrorCode = "below_min_severity"
+	CodeEventTooOld     ErrorCode = "event_too_old"
+	CodeSourceDenied    ErrorCode = "source_not_allowed"
+	CodeTimeout         ErrorCode = "timeout"
+	CodeInternal        ErrorCode = "internal_error"
+)
+
+// APIError is the body of every error response, wrapped as {"error": APIError}.
+type APIError struct {
+	Code      ErrorCode `json:"code"`
+	Message   string    `json:"message"`
+	Details   any       `json:"details,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// errorEnvelope wraps an APIError in the response body.
+type errorEnvelope struct {
+	Error APIError `json:"error"`
+}
+
+// codeForStatus returns the default error code for an HTTP status.
+func codeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedType
+	case http.StatusUpgradeRequired:
+		return CodeUpgradeRequired
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeInvalidRequest
+}
+
+// ErrorResponse sends an error response with the given status code, using
+// the default error code for that status.
+func ErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	writeError(w, r, statusCode, message)
+}
+
+// writeError writes an error response with the default code for status.
+func writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	writeAPIError(w, r, status, APIError{Code: codeForStatus(status), Message: message})
+}
+
+// writeErrorDetails writes an error response with structured details.
+func writeErrorDetails(w http.ResponseWriter, r *http.Request, status int, message string, details any) {
+	writeAPIError(w, r, status, APIError{Code: codeForStatus(status), Message: message, Details: details})
+}
+
+// timedOut reports whether err comes from the request running past its
+// server-side deadline, as opposed to the client going away.
+func timedOut(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// writeAPIError writes apiErr with status, filling in the request ID from
+// the request context when one was assigned.
+func writeAPIError(w http.ResponseWriter, r *http.Request, status int, apiErr APIError) {
+	if r != nil && apiErr.RequestID == "" {
+		apiErr.RequestID = middleware.GetReqID(r.Context())
+	}
+	writeJSON(w, r, status, errorEnvelope{Error: apiErr})
+}
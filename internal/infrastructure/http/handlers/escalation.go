@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// Escalator raises the severity of logs that keep recurring: once the same
+// title from the same source has been seen as many times as a threshold
+// within the window, that occurrence and the following ones are stored with
+// the threshold's severity, so fifty connection timeouts in a minute become
+// critical even though each one alone is a warning.
+type Escalator struct {
+	mu        sync.Mutex
+	window    time.Duration
+	steps     []escalationStep
+	seen      map[escalationKey][]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// escalationStep is a threshold of an Escalator.
+type escalationStep struct {
+	count    int
+	severity valueobjects.Severity
+}
+
+// escalationKey identifies the logs counted as occurrences of one another.
+type escalationKey struct {
+	tenant, source, title string
+}
+
+// NewEscalator creates an escalator from thresholds mapping standard
+// severities to the occurrences within window that raise a log to them,
+// e.g. {"error": 10, "critical": 50}. Thresholds must be at least 2. Returns
+// nil, meaning no escalation, when thresholds is empty or window is not
+// positive.
+func NewEscalator(window time.Duration, thresholds map[string]int) (*Escalator, error) {
+	if len(thresholds) == 0 || window <= 0 {
+		return nil, nil
+	}
+
+	steps := make([]escalationStep, 0, len(thresholds))
+	for severity, count := range thresholds {
+		if valueobjects.Severity(severity).Rank() == 0 {
+			return nil, fmt.Errorf("%q is not a standard severity", severity)
+		}
+		if count < 2 {
+			return nil, fmt.Errorf("severity %s: threshold must be at least 2, got %d", severity, count)
+		}
+		steps = append(steps, escalationStep{count: count, severity: valueobjects.Severity(severity)})
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].count < steps[j].count })
+
+	return &Escalator{
+		window: window,
+		steps:  steps,
+		seen:   make(map[escalationKey][]time.Time),
+		now:    time.Now,
+	}, nil
+}
+
+// LogEscalation describes a log escalated for recurring, sent as the data of
+// log_escalated events.
+type LogEscalation struct {
+	ID            int64  `json:"id"`
+	Title         string `json:"title"`
+	Source        string `json:"source,omitempty"`
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Occurrences   int    `json:"occurrences"`
+	WindowSeconds int    `json:"window_seconds"`
+
+	log *entities.Log
+}
+
+// Escalate records an occurrence of log, which is about to be saved for the
+// tenant of ctx, and raises its derived severity to that of the highest
+// threshold reached when it ranks above the log's effective severity. It
+// returns the escalation when this occurrence crossed that threshold, and
+// nil otherwise, so a burst is announced once.
+func (e *Escalator) Escalate(ctx context.Context, log *entities.Log) *LogEscalation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	e.sweep(now)
+
+	key := escalationKey{tenant: sqlite.TenantFromContext(ctx), source: log.EffectiveSource(), title: log.Header.Title}
+	occurrences := e.record(key, now)
+
+	var step *escalationStep
+	for i := range e.steps {
+		if occurrences >= e.steps[i].count && (step == nil || e.steps[i].severity.Rank() > step.severity.Rank()) {
+			step = &e.steps[i]
+		}
+	}
+	from := log.EffectiveSeverity()
+	if step == nil || step.severity.Rank() <= from.Rank() {
+		return nil
+	}
+
+	log.Metadata.DerivedSeverity = step.severity.String()
+	if occurrences != step.count {
+		return nil
+	}
+	return &LogEscalation{
+		Title:         log.Header.Title,
+		Source:        key.source,
+		From:          from.String(),
+		To:            step.severity.String(),
+		Occurrences:   occurrences,
+		WindowSeconds: int(e.window / time.Second),
+		log:           log,
+	}
+}
+
+// record adds an occurrence of key at now and returns the number within the
+// window. Occurrences past the highest threshold are not kept, bounding
+// memory under a flood while keeping the count above every threshold.
+func (e *Escalator) record(key escalationKey, now time.Time) int {
+	cutoff := now.Add(-e.window)
+	times := e.seen[key]
+	start := 0
+	for start < len(times) && !times[start].After(cutoff) {
+		start++
+	}
+	times = append(times[start:], now)
+	if keep := e.steps[len(e.steps)-1].count + 1; len(times) > keep {
+		times = times[len(times)-keep:]
+	}
+	e.seen[key] = times
+	return len(times)
+}
+
+// sweep evicts keys whose last occurrence left the window, at most once per
+// window.
+func (e *Escalator) sweep(now time.Time) {
+	if now.Sub(e.lastSweep) < e.window {
+		return
+	}
+	e.lastSweep = now
+	cutoff := now.Add(-e.window)
+	for key, times := range e.seen {
+		if !times[len(times)-1].After(cutoff) {
+			delete(e.seen, key)
+		}
+	}
+}
@@ -3,45 +3,170 @@ package handlers
 import (
 	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/mx-scribe/scribe/internal/application/queries"
 	"github.com/mx-scribe/scribe/internal/domain/entities"
 	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
 )
 
 // ExportJSON handles GET /api/export/json.
 func ExportJSON(db *sqlite.Database) http.HandlerFunc {
+	return ExportJSONWithPagination(db, nil)
+}
+
+// ExportJSONWithPagination handles GET /api/export/json, exporting up to the
+// configured export page size. The array is streamed one log at a time, so
+// memory stays flat however large the export. When reading fails after the
+// array has started, the array is still closed so the body stays valid JSON,
+// and the failure is reported in the X-Export-Error trailer.
+func ExportJSONWithPagination(db *sqlite.Database, pagination *queries.Pagination) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		logs, err := getAllLogs(db, r)
+		loc, err := timezoneParam(r)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		// Set download headers
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Content-Disposition", "attachment; filename=scribe-logs.json")
+		filters, err := exportLogFilters(r, pageSizes(pagination).Export)
+		if err != nil {
+			writeExportError(w, r, err)
+			return
+		}
 
-		// Convert to response format
-		response := make([]LogResponse, 0, len(logs))
-		for _, log := range logs {
-			response = append(response, logToResponse(log))
+		// Pin the export to the logs that exist now, so the totals match
+		// what is streamed
+		repo := sqlite.NewLogRepository(db)
+		if filters.MaxID, err = repo.MaxIDContext(r.Context()); err != nil {
+			writeExportError(w, r, err)
+			return
+		}
+		total, err := repo.CountMatchingContext(r.Context(), filters)
+		if err != nil {
+			writeExportError(w, r, err)
+			return
+		}
+
+		array := &jsonArrayWriter{w: w, pretty: wantsPrettyJSON(r)}
+		array.start = func() {
+			setExportTotals(w, total, min(filters.Limit, total))
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Disposition", "attachment; filename=scribe-logs.json")
+			w.Header().Set("Trailer", "X-Export-Error")
+			w.WriteHeader(http.StatusOK)
+		}
+
+		_, err = repo.EachContext(r.Context(), filters, func(log *entities.Log) error {
+			return array.write(logToResponse(log, loc))
+		})
+		if err != nil && array.count == 0 {
+			// Nothing has been sent yet, so the error can still be the response
+			writeExportError(w, r, err)
+			return
+		}
+		if err != nil {
+			w.Header().Set("X-Export-Error", err.Error())
 		}
+		_ = array.close()
+	}
+}
+
+// jsonArrayFlushEvery is how many elements jsonArrayWriter writes between
+// flushes.
+const jsonArrayFlushEvery = 100
+
+// jsonArrayWriter streams a JSON array to a response one element at a time,
+// producing the same bytes writeJSON would for the whole slice.
+type jsonArrayWriter struct {
+	w      http.ResponseWriter
+	pretty bool
+	start  func() // writes the response header before the first byte
+	count  int    // elements written
+}
+
+// write appends v to the array, opening it first when v is the first element.
+func (a *jsonArrayWriter) write(v any) error {
+	var data []byte
+	var err error
+	if a.pretty {
+		data, err = json.MarshalIndent(v, "  ", "  ")
+	} else {
+		data, err = json.Marshal(v)
+	}
+	if err != nil {
+		return err
+	}
+
+	sep := ","
+	if a.count == 0 {
+		a.start()
+		sep = "["
+	}
+	if a.pretty {
+		sep += "\n  "
+	}
+	if _, err := io.WriteString(a.w, sep+string(data)); err != nil {
+		return err
+	}
 
-		_ = json.NewEncoder(w).Encode(response)
+	a.count++
+	if a.count%jsonArrayFlushEvery == 0 {
+		_ = http.NewResponseController(a.w).Flush()
 	}
+	return nil
+}
+
+// close ends the array, writing an empty one when no element was written.
+func (a *jsonArrayWriter) close() error {
+	end := "]\n"
+	switch {
+	case a.count == 0:
+		a.start()
+		end = "[]\n"
+	case a.pretty:
+		end = "\n]\n"
+	}
+	_, err := io.WriteString(a.w, end)
+	return err
 }
 
 // ExportCSV handles GET /api/export/csv.
 func ExportCSV(db *sqlite.Database) http.HandlerFunc {
+	return ExportCSVWithPagination(db, nil)
+}
+
+// ExportCSVWithPagination handles GET /api/export/csv, exporting up to the
+// configured export page size. ?time_format= picks how created_at is
+// rendered; see timeFormatParam.
+func ExportCSVWithPagination(db *sqlite.Database, pagination *queries.Pagination) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		logs, err := getAllLogs(db, r)
+		loc, err := timezoneParam(r)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusBadRequest, err.Error())
 			return
 		}
 
+		formatTime, err := timeFormatParam(r, loc)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		logs, total, err := getAllLogs(db, r, pageSizes(pagination).Export)
+		if err != nil {
+			writeExportError(w, r, err)
+			return
+		}
+		setExportTotals(w, total, len(logs))
+
 		// Set download headers
 		w.Header().Set("Content-Type", "text/csv")
 		w.Header().Set("Content-Disposition", "attachment; filename=scribe-logs.csv")
@@ -60,25 +185,305 @@ func ExportCSV(db *sqlite.Database) http.HandlerFunc {
 				log.Header.Source,
 				log.Header.Title,
 				log.Header.Description,
-				log.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+				formatTime(log.CreatedAt),
 			}
 			_ = csvWriter.Write(row)
 		}
 	}
 }
 
-// getAllLogs retrieves all logs with optional filters.
-func getAllLogs(db *sqlite.Database, r *http.Request) ([]*entities.Log, error) {
-	filters := sqlite.LogFilters{
-		Limit:    10000, // Max export limit
-		Severity: r.URL.Query().Get("severity"),
-		Source:   r.URL.Query().Get("source"),
-		Search:   r.URL.Query().Get("search"),
-		FromDate: r.URL.Query().Get("from"),
-		ToDate:   r.URL.Query().Get("to"),
+// ExportNDJSON handles GET /api/export/ndjson.
+func ExportNDJSON(db *sqlite.Database) http.HandlerFunc {
+	return ExportNDJSONWithPagination(db, nil)
+}
+
+// ExportNDJSONWithPagination handles GET /api/export/ndjson, exporting up to
+// the configured export page size as one JSON log per line.
+func ExportNDJSONWithPagination(db *sqlite.Database, pagination *queries.Pagination) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		loc, err := timezoneParam(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		logs, total, err := getAllLogs(db, r, pageSizes(pagination).Export)
+		if err != nil {
+			writeExportError(w, r, err)
+			return
+		}
+		setExportTotals(w, total, len(logs))
+
+		// Set download headers
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", "attachment; filename=scribe-logs.ndjson")
+
+		encoder := json.NewEncoder(w)
+		for _, log := range logs {
+			if err := encoder.Encode(logToResponse(log, loc)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// xmlLog is a log in the XML export, with the columns of the CSV export.
+type xmlLog struct {
+	XMLName     xml.Name `xml:"log"`
+	ID          int64    `xml:"id,attr"`
+	Severity    string   `xml:"severity"`
+	Source      string   `xml:"source"`
+	Title       string   `xml:"title"`
+	Description string   `xml:"description"`
+	CreatedAt   string   `xml:"created_at"`
+}
+
+// ExportXML handles GET /api/export/xml.
+func ExportXML(db *sqlite.Database) http.HandlerFunc {
+	return ExportXMLWithPagination(db, nil)
+}
+
+// ExportXMLWithPagination handles GET /api/export/xml, exporting up to the
+// configured export page size as <log> elements of a <logs> document.
+// ?time_format= picks how created_at is rendered; see timeFormatParam.
+func ExportXMLWithPagination(db *sqlite.Database, pagination *queries.Pagination) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		loc, err := timezoneParam(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		formatTime, err := timeFormatParam(r, loc)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		logs, total, err := getAllLogs(db, r, pageSizes(pagination).Export)
+		if err != nil {
+			writeExportError(w, r, err)
+			return
+		}
+		setExportTotals(w, total, len(logs))
+
+		// Set download headers
+		w.Header().Set("Content-Type", "application/xml")
+		w.Header().Set("Content-Disposition", "attachment; filename=scribe-logs.xml")
+
+		_, _ = w.Write([]byte(xml.Header))
+		encoder := xml.NewEncoder(w)
+		encoder.Indent("", "  ")
+		defer encoder.Flush()
+
+		root := xml.StartElement{Name: xml.Name{Local: "logs"}}
+		_ = encoder.EncodeToken(root)
+		for _, log := range logs {
+			if err := encoder.Encode(xmlLog{
+				ID:          log.ID,
+				Severity:    string(log.EffectiveSeverity()),
+				Source:      log.Header.Source,
+				Title:       log.Header.Title,
+				Description: log.Header.Description,
+				CreatedAt:   formatTime(log.CreatedAt),
+			}); err != nil {
+				return
+			}
+		}
+		_ = encoder.EncodeToken(root.End())
+	}
+}
+
+// exportMediaTypes lists the media types GET /api/export negotiates and the
+// format each selects, most preferred first.
+var exportMediaTypes = []struct {
+	mediaType string
+	format    string
+}{
+	{"application/json", "json"},
+	{"text/csv", "csv"},
+	{"application/x-ndjson", "ndjson"},
+	{"application/xml", "xml"},
+}
+
+// Export handles GET /api/export.
+func Export(db *sqlite.Database) http.HandlerFunc {
+	return ExportWithPagination(db, nil)
+}
+
+// ExportWithPagination handles GET /api/export, running the JSON, CSV,
+// NDJSON or XML export picked from the Accept header. JSON is used when the
+// header is absent or accepts any type; an Accept naming none of the export
+// media types is answered with 406.
+func ExportWithPagination(db *sqlite.Database, pagination *queries.Pagination) http.HandlerFunc {
+	exports := map[string]http.Handler{
+		"json":   ExportJSONWithPagination(db, pagination),
+		"csv":    ExportCSVWithPagination(db, pagination),
+		"ndjson": ExportNDJSONWithPagination(db, pagination),
+		"xml":    ExportXMLWithPagination(db, pagination),
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+
+		format, ok := negotiateExportFormat(r.Header.Get("Accept"))
+		if !ok {
+			supported := make([]string, 0, len(exportMediaTypes))
+			for _, t := range exportMediaTypes {
+				supported = append(supported, t.mediaType)
+			}
+			writeError(w, r, http.StatusNotAcceptable, fmt.Sprintf("Accept must allow one of %s", strings.Join(supported, ", ")))
+			return
+		}
+		exports[format].ServeHTTP(w, r)
+	}
+}
+
+// negotiateExportFormat returns the export format of the media type accept
+// prefers most, honoring q values and wildcards, and false when it accepts
+// none of them. An empty accept selects JSON.
+func negotiateExportFormat(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return "json", true
+	}
+
+	best, bestQ := "", 0.0
+	for _, t := range exportMediaTypes {
+		if q := acceptQuality(accept, t.mediaType); q > bestQ {
+			best, bestQ = t.format, q
+		}
+	}
+	return best, best != ""
+}
+
+// acceptQuality returns the q value accept gives mediaType, taken from the
+// most specific media range matching it, or 0 when none does.
+func acceptQuality(accept, mediaType string) float64 {
+	major, _, _ := strings.Cut(mediaType, "/")
+
+	quality, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		accepted, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		var rank int
+		switch accepted {
+		case mediaType:
+			rank = 2
+		case major + "/*":
+			rank = 1
+		case "*/*":
+			rank = 0
+		default:
+			continue
+		}
+		if rank <= specificity {
+			continue
+		}
+
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		quality, specificity = q, rank
+	}
+	return quality
+}
+
+// getAllLogs retrieves all logs with optional filters, along with the total
+// number of logs matching them.
+func getAllLogs(db *sqlite.Database, r *http.Request, pageSize queries.PageSize) ([]*entities.Log, int, error) {
+	filters, err := exportLogFilters(r, pageSize)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	repo := sqlite.NewLogRepository(db)
-	logs, _, err := repo.FindAll(filters)
-	return logs, err
+	return repo.FindAllContext(r.Context(), filters)
+}
+
+// exportLogFilters returns the repository filters of an export request, capped
+// at the default export page size.
+func exportLogFilters(r *http.Request, pageSize queries.PageSize) (sqlite.LogFilters, error) {
+	minSeverity, err := minSeverityParam(r)
+	if err != nil {
+		return sqlite.LogFilters{}, err
+	}
+
+	category, err := categoryParam(r)
+	if err != nil {
+		return sqlite.LogFilters{}, err
+	}
+
+	bodyFields, err := bodyFieldsParam(r)
+	if err != nil {
+		return sqlite.LogFilters{}, err
+	}
+
+	or, err := orParam(r)
+	if err != nil {
+		return sqlite.LogFilters{}, err
+	}
+
+	return sqlite.LogFilters{
+		Limit:       pageSize.Default,
+		Severity:    r.URL.Query().Get("severity"),
+		MinSeverity: minSeverity,
+		Source:      r.URL.Query().Get("source"),
+		Color:       r.URL.Query().Get("color"),
+		Category:    category,
+		Search:      r.URL.Query().Get("search"),
+		TitleSearch: r.URL.Query().Get("title"),
+		BodySearch:  r.URL.Query().Get("body_contains"),
+		FromDate:    r.URL.Query().Get("from"),
+		ToDate:      r.URL.Query().Get("to"),
+		BodyFields:  bodyFields,
+		Or:          or,
+	}, nil
+}
+
+// timeFormatParam returns the function rendering export timestamps picked
+// by the time_format query parameter: rfc3339 (the default, to the second
+// unlike API responses, so export columns keep a fixed layout), epoch for
+// Unix seconds, or date for the calendar day alone, all in loc.
+func timeFormatParam(r *http.Request, loc *time.Location) (func(time.Time) string, error) {
+	switch format := r.URL.Query().Get("time_format"); format {
+	case "", "rfc3339":
+		return func(t time.Time) string { return t.In(loc).Format(time.RFC3339) }, nil
+	case "epoch":
+		return func(t time.Time) string { return strconv.FormatInt(t.Unix(), 10) }, nil
+	case "date":
+		return func(t time.Time) string { return t.In(loc).Format(time.DateOnly) }, nil
+	default:
+		return nil, fmt.Errorf("%w: time_format must be rfc3339, epoch or date, got %q", errInvalidFilter, format)
+	}
+}
+
+// setExportTotals reports how many logs matched the export filters and
+// whether the export was cut short by the limit.
+func setExportTotals(w http.ResponseWriter, total, exported int) {
+	w.Header().Set("X-Total-Matched", strconv.Itoa(total))
+	w.Header().Set("X-Truncated", strconv.FormatBool(total > exported))
+}
+
+// writeExportError writes the error response of an export that could not
+// read its logs.
+func writeExportError(w http.ResponseWriter, r *http.Request, err error) {
+	if timedOut(err) {
+		writeError(w, r, http.StatusGatewayTimeout, "export exceeded the server's operation timeout; narrow it with filters or a smaller limit")
+		return
+	}
+	writeError(w, r, exportErrorStatus(err), err.Error())
+}
+
+// exportErrorStatus maps an export error to an HTTP status code.
+func exportErrorStatus(err error) int {
+	if errors.Is(err, errInvalidFilter) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
 }
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// Signed export URL parameters. Every other parameter of a signed URL is an
+// export filter covered by the signature.
+const (
+	signedFormatParam    = "format"
+	signedTenantParam    = "tenant"
+	signedExpiresParam   = "expires"
+	signedSignatureParam = "signature"
+)
+
+const (
+	// DefaultSignedExportTTL is how long a signed export URL stays valid
+	// when the request does not say.
+	DefaultSignedExportTTL = time.Hour
+
+	// MaxSignedExportTTL is the longest validity a signed export URL can be
+	// given.
+	MaxSignedExportTTL = 7 * 24 * time.Hour
+)
+
+var (
+	// ErrExportSignatureInvalid is returned for a signed export URL whose
+	// signature is missing or does not match its parameters.
+	ErrExportSignatureInvalid = errors.New("invalid export signature")
+
+	// ErrExportLinkExpired is returned for a signed export URL past its
+	// expiry.
+	ErrExportLinkExpired = errors.New("export link has expired")
+)
+
+// ExportSigner signs and verifies time-limited export URLs with an HMAC key,
+// so an export can be shared without handing out credentials.
+type ExportSigner struct {
+	key []byte
+	now func() time.Time
+}
+
+// NewExportSigner creates a signer using key. An empty key is replaced by a
+// random one, so URLs stay valid only until the process restarts.
+func NewExportSigner(key []byte) *ExportSigner {
+	s := &ExportSigner{now: time.Now}
+	s.SetKey(key)
+	return s
+}
+
+// SetKey replaces the signing key, invalidating URLs signed with the old
+// one. An empty key is replaced by a random one.
+func (s *ExportSigner) SetKey(key []byte) {
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+	s.key = key
+}
+
+// Sign returns query with an expiry and a signature over all its parameters
+// added. query must not already hold an expiry or signature.
+func (s *ExportSigner) Sign(query url.Values, expires time.Time) url.Values {
+	signed := make(url.Values, len(query)+2)
+	for name, values := range query {
+		signed[name] = slices.Clone(values)
+	}
+	signed.Set(signedExpiresParam, strconv.FormatInt(expires.Unix(), 10))
+	signed.Set(signedSignatureParam, s.signature(signed))
+	return signed
+}
+
+// Verify checks the signature and expiry of a signed query.
+func (s *ExportSigner) Verify(query url.Values) error {
+	given, err := hex.DecodeString(query.Get(signedSignatureParam))
+	if err != nil || len(given) == 0 {
+		return ErrExportSignatureInvalid
+	}
+	want, _ := hex.DecodeString(s.signature(query))
+	if !hmac.Equal(given, want) {
+		return ErrExportSignatureInvalid
+	}
+
+	expires, err := strconv.ParseInt(query.Get(signedExpiresParam), 10, 64)
+	if err != nil {
+		return ErrExportSignatureInvalid
+	}
+	if !s.now().Before(time.Unix(expires, 0)) {
+		return ErrExportLinkExpired
+	}
+	return nil
+}
+
+// signature returns the hex HMAC-SHA256 of every parameter of query except
+// the signature, in the sorted order url.Values.Encode uses.
+func (s *ExportSigner) signature(query url.Values) string {
+	unsigned := make(url.Values, len(query))
+	for name, values := range query {
+		if name != signedSignatureParam {
+			unsigned[name] = values
+		}
+	}
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(unsigned.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignExportRequest is the body of POST /api/export/sign.
+type SignExportRequest struct {
+	// Format is the export format: json, csv, ndjson, xml or archive.
+	Format string `json:"format"`
+
+	// Filters are export query parameters, e.g. {"severity": "error",
+	// "from": "2024-05-01"}; see GET /api/export/json.
+	Filters map[string]string `json:"filters,omitempty"`
+
+	// ExpiresIn is how long the URL stays valid, e.g. "30m" or "2d".
+	// Defaults to DefaultSignedExportTTL, capped at MaxSignedExportTTL.
+	ExpiresIn string `json:"expires_in,omitempty"`
+}
+
+// SignExportResponse is the response of POST /api/export/sign.
+type SignExportResponse struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// SignExport handles POST /api/export/sign, returning a time-limited URL
+// that runs the described export without credentials. The URL is bound to
+// the tenant of the request.
+func SignExport(signer *ExportSigner, formats []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req SignExportRequest
+		if !decodeJSONBody(w, r, &req) {
+			return
+		}
+
+		if !slices.Contains(formats, req.Format) {
+			writeError(w, r, http.StatusBadRequest, fmt.Sprintf("format must be one of %s", strings.Join(formats, ", ")))
+			return
+		}
+
+		ttl := DefaultSignedExportTTL
+		if req.ExpiresIn != "" {
+			parsed, err := parseDuration(req.ExpiresIn)
+			if err != nil || parsed <= 0 {
+				writeError(w, r, http.StatusBadRequest, "invalid expires_in duration")
+				return
+			}
+			if parsed > MaxSignedExportTTL {
+				writeError(w, r, http.StatusBadRequest, fmt.Sprintf("expires_in must be at most %s", MaxSignedExportTTL))
+				return
+			}
+			ttl = parsed
+		}
+
+		query := url.Values{signedFormatParam: {req.Format}}
+		if tenant := sqlite.TenantFromContext(r.Context()); tenant != sqlite.DefaultTenant {
+			query.Set(signedTenantParam, tenant)
+		}
+		for name, value := range req.Filters {
+			if !signableFilter(name) {
+				writeError(w, r, http.StatusBadRequest, fmt.Sprintf("unknown export filter %q", name))
+				return
+			}
+			query.Set(name, value)
+		}
+
+		expires := signer.now().Add(ttl).Truncate(time.Second)
+		signed := signer.Sign(query, expires)
+
+		writeJSON(w, r, http.StatusOK, SignExportResponse{
+			URL:       "/api/export/signed?" + signed.Encode(),
+			ExpiresAt: expires.UTC().Format(time.RFC3339),
+		})
+	}
+}
+
+// signableFilter reports whether name may be embedded in a signed export.
+func signableFilter(name string) bool {
+	return slices.Contains(exportFilterParams, name) || name == "tz" || name == "time_format" ||
+		(strings.HasPrefix(name, "body.") && len(name) > len("body."))
+}
+
+// SignedExport handles GET /api/export/signed. A URL with a valid, unexpired
+// signature runs the export handler of its format with its filters, for the
+// tenant it was signed for whatever the request says; any other answers 403.
+func SignedExport(signer *ExportSigner, exports map[string]http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if err := signer.Verify(query); err != nil {
+			writeError(w, r, http.StatusForbidden, err.Error())
+			return
+		}
+
+		export, ok := exports[query.Get(signedFormatParam)]
+		if !ok {
+			writeError(w, r, http.StatusForbidden, ErrExportSignatureInvalid.Error())
+			return
+		}
+
+		filters := make(url.Values, len(query))
+		for name, values := range query {
+			switch name {
+			case signedFormatParam, signedTenantParam, signedExpiresParam, signedSignatureParam:
+			default:
+				filters[name] = values
+			}
+		}
+
+		exportReq := r.Clone(sqlite.ContextWithTenant(r.Context(), query.Get(signedTenantParam)))
+		exportReq.URL.RawQuery = filters.Encode()
+		export.ServeHTTP(w, exportReq)
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// GetFacets handles GET /api/facets.
+// Returns the distinct values and counts for each requested field
+// (?fields=source,severity), or for every facetable field if none are given.
+func GetFacets(db *sqlite.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		allowed := sqlite.FacetFields()
+
+		fields := allowed
+		if raw := r.URL.Query().Get("fields"); raw != "" {
+			fields = nil
+			for _, field := range strings.Split(raw, ",") {
+				field = strings.TrimSpace(field)
+				if field == "" || slices.Contains(fields, field) {
+					continue
+				}
+				if !slices.Contains(allowed, field) {
+					writeErrorDetails(w, r, http.StatusBadRequest, fmt.Sprintf(
+						"field %q is not facetable (allowed: %s)", field, strings.Join(allowed, ", "),
+					), map[string]any{"field": field, "allowed": allowed})
+					return
+				}
+				fields = append(fields, field)
+			}
+		}
+
+		repo := sqlite.NewLogRepository(db)
+		response := make(map[string][]sqlite.FacetValue, len(fields))
+		for _, field := range fields {
+			values, err := repo.CountByFieldContext(r.Context(), field)
+			if err != nil {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+				return
+			}
+			response[field] = values
+		}
+
+		writeJSON(w, r, http.StatusOK, response)
+	}
+}
@@ -1,17 +1,38 @@
 package handlers_test
 
 import (
+	"archive/zip"
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
 	"io/fs"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 	"testing/fstest"
+	"time"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 
+	"github.com/mx-scribe/scribe/internal/application/queries"
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/domain/services"
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
 	"github.com/mx-scribe/scribe/internal/infrastructure/http/handlers"
 	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
 )
@@ -179,15 +200,525 @@ func TestCreateLog_Validation(t *testing.T) {
 				t.Errorf("expected status %d, got %d", tt.wantCode, rec.Code)
 			}
 
-			var resp map[string]string
+			var resp struct {
+				Error handlers.APIError `json:"error"`
+			}
+			_ = json.NewDecoder(rec.Body).Decode(&resp)
+			if resp.Error.Message != tt.wantMsg {
+				t.Errorf("expected error '%s', got '%s'", tt.wantMsg, resp.Error.Message)
+			}
+		})
+	}
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Get("/api/logs", handlers.ListLogs(db))
+	router.Get("/api/logs/{id}", handlers.GetLog(db))
+	router.Get("/api/facets", handlers.GetFacets(db))
+	router.With(func(next http.Handler) http.Handler {
+		return http.MaxBytesHandler(next, 16)
+	}).Post("/api/logs", handlers.CreateLog(db))
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   handlers.ErrorCode
+	}{
+		{"invalid filter", http.MethodGet, "/api/logs?min_severity=loud", "", http.StatusBadRequest, handlers.CodeInvalidRequest},
+		{"invalid id", http.MethodGet, "/api/logs/abc", "", http.StatusBadRequest, handlers.CodeInvalidRequest},
+		{"missing log", http.MethodGet, "/api/logs/99999", "", http.StatusNotFound, handlers.CodeNotFound},
+		{"body too large", http.MethodPost, "/api/logs", `{"header":{"title":"far too long"}}`, http.StatusRequestEntityTooLarge, handlers.CodePayloadTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+
+			var resp map[string]map[string]any
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("invalid error body: %v", err)
+			}
+			apiErr, ok := resp["error"]
+			if !ok {
+				t.Fatalf("expected error envelope, got %v", resp)
+			}
+			if apiErr["code"] != string(tt.wantCode) {
+				t.Errorf("expected code %q, got %v", tt.wantCode, apiErr["code"])
+			}
+			if msg, _ := apiErr["message"].(string); msg == "" {
+				t.Error("expected a non-empty message")
+			}
+			if id, _ := apiErr["request_id"].(string); id == "" {
+				t.Error("expected request_id from context")
+			}
+			if _, ok := apiErr["details"]; ok {
+				t.Errorf("expected details to be omitted, got %v", apiErr["details"])
+			}
+		})
+	}
+}
+
+func TestErrorEnvelope_Details(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/facets?fields=title", nil)
+	rec := httptest.NewRecorder()
+
+	handlers.GetFacets(db).ServeHTTP(rec, req)
+
+	var resp struct {
+		Error struct {
+			Code      string         `json:"code"`
+			Details   map[string]any `json:"details"`
+			RequestID *string        `json:"request_id"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+
+	if resp.Error.Code != string(handlers.CodeInvalidRequest) {
+		t.Errorf("expected code invalid_request, got %q", resp.Error.Code)
+	}
+	if resp.Error.Details["field"] != "title" {
+		t.Errorf("expected details.field 'title', got %v", resp.Error.Details)
+	}
+	if resp.Error.RequestID != nil {
+		t.Errorf("expected request_id omitted without request ID middleware, got %q", *resp.Error.RequestID)
+	}
+}
+
+func TestCreateTextLog_DerivesMetadata(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	tests := []struct {
+		name         string
+		text         string
+		wantTitle    string
+		wantSeverity string
+		wantSource   string
+		wantCategory string
+		wantDescPart string
+	}{
+		{
+			name:         "http 500 line",
+			text:         "[checkout] POST /api/orders returned HTTP 500\n",
+			wantTitle:    "[checkout] POST /api/orders returned HTTP 500",
+			wantSeverity: "error",
+			wantSource:   "checkout",
+			wantCategory: "http",
+		},
+		{
+			name: "panic stack",
+			text: "panic: runtime error: invalid memory address or nil pointer dereference\n\n" +
+				"goroutine 1 [running]:\nmain.main()\n\t/app/main.go:12 +0x1d\n",
+			wantTitle:    "panic: runtime error: invalid memory address or nil pointer dereference",
+			wantSeverity: "error",
+			wantDescPart: "goroutine 1 [running]:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/logs/text", strings.NewReader(tt.text))
+			req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+			rec := httptest.NewRecorder()
+
+			handlers.CreateTextLog(db).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var resp handlers.LogResponse
 			_ = json.NewDecoder(rec.Body).Decode(&resp)
-			if resp["error"] != tt.wantMsg {
-				t.Errorf("expected error '%s', got '%s'", tt.wantMsg, resp["error"])
+
+			if resp.ID == 0 {
+				t.Error("expected created log ID")
+			}
+			if resp.Header.Title != tt.wantTitle {
+				t.Errorf("expected title %q, got %q", tt.wantTitle, resp.Header.Title)
+			}
+			if resp.Header.Severity != tt.wantSeverity {
+				t.Errorf("expected severity %s, got %s", tt.wantSeverity, resp.Header.Severity)
+			}
+			if tt.wantSource != "" && resp.Metadata.DerivedSource != tt.wantSource {
+				t.Errorf("expected derived source %s, got %s", tt.wantSource, resp.Metadata.DerivedSource)
+			}
+			if tt.wantCategory != "" && resp.Metadata.DerivedCategory != tt.wantCategory {
+				t.Errorf("expected category %s, got %s", tt.wantCategory, resp.Metadata.DerivedCategory)
+			}
+			if !strings.Contains(resp.Header.Description, tt.wantDescPart) {
+				t.Errorf("expected description to contain %q, got %q", tt.wantDescPart, resp.Header.Description)
+			}
+		})
+	}
+}
+
+func TestCreateTextLog_IngestPolicies(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	opts := handlers.DefaultIngestOptions()
+	opts.SourceFilter = handlers.NewSourceFilter(nil, []string{"checkout"}, false)
+	handler := handlers.CreateTextLogWithOptions(db, nil, &opts)
+
+	post := func(text string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/logs/text", strings.NewReader(text))
+		req.Header.Set("Content-Type", "text/plain")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The source derived from the line is denied
+	rec := post("[checkout] POST /api/orders returned HTTP 500")
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a denied source, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var errResp struct {
+		Error handlers.APIError `json:"error"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&errResp)
+	if errResp.Error.Code != handlers.CodeSourceDenied {
+		t.Errorf("expected source_not_allowed error, got %+v", errResp.Error)
+	}
+
+	if rec := post("[billing] invoice sent"); rec.Code != http.StatusCreated {
+		t.Errorf("expected another source to be accepted, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// The severity floor applies too
+	opts.SourceFilter = nil
+	opts.SeverityFloor = handlers.NewSeverityFloor("error", false)
+	if rec := post("[billing] invoice sent"); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422 below the severity floor, got %d", rec.Code)
+	}
+
+	_, total, _ := sqlite.NewLogRepository(db).FindAll(sqlite.LogFilters{})
+	if total != 1 {
+		t.Errorf("expected 1 stored log, got %d", total)
+	}
+}
+
+func TestTimestamps_TimezoneAndPrecision(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	router := chi.NewRouter()
+	router.Post("/api/logs/text", handlers.CreateTextLog(db))
+	router.Post("/api/logs/{id}/pin", handlers.PinLog(db))
+
+	do := func(method, path, body string) (*httptest.ResponseRecorder, handlers.LogResponse) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var resp handlers.LogResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	rec, created := do(http.MethodPost, "/api/logs/text?tz=Asia/Tokyo", "Disk almost full")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec, pinned := do(http.MethodPost, fmt.Sprintf("/api/logs/%d/pin?tz=Asia/Tokyo", created.ID), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	for name, resp := range map[string]handlers.LogResponse{"text": created, "pin": pinned} {
+		if !strings.HasSuffix(resp.CreatedAt, "+09:00") {
+			t.Errorf("%s: expected a +09:00 offset, got %s", name, resp.CreatedAt)
+		}
+		createdAt, err := time.Parse(time.RFC3339Nano, resp.CreatedAt)
+		if err != nil {
+			t.Fatalf("%s: invalid created_at: %v", name, err)
+		}
+		if !createdAt.Truncate(time.Millisecond).Equal(time.UnixMilli(resp.CreatedAtMs)) {
+			t.Errorf("%s: created_at %s lost the precision of created_at_ms %d", name, resp.CreatedAt, resp.CreatedAtMs)
+		}
+	}
+
+	rec, _ = do(http.MethodPost, "/api/logs/text?tz=Mars/Olympus", "Disk almost full")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an unknown time zone, got %d", rec.Code)
+	}
+}
+
+func TestCreateTextLog_InvalidRequest(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{"blank body", "text/plain", "  \n\n ", http.StatusBadRequest},
+		{"json content type", "application/json", `{"header":{"title":"x"}}`, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/logs/text", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+
+			handlers.CreateTextLog(db).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
 			}
 		})
 	}
 }
 
+func TestListLogs_ByIDs(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	first := createTestLog(t, db, "First", "info", "api")
+	second := createTestLog(t, db, "Second", "error", "api")
+	third := createTestLog(t, db, "Third", "info", "worker")
+
+	// Other filters are ignored and missing IDs omitted
+	query := fmt.Sprintf("?ids=%d,999,%d,%d&severity=error&limit=1", third, first, second)
+	req := httptest.NewRequest(http.MethodGet, "/api/logs"+query, nil)
+	rec := httptest.NewRecorder()
+	handlers.ListLogs(db).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp handlers.ListLogsResponse
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	var titles []string
+	for _, log := range resp.Logs {
+		titles = append(titles, log.Header.Title)
+	}
+	if strings.Join(titles, ",") != "Third,First,Second" || resp.Total != 3 {
+		t.Errorf("expected logs in request order, got %v (total %d)", titles, resp.Total)
+	}
+
+	for _, query := range []string{"?ids=", "?ids=1,abc", "?ids=" + strings.Repeat("1,", 101)} {
+		req := httptest.NewRequest(http.MethodGet, "/api/logs"+query, nil)
+		rec := httptest.NewRecorder()
+		handlers.ListLogs(db).ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestListLogs_FieldSearch(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "Payment declined", "error", "billing")
+	createTestLog(t, db, "Payment captured", "success", "billing")
+	createTestLog(t, db, "Login failed", "warning", "auth")
+
+	tests := []struct {
+		query     string
+		wantTotal int
+	}{
+		{"?title=payment", 2},
+		{"?body_contains=test", 3},
+		{"?body_contains=declined", 0},
+		{"?title=payment&severity=error", 1},
+		{"?title=payment&search=captured", 1},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/logs"+tt.query, nil)
+		rec := httptest.NewRecorder()
+
+		handlers.ListLogs(db).ServeHTTP(rec, req)
+
+		var resp handlers.ListLogsResponse
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		if resp.Total != tt.wantTotal {
+			t.Errorf("%q: expected total %d, got %d", tt.query, tt.wantTotal, resp.Total)
+		}
+	}
+}
+
+func TestCreateLog_ColorFromSeverity(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	colorizer, err := services.NewSeverityColorizer(map[string]string{"critical": "rose"})
+	if err != nil {
+		t.Fatalf("NewSeverityColorizer failed: %v", err)
+	}
+	opts := handlers.DefaultIngestOptions()
+	opts.Colorizer = colorizer
+	handler := handlers.CreateLogWithOptions(db, nil, &opts)
+
+	create := func(body string) handlers.LogResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+
+		var created map[string]any
+		_ = json.NewDecoder(rec.Body).Decode(&created)
+		id := int64(created["id"].(float64))
+
+		req = httptest.NewRequest(http.MethodGet, "/api/logs/"+strconv.FormatInt(id, 10), nil)
+		rec = httptest.NewRecorder()
+		router := chi.NewRouter()
+		router.Get("/api/logs/{id}", handlers.GetLog(db))
+		router.ServeHTTP(rec, req)
+
+		var resp handlers.LogResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode log: %v", err)
+		}
+		return resp
+	}
+
+	derived := create(`{"header":{"title":"Disk full","severity":"critical"}}`)
+	if derived.Header.Color != "rose" || derived.Metadata.DerivedColor != "rose" {
+		t.Errorf("expected rose derived from critical, got color %q, derived %q", derived.Header.Color, derived.Metadata.DerivedColor)
+	}
+
+	explicit := create(`{"header":{"title":"Disk full","severity":"critical","color":"blue"}}`)
+	if explicit.Header.Color != "blue" || explicit.Metadata.DerivedColor != "" {
+		t.Errorf("expected explicit blue to win, got color %q, derived %q", explicit.Header.Color, explicit.Metadata.DerivedColor)
+	}
+
+	// The color facet counts derived colors
+	req := httptest.NewRequest(http.MethodGet, "/api/facets?fields=color", nil)
+	rec := httptest.NewRecorder()
+	handlers.GetFacets(db).ServeHTTP(rec, req)
+
+	var facets map[string][]sqlite.FacetValue
+	_ = json.NewDecoder(rec.Body).Decode(&facets)
+	want := []sqlite.FacetValue{{Value: "blue", Count: 1}, {Value: "rose", Count: 1}}
+	if !slices.Equal(facets["color"], want) {
+		t.Errorf("expected color facet %v, got %v", want, facets["color"])
+	}
+}
+
+func TestCreateLog_AnalyzeOption(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	opts := handlers.DefaultIngestOptions()
+	handler := handlers.CreateLogWithOptions(db, nil, &opts)
+	body := `{"header":{"title":"POST /api/orders returned HTTP 500","source":"checkout"}}`
+
+	create := func(query string) entities.LogMetadata {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/logs"+query, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("%q: expected status 201, got %d", query, rec.Code)
+		}
+
+		var created map[string]any
+		_ = json.NewDecoder(rec.Body).Decode(&created)
+		log, err := sqlite.NewLogRepository(db).FindByID(int64(created["id"].(float64)))
+		if err != nil {
+			t.Fatalf("failed to find log: %v", err)
+		}
+		return log.Metadata
+	}
+
+	if metadata := create(""); metadata.DerivedSeverity != "error" || metadata.DerivedCategory != "http" {
+		t.Errorf("expected analysis by default, got %+v", metadata)
+	}
+	if metadata := create("?analyze=false"); metadata != (entities.LogMetadata{}) {
+		t.Errorf("expected empty metadata with analyze=false, got %+v", metadata)
+	}
+
+	opts.AutoAnalyze = false
+	if metadata := create(""); metadata != (entities.LogMetadata{}) {
+		t.Errorf("expected empty metadata when disabled by default, got %+v", metadata)
+	}
+	if metadata := create("?analyze=true"); metadata.DerivedSeverity != "error" {
+		t.Errorf("expected analyze=true to override the default, got %+v", metadata)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logs?analyze=sometimes", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for invalid analyze value, got %d", rec.Code)
+	}
+}
+
+func TestStreamLogs_AnalyzeOption(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	opts := handlers.DefaultIngestOptions()
+	handler := handlers.StreamLogsWithOptions(db, nil, &opts)
+	line := `{"header":{"title":"POST /api/orders returned HTTP 500","source":"checkout"}}` + "\n"
+
+	stream := func(query string) entities.LogMetadata {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/logs/stream"+query, strings.NewReader(line))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var summary handlers.StreamSummary
+		if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil || summary.Created != 1 {
+			t.Fatalf("%q: expected the line to be created, got %s", query, rec.Body.String())
+		}
+		logs, _, err := sqlite.NewLogRepository(db).FindAll(sqlite.LogFilters{Limit: 1})
+		if err != nil || len(logs) != 1 {
+			t.Fatalf("failed to find the streamed log: %v", err)
+		}
+		return logs[0].Metadata
+	}
+
+	if metadata := stream(""); metadata.DerivedSeverity != "error" || metadata.DerivedCategory != "http" {
+		t.Errorf("expected analysis by default, got %+v", metadata)
+	}
+	if metadata := stream("?analyze=false"); metadata != (entities.LogMetadata{}) {
+		t.Errorf("expected empty metadata with analyze=false, got %+v", metadata)
+	}
+
+	opts.AutoAnalyze = false
+	if metadata := stream(""); metadata != (entities.LogMetadata{}) {
+		t.Errorf("expected empty metadata when disabled by default, got %+v", metadata)
+	}
+	if metadata := stream("?analyze=true"); metadata.DerivedSeverity != "error" {
+		t.Errorf("expected analyze=true to override the default, got %+v", metadata)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logs/stream?analyze=sometimes", strings.NewReader(line))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for invalid analyze value, got %d", rec.Code)
+	}
+}
+
 func TestListLogs_Pagination(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
@@ -350,714 +881,4181 @@ func TestListLogs_Filters(t *testing.T) {
 	}
 }
 
-func TestGetLog_Success(t *testing.T) {
+func TestListLogs_MinSeverity(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
 
-	// Create a test log
-	id := createTestLog(t, db, "Test log for retrieval", "info", "test-source")
-
-	// Create a router with the route parameter
-	router := chi.NewRouter()
-	router.Get("/api/logs/{id}", handlers.GetLog(db))
+	createTestLog(t, db, "Disk almost full", "critical", "host")
+	createTestLog(t, db, "Job aborted", "error", "worker")
+	createTestLog(t, db, "Queue backlog", "warning", "worker")
+	createTestLog(t, db, "Job done", "info", "worker")
+	createTestLog(t, db, "Tracing span", "debug", "worker")
 
-	rec := httptest.NewRecorder()
+	tests := []struct {
+		name         string
+		query        string
+		wantSeverity []string
+	}{
+		{
+			name:         "warning and above",
+			query:        "?min_severity=warning",
+			wantSeverity: []string{"critical", "error", "warning"},
+		},
+		{
+			name:         "error and above",
+			query:        "?min_severity=error",
+			wantSeverity: []string{"critical", "error"},
+		},
+		{
+			name:         "explicit severity wins",
+			query:        "?min_severity=warning&severity=debug",
+			wantSeverity: []string{"debug"},
+		},
+	}
 
-	// Use proper ID in URL
-	req := httptest.NewRequest(http.MethodGet, "/api/logs/1", nil)
-	_ = id // Used to create the test log
-	router.ServeHTTP(rec, req)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/logs"+tt.query, nil)
+			rec := httptest.NewRecorder()
 
-	if rec.Code != http.StatusOK {
-		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
-	}
+			handlers.ListLogs(db).ServeHTTP(rec, req)
 
-	var resp map[string]any
-	_ = json.NewDecoder(rec.Body).Decode(&resp)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", rec.Code)
+			}
 
-	header := resp["header"].(map[string]any)
-	if header["title"] != "Test log for retrieval" {
-		t.Errorf("expected title 'Test log for retrieval', got '%s'", header["title"])
+			var resp handlers.ListLogsResponse
+			_ = json.NewDecoder(rec.Body).Decode(&resp)
+
+			if resp.Total != len(tt.wantSeverity) {
+				t.Errorf("expected total %d, got %d", len(tt.wantSeverity), resp.Total)
+			}
+			got := make(map[string]bool)
+			for _, log := range resp.Logs {
+				got[log.Header.Severity] = true
+			}
+			for _, severity := range tt.wantSeverity {
+				if !got[severity] {
+					t.Errorf("expected a %s log in results", severity)
+				}
+			}
+			if got["info"] && tt.wantSeverity[0] != "info" {
+				t.Error("did not expect info logs in results")
+			}
+		})
 	}
 }
 
-func TestGetLog_NotFound(t *testing.T) {
+func TestListLogs_InvalidMinSeverity(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
 
-	router := chi.NewRouter()
-	router.Get("/api/logs/{id}", handlers.GetLog(db))
+	tests := []struct {
+		path    string
+		handler http.HandlerFunc
+	}{
+		{"/api/logs?min_severity=loud", handlers.ListLogs(db)},
+		{"/api/export/json?min_severity=loud", handlers.ExportJSON(db)},
+		{"/api/export/csv?min_severity=loud", handlers.ExportCSV(db)},
+	}
 
-	req := httptest.NewRequest(http.MethodGet, "/api/logs/99999", nil)
-	rec := httptest.NewRecorder()
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		rec := httptest.NewRecorder()
 
-	router.ServeHTTP(rec, req)
+		tt.handler.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusNotFound {
-		t.Errorf("expected status 404, got %d", rec.Code)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", tt.path, rec.Code)
+		}
 	}
 }
 
-func TestGetLog_InvalidID(t *testing.T) {
+func TestListLogsWithPagination_ConfiguredPageSize(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
 
-	router := chi.NewRouter()
-	router.Get("/api/logs/{id}", handlers.GetLog(db))
+	for i := 0; i < 6; i++ {
+		createTestLog(t, db, fmt.Sprintf("Log %d", i), "info", "test")
+	}
 
-	req := httptest.NewRequest(http.MethodGet, "/api/logs/invalid", nil)
-	rec := httptest.NewRecorder()
+	pagination := queries.DefaultPagination()
+	pagination.List = queries.PageSize{Default: 2, Max: 4}
+	handler := handlers.ListLogsWithPagination(db, &pagination)
 
-	router.ServeHTTP(rec, req)
+	tests := []struct {
+		query     string
+		wantLimit int
+	}{
+		{"", 2},
+		{"?limit=3", 3},
+		{"?limit=50", 4},
+	}
 
-	if rec.Code != http.StatusBadRequest {
-		t.Errorf("expected status 400, got %d", rec.Code)
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/logs"+tt.query, nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		var resp handlers.ListLogsResponse
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		if resp.Limit != tt.wantLimit || len(resp.Logs) != tt.wantLimit {
+			t.Errorf("%q: expected %d logs, got limit=%d len=%d", tt.query, tt.wantLimit, resp.Limit, len(resp.Logs))
+		}
+	}
+
+	// Changes to the shared pagination apply to subsequent requests
+	pagination.List = queries.PageSize{Default: 5, Max: 5}
+	req := httptest.NewRequest(http.MethodGet, "/api/logs", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var resp handlers.ListLogsResponse
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Limit != 5 {
+		t.Errorf("expected updated default of 5, got %d", resp.Limit)
 	}
 }
 
-func TestGetStats(t *testing.T) {
+func TestListLogsWithPagination_MaxOffset(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
 
-	// Create logs with different severities
-	createTestLog(t, db, "Error 1", "error", "service-a")
-	createTestLog(t, db, "Error 2", "error", "service-a")
-	createTestLog(t, db, "Warning", "warning", "service-b")
-	createTestLog(t, db, "Info 1", "info", "service-a")
-	createTestLog(t, db, "Info 2", "info", "service-b")
+	for i := 0; i < 7; i++ {
+		createTestLog(t, db, fmt.Sprintf("Log %d", i), "info", "test")
+	}
 
-	req := httptest.NewRequest(http.MethodGet, "/api/stats", nil)
-	rec := httptest.NewRecorder()
+	pagination := queries.DefaultPagination()
+	pagination.List = queries.PageSize{Default: 2, Max: 2}
+	pagination.MaxOffset = 4
+	handler := handlers.ListLogsWithPagination(db, &pagination)
 
-	handler := handlers.GetStats(db)
-	handler.ServeHTTP(rec, req)
+	list := func(query string) (*httptest.ResponseRecorder, handlers.ListLogsResponse) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/logs"+query, nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusOK {
-		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		var resp handlers.ListLogsResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
 	}
 
-	var resp struct {
-		Total       int            `json:"total"`
-		Last24Hours int            `json:"last_24_hours"`
-		BySeverity  map[string]int `json:"by_severity"`
-		BySource    map[string]int `json:"by_source"`
+	// Page 3 starts at offset 4, the deepest allowed
+	if rec, resp := list("?page=3"); rec.Code != http.StatusOK || len(resp.Logs) != 2 {
+		t.Fatalf("expected page 3 to return 2 logs, got status %d with %d logs", rec.Code, len(resp.Logs))
 	}
-	_ = json.NewDecoder(rec.Body).Decode(&resp)
 
-	if resp.Total != 5 {
-		t.Errorf("expected total 5, got %d", resp.Total)
+	rec, _ := list("?page=4")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 past the max offset, got %d", rec.Code)
 	}
-
-	if resp.BySeverity["error"] != 2 {
-		t.Errorf("expected 2 errors, got %d", resp.BySeverity["error"])
+	if !strings.Contains(rec.Body.String(), "cursor") {
+		t.Errorf("expected the error to suggest cursor pagination, got %s", rec.Body.String())
 	}
 
-	if resp.BySeverity["warning"] != 1 {
-		t.Errorf("expected 1 warning, got %d", resp.BySeverity["warning"])
+	// Following next_cursor reads every log, past the max offset
+	var titles []string
+	query := ""
+	for pages := 0; pages < 10; pages++ {
+		rec, resp := list(query)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if resp.Total != 7 {
+			t.Errorf("expected total 7 on every page, got %d", resp.Total)
+		}
+		for _, log := range resp.Logs {
+			titles = append(titles, log.Header.Title)
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		query = "?cursor=" + resp.NextCursor
 	}
 
-	if resp.BySeverity["info"] != 2 {
-		t.Errorf("expected 2 info, got %d", resp.BySeverity["info"])
+	want := []string{"Log 6", "Log 5", "Log 4", "Log 3", "Log 2", "Log 1", "Log 0"}
+	if fmt.Sprint(titles) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, titles)
 	}
 
-	if resp.BySource["service-a"] != 3 {
-		t.Errorf("expected 3 from service-a, got %d", resp.BySource["service-a"])
+	if rec, _ := list("?cursor=not-a-cursor"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid cursor, got %d", rec.Code)
 	}
+}
 
-	if resp.BySource["service-b"] != 2 {
-		t.Errorf("expected 2 from service-b, got %d", resp.BySource["service-b"])
+func TestExportJSONWithPagination_ConfiguredPageSize(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	for i := 0; i < 4; i++ {
+		createTestLog(t, db, fmt.Sprintf("Log %d", i), "info", "test")
 	}
-}
 
-func TestHealth(t *testing.T) {
-	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	pagination := queries.DefaultPagination()
+	pagination.Export = queries.PageSize{Default: 3, Max: 3}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/export/json", nil)
 	rec := httptest.NewRecorder()
 
-	handlers.Health(rec, req)
+	handlers.ExportJSONWithPagination(db, &pagination).ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusOK {
-		t.Errorf("expected status 200, got %d", rec.Code)
+	var logs []handlers.LogResponse
+	_ = json.NewDecoder(rec.Body).Decode(&logs)
+	if len(logs) != 3 {
+		t.Errorf("expected 3 exported logs, got %d", len(logs))
 	}
+}
 
-	var resp struct {
-		Status  string `json:"status"`
-		Version string `json:"version"`
+func TestExport_TruncationHeaders(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	for i := 0; i < 4; i++ {
+		createTestLog(t, db, fmt.Sprintf("Error %d", i), "error", "api")
 	}
-	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	createTestLog(t, db, "Info", "info", "api")
 
-	if resp.Status != "ok" {
-		t.Errorf("expected status 'ok', got '%s'", resp.Status)
+	pagination := queries.DefaultPagination()
+	pagination.Export = queries.PageSize{Default: 3, Max: 3}
+
+	tests := []struct {
+		name          string
+		handler       http.HandlerFunc
+		query         string
+		wantMatched   string
+		wantTruncated string
+	}{
+		{"json truncated", handlers.ExportJSONWithPagination(db, &pagination), "?severity=error", "4", "true"},
+		{"csv truncated", handlers.ExportCSVWithPagination(db, &pagination), "?severity=error", "4", "true"},
+		{"json complete", handlers.ExportJSONWithPagination(db, &pagination), "?severity=info", "1", "false"},
 	}
 
-	if resp.Version == "" {
-		t.Error("expected version to be set")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/export"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			tt.handler.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("X-Total-Matched"); got != tt.wantMatched {
+				t.Errorf("expected X-Total-Matched %s, got %q", tt.wantMatched, got)
+			}
+			if got := rec.Header().Get("X-Truncated"); got != tt.wantTruncated {
+				t.Errorf("expected X-Truncated %s, got %q", tt.wantTruncated, got)
+			}
+		})
 	}
 }
 
-func TestExportJSON(t *testing.T) {
+func TestGetLog_Timezone(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
 
-	// Create test logs
-	createTestLog(t, db, "Error log", "error", "api")
-	createTestLog(t, db, "Info log", "info", "database")
-	createTestLog(t, db, "Warning log", "warning", "api")
+	id := createTestLog(t, db, "Zoned", "info", "api")
 
-	req := httptest.NewRequest(http.MethodGet, "/api/export/json", nil)
-	rec := httptest.NewRecorder()
+	router := chi.NewRouter()
+	router.Get("/api/logs/{id}", handlers.GetLog(db))
 
-	handler := handlers.ExportJSON(db)
-	handler.ServeHTTP(rec, req)
+	fetch := func(query string) (*httptest.ResponseRecorder, handlers.LogResponse) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/logs/%d%s", id, query), nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusOK {
-		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		var resp handlers.LogResponse
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		return rec, resp
 	}
 
-	// Check content type
-	contentType := rec.Header().Get("Content-Type")
-	if contentType != "application/json" {
-		t.Errorf("expected Content-Type 'application/json', got '%s'", contentType)
+	_, utc := fetch("")
+	if !strings.HasSuffix(utc.CreatedAt, "Z") {
+		t.Errorf("expected UTC timestamp by default, got %s", utc.CreatedAt)
 	}
 
-	// Check disposition
-	disposition := rec.Header().Get("Content-Disposition")
-	if disposition != "attachment; filename=scribe-logs.json" {
-		t.Errorf("unexpected Content-Disposition: %s", disposition)
+	utcTime, err := time.Parse(time.RFC3339, utc.CreatedAt)
+	if err != nil {
+		t.Fatalf("invalid created_at: %v", err)
+	}
+	if got := time.UnixMilli(utc.CreatedAtMs); !got.Equal(utcTime.Truncate(time.Millisecond)) {
+		t.Errorf("created_at_ms %d does not match created_at %s", utc.CreatedAtMs, utc.CreatedAt)
 	}
 
-	// Parse response
-	var logs []map[string]any
-	if err := json.NewDecoder(rec.Body).Decode(&logs); err != nil {
-		t.Fatalf("failed to decode JSON: %v", err)
+	_, tokyo := fetch("?tz=Asia/Tokyo")
+	if !strings.HasSuffix(tokyo.CreatedAt, "+09:00") {
+		t.Errorf("expected +09:00 offset, got %s", tokyo.CreatedAt)
+	}
+	tokyoTime, err := time.Parse(time.RFC3339, tokyo.CreatedAt)
+	if err != nil {
+		t.Fatalf("invalid created_at: %v", err)
+	}
+	if !tokyoTime.Equal(utcTime) {
+		t.Errorf("expected same instant, got %s and %s", tokyo.CreatedAt, utc.CreatedAt)
+	}
+	if tokyo.CreatedAtMs != utc.CreatedAtMs {
+		t.Errorf("expected created_at_ms independent of tz, got %d and %d", tokyo.CreatedAtMs, utc.CreatedAtMs)
 	}
 
-	if len(logs) != 3 {
-		t.Errorf("expected 3 logs, got %d", len(logs))
+	rec, _ := fetch("?tz=Mars/Olympus")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for unknown tz, got %d", rec.Code)
 	}
 }
 
-func TestExportJSON_WithFilters(t *testing.T) {
+func TestGetLog_Success(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
 
-	createTestLog(t, db, "Error log", "error", "api")
-	createTestLog(t, db, "Info log", "info", "database")
+	// Create a test log
+	id := createTestLog(t, db, "Test log for retrieval", "info", "test-source")
+
+	// Create a router with the route parameter
+	router := chi.NewRouter()
+	router.Get("/api/logs/{id}", handlers.GetLog(db))
 
-	req := httptest.NewRequest(http.MethodGet, "/api/export/json?severity=error", nil)
 	rec := httptest.NewRecorder()
 
-	handler := handlers.ExportJSON(db)
-	handler.ServeHTTP(rec, req)
+	// Use proper ID in URL
+	req := httptest.NewRequest(http.MethodGet, "/api/logs/1", nil)
+	_ = id // Used to create the test log
+	router.ServeHTTP(rec, req)
 
 	if rec.Code != http.StatusOK {
-		t.Fatalf("expected status 200, got %d", rec.Code)
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
 	}
 
-	var logs []map[string]any
-	_ = json.NewDecoder(rec.Body).Decode(&logs)
+	var resp map[string]any
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
 
-	if len(logs) != 1 {
-		t.Errorf("expected 1 error log, got %d", len(logs))
+	header := resp["header"].(map[string]any)
+	if header["title"] != "Test log for retrieval" {
+		t.Errorf("expected title 'Test log for retrieval', got '%s'", header["title"])
 	}
 }
 
-func TestExportCSV(t *testing.T) {
+// prometheusValue returns the value of the unlabelled series name in the
+// default Prometheus output.
+func prometheusValue(t *testing.T, name string) int64 {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handlers.PrometheusMetricsHandler(func() (uint64, int64, uint64) { return 0, 0, 0 }, nil).
+		ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics/prometheus", nil))
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, name+" "); ok {
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				t.Fatalf("invalid value for %s: %q", name, value)
+			}
+			return n
+		}
+	}
+	t.Fatalf("series %s not found in:\n%s", name, rec.Body.String())
+	return 0
+}
+
+func TestGetLog_BodySizeBytes(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
 
-	createTestLog(t, db, "Error log", "error", "api")
-	createTestLog(t, db, "Info log", "info", "database")
+	router := chi.NewRouter()
+	router.Post("/api/logs", handlers.CreateLog(db))
+	router.Get("/api/logs/{id}", handlers.GetLog(db))
 
-	req := httptest.NewRequest(http.MethodGet, "/api/export/csv", nil)
-	rec := httptest.NewRecorder()
+	body := map[string]any{"order_id": "A-1042", "items": []any{"sku-1", "sku-2"}, "total": 99.5}
+	serialized, _ := json.Marshal(body)
 
-	handler := handlers.ExportCSV(db)
-	handler.ServeHTTP(rec, req)
+	count := prometheusValue(t, "scribe_log_body_bytes_count")
+	sum := prometheusValue(t, "scribe_log_body_bytes_sum")
 
-	if rec.Code != http.StatusOK {
-		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	payload, _ := json.Marshal(map[string]any{"header": map[string]any{"title": "Order placed"}, "body": body})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/logs", bytes.NewReader(payload)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
 	}
-
-	// Check content type
-	contentType := rec.Header().Get("Content-Type")
-	if contentType != "text/csv" {
-		t.Errorf("expected Content-Type 'text/csv', got '%s'", contentType)
+	var created struct {
+		ID int64 `json:"id"`
 	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
 
-	// Check disposition
-	disposition := rec.Header().Get("Content-Disposition")
-	if disposition != "attachment; filename=scribe-logs.csv" {
-		t.Errorf("unexpected Content-Disposition: %s", disposition)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/logs/%d", created.ID), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
 	}
-
-	// Check CSV content has header and data rows
-	body := rec.Body.String()
-	if len(body) == 0 {
-		t.Error("expected non-empty CSV body")
+	var resp handlers.LogResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
 	}
-
-	// Should contain the CSV header
-	if !contains(body, "id,severity,source,title,description,created_at") {
-		t.Error("CSV should contain header row")
+	if resp.BodySizeBytes != int64(len(serialized)) {
+		t.Errorf("expected body_size_bytes %d, the length of %s, got %d", len(serialized), serialized, resp.BodySizeBytes)
 	}
 
-	// Should contain the log titles
-	if !contains(body, "Error log") || !contains(body, "Info log") {
-		t.Error("CSV should contain log data")
+	if got := prometheusValue(t, "scribe_log_body_bytes_count") - count; got != 1 {
+		t.Errorf("expected the histogram to observe 1 body, got %d", got)
+	}
+	if got := prometheusValue(t, "scribe_log_body_bytes_sum") - sum; got != int64(len(serialized)) {
+		t.Errorf("expected the histogram sum to grow by %d, got %d", len(serialized), got)
 	}
 }
 
-func TestExportCSV_WithFilters(t *testing.T) {
+func TestCreateLog_LargeIntegerRoundTrip(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
 
-	createTestLog(t, db, "Error log", "error", "api")
-	createTestLog(t, db, "Info log", "info", "database")
+	router := chi.NewRouter()
+	router.Post("/api/logs", handlers.CreateLog(db))
+	router.Post("/api/logs/stream", handlers.StreamLogs(db))
+	router.Get("/api/logs/{id}", handlers.GetLog(db))
 
-	req := httptest.NewRequest(http.MethodGet, "/api/export/csv?source=api", nil)
-	rec := httptest.NewRecorder()
+	// 2^53 + 1 and beyond cannot be represented exactly as a float64
+	const orderID = "12345678901234567"
+	const amount = "1999.99"
 
-	handler := handlers.ExportCSV(db)
-	handler.ServeHTTP(rec, req)
+	body := `{"header":{"title":"Order placed"},"body":{"order_id":` + orderID + `,"amount":` + amount + `,"items":[9007199254740993]}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&created)
 
+	req = httptest.NewRequest(http.MethodPost, "/api/logs/stream", strings.NewReader(body+"\n"))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
 	if rec.Code != http.StatusOK {
-		t.Fatalf("expected status 200, got %d", rec.Code)
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
 	}
 
-	body := rec.Body.String()
+	for _, id := range []int64{created.ID, created.ID + 1} {
+		req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/logs/%d", id), nil)
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
 
-	// Should contain the API log
+		dec := json.NewDecoder(rec.Body)
+		dec.UseNumber()
+		var resp struct {
+			Body map[string]any `json:"body"`
+		}
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatalf("failed to decode log: %v", err)
+		}
+		if got := resp.Body["order_id"]; got != json.Number(orderID) {
+			t.Errorf("log %d: expected order_id %s, got %v", id, orderID, got)
+		}
+		if got := resp.Body["amount"]; got != json.Number(amount) {
+			t.Errorf("log %d: expected amount %s, got %v", id, amount, got)
+		}
+		if items, _ := resp.Body["items"].([]any); len(items) != 1 || items[0] != json.Number("9007199254740993") {
+			t.Errorf("log %d: expected nested integer kept, got %v", id, resp.Body["items"])
+		}
+	}
+}
+
+func TestGetLog_NotFound(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	router := chi.NewRouter()
+	router.Get("/api/logs/{id}", handlers.GetLog(db))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/logs/99999", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestGetLogContext(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ids := make([]int64, 7)
+	for i := range ids {
+		source := "api"
+		if i%2 == 1 {
+			source = "worker"
+		}
+		ids[i] = createTestLog(t, db, fmt.Sprintf("Log %d", i), "info", source)
+	}
+
+	router := chi.NewRouter()
+	router.Get("/api/logs/{id}/context", handlers.GetLogContext(db))
+
+	get := func(query string) (int, handlers.LogContextResponse) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, query, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		var resp handlers.LogContextResponse
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		return rec.Code, resp
+	}
+
+	tests := []struct {
+		query      string
+		wantIDs    []int64
+		wantBefore int
+		wantAfter  int
+	}{
+		{"?before=2&after=2", []int64{ids[1], ids[2], ids[3], ids[4], ids[5]}, 2, 2},
+		{"", ids, 3, 3},
+		{"?before=0&after=1", []int64{ids[3], ids[4]}, 0, 1},
+		// Log 3 comes from worker, so same_source keeps to the other odd logs
+		{"?before=2&after=2&same_source=true", []int64{ids[1], ids[3], ids[5]}, 1, 1},
+	}
+	for _, tt := range tests {
+		status, resp := get(fmt.Sprintf("/api/logs/%d/context%s", ids[3], tt.query))
+		if status != http.StatusOK {
+			t.Fatalf("%q: expected status 200, got %d", tt.query, status)
+		}
+		if resp.TargetID != ids[3] || resp.Before != tt.wantBefore || resp.After != tt.wantAfter {
+			t.Errorf("%q: unexpected response %+v", tt.query, resp)
+		}
+		got := make([]int64, len(resp.Logs))
+		for i, log := range resp.Logs {
+			got[i] = log.ID
+		}
+		if !slices.Equal(got, tt.wantIDs) {
+			t.Errorf("%q: expected logs %v, got %v", tt.query, tt.wantIDs, got)
+		}
+	}
+
+	for query, want := range map[string]int{
+		"/api/logs/999/context":                 http.StatusNotFound,
+		"/api/logs/abc/context":                 http.StatusBadRequest,
+		"/api/logs/1/context?before=-1":         http.StatusBadRequest,
+		"/api/logs/1/context?after=many":        http.StatusBadRequest,
+		"/api/logs/1/context?same_source=sorta": http.StatusBadRequest,
+	} {
+		if status, _ := get(query); status != want {
+			t.Errorf("%s: expected status %d, got %d", query, want, status)
+		}
+	}
+}
+
+func TestGetLog_InvalidID(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	router := chi.NewRouter()
+	router.Get("/api/logs/{id}", handlers.GetLog(db))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/logs/invalid", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestCreateLog_ExpiresIn(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(`{"header":{"title":"Audit event"},"expires_in":"365d"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handlers.CreateLog(db).ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	createTestLog(t, db, "Plain log", "info", "api")
+
+	req = httptest.NewRequest(http.MethodGet, "/api/logs", nil)
+	rec = httptest.NewRecorder()
+	handlers.ListLogs(db).ServeHTTP(rec, req)
+
+	var list handlers.ListLogsResponse
+	_ = json.NewDecoder(rec.Body).Decode(&list)
+	for _, log := range list.Logs {
+		switch log.Header.Title {
+		case "Audit event":
+			expiresAt, err := time.Parse(time.RFC3339, log.ExpiresAt)
+			if err != nil {
+				t.Fatalf("expected an RFC 3339 expires_at, got %q", log.ExpiresAt)
+			}
+			if until := time.Until(expiresAt); until < 364*24*time.Hour || until > 366*24*time.Hour {
+				t.Errorf("expected expires_at about a year out, got %s", log.ExpiresAt)
+			}
+		case "Plain log":
+			if log.ExpiresAt != "" {
+				t.Errorf("expected no expires_at, got %s", log.ExpiresAt)
+			}
+		}
+	}
+
+	for _, expiresIn := range []string{"soon", "-1h", "0s"} {
+		body := fmt.Sprintf(`{"header":{"title":"Bad TTL"},"expires_in":%q}`, expiresIn)
+		req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handlers.CreateLog(db).ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expires_in %q: expected status 400, got %d", expiresIn, rec.Code)
+		}
+	}
+}
+
+func TestCreateLog_SuppliedTimestampOffset(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "Live log", "info", "api")
+
+	// Three hours old, but its +09:00 text would sort after a UTC "now"
+	eventTime := time.Now().Add(-3 * time.Hour).In(time.FixedZone("JST", 9*60*60))
+	body := fmt.Sprintf(`{"header":{"title":"Tokyo log"},"timestamp":%q}`, eventTime.Format(time.RFC3339))
+	req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handlers.CreateLog(db).ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/logs", nil)
+	rec = httptest.NewRecorder()
+	handlers.ListLogs(db).ServeHTTP(rec, req)
+
+	var list handlers.ListLogsResponse
+	_ = json.NewDecoder(rec.Body).Decode(&list)
+	if len(list.Logs) != 2 || list.Logs[0].Header.Title != "Live log" {
+		t.Fatalf("expected the live log first, got %+v", list.Logs)
+	}
+
+	repo := sqlite.NewLogRepository(db)
+	cutoff := time.Now().Add(-time.Hour)
+	counts, err := repo.CountOlderThanContext(context.Background(), []time.Time{cutoff})
+	if err != nil || counts[0] != 1 {
+		t.Errorf("expected 1 log older than an hour, got %v (%v)", counts, err)
+	}
+	if n, err := repo.CountDeletableContext(context.Background(), cutoff); err != nil || n != 1 {
+		t.Errorf("expected 1 deletable log, got %d (%v)", n, err)
+	}
+}
+
+func TestCreateLog_SuppliedTimestamp(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	eventTime := time.Now().UTC().Add(-72 * time.Hour).Truncate(time.Second)
+	body := fmt.Sprintf(`{"header":{"title":"Imported log"},"timestamp":%q}`, eventTime.Format(time.RFC3339))
+	req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handlers.CreateLog(db).ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// A log sent without a timestamp keeps both times equal
+	createTestLog(t, db, "Live log", "info", "api")
+
+	req = httptest.NewRequest(http.MethodGet, "/api/logs", nil)
+	rec = httptest.NewRecorder()
+	handlers.ListLogs(db).ServeHTTP(rec, req)
+
+	var list handlers.ListLogsResponse
+	_ = json.NewDecoder(rec.Body).Decode(&list)
+	if len(list.Logs) != 2 {
+		t.Fatalf("expected 2 logs, got %d", len(list.Logs))
+	}
+	for _, log := range list.Logs {
+		createdAt, _ := time.Parse(time.RFC3339, log.CreatedAt)
+		ingestedAt, _ := time.Parse(time.RFC3339, log.IngestedAt)
+		switch log.Header.Title {
+		case "Imported log":
+			if !createdAt.Equal(eventTime) {
+				t.Errorf("expected created_at %v, got %s", eventTime, log.CreatedAt)
+			}
+			if time.Since(ingestedAt) > time.Minute {
+				t.Errorf("expected ingested_at to be the receipt time, got %s", log.IngestedAt)
+			}
+		case "Live log":
+			if log.CreatedAt != log.IngestedAt {
+				t.Errorf("expected equal times without a timestamp, got %s and %s", log.CreatedAt, log.IngestedAt)
+			}
+		}
+	}
+
+	statsLast24h := func(opts *handlers.TimeFieldOptions) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/stats", nil)
+		rec := httptest.NewRecorder()
+		handlers.GetStatsWithOptions(db, opts).ServeHTTP(rec, req)
+		var stats struct {
+			Last24Hours int `json:"last_24_hours"`
+		}
+		_ = json.NewDecoder(rec.Body).Decode(&stats)
+		return stats.Last24Hours
+	}
+
+	if got := statsLast24h(nil); got != 2 {
+		t.Errorf("expected 2 logs in the last 24h by ingestion time (default), got %d", got)
+	}
+	if got := statsLast24h(&handlers.TimeFieldOptions{Stats: sqlite.EventTime}); got != 1 {
+		t.Errorf("expected 1 log in the last 24h by event time, got %d", got)
+	}
+
+	cleanup := func(opts *handlers.TimeFieldOptions) int64 {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/cleanup", strings.NewReader(`{"retention_days":1,"dry_run":true}`))
+		rec := httptest.NewRecorder()
+		handlers.CleanupLogsWithOptions(db, opts).ServeHTTP(rec, req)
+		var stats handlers.RetentionStats
+		_ = json.NewDecoder(rec.Body).Decode(&stats)
+		return stats.DeletedCount
+	}
+
+	if got := cleanup(nil); got != 1 {
+		t.Errorf("expected retention by event time (default) to catch the imported log, got %d", got)
+	}
+	if got := cleanup(&handlers.TimeFieldOptions{Retention: sqlite.IngestedAt}); got != 0 {
+		t.Errorf("expected retention by ingestion time to keep it, got %d", got)
+	}
+}
+
+func TestGetStats(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	// Create logs with different severities
+	createTestLog(t, db, "Error 1", "error", "service-a")
+	createTestLog(t, db, "Error 2", "error", "service-a")
+	createTestLog(t, db, "Warning", "warning", "service-b")
+	createTestLog(t, db, "Info 1", "info", "service-a")
+	createTestLog(t, db, "Info 2", "info", "service-b")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/stats", nil)
+	rec := httptest.NewRecorder()
+
+	handler := handlers.GetStats(db)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp struct {
+		Total       int            `json:"total"`
+		Last24Hours int            `json:"last_24_hours"`
+		BySeverity  map[string]int `json:"by_severity"`
+		BySource    map[string]int `json:"by_source"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+
+	if resp.Total != 5 {
+		t.Errorf("expected total 5, got %d", resp.Total)
+	}
+
+	if resp.BySeverity["error"] != 2 {
+		t.Errorf("expected 2 errors, got %d", resp.BySeverity["error"])
+	}
+
+	if resp.BySeverity["warning"] != 1 {
+		t.Errorf("expected 1 warning, got %d", resp.BySeverity["warning"])
+	}
+
+	if resp.BySeverity["info"] != 2 {
+		t.Errorf("expected 2 info, got %d", resp.BySeverity["info"])
+	}
+
+	if resp.BySource["service-a"] != 3 {
+		t.Errorf("expected 3 from service-a, got %d", resp.BySource["service-a"])
+	}
+
+	if resp.BySource["service-b"] != 2 {
+		t.Errorf("expected 2 from service-b, got %d", resp.BySource["service-b"])
+	}
+}
+
+func TestGetStats_UnknownSourceLabel(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+	db.SetUnknownSourceLabel("uncategorized")
+
+	createTestLog(t, db, "Sent from nowhere", "info", "")
+	createTestLog(t, db, "Sent from unknown", "info", "unknown")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/stats", nil)
+	rec := httptest.NewRecorder()
+	handlers.GetStats(db).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var stats struct {
+		BySource map[string]int `json:"by_source"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if stats.BySource["uncategorized"] != 1 || stats.BySource["unknown"] != 1 {
+		t.Errorf("expected 1 uncategorized and 1 unknown, got %v", stats.BySource)
+	}
+
+	// Filtering on the label returns the logs counted under it
+	req = httptest.NewRequest(http.MethodGet, "/api/logs?source=uncategorized", nil)
+	rec = httptest.NewRecorder()
+	handlers.ListLogs(db).ServeHTTP(rec, req)
+
+	var list struct {
+		Logs []struct {
+			Header struct {
+				Title string `json:"title"`
+			} `json:"header"`
+		} `json:"logs"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if len(list.Logs) != 1 || list.Logs[0].Header.Title != "Sent from nowhere" {
+		t.Errorf("expected only the log without a source, got %+v", list.Logs)
+	}
+}
+
+func TestGetTopErrors(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "Payment 1001 declined", "error", "billing")
+	createTestLog(t, db, "Payment 1002 declined", "error", "billing")
+	createTestLog(t, db, "Payment 1003 declined", "critical", "billing")
+	createTestLog(t, db, "Cache miss", "info", "billing")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/stats/top-errors?since=1h&limit=5", nil)
+	rec := httptest.NewRecorder()
+
+	handler := handlers.GetTopErrors(db)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp struct {
+		Groups []struct {
+			Title    string `json:"title"`
+			Count    int    `json:"count"`
+			SampleID int64  `json:"sample_id"`
+		} `json:"groups"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+
+	if len(resp.Groups) != 1 {
+		t.Fatalf("expected 1 group, got %d", len(resp.Groups))
+	}
+	if resp.Groups[0].Title != "Payment <n> declined" {
+		t.Errorf("expected normalized title, got %q", resp.Groups[0].Title)
+	}
+	if resp.Groups[0].Count != 3 {
+		t.Errorf("expected count 3, got %d", resp.Groups[0].Count)
+	}
+	if resp.Groups[0].SampleID == 0 {
+		t.Error("expected sample_id to be set")
+	}
+}
+
+func TestGetTopErrors_InvalidSince(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/stats/top-errors?since=yesterday", nil)
+	rec := httptest.NewRecorder()
+
+	handler := handlers.GetTopErrors(db)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestGetErrorsPerSource(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "Payment declined", "error", "billing")
+	createTestLog(t, db, "Payment gateway down", "critical", "billing")
+	createTestLog(t, db, "Invoice sent", "info", "billing")
+	createTestLog(t, db, "Login ok", "info", "auth")
+	createTestLog(t, db, "Token refresh failed", "error", "auth")
+	createTestLog(t, db, "Session created", "info", "auth")
+	createTestLog(t, db, "Session closed", "info", "auth")
+	createTestLog(t, db, "Cache warmed", "info", "cache")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/stats/errors-per-source?since=1h", nil)
+	rec := httptest.NewRecorder()
+
+	handlers.GetErrorsPerSource(db).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp []struct {
+		Source     string  `json:"source"`
+		ErrorCount int     `json:"error_count"`
+		TotalCount int     `json:"total_count"`
+		ErrorRate  float64 `json:"error_rate"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(resp) != 3 {
+		t.Fatalf("expected 3 sources, got %d: %+v", len(resp), resp)
+	}
+	if resp[0].Source != "billing" || resp[0].ErrorCount != 2 || resp[0].TotalCount != 3 || math.Abs(resp[0].ErrorRate-2.0/3) > 1e-9 {
+		t.Errorf("expected billing first with 2 of 3 errors, got %+v", resp[0])
+	}
+	if resp[1].Source != "auth" || resp[1].ErrorCount != 1 || resp[1].TotalCount != 4 || resp[1].ErrorRate != 0.25 {
+		t.Errorf("expected auth second with 1 of 4 errors, got %+v", resp[1])
+	}
+	if resp[2].Source != "cache" || resp[2].ErrorCount != 0 || resp[2].ErrorRate != 0 {
+		t.Errorf("expected cache last without errors, got %+v", resp[2])
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/stats/errors-per-source?since=-1h", nil)
+	rec = httptest.NewRecorder()
+	handlers.GetErrorsPerSource(db).ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for invalid since, got %d", rec.Code)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	rec := httptest.NewRecorder()
+
+	handlers.Health(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+
+	var resp struct {
+		Status  string `json:"status"`
+		Version string `json:"version"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+
+	if resp.Status != "ok" {
+		t.Errorf("expected status 'ok', got '%s'", resp.Status)
+	}
+
+	if resp.Version == "" {
+		t.Error("expected version to be set")
+	}
+}
+
+func TestAnalyzeLog_StatusSeverityOverride(t *testing.T) {
+	matcher, err := services.NewPatternMatcherWithStatusSeverity(map[string]string{"404": "error"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opts := handlers.DefaultIngestOptions()
+	opts.PatternMatcher = matcher
+
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"GET /api/orders returned 404", "error"},
+		{"GET /api/orders returned 503", "critical"},
+	}
+
+	for _, tt := range tests {
+		data, _ := json.Marshal(map[string]any{"header": map[string]any{"title": tt.title}})
+		req := httptest.NewRequest(http.MethodPost, "/api/analyze", bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+
+		handlers.AnalyzeLogWithOptions(&opts)(rec, req)
+
+		var got handlers.MetaResponse
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if got.DerivedSeverity != tt.want {
+			t.Errorf("%s: expected severity %q, got %q", tt.title, tt.want, got.DerivedSeverity)
+		}
+	}
+}
+
+func TestCreateTextLog_StatusSeverityOverride(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	matcher, err := services.NewPatternMatcherWithStatusSeverity(map[string]string{"404": "error"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opts := handlers.DefaultIngestOptions()
+	opts.PatternMatcher = matcher
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logs/text", strings.NewReader("GET /api/orders returned 404"))
+	req.Header.Set("Content-Type", "text/plain")
+	rec := httptest.NewRecorder()
+	handlers.CreateTextLogWithOptions(db, nil, &opts).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp handlers.LogResponse
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Metadata.DerivedSeverity != "error" {
+		t.Errorf("expected the configured matcher to derive error, got %q", resp.Metadata.DerivedSeverity)
+	}
+}
+
+func TestAnalyzeLog(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	tests := []struct {
+		name   string
+		header entities.LogHeader
+		body   map[string]any
+	}{
+		{"http status", entities.LogHeader{Title: "GET /api/orders returned HTTP 503"}, nil},
+		{"security", entities.LogHeader{Title: "SQL injection attempt detected", Source: "waf"}, nil},
+		{"stack trace", entities.LogHeader{Title: "Unhandled exception", Description: "panic: runtime error\ngoroutine 1 [running]:"}, nil},
+		{"body source", entities.LogHeader{Title: "Payment declined"}, map[string]any{"service": "billing"}},
+		{"plain", entities.LogHeader{Title: "User profile viewed"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := map[string]any{
+				"header": map[string]any{
+					"title":       tt.header.Title,
+					"source":      tt.header.Source,
+					"description": tt.header.Description,
+				},
+				"body": tt.body,
+			}
+			data, _ := json.Marshal(payload)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/analyze", bytes.NewReader(data))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			handlers.AnalyzeLog(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var got handlers.MetaResponse
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			body := tt.body
+			if body == nil {
+				body = map[string]any{}
+			}
+			want := services.NewPatternMatcher().AnalyzeLog(entities.NewLog(tt.header, body))
+			if got.DerivedSeverity != want.DerivedSeverity ||
+				got.DerivedSource != want.DerivedSource ||
+				got.DerivedCategory != want.DerivedCategory {
+				t.Errorf("expected %+v, got %+v", want, got)
+			}
+			if tt.name == "security" && got.DerivedSeverity != "critical" {
+				t.Errorf("expected security finding to be critical, got %+v", got)
+			}
+		})
+	}
+
+	// Nothing is stored
+	if _, total, _ := sqlite.NewLogRepository(db).FindAll(sqlite.LogFilters{}); total != 0 {
+		t.Errorf("expected no logs to be stored, got %d", total)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/analyze", strings.NewReader(`{"header":{}}`))
+	rec := httptest.NewRecorder()
+	handlers.AnalyzeLog(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 without title, got %d", rec.Code)
+	}
+}
+
+// postSourceLog posts a log for source to handler and returns the recorder.
+func postSourceLog(handler http.Handler, title, source string) *httptest.ResponseRecorder {
+	body := fmt.Sprintf(`{"header":{"title":%q,"source":%q}}`, title, source)
+	req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCreateLog_SourceRateLimit(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	opts := handlers.DefaultIngestOptions()
+	opts.SourceLimit = handlers.NewSourceLimiter(0.001, 2, false)
+	handler := handlers.CreateLogWithOptions(db, nil, &opts)
+
+	for i := 0; i < 2; i++ {
+		if rec := postSourceLog(handler, "noisy log", "noisy"); rec.Code != http.StatusCreated {
+			t.Fatalf("request %d: expected status 201 within burst, got %d", i, rec.Code)
+		}
+	}
+
+	rec := postSourceLog(handler, "noisy log", "noisy")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429 once burst is spent, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+	var errResp struct {
+		Error handlers.APIError `json:"error"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&errResp)
+	if errResp.Error.Code != handlers.CodeRateLimited || !contains(errResp.Error.Message, "noisy") {
+		t.Errorf("expected rate_limited error naming the source, got %+v", errResp.Error)
+	}
+
+	// Other sources keep flowing
+	if rec := postSourceLog(handler, "quiet log", "quiet"); rec.Code != http.StatusCreated {
+		t.Errorf("expected other source to be accepted, got %d", rec.Code)
+	}
+
+	logs, total, _ := sqlite.NewLogRepository(db).FindAll(sqlite.LogFilters{Source: "noisy"})
+	if total != 2 {
+		t.Errorf("expected 2 stored logs for throttled source, got %d (%d returned)", total, len(logs))
+	}
+}
+
+func TestCreateLog_MinIngestSeverity(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	opts := handlers.DefaultIngestOptions()
+	opts.SeverityFloor = handlers.NewSeverityFloor("info", false)
+	handler := handlers.CreateLogWithOptions(db, nil, &opts)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(`{"header":{"title":"cache probe","severity":"debug"}}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422 for debug log, got %d", rec.Code)
+	}
+	var errResp struct {
+		Error handlers.APIError `json:"error"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&errResp)
+	if errResp.Error.Code != handlers.CodeBelowMinimum {
+		t.Errorf("expected below_min_severity error, got %+v", errResp.Error)
+	}
+
+	for _, severity := range []string{"info", "warning", "error", "audit"} {
+		body := fmt.Sprintf(`{"header":{"title":"kept %s","severity":%q}}`, severity, severity)
+		if rec := post(body); rec.Code != http.StatusCreated {
+			t.Errorf("expected %s log to be stored, got %d", severity, rec.Code)
+		}
+	}
+
+	// The floor applies to the severity derived by pattern matching
+	if rec := post(`{"header":{"title":"entering checkout handler"}}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected log derived as debug to be rejected, got %d", rec.Code)
+	}
+
+	// Drop mode answers 204 and counts
+	floor := handlers.NewSeverityFloor("info", true)
+	opts.SeverityFloor = floor
+	for i := 0; i < 2; i++ {
+		if rec := post(`{"header":{"title":"cache probe","severity":"debug"}}`); rec.Code != http.StatusNoContent {
+			t.Errorf("expected status 204 in drop mode, got %d", rec.Code)
+		}
+	}
+	if floor.Dropped() != 2 {
+		t.Errorf("expected 2 dropped logs, got %d", floor.Dropped())
+	}
+
+	_, total, _ := sqlite.NewLogRepository(db).FindAll(sqlite.LogFilters{})
+	if total != 4 {
+		t.Errorf("expected 4 stored logs, got %d", total)
+	}
+
+	if handlers.NewSeverityFloor("", false) != nil || handlers.NewSeverityFloor("verbose", false) != nil {
+		t.Error("expected no floor for an empty or custom minimum")
+	}
+}
+
+func TestStreamLogs_MinIngestSeverity(t *testing.T) {
+	lines := strings.Join([]string{
+		`{"header":{"title":"cache probe","severity":"debug"}}`,
+		`{"header":{"title":"order placed","severity":"info"}}`,
+		`{"header":{"title":"entering checkout handler"}}`,
+	}, "\n") + "\n"
+
+	for _, drop := range []bool{false, true} {
+		t.Run(fmt.Sprintf("drop=%v", drop), func(t *testing.T) {
+			db := testDB(t)
+			defer db.Close()
+
+			opts := handlers.DefaultIngestOptions()
+			floor := handlers.NewSeverityFloor("info", drop)
+			opts.SeverityFloor = floor
+
+			req := httptest.NewRequest(http.MethodPost, "/api/logs/stream", strings.NewReader(lines))
+			req.Header.Set("Content-Type", "application/x-ndjson")
+			rec := httptest.NewRecorder()
+			handlers.StreamLogsWithOptions(db, nil, &opts).ServeHTTP(rec, req)
+
+			var summary handlers.StreamSummary
+			if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+				t.Fatalf("failed to decode summary: %v", err)
+			}
+			if summary.Created != 1 {
+				t.Errorf("expected only the info line stored, got %+v", summary)
+			}
+			if drop {
+				if summary.Dropped != 2 || summary.Failed != 0 || floor.Dropped() != 2 {
+					t.Errorf("expected 2 dropped lines, got %+v (floor counted %d)", summary, floor.Dropped())
+				}
+			} else if summary.Failed != 2 || len(summary.Errors) != 2 || summary.Errors[0].Index != 0 || summary.Errors[1].Index != 2 {
+				t.Errorf("expected lines 0 and 2 rejected, got %+v", summary)
+			}
+
+			if count, _ := sqlite.NewLogRepository(db).Count(); count != 1 {
+				t.Errorf("expected 1 stored log, got %d", count)
+			}
+		})
+	}
+}
+
+func TestCreateLog_Explain(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	handler := handlers.CreateLogWithOptions(db, nil, nil)
+	post := func(query, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/logs"+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	explain := func(body string) (string, *handlers.SeverityExplanation) {
+		t.Helper()
+		rec := post("?explain=true", body)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Severity string                        `json:"severity"`
+			Explain  *handlers.SeverityExplanation `json:"explain"`
+		}
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		if resp.Explain == nil {
+			t.Fatalf("expected an explanation, got %s", rec.Body.String())
+		}
+		return resp.Severity, resp.Explain
+	}
+
+	// The supplied severity wins over a different derived one
+	severity, got := explain(`{"header":{"title":"Deadlock detected","severity":"warning"}}`)
+	want := handlers.SeverityExplanation{SuppliedSeverity: "warning", DerivedSeverity: "critical", EffectiveSeverity: "warning"}
+	if *got != want || severity != "warning" {
+		t.Errorf("expected %+v, got %+v (severity %s)", want, *got, severity)
+	}
+
+	// Without a supplied severity the derived one is effective
+	_, got = explain(`{"header":{"title":"Deadlock detected"}}`)
+	want = handlers.SeverityExplanation{DerivedSeverity: "critical", EffectiveSeverity: "critical"}
+	if *got != want {
+		t.Errorf("expected %+v, got %+v", want, *got)
+	}
+
+	// A supplied info is replaced by the derived severity
+	_, got = explain(`{"header":{"title":"Deadlock detected","severity":"info"}}`)
+	want = handlers.SeverityExplanation{SuppliedSeverity: "info", DerivedSeverity: "critical", EffectiveSeverity: "critical", Overridden: true}
+	if *got != want {
+		t.Errorf("expected %+v, got %+v", want, *got)
+	}
+
+	// A supplied severity differing from the stored one only in case is kept
+	severity, got = explain(`{"header":{"title":"Deadlock detected","severity":"ERROR"}}`)
+	if got.Overridden || !strings.EqualFold(severity, "error") {
+		t.Errorf("expected a mixed-case severity not to be reported as overridden, got %+v (severity %s)", *got, severity)
+	}
+
+	if rec := post("", `{"header":{"title":"Deadlock detected"}}`); strings.Contains(rec.Body.String(), "explain") {
+		t.Errorf("expected no explanation by default, got %s", rec.Body.String())
+	}
+	if rec := post("?explain=maybe", `{"header":{"title":"Deadlock detected"}}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid explain, got %d", rec.Code)
+	}
+}
+
+func TestCreateLog_SourceFilter(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	// Without lists every source is accepted
+	opts := handlers.DefaultIngestOptions()
+	opts.SourceFilter = handlers.NewSourceFilter(nil, nil, false)
+	if opts.SourceFilter != nil {
+		t.Fatal("expected no filter without patterns")
+	}
+	handler := handlers.CreateLogWithOptions(db, nil, &opts)
+	if rec := postSourceLog(handler, "unfiltered", "anything"); rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 without a filter, got %d", rec.Code)
+	}
+
+	opts.SourceFilter = handlers.NewSourceFilter([]string{"api", "internal-*"}, []string{"internal-sandbox"}, false)
+	for _, source := range []string{"api", "internal-", "internal-billing"} {
+		if rec := postSourceLog(handler, "allowed", source); rec.Code != http.StatusCreated {
+			t.Errorf("expected source %q to be allowed, got %d", source, rec.Code)
+		}
+	}
+
+	for _, source := range []string{"rogue", "api-v2", "external-internal-billing", "internal-sandbox", ""} {
+		rec := postSourceLog(handler, "turned away", source)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("expected status 403 for source %q, got %d", source, rec.Code)
+			continue
+		}
+		var errResp struct {
+			Error handlers.APIError `json:"error"`
+		}
+		_ = json.NewDecoder(rec.Body).Decode(&errResp)
+		if errResp.Error.Code != handlers.CodeSourceDenied {
+			t.Errorf("expected source_not_allowed error for source %q, got %+v", source, errResp.Error)
+		}
+	}
+
+	// A deny list alone admits every other source
+	opts.SourceFilter = handlers.NewSourceFilter(nil, []string{"*-test", "load*gen"}, false)
+	for source, want := range map[string]int{
+		"api":            http.StatusCreated,
+		"payments-test":  http.StatusForbidden,
+		"loadgen":        http.StatusForbidden,
+		"load-small-gen": http.StatusForbidden,
+		"loadgen-2":      http.StatusCreated,
+	} {
+		if rec := postSourceLog(handler, "deny list", source); rec.Code != want {
+			t.Errorf("source %q: expected status %d, got %d", source, want, rec.Code)
+		}
+	}
+
+	// Drop mode answers 204 and counts
+	filter := handlers.NewSourceFilter([]string{"api"}, nil, true)
+	opts.SourceFilter = filter
+	for i := 0; i < 2; i++ {
+		if rec := postSourceLog(handler, "dropped", "rogue"); rec.Code != http.StatusNoContent {
+			t.Errorf("expected status 204 in drop mode, got %d", rec.Code)
+		}
+	}
+	if filter.Dropped() != 2 {
+		t.Errorf("expected 2 dropped logs, got %d", filter.Dropped())
+	}
+
+	_, total, _ := sqlite.NewLogRepository(db).FindAll(sqlite.LogFilters{})
+	if total != 6 {
+		t.Errorf("expected 6 stored logs, got %d", total)
+	}
+}
+
+func TestCreateLog_MaxEventAge(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	opts := handlers.DefaultIngestOptions()
+	opts.MaxEventAge = time.Hour
+	handler := handlers.CreateLogWithOptions(db, nil, &opts)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	stale := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	rec := post(fmt.Sprintf(`{"header":{"title":"replayed backup"},"timestamp":%q}`, stale))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422 for over-age log, got %d", rec.Code)
+	}
+	var errResp struct {
+		Error handlers.APIError `json:"error"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&errResp)
+	if errResp.Error.Code != handlers.CodeEventTooOld || !strings.Contains(errResp.Error.Message, "maximum event age") {
+		t.Errorf("expected event_too_old error, got %+v", errResp.Error)
+	}
+
+	fresh := time.Now().Add(-10 * time.Minute).UTC().Format(time.RFC3339)
+	if rec := post(fmt.Sprintf(`{"header":{"title":"recent backup"},"timestamp":%q}`, fresh)); rec.Code != http.StatusCreated {
+		t.Errorf("expected fresh log to be stored, got %d", rec.Code)
+	}
+	if rec := post(`{"header":{"title":"undated backup"}}`); rec.Code != http.StatusCreated {
+		t.Errorf("expected log without timestamp to be stored, got %d", rec.Code)
+	}
+
+	// The stream endpoint fails over-age lines and keeps the rest
+	var body bytes.Buffer
+	fmt.Fprintf(&body, `{"header":{"title":"stale line"},"timestamp":%q}`+"\n", stale)
+	body.WriteString(`{"header":{"title":"undated line"}}` + "\n")
+	req := httptest.NewRequest(http.MethodPost, "/api/logs/stream", &body)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	streamRec := httptest.NewRecorder()
+	handlers.StreamLogsWithOptions(db, nil, &opts).ServeHTTP(streamRec, req)
+
+	var summary handlers.StreamSummary
+	if err := json.NewDecoder(streamRec.Body).Decode(&summary); err != nil {
+		t.Fatalf("failed to decode summary: %v", err)
+	}
+	if summary.Created != 1 || summary.Failed != 1 || len(summary.Errors) != 1 || summary.Errors[0].Index != 0 || summary.Errors[0].Field != "timestamp" {
+		t.Errorf("expected the first line's timestamp to fail and the second line to be created, got %+v", summary)
+	}
+
+	_, total, _ := sqlite.NewLogRepository(db).FindAll(sqlite.LogFilters{})
+	if total != 3 {
+		t.Errorf("expected 3 stored logs, got %d", total)
+	}
+}
+
+func TestCreateLog_SourceRateLimitDrop(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	limiter := handlers.NewSourceLimiter(0.001, 1, true)
+	opts := handlers.DefaultIngestOptions()
+	opts.SourceLimit = limiter
+	handler := handlers.CreateLogWithOptions(db, nil, &opts)
+
+	if rec := postSourceLog(handler, "first", "noisy"); rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rec.Code)
+	}
+	for i := 0; i < 3; i++ {
+		rec := postSourceLog(handler, "excess", "noisy")
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("expected status 202 in drop mode, got %d", rec.Code)
+		}
+		var resp map[string]any
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		if resp["dropped"] != true {
+			t.Errorf("expected dropped=true, got %v", resp)
+		}
+	}
+	if rec := postSourceLog(handler, "other", "quiet"); rec.Code != http.StatusCreated {
+		t.Errorf("expected other source to be accepted, got %d", rec.Code)
+	}
+
+	if got := limiter.Dropped(); got["noisy"] != 3 || got["quiet"] != 0 {
+		t.Errorf("expected 3 drops for noisy only, got %v", got)
+	}
+	_, total, _ := sqlite.NewLogRepository(db).FindAll(sqlite.LogFilters{})
+	if total != 2 {
+		t.Errorf("expected dropped logs not to be stored, got %d logs", total)
+	}
+}
+
+func TestCreateLog_BodyNormalization(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	opts := handlers.DefaultIngestOptions()
+	opts.BodyNormalizer = services.NewBodyNormalizer(nil)
+	create := handlers.CreateLogWithOptions(db, nil, &opts)
+
+	for _, body := range []string{
+		`{"header":{"title":"api request"},"body":{"elapsed_ms":120}}`,
+		`{"header":{"title":"worker job"},"body":{"latency":120}}`,
+		`{"header":{"title":"db query"},"body":{"duration_ms":120,"latency":999}}`,
+		`{"header":{"title":"slow job"},"body":{"latency_ms":900}}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		create.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/logs?body.duration_ms=120", nil)
+	rec := httptest.NewRecorder()
+	handlers.ListLogs(db)(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var resp handlers.ListLogsResponse
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Total != 3 {
+		t.Fatalf("expected 3 logs with duration_ms=120, got %d", resp.Total)
+	}
+	for _, log := range resp.Logs {
+		switch log.Header.Title {
+		case "api request":
+			if log.Body["elapsed_ms"] != float64(120) {
+				t.Errorf("expected original elapsed_ms to be kept, got %v", log.Body)
+			}
+		case "worker job":
+			if log.Body["latency"] != float64(120) {
+				t.Errorf("expected original latency to be kept, got %v", log.Body)
+			}
+		case "db query":
+			if log.Body["latency"] != float64(999) {
+				t.Errorf("expected existing duration_ms to win over latency, got %v", log.Body)
+			}
+		}
+	}
+
+	// Without normalization only the explicit key matches
+	plain := handlers.CreateLogWithOptions(db, nil, nil)
+	req = httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(`{"header":{"title":"raw"},"body":{"elapsed_ms":120}}`))
+	req.Header.Set("Content-Type", "application/json")
+	plain.ServeHTTP(httptest.NewRecorder(), req)
+
+	_, total, _ := sqlite.NewLogRepository(db).FindAll(sqlite.LogFilters{BodyFields: map[string]string{"duration_ms": "120"}})
+	if total != 3 {
+		t.Errorf("expected unnormalized log not to match, got %d", total)
+	}
+}
+
+func TestCreateLog_BodyRedaction(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	redactor, err := services.NewBodyRedactor(nil, nil)
+	if err != nil {
+		t.Fatalf("NewBodyRedactor failed: %v", err)
+	}
+	opts := handlers.DefaultIngestOptions()
+	opts.BodyRedactor = redactor
+	create := handlers.CreateLogWithOptions(db, nil, &opts)
+
+	body := `{"header":{"title":"payment failed"},"body":{"user":"alice","password":"hunter2","token":"tok-secret","message":"card 4111 1111 1111 1111 declined"}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	create.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	logs, _, err := sqlite.NewLogRepository(db).FindAll(sqlite.LogFilters{})
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("expected 1 stored log, got %d (%v)", len(logs), err)
+	}
+	stored := logs[0].Body
+	if stored["password"] != services.RedactedValue || stored["token"] != services.RedactedValue {
+		t.Errorf("expected secrets to be redacted, got %v", stored)
+	}
+	if stored["message"] != "card "+services.RedactedValue+" declined" {
+		t.Errorf("expected card number to be redacted, got %v", stored["message"])
+	}
+	if stored["user"] != "alice" {
+		t.Errorf("expected other fields to be kept, got %v", stored["user"])
+	}
+
+	for _, secret := range []string{"hunter2", "tok-secret", "4111"} {
+		_, total, _ := sqlite.NewLogRepository(db).FindAll(sqlite.LogFilters{Search: secret})
+		if total != 0 {
+			t.Errorf("expected no log to match %q", secret)
+		}
+	}
+}
+
+func TestListLogs_InvalidBodyField(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/logs?body.bad%20key=1", nil)
+	rec := httptest.NewRecorder()
+	handlers.ListLogs(db)(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestSourceLimiter_Refill(t *testing.T) {
+	if handlers.NewSourceLimiter(0, 10, false) != nil {
+		t.Error("expected nil limiter for a zero rate")
+	}
+
+	limiter := handlers.NewSourceLimiter(50, 1, false)
+	if ok, _ := limiter.Allow("api"); !ok {
+		t.Fatal("expected first log to be allowed")
+	}
+	ok, retryAfter := limiter.Allow("api")
+	if ok {
+		t.Fatal("expected second log to be throttled")
+	}
+	if retryAfter <= 0 || retryAfter > 20*time.Millisecond {
+		t.Errorf("expected retry after within one token interval, got %v", retryAfter)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if ok, _ := limiter.Allow("api"); !ok {
+		t.Error("expected a token after refill")
+	}
+}
+
+func TestReady(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+	rec := httptest.NewRecorder()
+
+	handlers.Ready(db).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+
+	var resp handlers.ReadyResponse
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Status != "ready" || !resp.Schema.IsCurrent() || resp.Schema.Expected == 0 {
+		t.Errorf("expected ready with current schema, got %+v", resp)
+	}
+}
+
+func TestReady_SchemaBehind(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	// Simulate a database left behind by an older deploy
+	_, err := db.Conn().Exec(`DELETE FROM goose_db_version
+		WHERE version_id = (SELECT MAX(version_id) FROM goose_db_version)`)
+	if err != nil {
+		t.Fatalf("failed to roll back schema version: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+	rec := httptest.NewRecorder()
+
+	handlers.Ready(db).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", rec.Code)
+	}
+
+	var resp handlers.ReadyResponse
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Status != "not_ready" {
+		t.Errorf("expected status not_ready, got %q", resp.Status)
+	}
+	if resp.Schema.Current >= resp.Schema.Expected {
+		t.Errorf("expected schema behind, got %+v", resp.Schema)
+	}
+	if !contains(resp.Reason, "schema version mismatch") {
+		t.Errorf("expected mismatch reason, got %q", resp.Reason)
+	}
+
+	// Forced operation reports ready but keeps the reason
+	rec = httptest.NewRecorder()
+	opts := &handlers.ReadinessOptions{AllowSchemaMismatch: true}
+	handlers.ReadyWithOptions(db, opts).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200 with override, got %d", rec.Code)
+	}
+	resp = handlers.ReadyResponse{}
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Status != "ready" || resp.Reason == "" {
+		t.Errorf("expected ready with mismatch reason, got %+v", resp)
+	}
+}
+
+func TestJSONResponse_PrettyAndCompact(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "Formatted", "info", "api")
+
+	tests := []struct {
+		query      string
+		wantPretty bool
+	}{
+		{"", false},
+		{"?pretty=false", false},
+		{"?pretty=yes", false},
+		{"?pretty=true", true},
+		{"?pretty=1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/logs"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			handlers.ListLogs(db).ServeHTTP(rec, req)
+
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("expected Content-Type application/json, got %q", ct)
+			}
+
+			body := rec.Body.String()
+			if pretty := strings.Contains(body, "\n  \""); pretty != tt.wantPretty {
+				t.Errorf("expected pretty=%v, got body %q", tt.wantPretty, body)
+			}
+
+			var resp handlers.ListLogsResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Total != 1 {
+				t.Errorf("expected valid JSON with 1 log, got %v (%v)", resp.Total, err)
+			}
+		})
+	}
+}
+
+func TestJSONResponse_SetsHeaderAndStatus(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	handlers.JSONResponse(rec, http.StatusAccepted, map[string]string{"status": "queued"})
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("expected status 202, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected Content-Type application/json, got %q", ct)
+	}
+	if body := rec.Body.String(); body != `{"status":"queued"}`+"\n" {
+		t.Errorf("unexpected body %q", body)
+	}
+}
+
+func TestJSONResponse_EncodeFailureUsesEnvelope(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	handlers.JSONResponse(rec, http.StatusOK, map[string]any{"ch": make(chan int)})
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rec.Code)
+	}
+	var errResp struct {
+		Error handlers.APIError `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &errResp); err != nil {
+		t.Fatalf("expected a JSON error envelope, got %q: %v", rec.Body.String(), err)
+	}
+	if errResp.Error.Code != handlers.CodeInternal {
+		t.Errorf("expected code internal_error, got %q", errResp.Error.Code)
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	// Create test logs
+	createTestLog(t, db, "Error log", "error", "api")
+	createTestLog(t, db, "Info log", "info", "database")
+	createTestLog(t, db, "Warning log", "warning", "api")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/export/json", nil)
+	rec := httptest.NewRecorder()
+
+	handler := handlers.ExportJSON(db)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Check content type
+	contentType := rec.Header().Get("Content-Type")
+	if contentType != "application/json" {
+		t.Errorf("expected Content-Type 'application/json', got '%s'", contentType)
+	}
+
+	// Check disposition
+	disposition := rec.Header().Get("Content-Disposition")
+	if disposition != "attachment; filename=scribe-logs.json" {
+		t.Errorf("unexpected Content-Disposition: %s", disposition)
+	}
+
+	// Parse response
+	var logs []map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&logs); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	if len(logs) != 3 {
+		t.Errorf("expected 3 logs, got %d", len(logs))
+	}
+}
+
+func TestListLogs_ColorFilterNameOrHex(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	create := handlers.CreateLog(db)
+	for _, body := range []string{
+		`{"header":{"title":"Red one","color":"red"}}`,
+		`{"header":{"title":"Red two","color":"red"}}`,
+		`{"header":{"title":"Blue one","color":"blue"}}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		create.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	titles := func(color string) []string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/logs?color="+url.QueryEscape(color), nil)
+		rec := httptest.NewRecorder()
+		handlers.ListLogs(db).ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("color %q: expected status 200, got %d", color, rec.Code)
+		}
+		var resp handlers.ListLogsResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		var titles []string
+		for _, log := range resp.Logs {
+			titles = append(titles, log.Header.Title)
+		}
+		slices.Sort(titles)
+		return titles
+	}
+
+	byName := titles("red")
+	if want := []string{"Red one", "Red two"}; !slices.Equal(byName, want) {
+		t.Fatalf("expected %v filtering by name, got %v", want, byName)
+	}
+	for _, hex := range []string{"#ef4444", "#EF4444"} {
+		if got := titles(hex); !slices.Equal(got, byName) {
+			t.Errorf("expected filtering by %s to match filtering by name %v, got %v", hex, byName, got)
+		}
+	}
+	if got := titles("#ff0000"); len(got) != 0 {
+		t.Errorf("expected a hex outside the palette to match nothing, got %v", got)
+	}
+}
+
+func TestListLogs_CategoryFilter(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "Unauthorized access attempt", "warning", "gateway")
+	createTestLog(t, db, "Brute force login detected", "critical", "auth")
+	createTestLog(t, db, "Deadlock detected", "critical", "orders")
+	createTestLog(t, db, "Duplicate key on insert", "warning", "orders")
+	createTestLog(t, db, "Cache warmed", "info", "worker")
+
+	titles := func(handler http.Handler, path string) []string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+		var logs []handlers.LogResponse
+		if strings.HasPrefix(path, "/api/export") {
+			if err := json.Unmarshal(rec.Body.Bytes(), &logs); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		} else {
+			var resp handlers.ListLogsResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			logs = resp.Logs
+		}
+		var titles []string
+		for _, log := range logs {
+			titles = append(titles, log.Header.Title)
+		}
+		slices.Sort(titles)
+		return titles
+	}
+
+	list := handlers.ListLogs(db)
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/api/logs?category=security", []string{"Brute force login detected", "Unauthorized access attempt"}},
+		{"/api/logs?category=database", []string{"Deadlock detected", "Duplicate key on insert"}},
+		{"/api/logs?category=database&severity=warning", []string{"Duplicate key on insert"}},
+		{"/api/logs?category=security&severity=critical", []string{"Brute force login detected"}},
+		{"/api/logs?category=performance", nil},
+	}
+	for _, tt := range tests {
+		if got := titles(list, tt.path); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, got)
+		}
+	}
+
+	got := titles(handlers.ExportJSON(db), "/api/export/json?category=database&source=orders")
+	if want := []string{"Deadlock detected", "Duplicate key on insert"}; !slices.Equal(got, want) {
+		t.Errorf("export: expected %v, got %v", want, got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/logs?category=bogus", nil)
+	rec := httptest.NewRecorder()
+	list.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an unknown category, got %d", rec.Code)
+	}
+}
+
+func TestListLogs_OrFilter(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "API error", "error", "api")
+	createTestLog(t, db, "Charge info", "info", "payment-service")
+	createTestLog(t, db, "Charge warning", "warning", "payment-service")
+	createTestLog(t, db, "API info", "info", "api")
+
+	list := handlers.ListLogs(db)
+	titles := func(query string) []string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/logs?"+query, nil)
+		rec := httptest.NewRecorder()
+		list.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", query, rec.Code, rec.Body.String())
+		}
+		var resp handlers.ListLogsResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Total != len(resp.Logs) {
+			t.Errorf("%s: expected total %d to match the %d logs listed", query, resp.Total, len(resp.Logs))
+		}
+		var titles []string
+		for _, log := range resp.Logs {
+			titles = append(titles, log.Header.Title)
+		}
+		slices.Sort(titles)
+		return titles
+	}
+
+	or := "or=severity:error&or=source:payment-service"
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{or, []string{"API error", "Charge info", "Charge warning"}},
+		{or + "&severity=info", []string{"Charge info"}},
+		{or + "&to=" + url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339)), []string{"API error", "Charge info", "Charge warning"}},
+		{or + "&from=" + url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339)), nil},
+		{"or=title:warning&or=title:error&source=api", []string{"API error"}},
+	}
+	for _, tt := range tests {
+		if got := titles(tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.query, tt.want, got)
+		}
+	}
+
+	tooMany := strings.Repeat("or=severity:error&", sqlite.MaxOrFilters+1)
+	for _, query := range []string{"or=severity", "or=level:error", "or=category:bogus", tooMany} {
+		req := httptest.NewRequest(http.MethodGet, "/api/logs?"+query, nil)
+		rec := httptest.NewRecorder()
+		list.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%.40s: expected status 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestExportJSON_WithFilters(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "Error log", "error", "api")
+	createTestLog(t, db, "Info log", "info", "database")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/export/json?severity=error", nil)
+	rec := httptest.NewRecorder()
+
+	handler := handlers.ExportJSON(db)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var logs []map[string]any
+	_ = json.NewDecoder(rec.Body).Decode(&logs)
+
+	if len(logs) != 1 {
+		t.Errorf("expected 1 error log, got %d", len(logs))
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "Error log", "error", "api")
+	createTestLog(t, db, "Info log", "info", "database")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/export/csv", nil)
+	rec := httptest.NewRecorder()
+
+	handler := handlers.ExportCSV(db)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Check content type
+	contentType := rec.Header().Get("Content-Type")
+	if contentType != "text/csv" {
+		t.Errorf("expected Content-Type 'text/csv', got '%s'", contentType)
+	}
+
+	// Check disposition
+	disposition := rec.Header().Get("Content-Disposition")
+	if disposition != "attachment; filename=scribe-logs.csv" {
+		t.Errorf("unexpected Content-Disposition: %s", disposition)
+	}
+
+	// Check CSV content has header and data rows
+	body := rec.Body.String()
+	if len(body) == 0 {
+		t.Error("expected non-empty CSV body")
+	}
+
+	// Should contain the CSV header
+	if !contains(body, "id,severity,source,title,description,created_at") {
+		t.Error("CSV should contain header row")
+	}
+
+	// Should contain the log titles
+	if !contains(body, "Error log") || !contains(body, "Info log") {
+		t.Error("CSV should contain log data")
+	}
+}
+
+// signExportRouter serves the export sign and signed download routes with
+// signer, as the server does.
+func signExportRouter(db *sqlite.Database, signer *handlers.ExportSigner) *chi.Mux {
+	exports := map[string]http.Handler{
+		"json": handlers.ExportJSON(db),
+		"csv":  handlers.ExportCSV(db),
+	}
+	r := chi.NewRouter()
+	r.Post("/api/export/sign", handlers.SignExport(signer, []string{"json", "csv"}))
+	r.Get("/api/export/signed", handlers.SignedExport(signer, exports))
+	return r
+}
+
+func TestSignedExport(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "Payment failed", "error", "billing")
+	createTestLog(t, db, "Disk full", "critical", "infra")
+	createTestLog(t, db, "Invoice sent", "info", "billing")
+
+	router := signExportRouter(db, handlers.NewExportSigner([]byte("test-key")))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/export/sign",
+		strings.NewReader(`{"format":"csv","filters":{"source":"billing"},"expires_in":"30m"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 from sign, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var signed handlers.SignExportResponse
+	if err := json.NewDecoder(rec.Body).Decode(&signed); err != nil {
+		t.Fatalf("failed to decode sign response: %v", err)
+	}
+	expiresAt, err := time.Parse(time.RFC3339, signed.ExpiresAt)
+	if err != nil || time.Until(expiresAt) > 30*time.Minute || time.Until(expiresAt) < 29*time.Minute {
+		t.Errorf("expected expiry in 30 minutes, got %q", signed.ExpiresAt)
+	}
+
+	// The signed URL downloads the export without credentials
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, signed.URL, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 from signed URL, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Errorf("expected CSV export, got content type %q", ct)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Payment failed") || !strings.Contains(body, "Invoice sent") || strings.Contains(body, "Disk full") {
+		t.Errorf("expected only billing logs in export, got:\n%s", body)
+	}
+
+	// Changing a filter breaks the signature
+	tampered, _ := url.Parse(signed.URL)
+	query := tampered.Query()
+	query.Set("source", "infra")
+	tampered.RawQuery = query.Encode()
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tampered.String(), nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403 for tampered filters, got %d", rec.Code)
+	}
+
+	// So does altering the signature itself, or dropping it
+	query = tampered.Query()
+	query.Set("source", "billing")
+	sig := query.Get("signature")
+	query.Set("signature", strings.Repeat("0", len(sig)))
+	tampered.RawQuery = query.Encode()
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tampered.String(), nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403 for tampered signature, got %d", rec.Code)
+	}
+	var errResp struct {
+		Error handlers.APIError `json:"error"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&errResp)
+	if errResp.Error.Code != handlers.CodeForbidden {
+		t.Errorf("expected forbidden error code, got %+v", errResp.Error)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/export/signed?format=csv", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403 without a signature, got %d", rec.Code)
+	}
+
+	// A URL signed with another key is rejected
+	other := handlers.NewExportSigner([]byte("other-key")).Sign(url.Values{"format": {"csv"}}, time.Now().Add(time.Hour))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/export/signed?"+other.Encode(), nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403 for another key's signature, got %d", rec.Code)
+	}
+}
+
+func TestSignedExport_Expired(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+	createTestLog(t, db, "Payment failed", "error", "billing")
+
+	signer := handlers.NewExportSigner([]byte("test-key"))
+	router := signExportRouter(db, signer)
+
+	expired := signer.Sign(url.Values{"format": {"json"}}, time.Now().Add(-time.Minute))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/export/signed?"+expired.Encode(), nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for expired link, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "expired") {
+		t.Errorf("expected expiry message, got %s", rec.Body.String())
+	}
+
+	valid := signer.Sign(url.Values{"format": {"json"}}, time.Now().Add(time.Minute))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/export/signed?"+valid.Encode(), nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200 for unexpired link, got %d", rec.Code)
+	}
+}
+
+func TestSignExport_InvalidRequests(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	router := signExportRouter(db, handlers.NewExportSigner(nil))
+
+	for _, body := range []string{
+		`{"format":"xml"}`,
+		`{"format":"csv","filters":{"signature":"abc"}}`,
+		`{"format":"csv","filters":{"limit":"5"}}`,
+		`{"format":"csv","expires_in":"soon"}`,
+		`{"format":"csv","expires_in":"30d"}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/export/sign", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %s, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestExportCSV_WithFilters(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "Error log", "error", "api")
+	createTestLog(t, db, "Info log", "info", "database")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/export/csv?source=api", nil)
+	rec := httptest.NewRecorder()
+
+	handler := handlers.ExportCSV(db)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	body := rec.Body.String()
+
+	// Should contain the API log
 	if !contains(body, "Error log") {
 		t.Error("CSV should contain api log")
 	}
 
-	// Should NOT contain the database log
-	if contains(body, "Info log") {
-		t.Error("CSV should not contain filtered out log")
-	}
-}
+	// Should NOT contain the database log
+	if contains(body, "Info log") {
+		t.Error("CSV should not contain filtered out log")
+	}
+}
+
+func TestExport_AcceptNegotiation(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "Error log", "error", "api")
+	createTestLog(t, db, "Info log", "info", "database")
+
+	handler := handlers.Export(db)
+
+	tests := []struct {
+		accept          string
+		wantContentType string
+		wantBody        string
+	}{
+		{"", "application/json", `"title":"Error log"`},
+		{"application/json", "application/json", `"title":"Error log"`},
+		{"text/csv", "text/csv", "id,severity,source,title,description,created_at"},
+		{"application/x-ndjson", "application/x-ndjson", `"title":"Info log"`},
+		{"application/xml", "application/xml", "<title>Error log</title>"},
+		{"*/*", "application/json", `"title":"Error log"`},
+		{"text/*", "text/csv", "Info log"},
+		{"text/html, application/xml;q=0.5, text/csv;q=0.8", "text/csv", "Error log"},
+		{"application/json;q=0, */*;q=0.1", "text/csv", "Error log"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/export", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("Accept %q: expected status 200, got %d", tt.accept, rec.Code)
+			continue
+		}
+		if got := rec.Header().Get("Content-Type"); got != tt.wantContentType {
+			t.Errorf("Accept %q: expected Content-Type %q, got %q", tt.accept, tt.wantContentType, got)
+		}
+		if !strings.Contains(rec.Body.String(), tt.wantBody) {
+			t.Errorf("Accept %q: expected body to contain %q, got %s", tt.accept, tt.wantBody, rec.Body.String())
+		}
+		if rec.Header().Get("Vary") != "Accept" {
+			t.Errorf("Accept %q: expected Vary: Accept, got %q", tt.accept, rec.Header().Get("Vary"))
+		}
+	}
+
+	// Filters apply to the negotiated export
+	req := httptest.NewRequest(http.MethodGet, "/api/export?source=api", nil)
+	req.Header.Set("Accept", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n"); len(lines) != 1 || !strings.Contains(lines[0], "Error log") {
+		t.Errorf("expected only the api log, got %s", rec.Body.String())
+	}
+
+	for _, accept := range []string{"text/html", "image/png, application/json;q=0"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/export", nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotAcceptable {
+			t.Errorf("Accept %q: expected status 406, got %d", accept, rec.Code)
+		}
+	}
+}
+
+func TestExportXML(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "Disk <full> & failing", "error", "api")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/export/xml", nil)
+	rec := httptest.NewRecorder()
+	handlers.ExportXML(db).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if disposition := rec.Header().Get("Content-Disposition"); disposition != "attachment; filename=scribe-logs.xml" {
+		t.Errorf("unexpected Content-Disposition: %s", disposition)
+	}
+
+	var doc struct {
+		Logs []struct {
+			ID       int64  `xml:"id,attr"`
+			Severity string `xml:"severity"`
+			Title    string `xml:"title"`
+		} `xml:"log"`
+	}
+	if err := xml.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to parse XML export: %v\n%s", err, rec.Body.String())
+	}
+	if len(doc.Logs) != 1 || doc.Logs[0].Title != "Disk <full> & failing" || doc.Logs[0].Severity != "error" || doc.Logs[0].ID == 0 {
+		t.Errorf("unexpected XML logs: %+v", doc.Logs)
+	}
+}
+
+func TestExport_TimeFormat(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	// 23:30 UTC, already the next day in Tokyo. The fraction of a second is
+	// left out of the rfc3339 layout, which exports keep to the second.
+	log := entities.NewLog(entities.LogHeader{Title: "Timed export"}, nil)
+	log.CreatedAt = time.Date(2024, 3, 15, 23, 30, 5, 123456789, time.UTC)
+	if err := sqlite.NewLogRepository(db).Create(log); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "2024-03-15T23:30:05Z"},
+		{"?time_format=rfc3339", "2024-03-15T23:30:05Z"},
+		{"?time_format=rfc3339&tz=Asia/Tokyo", "2024-03-16T08:30:05+09:00"},
+		{"?time_format=epoch", "1710545405"},
+		{"?time_format=epoch&tz=Asia/Tokyo", "1710545405"},
+		{"?time_format=date", "2024-03-15"},
+		{"?time_format=date&tz=Asia/Tokyo", "2024-03-16"},
+	}
+
+	for _, tt := range tests {
+		t.Run("csv"+tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/export/csv"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			handlers.ExportCSV(db).ServeHTTP(rec, req)
+
+			records, err := csv.NewReader(rec.Body).ReadAll()
+			if err != nil || len(records) != 2 {
+				t.Fatalf("expected header and one row, got %v (%v)", records, err)
+			}
+			if got := records[1][5]; got != tt.want {
+				t.Errorf("expected created_at %q, got %q", tt.want, got)
+			}
+		})
+
+		t.Run("xml"+tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/export/xml"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			handlers.ExportXML(db).ServeHTTP(rec, req)
+
+			var doc struct {
+				Logs []struct {
+					CreatedAt string `xml:"created_at"`
+				} `xml:"log"`
+			}
+			if err := xml.Unmarshal(rec.Body.Bytes(), &doc); err != nil || len(doc.Logs) != 1 {
+				t.Fatalf("expected one log, got %+v (%v)", doc.Logs, err)
+			}
+			if got := doc.Logs[0].CreatedAt; got != tt.want {
+				t.Errorf("expected created_at %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	for _, handler := range []http.HandlerFunc{handlers.ExportCSV(db), handlers.ExportXML(db)} {
+		req := httptest.NewRequest(http.MethodGet, "/api/export?time_format=excel", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for unknown time_format, got %d", rec.Code)
+		}
+	}
+}
+
+func TestExportArchive(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "Error one", "error", "api")
+	createTestLog(t, db, "Error two", "error", "api")
+	createTestLog(t, db, "Info log", "info", "api")
+	createTestLog(t, db, "Other error", "error", "worker")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/export/archive?source=api&severity=error&pretty=true", nil)
+	rec := httptest.NewRecorder()
+	handlers.ExportArchive(db).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("expected application/zip, got %q", ct)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+
+	data, ok := files["logs.ndjson"]
+	if !ok {
+		t.Fatalf("expected logs.ndjson in archive, got %v", archive.File)
+	}
+	var manifest handlers.ExportManifest
+	if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil {
+		t.Fatalf("failed to decode manifest: %v", err)
+	}
+
+	sum := sha256.Sum256(data)
+	if manifest.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("manifest checksum %s does not match data file", manifest.SHA256)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if manifest.RowCount != 2 || len(lines) != 2 {
+		t.Errorf("expected 2 rows, manifest says %d and data has %d", manifest.RowCount, len(lines))
+	}
+	for _, line := range lines {
+		var log handlers.LogResponse
+		if err := json.Unmarshal([]byte(line), &log); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", line, err)
+		}
+		if log.Header.Source != "api" || log.Header.Severity != "error" {
+			t.Errorf("expected only filtered logs, got %+v", log.Header)
+		}
+	}
+
+	if len(manifest.Filters) != 2 || manifest.Filters["source"] != "api" || manifest.Filters["severity"] != "error" {
+		t.Errorf("expected manifest to record the filters, got %v", manifest.Filters)
+	}
+	if manifest.DataFile != "logs.ndjson" || manifest.TotalMatched != 2 || manifest.Truncated {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+	if _, err := time.Parse(time.RFC3339, manifest.ExportedAt); err != nil {
+		t.Errorf("expected RFC 3339 exported_at, got %q", manifest.ExportedAt)
+	}
+
+	// Tampering with the data breaks the checksum
+	tampered := sha256.Sum256(bytes.Replace(data, []byte("Error one"), []byte("Error 1"), 1))
+	if manifest.SHA256 == hex.EncodeToString(tampered[:]) {
+		t.Error("expected modified data not to match the manifest checksum")
+	}
+}
+
+func contains(s, substr string) bool {
+	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
+}
+
+func containsHelper(s, substr string) bool {
+	for i := 0; i <= len(s)-len(substr); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
+	}
+	return false
+}
+
+// Mock filesystem for SPA handler tests
+func mockFS() fs.FS {
+	return fstest.MapFS{
+		"dist/index.html": &fstest.MapFile{
+			Data: []byte("<!DOCTYPE html><html><body>SPA</body></html>"),
+		},
+		"dist/assets/app.js": &fstest.MapFile{
+			Data: []byte("console.log('app');"),
+		},
+		"dist/assets/style.css": &fstest.MapFile{
+			Data: []byte("body { color: black; }"),
+		},
+		"dist/favicon.ico": &fstest.MapFile{
+			Data: []byte("icon-data"),
+		},
+	}
+}
+
+func TestSPAHandler_ServeIndex(t *testing.T) {
+	handler := handlers.NewSPAHandler(mockFS(), "dist")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+
+	contentType := rec.Header().Get("Content-Type")
+	if contentType != "text/html; charset=utf-8" {
+		t.Errorf("expected Content-Type 'text/html; charset=utf-8', got '%s'", contentType)
+	}
+
+	// Should have no-cache headers for index.html
+	cacheControl := rec.Header().Get("Cache-Control")
+	if cacheControl != "no-cache, no-store, must-revalidate" {
+		t.Errorf("expected no-cache for index.html, got '%s'", cacheControl)
+	}
+}
+
+func TestSPAHandler_ServeAsset(t *testing.T) {
+	handler := handlers.NewSPAHandler(mockFS(), "dist")
+
+	tests := []struct {
+		name        string
+		path        string
+		wantContent string
+		wantType    string
+		wantCache   string
+	}{
+		{
+			name:        "serve JavaScript asset",
+			path:        "/assets/app.js",
+			wantContent: "console.log('app');",
+			wantType:    "application/javascript; charset=utf-8",
+			wantCache:   "public, max-age=31536000, immutable",
+		},
+		{
+			name:        "serve CSS asset",
+			path:        "/assets/style.css",
+			wantContent: "body { color: black; }",
+			wantType:    "text/css; charset=utf-8",
+			wantCache:   "public, max-age=31536000, immutable",
+		},
+		{
+			name:        "serve favicon",
+			path:        "/favicon.ico",
+			wantContent: "icon-data",
+			wantType:    "image/x-icon",
+			wantCache:   "", // No special caching for favicon
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("expected status 200, got %d", rec.Code)
+			}
+
+			contentType := rec.Header().Get("Content-Type")
+			if contentType != tt.wantType {
+				t.Errorf("expected Content-Type '%s', got '%s'", tt.wantType, contentType)
+			}
+
+			cacheControl := rec.Header().Get("Cache-Control")
+			if cacheControl != tt.wantCache {
+				t.Errorf("expected Cache-Control '%s', got '%s'", tt.wantCache, cacheControl)
+			}
+
+			body := rec.Body.String()
+			if body != tt.wantContent {
+				t.Errorf("expected body '%s', got '%s'", tt.wantContent, body)
+			}
+		})
+	}
+}
+
+func TestSPAHandler_SPAFallback(t *testing.T) {
+	handler := handlers.NewSPAHandler(mockFS(), "dist")
+
+	// Request a path that doesn't exist - should serve index.html for SPA routing
+	tests := []string{
+		"/dashboard",
+		"/logs/123",
+		"/settings/profile",
+		"/nonexistent/path/here",
+	}
+
+	for _, path := range tests {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("expected status 200 for SPA fallback, got %d", rec.Code)
+			}
+
+			contentType := rec.Header().Get("Content-Type")
+			if contentType != "text/html; charset=utf-8" {
+				t.Errorf("expected Content-Type 'text/html; charset=utf-8' for SPA fallback, got '%s'", contentType)
+			}
+
+			body := rec.Body.String()
+			if !contains(body, "SPA") {
+				t.Error("expected index.html content for SPA fallback")
+			}
+		})
+	}
+}
+
+func TestSPAHandler_PathCleaning(t *testing.T) {
+	handler := handlers.NewSPAHandler(mockFS(), "dist")
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"path with double slash", "//assets//app.js"},
+		{"path with dot segments", "/assets/../assets/app.js"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			// All paths should either serve the file or fall back to index.html
+			if rec.Code != http.StatusOK {
+				t.Errorf("expected status 200, got %d for path %s", rec.Code, tt.path)
+			}
+		})
+	}
+}
+
+func TestSPAHandler_ContentTypes(t *testing.T) {
+	// Test getContentType for various file types
+	mockFSWithTypes := fstest.MapFS{
+		"dist/index.html":  &fstest.MapFile{Data: []byte("html")},
+		"dist/image.png":   &fstest.MapFile{Data: []byte("png")},
+		"dist/image.jpg":   &fstest.MapFile{Data: []byte("jpg")},
+		"dist/image.gif":   &fstest.MapFile{Data: []byte("gif")},
+		"dist/icon.svg":    &fstest.MapFile{Data: []byte("svg")},
+		"dist/data.json":   &fstest.MapFile{Data: []byte("{}")},
+		"dist/font.woff":   &fstest.MapFile{Data: []byte("woff")},
+		"dist/font.woff2":  &fstest.MapFile{Data: []byte("woff2")},
+		"dist/font.ttf":    &fstest.MapFile{Data: []byte("ttf")},
+		"dist/font.eot":    &fstest.MapFile{Data: []byte("eot")},
+		"dist/unknown.xyz": &fstest.MapFile{Data: []byte("unknown")},
+	}
+
+	handler := handlers.NewSPAHandler(mockFSWithTypes, "dist")
+
+	tests := []struct {
+		path     string
+		wantType string
+	}{
+		{"/image.png", "image/png"},
+		{"/image.jpg", "image/jpeg"},
+		{"/image.gif", "image/gif"},
+		{"/icon.svg", "image/svg+xml"},
+		{"/data.json", "application/json; charset=utf-8"},
+		{"/font.woff", "font/woff"},
+		{"/font.woff2", "font/woff2"},
+		{"/font.ttf", "font/ttf"},
+		{"/font.eot", "application/vnd.ms-fontobject"},
+		{"/unknown.xyz", "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", rec.Code)
+			}
+
+			contentType := rec.Header().Get("Content-Type")
+			if contentType != tt.wantType {
+				t.Errorf("expected Content-Type '%s', got '%s'", tt.wantType, contentType)
+			}
+		})
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	getMetrics := func() (uint64, int64, uint64) {
+		return 100, 5, 3 // totalRequests, activeRequests, totalErrors
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+
+	handler := handlers.MetricsHandler(getMetrics, nil)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+
+	contentType := rec.Header().Get("Content-Type")
+	if contentType != "application/json" {
+		t.Errorf("expected Content-Type 'application/json', got '%s'", contentType)
+	}
+
+	var resp struct {
+		TotalRequests  uint64 `json:"total_requests"`
+		ActiveRequests int64  `json:"active_requests"`
+		TotalErrors    uint64 `json:"total_errors"`
+		ErrorRate      string `json:"error_rate"`
+		Uptime         string `json:"uptime"`
+		GoRoutines     int    `json:"go_routines"`
+		MemoryMB       uint64 `json:"memory_mb"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+
+	if resp.TotalRequests != 100 {
+		t.Errorf("expected 100 total requests, got %d", resp.TotalRequests)
+	}
+	if resp.ActiveRequests != 5 {
+		t.Errorf("expected 5 active requests, got %d", resp.ActiveRequests)
+	}
+	if resp.TotalErrors != 3 {
+		t.Errorf("expected 3 total errors, got %d", resp.TotalErrors)
+	}
+	if resp.ErrorRate != "3.000%" {
+		t.Errorf("expected error rate '3.000%%', got '%s'", resp.ErrorRate)
+	}
+	if resp.GoRoutines <= 0 {
+		t.Error("expected go_routines > 0")
+	}
+}
+
+func TestMetricsHandler_WithSSE(t *testing.T) {
+	getMetrics := func() (uint64, int64, uint64) {
+		return 50, 2, 1
+	}
+
+	hub := handlers.NewSSEHub()
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+
+	handler := handlers.MetricsHandler(getMetrics, hub)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+
+	var resp struct {
+		SSEClients int `json:"sse_clients"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+
+	// SSEClients should be 0 since no clients are connected
+	if resp.SSEClients != 0 {
+		t.Errorf("expected 0 SSE clients, got %d", resp.SSEClients)
+	}
+}
+
+func TestPrometheusMetricsHandler(t *testing.T) {
+	getMetrics := func() (uint64, int64, uint64) {
+		return 200, 10, 5
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics/prometheus", nil)
+	rec := httptest.NewRecorder()
+
+	handler := handlers.PrometheusMetricsHandler(getMetrics, nil)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+
+	contentType := rec.Header().Get("Content-Type")
+	if contentType != "text/plain; charset=utf-8" {
+		t.Errorf("expected Content-Type 'text/plain; charset=utf-8', got '%s'", contentType)
+	}
+
+	body := rec.Body.String()
+
+	// Check for required Prometheus metrics
+	expectedMetrics := []string{
+		"scribe_http_requests_total 200",
+		"scribe_http_requests_active 10",
+		"scribe_http_errors_total 5",
+		"scribe_uptime_seconds",
+		"scribe_goroutines",
+		"scribe_memory_bytes",
+		"scribe_sse_clients",
+	}
+
+	for _, metric := range expectedMetrics {
+		if !contains(body, metric) {
+			t.Errorf("expected Prometheus output to contain '%s'", metric)
+		}
+	}
+
+	// Check for HELP and TYPE comments
+	if !contains(body, "# HELP scribe_http_requests_total") {
+		t.Error("expected HELP comment for requests_total")
+	}
+	if !contains(body, "# TYPE scribe_http_requests_total counter") {
+		t.Error("expected TYPE comment for requests_total")
+	}
+}
+
+func TestPrometheusMetricsHandler_PrefixAndLabels(t *testing.T) {
+	getMetrics := func() (uint64, int64, uint64) {
+		return 200, 10, 5
+	}
+
+	opts := handlers.PrometheusOptions{
+		Prefix: "logs_",
+		Labels: map[string]string{"instance": "scribe-1", "env": `pr"od`},
+	}
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("expected valid options, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics/prometheus", nil)
+	rec := httptest.NewRecorder()
+	handlers.PrometheusMetricsHandlerWithOptions(getMetrics, nil, &opts).ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	if contains(body, "scribe_") {
+		t.Error("expected the default prefix to be replaced")
+	}
+	if !contains(body, `logs_http_requests_total{env="pr\"od",instance="scribe-1"} 200`) {
+		t.Errorf("expected prefixed, labelled requests_total, got:\n%s", body)
+	}
+
+	series, ingested := 0, 0
+	for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+		if strings.HasPrefix(line, "#") {
+			if !strings.HasPrefix(line, "# HELP logs_") && !strings.HasPrefix(line, "# TYPE logs_") {
+				t.Errorf("expected prefixed comment, got %q", line)
+			}
+			continue
+		}
+		if !strings.HasPrefix(line, "logs_") || !contains(line, `{env="pr\"od",instance="scribe-1"`) {
+			t.Errorf("expected prefix and labels on %q", line)
+		}
+		// Custom severities ingested by other tests add ingestion series
+		if strings.HasPrefix(line, "logs_logs_ingested_total{") {
+			ingested++
+			continue
+		}
+		series++
+	}
+	// 7 gauges and counters, then the body size histogram's 8 buckets, sum
+	// and count
+	if series != 17 {
+		t.Errorf("expected 17 series, got %d", series)
+	}
+	if ingested < len(valueobjects.StandardSeverities()) {
+		t.Errorf("expected an ingestion series per standard severity, got %d", ingested)
+	}
+	if !contains(body, `logs_log_body_bytes_bucket{env="pr\"od",instance="scribe-1",le="+Inf"} `) {
+		t.Errorf("expected the body size histogram buckets to carry the labels and le, got:\n%s", body)
+	}
+}
+
+func TestPrometheusMetricsHandler_LogsIngestedBySeverity(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	series := func(severity string) string {
+		return `scribe_logs_ingested_total{severity="` + severity + `"}`
+	}
+	before := map[string]int64{}
+	for _, severity := range []string{"error", "warning", "info", "critical"} {
+		before[severity] = prometheusValue(t, series(severity))
+	}
+
+	// Single creates
+	createTestLog(t, db, "Disk failing", "error", "api")
+	createTestLog(t, db, "Disk failing again", "error", "api")
+	createTestLog(t, db, "Slow response", "warning", "api")
+
+	// A streamed batch
+	var body bytes.Buffer
+	body.WriteString(`{"header":{"title":"Batch error","severity":"error"}}` + "\n")
+	body.WriteString(`{"header":{"title":"Batch info","severity":"info"}}` + "\n")
+	body.WriteString(`{"header":{"title":"Batch info 2","severity":"info"}}` + "\n")
+	req := httptest.NewRequest(http.MethodPost, "/api/logs/stream", &body)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	handlers.StreamLogs(db).ServeHTTP(rec, req)
+
+	var summary handlers.StreamSummary
+	if err := json.NewDecoder(rec.Body).Decode(&summary); err != nil || summary.Created != 3 {
+		t.Fatalf("expected the batch to be created, got %+v (%v)", summary, err)
+	}
+
+	want := map[string]int64{"error": 3, "warning": 1, "info": 2, "critical": 0}
+	for severity, n := range want {
+		if got := prometheusValue(t, series(severity)) - before[severity]; got != n {
+			t.Errorf("expected %s counter to grow by %d, got %d", severity, n, got)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handlers.PrometheusMetricsHandler(func() (uint64, int64, uint64) { return 0, 0, 0 }, nil).
+		ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics/prometheus", nil))
+	if !contains(rec.Body.String(), "# TYPE scribe_logs_ingested_total counter") {
+		t.Error("expected scribe_logs_ingested_total to be typed as a counter")
+	}
+}
+
+func TestPrometheusMetricsHandler_LogsIngestedOverflowLabel(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	// Enough distinct custom severities to use up the labelled series, then
+	// one literally named "other"
+	for i := 0; i <= 20; i++ {
+		createTestLog(t, db, "Custom", fmt.Sprintf("overflow-%d", i), "api")
+	}
+	createTestLog(t, db, "Custom", "other", "api")
+
+	if n := prometheusValue(t, `scribe_logs_ingested_total{severity="__other__"}`); n < 2 {
+		t.Errorf("expected the overflow series to count at least 2 logs, got %d", n)
+	}
+	rec := httptest.NewRecorder()
+	handlers.PrometheusMetricsHandler(func() (uint64, int64, uint64) { return 0, 0, 0 }, nil).
+		ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics/prometheus", nil))
+	if contains(rec.Body.String(), `scribe_logs_ingested_total{severity="other"}`) {
+		t.Error("expected overflow counted under the reserved __other__ label, not \"other\"")
+	}
+}
+
+func TestPrometheusOptions_Validate(t *testing.T) {
+	valid := []handlers.PrometheusOptions{
+		handlers.DefaultPrometheusOptions(),
+		{Prefix: ""},
+		{Prefix: "app:scribe_", Labels: map[string]string{"env": "", "_zone": "eu-1"}},
+	}
+	for _, opts := range valid {
+		if err := opts.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", opts, err)
+		}
+	}
+
+	invalid := []handlers.PrometheusOptions{
+		{Prefix: "1scribe_"},
+		{Prefix: "scribe-"},
+		{Prefix: "scribe_", Labels: map[string]string{"env-name": "prod"}},
+		{Prefix: "scribe_", Labels: map[string]string{"__name": "x"}},
+		{Prefix: "scribe_", Labels: map[string]string{"env": "\xff"}},
+	}
+	for _, opts := range invalid {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", opts)
+		}
+	}
+}
+
+func TestStreamLogs(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	var body bytes.Buffer
+	for i := 0; i < 250; i++ {
+		if i == 120 {
+			body.WriteString("{not valid json\n")
+		}
+		if i == 180 {
+			body.WriteString(`{"header":{"severity":"error"}}` + "\n")
+		}
+		fmt.Fprintf(&body, `{"header":{"title":"Streamed log %d","severity":"info"},"body":{"n":%d}}`+"\n", i, i)
+		if i == 200 {
+			body.WriteString("\n") // blank lines are ignored
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logs/stream", &body)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+
+	handlers.StreamLogs(db).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var summary handlers.StreamSummary
+	if err := json.NewDecoder(rec.Body).Decode(&summary); err != nil {
+		t.Fatalf("failed to decode summary: %v", err)
+	}
+
+	if summary.Received != 252 {
+		t.Errorf("expected 252 received lines, got %d", summary.Received)
+	}
+	if summary.Created != 250 {
+		t.Errorf("expected 250 created logs, got %d", summary.Created)
+	}
+	if summary.Failed != 2 {
+		t.Errorf("expected 2 failed lines, got %d", summary.Failed)
+	}
+	if len(summary.Errors) != 2 || summary.Errors[0].Index != 120 || summary.Errors[1].Index != 181 {
+		t.Errorf("expected errors at indexes 120 and 181, got %+v", summary.Errors)
+	}
+
+	count, _ := sqlite.NewLogRepository(db).Count()
+	if count != 250 {
+		t.Errorf("expected 250 logs in database, got %d", count)
+	}
+}
+
+func TestStreamLogs_ItemErrors(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	lines := []string{
+		`{"header":{"title":"Valid log"}}`,
+		`{"header":{"severity":"error"}}`,
+		`{"header":{"title":"Numeric severity","severity":5}}`,
+		`{"header":{"title":"Bad TTL"},"expires_in":"forever"}`,
+		`{"header":{"title":"Bad body"},"body":["not","an","object"]}`,
+		`{not valid json`,
+		`{"header":{"title":"` + strings.Repeat("x", 1<<20) + `"}}`,
+		`{"header":{"title":"Another valid log"}}`,
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/logs/stream", strings.NewReader(strings.Join(lines, "\n")+"\n"))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	handlers.StreamLogs(db).ServeHTTP(rec, req)
+
+	var summary handlers.StreamSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("failed to decode summary: %v", err)
+	}
+	if summary.Received != 8 || summary.Created != 2 || summary.Failed != 6 {
+		t.Errorf("expected 8 received, 2 created and 6 failed, got %+v", summary)
+	}
+
+	want := []struct {
+		index int
+		field string
+	}{
+		{1, "header.title"},
+		{2, "header.severity"},
+		{3, "expires_in"},
+		{4, "body"},
+		{5, ""},
+		{6, ""},
+	}
+	if len(summary.Errors) != len(want) {
+		t.Fatalf("expected %d errors, got %+v", len(want), summary.Errors)
+	}
+	for i, w := range want {
+		got := summary.Errors[i]
+		if got.Index != w.index || got.Field != w.field || got.Message == "" {
+			t.Errorf("error %d: expected index %d and field %q with a message, got %+v", i, w.index, w.field, got)
+		}
+	}
+
+	if msg := summary.Errors[1].Message; msg != "header.severity must be a string, got number" {
+		t.Errorf("unexpected severity error message: %q", msg)
+	}
+
+	// Errors serialize as {index, field, message}
+	var raw struct {
+		Errors []map[string]any `json:"errors"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &raw)
+	if len(raw.Errors) == 0 || raw.Errors[0]["index"] != float64(1) || raw.Errors[0]["field"] != "header.title" || raw.Errors[0]["message"] != "title is required" {
+		t.Errorf("unexpected error encoding: %+v", raw.Errors)
+	}
+}
+
+func TestStreamLogs_IngestPolicies(t *testing.T) {
+	lines := strings.Join([]string{
+		`{"header":{"title":"Crawl failed","severity":"error","source":"crawler"}}`,
+		`{"header":{"title":"Charge failed","severity":"error","source":"billing"}}`,
+	}, "\n") + "\n"
+
+	tests := []struct {
+		name       string
+		configure  func(opts *handlers.IngestOptions)
+		lines      string // appended to the common lines
+		wantCode   handlers.ErrorCode
+		wantFailed int
+		wantDrop   int
+		wantStored int
+	}{
+		{
+			name: "denied source rejected",
+			configure: func(opts *handlers.IngestOptions) {
+				opts.SourceFilter = handlers.NewSourceFilter(nil, []string{"crawler"}, false)
+			},
+			wantCode:   handlers.CodeSourceDenied,
+			wantFailed: 1,
+			wantStored: 1,
+		},
+		{
+			name: "denied source dropped",
+			configure: func(opts *handlers.IngestOptions) {
+				opts.SourceFilter = handlers.NewSourceFilter(nil, []string{"crawler"}, true)
+			},
+			wantDrop:   1,
+			wantStored: 1,
+		},
+		{
+			name: "rate limited per line",
+			configure: func(opts *handlers.IngestOptions) {
+				opts.SourceLimit = handlers.NewSourceLimiter(0.001, 1, false)
+			},
+			lines:      `{"header":{"title":"Crawl failed again","severity":"error","source":"crawler"}}` + "\n",
+			wantCode:   handlers.CodeRateLimited,
+			wantFailed: 1,
+			wantStored: 2,
+		},
+		{
+			name: "rate limited lines dropped",
+			configure: func(opts *handlers.IngestOptions) {
+				opts.SourceLimit = handlers.NewSourceLimiter(0.001, 1, true)
+			},
+			lines:      `{"header":{"title":"Crawl failed again","severity":"error","source":"crawler"}}` + "\n",
+			wantDrop:   1,
+			wantStored: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			defer db.Close()
+
+			opts := handlers.DefaultIngestOptions()
+			tt.configure(&opts)
+
+			body := lines + tt.lines
+			req := httptest.NewRequest(http.MethodPost, "/api/logs/stream", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-ndjson")
+			rec := httptest.NewRecorder()
+			handlers.StreamLogsWithOptions(db, nil, &opts).ServeHTTP(rec, req)
+
+			var summary handlers.StreamSummary
+			if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+				t.Fatalf("failed to decode summary: %v", err)
+			}
+			received := strings.Count(body, "\n")
+			if summary.Received != received || summary.Failed != tt.wantFailed || summary.Dropped != tt.wantDrop {
+				t.Errorf("expected %d failed and %d dropped of %d, got %+v", tt.wantFailed, tt.wantDrop, received, summary)
+			}
+			if tt.wantFailed > 0 && (len(summary.Errors) != 1 || summary.Errors[0].Code != tt.wantCode) {
+				t.Errorf("expected one %s line reported, got %+v", tt.wantCode, summary.Errors)
+			}
+			if count, _ := sqlite.NewLogRepository(db).Count(); count != tt.wantStored {
+				t.Errorf("expected %d stored logs, got %d", tt.wantStored, count)
+			}
+		})
+	}
+}
+
+func TestStreamLogs_InsertsAsLinesArrive(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	pr, pw := io.Pipe()
+	req := httptest.NewRequest(http.MethodPost, "/api/logs/stream", pr)
+	rec := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		handlers.StreamLogs(db).ServeHTTP(rec, req)
+		close(done)
+	}()
+
+	_, _ = pw.Write([]byte(`{"header":{"title":"First streamed log"}}` + "\n"))
+
+	// The log should land before the stream is closed
+	repo := sqlite.NewLogRepository(db)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if count, _ := repo.Count(); count == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected log to be inserted while the stream is still open")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	_, _ = pw.Write([]byte(`{"header":{"title":"Second streamed log"}}` + "\n"))
+	_ = pw.Close()
+	<-done
+
+	var summary handlers.StreamSummary
+	_ = json.NewDecoder(rec.Body).Decode(&summary)
+	if summary.Created != 2 {
+		t.Errorf("expected 2 created logs, got %d", summary.Created)
+	}
+}
+
+// importEvents parses the SSE frames written by ImportLogs.
+func importEvents(t *testing.T, body string) []handlers.SSEEvent {
+	t.Helper()
+	var events []handlers.SSEEvent
+	for _, frame := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		for _, line := range strings.Split(frame, "\n") {
+			data, ok := strings.CutPrefix(line, "data: ")
+			if !ok {
+				continue
+			}
+			var event handlers.SSEEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				t.Fatalf("invalid SSE data %q: %v", data, err)
+			}
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func TestImportLogs(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	var body bytes.Buffer
+	for i := 0; i < 1200; i++ {
+		if i == 700 {
+			body.WriteString("{not valid json\n")
+		}
+		fmt.Fprintf(&body, `{"header":{"title":"Imported log %d"},"body":{"n":%d}}`+"\n", i, i)
+	}
+	body.WriteString(`{"header":{"severity":"error"}}` + "\n")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/import", &body)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+
+	handlers.ImportLogs(db).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected text/event-stream, got %q", ct)
+	}
+
+	events := importEvents(t, rec.Body.String())
+	if len(events) != 4 {
+		t.Fatalf("expected 3 progress events and a summary, got %d: %s", len(events), rec.Body.String())
+	}
+
+	wantImported := []int{500, 1000, 1200}
+	for i, want := range wantImported {
+		event := events[i]
+		data, _ := json.Marshal(event.Data)
+		var progress handlers.ImportProgress
+		_ = json.Unmarshal(data, &progress)
+		if event.Type != "progress" || progress.Imported != want {
+			t.Errorf("event %d: expected progress with imported=%d, got %s %s", i, want, event.Type, data)
+		}
+		if progress.TotalRead != progress.Imported+progress.Skipped {
+			t.Errorf("event %d: expected total_read to equal imported+skipped, got %s", i, data)
+		}
+	}
+
+	last := events[len(events)-1]
+	data, _ := json.Marshal(last.Data)
+	var summary handlers.ImportSummary
+	_ = json.Unmarshal(data, &summary)
+	if last.Type != "summary" {
+		t.Fatalf("expected final summary event, got %q", last.Type)
+	}
+	if summary.Imported != 1200 || summary.Skipped != 2 || summary.TotalRead != 1202 {
+		t.Errorf("unexpected summary: %s", data)
+	}
+	if len(summary.Errors) != 2 || summary.Errors[0].Line != 701 || summary.Errors[1].Line != 1202 {
+		t.Errorf("expected errors on lines 701 and 1202, got %+v", summary.Errors)
+	}
+
+	count, _ := sqlite.NewLogRepository(db).Count()
+	if count != summary.Imported {
+		t.Errorf("expected %d logs in database, got %d", summary.Imported, count)
+	}
+}
+
+func TestImportLogs_ClientDisconnect(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pr, pw := io.Pipe()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/import", pr).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		handlers.ImportLogs(db).ServeHTTP(rec, req)
+		close(done)
+	}()
+
+	for i := 0; i < 10; i++ {
+		_, _ = fmt.Fprintf(pw, `{"header":{"title":"Log %d"}}`+"\n", i)
+	}
+
+	// A disconnecting client cancels the context and closes the body
+	cancel()
+	_ = pw.CloseWithError(context.Canceled)
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected import to stop after the client disconnected")
+	}
+
+	for _, event := range importEvents(t, rec.Body.String()) {
+		if event.Type == "summary" {
+			t.Error("expected no summary after the client disconnected")
+		}
+	}
+	if count, _ := sqlite.NewLogRepository(db).Count(); count != 0 {
+		t.Errorf("expected the unfinished batch not to be inserted, got %d logs", count)
+	}
+}
+
+func reanalyze(t *testing.T, db *sqlite.Database, opts *handlers.IngestOptions, body string) handlers.ReanalyzeSummary {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/reanalyze", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handlers.ReanalyzeLogsWithOptions(db, nil, opts, nil).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	events := importEvents(t, rec.Body.String())
+	if len(events) == 0 || events[len(events)-1].Type != "summary" {
+		t.Fatalf("expected a final summary event, got %s", rec.Body.String())
+	}
+	data, _ := json.Marshal(events[len(events)-1].Data)
+	var summary handlers.ReanalyzeSummary
+	_ = json.Unmarshal(data, &summary)
+	return summary
+}
+
+func TestReanalyzeLogs(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	notFound := createTestLog(t, db, "API returned 404 not found", "info", "")
+	createTestLog(t, db, "Request returned status 200", "info", "")
+
+	repo := sqlite.NewLogRepository(db)
+	derivedSeverity := func() string {
+		log, err := repo.FindByID(notFound)
+		if err != nil {
+			t.Fatalf("FindByID() error = %v", err)
+		}
+		return log.Metadata.DerivedSeverity
+	}
+	if got := derivedSeverity(); got != "warning" {
+		t.Fatalf("expected 404 to be derived as warning by default, got %q", got)
+	}
+
+	// Tighten the rule, as a restart with a new config would
+	matcher, err := services.NewPatternMatcherWithStatusSeverity(map[string]string{"404": "error"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opts := handlers.DefaultIngestOptions()
+	opts.PatternMatcher = matcher
+
+	summary := reanalyze(t, db, &opts, `{"dry_run":true}`)
+	if !summary.DryRun || summary.Scanned != 2 || summary.Changed != 1 || summary.Total != 2 {
+		t.Errorf("unexpected dry run summary: %+v", summary)
+	}
+	if got := derivedSeverity(); got != "warning" {
+		t.Errorf("expected dry run to leave the log unchanged, got %q", got)
+	}
+
+	summary = reanalyze(t, db, &opts, "")
+	if summary.DryRun || summary.Changed != 1 || summary.Error != "" {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if got := derivedSeverity(); got != "error" {
+		t.Errorf("expected reanalysis to derive error, got %q", got)
+	}
+
+	// Default rules restore the original classification
+	if summary := reanalyze(t, db, nil, ""); summary.Changed != 1 {
+		t.Errorf("expected 1 change back to the default rules, got %+v", summary)
+	}
+	if got := derivedSeverity(); got != "warning" {
+		t.Errorf("expected default rules to derive warning, got %q", got)
+	}
+}
+
+func TestDeleteLog(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	// Create a test log
+	id := createTestLog(t, db, "Log to delete", "info", "test")
+
+	router := chi.NewRouter()
+	router.Delete("/api/logs/{id}", handlers.DeleteLog(db))
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/logs/1", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Verify log is deleted
+	router2 := chi.NewRouter()
+	router2.Get("/api/logs/{id}", handlers.GetLog(db))
+
+	req2 := httptest.NewRequest(http.MethodGet, "/api/logs/1", nil)
+	rec2 := httptest.NewRecorder()
+	router2.ServeHTTP(rec2, req2)
+
+	if rec2.Code != http.StatusNotFound {
+		t.Errorf("expected deleted log to return 404, got %d", rec2.Code)
+	}
+
+	_ = id // Suppress unused variable warning
+}
+
+func TestDeleteLog_NotFound(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	router := chi.NewRouter()
+	router.Delete("/api/logs/{id}", handlers.DeleteLog(db))
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/logs/99999", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestDeleteLog_AlreadyDeleted(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	id := createTestLog(t, db, "Delete me once", "info", "test")
+
+	router := chi.NewRouter()
+	router.Delete("/api/logs/{id}", handlers.DeleteLog(db))
+
+	for i, want := range []int{http.StatusNoContent, http.StatusNotFound} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/logs/%d", id), nil))
+		if rec.Code != want {
+			t.Errorf("delete %d: expected status %d, got %d: %s", i+1, want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestDeleteLogs_Bulk(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	// Create test logs
+	createTestLog(t, db, "Log 1", "info", "test")
+	createTestLog(t, db, "Log 2", "info", "test")
+	createTestLog(t, db, "Log 3", "info", "test")
+
+	body := `{"ids": [1, 2]}`
+	req := httptest.NewRequest(http.MethodDelete, "/api/logs", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler := handlers.DeleteLogs(db)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp struct {
+		Deleted int `json:"deleted"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+
+	if resp.Deleted != 2 {
+		t.Errorf("expected 2 deleted, got %d", resp.Deleted)
+	}
+}
+
+func TestDeleteLogs_EmptyIDs(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	body := `{"ids": []}`
+	req := httptest.NewRequest(http.MethodDelete, "/api/logs", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler := handlers.DeleteLogs(db)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestDeleteLogs_InvalidBody(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	body := `{invalid json}`
+	req := httptest.NewRequest(http.MethodDelete, "/api/logs", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler := handlers.DeleteLogs(db)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestDeleteLog_InvalidID(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	router := chi.NewRouter()
+	router.Delete("/api/logs/{id}", handlers.DeleteLog(db))
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/logs/invalid", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestPinLog_PinAndUnpin(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	id := createTestLog(t, db, "Incident start", "error", "api")
+
+	router := chi.NewRouter()
+	router.Post("/api/logs/{id}/pin", handlers.PinLog(db))
+	router.Delete("/api/logs/{id}/pin", handlers.UnpinLog(db))
+
+	path := fmt.Sprintf("/api/logs/%d/pin", id)
+	for _, tt := range []struct {
+		method string
+		want   bool
+	}{
+		{http.MethodPost, true},
+		{http.MethodDelete, false},
+	} {
+		req := httptest.NewRequest(tt.method, path, nil)
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", tt.method, rec.Code, rec.Body.String())
+		}
+
+		var resp handlers.LogResponse
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		if resp.ID != id || resp.Pinned != tt.want {
+			t.Errorf("%s: expected log %d pinned=%v, got id=%d pinned=%v", tt.method, id, tt.want, resp.ID, resp.Pinned)
+		}
+	}
+}
+
+func TestPinLog_Errors(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	router := chi.NewRouter()
+	router.Post("/api/logs/{id}/pin", handlers.PinLog(db))
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/api/logs/99999/pin", http.StatusNotFound},
+		{"/api/logs/invalid/pin", http.StatusBadRequest},
+		{"/api/logs/99999/pin?tz=Mars/Olympus", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+		rec := httptest.NewRecorder()
 
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
-}
+		router.ServeHTTP(rec, req)
 
-func containsHelper(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.wantStatus, rec.Code)
 		}
 	}
-	return false
 }
 
-// Mock filesystem for SPA handler tests
-func mockFS() fs.FS {
-	return fstest.MapFS{
-		"dist/index.html": &fstest.MapFile{
-			Data: []byte("<!DOCTYPE html><html><body>SPA</body></html>"),
-		},
-		"dist/assets/app.js": &fstest.MapFile{
-			Data: []byte("console.log('app');"),
-		},
-		"dist/assets/style.css": &fstest.MapFile{
-			Data: []byte("body { color: black; }"),
-		},
-		"dist/favicon.ico": &fstest.MapFile{
-			Data: []byte("icon-data"),
-		},
-	}
-}
+func TestListLogs_PinnedFilter(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
 
-func TestSPAHandler_ServeIndex(t *testing.T) {
-	handler := handlers.NewSPAHandler(mockFS(), "dist")
+	pinnedID := createTestLog(t, db, "Pinned log", "info", "api")
+	createTestLog(t, db, "Other log", "info", "api")
 
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	rec := httptest.NewRecorder()
+	router := chi.NewRouter()
+	router.Post("/api/logs/{id}/pin", handlers.PinLog(db))
+	router.Get("/api/logs", handlers.ListLogs(db))
 
-	handler.ServeHTTP(rec, req)
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/logs/%d/pin", pinnedID), nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
 
-	if rec.Code != http.StatusOK {
-		t.Errorf("expected status 200, got %d", rec.Code)
+	tests := []struct {
+		query     string
+		wantTotal int
+	}{
+		{"?pinned=true", 1},
+		{"?pinned=false", 1},
+		{"", 2},
 	}
 
-	contentType := rec.Header().Get("Content-Type")
-	if contentType != "text/html; charset=utf-8" {
-		t.Errorf("expected Content-Type 'text/html; charset=utf-8', got '%s'", contentType)
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/logs"+tt.query, nil)
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		var resp handlers.ListLogsResponse
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		if resp.Total != tt.wantTotal {
+			t.Errorf("%q: expected total %d, got %d", tt.query, tt.wantTotal, resp.Total)
+		}
+		if tt.query == "?pinned=true" && (len(resp.Logs) != 1 || resp.Logs[0].ID != pinnedID) {
+			t.Errorf("expected only log %d, got %+v", pinnedID, resp.Logs)
+		}
 	}
 
-	// Should have no-cache headers for index.html
-	cacheControl := rec.Header().Get("Cache-Control")
-	if cacheControl != "no-cache, no-store, must-revalidate" {
-		t.Errorf("expected no-cache for index.html, got '%s'", cacheControl)
+	req = httptest.NewRequest(http.MethodGet, "/api/logs?pinned=maybe", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for invalid pinned value, got %d", rec.Code)
 	}
 }
 
-func TestSPAHandler_ServeAsset(t *testing.T) {
-	handler := handlers.NewSPAHandler(mockFS(), "dist")
+func TestListLogs_IncludeBody(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "Log with body", "info", "api")
+
+	handler := handlers.ListLogs(db)
 
 	tests := []struct {
-		name        string
-		path        string
-		wantContent string
-		wantType    string
-		wantCache   string
+		query    string
+		wantBody bool
 	}{
-		{
-			name:        "serve JavaScript asset",
-			path:        "/assets/app.js",
-			wantContent: "console.log('app');",
-			wantType:    "application/javascript; charset=utf-8",
-			wantCache:   "public, max-age=31536000, immutable",
-		},
-		{
-			name:        "serve CSS asset",
-			path:        "/assets/style.css",
-			wantContent: "body { color: black; }",
-			wantType:    "text/css; charset=utf-8",
-			wantCache:   "public, max-age=31536000, immutable",
-		},
-		{
-			name:        "serve favicon",
-			path:        "/favicon.ico",
-			wantContent: "icon-data",
-			wantType:    "image/x-icon",
-			wantCache:   "", // No special caching for favicon
-		},
+		{"", true},
+		{"?include_body=true", true},
+		{"?include_body=false", false},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
-			rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/logs"+tt.query, nil)
+		rec := httptest.NewRecorder()
 
-			handler.ServeHTTP(rec, req)
+		handler.ServeHTTP(rec, req)
 
-			if rec.Code != http.StatusOK {
-				t.Errorf("expected status 200, got %d", rec.Code)
-			}
+		var resp handlers.ListLogsResponse
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		if len(resp.Logs) != 1 {
+			t.Fatalf("%q: expected 1 log, got %d", tt.query, len(resp.Logs))
+		}
+		if got := resp.Logs[0].Body["test"] == true; got != tt.wantBody {
+			t.Errorf("%q: expected body present=%v, got %v", tt.query, tt.wantBody, resp.Logs[0].Body)
+		}
+	}
 
-			contentType := rec.Header().Get("Content-Type")
-			if contentType != tt.wantType {
-				t.Errorf("expected Content-Type '%s', got '%s'", tt.wantType, contentType)
-			}
+	req := httptest.NewRequest(http.MethodGet, "/api/logs?include_body=nope", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for invalid include_body value, got %d", rec.Code)
+	}
+}
 
-			cacheControl := rec.Header().Get("Cache-Control")
-			if cacheControl != tt.wantCache {
-				t.Errorf("expected Cache-Control '%s', got '%s'", tt.wantCache, cacheControl)
-			}
+func TestListLogIDs_MatchesList(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
 
-			body := rec.Body.String()
-			if body != tt.wantContent {
-				t.Errorf("expected body '%s', got '%s'", tt.wantContent, body)
-			}
-		})
+	for i := 0; i < 5; i++ {
+		createTestLog(t, db, fmt.Sprintf("Checkout failed %d", i), "error", "shop")
+		createTestLog(t, db, fmt.Sprintf("Checkout started %d", i), "info", "shop")
+		createTestLog(t, db, fmt.Sprintf("Token refreshed %d", i), "info", "auth")
 	}
-}
 
-func TestSPAHandler_SPAFallback(t *testing.T) {
-	handler := handlers.NewSPAHandler(mockFS(), "dist")
+	pagination := queries.DefaultPagination()
+	pagination.IDs = queries.PageSize{Default: 4, Max: 6}
 
-	// Request a path that doesn't exist - should serve index.html for SPA routing
-	tests := []string{
-		"/dashboard",
-		"/logs/123",
-		"/settings/profile",
-		"/nonexistent/path/here",
+	router := chi.NewRouter()
+	router.Get("/api/logs", handlers.ListLogs(db))
+	router.Get("/api/logs/ids", handlers.ListLogIDsWithPagination(db, &pagination))
+
+	get := func(path string, v any) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+		if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", path, err)
+		}
 	}
 
-	for _, path := range tests {
-		t.Run(path, func(t *testing.T) {
-			req := httptest.NewRequest(http.MethodGet, path, nil)
-			rec := httptest.NewRecorder()
+	tests := []struct {
+		filters    string
+		wantCount  int
+		wantTotal  int
+		wantOffset int
+	}{
+		{"severity=error", 4, 5, 0},
+		{"source=shop&limit=10", 6, 10, 0},
+		{"source=shop&limit=6&page=2", 4, 10, 6},
+		{"search=token&limit=100", 5, 5, 0},
+		{"min_severity=error&source=auth", 0, 0, 0},
+	}
 
-			handler.ServeHTTP(rec, req)
+	for _, tt := range tests {
+		var ids handlers.LogIDsResponse
+		get("/api/logs/ids?"+tt.filters, &ids)
 
-			if rec.Code != http.StatusOK {
-				t.Errorf("expected status 200 for SPA fallback, got %d", rec.Code)
-			}
+		if len(ids.IDs) != tt.wantCount || ids.Total != tt.wantTotal {
+			t.Errorf("%s: expected %d ids of %d, got %d of %d", tt.filters, tt.wantCount, tt.wantTotal, len(ids.IDs), ids.Total)
+		}
+		if ids.IDs == nil {
+			t.Errorf("%s: expected an empty array rather than null", tt.filters)
+		}
 
-			contentType := rec.Header().Get("Content-Type")
-			if contentType != "text/html; charset=utf-8" {
-				t.Errorf("expected Content-Type 'text/html; charset=utf-8' for SPA fallback, got '%s'", contentType)
+		// The full list under the same filters holds the same logs, in order
+		query, _ := url.ParseQuery(tt.filters)
+		query.Del("page")
+		query.Set("limit", "100")
+		var list handlers.ListLogsResponse
+		get("/api/logs?"+query.Encode(), &list)
+		if list.Total != ids.Total {
+			t.Errorf("%s: list total %d differs from ids total %d", tt.filters, list.Total, ids.Total)
+		}
+		for i, id := range ids.IDs {
+			if j := tt.wantOffset + i; j >= len(list.Logs) || list.Logs[j].ID != id {
+				t.Errorf("%s: id %d at %d does not match the list", tt.filters, id, i)
 			}
+		}
+	}
 
-			body := rec.Body.String()
-			if !contains(body, "SPA") {
-				t.Error("expected index.html content for SPA fallback")
-			}
-		})
+	req := httptest.NewRequest(http.MethodGet, "/api/logs/ids?min_severity=loud", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid filter, got %d", rec.Code)
 	}
 }
 
-func TestSPAHandler_PathCleaning(t *testing.T) {
-	handler := handlers.NewSPAHandler(mockFS(), "dist")
+func TestListLogs_IncludeMetadata(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	id := createTestLog(t, db, "POST /api/orders returned HTTP 500", "info", "checkout")
+
+	router := chi.NewRouter()
+	router.Get("/api/logs", handlers.ListLogs(db))
+	router.Get("/api/logs/{id}", handlers.GetLog(db))
+
+	get := func(path string) map[string]any {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+		var resp map[string]any
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		if logs, ok := resp["logs"].([]any); ok {
+			if len(logs) != 1 {
+				t.Fatalf("%s: expected 1 log, got %d", path, len(logs))
+			}
+			return logs[0].(map[string]any)
+		}
+		return resp
+	}
 
 	tests := []struct {
-		name string
-		path string
+		path         string
+		wantMetadata bool
 	}{
-		{"path with double slash", "//assets//app.js"},
-		{"path with dot segments", "/assets/../assets/app.js"},
+		{"/api/logs", false},
+		{"/api/logs?include_metadata=false", false},
+		{"/api/logs?include_metadata=true", true},
+		{fmt.Sprintf("/api/logs?ids=%d", id), false},
+		{fmt.Sprintf("/api/logs?ids=%d&include_metadata=true", id), true},
+		{fmt.Sprintf("/api/logs/%d", id), true},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
-			rec := httptest.NewRecorder()
-
-			handler.ServeHTTP(rec, req)
+		log := get(tt.path)
+		metadata, ok := log["metadata"].(map[string]any)
+		if ok != tt.wantMetadata {
+			t.Errorf("%s: expected metadata present=%v, got %v", tt.path, tt.wantMetadata, log["metadata"])
+			continue
+		}
+		if ok && metadata["derived_category"] != "http" {
+			t.Errorf("%s: expected derived_category http, got %v", tt.path, metadata["derived_category"])
+		}
+	}
 
-			// All paths should either serve the file or fall back to index.html
-			if rec.Code != http.StatusOK {
-				t.Errorf("expected status 200, got %d for path %s", rec.Code, tt.path)
-			}
-		})
+	req := httptest.NewRequest(http.MethodGet, "/api/logs?include_metadata=nope", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for invalid include_metadata value, got %d", rec.Code)
 	}
 }
 
-func TestSPAHandler_ContentTypes(t *testing.T) {
-	// Test getContentType for various file types
-	mockFSWithTypes := fstest.MapFS{
-		"dist/index.html":  &fstest.MapFile{Data: []byte("html")},
-		"dist/image.png":   &fstest.MapFile{Data: []byte("png")},
-		"dist/image.jpg":   &fstest.MapFile{Data: []byte("jpg")},
-		"dist/image.gif":   &fstest.MapFile{Data: []byte("gif")},
-		"dist/icon.svg":    &fstest.MapFile{Data: []byte("svg")},
-		"dist/data.json":   &fstest.MapFile{Data: []byte("{}")},
-		"dist/font.woff":   &fstest.MapFile{Data: []byte("woff")},
-		"dist/font.woff2":  &fstest.MapFile{Data: []byte("woff2")},
-		"dist/font.ttf":    &fstest.MapFile{Data: []byte("ttf")},
-		"dist/font.eot":    &fstest.MapFile{Data: []byte("eot")},
-		"dist/unknown.xyz": &fstest.MapFile{Data: []byte("unknown")},
-	}
+func TestListLogs_Highlight(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
 
-	handler := handlers.NewSPAHandler(mockFSWithTypes, "dist")
+	longTitle := strings.Repeat("lorem ipsum ", 20) + "Payment TIMEOUT on checkout" + strings.Repeat(" dolor sit", 20)
+	createTestLog(t, db, longTitle, "error", "api")
 
-	tests := []struct {
-		path     string
-		wantType string
-	}{
-		{"/image.png", "image/png"},
-		{"/image.jpg", "image/jpeg"},
-		{"/image.gif", "image/gif"},
-		{"/icon.svg", "image/svg+xml"},
-		{"/data.json", "application/json; charset=utf-8"},
-		{"/font.woff", "font/woff"},
-		{"/font.woff2", "font/woff2"},
-		{"/font.ttf", "font/ttf"},
-		{"/font.eot", "application/vnd.ms-fontobject"},
-		{"/unknown.xyz", "application/octet-stream"},
+	body := `{"header":{"title":"Worker crashed"},"body":{"reason":"upstream timeout after 30s"}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(body))
+	handlers.CreateLog(db).ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/logs?search=timeout&highlight=true", nil)
+	rec := httptest.NewRecorder()
+	handlers.ListLogs(db).ServeHTTP(rec, req)
+
+	var resp handlers.ListLogsResponse
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	if len(resp.Logs) != 2 {
+		t.Fatalf("expected 2 matching logs, got %d", len(resp.Logs))
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.path, func(t *testing.T) {
-			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
-			rec := httptest.NewRecorder()
+	for _, log := range resp.Logs {
+		snippet := log.MatchSnippet
+		if !strings.Contains(strings.ToLower(snippet), "<mark>timeout</mark>") {
+			t.Errorf("log %q: expected marked term in snippet, got %q", log.Header.Title, snippet)
+		}
+		if n := utf8.RuneCountInString(snippet); n > 2*40+len("timeout")+len("<mark></mark>")+2 {
+			t.Errorf("log %q: snippet too long (%d runes): %q", log.Header.Title, n, snippet)
+		}
+	}
 
-			handler.ServeHTTP(rec, req)
+	// Without highlight, no snippet is returned
+	req = httptest.NewRequest(http.MethodGet, "/api/logs?search=timeout", nil)
+	rec = httptest.NewRecorder()
+	handlers.ListLogs(db).ServeHTTP(rec, req)
 
-			if rec.Code != http.StatusOK {
-				t.Fatalf("expected status 200, got %d", rec.Code)
-			}
+	resp = handlers.ListLogsResponse{}
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	for _, log := range resp.Logs {
+		if log.MatchSnippet != "" {
+			t.Errorf("expected no snippet without highlight, got %q", log.MatchSnippet)
+		}
+	}
 
-			contentType := rec.Header().Get("Content-Type")
-			if contentType != tt.wantType {
-				t.Errorf("expected Content-Type '%s', got '%s'", tt.wantType, contentType)
+	// Log text is escaped so only the <mark> tags are markup
+	createTestLog(t, db, `<script>alert("x")</script> timeout & retry`, "error", "api")
+	req = httptest.NewRequest(http.MethodGet, "/api/logs?search=timeout&highlight=true", nil)
+	rec = httptest.NewRecorder()
+	handlers.ListLogs(db).ServeHTTP(rec, req)
+
+	resp = handlers.ListLogsResponse{}
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	want := "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; <mark>timeout</mark> &amp; retry"
+	found := false
+	for _, log := range resp.Logs {
+		if strings.HasPrefix(log.Header.Title, "<script>") {
+			found = true
+			if log.MatchSnippet != want {
+				t.Errorf("expected escaped snippet %q, got %q", want, log.MatchSnippet)
 			}
-		})
+		}
+	}
+	if !found {
+		t.Error("expected the <script> log among the matches")
 	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/logs?search=timeout&highlight=maybe", nil)
+	rec = httptest.NewRecorder()
+	handlers.ListLogs(db).ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for invalid highlight value, got %d", rec.Code)
+	}
+}
+
+func annotationRouter(db *sqlite.Database) *chi.Mux {
+	router := chi.NewRouter()
+	router.Get("/api/logs/{id}", handlers.GetLog(db))
+	router.Delete("/api/logs/{id}", handlers.DeleteLog(db))
+	router.Get("/api/logs/{id}/annotations", handlers.ListAnnotations(db))
+	router.Post("/api/logs/{id}/annotations", handlers.CreateAnnotation(db))
+	return router
 }
 
-func TestMetricsHandler(t *testing.T) {
-	getMetrics := func() (uint64, int64, uint64) {
-		return 100, 5, 3 // totalRequests, activeRequests, totalErrors
-	}
+func TestAnnotations_AddAndList(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
 
-	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
-	rec := httptest.NewRecorder()
+	id := createTestLog(t, db, "Checkout timeout", "error", "shop")
+	router := annotationRouter(db)
+	path := fmt.Sprintf("/api/logs/%d/annotations", id)
 
-	handler := handlers.MetricsHandler(getMetrics, nil)
-	handler.ServeHTTP(rec, req)
+	for _, body := range []string{
+		`{"author": "alex", "text": "known issue, see JIRA-123"}`,
+		`{"text": "  deployed hotfix  "}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader([]byte(body)))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusOK {
-		t.Errorf("expected status 200, got %d", rec.Code)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+		}
 	}
 
-	contentType := rec.Header().Get("Content-Type")
-	if contentType != "application/json" {
-		t.Errorf("expected Content-Type 'application/json', got '%s'", contentType)
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
 	}
 
 	var resp struct {
-		TotalRequests  uint64 `json:"total_requests"`
-		ActiveRequests int64  `json:"active_requests"`
-		TotalErrors    uint64 `json:"total_errors"`
-		ErrorRate      string `json:"error_rate"`
-		Uptime         string `json:"uptime"`
-		GoRoutines     int    `json:"go_routines"`
-		MemoryMB       uint64 `json:"memory_mb"`
+		Annotations []handlers.AnnotationResponse `json:"annotations"`
+		Total       int                           `json:"total"`
 	}
 	_ = json.NewDecoder(rec.Body).Decode(&resp)
 
-	if resp.TotalRequests != 100 {
-		t.Errorf("expected 100 total requests, got %d", resp.TotalRequests)
-	}
-	if resp.ActiveRequests != 5 {
-		t.Errorf("expected 5 active requests, got %d", resp.ActiveRequests)
+	if resp.Total != 2 || len(resp.Annotations) != 2 {
+		t.Fatalf("expected 2 annotations, got %d", resp.Total)
 	}
-	if resp.TotalErrors != 3 {
-		t.Errorf("expected 3 total errors, got %d", resp.TotalErrors)
+	if resp.Annotations[0].Author != "alex" || resp.Annotations[0].LogID != id {
+		t.Errorf("unexpected first annotation: %+v", resp.Annotations[0])
 	}
-	if resp.ErrorRate != "3.000%" {
-		t.Errorf("expected error rate '3.000%%', got '%s'", resp.ErrorRate)
+	if resp.Annotations[1].Text != "deployed hotfix" {
+		t.Errorf("expected trimmed text, got %q", resp.Annotations[1].Text)
 	}
-	if resp.GoRoutines <= 0 {
-		t.Error("expected go_routines > 0")
+
+	// The log response carries the annotation count
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/logs/%d", id), nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var log handlers.LogResponse
+	_ = json.NewDecoder(rec.Body).Decode(&log)
+	if log.AnnotationCount != 2 {
+		t.Errorf("expected annotation_count 2, got %d", log.AnnotationCount)
 	}
 }
 
-func TestMetricsHandler_WithSSE(t *testing.T) {
-	getMetrics := func() (uint64, int64, uint64) {
-		return 50, 2, 1
-	}
+func TestAnnotations_Validation(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
 
-	hub := handlers.NewSSEHub()
+	id := createTestLog(t, db, "Some log", "info", "api")
+	router := annotationRouter(db)
 
-	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
-	rec := httptest.NewRecorder()
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"missing text", http.MethodPost, fmt.Sprintf("/api/logs/%d/annotations", id), `{"author": "alex"}`, http.StatusBadRequest},
+		{"invalid body", http.MethodPost, fmt.Sprintf("/api/logs/%d/annotations", id), `{invalid`, http.StatusBadRequest},
+		{"unknown log", http.MethodPost, "/api/logs/99999/annotations", `{"text": "hi"}`, http.StatusNotFound},
+		{"unknown log list", http.MethodGet, "/api/logs/99999/annotations", "", http.StatusNotFound},
+		{"invalid id", http.MethodGet, "/api/logs/abc/annotations", "", http.StatusBadRequest},
+	}
 
-	handler := handlers.MetricsHandler(getMetrics, hub)
-	handler.ServeHTTP(rec, req)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewReader([]byte(tt.body)))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusOK {
-		t.Errorf("expected status 200, got %d", rec.Code)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
 	}
+}
 
-	var resp struct {
-		SSEClients int `json:"sse_clients"`
+func TestAnnotations_CascadeDelete(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	id := createTestLog(t, db, "Short-lived log", "info", "api")
+	router := annotationRouter(db)
+	path := fmt.Sprintf("/api/logs/%d/annotations", id)
+
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader([]byte(`{"text": "note"}`)))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/logs/%d", id), nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", rec.Code)
 	}
-	_ = json.NewDecoder(rec.Body).Decode(&resp)
 
-	// SSEClients should be 0 since no clients are connected
-	if resp.SSEClients != 0 {
-		t.Errorf("expected 0 SSE clients, got %d", resp.SSEClients)
+	annotations, err := sqlite.NewAnnotationRepository(db).FindByLogID(id)
+	if err != nil {
+		t.Fatalf("failed to list annotations: %v", err)
+	}
+	if len(annotations) != 0 {
+		t.Errorf("expected annotations to be deleted with the log, got %d", len(annotations))
 	}
 }
 
-func TestPrometheusMetricsHandler(t *testing.T) {
-	getMetrics := func() (uint64, int64, uint64) {
-		return 200, 10, 5
-	}
+func TestGetFacets_MultipleFields(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
 
-	req := httptest.NewRequest(http.MethodGet, "/metrics/prometheus", nil)
+	createTestLog(t, db, "Charge declined", "error", "payments")
+	createTestLog(t, db, "Charge retried", "warning", "payments")
+	createTestLog(t, db, "User signup", "info", "auth")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/facets?fields=source,severity", nil)
 	rec := httptest.NewRecorder()
 
-	handler := handlers.PrometheusMetricsHandler(getMetrics, nil)
-	handler.ServeHTTP(rec, req)
+	handlers.GetFacets(db).ServeHTTP(rec, req)
 
 	if rec.Code != http.StatusOK {
-		t.Errorf("expected status 200, got %d", rec.Code)
-	}
-
-	contentType := rec.Header().Get("Content-Type")
-	if contentType != "text/plain; charset=utf-8" {
-		t.Errorf("expected Content-Type 'text/plain; charset=utf-8', got '%s'", contentType)
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
 	}
 
-	body := rec.Body.String()
-
-	// Check for required Prometheus metrics
-	expectedMetrics := []string{
-		"scribe_http_requests_total 200",
-		"scribe_http_requests_active 10",
-		"scribe_http_errors_total 5",
-		"scribe_uptime_seconds",
-		"scribe_goroutines",
-		"scribe_memory_bytes",
-		"scribe_sse_clients",
-	}
+	var resp map[string][]sqlite.FacetValue
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
 
-	for _, metric := range expectedMetrics {
-		if !contains(body, metric) {
-			t.Errorf("expected Prometheus output to contain '%s'", metric)
-		}
+	if len(resp) != 2 {
+		t.Errorf("expected 2 fields, got %d", len(resp))
 	}
-
-	// Check for HELP and TYPE comments
-	if !contains(body, "# HELP scribe_http_requests_total") {
-		t.Error("expected HELP comment for requests_total")
+	if sources := resp["source"]; len(sources) != 2 || sources[0] != (sqlite.FacetValue{Value: "payments", Count: 2}) {
+		t.Errorf("unexpected source facet: %v", sources)
 	}
-	if !contains(body, "# TYPE scribe_http_requests_total counter") {
-		t.Error("expected TYPE comment for requests_total")
+	if severities := resp["severity"]; len(severities) != 3 {
+		t.Errorf("expected 3 severities, got %v", severities)
 	}
 }
 
-func TestDeleteLog(t *testing.T) {
+func TestGetFacets_DefaultsToAllFields(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
 
-	// Create a test log
-	id := createTestLog(t, db, "Log to delete", "info", "test")
-
-	router := chi.NewRouter()
-	router.Delete("/api/logs/{id}", handlers.DeleteLog(db))
-
-	req := httptest.NewRequest(http.MethodDelete, "/api/logs/1", nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/facets", nil)
 	rec := httptest.NewRecorder()
 
-	router.ServeHTTP(rec, req)
-
-	if rec.Code != http.StatusNoContent {
-		t.Errorf("expected status 204, got %d: %s", rec.Code, rec.Body.String())
-	}
-
-	// Verify log is deleted
-	router2 := chi.NewRouter()
-	router2.Get("/api/logs/{id}", handlers.GetLog(db))
+	handlers.GetFacets(db).ServeHTTP(rec, req)
 
-	req2 := httptest.NewRequest(http.MethodGet, "/api/logs/1", nil)
-	rec2 := httptest.NewRecorder()
-	router2.ServeHTTP(rec2, req2)
+	var resp map[string][]sqlite.FacetValue
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
 
-	if rec2.Code != http.StatusNotFound {
-		t.Errorf("expected deleted log to return 404, got %d", rec2.Code)
+	for _, field := range sqlite.FacetFields() {
+		values, ok := resp[field]
+		if !ok {
+			t.Errorf("expected field %s in response", field)
+		}
+		if values == nil {
+			t.Errorf("expected empty list for %s, got null", field)
+		}
 	}
-
-	_ = id // Suppress unused variable warning
 }
 
-func TestDeleteLog_NotFound(t *testing.T) {
+func TestGetFacets_RejectsUnknownField(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
 
-	router := chi.NewRouter()
-	router.Delete("/api/logs/{id}", handlers.DeleteLog(db))
-
-	req := httptest.NewRequest(http.MethodDelete, "/api/logs/99999", nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/facets?fields=source,body", nil)
 	rec := httptest.NewRecorder()
 
-	router.ServeHTTP(rec, req)
+	handlers.GetFacets(db).ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusNotFound {
-		t.Errorf("expected status 404, got %d", rec.Code)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+	if !contains(rec.Body.String(), "body") {
+		t.Errorf("expected error to name the field, got %s", rec.Body.String())
 	}
 }
 
-func TestDeleteLogs_Bulk(t *testing.T) {
+func TestCleanupLogs(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
 
-	// Create test logs
-	createTestLog(t, db, "Log 1", "info", "test")
-	createTestLog(t, db, "Log 2", "info", "test")
-	createTestLog(t, db, "Log 3", "info", "test")
+	// Create a test log
+	createTestLog(t, db, "Test log", "info", "test")
 
-	body := `{"ids": [1, 2]}`
-	req := httptest.NewRequest(http.MethodDelete, "/api/logs", bytes.NewReader([]byte(body)))
+	body := `{"retention_days": 30}`
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/cleanup", bytes.NewReader([]byte(body)))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
 
-	handler := handlers.DeleteLogs(db)
+	handler := handlers.CleanupLogs(db)
 	handler.ServeHTTP(rec, req)
 
 	if rec.Code != http.StatusOK {
@@ -1065,97 +5063,204 @@ func TestDeleteLogs_Bulk(t *testing.T) {
 	}
 
 	var resp struct {
-		Deleted int `json:"deleted"`
+		DeletedCount int64  `json:"deleted_count"`
+		CutoffDate   string `json:"cutoff_date"`
+		Message      string `json:"message"`
 	}
 	_ = json.NewDecoder(rec.Body).Decode(&resp)
 
-	if resp.Deleted != 2 {
-		t.Errorf("expected 2 deleted, got %d", resp.Deleted)
+	if resp.Message != "Cleanup completed successfully" {
+		t.Errorf("expected success message, got '%s'", resp.Message)
+	}
+	if resp.CutoffDate == "" {
+		t.Error("expected cutoff_date to be set")
 	}
 }
 
-func TestDeleteLogs_EmptyIDs(t *testing.T) {
+func TestCleanupLogs_DryRun(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
 
-	body := `{"ids": []}`
-	req := httptest.NewRequest(http.MethodDelete, "/api/logs", bytes.NewReader([]byte(body)))
-	req.Header.Set("Content-Type", "application/json")
-	rec := httptest.NewRecorder()
+	repo := sqlite.NewLogRepository(db)
+	for i := 0; i < 3; i++ {
+		log := entities.NewLog(entities.LogHeader{Title: "Old log"}, nil)
+		log.CreatedAt = time.Now().AddDate(0, 0, -60)
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+	createTestLog(t, db, "Fresh log", "info", "test")
 
-	handler := handlers.DeleteLogs(db)
-	handler.ServeHTTP(rec, req)
+	cleanup := func(body string) handlers.RetentionStats {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/cleanup", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
 
-	if rec.Code != http.StatusBadRequest {
-		t.Errorf("expected status 400, got %d", rec.Code)
-	}
-}
+		handlers.CleanupLogs(db).ServeHTTP(rec, req)
 
-func TestDeleteLogs_InvalidBody(t *testing.T) {
-	db := testDB(t)
-	defer db.Close()
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
 
-	body := `{invalid json}`
-	req := httptest.NewRequest(http.MethodDelete, "/api/logs", bytes.NewReader([]byte(body)))
-	req.Header.Set("Content-Type", "application/json")
-	rec := httptest.NewRecorder()
+		var resp handlers.RetentionStats
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		return resp
+	}
 
-	handler := handlers.DeleteLogs(db)
-	handler.ServeHTTP(rec, req)
+	preview := cleanup(`{"retention_days": 30, "dry_run": true}`)
+	if !preview.DryRun {
+		t.Error("expected dry_run to be true")
+	}
+	if preview.DeletedCount != 3 {
+		t.Errorf("expected 3 logs to be reported, got %d", preview.DeletedCount)
+	}
+	if !contains(preview.Message, "Dry run") {
+		t.Errorf("expected message to mention dry run, got %q", preview.Message)
+	}
 
-	if rec.Code != http.StatusBadRequest {
-		t.Errorf("expected status 400, got %d", rec.Code)
+	if count, _ := repo.Count(); count != 4 {
+		t.Fatalf("expected no logs deleted in dry run, got %d remaining", count)
+	}
+
+	result := cleanup(`{"retention_days": 30}`)
+	if result.DryRun {
+		t.Error("expected dry_run to be false")
+	}
+	if result.DeletedCount != preview.DeletedCount {
+		t.Errorf("expected real cleanup to delete %d logs, got %d", preview.DeletedCount, result.DeletedCount)
 	}
 }
 
-func TestDeleteLog_InvalidID(t *testing.T) {
+func TestRemapSeverity(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
 
+	noisy1 := createTestLog(t, db, "Crawl failed", "error", "crawler")
+	noisy2 := createTestLog(t, db, "Crawl failed again", "error", "crawler")
+	other := createTestLog(t, db, "Charge failed", "error", "billing")
+
+	hub := handlers.NewSSEHub()
 	router := chi.NewRouter()
-	router.Delete("/api/logs/{id}", handlers.DeleteLog(db))
+	router.Get("/api/ws", handlers.WebSocketHandler(hub))
+	router.Post("/api/admin/remap", handlers.RemapSeverityWithSSE(db, hub))
+	server := httptest.NewServer(router)
+	defer server.Close()
 
-	req := httptest.NewRequest(http.MethodDelete, "/api/logs/invalid", nil)
-	rec := httptest.NewRecorder()
+	client := dialWebSocket(t, server, "/api/ws")
 
-	router.ServeHTTP(rec, req)
+	body := `{"source":"crawler","from_severity":"error","to_severity":"debug"}`
+	resp, err := http.Post(server.URL+"/api/admin/remap", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to remap: %v", err)
+	}
+	defer resp.Body.Close()
 
-	if rec.Code != http.StatusBadRequest {
-		t.Errorf("expected status 400, got %d", rec.Code)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var result handlers.RemapSeverityResponse
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	if result.Updated != 2 {
+		t.Errorf("expected 2 logs updated, got %d", result.Updated)
+	}
+
+	if event := client.readEvent(t); event.Type != "stats_updated" {
+		t.Errorf("expected stats_updated event, got %s", event.Type)
+	}
+
+	repo := sqlite.NewLogRepository(db)
+	for id, want := range map[int64]string{noisy1: "debug", noisy2: "debug", other: "error"} {
+		log, _ := repo.FindByID(id)
+		if string(log.Header.Severity) != want {
+			t.Errorf("log %d: expected severity %s, got %s", id, want, log.Header.Severity)
+		}
 	}
 }
 
-func TestCleanupLogs(t *testing.T) {
+func TestIntegrity_DetectAndRepair(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
 
-	// Create a test log
-	createTestLog(t, db, "Test log", "info", "test")
+	createTestLog(t, db, "Healthy", "info", "api")
+	corrupt := createTestLog(t, db, "Corrupt", "info", "api")
+	if _, err := db.Conn().Exec("UPDATE logs SET body = ? WHERE id = ?", `{"order": 42,`, corrupt); err != nil {
+		t.Fatalf("failed to corrupt body: %v", err)
+	}
 
-	body := `{"retention_days": 30}`
-	req := httptest.NewRequest(http.MethodPost, "/api/admin/cleanup", bytes.NewReader([]byte(body)))
-	req.Header.Set("Content-Type", "application/json")
-	rec := httptest.NewRecorder()
+	router := chi.NewRouter()
+	router.Get("/api/admin/integrity", handlers.CheckIntegrity(db))
+	router.Post("/api/admin/integrity/repair", handlers.RepairIntegrity(db))
+
+	check := func() handlers.IntegrityResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/integrity", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		var resp handlers.IntegrityResponse
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		return resp
+	}
 
-	handler := handlers.CleanupLogs(db)
-	handler.ServeHTTP(rec, req)
+	if resp := check(); resp.Count != 1 || len(resp.CorruptBodies) != 1 || resp.CorruptBodies[0] != corrupt {
+		t.Fatalf("expected corrupt body %d reported, got %+v", corrupt, resp)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/integrity/repair", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var repaired handlers.RepairIntegrityResponse
+	_ = json.NewDecoder(rec.Body).Decode(&repaired)
+	if repaired.Count != 1 || repaired.Repaired[0] != corrupt {
+		t.Errorf("expected log %d repaired, got %+v", corrupt, repaired)
+	}
 
-	if rec.Code != http.StatusOK {
-		t.Errorf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	log, err := sqlite.NewLogRepository(db).FindByID(corrupt)
+	if err != nil {
+		t.Fatalf("failed to find log: %v", err)
+	}
+	if log.Body["body_raw"] != `{"order": 42,` {
+		t.Errorf("expected raw body quarantined under body_raw, got %v", log.Body)
 	}
 
-	var resp struct {
-		DeletedCount int64  `json:"deleted_count"`
-		CutoffDate   string `json:"cutoff_date"`
-		Message      string `json:"message"`
+	if resp := check(); resp.Count != 0 || resp.CorruptBodies == nil {
+		t.Errorf("expected an empty report after repair, got %+v", resp)
 	}
-	_ = json.NewDecoder(rec.Body).Decode(&resp)
+}
 
-	if resp.Message != "Cleanup completed successfully" {
-		t.Errorf("expected success message, got '%s'", resp.Message)
+func TestRemapSeverity_InvalidRequest(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing source", `{"from_severity":"error","to_severity":"debug"}`},
+		{"missing to_severity", `{"source":"crawler","from_severity":"error"}`},
+		{"same severity", `{"source":"crawler","from_severity":"error","to_severity":"error"}`},
+		{"unknown from_severity", `{"source":"crawler","from_severity":"eror","to_severity":"debug"}`},
+		{"unknown to_severity", `{"source":"crawler","from_severity":"error","to_severity":"quiet"}`},
+		{"invalid JSON", `{invalid}`},
 	}
-	if resp.CutoffDate == "" {
-		t.Error("expected cutoff_date to be set")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/remap", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handlers.RemapSeverity(db).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", rec.Code)
+			}
+		})
 	}
 }
 
@@ -1241,6 +5346,215 @@ func TestGetRetentionInfo(t *testing.T) {
 	}
 }
 
+func TestGetRetentionInfo_CustomBuckets(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	repo := sqlite.NewLogRepository(db)
+	now := time.Now()
+	for _, age := range []time.Duration{
+		time.Hour,           // newer than every bucket
+		3 * 24 * time.Hour,  // older than 1d
+		10 * 24 * time.Hour, // older than 1d, 7d
+		45 * 24 * time.Hour, // older than 1d, 7d, 30d
+		45 * 24 * time.Hour,
+	} {
+		log := entities.NewLog(entities.LogHeader{Title: "Aged log"}, nil)
+		log.CreatedAt = now.Add(-age)
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/retention?buckets=1d,7d,30d,90d", nil)
+	rec := httptest.NewRecorder()
+
+	handler := handlers.GetRetentionInfo(db)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp struct {
+		Total   int                  `json:"total"`
+		ByAge   map[string]int       `json:"by_age"`
+		Buckets []handlers.AgeBucket `json:"buckets"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+
+	if resp.ByAge != nil {
+		t.Error("expected by_age to be omitted when custom buckets are requested")
+	}
+
+	want := []struct {
+		bucket    string
+		olderThan int
+	}{
+		{"1d", 4},
+		{"7d", 3},
+		{"30d", 2},
+		{"90d", 0},
+	}
+	if len(resp.Buckets) != len(want) {
+		t.Fatalf("expected %d buckets, got %d", len(want), len(resp.Buckets))
+	}
+	for i, w := range want {
+		b := resp.Buckets[i]
+		if b.Bucket != w.bucket {
+			t.Errorf("bucket %d: expected %q, got %q", i, w.bucket, b.Bucket)
+		}
+		if b.OlderThan != w.olderThan {
+			t.Errorf("bucket %s: expected older_than %d, got %d", w.bucket, w.olderThan, b.OlderThan)
+		}
+		if b.OlderThan+b.Remaining != resp.Total {
+			t.Errorf("bucket %s: older_than + remaining = %d, want total %d", w.bucket, b.OlderThan+b.Remaining, resp.Total)
+		}
+		if i > 0 && b.OlderThan > resp.Buckets[i-1].OlderThan {
+			t.Errorf("bucket %s: counts should be cumulative (non-increasing with age)", w.bucket)
+		}
+	}
+}
+
+func TestGetRetentionInfo_PinnedLogsCounted(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	repo := sqlite.NewLogRepository(db)
+	old := entities.NewLog(entities.LogHeader{Title: "Pinned incident"}, nil)
+	old.CreatedAt = time.Now().AddDate(0, -3, 0)
+	if err := repo.Create(old); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+	if err := repo.SetPinned(old.ID, true); err != nil {
+		t.Fatalf("failed to pin log: %v", err)
+	}
+	createTestLog(t, db, "Fresh", "info", "api")
+
+	fetch := func(query string) map[string]json.RawMessage {
+		rec := httptest.NewRecorder()
+		handlers.GetRetentionInfo(db).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/admin/retention"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp map[string]json.RawMessage
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return resp
+	}
+
+	resp := fetch("")
+	var byAge map[string]int
+	_ = json.Unmarshal(resp["by_age"], &byAge)
+	sum := 0
+	for _, n := range byAge {
+		sum += n
+	}
+	if sum != 2 || byAge["older"] != 1 {
+		t.Errorf("expected the pinned log in the older bucket and buckets adding up to 2, got %v", byAge)
+	}
+
+	resp = fetch("?buckets=30d")
+	var buckets []handlers.AgeBucket
+	_ = json.Unmarshal(resp["buckets"], &buckets)
+	if len(buckets) != 1 || buckets[0].OlderThan != 1 || buckets[0].Remaining != 1 {
+		t.Errorf("expected the pinned log to count as older than 30d, got %+v", buckets)
+	}
+}
+
+func TestGetRetentionInfo_InvalidBuckets(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/retention?buckets=1d,soon", nil)
+	rec := httptest.NewRecorder()
+
+	handler := handlers.GetRetentionInfo(db)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestGetRetentionInfo_Timezone(t *testing.T) {
+	now := time.Date(2026, 3, 10, 3, 0, 0, 0, time.UTC)
+	opts := handlers.DefaultTimeFieldOptions()
+	opts.Now = func() time.Time { return now }
+
+	tests := []struct {
+		name     string
+		tz       string
+		loggedAt time.Time
+		want     string
+	}{
+		// 15:30 UTC on the 9th is the previous day in UTC but the same day
+		// for viewers in New York (10:30) and Tokyo (00:30 on the 10th)
+		{"utc default", "", time.Date(2026, 3, 9, 15, 30, 0, 0, time.UTC), "yesterday"},
+		{"new york", "America/New_York", time.Date(2026, 3, 9, 15, 30, 0, 0, time.UTC), "today"},
+		{"tokyo", "Asia/Tokyo", time.Date(2026, 3, 9, 15, 30, 0, 0, time.UTC), "today"},
+		// 14:30 UTC on the 9th is 23:30 on the 9th in Tokyo
+		{"tokyo before midnight", "Asia/Tokyo", time.Date(2026, 3, 9, 14, 30, 0, 0, time.UTC), "yesterday"},
+		// 01:00 UTC on the 9th is still the 8th in New York
+		{"new york two days back", "America/New_York", time.Date(2026, 3, 9, 1, 0, 0, 0, time.UTC), "yesterday"},
+		{"utc two days back", "", time.Date(2026, 3, 8, 1, 0, 0, 0, time.UTC), "last_week"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			defer db.Close()
+
+			log := entities.NewLog(entities.LogHeader{Title: "Timed log"}, nil)
+			log.CreatedAt = tt.loggedAt
+			if err := sqlite.NewLogRepository(db).Create(log); err != nil {
+				t.Fatalf("failed to create log: %v", err)
+			}
+
+			target := "/api/admin/retention"
+			if tt.tz != "" {
+				target += "?tz=" + url.QueryEscape(tt.tz)
+			}
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			rec := httptest.NewRecorder()
+
+			handlers.GetRetentionInfoWithOptions(db, &opts).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var resp struct {
+				ByAge map[string]int `json:"by_age"`
+			}
+			_ = json.NewDecoder(rec.Body).Decode(&resp)
+
+			for bucket, count := range resp.ByAge {
+				want := 0
+				if bucket == tt.want {
+					want = 1
+				}
+				if count != want {
+					t.Errorf("bucket %s: expected %d, got %d (by_age %v)", bucket, want, count, resp.ByAge)
+				}
+			}
+		})
+	}
+}
+
+func TestGetRetentionInfo_InvalidTimezone(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/retention?tz=Mars/Olympus", nil)
+	rec := httptest.NewRecorder()
+
+	handlers.GetRetentionInfo(db).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
 func TestSimpleMetrics(t *testing.T) {
 	m := &handlers.SimpleMetrics{}
 
@@ -1395,6 +5709,75 @@ func TestDeleteLogsWithSSE_Broadcast(t *testing.T) {
 	}
 }
 
+func TestDeleteLogsByFilter(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	createTestLog(t, db, "cache probe", "debug", "test-source")
+	createTestLog(t, db, "cache miss", "debug", "test-source")
+	createTestLog(t, db, "cache warmed", "info", "test-source")
+	createTestLog(t, db, "request traced", "debug", "api")
+	createTestLog(t, db, "request failed", "error", "api")
+
+	hub := handlers.NewSSEHub()
+	stream := httptest.NewServer(handlers.SSEHandler(hub))
+	defer stream.Close()
+	resp, err := http.Get(stream.URL)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	if event := readSSEEvent(t, reader); event.Type != "connected" {
+		t.Fatalf("expected connected event, got %s", event.Type)
+	}
+
+	handler := handlers.DeleteLogsByFilter(db, hub)
+	deleteLogs := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/api/logs?"+query, nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, query := range []string{
+		"severity=debug&source=test-source",
+		"severity=debug&source=test-source&confirm=false",
+		"confirm=true",
+		"min_severity=loud&confirm=true",
+	} {
+		if rec := deleteLogs(query); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, rec.Code)
+		}
+	}
+
+	rec := deleteLogs("severity=debug&source=test-source&confirm=true")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var result struct {
+		Deleted int64 `json:"deleted"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&result)
+	if result.Deleted != 2 {
+		t.Errorf("expected 2 deleted, got %d", result.Deleted)
+	}
+
+	logs, total, _ := sqlite.NewLogRepository(db).FindAll(sqlite.LogFilters{})
+	if total != 3 {
+		t.Fatalf("expected 3 logs left, got %d", total)
+	}
+	for _, log := range logs {
+		if log.Header.Severity == "debug" && log.Header.Source == "test-source" {
+			t.Errorf("expected %q to be deleted", log.Header.Title)
+		}
+	}
+
+	if event := readSSEEvent(t, reader); event.Type != "stats_updated" {
+		t.Errorf("expected stats_updated event, got %s", event.Type)
+	}
+}
+
 func TestPrometheusMetricsHandler_WithSSE(t *testing.T) {
 	getMetrics := func() (uint64, int64, uint64) {
 		return 100, 5, 2
@@ -1448,15 +5831,57 @@ func TestGetStats_Empty(t *testing.T) {
 func TestSPAHandler_MethodNotAllowed(t *testing.T) {
 	handler := handlers.NewSPAHandler(mockFS(), "dist")
 
-	// POST should still work (SPA handles routing)
-	req := httptest.NewRequest(http.MethodPost, "/some-path", nil)
-	rec := httptest.NewRecorder()
+	for _, path := range []string{"/some-path", "/assets/app.js"} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("POST %s: expected status 405, got %d", path, rec.Code)
+		}
+		if allow := rec.Header().Get("Allow"); allow != "GET, HEAD" {
+			t.Errorf("POST %s: expected Allow 'GET, HEAD', got '%s'", path, allow)
+		}
+		if contains(rec.Body.String(), "SPA") {
+			t.Errorf("POST %s: expected no index.html content", path)
+		}
+	}
 
+	// HEAD still routes to the SPA
+	req := httptest.NewRequest(http.MethodHead, "/dashboard", nil)
+	rec := httptest.NewRecorder()
 	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("HEAD: expected status 200, got %d", rec.Code)
+	}
+}
 
-	// SPA should serve index.html for any path
+func TestSPAHandler_APIPathsNotFound(t *testing.T) {
+	handler := handlers.NewSPAHandler(mockFS(), "dist")
+
+	for _, path := range []string{"/api", "/api/unknown", "/api/logs/1/nope"} {
+		for _, method := range []string{http.MethodGet, http.MethodPost} {
+			req := httptest.NewRequest(method, path, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("%s %s: expected status 404, got %d", method, path, rec.Code)
+			}
+			if contains(rec.Body.String(), "SPA") {
+				t.Errorf("%s %s: expected no index.html content", method, path)
+			}
+		}
+	}
+
+	// Front-end paths that merely start with "api" still route to the SPA
+	req := httptest.NewRequest(http.MethodGet, "/apidocs", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
 	if rec.Code != http.StatusOK {
-		t.Errorf("expected status 200 for SPA fallback, got %d", rec.Code)
+		t.Errorf("expected status 200 for /apidocs, got %d", rec.Code)
 	}
 }
 
@@ -1482,6 +5907,128 @@ func TestExportJSON_Empty(t *testing.T) {
 	}
 }
 
+// createExportLogs inserts n logs in a single batch.
+func createExportLogs(t *testing.T, db *sqlite.Database, n int) {
+	t.Helper()
+	logs := make([]*entities.Log, n)
+	for i := range logs {
+		logs[i] = entities.NewLog(entities.LogHeader{Title: fmt.Sprintf("Export %d", i), Severity: valueobjects.SeverityInfo}, map[string]any{"n": i})
+	}
+	if err := sqlite.NewLogRepository(db).CreateBatchContext(context.Background(), logs); err != nil {
+		t.Fatalf("failed to create logs: %v", err)
+	}
+}
+
+func TestExportJSON_Streamed(t *testing.T) {
+	for _, n := range []int{1, 3, 2500} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			db := testDB(t)
+			defer db.Close()
+			createExportLogs(t, db, n)
+
+			pagination := queries.DefaultPagination()
+			pagination.Export = queries.PageSize{Default: 5000, Max: 5000}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/export/json", nil)
+			rec := httptest.NewRecorder()
+			handlers.ExportJSONWithPagination(db, &pagination).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if got := rec.Header().Get("X-Total-Matched"); got != strconv.Itoa(n) {
+				t.Errorf("expected X-Total-Matched %d, got %s", n, got)
+			}
+
+			var logs []handlers.LogResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &logs); err != nil {
+				t.Fatalf("streamed export is not a valid JSON array: %v", err)
+			}
+			if len(logs) != n {
+				t.Fatalf("expected %d logs, got %d", n, len(logs))
+			}
+			// Newest first
+			for i := 1; i < len(logs); i++ {
+				if logs[i].ID >= logs[i-1].ID {
+					t.Fatalf("logs out of order at %d: %d after %d", i, logs[i].ID, logs[i-1].ID)
+				}
+			}
+			if rec.Result().Trailer.Get("X-Export-Error") != "" {
+				t.Errorf("unexpected X-Export-Error: %s", rec.Result().Trailer.Get("X-Export-Error"))
+			}
+		})
+	}
+}
+
+func TestExportJSON_StreamedPrettyMatchesMarshalIndent(t *testing.T) {
+	for _, n := range []int{0, 2} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			db := testDB(t)
+			defer db.Close()
+			createExportLogs(t, db, n)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/export/json?pretty=true", nil)
+			rec := httptest.NewRecorder()
+			handlers.ExportJSON(db).ServeHTTP(rec, req)
+
+			var logs []handlers.LogResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &logs); err != nil {
+				t.Fatalf("failed to decode export: %v", err)
+			}
+			if logs == nil {
+				logs = []handlers.LogResponse{}
+			}
+			want, _ := json.MarshalIndent(logs, "", "  ")
+			if rec.Body.String() != string(want)+"\n" {
+				t.Errorf("pretty export differs from MarshalIndent:\n%s\nwant:\n%s", rec.Body.String(), want)
+			}
+		})
+	}
+}
+
+// cancelAfterWrite cancels the request once the response body has started.
+type cancelAfterWrite struct {
+	*httptest.ResponseRecorder
+	cancel context.CancelFunc
+}
+
+func (w *cancelAfterWrite) Write(p []byte) (int, error) {
+	w.cancel()
+	return w.ResponseRecorder.Write(p)
+}
+
+func (w *cancelAfterWrite) WriteString(str string) (int, error) {
+	w.cancel()
+	return w.ResponseRecorder.WriteString(str)
+}
+
+func TestExportJSON_StreamFailureKeepsValidJSON(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+	// More than one read batch, so the second read sees the cancellation
+	createExportLogs(t, db, 1500)
+
+	pagination := queries.DefaultPagination()
+	pagination.Export = queries.PageSize{Default: 5000, Max: 5000}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/api/export/json", nil).WithContext(ctx)
+	rec := &cancelAfterWrite{ResponseRecorder: httptest.NewRecorder(), cancel: cancel}
+	handlers.ExportJSONWithPagination(db, &pagination).ServeHTTP(rec, req)
+
+	var logs []handlers.LogResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &logs); err != nil {
+		t.Fatalf("interrupted export is not valid JSON: %v", err)
+	}
+	if len(logs) == 0 || len(logs) >= 1500 {
+		t.Errorf("expected a partial export, got %d logs", len(logs))
+	}
+	if rec.Result().Trailer.Get("X-Export-Error") == "" {
+		t.Error("expected X-Export-Error trailer on an interrupted export")
+	}
+}
+
 func TestExportCSV_Empty(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
@@ -1540,9 +6087,14 @@ func TestSSEHandler_NoFlusher(t *testing.T) {
 		t.Errorf("expected status 500, got %d", rec.code)
 	}
 
-	body := rec.body.String()
-	if !contains(body, "Streaming unsupported") {
-		t.Error("expected 'Streaming unsupported' error message")
+	var errResp struct {
+		Error handlers.APIError `json:"error"`
+	}
+	if err := json.Unmarshal(rec.body.Bytes(), &errResp); err != nil {
+		t.Fatalf("expected a JSON error envelope, got %q: %v", rec.body.String(), err)
+	}
+	if errResp.Error.Code != handlers.CodeInternal || errResp.Error.Message != "Streaming unsupported" {
+		t.Errorf("expected internal_error 'Streaming unsupported', got %+v", errResp.Error)
 	}
 }
 
@@ -1558,6 +6110,67 @@ func TestSSEHub_RegisterUnregister(t *testing.T) {
 	}
 }
 
+func TestGetEnums(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/meta/enums", nil)
+	rec := httptest.NewRecorder()
+
+	handlers.GetEnums(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var response handlers.EnumsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	standard := valueobjects.StandardSeverities()
+	if len(response.Severities) != len(standard) {
+		t.Fatalf("expected %d severities, got %d", len(standard), len(response.Severities))
+	}
+	for i, s := range response.Severities {
+		if s.Value != standard[i].String() {
+			t.Errorf("severity %d: expected %q, got %q", i, standard[i], s.Value)
+		}
+		if s.Order != i+1 {
+			t.Errorf("severity %q: expected order %d, got %d", s.Value, i+1, s.Order)
+		}
+		if s.Rank != standard[i].Rank() || s.Rank == 0 {
+			t.Errorf("severity %q: unexpected rank %d", s.Value, s.Rank)
+		}
+		if i > 0 && s.Rank < response.Severities[i-1].Rank {
+			t.Errorf("ranks not monotonic: %q (%d) after %q (%d)",
+				s.Value, s.Rank, response.Severities[i-1].Value, response.Severities[i-1].Rank)
+		}
+		if !valueobjects.Color(s.Color).IsValid() {
+			t.Errorf("severity %q: invalid color %q", s.Value, s.Color)
+		}
+	}
+
+	categories := valueobjects.AllCategories()
+	if len(response.Categories) != len(categories) {
+		t.Fatalf("expected %d categories, got %d", len(categories), len(response.Categories))
+	}
+	for i, c := range categories {
+		if response.Categories[i] != c.String() {
+			t.Errorf("category %d: expected %q, got %q", i, c, response.Categories[i])
+		}
+	}
+
+	if len(response.Colors) != len(valueobjects.ValidColors) {
+		t.Fatalf("expected %d colors, got %d", len(valueobjects.ValidColors), len(response.Colors))
+	}
+	for i, c := range valueobjects.ValidColors {
+		if response.Colors[i] != c {
+			t.Errorf("color %d: expected %q, got %q", i, c, response.Colors[i])
+		}
+		if hex := response.Palette[c]; hex == "" || hex != valueobjects.Color(c).Hex() {
+			t.Errorf("color %q: expected palette hex %q, got %q", c, valueobjects.Color(c).Hex(), hex)
+		}
+	}
+}
+
 func TestMetrics_FormatDuration(t *testing.T) {
 	// Test by calling MetricsHandler which uses formatDuration internally
 	getMetrics := func() (uint64, int64, uint64) {
@@ -1588,3 +6201,20 @@ func TestMetrics_FormatDuration(t *testing.T) {
 func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
+
+func TestNewEscalator(t *testing.T) {
+	if escalator, err := handlers.NewEscalator(time.Minute, nil); escalator != nil || err != nil {
+		t.Errorf("expected no escalator without thresholds, got %v, %v", escalator, err)
+	}
+	if escalator, err := handlers.NewEscalator(0, map[string]int{"error": 10}); escalator != nil || err != nil {
+		t.Errorf("expected no escalator without a window, got %v, %v", escalator, err)
+	}
+	for _, thresholds := range []map[string]int{
+		{"fatal": 10},
+		{"error": 1},
+	} {
+		if _, err := handlers.NewEscalator(time.Minute, thresholds); err == nil {
+			t.Errorf("expected %v to be rejected", thresholds)
+		}
+	}
+}
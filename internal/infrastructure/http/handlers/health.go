@@ -1,9 +1,9 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
 	"github.com/mx-scribe/scribe/internal/version"
 )
 
@@ -20,6 +20,52 @@ func Health(w http.ResponseWriter, r *http.Request) {
 		Version: version.Version,
 	}
 
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(response)
+	writeJSON(w, r, http.StatusOK, response)
+}
+
+// ReadyResponse represents the readiness check response.
+type ReadyResponse struct {
+	Status string              `json:"status"`
+	Schema sqlite.SchemaStatus `json:"schema"`
+	Reason string              `json:"reason,omitempty"`
+}
+
+// ReadinessOptions holds server-wide readiness settings.
+type ReadinessOptions struct {
+	// AllowSchemaMismatch reports ready even when the database schema is not
+	// at the version the binary expects, for forced operation.
+	AllowSchemaMismatch bool
+}
+
+// Ready handles the readiness check endpoint.
+func Ready(db *sqlite.Database) http.HandlerFunc {
+	return ReadyWithOptions(db, nil)
+}
+
+// ReadyWithOptions handles the readiness check endpoint using opts, which are
+// read on every request. It returns 503 when the database schema version
+// differs from the latest embedded migration, unless opts allow it.
+func ReadyWithOptions(db *sqlite.Database, opts *ReadinessOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, err := sqlite.CheckSchema(db.Conn())
+		if err != nil {
+			writeJSON(w, r, http.StatusServiceUnavailable, ReadyResponse{
+				Status: "not_ready",
+				Reason: err.Error(),
+			})
+			return
+		}
+
+		response := ReadyResponse{Status: "ready", Schema: status}
+		if err := status.Err(); err != nil {
+			response.Reason = err.Error()
+			if opts == nil || !opts.AllowSchemaMismatch {
+				response.Status = "not_ready"
+				writeJSON(w, r, http.StatusServiceUnavailable, response)
+				return
+			}
+		}
+
+		writeJSON(w, r, http.StatusOK, response)
+	}
 }
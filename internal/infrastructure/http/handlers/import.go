@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/application/commands"
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// importBatchSize is the number of logs inserted per transaction during an
+// import; a progress event is sent after each batch.
+const importBatchSize = 500
+
+// ImportProgress reports how far an import has got.
+type ImportProgress struct {
+	Imported  int `json:"imported"`
+	Skipped   int `json:"skipped"`
+	TotalRead int `json:"total_read"`
+}
+
+// ImportLineError describes a line that could not be imported.
+type ImportLineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportSummary is the final event of an import.
+type ImportSummary struct {
+	ImportProgress
+	Errors []ImportLineError `json:"errors,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// ImportLogs handles POST /api/admin/import. The body is read as NDJSON (one
+// CreateLogRequest per line) and inserted in batched transactions, while
+// the response streams "progress" SSE events after each batch and a final
+// "summary" event. Invalid lines are skipped and reported in the summary.
+// The import stops when the client disconnects; batches already committed
+// are kept.
+func ImportLogs(db *sqlite.Database) http.HandlerFunc {
+	return ImportLogsWithOptions(db, nil)
+}
+
+// ImportLogsWithOptions handles POST /api/admin/import like ImportLogs,
+// building each line through the ingestion path of POST /api/logs with
+// opts, with ?analyze overriding whether lines are pattern matched. Lines
+// its policies turn away are skipped; only rejected ones are reported as
+// errors.
+func ImportLogsWithOptions(db *sqlite.Database, opts *IngestOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		analyze, err := analyzeParam(r, opts)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, r, http.StatusInternalServerError, "streaming unsupported")
+			return
+		}
+
+		// Large imports must not be cut off by the server timeouts
+		rc := http.NewResponseController(w)
+		_ = rc.SetReadDeadline(time.Time{})
+		_ = rc.SetWriteDeadline(time.Time{})
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		ctx := r.Context()
+		repo := sqlite.NewLogRepository(db)
+		handler := commands.NewCreateLogHandler(repo)
+
+		summary := ImportSummary{}
+		batch := make([]*entities.Log, 0, importBatchSize)
+		batchLines := make([]int, 0, importBatchSize)
+
+		skip := func(line int, err error) {
+			summary.Skipped++
+			if len(summary.Errors) < streamMaxErrors {
+				summary.Errors = append(summary.Errors, ImportLineError{Line: line, Error: err.Error()})
+			}
+		}
+
+		flush := func() {
+			if len(batch) == 0 {
+				return
+			}
+			if err := repo.CreateBatchContext(ctx, batch); err != nil {
+				for _, line := range batchLines {
+					skip(line, err)
+				}
+			} else {
+				summary.Imported += len(batch)
+				observeIngested(batch...)
+			}
+			batch = batch[:0]
+			batchLines = batchLines[:0]
+
+			if ctx.Err() == nil {
+				sendSSEEvent(w, flusher, SSEEvent{Type: "progress", Data: summary.ImportProgress})
+			}
+		}
+
+		reader := bufio.NewReader(r.Body)
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+
+			line, tooLong, err := readStreamLine(reader, streamMaxLineSize)
+			if len(bytes.TrimSpace(line)) > 0 || tooLong {
+				summary.TotalRead++
+
+				log, lineErr := buildStreamLog(handler, line, tooLong, analyze, opts)
+				var ingestErr *IngestError
+				switch {
+				case errors.As(lineErr, &ingestErr) && ingestErr.Dropped:
+					summary.Skipped++
+				case lineErr != nil:
+					skip(summary.TotalRead, lineErr)
+				default:
+					batch = append(batch, log)
+					batchLines = append(batchLines, summary.TotalRead)
+				}
+
+				if len(batch) >= importBatchSize {
+					flush()
+				}
+			}
+
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				flush()
+				if !errors.Is(err, io.EOF) {
+					summary.Error = "failed to read import: " + err.Error()
+				}
+				break
+			}
+		}
+
+		sendSSEEvent(w, flusher, SSEEvent{Type: "summary", Data: summary})
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/application/commands"
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// IngestError is a log turned away by an ingestion policy of IngestOptions.
+type IngestError struct {
+	// Code is CodeEventTooOld, CodeSourceDenied, CodeBelowMinimum or
+	// CodeRateLimited.
+	Code    ErrorCode
+	Message string
+
+	// Dropped reports that the policy drops such logs silently rather than
+	// rejecting them.
+	Dropped bool
+
+	// Source is the effective source of a CodeSourceDenied or
+	// CodeRateLimited rejection. RetryAfter is how long until the source
+	// of the latter has a token available again.
+	Source     string
+	RetryAfter time.Duration
+}
+
+func (e *IngestError) Error() string {
+	return e.Message
+}
+
+// BuildLog builds a log ready to be saved from input the way POST /api/logs
+// does: it checks the event age, applies the ingestion passes of opts, then
+// the source filter, the severity floor and the per-source rate limit. A
+// log turned away by one of them is reported as an *IngestError. A nil opts
+// only builds.
+func (opts *IngestOptions) BuildLog(handler *commands.CreateLogHandler, input commands.CreateLogInput) (*entities.Log, error) {
+	log, _, err := opts.buildLog(handler, input)
+	return log, err
+}
+
+// buildLog is BuildLog returning the metadata the pattern matcher derived
+// as well.
+func (opts *IngestOptions) buildLog(handler *commands.CreateLogHandler, input commands.CreateLogInput) (*entities.Log, entities.LogMetadata, error) {
+	if err := opts.checkEventAge(input.Timestamp); err != nil {
+		return nil, entities.LogMetadata{}, &IngestError{Code: CodeEventTooOld, Message: err.Error()}
+	}
+
+	opts.applyToInput(&input)
+	log, analysis, err := handler.BuildAnalyzed(input)
+	if err != nil {
+		return nil, analysis, err
+	}
+	if opts == nil {
+		return log, analysis, nil
+	}
+
+	if opts.SourceFilter != nil && !opts.SourceFilter.Allow(log.EffectiveSource()) {
+		return nil, analysis, &IngestError{
+			Code:    CodeSourceDenied,
+			Message: fmt.Sprintf("source %q is not allowed", log.EffectiveSource()),
+			Dropped: opts.SourceFilter.Drops(),
+			Source:  log.EffectiveSource(),
+		}
+	}
+
+	if opts.SeverityFloor != nil && !opts.SeverityFloor.Allow(log.EffectiveSeverity()) {
+		return nil, analysis, &IngestError{
+			Code:    CodeBelowMinimum,
+			Message: fmt.Sprintf("severity %q is below the ingestion minimum %q", log.EffectiveSeverity(), opts.SeverityFloor.Min()),
+			Dropped: opts.SeverityFloor.Drops(),
+		}
+	}
+
+	if opts.SourceLimit != nil {
+		source := log.EffectiveSource()
+		if ok, retryAfter := opts.SourceLimit.Allow(source); !ok {
+			return nil, analysis, &IngestError{
+				Code:       CodeRateLimited,
+				Message:    fmt.Sprintf("rate limit exceeded for source %q", source),
+				Dropped:    opts.SourceLimit.Drops(),
+				Source:     source,
+				RetryAfter: retryAfter,
+			}
+		}
+	}
+
+	return log, analysis, nil
+}
+
+// SaveLogs stores logs built by BuildLog in one transaction, escalating
+// those that recur, records their body sizes and severities and broadcasts
+// them to hub when it is not nil.
+func (opts *IngestOptions) SaveLogs(ctx context.Context, db *sqlite.Database, hub *SSEHub, logs []*entities.Log) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	escalations := opts.escalate(ctx, logs...)
+	if err := sqlite.NewLogRepository(db).CreateBatchContext(ctx, logs); err != nil {
+		return err
+	}
+	observeIngested(logs...)
+	if hub != nil {
+		for _, log := range logs {
+			hub.BroadcastLogCreatedContext(ctx, log)
+		}
+		broadcastEscalations(ctx, hub, escalations)
+	}
+	return nil
+}
+
+// escalate runs the escalator of opts over logs about to be saved for the
+// tenant of ctx, returning the escalations to announce once they are.
+func (opts *IngestOptions) escalate(ctx context.Context, logs ...*entities.Log) []*LogEscalation {
+	if opts == nil || opts.Escalator == nil {
+		return nil
+	}
+	var escalations []*LogEscalation
+	for _, log := range logs {
+		if escalation := opts.Escalator.Escalate(ctx, log); escalation != nil {
+			escalations = append(escalations, escalation)
+		}
+	}
+	return escalations
+}
+
+// broadcastEscalations sends a log_escalated event for each escalation of a
+// saved log.
+func broadcastEscalations(ctx context.Context, hub *SSEHub, escalations []*LogEscalation) {
+	for _, escalation := range escalations {
+		escalation.ID = escalation.log.ID
+		hub.BroadcastLogEscalatedContext(ctx, *escalation)
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// IntegrityResponse lists the logs whose stored body is not a JSON object.
+// Such bodies are read back as empty, so without this check the corruption
+// goes unnoticed.
+type IntegrityResponse struct {
+	CorruptBodies []int64 `json:"corrupt_bodies"`
+	Count         int     `json:"count"`
+}
+
+// RepairIntegrityResponse lists the logs whose corrupt body was quarantined.
+type RepairIntegrityResponse struct {
+	Repaired []int64 `json:"repaired"`
+	Count    int     `json:"count"`
+}
+
+// CheckIntegrity handles GET /api/admin/integrity, reporting the IDs of logs
+// whose stored body fails to parse.
+func CheckIntegrity(db *sqlite.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ids, err := sqlite.NewLogRepository(db).FindCorruptBodyIDsContext(r.Context())
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, IntegrityResponse{CorruptBodies: ids, Count: len(ids)})
+	}
+}
+
+// RepairIntegrity handles POST /api/admin/integrity/repair. Each corrupt
+// body is replaced by {"body_raw": "<stored text>"}, keeping the raw text
+// recoverable instead of losing it to an empty body.
+func RepairIntegrity(db *sqlite.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ids, err := sqlite.NewLogRepository(db).QuarantineCorruptBodiesContext(r.Context())
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, RepairIntegrityResponse{Repaired: ids, Count: len(ids)})
+	}
+}
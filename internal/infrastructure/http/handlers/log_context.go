@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+const (
+	// defaultContextLogs is the number of neighbors returned on each side of
+	// a log when before or after is not given.
+	defaultContextLogs = 10
+
+	// maxContextLogs caps the neighbors returned on each side of a log.
+	maxContextLogs = 100
+)
+
+// LogContextResponse is a log with the logs around it, oldest first.
+type LogContextResponse struct {
+	TargetID int64         `json:"target_id"`
+	Logs     []LogResponse `json:"logs"`
+	Before   int           `json:"before"`
+	After    int           `json:"after"`
+}
+
+// GetLogContext handles GET /api/logs/{id}/context, returning the log with
+// up to before (default 10) logs preceding it and after (default 10)
+// following it in time, like grep -C. With same_source=true only logs from
+// the same effective source are included.
+func GetLogContext(db *sqlite.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "invalid log ID")
+			return
+		}
+
+		before, err := contextCountParam(r, "before")
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		after, err := contextCountParam(r, "after")
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		sameSource, err := sameSourceParam(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		loc, err := timezoneParam(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		repo := sqlite.NewLogRepository(db)
+		log, err := repo.FindByIDContext(r.Context(), id)
+		if err != nil {
+			if err == entities.ErrLogNotFound {
+				writeError(w, r, http.StatusNotFound, "log not found")
+				return
+			}
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		older, newer, err := repo.FindNeighborsContext(r.Context(), log, before, after, sameSource)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		response := LogContextResponse{
+			TargetID: log.ID,
+			Logs:     make([]LogResponse, 0, len(older)+1+len(newer)),
+			Before:   len(older),
+			After:    len(newer),
+		}
+		for _, neighbor := range older {
+			response.Logs = append(response.Logs, logToResponse(neighbor, loc))
+		}
+		response.Logs = append(response.Logs, logToResponse(log, loc))
+		for _, neighbor := range newer {
+			response.Logs = append(response.Logs, logToResponse(neighbor, loc))
+		}
+
+		writeJSON(w, r, http.StatusOK, response)
+	}
+}
+
+// contextCountParam returns the named neighbor count query parameter,
+// defaulting to defaultContextLogs and capped at maxContextLogs.
+func contextCountParam(r *http.Request, name string) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return defaultContextLogs, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: %s must be a non-negative integer", errInvalidFilter, name)
+	}
+	return min(n, maxContextLogs), nil
+}
+
+// sameSourceParam returns the same_source query parameter, false when absent.
+func sameSourceParam(r *http.Request) (bool, error) {
+	raw := r.URL.Query().Get("same_source")
+	if raw == "" {
+		return false, nil
+	}
+	sameSource, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%w: same_source must be true or false", errInvalidFilter)
+	}
+	return sameSource, nil
+}
@@ -1,14 +1,22 @@
 package handlers
 
 import (
-	"encoding/json"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/mx-scribe/scribe/internal/application/commands"
+	"github.com/mx-scribe/scribe/internal/application/queries"
 	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/domain/services"
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
 	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
 )
 
@@ -22,15 +30,32 @@ type CreateLogRequest struct {
 		Description string `json:"description,omitempty"`
 	} `json:"header"`
 	Body map[string]any `json:"body,omitempty"`
+
+	// Timestamp is the optional original event time (RFC 3339) of an
+	// imported or forwarded log; it defaults to the time of receipt.
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+
+	// ExpiresIn is how long to keep the log, e.g. "12h" or "365d",
+	// overriding the global retention window.
+	ExpiresIn string `json:"expires_in,omitempty"`
 }
 
 // LogResponse represents a log in API responses.
 type LogResponse struct {
-	ID        int64          `json:"id"`
-	Header    HeaderResponse `json:"header"`
-	Body      map[string]any `json:"body"`
-	Metadata  MetaResponse   `json:"metadata,omitempty"`
-	CreatedAt string         `json:"created_at"`
+	ID              int64          `json:"id"`
+	Header          HeaderResponse `json:"header"`
+	Body            map[string]any `json:"body"`
+	Metadata        *MetaResponse  `json:"metadata,omitempty"`
+	Pinned          bool           `json:"pinned"`
+	AnnotationCount int            `json:"annotation_count"`
+	MatchSnippet    string         `json:"match_snippet,omitempty"`
+	CreatedAt       string         `json:"created_at"`
+	CreatedAtMs     int64          `json:"created_at_ms"`
+	IngestedAt      string         `json:"ingested_at"`
+	ExpiresAt       string         `json:"expires_at,omitempty"`
+	// BodySizeBytes is the length of the serialized body, reported by
+	// GET /api/logs/{id}.
+	BodySizeBytes int64 `json:"body_size_bytes,omitempty"`
 }
 
 // HeaderResponse represents the log header in responses.
@@ -47,6 +72,19 @@ type MetaResponse struct {
 	DerivedSeverity string `json:"derived_severity,omitempty"`
 	DerivedSource   string `json:"derived_source,omitempty"`
 	DerivedCategory string `json:"derived_category,omitempty"`
+	DerivedColor    string `json:"derived_color,omitempty"`
+}
+
+// SeverityExplanation shows how the severity of a created log was resolved,
+// returned as "explain" by POST /api/logs?explain=true. DerivedSeverity is
+// what the pattern matcher suggested, even when the supplied severity took
+// precedence. Overridden reports that the stored severity is not the one
+// supplied, ignoring case.
+type SeverityExplanation struct {
+	SuppliedSeverity  string `json:"supplied_severity,omitempty"`
+	DerivedSeverity   string `json:"derived_severity,omitempty"`
+	EffectiveSeverity string `json:"effective_severity"`
+	Overridden        bool   `json:"overridden"`
 }
 
 // ListLogsResponse represents the paginated logs response.
@@ -55,6 +93,131 @@ type ListLogsResponse struct {
 	Total int           `json:"total"`
 	Limit int           `json:"limit"`
 	Page  int           `json:"page"`
+	// NextCursor continues the listing after the last log via ?cursor=.
+	// It is omitted when the page was not full.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Input converts the request into the create log command input,
+// failing when expires_in is not a positive duration.
+func (req CreateLogRequest) Input() (commands.CreateLogInput, error) {
+	input := commands.CreateLogInput{
+		Title:       req.Header.Title,
+		Severity:    req.Header.Severity,
+		Source:      req.Header.Source,
+		Color:       req.Header.Color,
+		Description: req.Header.Description,
+		Body:        req.Body,
+		Timestamp:   req.Timestamp,
+	}
+	if req.ExpiresIn != "" {
+		expiresIn, err := parseDuration(req.ExpiresIn)
+		if err != nil || expiresIn <= 0 {
+			return input, &fieldError{
+				field: "expires_in",
+				err:   fmt.Errorf("expires_in must be a positive duration, got %q", req.ExpiresIn),
+			}
+		}
+		input.ExpiresIn = expiresIn
+	}
+	return input, nil
+}
+
+// fieldError is a validation failure of a single request field.
+type fieldError struct {
+	field string
+	err   error
+}
+
+func (e *fieldError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
+
+// IngestOptions holds server-wide defaults for log ingestion.
+type IngestOptions struct {
+	// AutoAnalyze runs the pattern matcher on new logs unless a request
+	// overrides it with ?analyze=false.
+	AutoAnalyze bool
+
+	// SourceLimit caps logs per second per source, checked after source
+	// derivation. Nil disables it.
+	SourceLimit *SourceLimiter
+
+	// SeverityFloor turns away logs whose effective severity, after
+	// derivation, ranks below a minimum. Nil disables it.
+	SeverityFloor *SeverityFloor
+
+	// SourceFilter turns away logs whose effective source, after
+	// derivation, is not approved. Nil admits every source.
+	SourceFilter *SourceFilter
+
+	// MaxEventAge turns away logs whose supplied timestamp is older than
+	// it, so replayed data cannot pass for recent. Logs timed by the server
+	// are exempt. Zero disables the check.
+	MaxEventAge time.Duration
+
+	// BodyNormalizer copies aliased body fields to canonical keys on
+	// ingest. Nil disables normalization.
+	BodyNormalizer *services.BodyNormalizer
+
+	// BodyRedactor masks secrets in bodies on ingest. Nil disables
+	// redaction.
+	BodyRedactor *services.BodyRedactor
+
+	// PatternMatcher derives metadata for new logs and /api/analyze. Nil
+	// uses the built-in rules.
+	PatternMatcher *services.PatternMatcher
+
+	// Colorizer derives a color from the effective severity of new logs
+	// sent without one. Nil leaves their color unset.
+	Colorizer *services.SeverityColorizer
+
+	// Escalator raises the severity of logs that recur past its thresholds
+	// as they are saved. Nil disables escalation.
+	Escalator *Escalator
+}
+
+// applyToInput sets the body normalization and redaction passes, the
+// pattern matcher and the colorizer of opts on input. A nil opts leaves
+// input unchanged.
+func (opts *IngestOptions) applyToInput(input *commands.CreateLogInput) {
+	if opts == nil {
+		return
+	}
+	input.Normalizer = opts.BodyNormalizer
+	input.Redactor = opts.BodyRedactor
+	input.Matcher = opts.PatternMatcher
+	input.Colorizer = opts.Colorizer
+}
+
+// checkEventAge returns an error when ts, a log's supplied timestamp, is
+// older than the maximum event age of opts.
+func (opts *IngestOptions) checkEventAge(ts *time.Time) error {
+	if opts == nil || opts.MaxEventAge <= 0 || ts == nil || ts.IsZero() {
+		return nil
+	}
+	if time.Since(*ts) > opts.MaxEventAge {
+		return fmt.Errorf("timestamp %s is older than the maximum event age of %s", ts.Format(time.RFC3339), opts.MaxEventAge)
+	}
+	return nil
+}
+
+// matcher returns the pattern matcher of opts, or one with the built-in
+// rules when none is set.
+func (opts *IngestOptions) matcher() *services.PatternMatcher {
+	if opts == nil || opts.PatternMatcher == nil {
+		return services.NewPatternMatcher()
+	}
+	return opts.PatternMatcher
+}
+
+// DefaultIngestOptions returns the default ingestion options.
+func DefaultIngestOptions() IngestOptions {
+	return IngestOptions{AutoAnalyze: true}
 }
 
 // CreateLog handles POST /api/logs.
@@ -64,42 +227,71 @@ func CreateLog(db *sqlite.Database) http.HandlerFunc {
 
 // CreateLogWithSSE handles POST /api/logs with SSE broadcast support.
 func CreateLogWithSSE(db *sqlite.Database, hub *SSEHub) http.HandlerFunc {
+	return CreateLogWithOptions(db, hub, nil)
+}
+
+// CreateLogWithOptions handles POST /api/logs using the ingestion defaults in
+// opts, which are read on every request. A nil opts uses the defaults.
+func CreateLogWithOptions(db *sqlite.Database, hub *SSEHub, opts *IngestOptions) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		analyze, err := analyzeParam(r, opts)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		explain, err := explainParam(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		var req CreateLogRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			writeError(w, http.StatusBadRequest, "invalid request body")
+		if !decodeJSONBody(w, r, &req) {
 			return
 		}
 
 		if req.Header.Title == "" {
-			writeError(w, http.StatusBadRequest, "title is required")
+			writeError(w, r, http.StatusBadRequest, "title is required")
 			return
 		}
 
 		repo := sqlite.NewLogRepository(db)
 		handler := commands.NewCreateLogHandler(repo)
 
-		input := commands.CreateLogInput{
-			Title:       req.Header.Title,
-			Severity:    req.Header.Severity,
-			Source:      req.Header.Source,
-			Color:       req.Header.Color,
-			Description: req.Header.Description,
-			Body:        req.Body,
+		input, err := req.Input()
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		input.SkipAnalysis = !analyze
+
+		log, analysis, err := opts.buildLog(handler, input)
+		var ingestErr *IngestError
+		switch {
+		case errors.As(err, &ingestErr):
+			writeIngestError(w, r, ingestErr)
+			return
+		case err != nil:
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
 		}
 
-		output, err := handler.Handle(input)
+		escalations := opts.escalate(r.Context(), log)
+		output, err := handler.SaveContext(r.Context(), log)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusInternalServerError, err.Error())
 			return
 		}
+		observeIngested(log)
 
 		// Broadcast to SSE clients if hub is available
 		if hub != nil {
-			log, _ := repo.FindByID(output.ID)
+			log, _ := repo.FindByIDContext(r.Context(), output.ID)
 			if log != nil {
-				hub.BroadcastLogCreated(log)
+				hub.BroadcastLogCreatedContext(r.Context(), log)
 			}
+			broadcastEscalations(r.Context(), hub, escalations)
 		}
 
 		response := map[string]any{
@@ -108,12 +300,50 @@ func CreateLogWithSSE(db *sqlite.Database, hub *SSEHub) http.HandlerFunc {
 			"severity":   output.Severity,
 			"created_at": output.CreatedAt,
 		}
+		if explain {
+			response["explain"] = SeverityExplanation{
+				SuppliedSeverity:  req.Header.Severity,
+				DerivedSeverity:   analysis.DerivedSeverity,
+				EffectiveSeverity: output.Severity,
+				Overridden:        req.Header.Severity != "" && !strings.EqualFold(req.Header.Severity, output.Severity),
+			}
+		}
 
-		w.WriteHeader(http.StatusCreated)
-		_ = json.NewEncoder(w).Encode(response)
+		w.Header().Set("Location", logLocation(output.ID))
+		writeJSON(w, r, http.StatusCreated, response)
 	}
 }
 
+// writeIngestError answers a log turned away by an ingestion policy: 422
+// for an event too old or below the severity floor, 403 when its source is
+// not allowed, 429 with Retry-After when its source is over the rate limit,
+// or 204 and 202 when the floor, the source filter and the limit drop logs
+// instead.
+func writeIngestError(w http.ResponseWriter, r *http.Request, err *IngestError) {
+	switch {
+	case (err.Code == CodeBelowMinimum || err.Code == CodeSourceDenied) && err.Dropped:
+		w.WriteHeader(http.StatusNoContent)
+	case err.Code == CodeSourceDenied:
+		writeAPIError(w, r, http.StatusForbidden, APIError{Code: err.Code, Message: err.Message})
+	case err.Code == CodeRateLimited && err.Dropped:
+		writeJSON(w, r, http.StatusAccepted, map[string]any{
+			"dropped": true,
+			"source":  err.Source,
+		})
+	case err.Code == CodeRateLimited:
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(err.RetryAfter.Seconds()))))
+		writeError(w, r, http.StatusTooManyRequests, err.Message)
+	default:
+		writeAPIError(w, r, http.StatusUnprocessableEntity, APIError{Code: err.Code, Message: err.Message})
+	}
+}
+
+// logLocation returns the URL path of the log with the given ID, as set in
+// the Location header of 201 responses.
+func logLocation(id int64) string {
+	return "/api/logs/" + strconv.FormatInt(id, 10)
+}
+
 // DeleteLog handles DELETE /api/logs/{id}.
 func DeleteLog(db *sqlite.Database) http.HandlerFunc {
 	return DeleteLogWithSSE(db, nil)
@@ -125,32 +355,36 @@ func DeleteLogWithSSE(db *sqlite.Database, hub *SSEHub) http.HandlerFunc {
 		idStr := chi.URLParam(r, "id")
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid log ID")
+			writeError(w, r, http.StatusBadRequest, "invalid log ID")
 			return
 		}
 
 		repo := sqlite.NewLogRepository(db)
 
 		// Check if log exists
-		_, err = repo.FindByID(id)
+		exists, err := repo.ExistsContext(r.Context(), id)
 		if err != nil {
-			if err == entities.ErrLogNotFound {
-				writeError(w, http.StatusNotFound, "log not found")
-				return
-			}
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !exists {
+			writeError(w, r, http.StatusNotFound, "log not found")
 			return
 		}
 
-		// Delete the log
-		if err := repo.Delete(id); err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+		// Delete the log; it may have been deleted since the check
+		if err := repo.DeleteContext(r.Context(), id); err != nil {
+			if err == entities.ErrLogNotFound {
+				writeError(w, r, http.StatusNotFound, "log not found")
+				return
+			}
+			writeError(w, r, http.StatusInternalServerError, err.Error())
 			return
 		}
 
 		// Broadcast to SSE clients if hub is available
 		if hub != nil {
-			hub.BroadcastLogDeleted(id)
+			hub.BroadcastLogDeletedContext(r.Context(), id)
 		}
 
 		w.WriteHeader(http.StatusNoContent)
@@ -168,13 +402,12 @@ func DeleteLogsWithSSE(db *sqlite.Database, hub *SSEHub) http.HandlerFunc {
 		var req struct {
 			IDs []int64 `json:"ids"`
 		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			writeError(w, http.StatusBadRequest, "invalid request body")
+		if !decodeJSONBody(w, r, &req) {
 			return
 		}
 
 		if len(req.IDs) == 0 {
-			writeError(w, http.StatusBadRequest, "ids are required")
+			writeError(w, r, http.StatusBadRequest, "ids are required")
 			return
 		}
 
@@ -182,50 +415,184 @@ func DeleteLogsWithSSE(db *sqlite.Database, hub *SSEHub) http.HandlerFunc {
 		deleted := 0
 
 		for _, id := range req.IDs {
-			if err := repo.Delete(id); err == nil {
+			if err := repo.DeleteContext(r.Context(), id); err == nil {
 				deleted++
 				if hub != nil {
-					hub.BroadcastLogDeleted(id)
+					hub.BroadcastLogDeletedContext(r.Context(), id)
 				}
 			}
 		}
 
-		_ = json.NewEncoder(w).Encode(map[string]int{"deleted": deleted})
+		writeJSON(w, r, http.StatusOK, map[string]int{"deleted": deleted})
+	}
+}
+
+// DeleteLogsByFilter handles DELETE /api/logs?confirm=true with filters.
+func DeleteLogsByFilter(db *sqlite.Database, hub *SSEHub) http.HandlerFunc {
+	return DeleteLogsByFilterWithOptions(db, hub, nil)
+}
+
+// DeleteLogsByFilterWithOptions handles DELETE /api/logs?confirm=true,
+// deleting every log matching the list filters of GET /api/logs, and
+// broadcasts refreshed stats counting the last 24 hours by the stats time
+// field in opts. At least one filter is required.
+func DeleteLogsByFilterWithOptions(db *sqlite.Database, hub *SSEHub, opts *TimeFieldOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if confirm, _ := strconv.ParseBool(r.URL.Query().Get("confirm")); !confirm {
+			writeError(w, r, http.StatusBadRequest, "confirm=true is required to delete logs by filter")
+			return
+		}
+
+		filters, err := listFilters(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		deleted, err := sqlite.NewLogRepository(db).DeleteByFilterContext(r.Context(), filters)
+		if errors.Is(err, sqlite.ErrNoFilter) {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		// Broadcast refreshed stats to SSE clients if hub is available
+		if hub != nil && deleted > 0 {
+			if stats, err := queries.NewGetStatsHandler(statsRepository(db, opts)).HandleContext(r.Context()); err == nil {
+				hub.BroadcastStatsUpdatedContext(r.Context(), stats)
+			}
+		}
+
+		writeJSON(w, r, http.StatusOK, map[string]int64{"deleted": deleted})
+	}
+}
+
+// PinLog handles POST /api/logs/{id}/pin.
+func PinLog(db *sqlite.Database) http.HandlerFunc {
+	return setPinned(db, true)
+}
+
+// UnpinLog handles DELETE /api/logs/{id}/pin.
+func UnpinLog(db *sqlite.Database) http.HandlerFunc {
+	return setPinned(db, false)
+}
+
+// setPinned updates a log's pinned state and returns the updated log.
+func setPinned(db *sqlite.Database, pinned bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "invalid log ID")
+			return
+		}
+		loc, err := timezoneParam(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		repo := sqlite.NewLogRepository(db)
+		if err := repo.SetPinnedContext(r.Context(), id, pinned); err != nil {
+			if err == entities.ErrLogNotFound {
+				writeError(w, r, http.StatusNotFound, "log not found")
+				return
+			}
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		log, err := repo.FindByIDContext(r.Context(), id)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, logToResponse(log, loc))
 	}
 }
 
 // ListLogs handles GET /api/logs.
 func ListLogs(db *sqlite.Database) http.HandlerFunc {
+	return ListLogsWithPagination(db, nil)
+}
+
+// ListLogsWithPagination handles GET /api/logs using the list page size from
+// pagination, which is read on every request. A nil pagination uses the defaults.
+// A page whose offset goes past pagination.MaxOffset is rejected with 400;
+// deeper reads follow next_cursor with ?cursor=, which replaces ?page=.
+func ListLogsWithPagination(db *sqlite.Database, pagination *queries.Pagination) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("ids") {
+			listLogsByID(w, r, db, pageSizes(pagination).List.Max)
+			return
+		}
+
 		// Parse query parameters
 		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-		if limit <= 0 {
-			limit = 20
-		}
-		if limit > 100 {
-			limit = 100
+		limit = pageSizes(pagination).List.Clamp(limit)
+
+		cursor, err := listCursorParam(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
 		}
 
 		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-		if page <= 0 {
+		if page <= 0 || cursor != nil {
 			page = 1
 		}
 		offset := (page - 1) * limit
+		if maxOffset := pageSizes(pagination).MaxOffset; maxOffset > 0 && offset > maxOffset {
+			writeError(w, r, http.StatusBadRequest, fmt.Sprintf(
+				"page %d is past the maximum offset of %d logs; page further with ?cursor= and the next_cursor of each response",
+				page, maxOffset))
+			return
+		}
+
+		filters, err := listFilters(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		filters.After = cursor
 
-		filters := sqlite.LogFilters{
-			Limit:    limit,
-			Offset:   offset,
-			Severity: r.URL.Query().Get("severity"),
-			Source:   r.URL.Query().Get("source"),
-			Search:   r.URL.Query().Get("search"),
-			FromDate: r.URL.Query().Get("from"),
-			ToDate:   r.URL.Query().Get("to"),
+		highlight, err := highlightParam(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
 		}
 
+		includeBody, err := includeBodyParam(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		includeMetadata, err := includeMetadataParam(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		loc, err := timezoneParam(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		search := filters.Search
+		filters.Limit = limit
+		filters.Offset = offset
+		filters.IncludeBody = includeBody
+
 		repo := sqlite.NewLogRepository(db)
-		logs, total, err := repo.FindAll(filters)
+		logs, total, err := repo.FindAllContext(r.Context(), filters)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusInternalServerError, err.Error())
 			return
 		}
 
@@ -237,11 +604,145 @@ func ListLogs(db *sqlite.Database) http.HandlerFunc {
 		}
 
 		for _, log := range logs {
-			response.Logs = append(response.Logs, logToResponse(log))
+			logResponse := logToResponse(log, loc)
+			if highlight {
+				logResponse.MatchSnippet = matchSnippet(log, search)
+			}
+			if !includeMetadata {
+				logResponse.Metadata = nil
+			}
+			response.Logs = append(response.Logs, logResponse)
+		}
+		if len(logs) == limit {
+			response.NextCursor = encodeListCursor(logs[len(logs)-1])
+		}
+
+		writeJSON(w, r, http.StatusOK, response)
+	}
+}
+
+// encodeListCursor returns the opaque ?cursor= value that continues a
+// listing after log.
+func encodeListCursor(log *entities.Log) string {
+	raw := log.CreatedAt.Format(time.RFC3339Nano) + "|" + strconv.FormatInt(log.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// listCursorParam returns the cursor query parameter, or nil when it is absent.
+func listCursorParam(r *http.Request) (*sqlite.LogCursor, error) {
+	raw := r.URL.Query().Get("cursor")
+	if raw == "" {
+		return nil, nil
+	}
+	invalid := fmt.Errorf("%w: invalid cursor", errInvalidFilter)
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, invalid
+	}
+	createdAt, id, ok := strings.Cut(string(decoded), "|")
+	if !ok {
+		return nil, invalid
+	}
+	cursor := &sqlite.LogCursor{}
+	if cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, invalid
+	}
+	if cursor.ID, err = strconv.ParseInt(id, 10, 64); err != nil {
+		return nil, invalid
+	}
+	return cursor, nil
+}
+
+// LogIDsResponse is the response of GET /api/logs/ids.
+type LogIDsResponse struct {
+	IDs   []int64 `json:"ids"`
+	Total int     `json:"total"`
+}
+
+// ListLogIDs handles GET /api/logs/ids.
+func ListLogIDs(db *sqlite.Database) http.HandlerFunc {
+	return ListLogIDsWithPagination(db, nil)
+}
+
+// ListLogIDsWithPagination handles GET /api/logs/ids, returning only the IDs
+// of the logs GET /api/logs would list for the same filters, in the same
+// order, with the total number matching. limit defaults to and is capped by
+// the ids page size; page pages through larger results. Feeding the IDs to
+// DELETE /api/logs deletes everything matching a filter.
+func ListLogIDsWithPagination(db *sqlite.Database, pagination *queries.Pagination) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		limit = pageSizes(pagination).IDs.Clamp(limit)
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page <= 0 {
+			page = 1
+		}
+
+		filters, err := listFilters(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
 		}
+		filters.Limit = limit
+		filters.Offset = (page - 1) * limit
+
+		ids, total, err := sqlite.NewLogRepository(db).FindIDsContext(r.Context(), filters)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, LogIDsResponse{IDs: ids, Total: total})
+	}
+}
+
+// listFilters returns the filters of a GET /api/logs style request:
+// severity, min_severity, source, color, category, search, title,
+// body_contains, from, to, pinned, body.<key> and or. Paging and body
+// inclusion are left to the caller.
+func listFilters(r *http.Request) (sqlite.LogFilters, error) {
+	minSeverity, err := minSeverityParam(r)
+	if err != nil {
+		return sqlite.LogFilters{}, err
+	}
+
+	category, err := categoryParam(r)
+	if err != nil {
+		return sqlite.LogFilters{}, err
+	}
+
+	pinned, err := pinnedParam(r)
+	if err != nil {
+		return sqlite.LogFilters{}, err
+	}
+
+	bodyFields, err := bodyFieldsParam(r)
+	if err != nil {
+		return sqlite.LogFilters{}, err
+	}
 
-		_ = json.NewEncoder(w).Encode(response)
+	or, err := orParam(r)
+	if err != nil {
+		return sqlite.LogFilters{}, err
 	}
+
+	query := r.URL.Query()
+	return sqlite.LogFilters{
+		Severity:    query.Get("severity"),
+		MinSeverity: minSeverity,
+		Source:      query.Get("source"),
+		Color:       query.Get("color"),
+		Category:    category,
+		Search:      query.Get("search"),
+		TitleSearch: query.Get("title"),
+		BodySearch:  query.Get("body_contains"),
+		FromDate:    query.Get("from"),
+		ToDate:      query.Get("to"),
+		Pinned:      pinned,
+		BodyFields:  bodyFields,
+		Or:          or,
+	}, nil
 }
 
 // GetLog handles GET /api/logs/{id}.
@@ -250,27 +751,315 @@ func GetLog(db *sqlite.Database) http.HandlerFunc {
 		idStr := chi.URLParam(r, "id")
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid log ID")
+			writeError(w, r, http.StatusBadRequest, "invalid log ID")
+			return
+		}
+
+		loc, err := timezoneParam(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
 			return
 		}
 
 		repo := sqlite.NewLogRepository(db)
-		log, err := repo.FindByID(id)
+		log, err := repo.FindByIDContext(r.Context(), id)
 		if err != nil {
 			if err == entities.ErrLogNotFound {
-				writeError(w, http.StatusNotFound, "log not found")
+				writeError(w, r, http.StatusNotFound, "log not found")
 				return
 			}
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusInternalServerError, err.Error())
 			return
 		}
 
-		_ = json.NewEncoder(w).Encode(logToResponse(log))
+		response := logToResponse(log, loc)
+		response.BodySizeBytes = log.BodySize
+		writeJSON(w, r, http.StatusOK, response)
 	}
 }
 
-// logToResponse converts a Log entity to a LogResponse.
-func logToResponse(log *entities.Log) LogResponse {
+// listLogsByID serves GET /api/logs?ids=1,2,3: exactly the listed logs, in
+// request order, ignoring every other filter. Missing IDs are omitted, and
+// at most maxIDs may be asked for.
+func listLogsByID(w http.ResponseWriter, r *http.Request, db *sqlite.Database, maxIDs int) {
+	ids, err := idsParam(r, maxIDs)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	loc, err := timezoneParam(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	includeMetadata, err := includeMetadataParam(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logs, err := sqlite.NewLogRepository(db).FindByIDsContext(r.Context(), ids)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := ListLogsResponse{
+		Logs:  make([]LogResponse, 0, len(logs)),
+		Total: len(logs),
+		Limit: len(ids),
+		Page:  1,
+	}
+	for _, log := range logs {
+		logResponse := logToResponse(log, loc)
+		if !includeMetadata {
+			logResponse.Metadata = nil
+		}
+		response.Logs = append(response.Logs, logResponse)
+	}
+
+	writeJSON(w, r, http.StatusOK, response)
+}
+
+// idsParam returns the comma-separated ids query parameter, rejecting
+// non-numeric IDs and lists longer than maxIDs.
+func idsParam(r *http.Request, maxIDs int) ([]int64, error) {
+	var ids []int64
+	for _, raw := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid log ID %q in ids", errInvalidFilter, raw)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: ids must list at least one log ID", errInvalidFilter)
+	}
+	if len(ids) > maxIDs {
+		return nil, fmt.Errorf("%w: ids lists %d logs, at most %d allowed", errInvalidFilter, len(ids), maxIDs)
+	}
+	return ids, nil
+}
+
+// errInvalidFilter is returned when a query parameter filter cannot be applied.
+var errInvalidFilter = errors.New("invalid filter")
+
+// minSeverityParam returns the min_severity query parameter, validating
+// that it is a standard severity with a rank.
+func minSeverityParam(r *http.Request) (string, error) {
+	minSeverity := r.URL.Query().Get("min_severity")
+	if minSeverity != "" && valueobjects.Severity(minSeverity).Rank() == 0 {
+		return "", fmt.Errorf("%w: unknown min_severity %q", errInvalidFilter, minSeverity)
+	}
+	return minSeverity, nil
+}
+
+// categoryParam returns the category query parameter, which must name one
+// of the categories the pattern matcher derives.
+func categoryParam(r *http.Request) (string, error) {
+	category := r.URL.Query().Get("category")
+	if category != "" && !valueobjects.Category(category).IsValid() {
+		return "", fmt.Errorf("%w: unknown category %q", errInvalidFilter, category)
+	}
+	return category, nil
+}
+
+// pageSizes returns pagination, or the defaults when it is nil.
+func pageSizes(pagination *queries.Pagination) queries.Pagination {
+	if pagination == nil {
+		return queries.DefaultPagination()
+	}
+	return *pagination
+}
+
+// pinnedParam returns the pinned query parameter, or nil when it is absent.
+func pinnedParam(r *http.Request) (*bool, error) {
+	raw := r.URL.Query().Get("pinned")
+	if raw == "" {
+		return nil, nil
+	}
+	pinned, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: pinned must be true or false", errInvalidFilter)
+	}
+	return &pinned, nil
+}
+
+// includeBodyParam returns the include_body query parameter, or nil (include
+// bodies) when it is absent.
+func includeBodyParam(r *http.Request) (*bool, error) {
+	raw := r.URL.Query().Get("include_body")
+	if raw == "" {
+		return nil, nil
+	}
+	include, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: include_body must be true or false", errInvalidFilter)
+	}
+	return &include, nil
+}
+
+// includeMetadataParam returns the include_metadata query parameter. List
+// responses omit the derived metadata block unless it is true.
+func includeMetadataParam(r *http.Request) (bool, error) {
+	raw := r.URL.Query().Get("include_metadata")
+	if raw == "" {
+		return false, nil
+	}
+	include, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%w: include_metadata must be true or false", errInvalidFilter)
+	}
+	return include, nil
+}
+
+// bodyFieldsParam returns the body.<key> query parameters as body field
+// filters, or nil when there are none.
+func bodyFieldsParam(r *http.Request) (map[string]string, error) {
+	var fields map[string]string
+	for name, values := range r.URL.Query() {
+		key, ok := strings.CutPrefix(name, "body.")
+		if !ok {
+			continue
+		}
+		if !sqlite.ValidBodyField(key) {
+			return nil, fmt.Errorf("%w: invalid body field %q", errInvalidFilter, key)
+		}
+		if fields == nil {
+			fields = make(map[string]string)
+		}
+		fields[key] = values[0]
+	}
+	return fields, nil
+}
+
+// orParam returns the repeated or query parameter as OR'd filters, one per
+// field:value clause, e.g. ?or=severity:error&or=source:payment-service
+// matches logs that are errors or come from payment-service. Clauses can
+// filter on severity, source, color, category, search, title, body_contains
+// and body.<key>; at most sqlite.MaxOrFilters are allowed.
+func orParam(r *http.Request) ([]sqlite.LogFilters, error) {
+	clauses := r.URL.Query()["or"]
+	if len(clauses) > sqlite.MaxOrFilters {
+		return nil, fmt.Errorf("%w: or lists %d clauses, at most %d allowed", errInvalidFilter, len(clauses), sqlite.MaxOrFilters)
+	}
+
+	var filters []sqlite.LogFilters
+	for _, clause := range clauses {
+		field, value, ok := strings.Cut(clause, ":")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: or clause %q must be field:value", errInvalidFilter, clause)
+		}
+
+		var filter sqlite.LogFilters
+		switch field {
+		case "severity":
+			filter.Severity = value
+		case "source":
+			filter.Source = value
+		case "color":
+			filter.Color = value
+		case "category":
+			if !valueobjects.Category(value).IsValid() {
+				return nil, fmt.Errorf("%w: unknown category %q", errInvalidFilter, value)
+			}
+			filter.Category = value
+		case "search":
+			filter.Search = value
+		case "title":
+			filter.TitleSearch = value
+		case "body_contains":
+			filter.BodySearch = value
+		default:
+			key, ok := strings.CutPrefix(field, "body.")
+			if !ok {
+				return nil, fmt.Errorf("%w: or clause %q filters on unsupported field %q", errInvalidFilter, clause, field)
+			}
+			if !sqlite.ValidBodyField(key) {
+				return nil, fmt.Errorf("%w: invalid body field %q", errInvalidFilter, key)
+			}
+			filter.BodyFields = map[string]string{key: value}
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// analyzeParam reports whether pattern matching should run for the request,
+// falling back to the configured default when ?analyze is absent.
+func analyzeParam(r *http.Request, opts *IngestOptions) (bool, error) {
+	raw := r.URL.Query().Get("analyze")
+	if raw == "" {
+		if opts == nil {
+			return DefaultIngestOptions().AutoAnalyze, nil
+		}
+		return opts.AutoAnalyze, nil
+	}
+	analyze, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%w: analyze must be true or false", errInvalidFilter)
+	}
+	return analyze, nil
+}
+
+// highlightParam reports whether the highlight query parameter is set.
+func highlightParam(r *http.Request) (bool, error) {
+	raw := r.URL.Query().Get("highlight")
+	if raw == "" {
+		return false, nil
+	}
+	highlight, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%w: highlight must be true or false", errInvalidFilter)
+	}
+	return highlight, nil
+}
+
+// explainParam reports whether the explain query parameter is set.
+func explainParam(r *http.Request) (bool, error) {
+	raw := r.URL.Query().Get("explain")
+	if raw == "" {
+		return false, nil
+	}
+	explain, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%w: explain must be true or false", errInvalidFilter)
+	}
+	return explain, nil
+}
+
+// timezoneParam returns the location named by the tz query parameter, or
+// UTC when it is absent.
+func timezoneParam(r *http.Request) (*time.Location, error) {
+	name := r.URL.Query().Get("tz")
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown tz %q", errInvalidFilter, name)
+	}
+	return loc, nil
+}
+
+// formatTimestamp renders t as RFC3339 in loc, keeping sub-second precision.
+func formatTimestamp(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(time.RFC3339Nano)
+}
+
+// logToResponse converts a Log entity to a LogResponse, rendering
+// timestamps in loc.
+func logToResponse(log *entities.Log, loc *time.Location) LogResponse {
+	var expiresAt string
+	if log.ExpiresAt != nil {
+		expiresAt = formatTimestamp(*log.ExpiresAt, loc)
+	}
+
 	return LogResponse{
 		ID: log.ID,
 		Header: HeaderResponse{
@@ -281,17 +1070,17 @@ func logToResponse(log *entities.Log) LogResponse {
 			Description: log.Header.Description,
 		},
 		Body: log.Body,
-		Metadata: MetaResponse{
+		Metadata: &MetaResponse{
 			DerivedSeverity: log.Metadata.DerivedSeverity,
 			DerivedSource:   log.Metadata.DerivedSource,
 			DerivedCategory: log.Metadata.DerivedCategory,
+			DerivedColor:    log.Metadata.DerivedColor,
 		},
-		CreatedAt: log.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Pinned:          log.Pinned,
+		AnnotationCount: log.AnnotationCount,
+		CreatedAt:       formatTimestamp(log.CreatedAt, loc),
+		CreatedAtMs:     log.CreatedAt.UnixMilli(),
+		IngestedAt:      formatTimestamp(log.IngestedAt, loc),
+		ExpiresAt:       expiresAt,
 	}
 }
-
-// writeError writes an error response.
-func writeError(w http.ResponseWriter, status int, message string) {
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
-}
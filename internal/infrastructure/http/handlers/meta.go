@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+)
+
+// SeverityEnum describes a standard severity for clients.
+// Order is the display position (least to most severe); Rank is the
+// logical rank used by min_severity filters.
+type SeverityEnum struct {
+	Value string `json:"value"`
+	Rank  int    `json:"rank"`
+	Order int    `json:"order"`
+	Color string `json:"color"`
+}
+
+// EnumsResponse lists the canonical enum values known to the server.
+type EnumsResponse struct {
+	Severities []SeverityEnum `json:"severities"`
+	Categories []string       `json:"categories"`
+	Colors     []string       `json:"colors"`
+	// Palette maps each color to the hex value the dashboard draws it
+	// with; the color filter accepts either.
+	Palette map[string]string `json:"palette"`
+}
+
+// GetEnums handles GET /api/meta/enums.
+func GetEnums(w http.ResponseWriter, r *http.Request) {
+	severities := valueobjects.StandardSeverities()
+	response := EnumsResponse{
+		Severities: make([]SeverityEnum, 0, len(severities)),
+		Categories: make([]string, 0),
+		Colors:     append([]string(nil), valueobjects.ValidColors...),
+		Palette:    make(map[string]string, len(valueobjects.ValidColors)),
+	}
+
+	for i, s := range severities {
+		response.Severities = append(response.Severities, SeverityEnum{
+			Value: s.String(),
+			Rank:  s.Rank(),
+			Order: i + 1,
+			Color: valueobjects.AutoAssignColor(s).String(),
+		})
+	}
+	for _, c := range valueobjects.ValidColors {
+		response.Palette[c] = valueobjects.Color(c).Hex()
+	}
+	for _, c := range valueobjects.AllCategories() {
+		response.Categories = append(response.Categories, c.String())
+	}
+
+	writeJSON(w, r, http.StatusOK, response)
+}
@@ -1,11 +1,21 @@
 package handlers
 
 import (
-	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"regexp"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
+	"unicode/utf8"
+
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
 )
 
 // MetricsData holds collected metrics.
@@ -29,6 +39,142 @@ type MetricsCollector interface {
 
 var startTime = time.Now()
 
+// bodySizeBuckets are the upper bounds, in bytes, of the log body size
+// histogram buckets.
+var bodySizeBuckets = []int64{256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20}
+
+// bodySizes records the serialized body size of every ingested log.
+var bodySizes = newHistogram(bodySizeBuckets)
+
+// ingestedBySeverity counts every ingested log by effective severity.
+var ingestedBySeverity = newSeverityCounter()
+
+// observeIngested records the body sizes and severities of newly stored
+// logs.
+func observeIngested(logs ...*entities.Log) {
+	for _, log := range logs {
+		bodySizes.observe(log.BodySize)
+		ingestedBySeverity.inc(log.EffectiveSeverity())
+	}
+}
+
+// maxCustomSeverityLabels caps the custom severities given their own
+// series, so arbitrary client input cannot grow the output without bound.
+// Further custom severities are counted under overflowSeverityLabel.
+const maxCustomSeverityLabels = 20
+
+// overflowSeverityLabel labels the custom severities past the cap. It is
+// reserved, so a custom severity such as "other" keeps a series of its own;
+// logs sent with this exact severity are counted as overflow.
+const overflowSeverityLabel = "__other__"
+
+// severityCounter is a Prometheus counter with a severity label. The
+// standard severities are always reported, from zero, so rates over them
+// work from the first scrape.
+type severityCounter struct {
+	mu     sync.Mutex
+	counts map[string]uint64
+	custom int // custom severities with their own series
+}
+
+func newSeverityCounter() *severityCounter {
+	c := &severityCounter{counts: make(map[string]uint64)}
+	for _, severity := range valueobjects.StandardSeverities() {
+		c.counts[string(severity)] = 0
+	}
+	return c
+}
+
+// inc counts one log of severity.
+func (c *severityCounter) inc(severity valueobjects.Severity) {
+	label := string(severity)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.counts[label]; !ok && !severity.IsStandard() {
+		if c.custom >= maxCustomSeverityLabels || label == overflowSeverityLabel {
+			label = overflowSeverityLabel
+		} else {
+			c.custom++
+		}
+	}
+	c.counts[label]++
+}
+
+// write writes the counter as name in Prometheus exposition format, one
+// series per severity in sorted order, with the static labels of options.
+func (c *severityCounter) write(w io.Writer, name, help string, options PrometheusOptions) {
+	c.mu.Lock()
+	counts := make(map[string]string, len(c.counts))
+	for severity, count := range c.counts {
+		counts[severity] = formatUint(count)
+	}
+	c.mu.Unlock()
+
+	labels := options.labelSet()
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, severity := range sortedKeys(counts) {
+		set := `{severity="` + escaper.Replace(severity) + `"}`
+		if labels != "" {
+			set = strings.TrimSuffix(labels, "}") + `,severity="` + escaper.Replace(severity) + `"}`
+		}
+		fmt.Fprintf(w, "%s%s %s\n", name, set, counts[severity])
+	}
+}
+
+// histogram is a Prometheus histogram over integer values with fixed
+// bucket upper bounds.
+type histogram struct {
+	mu     sync.Mutex
+	bounds []int64
+	counts []uint64 // per bucket, not cumulative; the last is +Inf
+	sum    int64
+	count  uint64
+}
+
+func newHistogram(bounds []int64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+// observe adds v to the first bucket whose bound is at least v.
+func (h *histogram) observe(v int64) {
+	i := sort.Search(len(h.bounds), func(i int) bool { return h.bounds[i] >= v })
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+// write writes the histogram as name in Prometheus exposition format, with
+// the static labels of options on every series.
+func (h *histogram) write(w io.Writer, name, help string, options PrometheusOptions) {
+	h.mu.Lock()
+	counts := append([]uint64(nil), h.counts...)
+	sum, count := h.sum, h.count
+	h.mu.Unlock()
+
+	labels := options.labelSet()
+	bucketLabels := func(le string) string {
+		if labels == "" {
+			return `{le="` + le + `"}`
+		}
+		return strings.TrimSuffix(labels, "}") + `,le="` + le + `"}`
+	}
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += counts[i]
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, bucketLabels(strconv.FormatInt(bound, 10)), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket%s %d\n", name, bucketLabels("+Inf"), count)
+	fmt.Fprintf(w, "%s_sum%s %d\n", name, labels, sum)
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels, count)
+}
+
 // MetricsHandler handles GET /metrics.
 func MetricsHandler(getMetrics func() (uint64, int64, uint64), sseHub *SSEHub) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -57,13 +203,82 @@ func MetricsHandler(getMetrics func() (uint64, int64, uint64), sseHub *SSEHub) h
 			data.SSEClients = sseHub.ClientCount()
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(data)
+		writeJSON(w, r, http.StatusOK, data)
 	}
 }
 
+// DefaultMetricPrefix is prepended to every Prometheus series name.
+const DefaultMetricPrefix = "scribe_"
+
+var (
+	metricPrefixPattern = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)?$`)
+	labelNamePattern    = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// PrometheusOptions controls how series are named in the Prometheus output.
+type PrometheusOptions struct {
+	// Prefix is prepended to every series name, e.g. "scribe_".
+	Prefix string
+
+	// Labels are static labels added to every series, e.g. instance or env.
+	Labels map[string]string
+}
+
+// DefaultPrometheusOptions returns the default Prometheus options: the
+// "scribe_" prefix and no labels.
+func DefaultPrometheusOptions() PrometheusOptions {
+	return PrometheusOptions{Prefix: DefaultMetricPrefix}
+}
+
+// Validate checks the prefix and labels against the Prometheus naming rules.
+func (o PrometheusOptions) Validate() error {
+	if !metricPrefixPattern.MatchString(o.Prefix) {
+		return fmt.Errorf("invalid metric prefix %q", o.Prefix)
+	}
+	for _, name := range sortedKeys(o.Labels) {
+		if !labelNamePattern.MatchString(name) || strings.HasPrefix(name, "__") {
+			return fmt.Errorf("invalid metric label name %q", name)
+		}
+		if !utf8.ValidString(o.Labels[name]) {
+			return fmt.Errorf("metric label %q value must be valid UTF-8", name)
+		}
+	}
+	return nil
+}
+
+// labelSet renders the labels in Prometheus exposition format, sorted by
+// name, or "" when there are none.
+func (o PrometheusOptions) labelSet() string {
+	if len(o.Labels) == 0 {
+		return ""
+	}
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	pairs := make([]string, 0, len(o.Labels))
+	for _, name := range sortedKeys(o.Labels) {
+		pairs = append(pairs, name+`="`+escaper.Replace(o.Labels[name])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // PrometheusMetricsHandler handles GET /metrics/prometheus.
 func PrometheusMetricsHandler(getMetrics func() (uint64, int64, uint64), sseHub *SSEHub) http.HandlerFunc {
+	return PrometheusMetricsHandlerWithOptions(getMetrics, sseHub, nil)
+}
+
+// PrometheusMetricsHandlerWithOptions handles GET /metrics/prometheus using
+// the naming in opts, which is read on every request. A nil opts uses the
+// defaults.
+func PrometheusMetricsHandlerWithOptions(getMetrics func() (uint64, int64, uint64), sseHub *SSEHub, opts *PrometheusOptions) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		totalReqs, activeReqs, totalErrs := getMetrics()
 
@@ -75,51 +290,38 @@ func PrometheusMetricsHandler(getMetrics func() (uint64, int64, uint64), sseHub
 			sseClients = sseHub.ClientCount()
 		}
 
+		options := DefaultPrometheusOptions()
+		if opts != nil {
+			options = *opts
+		}
+		labels := options.labelSet()
+
+		metrics := []struct {
+			name, help, kind, value string
+		}{
+			{"http_requests_total", "Total number of HTTP requests", "counter", formatUint(totalReqs)},
+			{"http_requests_active", "Current number of active HTTP requests", "gauge", formatInt(activeReqs)},
+			{"http_errors_total", "Total number of HTTP errors (4xx and 5xx)", "counter", formatUint(totalErrs)},
+			{"uptime_seconds", "Server uptime in seconds", "gauge", formatFloat(time.Since(startTime).Seconds())},
+			{"goroutines", "Current number of goroutines", "gauge", formatInt(int64(runtime.NumGoroutine()))},
+			{"memory_bytes", "Current memory usage in bytes", "gauge", formatUint(memStats.Alloc)},
+			{"sse_clients", "Current number of SSE clients", "gauge", formatInt(int64(sseClients))},
+		}
+
 		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 
 		// Prometheus format
-		_, _ = w.Write([]byte("# HELP scribe_http_requests_total Total number of HTTP requests\n"))
-		_, _ = w.Write([]byte("# TYPE scribe_http_requests_total counter\n"))
-		writeMetric(w, "scribe_http_requests_total", totalReqs)
-
-		_, _ = w.Write([]byte("# HELP scribe_http_requests_active Current number of active HTTP requests\n"))
-		_, _ = w.Write([]byte("# TYPE scribe_http_requests_active gauge\n"))
-		writeMetricInt(w, "scribe_http_requests_active", activeReqs)
-
-		_, _ = w.Write([]byte("# HELP scribe_http_errors_total Total number of HTTP errors (4xx and 5xx)\n"))
-		_, _ = w.Write([]byte("# TYPE scribe_http_errors_total counter\n"))
-		writeMetric(w, "scribe_http_errors_total", totalErrs)
-
-		_, _ = w.Write([]byte("# HELP scribe_uptime_seconds Server uptime in seconds\n"))
-		_, _ = w.Write([]byte("# TYPE scribe_uptime_seconds gauge\n"))
-		writeMetricFloat(w, "scribe_uptime_seconds", time.Since(startTime).Seconds())
-
-		_, _ = w.Write([]byte("# HELP scribe_goroutines Current number of goroutines\n"))
-		_, _ = w.Write([]byte("# TYPE scribe_goroutines gauge\n"))
-		writeMetricInt(w, "scribe_goroutines", int64(runtime.NumGoroutine()))
-
-		_, _ = w.Write([]byte("# HELP scribe_memory_bytes Current memory usage in bytes\n"))
-		_, _ = w.Write([]byte("# TYPE scribe_memory_bytes gauge\n"))
-		writeMetric(w, "scribe_memory_bytes", memStats.Alloc)
-
-		_, _ = w.Write([]byte("# HELP scribe_sse_clients Current number of SSE clients\n"))
-		_, _ = w.Write([]byte("# TYPE scribe_sse_clients gauge\n"))
-		writeMetricInt(w, "scribe_sse_clients", int64(sseClients))
+		for _, m := range metrics {
+			name := options.Prefix + m.name
+			_, _ = w.Write([]byte("# HELP " + name + " " + m.help + "\n"))
+			_, _ = w.Write([]byte("# TYPE " + name + " " + m.kind + "\n"))
+			_, _ = w.Write([]byte(name + labels + " " + m.value + "\n"))
+		}
+		bodySizes.write(w, options.Prefix+"log_body_bytes", "Serialized size of ingested log bodies in bytes", options)
+		ingestedBySeverity.write(w, options.Prefix+"logs_ingested_total", "Total number of ingested logs by effective severity", options)
 	}
 }
 
-func writeMetric(w http.ResponseWriter, name string, value uint64) {
-	_, _ = w.Write([]byte(name + " " + formatUint(value) + "\n"))
-}
-
-func writeMetricInt(w http.ResponseWriter, name string, value int64) {
-	_, _ = w.Write([]byte(name + " " + formatInt(value) + "\n"))
-}
-
-func writeMetricFloat(w http.ResponseWriter, name string, value float64) {
-	_, _ = w.Write([]byte(name + " " + formatFloat(value) + "\n"))
-}
-
 func formatUint(v uint64) string {
 	return formatInt(int64(v)) //nolint:gosec // Metrics won't overflow int64
 }
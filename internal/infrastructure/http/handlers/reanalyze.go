@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/application/commands"
+	"github.com/mx-scribe/scribe/internal/application/queries"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// ReanalyzeLogsRequest represents the optional request body of a reanalysis.
+type ReanalyzeLogsRequest struct {
+	DryRun bool `json:"dry_run"`
+}
+
+// ReanalyzeSummary is the final event of a reanalysis.
+type ReanalyzeSummary struct {
+	commands.ReanalyzeProgress
+	DryRun bool   `json:"dry_run"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ReanalyzeLogs handles POST /api/admin/reanalyze.
+func ReanalyzeLogs(db *sqlite.Database) http.HandlerFunc {
+	return ReanalyzeLogsWithOptions(db, nil, nil, nil)
+}
+
+// ReanalyzeLogsWithOptions handles POST /api/admin/reanalyze. It re-runs the
+// pattern matcher (and severity colorizer) in opts over every stored log and
+// updates the derived metadata that changed, streaming "progress" SSE events
+// and a final "summary" event with the counts. With {"dry_run": true} the
+// counts are reported but nothing is written. The run stops when the client
+// disconnects or the request deadline passes; batches already written are
+// kept, and a run cut off by the deadline still ends with a summary of the
+// progress made and an error. Stats broadcast afterwards count their last 24
+// hours by the stats time field in fields.
+func ReanalyzeLogsWithOptions(db *sqlite.Database, hub *SSEHub, opts *IngestOptions, fields *TimeFieldOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req ReanalyzeLogsRequest
+		if r.ContentLength != 0 && !decodeJSONBody(w, r, &req) {
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, r, http.StatusInternalServerError, "streaming unsupported")
+			return
+		}
+
+		// Reanalyzing a large database must not be cut off by the server timeouts
+		rc := http.NewResponseController(w)
+		_ = rc.SetReadDeadline(time.Time{})
+		_ = rc.SetWriteDeadline(time.Time{})
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		ctx := r.Context()
+		repo := sqlite.NewLogRepository(db)
+
+		request := commands.ReanalyzeLogsRequest{DryRun: req.DryRun, Matcher: opts.matcher()}
+		if opts != nil {
+			request.Colorizer = opts.Colorizer
+		}
+
+		response, err := commands.NewReanalyzeLogsHandler(repo).Handle(ctx, request, func(progress commands.ReanalyzeProgress) {
+			sendSSEEvent(w, flusher, SSEEvent{Type: "progress", Data: progress})
+		})
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return
+		}
+
+		summary := ReanalyzeSummary{DryRun: req.DryRun}
+		if response != nil {
+			summary.ReanalyzeProgress = response.ReanalyzeProgress
+		}
+		if timedOut(err) {
+			summary.Error = "reanalysis exceeded the server's operation timeout; batches already written are kept"
+		} else if err != nil {
+			summary.Error = err.Error()
+		}
+
+		// Broadcast refreshed stats to SSE clients if hub is available; a run
+		// that timed out may still have written batches
+		if hub != nil && !req.DryRun && summary.Changed > 0 {
+			statsCtx := context.WithoutCancel(ctx)
+			if stats, err := queries.NewGetStatsHandler(statsRepository(db, fields)).HandleContext(statsCtx); err == nil {
+				hub.BroadcastStatsUpdatedContext(statsCtx, stats)
+			}
+		}
+
+		sendSSEEvent(w, flusher, SSEEvent{Type: "summary", Data: summary})
+	}
+}
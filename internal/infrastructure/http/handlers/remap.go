@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mx-scribe/scribe/internal/application/queries"
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// RemapSeverityRequest represents the request body for reclassifying a source.
+type RemapSeverityRequest struct {
+	Source       string `json:"source"`
+	FromSeverity string `json:"from_severity"`
+	ToSeverity   string `json:"to_severity"`
+}
+
+// RemapSeverityResponse reports how many logs were reclassified.
+type RemapSeverityResponse struct {
+	Source       string `json:"source"`
+	FromSeverity string `json:"from_severity"`
+	ToSeverity   string `json:"to_severity"`
+	Updated      int64  `json:"updated"`
+}
+
+// RemapSeverity handles POST /api/admin/remap.
+func RemapSeverity(db *sqlite.Database) http.HandlerFunc {
+	return RemapSeverityWithSSE(db, nil)
+}
+
+// RemapSeverityWithSSE handles POST /api/admin/remap with SSE broadcast support.
+// It changes the severity of every log from a source whose effective severity
+// (the derived severity when set, otherwise the stored one) is from_severity
+// to to_severity. Both must be standard severities.
+func RemapSeverityWithSSE(db *sqlite.Database, hub *SSEHub) http.HandlerFunc {
+	return RemapSeverityWithOptions(db, hub, nil)
+}
+
+// RemapSeverityWithOptions handles POST /api/admin/remap like
+// RemapSeverityWithSSE, counting the broadcast stats' last 24 hours by the
+// stats time field in opts.
+func RemapSeverityWithOptions(db *sqlite.Database, hub *SSEHub, opts *TimeFieldOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req RemapSeverityRequest
+		if !decodeJSONBody(w, r, &req) {
+			return
+		}
+
+		req.Source = strings.TrimSpace(req.Source)
+		req.FromSeverity = strings.TrimSpace(req.FromSeverity)
+		req.ToSeverity = strings.TrimSpace(req.ToSeverity)
+
+		if req.Source == "" || req.FromSeverity == "" || req.ToSeverity == "" {
+			writeError(w, r, http.StatusBadRequest, "source, from_severity and to_severity are required")
+			return
+		}
+		for _, severity := range []string{req.FromSeverity, req.ToSeverity} {
+			if !valueobjects.Severity(severity).IsStandard() {
+				writeError(w, r, http.StatusBadRequest, fmt.Sprintf("unknown severity %q", severity))
+				return
+			}
+		}
+		if req.FromSeverity == req.ToSeverity {
+			writeError(w, r, http.StatusBadRequest, "from_severity and to_severity must differ")
+			return
+		}
+
+		repo := sqlite.NewLogRepository(db)
+		updated, err := repo.RemapSeverityContext(r.Context(), req.Source, req.FromSeverity, req.ToSeverity)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		// Broadcast refreshed stats to SSE clients if hub is available
+		if hub != nil && updated > 0 {
+			if stats, err := queries.NewGetStatsHandler(statsRepository(db, opts)).HandleContext(r.Context()); err == nil {
+				hub.BroadcastStatsUpdatedContext(r.Context(), stats)
+			}
+		}
+
+		writeJSON(w, r, http.StatusOK, RemapSeverityResponse{
+			Source:       req.Source,
+			FromSeverity: req.FromSeverity,
+			ToSeverity:   req.ToSeverity,
+			Updated:      updated,
+		})
+	}
+}
@@ -1,22 +1,48 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 )
 
-// JSONResponse sends a JSON response with the given status code.
-func JSONResponse(w http.ResponseWriter, statusCode int, data any) {
+// writeJSON writes data as a JSON response with the given status code and
+// sets the Content-Type header. Output is compact unless the request asks for
+// ?pretty=true, in which case it is indented for reading. r may be nil.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, data any) {
+	var body []byte
+	var err error
+	if wantsPrettyJSON(r) {
+		body, err = json.MarshalIndent(data, "", "  ")
+	} else {
+		body, err = json.Marshal(data)
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error":{"code":"internal_error","message":"failed to encode response"}}` + "\n"))
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	_ = json.NewEncoder(w).Encode(data)
+	w.WriteHeader(status)
+	_, _ = w.Write(append(body, '\n'))
 }
 
-// ErrorResponse sends an error response with the given status code.
-func ErrorResponse(w http.ResponseWriter, statusCode int, message string) {
-	JSONResponse(w, statusCode, map[string]string{
-		"error": message,
-	})
+// wantsPrettyJSON reports whether the request opted into indented JSON.
+func wantsPrettyJSON(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+	pretty, _ := strconv.ParseBool(r.URL.Query().Get("pretty"))
+	return pretty
+}
+
+// JSONResponse sends a JSON response with the given status code.
+func JSONResponse(w http.ResponseWriter, statusCode int, data any) {
+	writeJSON(w, nil, statusCode, data)
 }
 
 // SuccessResponse sends a 200 OK response with the given data.
@@ -28,3 +54,39 @@ func SuccessResponse(w http.ResponseWriter, data any) {
 func CreatedResponse(w http.ResponseWriter, data any) {
 	JSONResponse(w, http.StatusCreated, data)
 }
+
+// decodeJSON decodes data into v like json.Unmarshal, except that numbers in
+// untyped values such as log bodies are decoded as json.Number, keeping
+// large integers exact.
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+// decodeJSONBody decodes the request body into v, keeping numbers in untyped
+// values as json.Number. On failure it writes a 413 if the body exceeded its
+// size limit, or a 400 otherwise, and returns false.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	err := dec.Decode(v)
+	if err == nil {
+		return true
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeError(w, r, http.StatusRequestEntityTooLarge, "request body too large")
+		return false
+	}
+
+	writeError(w, r, http.StatusBadRequest, "invalid request body")
+	return false
+}
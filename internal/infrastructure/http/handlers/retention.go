@@ -1,8 +1,10 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
@@ -12,6 +14,8 @@ import (
 type RetentionConfig struct {
 	// RetentionDays is the number of days to keep logs (0 = keep forever)
 	RetentionDays int `json:"retention_days"`
+	// DryRun reports what would be deleted without deleting anything
+	DryRun bool `json:"dry_run,omitempty"`
 }
 
 // RetentionStats represents the result of a cleanup operation.
@@ -19,29 +23,67 @@ type RetentionStats struct {
 	DeletedCount int64  `json:"deleted_count"`
 	CutoffDate   string `json:"cutoff_date"`
 	Message      string `json:"message"`
+	DryRun       bool   `json:"dry_run"`
 }
 
 // CleanupLogs handles POST /api/admin/cleanup.
-// Deletes logs older than the specified retention period.
+// Deletes logs older than the specified retention period, or only counts them when dry_run is set.
 func CleanupLogs(db *sqlite.Database) http.HandlerFunc {
+	return CleanupLogsWithOptions(db, nil)
+}
+
+// CleanupLogsWithOptions handles POST /api/admin/cleanup, judging log age by
+// the retention time field in opts. A nil opts uses the defaults. A cleanup
+// that runs past the request deadline is rolled back and answered with 504.
+func CleanupLogsWithOptions(db *sqlite.Database, opts *TimeFieldOptions) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var config RetentionConfig
-		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
-			writeError(w, http.StatusBadRequest, "invalid request body")
+		if !decodeJSONBody(w, r, &config) {
 			return
 		}
 
 		if config.RetentionDays <= 0 {
-			writeError(w, http.StatusBadRequest, "retention_days must be greater than 0")
+			writeError(w, r, http.StatusBadRequest, "retention_days must be greater than 0")
 			return
 		}
 
 		cutoffDate := time.Now().AddDate(0, 0, -config.RetentionDays)
 
-		repo := sqlite.NewLogRepository(db)
-		deleted, err := repo.DeleteOlderThan(cutoffDate)
+		repo := sqlite.NewLogRepository(db).WithTimeField(timeFields(opts).Retention)
+
+		if config.DryRun {
+			count, err := repo.CountDeletableContext(r.Context(), cutoffDate)
+			if timedOut(err) {
+				writeError(w, r, http.StatusGatewayTimeout, "cleanup dry run exceeded the server's operation timeout")
+				return
+			}
+			if err != nil {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+				return
+			}
+
+			response := RetentionStats{
+				DeletedCount: int64(count),
+				CutoffDate:   cutoffDate.Format(time.RFC3339),
+				Message:      fmt.Sprintf("Dry run: %d logs would be deleted, nothing was removed", count),
+				DryRun:       true,
+			}
+
+			writeJSON(w, r, http.StatusOK, response)
+			return
+		}
+
+		deleted, err := repo.DeleteOlderThanContext(r.Context(), cutoffDate)
+		if timedOut(err) {
+			// The delete is a single statement, so nothing was removed
+			writeErrorDetails(w, r, http.StatusGatewayTimeout, "cleanup exceeded the server's operation timeout; no logs were deleted", RetentionStats{
+				CutoffDate: cutoffDate.Format(time.RFC3339),
+				Message:    "Cleanup timed out and was rolled back",
+			})
+			return
+		}
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusInternalServerError, err.Error())
 			return
 		}
 
@@ -51,90 +93,146 @@ func CleanupLogs(db *sqlite.Database) http.HandlerFunc {
 			Message:      "Cleanup completed successfully",
 		}
 
-		_ = json.NewEncoder(w).Encode(response)
+		writeJSON(w, r, http.StatusOK, response)
 	}
 }
 
+// AgeBucket reports how many logs fall beyond a retention boundary.
+type AgeBucket struct {
+	Bucket     string `json:"bucket"`
+	CutoffDate string `json:"cutoff_date"`
+	OlderThan  int    `json:"older_than"`
+	Remaining  int    `json:"remaining"`
+}
+
+// maxAgeBuckets limits how many custom buckets a single request may ask for.
+const maxAgeBuckets = 20
+
 // GetRetentionInfo handles GET /api/admin/retention.
-// Returns information about log age distribution.
+// Returns information about log age distribution. Custom boundaries can be
+// requested with ?buckets=1d,7d,30d to preview the impact of a retention change.
+// The today and yesterday buckets follow the day boundaries of ?tz=, UTC by
+// default.
 func GetRetentionInfo(db *sqlite.Database) http.HandlerFunc {
+	return GetRetentionInfoWithOptions(db, nil)
+}
+
+// GetRetentionInfoWithOptions handles GET /api/admin/retention, judging log
+// age by the retention time field in opts. A nil opts uses the defaults.
+func GetRetentionInfoWithOptions(db *sqlite.Database, opts *TimeFieldOptions) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		repo := sqlite.NewLogRepository(db)
+		var customBuckets []string
+		var bucketAges []time.Duration
+		if v := r.URL.Query().Get("buckets"); v != "" {
+			for _, part := range strings.Split(v, ",") {
+				part = strings.TrimSpace(part)
+				age, err := parseDuration(part)
+				if err != nil || age <= 0 {
+					writeError(w, r, http.StatusBadRequest, "invalid bucket: "+part)
+					return
+				}
+				customBuckets = append(customBuckets, part)
+				bucketAges = append(bucketAges, age)
+			}
+			if len(customBuckets) > maxAgeBuckets {
+				writeError(w, r, http.StatusBadRequest, "too many buckets")
+				return
+			}
+		}
 
-		total, err := repo.Count()
+		loc, err := timezoneParam(r)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		last24h, err := repo.CountLast24Hours()
+		fields := timeFields(opts)
+		now := fields.now()
+		repo := sqlite.NewLogRepository(db).WithTimeField(fields.Retention)
+
+		total, err := repo.CountContext(r.Context())
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusInternalServerError, err.Error())
 			return
 		}
 
-		// Get counts by age buckets
-		ageBuckets, err := getLogAgeBuckets(db)
+		last24h, err := statsRepository(db, opts).CountLast24HoursContext(r.Context())
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusInternalServerError, err.Error())
 			return
 		}
 
 		response := map[string]any{
 			"total":         total,
 			"last_24_hours": last24h,
-			"by_age":        ageBuckets,
 		}
 
-		_ = json.NewEncoder(w).Encode(response)
+		if len(customBuckets) > 0 {
+			buckets, err := getCustomAgeBuckets(r.Context(), repo, now, total, customBuckets, bucketAges)
+			if err != nil {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+				return
+			}
+			response["buckets"] = buckets
+		} else {
+			ageBuckets, err := getLogAgeBuckets(r.Context(), repo, now, loc)
+			if err != nil {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+				return
+			}
+			response["by_age"] = ageBuckets
+		}
+
+		writeJSON(w, r, http.StatusOK, response)
 	}
 }
 
-// getLogAgeBuckets returns log counts grouped by age.
-func getLogAgeBuckets(db *sqlite.Database) (map[string]int, error) {
-	now := time.Now()
-	buckets := map[string]int{
-		"today":      0,
-		"yesterday":  0,
-		"last_week":  0,
-		"last_month": 0,
-		"older":      0,
+// getLogAgeBuckets returns log counts grouped by age, splitting today from
+// yesterday at midnight in loc.
+func getLogAgeBuckets(ctx context.Context, repo *sqlite.LogRepository, now time.Time, loc *time.Location) (map[string]int, error) {
+	local := now.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	// Hand the repository the boundaries in now's location, like the other cutoffs
+	today := midnight.In(now.Location())
+	yesterday := midnight.AddDate(0, 0, -1).In(now.Location())
+	weekAgo := now.AddDate(0, 0, -7)
+	monthAgo := now.AddDate(0, -1, 0)
+
+	// Each bucket is the difference between two cumulative "older than" counts
+	older, err := repo.CountOlderThanContext(ctx, []time.Time{now, today, yesterday, weekAgo, monthAgo})
+	if err != nil {
+		return nil, err
 	}
 
-	// Query for each bucket
-	queries := []struct {
-		bucket string
-		from   time.Time
-		to     time.Time
-	}{
-		{"today", now.Truncate(24 * time.Hour), now},
-		{"yesterday", now.Truncate(24 * time.Hour).Add(-24 * time.Hour), now.Truncate(24 * time.Hour)},
-		{"last_week", now.AddDate(0, 0, -7), now.Truncate(24 * time.Hour).Add(-24 * time.Hour)},
-		{"last_month", now.AddDate(0, -1, 0), now.AddDate(0, 0, -7)},
-	}
+	return map[string]int{
+		"today":      older[0] - older[1],
+		"yesterday":  older[1] - older[2],
+		"last_week":  older[2] - older[3],
+		"last_month": older[3] - older[4],
+		"older":      older[4],
+	}, nil
+}
 
-	for _, q := range queries {
-		var count int
-		err := db.Conn().QueryRow(
-			"SELECT COUNT(*) FROM logs WHERE created_at >= ? AND created_at < ?",
-			q.from, q.to,
-		).Scan(&count)
-		if err != nil {
-			return nil, err
-		}
-		buckets[q.bucket] = count
+// getCustomAgeBuckets returns, for each requested age, how many logs are older than it.
+func getCustomAgeBuckets(ctx context.Context, repo *sqlite.LogRepository, now time.Time, total int, labels []string, ages []time.Duration) ([]AgeBucket, error) {
+	cutoffs := make([]time.Time, len(ages))
+	for i, age := range ages {
+		cutoffs[i] = now.Add(-age)
 	}
 
-	// Older than a month
-	var olderCount int
-	err := db.Conn().QueryRow(
-		"SELECT COUNT(*) FROM logs WHERE created_at < ?",
-		now.AddDate(0, -1, 0),
-	).Scan(&olderCount)
+	counts, err := repo.CountOlderThanContext(ctx, cutoffs)
 	if err != nil {
 		return nil, err
 	}
-	buckets["older"] = olderCount
 
+	buckets := make([]AgeBucket, len(labels))
+	for i, label := range labels {
+		buckets[i] = AgeBucket{
+			Bucket:     label,
+			CutoffDate: cutoffs[i].Format(time.RFC3339),
+			OlderThan:  counts[i],
+			Remaining:  total - counts[i],
+		}
+	}
 	return buckets, nil
 }
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"sync/atomic"
+
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+)
+
+// SeverityFloor turns away logs whose effective severity ranks below a
+// minimum at ingestion, so noisy low-severity logs are never stored.
+type SeverityFloor struct {
+	min     valueobjects.Severity
+	drop    bool
+	dropped atomic.Int64
+}
+
+// NewSeverityFloor creates a floor admitting logs ranked at or above min.
+// When drop is true, logs below it are dropped and counted instead of
+// rejected. Returns nil, meaning no floor, when min is not a standard
+// severity.
+func NewSeverityFloor(min string, drop bool) *SeverityFloor {
+	severity := valueobjects.Severity(min)
+	if severity.Rank() == 0 {
+		return nil
+	}
+	return &SeverityFloor{min: severity, drop: drop}
+}
+
+// Allow reports whether a log with the given effective severity may be
+// stored. Custom severities have no rank and are always allowed.
+func (f *SeverityFloor) Allow(severity valueobjects.Severity) bool {
+	if severity.Rank() == 0 || severity.AtLeast(f.min) {
+		return true
+	}
+	if f.drop {
+		f.dropped.Add(1)
+	}
+	return false
+}
+
+// Min returns the lowest severity admitted.
+func (f *SeverityFloor) Min() valueobjects.Severity {
+	return f.min
+}
+
+// Drops reports whether logs below the floor are dropped rather than
+// rejected.
+func (f *SeverityFloor) Drops() bool {
+	return f.drop
+}
+
+// Dropped returns the number of logs dropped for being below the floor.
+func (f *SeverityFloor) Dropped() int64 {
+	return f.dropped.Load()
+}
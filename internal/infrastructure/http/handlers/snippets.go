@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html"
+	"unicode"
+
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+)
+
+const (
+	// snippetContext is the number of characters kept on each side of a match.
+	snippetContext = 40
+
+	snippetMarkOpen  = "<mark>"
+	snippetMarkClose = "</mark>"
+	snippetEllipsis  = "…"
+)
+
+// matchSnippet returns a short window around the first occurrence of term in
+// the log's title, description or body, with the match wrapped in <mark>
+// tags. The log text around the tags is HTML-escaped, so the snippet is safe
+// to render as markup. Matching is case-insensitive, like the SQL LIKE
+// search. It returns an empty string when term does not occur in any of
+// those fields.
+func matchSnippet(log *entities.Log, term string) string {
+	if term == "" {
+		return ""
+	}
+
+	fields := []string{log.Header.Title, log.Header.Description}
+	if len(log.Body) > 0 {
+		if body, err := json.Marshal(log.Body); err == nil {
+			fields = append(fields, string(body))
+		}
+	}
+
+	for _, field := range fields {
+		if snippet, ok := snippetAround(field, term); ok {
+			return snippet
+		}
+	}
+	return ""
+}
+
+// snippetAround builds the marked snippet for the first case-insensitive
+// occurrence of term in text, escaping each text segment on its own so only
+// the <mark> tags are markup.
+func snippetAround(text, term string) (string, bool) {
+	runes := []rune(text)
+	start := indexFold(runes, []rune(term))
+	if start < 0 {
+		return "", false
+	}
+	end := start + len([]rune(term))
+
+	from := max(start-snippetContext, 0)
+	to := min(end+snippetContext, len(runes))
+
+	snippet := ""
+	if from > 0 {
+		snippet += snippetEllipsis
+	}
+	snippet += html.EscapeString(string(runes[from:start])) +
+		snippetMarkOpen + html.EscapeString(string(runes[start:end])) + snippetMarkClose +
+		html.EscapeString(string(runes[end:to]))
+	if to < len(runes) {
+		snippet += snippetEllipsis
+	}
+	return snippet, true
+}
+
+// indexFold returns the rune index of the first case-insensitive occurrence
+// of needle in haystack, or -1 if there is none.
+func indexFold(haystack, needle []rune) int {
+	if len(needle) == 0 || len(needle) > len(haystack) {
+		return -1
+	}
+
+outer:
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		for j, r := range needle {
+			if unicode.ToLower(haystack[i+j]) != unicode.ToLower(r) {
+				continue outer
+			}
+		}
+		return i
+	}
+	return -1
+}
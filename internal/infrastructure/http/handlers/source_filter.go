@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// SourceFilter turns away logs from sources that are not approved at
+// ingestion, so rogue agents cannot pollute the store. Patterns match a
+// source exactly, or with * standing for any run of characters, e.g.
+// "internal-*".
+type SourceFilter struct {
+	allowed []string
+	denied  []string
+	drop    bool
+	dropped atomic.Int64
+}
+
+// NewSourceFilter creates a filter admitting sources that match a pattern
+// of allowed, or any source when allowed is empty, unless they match a
+// pattern of denied. When drop is true, other logs are dropped and counted
+// instead of rejected. Returns nil, meaning no filter, when both lists are
+// empty.
+func NewSourceFilter(allowed, denied []string, drop bool) *SourceFilter {
+	if len(allowed) == 0 && len(denied) == 0 {
+		return nil
+	}
+	return &SourceFilter{allowed: allowed, denied: denied, drop: drop}
+}
+
+// Allow reports whether a log with the given effective source may be
+// stored. Denied patterns take precedence over allowed ones.
+func (f *SourceFilter) Allow(source string) bool {
+	if !matchesAnySource(f.denied, source) && (len(f.allowed) == 0 || matchesAnySource(f.allowed, source)) {
+		return true
+	}
+	if f.drop {
+		f.dropped.Add(1)
+	}
+	return false
+}
+
+// Drops reports whether logs from disallowed sources are dropped rather
+// than rejected.
+func (f *SourceFilter) Drops() bool {
+	return f.drop
+}
+
+// Dropped returns the number of logs dropped for their source.
+func (f *SourceFilter) Dropped() int64 {
+	return f.dropped.Load()
+}
+
+// matchesAnySource reports whether source matches one of patterns.
+func matchesAnySource(patterns []string, source string) bool {
+	for _, pattern := range patterns {
+		if matchSource(pattern, source) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSource reports whether source matches pattern, where * matches any
+// run of characters, including none.
+func matchSource(pattern, source string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return source == pattern
+	}
+
+	rest, ok := strings.CutPrefix(source, parts[0])
+	if !ok {
+		return false
+	}
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	return strings.HasSuffix(rest, last)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sourceBucketIdle is how long a full bucket may sit unused before it is
+// evicted, keeping memory bounded when sources come and go.
+const sourceBucketIdle = 5 * time.Minute
+
+// SourceLimiter caps ingestion per log source with one token bucket per
+// source, so a single noisy source cannot starve the others.
+type SourceLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	drop      bool
+	buckets   map[string]*sourceBucket
+	dropped   map[string]int64
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type sourceBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewSourceLimiter creates a limiter allowing perSecond logs per source, with
+// bursts of up to burst logs. A burst below 1 defaults to perSecond (at
+// least 1). When drop is true, excess logs are dropped and counted instead
+// of rejected. Returns nil, meaning no limit, when perSecond is not positive.
+func NewSourceLimiter(perSecond float64, burst int, drop bool) *SourceLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	b := float64(burst)
+	if b < 1 {
+		b = math.Max(1, math.Ceil(perSecond))
+	}
+	return &SourceLimiter{
+		rate:    perSecond,
+		burst:   b,
+		drop:    drop,
+		buckets: make(map[string]*sourceBucket),
+		dropped: make(map[string]int64),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token for source, reporting whether the log may be stored.
+// When it returns false, retryAfter is how long until a token is available.
+func (l *SourceLimiter) Allow(source string) (ok bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, exists := l.buckets[source]
+	if !exists {
+		bucket = &sourceBucket{tokens: l.burst, last: now}
+		l.buckets[source] = bucket
+	}
+
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		if l.drop {
+			l.dropped[source]++
+		}
+		wait := (1 - bucket.tokens) / l.rate
+		return false, time.Duration(wait * float64(time.Second))
+	}
+
+	bucket.tokens--
+	return true, 0
+}
+
+// Drops reports whether excess logs are dropped rather than rejected.
+func (l *SourceLimiter) Drops() bool {
+	return l.drop
+}
+
+// Dropped returns the number of logs dropped per source.
+func (l *SourceLimiter) Dropped() map[string]int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	counts := make(map[string]int64, len(l.dropped))
+	for source, n := range l.dropped {
+		counts[source] = n
+	}
+	return counts
+}
+
+// sweep evicts buckets that have been idle long enough to be full again.
+// Callers must hold l.mu.
+func (l *SourceLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sourceBucketIdle {
+		return
+	}
+	l.lastSweep = now
+	for source, bucket := range l.buckets {
+		if now.Sub(bucket.last) >= sourceBucketIdle {
+			delete(l.buckets, source)
+		}
+	}
+}
@@ -1,35 +1,81 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
 )
 
-// SSEHub manages Server-Sent Events connections.
+// DefaultCoalesceInterval is how often coalesced log_created events are flushed.
+const DefaultCoalesceInterval = 200 * time.Millisecond
+
+const (
+	// replayMaxEvents bounds both the persisted event table and the events
+	// replayed to one reconnecting client.
+	replayMaxEvents = 1000
+
+	// replayPruneInterval is the most often persisted events are pruned.
+	replayPruneInterval = time.Minute
+)
+
+// SSEHub manages Server-Sent Events connections. Events are only delivered
+// to clients of the tenant they were broadcast for.
+//
+// When coalescing is enabled and more than the threshold of log_created
+// events arrive within one second, further log_created events that second
+// are held and flushed together as one logs_created_batch event.
 type SSEHub struct {
-	clients    map[chan SSEEvent]bool
-	register   chan chan SSEEvent
+	clients    map[chan SSEEvent]string
+	register   chan sseClient
 	unregister chan chan SSEEvent
 	broadcast  chan SSEEvent
 	mu         sync.RWMutex
+
+	coalesceThreshold int
+	coalesceInterval  time.Duration
+
+	events    *sqlite.EventRepository
+	replayTTL time.Duration
+	lastPrune time.Time
 }
 
-// SSEEvent represents an event sent to clients.
+// SSEEvent represents an event sent to clients. ID is set, and sent as the
+// SSE event id, only when event persistence is enabled. Tenant is the
+// tenant whose clients receive the event.
 type SSEEvent struct {
-	Type string `json:"type"`
-	Data any    `json:"data"`
+	ID     int64  `json:"-"`
+	Tenant string `json:"-"`
+	Type   string `json:"type"`
+	Data   any    `json:"data"`
+}
+
+// sseClient is a connection registering with the hub: the channel it
+// receives events on and the tenant it receives them for.
+type sseClient struct {
+	events chan SSEEvent
+	tenant string
+}
+
+// subscribe registers a client for the tenant of ctx, receiving events on a
+// channel buffering size events. The caller must unregister it.
+func (h *SSEHub) subscribe(ctx context.Context, size int) chan SSEEvent {
+	client := make(chan SSEEvent, size)
+	h.register <- sseClient{events: client, tenant: sqlite.TenantFromContext(ctx)}
+	return client
 }
 
 // NewSSEHub creates a new SSE hub.
 func NewSSEHub() *SSEHub {
 	hub := &SSEHub{
-		clients:    make(map[chan SSEEvent]bool),
-		register:   make(chan chan SSEEvent),
+		clients:    make(map[chan SSEEvent]string),
+		register:   make(chan sseClient),
 		unregister: make(chan chan SSEEvent),
 		broadcast:  make(chan SSEEvent, 100),
 	}
@@ -37,13 +83,129 @@ func NewSSEHub() *SSEHub {
 	return hub
 }
 
+// SetCoalescing enables batching of log_created events once more than
+// threshold arrive within a second, flushing every interval. A threshold of
+// zero or less disables coalescing.
+func (h *SSEHub) SetCoalescing(threshold int, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultCoalesceInterval
+	}
+	h.mu.Lock()
+	h.coalesceThreshold = threshold
+	h.coalesceInterval = interval
+	h.mu.Unlock()
+}
+
+// coalescing returns the current coalescing settings.
+func (h *SSEHub) coalescing() (int, time.Duration) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.coalesceThreshold, h.coalesceInterval
+}
+
+// SetEventPersistence stores every broadcast event in events and keeps it
+// for ttl, so SSE clients reconnecting with a Last-Event-ID header are sent
+// what they missed, even after a restart. The newest events are replayed
+// from the table on reconnect; a nil events disables persistence.
+func (h *SSEHub) SetEventPersistence(events *sqlite.EventRepository, ttl time.Duration) {
+	h.mu.Lock()
+	h.events = events
+	h.replayTTL = ttl
+	h.mu.Unlock()
+}
+
+// eventStore returns the event repository, or nil when events are not
+// persisted.
+func (h *SSEHub) eventStore() *sqlite.EventRepository {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.events
+}
+
+// persist stores event when persistence is enabled, returning it with its
+// ID set, and prunes expired events from time to time. Events that fail to
+// store are still delivered, just without an ID.
+func (h *SSEHub) persist(event SSEEvent) SSEEvent {
+	h.mu.RLock()
+	events, ttl := h.events, h.replayTTL
+	h.mu.RUnlock()
+	if events == nil {
+		return event
+	}
+
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return event
+	}
+	ctx := context.Background()
+	now := time.Now()
+	if id, err := events.AppendContext(sqlite.ContextWithTenant(ctx, event.Tenant), event.Type, data, now); err == nil {
+		event.ID = id
+	}
+
+	if now.Sub(h.lastPrune) >= min(ttl, replayPruneInterval) {
+		h.lastPrune = now
+		_, _ = events.PruneContext(ctx, now.Add(-ttl), replayMaxEvents)
+	}
+	return event
+}
+
+// replay passes send the persisted events of the tenant of ctx after
+// lastEventID, with their data still JSON-encoded, returning the ID of the
+// last one (or lastEventID when there were none).
+func (h *SSEHub) replay(ctx context.Context, lastEventID int64, send func(SSEEvent)) int64 {
+	events := h.eventStore()
+	if events == nil {
+		return lastEventID
+	}
+
+	stored, err := events.SinceContext(ctx, lastEventID, replayMaxEvents)
+	if err != nil {
+		return lastEventID
+	}
+	for _, event := range stored {
+		send(SSEEvent{ID: event.ID, Type: event.Type, Data: event.Data})
+		lastEventID = event.ID
+	}
+	return lastEventID
+}
+
 // run processes hub events.
 func (h *SSEHub) run() {
+	var (
+		windowStart time.Time
+		windowCount int
+		pending     []SSEEvent
+		flush       <-chan time.Time
+	)
+
+	// Held events are batched per tenant, in the order tenants first appear
+	flushPending := func() {
+		var tenants []string
+		batches := make(map[string][]any)
+		for _, event := range pending {
+			if _, ok := batches[event.Tenant]; !ok {
+				tenants = append(tenants, event.Tenant)
+			}
+			batches[event.Tenant] = append(batches[event.Tenant], event.Data)
+		}
+		for _, tenant := range tenants {
+			logs := batches[tenant]
+			h.deliver(SSEEvent{
+				Tenant: tenant,
+				Type:   "logs_created_batch",
+				Data:   map[string]any{"count": len(logs), "logs": logs},
+			})
+		}
+		pending = nil
+		flush = nil
+	}
+
 	for {
 		select {
 		case client := <-h.register:
 			h.mu.Lock()
-			h.clients[client] = true
+			h.clients[client.events] = client.tenant
 			h.mu.Unlock()
 
 		case client := <-h.unregister:
@@ -55,39 +217,107 @@ func (h *SSEHub) run() {
 			h.mu.Unlock()
 
 		case event := <-h.broadcast:
-			h.mu.RLock()
-			for client := range h.clients {
-				select {
-				case client <- event:
-				default:
+			if event.Type == "log_created" {
+				threshold, interval := h.coalescing()
+				now := time.Now()
+				if now.Sub(windowStart) >= time.Second {
+					windowStart = now
+					windowCount = 0
+				}
+				windowCount++
+
+				if threshold > 0 && windowCount > threshold {
+					pending = append(pending, event)
+					if flush == nil {
+						flush = time.After(interval)
+					}
+					continue
 				}
 			}
-			h.mu.RUnlock()
+
+			// Keep ordering: anything held back goes out before this event
+			flushPending()
+			h.deliver(event)
+
+		case <-flush:
+			flushPending()
 		}
 	}
 }
 
-// BroadcastLogCreated sends a log created event to all clients.
+// deliver persists event if enabled, then sends it to every client of its
+// tenant, dropping it for clients that are full.
+func (h *SSEHub) deliver(event SSEEvent) {
+	event = h.persist(event)
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client, tenant := range h.clients {
+		if tenant != event.Tenant {
+			continue
+		}
+		select {
+		case client <- event:
+		default:
+		}
+	}
+}
+
+// BroadcastLogCreated sends a log created event to all clients of the
+// default tenant.
 func (h *SSEHub) BroadcastLogCreated(log *entities.Log) {
+	h.BroadcastLogCreatedContext(context.Background(), log)
+}
+
+// BroadcastLogCreatedContext sends a log created event to all clients of the
+// tenant of ctx.
+func (h *SSEHub) BroadcastLogCreatedContext(ctx context.Context, log *entities.Log) {
 	h.broadcast <- SSEEvent{
-		Type: "log_created",
-		Data: logToSSEResponse(log),
+		Tenant: sqlite.TenantFromContext(ctx),
+		Type:   "log_created",
+		Data:   logToSSEResponse(log),
 	}
 }
 
-// BroadcastLogDeleted sends a log deleted event to all clients.
+// BroadcastLogEscalatedContext sends a log escalated event to all clients
+// of the tenant of ctx.
+func (h *SSEHub) BroadcastLogEscalatedContext(ctx context.Context, escalation LogEscalation) {
+	h.broadcast <- SSEEvent{
+		Tenant: sqlite.TenantFromContext(ctx),
+		Type:   "log_escalated",
+		Data:   escalation,
+	}
+}
+
+// BroadcastLogDeleted sends a log deleted event to all clients of the
+// default tenant.
 func (h *SSEHub) BroadcastLogDeleted(id int64) {
+	h.BroadcastLogDeletedContext(context.Background(), id)
+}
+
+// BroadcastLogDeletedContext sends a log deleted event to all clients of the
+// tenant of ctx.
+func (h *SSEHub) BroadcastLogDeletedContext(ctx context.Context, id int64) {
 	h.broadcast <- SSEEvent{
-		Type: "log_deleted",
-		Data: map[string]int64{"id": id},
+		Tenant: sqlite.TenantFromContext(ctx),
+		Type:   "log_deleted",
+		Data:   map[string]int64{"id": id},
 	}
 }
 
-// BroadcastStatsUpdated sends a stats updated event to all clients.
+// BroadcastStatsUpdated sends a stats updated event to all clients of the
+// default tenant.
 func (h *SSEHub) BroadcastStatsUpdated(stats any) {
+	h.BroadcastStatsUpdatedContext(context.Background(), stats)
+}
+
+// BroadcastStatsUpdatedContext sends a stats updated event to all clients
+// of the tenant of ctx.
+func (h *SSEHub) BroadcastStatsUpdatedContext(ctx context.Context, stats any) {
 	h.broadcast <- SSEEvent{
-		Type: "stats_updated",
-		Data: stats,
+		Tenant: sqlite.TenantFromContext(ctx),
+		Type:   "stats_updated",
+		Data:   stats,
 	}
 }
 
@@ -98,7 +328,10 @@ func (h *SSEHub) ClientCount() int {
 	return len(h.clients)
 }
 
-// SSEHandler handles GET /api/events for SSE connections.
+// SSEHandler handles GET /api/events for SSE connections, delivering the
+// events of the request's tenant. When the hub persists events, a
+// Last-Event-ID header replays the events after that ID before live events
+// resume.
 func SSEHandler(hub *SSEHub) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/event-stream")
@@ -108,12 +341,14 @@ func SSEHandler(hub *SSEHub) http.HandlerFunc {
 
 		flusher, ok := w.(http.Flusher)
 		if !ok {
-			http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "Streaming unsupported")
 			return
 		}
 
-		client := make(chan SSEEvent, 10)
-		hub.register <- client
+		// The stream outlives the server's write timeout
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+		client := hub.subscribe(r.Context(), 10)
 
 		sendSSEEvent(w, flusher, SSEEvent{
 			Type: "connected",
@@ -123,6 +358,15 @@ func SSEHandler(hub *SSEHub) http.HandlerFunc {
 			},
 		})
 
+		// Registered first, so events broadcast during the replay are queued;
+		// those already replayed are skipped by ID below
+		var replayed int64
+		if lastEventID, err := strconv.ParseInt(r.Header.Get("Last-Event-ID"), 10, 64); err == nil && lastEventID > 0 {
+			replayed = hub.replay(r.Context(), lastEventID, func(event SSEEvent) {
+				sendSSEEvent(w, flusher, event)
+			})
+		}
+
 		notify := r.Context().Done()
 		go func() {
 			<-notify
@@ -138,6 +382,9 @@ func SSEHandler(hub *SSEHub) http.HandlerFunc {
 				if !ok {
 					return
 				}
+				if event.ID != 0 && event.ID <= replayed {
+					continue
+				}
 				sendSSEEvent(w, flusher, event)
 
 			case <-ticker.C:
@@ -160,6 +407,9 @@ func sendSSEEvent(w http.ResponseWriter, flusher http.Flusher, event SSEEvent) {
 		return
 	}
 
+	if event.ID != 0 {
+		fmt.Fprintf(w, "id: %d\n", event.ID)
+	}
 	fmt.Fprintf(w, "event: %s\n", event.Type)
 	fmt.Fprintf(w, "data: %s\n\n", data)
 	flusher.Flush()
@@ -181,7 +431,11 @@ func logToSSEResponse(log *entities.Log) map[string]any {
 			"derived_severity": log.Metadata.DerivedSeverity,
 			"derived_source":   log.Metadata.DerivedSource,
 			"derived_category": log.Metadata.DerivedCategory,
+			"derived_color":    log.Metadata.DerivedColor,
 		},
-		"created_at": log.CreatedAt.Format(time.RFC3339),
+		"pinned":        log.Pinned,
+		"created_at":    formatTimestamp(log.CreatedAt, time.UTC),
+		"created_at_ms": log.CreatedAt.UnixMilli(),
+		"ingested_at":   formatTimestamp(log.IngestedAt, time.UTC),
 	}
 }
@@ -10,6 +10,8 @@ import (
 
 // SPAHandler serves static files with SPA fallback.
 // If a file is not found, it serves index.html for client-side routing.
+// Only GET and HEAD are served, and paths under /api are never routed to the
+// SPA, so API clients get a proper 404 or 405 instead of index.html.
 type SPAHandler struct {
 	staticFS   fs.FS
 	staticPath string
@@ -34,6 +36,16 @@ func (h *SPAHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	upath = path.Clean(upath)
 
+	if upath == "/api" || strings.HasPrefix(upath, "/api/") {
+		writeError(w, r, http.StatusNotFound, "Not found")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, r, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
 	// Build the full path within the embedded filesystem
 	fullPath := path.Join(h.staticPath, upath)
 
@@ -77,10 +89,10 @@ func (h *SPAHandler) serveIndex(w http.ResponseWriter, r *http.Request) {
 }
 
 // serveFile serves a file from the embedded filesystem.
-func (h *SPAHandler) serveFile(w http.ResponseWriter, _ *http.Request, filePath string) {
+func (h *SPAHandler) serveFile(w http.ResponseWriter, r *http.Request, filePath string) {
 	file, err := h.staticFS.Open(filePath)
 	if err != nil {
-		http.Error(w, "File not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, "File not found")
 		return
 	}
 	defer file.Close()
@@ -88,7 +100,7 @@ func (h *SPAHandler) serveFile(w http.ResponseWriter, _ *http.Request, filePath
 	// Get file info for Content-Length
 	stat, err := file.Stat()
 	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 
@@ -108,7 +120,7 @@ func (h *SPAHandler) serveFile(w http.ResponseWriter, _ *http.Request, filePath
 	// Read and write content
 	content, err := io.ReadAll(file)
 	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 
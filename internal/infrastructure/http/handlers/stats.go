@@ -1,25 +1,168 @@
 package handlers
 
 import (
-	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/mx-scribe/scribe/internal/application/queries"
 	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
 )
 
+// TimeFieldOptions selects which timestamp time-window views compare
+// against: the event time or the ingestion time.
+type TimeFieldOptions struct {
+	// Stats is used wherever recency matters: the last-24-hours counts of
+	// GET /api/stats, its SSE broadcasts and GET /api/admin/retention, and
+	// the ?since= windows of the top-errors and errors-per-source stats.
+	Stats sqlite.TimeField
+
+	// Retention is used by cleanup and the retention age buckets.
+	Retention sqlite.TimeField
+
+	// Now returns the time the retention age buckets are measured from.
+	// Nil uses time.Now.
+	Now func() time.Time
+}
+
+// DefaultTimeFieldOptions returns the default time fields: ingestion time for
+// operational stats and event time for retention.
+func DefaultTimeFieldOptions() TimeFieldOptions {
+	return TimeFieldOptions{Stats: sqlite.IngestedAt, Retention: sqlite.EventTime}
+}
+
+// timeFields returns opts, or the defaults when it is nil.
+func timeFields(opts *TimeFieldOptions) TimeFieldOptions {
+	if opts == nil {
+		return DefaultTimeFieldOptions()
+	}
+	return *opts
+}
+
+// now returns the current time from the clock in opts.
+func (o TimeFieldOptions) now() time.Time {
+	if o.Now == nil {
+		return time.Now()
+	}
+	return o.Now()
+}
+
 // GetStats handles GET /api/stats.
 func GetStats(db *sqlite.Database) http.HandlerFunc {
+	return GetStatsWithOptions(db, nil)
+}
+
+// GetStatsWithOptions handles GET /api/stats, counting the last 24 hours by
+// the stats time field in opts, which is read on every request. A nil opts
+// uses the defaults.
+func GetStatsWithOptions(db *sqlite.Database, opts *TimeFieldOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler := queries.NewGetStatsHandler(statsRepository(db, opts))
+
+		stats, err := handler.HandleContext(r.Context())
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, stats)
+	}
+}
+
+// statsRepository returns a repository whose recency queries use the stats
+// time field in opts.
+func statsRepository(db *sqlite.Database, opts *TimeFieldOptions) *sqlite.LogRepository {
+	return sqlite.NewLogRepository(db).WithTimeField(timeFields(opts).Stats)
+}
+
+// GetTopErrors handles GET /api/stats/top-errors.
+func GetTopErrors(db *sqlite.Database) http.HandlerFunc {
+	return GetTopErrorsWithOptions(db, nil)
+}
+
+// GetTopErrorsWithOptions handles GET /api/stats/top-errors.
+// Groups recent error/critical logs by normalized title, with the window
+// measured on the stats time field in opts. A nil opts uses the defaults.
+func GetTopErrorsWithOptions(db *sqlite.Database, opts *TimeFieldOptions) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		repo := sqlite.NewLogRepository(db)
-		handler := queries.NewGetStatsHandler(repo)
+		since, ok := sinceParam(w, r)
+		if !ok {
+			return
+		}
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		repo := statsRepository(db, opts)
+		handler := queries.NewGetTopErrorsHandler(repo)
 
-		stats, err := handler.Handle()
+		response, err := handler.Handle(r.Context(), queries.GetTopErrorsRequest{
+			Since: since,
+			Limit: limit,
+		})
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, response)
+	}
+}
+
+// GetErrorsPerSource handles GET /api/stats/errors-per-source.
+func GetErrorsPerSource(db *sqlite.Database) http.HandlerFunc {
+	return GetErrorsPerSourceWithOptions(db, nil)
+}
+
+// GetErrorsPerSourceWithOptions handles GET /api/stats/errors-per-source.
+// Returns per-source error and total counts over the window, sorted by
+// error rate, to single out the services failing the most. The window is
+// measured on the stats time field in opts; a nil opts uses the defaults.
+func GetErrorsPerSourceWithOptions(db *sqlite.Database, opts *TimeFieldOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		since, ok := sinceParam(w, r)
+		if !ok {
 			return
 		}
 
-		_ = json.NewEncoder(w).Encode(stats)
+		repo := statsRepository(db, opts)
+		handler := queries.NewGetErrorsPerSourceHandler(repo)
+
+		response, err := handler.Handle(r.Context(), queries.GetErrorsPerSourceRequest{Since: since})
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, response)
+	}
+}
+
+// sinceParam reads the ?since= window of the stats endpoints, one hour by
+// default. It writes a 400 and returns false when the value is not a
+// positive duration.
+func sinceParam(w http.ResponseWriter, r *http.Request) (time.Duration, bool) {
+	v := r.URL.Query().Get("since")
+	if v == "" {
+		return time.Hour, true
+	}
+	since, err := parseDuration(v)
+	if err != nil || since <= 0 {
+		writeError(w, r, http.StatusBadRequest, "invalid since duration")
+		return 0, false
+	}
+	return since, true
+}
+
+// parseDuration parses a Go duration string, additionally accepting a "d" suffix for days (e.g. "7d").
+func parseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
 	}
+	return time.ParseDuration(s)
 }
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/application/commands"
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+const (
+	// streamBatchSize is the maximum number of logs inserted per transaction.
+	streamBatchSize = 100
+
+	// streamMaxLineSize is the maximum accepted size of a single NDJSON line.
+	streamMaxLineSize = 1 << 20
+
+	// streamMaxErrors caps how many line errors are reported in the summary.
+	streamMaxErrors = 100
+)
+
+// BatchItemError describes a log of a batch that could not be created.
+// Index is the log's zero-based position in the batch. Field names the
+// offending request field, e.g. "header.title", and is omitted when the entry
+// as a whole is at fault, such as invalid JSON or an oversized line. Code is
+// set for logs an ingestion policy rejected, e.g. "rate_limited".
+type BatchItemError struct {
+	Index   int       `json:"index"`
+	Field   string    `json:"field,omitempty"`
+	Code    ErrorCode `json:"code,omitempty"`
+	Message string    `json:"message"`
+}
+
+// newBatchItemError builds the BatchItemError of the log at index, taking
+// the field from a fieldError or JSON type error and the code from an
+// IngestError.
+func newBatchItemError(index int, err error) BatchItemError {
+	var ingestErr *IngestError
+	if errors.As(err, &ingestErr) {
+		return BatchItemError{Index: index, Code: ingestErr.Code, Message: ingestErr.Message}
+	}
+	var fieldErr *fieldError
+	if errors.As(err, &fieldErr) {
+		return BatchItemError{Index: index, Field: fieldErr.field, Message: fieldErr.Error()}
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return BatchItemError{
+			Index:   index,
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("%s must be %s, got %s", typeErr.Field, jsonTypeName(typeErr.Type), typeErr.Value),
+		}
+	}
+	return BatchItemError{Index: index, Message: err.Error()}
+}
+
+// jsonTypeName names the JSON value a Go type is decoded from.
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	}
+	return "a " + t.String()
+}
+
+// StreamSummary is returned once the NDJSON stream has been fully consumed.
+// Dropped counts lines an ingestion policy set to drop turned away; lines it
+// rejects count as failed.
+type StreamSummary struct {
+	Received int              `json:"received"`
+	Created  int              `json:"created"`
+	Failed   int              `json:"failed"`
+	Dropped  int              `json:"dropped,omitempty"`
+	Errors   []BatchItemError `json:"errors,omitempty"`
+}
+
+// StreamLogs handles POST /api/logs/stream.
+func StreamLogs(db *sqlite.Database) http.HandlerFunc {
+	return StreamLogsWithSSE(db, nil)
+}
+
+// StreamLogsWithSSE handles POST /api/logs/stream with SSE broadcast support.
+// The body is read as NDJSON (one CreateLogRequest per line) and inserted in
+// batches as lines arrive, so clients can stream indefinitely over a chunked request.
+// Malformed lines are reported in the summary without aborting the stream.
+func StreamLogsWithSSE(db *sqlite.Database, hub *SSEHub) http.HandlerFunc {
+	return StreamLogsWithOptions(db, hub, nil)
+}
+
+// StreamLogsWithOptions handles POST /api/logs/stream like StreamLogsWithSSE,
+// building each line through the ingestion path of POST /api/logs with opts.
+// Like there, ?analyze overrides whether lines are pattern matched.
+func StreamLogsWithOptions(db *sqlite.Database, hub *SSEHub, opts *IngestOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		analyze, err := analyzeParam(r, opts)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Long-lived streams must not be cut off by the server timeouts
+		rc := http.NewResponseController(w)
+		_ = rc.SetReadDeadline(time.Time{})
+		_ = rc.SetWriteDeadline(time.Time{})
+
+		repo := sqlite.NewLogRepository(db)
+		handler := commands.NewCreateLogHandler(repo)
+
+		summary := StreamSummary{}
+		batch := make([]*entities.Log, 0, streamBatchSize)
+		batchIndexes := make([]int, 0, streamBatchSize)
+
+		fail := func(index int, err error) {
+			summary.Failed++
+			if len(summary.Errors) < streamMaxErrors {
+				summary.Errors = append(summary.Errors, newBatchItemError(index, err))
+			}
+		}
+
+		flush := func() {
+			if len(batch) == 0 {
+				return
+			}
+			escalations := opts.escalate(r.Context(), batch...)
+			if err := repo.CreateBatchContext(r.Context(), batch); err != nil {
+				for _, index := range batchIndexes {
+					fail(index, err)
+				}
+			} else {
+				summary.Created += len(batch)
+				observeIngested(batch...)
+				if hub != nil {
+					for _, log := range batch {
+						hub.BroadcastLogCreatedContext(r.Context(), log)
+					}
+					broadcastEscalations(r.Context(), hub, escalations)
+				}
+			}
+			batch = batch[:0]
+			batchIndexes = batchIndexes[:0]
+		}
+
+		reader := bufio.NewReader(r.Body)
+		for {
+			line, tooLong, err := readStreamLine(reader, streamMaxLineSize)
+			if len(bytes.TrimSpace(line)) > 0 || tooLong {
+				index := summary.Received
+				summary.Received++
+
+				log, lineErr := buildStreamLog(handler, line, tooLong, analyze, opts)
+				var ingestErr *IngestError
+				switch {
+				case errors.As(lineErr, &ingestErr) && ingestErr.Dropped:
+					summary.Dropped++
+				case lineErr != nil:
+					fail(index, lineErr)
+				default:
+					batch = append(batch, log)
+					batchIndexes = append(batchIndexes, index)
+				}
+
+				// Flush when the batch is full or the client has paused sending
+				if len(batch) >= streamBatchSize || reader.Buffered() == 0 {
+					flush()
+				}
+			}
+
+			if err != nil {
+				flush()
+				if !errors.Is(err, io.EOF) {
+					writeError(w, r, http.StatusBadRequest, "failed to read stream: "+err.Error())
+					return
+				}
+				break
+			}
+		}
+
+		writeJSON(w, r, http.StatusOK, summary)
+	}
+}
+
+// buildStreamLog decodes a single NDJSON line into a log ready for insertion
+// through opts.buildLog, so lines go through the same passes and policies
+// as POST /api/logs. analyze reports whether the pattern matcher runs.
+func buildStreamLog(handler *commands.CreateLogHandler, line []byte, tooLong, analyze bool, opts *IngestOptions) (*entities.Log, error) {
+	if tooLong {
+		return nil, fmt.Errorf("line exceeds %d bytes", streamMaxLineSize)
+	}
+
+	var req CreateLogRequest
+	if err := decodeJSON(line, &req); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if req.Header.Title == "" {
+		return nil, &fieldError{field: "header.title", err: errors.New("title is required")}
+	}
+	if err := opts.checkEventAge(req.Timestamp); err != nil {
+		return nil, &fieldError{field: "timestamp", err: err}
+	}
+
+	input, err := req.Input()
+	if err != nil {
+		return nil, err
+	}
+	input.SkipAnalysis = !analyze
+	log, _, err := opts.buildLog(handler, input)
+	return log, err
+}
+
+// readStreamLine reads one newline-terminated line, keeping at most max bytes.
+// Oversized lines are consumed entirely and reported via tooLong.
+func readStreamLine(reader *bufio.Reader, max int) (line []byte, tooLong bool, err error) {
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if !tooLong {
+			if len(line)+len(chunk) > max {
+				tooLong = true
+				line = nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		return line, tooLong, err
+	}
+}
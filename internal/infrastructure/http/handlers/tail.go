@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/application/queries"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// TailLogs handles GET /api/logs/export-stream.
+func TailLogs(db *sqlite.Database, hub *SSEHub) http.HandlerFunc {
+	return TailLogsWithPagination(db, hub, nil)
+}
+
+// TailLogsWithPagination handles GET /api/logs/export-stream, an SSE stream
+// that tails a filtered view of the logs. It first sends the most recent
+// matching logs, oldest to newest, as "backlog" events, then a
+// "backlog_complete" event, then live "log_created" and
+// "logs_created_batch" events from hub that match the same severity,
+// min_severity, source and search filters. The backlog holds up to ?limit=
+// logs, bounded by the export page size. Logs created while the backlog is
+// read are delivered once, as live events.
+func TailLogsWithPagination(db *sqlite.Database, hub *SSEHub, pagination *queries.Pagination) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := eventFilterFromRequest(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		filter.search = r.URL.Query().Get("search")
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		limit = pageSizes(pagination).Export.Clamp(limit)
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, r, http.StatusInternalServerError, "streaming unsupported")
+			return
+		}
+
+		// Subscribe before reading the backlog so no log falls between the
+		// two; afterBacklog skips live events for logs it already holds
+		client := hub.subscribe(r.Context(), 100)
+		defer func() { hub.unregister <- client }()
+
+		ctx := r.Context()
+		repo := sqlite.NewLogRepository(db)
+		maxID, err := repo.MaxIDContext(ctx)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+		logs, total, err := repo.FindAllContext(ctx, sqlite.LogFilters{
+			Severity:    filter.severity,
+			MinSeverity: filter.minSeverity,
+			Source:      filter.source,
+			Search:      filter.search,
+			MaxID:       maxID,
+			Limit:       limit,
+		})
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		// The stream outlives the server's write timeout
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		// Logs are read newest first; the backlog is sent oldest first
+		slices.Reverse(logs)
+		for _, log := range logs {
+			sendSSEEvent(w, flusher, SSEEvent{Type: "backlog", Data: logToSSEResponse(log)})
+		}
+		sendSSEEvent(w, flusher, SSEEvent{
+			Type: "backlog_complete",
+			Data: map[string]any{"count": len(logs), "total_matched": total},
+		})
+
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event, ok := <-client:
+				if !ok {
+					return
+				}
+				if event, ok = afterBacklog(event, maxID); !ok {
+					continue
+				}
+				if event, ok = filter.apply(event); !ok {
+					continue
+				}
+				sendSSEEvent(w, flusher, event)
+
+			case <-ticker.C:
+				sendSSEEvent(w, flusher, SSEEvent{
+					Type: "ping",
+					Data: map[string]string{"timestamp": time.Now().Format(time.RFC3339)},
+				})
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// afterBacklog returns the event to deliver and whether to deliver it,
+// leaving out logs with an ID of at most maxID, which the backlog already
+// sent.
+func afterBacklog(event SSEEvent, maxID int64) (SSEEvent, bool) {
+	isNew := func(logData any) bool {
+		data, ok := logData.(map[string]any)
+		if !ok {
+			return true
+		}
+		id, _ := data["id"].(int64)
+		return id > maxID
+	}
+
+	switch event.Type {
+	case "log_created":
+		return event, isNew(event.Data)
+
+	case "logs_created_batch":
+		batch, ok := event.Data.(map[string]any)
+		if !ok {
+			return event, true
+		}
+		logs, _ := batch["logs"].([]any)
+		kept := make([]any, 0, len(logs))
+		for _, log := range logs {
+			if isNew(log) {
+				kept = append(kept, log)
+			}
+		}
+		if len(kept) == 0 {
+			return event, false
+		}
+		return SSEEvent{
+			Type: event.Type,
+			Data: map[string]any{"count": len(kept), "logs": kept},
+		}, true
+	}
+	return event, false
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/mx-scribe/scribe/internal/application/commands"
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// CreateTextLog handles POST /api/logs/text.
+func CreateTextLog(db *sqlite.Database) http.HandlerFunc {
+	return CreateTextLogWithSSE(db, nil)
+}
+
+// CreateTextLogWithSSE handles POST /api/logs/text with SSE broadcast support.
+// The text/plain body is a raw log line: its first line becomes the title and
+// any remaining lines (such as a stack trace) the description. Severity,
+// source and category are left to the pattern matcher.
+func CreateTextLogWithSSE(db *sqlite.Database, hub *SSEHub) http.HandlerFunc {
+	return CreateTextLogWithOptions(db, hub, nil)
+}
+
+// CreateTextLogWithOptions handles POST /api/logs/text like
+// CreateTextLogWithSSE, applying the ingestion options of POST /api/logs:
+// the configured pattern matcher and colorizer, the source filter, the
+// severity floor, the per-source rate limit and escalation. opts is read on
+// every request; a nil opts uses the defaults.
+func CreateTextLogWithOptions(db *sqlite.Database, hub *SSEHub, opts *IngestOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if contentType := r.Header.Get("Content-Type"); contentType != "" {
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			if err != nil || mediaType != "text/plain" {
+				writeError(w, r, http.StatusUnsupportedMediaType, "content type must be text/plain")
+				return
+			}
+		}
+
+		loc, err := timezoneParam(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		raw, err := io.ReadAll(r.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeError(w, r, http.StatusRequestEntityTooLarge, "request body too large")
+				return
+			}
+			writeError(w, r, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+
+		title, description := splitTextLog(string(raw))
+		if title == "" {
+			writeError(w, r, http.StatusBadRequest, "log text is required")
+			return
+		}
+
+		repo := sqlite.NewLogRepository(db)
+		handler := commands.NewCreateLogHandler(repo)
+
+		log, _, err := opts.buildLog(handler, commands.CreateLogInput{
+			Title:       title,
+			Description: description,
+		})
+		var ingestErr *IngestError
+		switch {
+		case errors.As(err, &ingestErr):
+			writeIngestError(w, r, ingestErr)
+			return
+		case err != nil:
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if err := opts.SaveLogs(r.Context(), db, hub, []*entities.Log{log}); err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		log, err = repo.FindByIDContext(r.Context(), log.ID)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		w.Header().Set("Location", logLocation(log.ID))
+		writeJSON(w, r, http.StatusCreated, logToResponse(log, loc))
+	}
+}
+
+// splitTextLog returns the first non-blank line of text as the title and the
+// rest, trimmed, as the description.
+func splitTextLog(text string) (title, description string) {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	title, description, _ = strings.Cut(text, "\n")
+	return strings.TrimSpace(title), strings.TrimSpace(description)
+}
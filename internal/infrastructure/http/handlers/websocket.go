@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // required by the WebSocket handshake (RFC 6455)
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+)
+
+// websocketGUID is the fixed key suffix defined by RFC 6455.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes used by the event stream.
+const (
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+)
+
+const (
+	wsWriteTimeout = 10 * time.Second
+	// wsMaxControlPayload bounds frames read from clients; the stream is
+	// server-push only, so clients only send small control frames.
+	wsMaxControlPayload = 125
+)
+
+// eventFilter selects which log events a client receives.
+type eventFilter struct {
+	severity    string
+	minSeverity string
+	source      string
+
+	// search matches title, description or body case-insensitively, like
+	// the search filter of GET /api/logs.
+	search string
+}
+
+// eventFilterFromRequest reads severity, min_severity and source query params.
+func eventFilterFromRequest(r *http.Request) (eventFilter, error) {
+	minSeverity, err := minSeverityParam(r)
+	if err != nil {
+		return eventFilter{}, err
+	}
+	return eventFilter{
+		severity:    r.URL.Query().Get("severity"),
+		minSeverity: minSeverity,
+		source:      r.URL.Query().Get("source"),
+	}, nil
+}
+
+// apply returns the event to deliver and whether to deliver it. log_created
+// events are dropped unless they match; logs_created_batch events keep only
+// matching logs. Other event types are always delivered.
+func (f eventFilter) apply(event SSEEvent) (SSEEvent, bool) {
+	switch event.Type {
+	case "log_created":
+		return event, f.matches(event.Data)
+
+	case "logs_created_batch":
+		batch, ok := event.Data.(map[string]any)
+		if !ok {
+			return event, true
+		}
+		logs, _ := batch["logs"].([]any)
+		kept := make([]any, 0, len(logs))
+		for _, log := range logs {
+			if f.matches(log) {
+				kept = append(kept, log)
+			}
+		}
+		if len(kept) == 0 {
+			return event, false
+		}
+		return SSEEvent{
+			Type: event.Type,
+			Data: map[string]any{"count": len(kept), "logs": kept},
+		}, true
+	}
+	return event, true
+}
+
+// matches reports whether a log_created payload passes the filter.
+func (f eventFilter) matches(logData any) bool {
+	data, ok := logData.(map[string]any)
+	if !ok {
+		return true
+	}
+	header, _ := data["header"].(map[string]any)
+	severity, _ := header["severity"].(string)
+	source, _ := header["source"].(string)
+
+	if f.severity != "" {
+		if severity != f.severity {
+			return false
+		}
+	} else if f.minSeverity != "" {
+		if !valueobjects.Severity(severity).AtLeast(valueobjects.Severity(f.minSeverity)) {
+			return false
+		}
+	}
+	if f.source != "" && source != f.source {
+		return false
+	}
+	if f.search != "" {
+		title, _ := header["title"].(string)
+		description, _ := header["description"].(string)
+		body, _ := json.Marshal(data["body"])
+		term := strings.ToLower(f.search)
+		if !strings.Contains(strings.ToLower(title), term) &&
+			!strings.Contains(strings.ToLower(description), term) &&
+			!strings.Contains(strings.ToLower(string(body)), term) {
+			return false
+		}
+	}
+	return true
+}
+
+// WebSocketHandler handles GET /api/ws, pushing the same events as
+// /api/events over a WebSocket for proxies that break SSE.
+func WebSocketHandler(hub *SSEHub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := eventFilterFromRequest(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if !headerContainsToken(r.Header, "Connection", "upgrade") ||
+			!headerContainsToken(r.Header, "Upgrade", "websocket") {
+			writeError(w, r, http.StatusBadRequest, "websocket upgrade required")
+			return
+		}
+		if r.Header.Get("Sec-WebSocket-Version") != "13" {
+			w.Header().Set("Sec-WebSocket-Version", "13")
+			writeError(w, r, http.StatusUpgradeRequired, "unsupported websocket version")
+			return
+		}
+		key := r.Header.Get("Sec-WebSocket-Key")
+		if key == "" {
+			writeError(w, r, http.StatusBadRequest, "missing Sec-WebSocket-Key")
+			return
+		}
+
+		netConn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, "websocket unsupported")
+			return
+		}
+		defer netConn.Close()
+
+		// Drop any deadlines inherited from the server's timeouts
+		_ = netConn.SetDeadline(time.Time{})
+
+		conn := &wsConn{conn: netConn, rw: rw}
+		if err := conn.handshake(key); err != nil {
+			return
+		}
+
+		client := hub.subscribe(r.Context(), 10)
+		defer func() { hub.unregister <- client }()
+
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			conn.readLoop()
+		}()
+
+		if err := conn.writeEvent(SSEEvent{
+			Type: "connected",
+			Data: map[string]any{
+				"message":   "Connected to SCRIBE event stream",
+				"timestamp": time.Now().Format(time.RFC3339),
+			},
+		}); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event, ok := <-client:
+				if !ok {
+					return
+				}
+				event, ok = filter.apply(event)
+				if !ok {
+					continue
+				}
+				if err := conn.writeEvent(event); err != nil {
+					return
+				}
+
+			case <-ticker.C:
+				if err := conn.writeEvent(SSEEvent{
+					Type: "ping",
+					Data: map[string]string{"timestamp": time.Now().Format(time.RFC3339)},
+				}); err != nil {
+					return
+				}
+
+			case <-closed:
+				return
+			}
+		}
+	}
+}
+
+// headerContainsToken reports whether a comma-separated header contains token.
+func headerContainsToken(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wsConn is a minimal server-side WebSocket connection that sends text
+// frames and answers control frames.
+type wsConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+	mu   sync.Mutex
+}
+
+// handshake completes the opening handshake.
+func (c *wsConn) handshake(key string) error {
+	sum := sha1.Sum([]byte(key + websocketGUID)) //nolint:gosec // mandated by RFC 6455
+	accept := base64.StdEncoding.EncodeToString(sum[:])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	_, _ = fmt.Fprintf(c.rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", accept)
+	return c.rw.Flush()
+}
+
+// writeEvent sends event as a JSON text frame.
+func (c *wsConn) writeEvent(event SSEEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return c.writeFrame(wsOpText, data)
+}
+
+// writeFrame writes a single unmasked, unfragmented frame.
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n <= 125:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if _, err := c.rw.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.rw.Write(payload); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+// readLoop consumes client frames until the connection closes, answering
+// pings and echoing close frames.
+func (c *wsConn) readLoop() {
+	for {
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return
+			}
+		case wsOpClose:
+			_ = c.writeFrame(wsOpClose, payload)
+			return
+		}
+	}
+}
+
+// readFrame reads one client frame. Client frames must be masked.
+func (c *wsConn) readFrame() (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.rw, head[:]); err != nil {
+		return 0, nil, err
+	}
+
+	opcode := head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7F)
+	if !masked {
+		return 0, nil, errors.New("websocket: unmasked client frame")
+	}
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+		return 0, nil, err
+	}
+
+	// Data frames are ignored, so only their length matters
+	if opcode&0x8 == 0 {
+		if _, err := io.CopyN(io.Discard, c.rw, int64(length)); err != nil {
+			return 0, nil, err
+		}
+		return opcode, nil, nil
+	}
+	if length > wsMaxControlPayload {
+		return 0, nil, errors.New("websocket: control frame too large")
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
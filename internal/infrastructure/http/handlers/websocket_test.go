@@ -0,0 +1,722 @@
+package handlers_test
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1" //nolint:gosec // required by the WebSocket handshake
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+	"github.com/mx-scribe/scribe/internal/infrastructure/http/handlers"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// wsTestClient is a minimal WebSocket client for exercising /api/ws.
+type wsTestClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func newWebSocketServer(t *testing.T, db *sqlite.Database) *httptest.Server {
+	t.Helper()
+
+	hub := handlers.NewSSEHub()
+	router := chi.NewRouter()
+	router.Get("/api/ws", handlers.WebSocketHandler(hub))
+	router.Post("/api/logs", handlers.CreateLogWithSSE(db, hub))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func dialWebSocket(t *testing.T, server *httptest.Server, path string) *wsTestClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	key := base64.StdEncoding.EncodeToString([]byte("scribe-test-key!"))
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\n"+
+		"Host: %s\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: %s\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n", path, server.Listener.Addr(), key)
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("failed to read handshake: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected status 101, got %d", resp.StatusCode)
+	}
+
+	sum := sha1.Sum([]byte(key + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11")) //nolint:gosec // handshake
+	if got, want := resp.Header.Get("Sec-WebSocket-Accept"), base64.StdEncoding.EncodeToString(sum[:]); got != want {
+		t.Fatalf("expected accept %q, got %q", want, got)
+	}
+
+	client := &wsTestClient{conn: conn, reader: reader}
+	if event := client.readEvent(t); event.Type != "connected" {
+		t.Fatalf("expected connected event, got %s", event.Type)
+	}
+	return client
+}
+
+// readEvent reads the next text frame and decodes it as an event.
+func (c *wsTestClient) readEvent(t *testing.T) handlers.SSEEvent {
+	t.Helper()
+
+	_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var head [2]byte
+	if _, err := io.ReadFull(c.reader, head[:]); err != nil {
+		t.Fatalf("failed to read frame: %v", err)
+	}
+	if head[1]&0x80 != 0 {
+		t.Fatal("server frames must not be masked")
+	}
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		_, _ = io.ReadFull(c.reader, ext[:])
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		_, _ = io.ReadFull(c.reader, ext[:])
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		t.Fatalf("failed to read payload: %v", err)
+	}
+	if opcode := head[0] & 0x0F; opcode != 0x1 {
+		t.Fatalf("expected text frame, got opcode %d", opcode)
+	}
+
+	var event handlers.SSEEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		t.Fatalf("invalid event payload %q: %v", payload, err)
+	}
+	return event
+}
+
+// writeFrame sends a masked client frame.
+func (c *wsTestClient) writeFrame(t *testing.T, opcode byte, payload []byte) {
+	t.Helper()
+
+	mask := [4]byte{1, 2, 3, 4}
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload))}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		t.Fatalf("failed to write frame: %v", err)
+	}
+}
+
+func postLog(t *testing.T, server *httptest.Server, title, severity, source string) {
+	t.Helper()
+
+	body := fmt.Sprintf(`{"header":{"title":%q,"severity":%q,"source":%q}}`, title, severity, source)
+	resp, err := http.Post(server.URL+"/api/logs", "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", resp.StatusCode)
+	}
+}
+
+func eventTitle(event handlers.SSEEvent) string {
+	data, _ := event.Data.(map[string]any)
+	header, _ := data["header"].(map[string]any)
+	title, _ := header["title"].(string)
+	return title
+}
+
+func TestWebSocketHandler_ReceivesLogCreated(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	server := newWebSocketServer(t, db)
+	client := dialWebSocket(t, server, "/api/ws")
+
+	postLog(t, server, "Deploy finished", "info", "ci")
+
+	event := client.readEvent(t)
+	if event.Type != "log_created" {
+		t.Fatalf("expected log_created event, got %s", event.Type)
+	}
+	if title := eventTitle(event); title != "Deploy finished" {
+		t.Errorf("expected title 'Deploy finished', got %q", title)
+	}
+}
+
+func TestWebSocketHandler_Filters(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	server := newWebSocketServer(t, db)
+	bySeverity := dialWebSocket(t, server, "/api/ws?min_severity=error")
+	bySource := dialWebSocket(t, server, "/api/ws?source=billing")
+
+	postLog(t, server, "Cache warmed", "info", "web")
+	postLog(t, server, "Invoice sent", "info", "billing")
+	postLog(t, server, "Queue stalled", "error", "web")
+
+	if title := eventTitle(bySeverity.readEvent(t)); title != "Queue stalled" {
+		t.Errorf("min_severity filter: expected 'Queue stalled', got %q", title)
+	}
+	if title := eventTitle(bySource.readEvent(t)); title != "Invoice sent" {
+		t.Errorf("source filter: expected 'Invoice sent', got %q", title)
+	}
+}
+
+func TestWebSocketHandler_Ping(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	server := newWebSocketServer(t, db)
+	client := dialWebSocket(t, server, "/api/ws")
+
+	client.writeFrame(t, 0x9, []byte("hi"))
+
+	_ = client.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	frame := make([]byte, 4)
+	if _, err := io.ReadFull(client.reader, frame); err != nil {
+		t.Fatalf("failed to read pong: %v", err)
+	}
+	if frame[0]&0x0F != 0xA || string(frame[2:]) != "hi" {
+		t.Errorf("expected pong echoing payload, got %v", frame)
+	}
+}
+
+func TestWebSocketHandler_RejectsPlainRequest(t *testing.T) {
+	hub := handlers.NewSSEHub()
+
+	tests := []struct {
+		name       string
+		query      string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{
+			name:       "no upgrade headers",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "wrong version",
+			headers: map[string]string{
+				"Connection":            "Upgrade",
+				"Upgrade":               "websocket",
+				"Sec-WebSocket-Key":     "abc",
+				"Sec-WebSocket-Version": "8",
+			},
+			wantStatus: http.StatusUpgradeRequired,
+		},
+		{
+			name:       "invalid min_severity",
+			query:      "?min_severity=loud",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/ws"+tt.query, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+
+			handlers.WebSocketHandler(hub).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func newCoalescingServer(t *testing.T, threshold int) (*handlers.SSEHub, *httptest.Server) {
+	t.Helper()
+
+	hub := handlers.NewSSEHub()
+	hub.SetCoalescing(threshold, 50*time.Millisecond)
+
+	router := chi.NewRouter()
+	router.Get("/api/ws", handlers.WebSocketHandler(hub))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return hub, server
+}
+
+func burstLogs(hub *handlers.SSEHub, n int, source func(i int) string) {
+	for i := 0; i < n; i++ {
+		log := entities.NewLog(entities.LogHeader{
+			Title:    fmt.Sprintf("Burst %d", i),
+			Severity: valueobjects.SeverityInfo,
+			Source:   source(i),
+		}, nil)
+		log.ID = int64(i + 1)
+		hub.BroadcastLogCreated(log)
+	}
+}
+
+func TestSSEHub_CoalescesBursts(t *testing.T) {
+	hub, server := newCoalescingServer(t, 5)
+	client := dialWebSocket(t, server, "/api/ws")
+	billing := dialWebSocket(t, server, "/api/ws?source=billing")
+
+	const total = 40
+	burstLogs(hub, total, func(i int) string {
+		if i%4 == 0 {
+			return "billing"
+		}
+		return "web"
+	})
+
+	individual, batched, frames := 0, 0, 0
+	for individual+batched < total {
+		event := client.readEvent(t)
+		frames++
+		switch event.Type {
+		case "log_created":
+			individual++
+		case "logs_created_batch":
+			data := event.Data.(map[string]any)
+			logs := data["logs"].([]any)
+			if int(data["count"].(float64)) != len(logs) {
+				t.Errorf("batch count %v does not match %d logs", data["count"], len(logs))
+			}
+			batched += len(logs)
+		default:
+			t.Fatalf("unexpected event %s", event.Type)
+		}
+	}
+
+	if individual != 5 {
+		t.Errorf("expected the first 5 events individually, got %d", individual)
+	}
+	if batched != total-5 {
+		t.Errorf("expected %d batched logs, got %d", total-5, batched)
+	}
+	if frames >= total {
+		t.Errorf("expected fewer frames than logs, got %d frames for %d logs", frames, total)
+	}
+
+	// Filtered clients only see matching logs, including inside batches
+	received := 0
+	for received < total/4 {
+		event := billing.readEvent(t)
+		switch event.Type {
+		case "log_created":
+			received++
+		case "logs_created_batch":
+			for _, log := range event.Data.(map[string]any)["logs"].([]any) {
+				header := log.(map[string]any)["header"].(map[string]any)
+				if header["source"] != "billing" {
+					t.Errorf("unexpected source %v in filtered batch", header["source"])
+				}
+				received++
+			}
+		}
+	}
+}
+
+func TestSSEHub_BelowThresholdSendsIndividually(t *testing.T) {
+	hub, server := newCoalescingServer(t, 100)
+	client := dialWebSocket(t, server, "/api/ws")
+
+	burstLogs(hub, 8, func(int) string { return "web" })
+
+	for i := 0; i < 8; i++ {
+		if event := client.readEvent(t); event.Type != "log_created" {
+			t.Fatalf("expected individual log_created events, got %s", event.Type)
+		}
+	}
+}
+
+// readSSEEvent reads the next SSE frame from reader and decodes its data,
+// along with its id when it has one.
+func readSSEEvent(t *testing.T, reader *bufio.Reader) handlers.SSEEvent {
+	t.Helper()
+
+	var id int64
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read SSE frame: %v", err)
+		}
+		if raw, ok := strings.CutPrefix(strings.TrimSpace(line), "id: "); ok {
+			_, _ = fmt.Sscan(raw, &id)
+			continue
+		}
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), "data: ")
+		if !ok {
+			continue
+		}
+		event := handlers.SSEEvent{ID: id}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("invalid SSE data %q: %v", data, err)
+		}
+		return event
+	}
+}
+
+func TestTailLogs_BacklogThenLive(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	hub := handlers.NewSSEHub()
+	router := chi.NewRouter()
+	router.Get("/api/logs/export-stream", handlers.TailLogs(db, hub))
+	router.Post("/api/logs", handlers.CreateLogWithSSE(db, hub))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	postLog(t, server, "Checkout timeout one", "error", "api")
+	postLog(t, server, "Checkout timeout warning", "warning", "api")
+	postLog(t, server, "Checkout timeout worker", "error", "worker")
+	postLog(t, server, "Checkout timeout two", "error", "api")
+	postLog(t, server, "Checkout failed", "error", "api")
+
+	resp, err := http.Get(server.URL + "/api/logs/export-stream?severity=error&source=api&search=TIMEOUT")
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+	reader := bufio.NewReader(resp.Body)
+
+	// Backlog first, oldest to newest, filtered
+	for _, want := range []string{"Checkout timeout one", "Checkout timeout two"} {
+		event := readSSEEvent(t, reader)
+		if event.Type != "backlog" || eventTitle(event) != want {
+			t.Fatalf("expected backlog event %q, got %s %q", want, event.Type, eventTitle(event))
+		}
+	}
+	event := readSSEEvent(t, reader)
+	if event.Type != "backlog_complete" {
+		t.Fatalf("expected backlog_complete, got %s", event.Type)
+	}
+	if data, _ := event.Data.(map[string]any); data["count"] != float64(2) {
+		t.Errorf("expected backlog count 2, got %v", event.Data)
+	}
+
+	// Then live events, with the same filters
+	postLog(t, server, "Payment timeout warning", "warning", "api")
+	postLog(t, server, "Payment failed", "error", "api")
+	postLog(t, server, "Payment timeout", "error", "api")
+
+	event = readSSEEvent(t, reader)
+	if event.Type != "log_created" || eventTitle(event) != "Payment timeout" {
+		t.Errorf("expected live log_created for 'Payment timeout', got %s %q", event.Type, eventTitle(event))
+	}
+}
+
+func TestTailLogs_InvalidFilter(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/logs/export-stream?min_severity=loud", nil)
+	rec := httptest.NewRecorder()
+	handlers.TailLogs(db, handlers.NewSSEHub())(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestSSEHub_ReplaysPersistedEventsAfterRestart(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	events := sqlite.NewEventRepository(db)
+
+	// First server run: broadcast events with nobody connected
+	before := handlers.NewSSEHub()
+	before.SetEventPersistence(events, time.Minute)
+	for id := int64(1); id <= 3; id++ {
+		before.BroadcastLogDeleted(id)
+	}
+
+	var stored []sqlite.StoredEvent
+	deadline := time.Now().Add(2 * time.Second)
+	for len(stored) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		stored, _ = events.SinceContext(t.Context(), 0, 10)
+	}
+	if len(stored) != 3 {
+		t.Fatalf("expected 3 persisted events, got %d", len(stored))
+	}
+
+	// After a restart, a new hub replays from the table
+	hub := handlers.NewSSEHub()
+	hub.SetEventPersistence(sqlite.NewEventRepository(db), time.Minute)
+	server := httptest.NewServer(handlers.SSEHandler(hub))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Last-Event-ID", fmt.Sprint(stored[0].ID))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+
+	if event := readSSEEvent(t, reader); event.Type != "connected" {
+		t.Fatalf("expected connected event, got %s", event.Type)
+	}
+	for i, want := range stored[1:] {
+		event := readSSEEvent(t, reader)
+		data, _ := event.Data.(map[string]any)
+		if event.Type != "log_deleted" || event.ID != want.ID || data["id"] != float64(i+2) {
+			t.Errorf("replayed event %d: expected log_deleted id %d for log %d, got %+v", i, want.ID, i+2, event)
+		}
+	}
+
+	// Live events continue the ID sequence
+	hub.BroadcastLogDeleted(4)
+	event := readSSEEvent(t, reader)
+	if event.Type != "log_deleted" || event.ID != stored[2].ID+1 {
+		t.Errorf("expected live log_deleted with id %d, got %+v", stored[2].ID+1, event)
+	}
+}
+
+func TestSSEHandler_NoIDsWithoutPersistence(t *testing.T) {
+	hub := handlers.NewSSEHub()
+	server := httptest.NewServer(handlers.SSEHandler(hub))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+
+	if event := readSSEEvent(t, reader); event.Type != "connected" {
+		t.Fatalf("expected connected event, got %s", event.Type)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.ClientCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	hub.BroadcastLogDeleted(7)
+	if event := readSSEEvent(t, reader); event.Type != "log_deleted" || event.ID != 0 {
+		t.Errorf("expected log_deleted without an id, got %+v", event)
+	}
+}
+
+// openAlertStream connects to an alert stream and reads its connected event,
+// resuming after lastEventID when it is not zero.
+func openAlertStream(t *testing.T, hub *handlers.SSEHub, server *httptest.Server, query string, lastEventID int64) *bufio.Reader {
+	t.Helper()
+
+	clients := hub.ClientCount()
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/alerts/stream"+query, nil)
+	if lastEventID != 0 {
+		req.Header.Set("Last-Event-ID", fmt.Sprint(lastEventID))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to open alert stream: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	if event := readSSEEvent(t, reader); event.Type != "connected" {
+		t.Fatalf("expected connected event, got %s", event.Type)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.ClientCount() == clients && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return reader
+}
+
+func TestAlertStream_OnlyMatchingLogsAndReplay(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	hub := handlers.NewSSEHub()
+	hub.SetEventPersistence(sqlite.NewEventRepository(db), time.Minute)
+	router := chi.NewRouter()
+	router.Get("/api/alerts/stream", handlers.AlertStream(hub))
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	broadcast := func(id int64, title string, severity valueobjects.Severity) {
+		log := entities.NewLog(entities.LogHeader{Title: title, Severity: severity, Source: "billing"}, nil)
+		log.ID = id
+		hub.BroadcastLogCreated(log)
+	}
+
+	const query = "?min_severity=error&search=payment"
+	reader := openAlertStream(t, hub, server, query, 0)
+
+	broadcast(1, "Payment succeeded", valueobjects.SeverityInfo)
+	broadcast(2, "Payment failed", valueobjects.SeverityError)
+	broadcast(3, "Disk full", valueobjects.SeverityCritical)
+	hub.BroadcastLogDeleted(2)
+	broadcast(4, "Payment gateway down", valueobjects.SeverityCritical)
+
+	var lastID int64
+	for _, want := range []string{"Payment failed", "Payment gateway down"} {
+		event := readSSEEvent(t, reader)
+		if event.Type != "alert" || eventTitle(event) != want {
+			t.Fatalf("expected alert for %q, got %s %q", want, event.Type, eventTitle(event))
+		}
+		if event.ID <= lastID {
+			t.Fatalf("expected alert ids to increase, got %d after %d", event.ID, lastID)
+		}
+		lastID = event.ID
+	}
+
+	// Logs created while the client is away are replayed on reconnect,
+	// before live alerts resume
+	broadcast(5, "Payment retry failed", valueobjects.SeverityError)
+	broadcast(6, "Payment retried", valueobjects.SeverityInfo)
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		stored, _ := sqlite.NewEventRepository(db).SinceContext(t.Context(), lastID, 10)
+		if len(stored) == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	resumed := openAlertStream(t, hub, server, query, lastID)
+	if event := readSSEEvent(t, resumed); event.Type != "alert" || eventTitle(event) != "Payment retry failed" || event.ID <= lastID {
+		t.Fatalf("expected replayed alert for 'Payment retry failed' after id %d, got %s %q id %d", lastID, event.Type, eventTitle(event), event.ID)
+	}
+	broadcast(7, "Payment refund failed", valueobjects.SeverityError)
+	if event := readSSEEvent(t, resumed); event.Type != "alert" || eventTitle(event) != "Payment refund failed" {
+		t.Errorf("expected live alert for 'Payment refund failed', got %s %q", event.Type, eventTitle(event))
+	}
+}
+
+func TestAlertStream_InvalidFilter(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/alerts/stream?min_severity=loud", nil)
+	rec := httptest.NewRecorder()
+	handlers.AlertStream(handlers.NewSSEHub())(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestCreateLog_EscalatesRecurringLogs(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	escalator, err := handlers.NewEscalator(time.Minute, map[string]int{"error": 3, "critical": 5})
+	if err != nil {
+		t.Fatalf("NewEscalator failed: %v", err)
+	}
+	opts := handlers.DefaultIngestOptions()
+	opts.AutoAnalyze = false
+	opts.Escalator = escalator
+
+	hub := handlers.NewSSEHub()
+	router := chi.NewRouter()
+	router.Get("/api/events", handlers.SSEHandler(hub))
+	router.Post("/api/logs", handlers.CreateLogWithOptions(db, hub, &opts))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/events")
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	if event := readSSEEvent(t, reader); event.Type != "connected" {
+		t.Fatalf("expected connected event, got %s", event.Type)
+	}
+
+	// The same title from another source is counted separately
+	postLog(t, server, "Connection timeout", "warning", "billing")
+	if event := readSSEEvent(t, reader); event.Type != "log_created" {
+		t.Fatalf("expected log_created event, got %s", event.Type)
+	}
+
+	var escalations []handlers.SSEEvent
+	for occurrence := 1; occurrence <= 5; occurrence++ {
+		postLog(t, server, "Connection timeout", "warning", "orders")
+		if event := readSSEEvent(t, reader); event.Type != "log_created" {
+			t.Fatalf("occurrence %d: expected log_created event, got %s", occurrence, event.Type)
+		}
+		// The third and fifth occurrences cross a threshold
+		if occurrence == 3 || occurrence == 5 {
+			escalations = append(escalations, readSSEEvent(t, reader))
+		}
+	}
+
+	if len(escalations) != 2 {
+		t.Fatalf("expected 2 escalations, got %d", len(escalations))
+	}
+	for i, want := range []struct {
+		id          float64
+		from, to    string
+		occurrences float64
+	}{
+		{4, "warning", "error", 3},
+		{6, "warning", "critical", 5},
+	} {
+		event := escalations[i]
+		data, _ := event.Data.(map[string]any)
+		if event.Type != "log_escalated" || data["id"] != want.id || data["from"] != want.from || data["to"] != want.to ||
+			data["occurrences"] != want.occurrences || data["source"] != "orders" || data["window_seconds"] != float64(60) {
+			t.Errorf("escalation %d: expected log %v escalated from %s to %s after %v occurrences, got %s %v",
+				i, want.id, want.from, want.to, want.occurrences, event.Type, data)
+		}
+	}
+
+	repo := sqlite.NewLogRepository(db)
+	for id, want := range map[int64]valueobjects.Severity{1: "warning", 2: "warning", 3: "warning", 4: "error", 5: "error", 6: "critical"} {
+		log, err := repo.FindByID(id)
+		if err != nil {
+			t.Fatalf("failed to find log %d: %v", id, err)
+		}
+		if log.EffectiveSeverity() != want {
+			t.Errorf("log %d: expected effective severity %s, got %s", id, want, log.EffectiveSeverity())
+		}
+		if log.Header.Severity != "warning" {
+			t.Errorf("log %d: expected the sent severity to be kept, got %s", id, log.Header.Severity)
+		}
+	}
+}
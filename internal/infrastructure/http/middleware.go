@@ -1,13 +1,22 @@
 package http
 
 import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/mx-scribe/scribe/internal/infrastructure/http/handlers"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
 )
 
 // Metrics tracks server metrics.
@@ -28,12 +37,16 @@ func GetMetrics() *Metrics {
 // setupMiddleware configures all middleware for the server.
 func (s *Server) setupMiddleware() {
 	s.router.Use(middleware.RequestID)
+	s.router.Use(recordPeerAddr)
 	s.router.Use(middleware.RealIP)
 	s.router.Use(metricsMiddleware)
+	s.router.Use(s.serverTiming)
 	s.router.Use(requestLogger)
 	s.router.Use(middleware.Recoverer)
-	s.router.Use(rateLimiter(100, time.Second))
+	s.router.Use(rateLimiterWithExemption(100, time.Second, s.rateLimitExempted))
 	s.router.Use(corsMiddleware)
+	s.router.Use(s.scopeTenant)
+	s.router.Use(s.rejectWritesWhenReadOnly)
 	s.router.Use(middleware.SetHeader("Content-Type", "application/json"))
 }
 
@@ -59,7 +72,8 @@ func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-Request-ID")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-Request-ID, X-Tenant")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Total-Matched, X-Truncated")
 		w.Header().Set("Access-Control-Max-Age", "3600")
 
 		if r.Method == "OPTIONS" {
@@ -101,8 +115,198 @@ func metricsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// serverTiming adds a Server-Timing header reporting time spent in the
+// database and in total, so request latency is visible in browser devtools.
+// Database time is whatever repository calls recorded before the response
+// headers were written. Repository calls slower than the slow query
+// threshold are logged with the request's method, path and ID.
+func (s *Server) serverTiming(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, timer := sqlite.WithQueryTimer(r.Context())
+		timer.LogSlowQueries(s.slowQuery, slog.Default().With(
+			"method", r.Method,
+			"path", r.URL.Path,
+			"request_id", middleware.GetReqID(r.Context()),
+		))
+		tw := &timingResponseWriter{ResponseWriter: w, start: time.Now(), timer: timer}
+		next.ServeHTTP(tw, r.WithContext(ctx))
+	})
+}
+
+// timingResponseWriter sets the Server-Timing header just before the
+// response headers are sent.
+type timingResponseWriter struct {
+	http.ResponseWriter
+	start       time.Time
+	timer       *sqlite.QueryTimer
+	wroteHeader bool
+}
+
+func (w *timingResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		dbTime, _ := w.timer.Total()
+		w.Header().Set("Server-Timing", fmt.Sprintf("db;dur=%.2f, total;dur=%.2f",
+			durationMillis(dbTime), durationMillis(time.Since(w.start))))
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *timingResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush supports streaming handlers such as SSE.
+func (w *timingResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *timingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// durationMillis converts d to fractional milliseconds.
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// limitBody caps the request body at the server's configured size. Handlers
+// report an oversized body as 413 Request Entity Too Large.
+func (s *Server) limitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.maxBodyBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, s.maxBodyBytes)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitDuration gives the request a server-side deadline of the operation
+// timeout, so the queries of long operations are aborted once it passes.
+// Handlers report a passed deadline as 504 Gateway Timeout. The server's
+// write timeout is cleared, so the operation timeout alone bounds them and
+// a streamed export is not cut off mid-body.
+func (s *Server) limitDuration(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		if s.timeouts.Operation <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), s.timeouts.Operation)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// adminCredentials holds SHA-256 digests of the admin user and password so
+// comparisons run in constant time regardless of input length.
+type adminCredentials struct {
+	user     [sha256.Size]byte
+	password [sha256.Size]byte
+}
+
+func newAdminCredentials(user, password string) *adminCredentials {
+	return &adminCredentials{
+		user:     sha256.Sum256([]byte(user)),
+		password: sha256.Sum256([]byte(password)),
+	}
+}
+
+// matches reports whether user and password equal the stored credentials.
+func (c *adminCredentials) matches(user, password string) bool {
+	userHash := sha256.Sum256([]byte(user))
+	passwordHash := sha256.Sum256([]byte(password))
+
+	userOK := subtle.ConstantTimeCompare(userHash[:], c.user[:])
+	passwordOK := subtle.ConstantTimeCompare(passwordHash[:], c.password[:])
+	return userOK&passwordOK == 1
+}
+
+// rateLimitExempted reports whether r bypasses the rate limiter.
+func (s *Server) rateLimitExempted(r *http.Request) bool {
+	return s.rateLimitExempt != nil && s.rateLimitExempt.exempts(r)
+}
+
+// requireAdminAuth enforces HTTP Basic Auth on admin routes when admin
+// credentials are configured. Otherwise requests pass through unchanged.
+func (s *Server) requireAdminAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.adminAuth == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, password, ok := r.BasicAuth()
+		if !ok || !s.adminAuth.matches(user, password) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="scribe admin", charset="UTF-8"`)
+			handlers.ErrorResponse(w, r, http.StatusUnauthorized, "admin authentication required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tenantHeader is the request header naming the tenant a request acts for.
+const tenantHeader = "X-Tenant"
+
+// scopeTenant sets the tenant of each request from the X-Tenant header when
+// tenancy is enabled, so the repositories only see and create that tenant's
+// logs. Requests without the header act for the default tenant and an
+// invalid name answers 400. With tenancy disabled the header is ignored.
+func (s *Server) scopeTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant := r.Header.Get(tenantHeader)
+		if !s.tenancy || tenant == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !sqlite.ValidTenant(tenant) {
+			handlers.ErrorResponse(w, r, http.StatusBadRequest, "invalid "+tenantHeader+" header")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(sqlite.ContextWithTenant(r.Context(), tenant)))
+	})
+}
+
+// rejectWritesWhenReadOnly answers 503 to /api requests other than GET, HEAD
+// and OPTIONS while the server is in read-only maintenance mode.
+func (s *Server) rejectWritesWhenReadOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.readOnly.Load() || !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+		default:
+			w.Header().Set("Retry-After", "60")
+			handlers.ErrorResponse(w, r, http.StatusServiceUnavailable, "server is in read-only maintenance mode; writes are disabled until it ends")
+		}
+	})
+}
+
 // rateLimiter implements a simple token bucket rate limiter.
 func rateLimiter(limit int, window time.Duration) func(http.Handler) http.Handler {
+	return rateLimiterWithExemption(limit, window, nil)
+}
+
+// rateLimiterWithExemption is rateLimiter letting requests for which exempt
+// returns true through without consuming a token. A nil exempt exempts
+// nothing.
+func rateLimiterWithExemption(limit int, window time.Duration, exempt func(*http.Request) bool) func(http.Handler) http.Handler {
 	var (
 		mu       sync.Mutex
 		tokens   = limit
@@ -123,6 +327,11 @@ func rateLimiter(limit int, window time.Duration) func(http.Handler) http.Handle
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempt != nil && exempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			mu.Lock()
 
 			now := time.Now()
@@ -139,7 +348,7 @@ func rateLimiter(limit int, window time.Duration) func(http.Handler) http.Handle
 			if tokens <= 0 {
 				mu.Unlock()
 				w.Header().Set("Retry-After", "1")
-				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+				handlers.ErrorResponse(w, r, http.StatusTooManyRequests, "rate limit exceeded")
 				return
 			}
 
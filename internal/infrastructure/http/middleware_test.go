@@ -7,6 +7,8 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
 )
 
 // testHandler is a simple handler for testing middleware.
@@ -246,6 +248,90 @@ func TestRateLimiter_RefillTokens(t *testing.T) {
 	}
 }
 
+func TestRateLimiter_Exemptions(t *testing.T) {
+	exempt, err := newRateLimitExemptions([]string{"192.0.2.10", "10.1.0.0/16", "2001:db8::/32", "key:scraper-key"})
+	if err != nil {
+		t.Fatalf("failed to parse exemptions: %v", err)
+	}
+
+	// Peer addresses are recorded before RealIP, as in the server
+	limiter := rateLimiterWithExemption(2, time.Hour, exempt.exempts)
+	handler := recordPeerAddr(middleware.RealIP(limiter(http.HandlerFunc(testHandler))))
+
+	request := func(remoteAddr string, headers map[string]string) int {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = remoteAddr
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Exempt clients never consume tokens, so the limit is still unused
+	exemptClients := []struct {
+		remoteAddr string
+		headers    map[string]string
+	}{
+		{"192.0.2.10:4000", nil},
+		{"10.1.44.3:80", nil},
+		{"[2001:db8::7]:443", nil},
+		{"[::ffff:10.1.0.1]:80", nil},
+		{"198.51.100.1:80", map[string]string{"X-API-Key": "scraper-key"}},
+	}
+	for _, client := range exemptClients {
+		for i := 0; i < 5; i++ {
+			if code := request(client.remoteAddr, client.headers); code != http.StatusOK {
+				t.Fatalf("%s: expected exempt request %d to pass, got %d", client.remoteAddr, i+1, code)
+			}
+		}
+	}
+
+	// Non-exempt clients share the limit, whatever they claim to be
+	limited := []struct {
+		remoteAddr string
+		headers    map[string]string
+	}{
+		{"10.2.0.1:80", nil},
+		{"192.0.2.11:80", map[string]string{"X-API-Key": "wrong-key"}},
+		{"198.51.100.1:80", map[string]string{"X-Forwarded-For": "192.0.2.10"}},
+	}
+	codes := make([]int, len(limited))
+	for i, client := range limited {
+		codes[i] = request(client.remoteAddr, client.headers)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("expected two requests then a 429 for non-exempt clients, got %v", codes)
+	}
+
+	if code := request("192.0.2.10:4000", nil); code != http.StatusOK {
+		t.Errorf("expected exempt IP to pass once the limit is reached, got %d", code)
+	}
+}
+
+func TestValidateRateLimitExempt(t *testing.T) {
+	tests := []struct {
+		entry   string
+		wantErr bool
+	}{
+		{"192.0.2.10", false},
+		{"10.0.0.0/8", false},
+		{"::1", false},
+		{"2001:db8::/32", false},
+		{"key:abc123", false},
+		{"key:", true},
+		{"10.0.0.0/33", true},
+		{"monitoring", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		if err := ValidateRateLimitExempt(tt.entry); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateRateLimitExempt(%q) error = %v, wantErr %v", tt.entry, err, tt.wantErr)
+		}
+	}
+}
+
 // TestRequestLogger tests the request logging middleware.
 func TestRequestLogger(t *testing.T) {
 	handler := requestLogger(http.HandlerFunc(testHandler))
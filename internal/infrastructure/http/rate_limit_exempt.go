@@ -0,0 +1,128 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// apiKeyHeader is the request header carrying a client's API key.
+const apiKeyHeader = "X-API-Key"
+
+// apiKeyPrefix marks a rate limit exemption entry as an API key rather
+// than an IP address or CIDR range.
+const apiKeyPrefix = "key:"
+
+// peerAddrKey is the context key holding the connection's remote address,
+// recorded before middleware.RealIP replaces it with a forwarded one.
+type peerAddrKey struct{}
+
+// recordPeerAddr stores the connection's remote address in the request
+// context. Exemptions match on it rather than on forwarding headers, which
+// any client can set.
+func recordPeerAddr(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), peerAddrKey{}, r.RemoteAddr)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// rateLimitExemptions lists the clients that bypass the rate limiter, by
+// connection IP or by API key. Keys are held as SHA-256 digests so they are
+// compared in constant time.
+type rateLimitExemptions struct {
+	prefixes []netip.Prefix
+	keys     [][sha256.Size]byte
+}
+
+// newRateLimitExemptions parses exemption entries: IP addresses, CIDR ranges
+// and API keys written as "key:<key>".
+func newRateLimitExemptions(entries []string) (*rateLimitExemptions, error) {
+	e := &rateLimitExemptions{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if key, ok := strings.CutPrefix(entry, apiKeyPrefix); ok {
+			if key == "" {
+				return nil, fmt.Errorf("empty API key in rate limit exemption %q", entry)
+			}
+			e.keys = append(e.keys, sha256.Sum256([]byte(key)))
+			continue
+		}
+		prefix, err := parseExemptPrefix(entry)
+		if err != nil {
+			return nil, err
+		}
+		e.prefixes = append(e.prefixes, prefix)
+	}
+	return e, nil
+}
+
+// parseExemptPrefix parses an IP address or CIDR range, treating a single
+// address as a range holding only itself.
+func parseExemptPrefix(entry string) (netip.Prefix, error) {
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid CIDR %q in rate limit exemption", entry)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid IP %q in rate limit exemption (use %s<key> for API keys)", entry, apiKeyPrefix)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// ValidateRateLimitExempt checks that entry is a usable rate limit
+// exemption: an IP address, a CIDR range or "key:<key>".
+func ValidateRateLimitExempt(entry string) error {
+	_, err := newRateLimitExemptions([]string{entry})
+	return err
+}
+
+// exempts reports whether r comes from an exempt IP or carries an exempt
+// API key.
+func (e *rateLimitExemptions) exempts(r *http.Request) bool {
+	if len(e.prefixes) > 0 {
+		if addr, ok := peerAddr(r); ok {
+			for _, prefix := range e.prefixes {
+				if prefix.Contains(addr) {
+					return true
+				}
+			}
+		}
+	}
+
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		keyHash := sha256.Sum256([]byte(key))
+		matched := 0
+		for _, exempt := range e.keys {
+			matched |= subtle.ConstantTimeCompare(keyHash[:], exempt[:])
+		}
+		return matched == 1
+	}
+	return false
+}
+
+// peerAddr returns the IP of the connection r arrived on.
+func peerAddr(r *http.Request) (netip.Addr, bool) {
+	remote, ok := r.Context().Value(peerAddrKey{}).(string)
+	if !ok {
+		remote = r.RemoteAddr
+	}
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	addr, err := netip.ParseAddr(remote)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// routeFilter is a chi.Router that registers a plain 404 in place of the
+// handler for routes matched by a disabled-route pattern. Registering the
+// 404 (rather than nothing) keeps disabled top-level routes such as /metrics
+// from falling through to the web UI.
+type routeFilter struct {
+	chi.Router
+	prefix   string
+	disabled []string
+}
+
+// newRouteFilter wraps router, answering 404 for routes matched by any of
+// disabled.
+// See routeDisabled for the pattern syntax.
+func newRouteFilter(router chi.Router, disabled []string) chi.Router {
+	if len(disabled) == 0 {
+		return router
+	}
+	return &routeFilter{Router: router, disabled: disabled}
+}
+
+// Get registers a GET route, or a 404 when it is disabled.
+func (f *routeFilter) Get(pattern string, h http.HandlerFunc) {
+	if f.isDisabled(http.MethodGet, pattern) {
+		h = http.NotFound
+	}
+	f.Router.Get(pattern, h)
+}
+
+// Post registers a POST route, or a 404 when it is disabled.
+func (f *routeFilter) Post(pattern string, h http.HandlerFunc) {
+	if f.isDisabled(http.MethodPost, pattern) {
+		h = http.NotFound
+	}
+	f.Router.Post(pattern, h)
+}
+
+// Delete registers a DELETE route, or a 404 when it is disabled.
+func (f *routeFilter) Delete(pattern string, h http.HandlerFunc) {
+	if f.isDisabled(http.MethodDelete, pattern) {
+		h = http.NotFound
+	}
+	f.Router.Delete(pattern, h)
+}
+
+// With returns a filtered inline router using the given middlewares.
+func (f *routeFilter) With(middlewares ...func(http.Handler) http.Handler) chi.Router {
+	return &routeFilter{Router: f.Router.With(middlewares...), prefix: f.prefix, disabled: f.disabled}
+}
+
+// Route mounts a filtered sub-router on pattern. When a pattern disables
+// the whole subtree, the sub-router is replaced by a 404 so its middleware
+// (such as admin auth) never runs.
+func (f *routeFilter) Route(pattern string, fn func(r chi.Router)) chi.Router {
+	prefix := f.prefix + pattern
+	for _, disabled := range f.disabled {
+		if strings.HasSuffix(disabled, "/*") && !strings.Contains(disabled, " ") && routeDisabled(disabled, "", prefix) {
+			f.Router.Handle(pattern, http.NotFoundHandler())
+			f.Router.Handle(pattern+"/*", http.NotFoundHandler())
+			return f.Router
+		}
+	}
+	return f.Router.Route(pattern, func(r chi.Router) {
+		fn(&routeFilter{Router: r, prefix: prefix, disabled: f.disabled})
+	})
+}
+
+func (f *routeFilter) isDisabled(method, pattern string) bool {
+	route := f.prefix + pattern
+	for _, disabled := range f.disabled {
+		if routeDisabled(disabled, method, route) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeDisabled reports whether the disabled-route pattern matches a route
+// registered for method on path. Patterns are route paths as registered,
+// e.g. "/api/logs/{id}", optionally preceded by a method ("DELETE /api/logs").
+// A trailing "/*" also matches every route below the prefix, so
+// "/api/admin/*" disables all admin endpoints.
+func routeDisabled(pattern, method, path string) bool {
+	if m, p, ok := strings.Cut(pattern, " "); ok {
+		if !strings.EqualFold(m, method) {
+			return false
+		}
+		pattern = strings.TrimSpace(p)
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	}
+	return path == pattern
+}
+
+// ValidateDisabledRoute checks that pattern is a usable disabled-route pattern.
+func ValidateDisabledRoute(pattern string) error {
+	path := pattern
+	if m, p, ok := strings.Cut(pattern, " "); ok {
+		switch strings.ToUpper(m) {
+		case http.MethodGet, http.MethodPost, http.MethodDelete:
+		default:
+			return fmt.Errorf("unsupported method %q in disabled route %q", m, pattern)
+		}
+		path = strings.TrimSpace(p)
+	}
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("disabled route %q must start with /", pattern)
+	}
+	return nil
+}
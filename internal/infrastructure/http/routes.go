@@ -2,6 +2,7 @@ package http
 
 import (
 	"io/fs"
+	"net/http"
 
 	"github.com/go-chi/chi/v5"
 
@@ -10,36 +11,93 @@ import (
 
 // setupRoutes configures API routes for the server.
 func (s *Server) setupRoutes() {
-	s.router.Get("/health", handlers.Health)
+	router := newRouteFilter(s.router, s.disabledRoutes)
+
+	router.Get("/health", handlers.Health)
+	router.Get("/ready", handlers.ReadyWithOptions(s.db, s.readiness))
 
 	getMetrics := func() (uint64, int64, uint64) {
 		m := GetMetrics()
 		return m.TotalRequests, m.ActiveRequests, m.TotalErrors
 	}
-	s.router.Get("/metrics", handlers.MetricsHandler(getMetrics, s.sseHub))
-	s.router.Get("/metrics/prometheus", handlers.PrometheusMetricsHandler(getMetrics, s.sseHub))
+	router.Get("/metrics", handlers.MetricsHandler(getMetrics, s.sseHub))
+	router.Get("/metrics/prometheus", handlers.PrometheusMetricsHandlerWithOptions(getMetrics, s.sseHub, s.prometheus))
 
-	s.router.Route("/api", func(r chi.Router) {
-		r.Post("/logs", handlers.CreateLogWithSSE(s.db, s.sseHub))
-		r.Get("/logs", handlers.ListLogs(s.db))
+	router.Route("/api", func(r chi.Router) {
+		r.With(s.limitBody).Post("/logs", handlers.CreateLogWithOptions(s.db, s.sseHub, s.ingest))
+		r.Post("/logs/stream", handlers.StreamLogsWithOptions(s.db, s.sseHub, s.ingest))
+		r.With(s.limitBody).Post("/logs/text", handlers.CreateTextLogWithOptions(s.db, s.sseHub, s.ingest))
+		r.With(s.limitBody).Post("/analyze", handlers.AnalyzeLogWithOptions(s.ingest))
+		r.Get("/logs", handlers.ListLogsWithPagination(s.db, s.pagination))
+		r.Get("/logs/ids", handlers.ListLogIDsWithPagination(s.db, s.pagination))
+		r.Get("/logs/export-stream", handlers.TailLogsWithPagination(s.db, s.sseHub, s.pagination))
 		r.Get("/logs/{id}", handlers.GetLog(s.db))
 		r.Delete("/logs/{id}", handlers.DeleteLogWithSSE(s.db, s.sseHub))
-		r.Delete("/logs", handlers.DeleteLogsWithSSE(s.db, s.sseHub))
+		r.With(s.limitBody).Delete("/logs", s.deleteLogs(
+			handlers.DeleteLogsWithSSE(s.db, s.sseHub),
+			s.requireAdminAuth(handlers.DeleteLogsByFilterWithOptions(s.db, s.sseHub, s.timeFields)),
+		))
+		r.Post("/logs/{id}/pin", handlers.PinLog(s.db))
+		r.Delete("/logs/{id}/pin", handlers.UnpinLog(s.db))
+		r.Get("/logs/{id}/context", handlers.GetLogContext(s.db))
+		r.Get("/logs/{id}/annotations", handlers.ListAnnotations(s.db))
+		r.With(s.limitBody).Post("/logs/{id}/annotations", handlers.CreateAnnotation(s.db))
+
+		r.Get("/stats", handlers.GetStatsWithOptions(s.db, s.timeFields))
+		r.Get("/stats/top-errors", handlers.GetTopErrorsWithOptions(s.db, s.timeFields))
+		r.Get("/stats/errors-per-source", handlers.GetErrorsPerSourceWithOptions(s.db, s.timeFields))
+
+		r.Get("/facets", handlers.GetFacets(s.db))
 
-		r.Get("/stats", handlers.GetStats(s.db))
+		r.Get("/meta/enums", handlers.GetEnums)
 
-		r.Get("/export/json", handlers.ExportJSON(s.db))
-		r.Get("/export/csv", handlers.ExportCSV(s.db))
+		exportJSON := handlers.ExportJSONWithPagination(s.db, s.pagination)
+		exportCSV := handlers.ExportCSVWithPagination(s.db, s.pagination)
+		exportNDJSON := handlers.ExportNDJSONWithPagination(s.db, s.pagination)
+		exportXML := handlers.ExportXMLWithPagination(s.db, s.pagination)
+		exportArchive := handlers.ExportArchiveWithPagination(s.db, s.pagination)
+		r.With(s.limitDuration).Get("/export", handlers.ExportWithPagination(s.db, s.pagination))
+		r.With(s.limitDuration).Get("/export/json", exportJSON)
+		r.With(s.limitDuration).Get("/export/csv", exportCSV)
+		r.With(s.limitDuration).Get("/export/ndjson", exportNDJSON)
+		r.With(s.limitDuration).Get("/export/xml", exportXML)
+		r.With(s.limitDuration).Get("/export/archive", exportArchive)
+		exports := map[string]http.Handler{"json": exportJSON, "csv": exportCSV, "ndjson": exportNDJSON, "xml": exportXML, "archive": exportArchive}
+		r.With(s.requireAdminAuth, s.limitBody).Post("/export/sign", handlers.SignExport(s.exportSigner, []string{"json", "csv", "ndjson", "xml", "archive"}))
+		r.With(s.limitDuration).Get("/export/signed", handlers.SignedExport(s.exportSigner, exports))
 
 		r.Get("/events", handlers.SSEHandler(s.sseHub))
+		r.Get("/ws", handlers.WebSocketHandler(s.sseHub))
+		r.Get("/alerts/stream", handlers.AlertStream(s.sseHub))
 
 		r.Route("/admin", func(r chi.Router) {
-			r.Get("/retention", handlers.GetRetentionInfo(s.db))
-			r.Post("/cleanup", handlers.CleanupLogs(s.db))
+			r.Use(s.requireAdminAuth)
+			r.Get("/retention", handlers.GetRetentionInfoWithOptions(s.db, s.timeFields))
+			r.With(s.limitBody, s.limitDuration).Post("/cleanup", handlers.CleanupLogsWithOptions(s.db, s.timeFields))
+			r.With(s.limitBody).Post("/remap", handlers.RemapSeverityWithOptions(s.db, s.sseHub, s.timeFields))
+			r.Post("/import", handlers.ImportLogsWithOptions(s.db, s.ingest))
+			r.With(s.limitBody, s.limitDuration).Post("/reanalyze", handlers.ReanalyzeLogsWithOptions(s.db, s.sseHub, s.ingest, s.timeFields))
+			r.Get("/integrity", handlers.CheckIntegrity(s.db))
+			r.Post("/integrity/repair", handlers.RepairIntegrity(s.db))
 		})
 	})
 }
 
+// deleteLogs routes DELETE /api/logs to byFilter when the request has query
+// parameters other than pretty, and to byIDs, which reads the IDs from the
+// body, otherwise.
+func (s *Server) deleteLogs(byIDs, byFilter http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		query.Del("pretty")
+		if len(query) > 0 {
+			byFilter.ServeHTTP(w, r)
+			return
+		}
+		byIDs.ServeHTTP(w, r)
+	}
+}
+
 // SetStaticFS sets the embedded filesystem for serving static files.
 func (s *Server) SetStaticFS(staticFS fs.FS) {
 	s.staticFS = staticFS
@@ -4,7 +4,9 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/mx-scribe/scribe/internal/infrastructure/http/handlers"
@@ -285,6 +287,7 @@ func TestRoutes_AllRoutesRegistered(t *testing.T) {
 	// because it requires a timeout context to avoid blocking
 	expectedRoutes := []string{
 		"/health",
+		"/ready",
 		"/metrics",
 		"/metrics/prometheus",
 		"/api/logs",
@@ -292,6 +295,7 @@ func TestRoutes_AllRoutesRegistered(t *testing.T) {
 		"/api/export/csv",
 		"/api/export/json",
 		"/api/admin/retention",
+		"/api/meta/enums",
 	}
 
 	for _, route := range expectedRoutes {
@@ -334,6 +338,49 @@ func TestRoutes_ContentTypeJSON(t *testing.T) {
 	}
 }
 
+func TestRoutes_CreateLogLocation(t *testing.T) {
+	server := setupTestServer(t)
+	defer server.db.Close()
+
+	requests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+	}{
+		{"JSON log", "/api/logs", "application/json", `{"header":{"title":"created"},"body":{}}`},
+		{"text log", "/api/logs/text", "text/plain", "created from text"},
+	}
+
+	for _, tt := range requests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			server.router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if contentType := rec.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("Expected Content-Type 'application/json', got '%s'", contentType)
+			}
+
+			location := rec.Header().Get("Location")
+			if !strings.HasPrefix(location, "/api/logs/") {
+				t.Fatalf("Expected Location under /api/logs/, got '%s'", location)
+			}
+
+			req = httptest.NewRequest("GET", location, nil)
+			rec = httptest.NewRecorder()
+			server.router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Errorf("Expected GET %s to return 200, got %d", location, rec.Code)
+			}
+		})
+	}
+}
+
 func TestSSEHub_Integration(t *testing.T) {
 	server := setupTestServer(t)
 	defer server.db.Close()
@@ -360,3 +407,80 @@ func TestNewSSEHub(t *testing.T) {
 		t.Errorf("Expected 0 clients, got %d", hub.ClientCount())
 	}
 }
+
+func TestRoutes_DisabledRoutes(t *testing.T) {
+	db, err := sqlite.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+	if err := sqlite.RunMigrations(db.Conn()); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	server := NewServerWithDisabledRoutes(db, []string{"/api/export/*", "/api/admin/*", "DELETE /api/logs", "/metrics"})
+	server.SetAdminAuth("ops", "secret")
+	server.SetStaticFS(fstest.MapFS{"dist/index.html": {Data: []byte("<html></html>")}})
+
+	tests := []struct {
+		method, path string
+		wantStatus   int
+	}{
+		{"GET", "/api/export/json", http.StatusNotFound},
+		{"GET", "/api/export/csv", http.StatusNotFound},
+		{"GET", "/api/admin/retention", http.StatusNotFound},
+		{"POST", "/api/admin/cleanup", http.StatusNotFound},
+		{"DELETE", "/api/logs", http.StatusNotFound},
+		{"GET", "/metrics", http.StatusNotFound},
+		{"GET", "/metrics/prometheus", http.StatusOK},
+		{"GET", "/api/logs", http.StatusOK},
+		{"GET", "/api/stats", http.StatusOK},
+		{"GET", "/health", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+
+			server.router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRouteDisabled(t *testing.T) {
+	tests := []struct {
+		pattern, method, path string
+		want                  bool
+	}{
+		{"/api/export/*", "GET", "/api/export/json", true},
+		{"/api/export/*", "GET", "/api/export", true},
+		{"/api/export/*", "GET", "/api/exports", false},
+		{"/api/logs/{id}", "DELETE", "/api/logs/{id}", true},
+		{"/api/logs/{id}", "GET", "/api/logs/{id}/annotations", false},
+		{"DELETE /api/logs", "DELETE", "/api/logs", true},
+		{"delete /api/logs", "DELETE", "/api/logs", true},
+		{"DELETE /api/logs", "GET", "/api/logs", false},
+	}
+
+	for _, tt := range tests {
+		if got := routeDisabled(tt.pattern, tt.method, tt.path); got != tt.want {
+			t.Errorf("routeDisabled(%q, %q, %q) = %v, want %v", tt.pattern, tt.method, tt.path, got, tt.want)
+		}
+	}
+
+	for _, pattern := range []string{"/api/export/*", "POST /api/logs"} {
+		if err := ValidateDisabledRoute(pattern); err != nil {
+			t.Errorf("expected %q to be valid, got %v", pattern, err)
+		}
+	}
+	for _, pattern := range []string{"api/export", "PATCH /api/logs", ""} {
+		if err := ValidateDisabledRoute(pattern); err == nil {
+			t.Errorf("expected %q to be invalid", pattern)
+		}
+	}
+}
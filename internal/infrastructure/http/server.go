@@ -4,33 +4,119 @@ import (
 	"context"
 	"fmt"
 	"io/fs"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/mx-scribe/scribe/internal/application/queries"
+	"github.com/mx-scribe/scribe/internal/domain/services"
 	"github.com/mx-scribe/scribe/internal/infrastructure/http/handlers"
 	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
 )
 
+// DefaultMaxBodyBytes is the default request body limit for JSON endpoints.
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+// Timeouts bounds how long the server spends on a connection. A zero value
+// means no timeout. Long-lived streams (/api/events, /api/ws, the log
+// stream, tail, alert stream and import) clear their deadlines and are exempt,
+// as are exports, cleanup and reanalysis, which Operation bounds instead.
+type Timeouts struct {
+	// Read bounds reading a whole request, body included.
+	Read time.Duration
+	// ReadHeader bounds reading request headers, cutting off clients that
+	// send them slowly to hold connections open (slowloris).
+	ReadHeader time.Duration
+	// Write bounds writing a response, from the end of the header read.
+	Write time.Duration
+	// Idle bounds how long a keep-alive connection waits for a request.
+	Idle time.Duration
+	// Operation bounds the work behind exports, cleanup and reanalysis;
+	// their queries are aborted once it passes, even if the client waits.
+	Operation time.Duration
+}
+
+// DefaultTimeouts returns the built-in server timeouts.
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Read:       15 * time.Second,
+		ReadHeader: 5 * time.Second,
+		Write:      15 * time.Second,
+		Idle:       60 * time.Second,
+		Operation:  5 * time.Minute,
+	}
+}
+
 // Server represents the HTTP server.
 type Server struct {
-	router   *chi.Mux
-	server   *http.Server
-	db       *sqlite.Database
-	staticFS fs.FS
-	sseHub   *handlers.SSEHub
+	router          *chi.Mux
+	server          *http.Server
+	db              *sqlite.Database
+	staticFS        fs.FS
+	sseHub          *handlers.SSEHub
+	maxBodyBytes    int64
+	pagination      *queries.Pagination
+	adminAuth       *adminCredentials
+	rateLimitExempt *rateLimitExemptions
+	ingest          *handlers.IngestOptions
+	readiness       *handlers.ReadinessOptions
+	prometheus      *handlers.PrometheusOptions
+	timeFields      *handlers.TimeFieldOptions
+	exportSigner    *handlers.ExportSigner
+	timeouts        Timeouts
+	tenancy         bool
+
+	// slowQuery is the repository call duration above which a request logs
+	// a slow query warning; zero disables it.
+	slowQuery time.Duration
+
+	// readOnly rejects writes to /api; it can change while serving.
+	readOnly atomic.Bool
+
+	// onListen is called by Start once the listener is accepting.
+	onListen func(net.Addr)
+
+	// onReload is called by Start on SIGHUP.
+	onReload func()
+
+	// disabledRoutes lists route patterns that answer 404; see routeDisabled.
+	disabledRoutes []string
 }
 
 // NewServer creates a new HTTP server.
 func NewServer(db *sqlite.Database) *Server {
+	return NewServerWithDisabledRoutes(db, nil)
+}
+
+// NewServerWithDisabledRoutes creates a new HTTP server whose routes matching
+// any of disabled are not served and answer 404, e.g. "/api/export/*" or
+// "DELETE /api/logs". Patterns should be checked with ValidateDisabledRoute.
+func NewServerWithDisabledRoutes(db *sqlite.Database, disabled []string) *Server {
+	pagination := queries.DefaultPagination()
+	ingest := handlers.DefaultIngestOptions()
+	prometheus := handlers.DefaultPrometheusOptions()
+	timeFields := handlers.DefaultTimeFieldOptions()
 	s := &Server{
-		router: chi.NewRouter(),
-		db:     db,
-		sseHub: handlers.NewSSEHub(),
+		router:       chi.NewRouter(),
+		db:           db,
+		sseHub:       handlers.NewSSEHub(),
+		maxBodyBytes: DefaultMaxBodyBytes,
+		pagination:   &pagination,
+		ingest:       &ingest,
+		readiness:    &handlers.ReadinessOptions{},
+		prometheus:   &prometheus,
+		timeFields:   &timeFields,
+		exportSigner: handlers.NewExportSigner(nil),
+		timeouts:     DefaultTimeouts(),
+		slowQuery:    sqlite.DefaultSlowQueryThreshold,
+
+		disabledRoutes: disabled,
 	}
 
 	s.setupMiddleware()
@@ -39,48 +125,310 @@ func NewServer(db *sqlite.Database) *Server {
 	return s
 }
 
+// IngestOptions returns the ingestion options of POST /api/logs, for
+// ingestion paths served outside the router.
+func (s *Server) IngestOptions() *handlers.IngestOptions {
+	return s.ingest
+}
+
 // SSEHub returns the SSE hub for broadcasting events.
 func (s *Server) SSEHub() *handlers.SSEHub {
 	return s.sseHub
 }
 
+// SetMaxBodyBytes sets the request body limit for JSON endpoints.
+// A value of zero or less disables the limit.
+func (s *Server) SetMaxBodyBytes(n int64) {
+	s.maxBodyBytes = n
+}
+
+// SetAdminAuth requires HTTP Basic Auth with the given credentials on
+// /api/admin/* routes. An empty user or password removes the requirement.
+func (s *Server) SetAdminAuth(user, password string) {
+	if user == "" || password == "" {
+		s.adminAuth = nil
+		return
+	}
+	s.adminAuth = newAdminCredentials(user, password)
+}
+
+// SetExportSigningKey sets the HMAC key of signed export URLs, so they stay
+// valid across restarts and instances sharing the key. An empty key uses a
+// random one generated at startup.
+func (s *Server) SetExportSigningKey(key string) {
+	s.exportSigner.SetKey([]byte(key))
+}
+
+// SetTenancy sets whether requests are scoped to the tenant named by their
+// X-Tenant header: logs, stats, exports and live events of one tenant are
+// invisible to the others. Requests without the header use the default
+// tenant, which holds every log when tenancy is disabled. The header is
+// trusted as sent, so it should be set by an authenticating proxy.
+func (s *Server) SetTenancy(enabled bool) {
+	s.tenancy = enabled
+}
+
+// SetReadOnly switches maintenance mode on or off: while on, every /api
+// request other than GET, HEAD and OPTIONS answers 503 and reads, the
+// dashboard and exports keep working. It is safe to call while serving.
+func (s *Server) SetReadOnly(enabled bool) {
+	s.readOnly.Store(enabled)
+}
+
+// ReadOnly reports whether maintenance mode is on.
+func (s *Server) ReadOnly() bool {
+	return s.readOnly.Load()
+}
+
+// SetRateLimitExempt lets clients bypass the request rate limiter when they
+// connect from one of the listed IP addresses or CIDR ranges, or send one of
+// the listed "key:<key>" API keys in the X-API-Key header. IPs are matched
+// against the connection address, not forwarding headers. An empty list
+// exempts nobody.
+func (s *Server) SetRateLimitExempt(entries []string) error {
+	if len(entries) == 0 {
+		s.rateLimitExempt = nil
+		return nil
+	}
+	exempt, err := newRateLimitExemptions(entries)
+	if err != nil {
+		return err
+	}
+	s.rateLimitExempt = exempt
+	return nil
+}
+
+// SetAutoAnalyze sets whether new logs are run through the pattern matcher
+// by default. Clients can still override it per request with ?analyze=.
+func (s *Server) SetAutoAnalyze(enabled bool) {
+	s.ingest.AutoAnalyze = enabled
+}
+
+// SetSourceRateLimit caps POST /api/logs at perSecond logs per source, with
+// bursts of up to burst. When drop is true, excess logs are dropped and
+// counted instead of rejected with 429. A perSecond of zero or less removes
+// the limit.
+func (s *Server) SetSourceRateLimit(perSecond float64, burst int, drop bool) {
+	s.ingest.SourceLimit = handlers.NewSourceLimiter(perSecond, burst, drop)
+}
+
+// SetMinIngestSeverity makes POST /api/logs turn away logs whose effective
+// severity ranks below min. When drop is true, they are dropped and counted
+// with 204 instead of rejected with 422. An empty min removes the floor.
+func (s *Server) SetMinIngestSeverity(min string, drop bool) {
+	s.ingest.SeverityFloor = handlers.NewSeverityFloor(min, drop)
+}
+
+// SetSourceFilter makes POST /api/logs turn away logs whose effective
+// source matches none of allowed (when set) or any of denied, with * as a
+// wildcard, e.g. "internal-*". Denied wins over allowed. When drop is true,
+// they are dropped and counted with 204 instead of rejected with 403. Empty
+// lists remove the filter.
+func (s *Server) SetSourceFilter(allowed, denied []string, drop bool) {
+	s.ingest.SourceFilter = handlers.NewSourceFilter(allowed, denied, drop)
+}
+
+// SetMaxEventAge makes log ingestion reject logs whose supplied timestamp is
+// older than maxAge with 422. Logs without a timestamp are always accepted.
+// Zero removes the limit.
+func (s *Server) SetMaxEventAge(maxAge time.Duration) {
+	s.ingest.MaxEventAge = maxAge
+}
+
+// SetBodyNormalization enables copying aliased body fields to canonical keys
+// on POST /api/logs, using aliases (canonical key to alias keys) or the
+// defaults when aliases is empty. Disabled removes normalization.
+func (s *Server) SetBodyNormalization(enabled bool, aliases map[string][]string) {
+	if !enabled {
+		s.ingest.BodyNormalizer = nil
+		return
+	}
+	if len(aliases) == 0 {
+		aliases = nil
+	}
+	s.ingest.BodyNormalizer = services.NewBodyNormalizer(aliases)
+}
+
+// SetMetricsNaming sets the prefix and static labels applied to every series
+// served by /metrics/prometheus. Callers validate them with
+// handlers.PrometheusOptions.Validate.
+func (s *Server) SetMetricsNaming(prefix string, labels map[string]string) {
+	s.prometheus.Prefix = prefix
+	s.prometheus.Labels = labels
+}
+
+// SetTimeFields sets whether recency (the last-24-hours counts and the
+// stats ?since= windows) and retention (cleanup and age buckets) use the
+// event time or the ingestion time.
+func (s *Server) SetTimeFields(stats, retention sqlite.TimeField) {
+	s.timeFields.Stats = stats
+	s.timeFields.Retention = retention
+}
+
+// SetBodyRedaction enables masking of secrets in log bodies on ingest: the
+// values of keys containing any of keys, and parts of string values matching
+// any of patterns. Nil keys or patterns use the defaults. Disabled removes
+// redaction. It returns an error for an invalid pattern.
+func (s *Server) SetBodyRedaction(enabled bool, keys, patterns []string) error {
+	if !enabled {
+		s.ingest.BodyRedactor = nil
+		return nil
+	}
+	redactor, err := services.NewBodyRedactor(keys, patterns)
+	if err != nil {
+		return err
+	}
+	s.ingest.BodyRedactor = redactor
+	return nil
+}
+
+// SetPatternMatching configures the pattern matching run on ingestion and
+// by /api/analyze: HTTP status severity overrides and keyword scoring. Zero
+// options restore the built-in rules. It returns an error for invalid
+// options.
+func (s *Server) SetPatternMatching(opts services.PatternMatcherOptions) error {
+	if len(opts.HTTPStatusSeverity) == 0 && !opts.KeywordScoring {
+		s.ingest.PatternMatcher = nil
+		return nil
+	}
+	matcher, err := services.NewPatternMatcherWithOptions(opts)
+	if err != nil {
+		return err
+	}
+	s.ingest.PatternMatcher = matcher
+	return nil
+}
+
+// SetColorFromSeverity enables deriving a color from the effective severity
+// of logs created without one, using the built-in severity to color mapping
+// with overrides merged over it. Explicit colors are kept. Disabled removes
+// derivation. It returns an error for an invalid severity or color.
+func (s *Server) SetColorFromSeverity(enabled bool, overrides map[string]string) error {
+	if !enabled {
+		s.ingest.Colorizer = nil
+		return nil
+	}
+	colorizer, err := services.NewSeverityColorizer(overrides)
+	if err != nil {
+		return err
+	}
+	s.ingest.Colorizer = colorizer
+	return nil
+}
+
+// SetEscalation raises the severity of logs that recur on ingestion: once
+// the same title from the same source is seen as many times as a threshold
+// within window, it is stored with the threshold's severity and a
+// log_escalated event is broadcast. Empty thresholds disable escalation.
+func (s *Server) SetEscalation(window time.Duration, thresholds map[string]int) error {
+	escalator, err := handlers.NewEscalator(window, thresholds)
+	if err != nil {
+		return err
+	}
+	s.ingest.Escalator = escalator
+	return nil
+}
+
+// SetAllowSchemaMismatch sets whether /ready reports ready when the database
+// schema is not at the version the binary expects.
+func (s *Server) SetAllowSchemaMismatch(allow bool) {
+	s.readiness.AllowSchemaMismatch = allow
+}
+
+// SetPagination sets the page sizes used by the list and export endpoints.
+// Unset values fall back to the defaults.
+func (s *Server) SetPagination(pagination queries.Pagination) {
+	*s.pagination = pagination.Normalize()
+}
+
+// SetTimeouts sets the timeouts of the HTTP server created by Start.
+func (s *Server) SetTimeouts(timeouts Timeouts) {
+	s.timeouts = timeouts
+}
+
+// SetSlowQueryThreshold sets the repository call duration above which a
+// request logs a slow query warning with the call and its duration. Zero
+// disables the warnings.
+func (s *Server) SetSlowQueryThreshold(threshold time.Duration) {
+	s.slowQuery = threshold
+}
+
+// SetOnListen sets a function Start calls with the listening address once
+// the server accepts connections, e.g. to open the dashboard. It is not
+// called when the listener fails.
+func (s *Server) SetOnListen(fn func(net.Addr)) {
+	s.onListen = fn
+}
+
+// SetOnReload sets a function Start calls each time the process receives
+// SIGHUP, e.g. to re-read the config and apply the settings that can change
+// while serving.
+func (s *Server) SetOnReload(fn func()) {
+	s.onReload = fn
+}
+
+// httpServer returns the http.Server that serves s on addr.
+func (s *Server) httpServer(addr string) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           s.router,
+		ReadTimeout:       s.timeouts.Read,
+		ReadHeaderTimeout: s.timeouts.ReadHeader,
+		WriteTimeout:      s.timeouts.Write,
+		IdleTimeout:       s.timeouts.Idle,
+	}
+}
+
 // Start starts the HTTP server with graceful shutdown.
 func (s *Server) Start(port int) error {
-	s.server = &http.Server{
-		Addr:         fmt.Sprintf(":%d", port),
-		Handler:      s.router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	s.server = s.httpServer(fmt.Sprintf(":%d", port))
+
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("server error: %w", err)
 	}
 
 	serverErrors := make(chan error, 1)
 	go func() {
-		fmt.Printf("SCRIBE server starting on http://localhost:%d\n", port)
-		serverErrors <- s.server.ListenAndServe()
+		fmt.Printf("SCRIBE server starting on http://localhost:%d\n", listener.Addr().(*net.TCPAddr).Port)
+		serverErrors <- s.server.Serve(listener)
 	}()
 
+	if s.onListen != nil {
+		s.onListen(listener.Addr())
+	}
+
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
 
-	select {
-	case err := <-serverErrors:
-		if err != http.ErrServerClosed {
-			return fmt.Errorf("server error: %w", err)
-		}
-	case sig := <-shutdown:
-		fmt.Printf("\nReceived %v signal, shutting down...\n", sig)
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+
+	for {
+		select {
+		case err := <-serverErrors:
+			if err != http.ErrServerClosed {
+				return fmt.Errorf("server error: %w", err)
+			}
+			return nil
+		case <-reload:
+			if s.onReload != nil {
+				s.onReload()
+			}
+		case sig := <-shutdown:
+			fmt.Printf("\nReceived %v signal, shutting down...\n", sig)
 
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
 
-		if err := s.server.Shutdown(ctx); err != nil {
-			s.server.Close()
-			return fmt.Errorf("could not stop server gracefully: %w", err)
+			if err := s.server.Shutdown(ctx); err != nil {
+				s.server.Close()
+				return fmt.Errorf("could not stop server gracefully: %w", err)
+			}
+			return nil
 		}
 	}
-
-	return nil
 }
 
 // Router returns the chi router for testing.
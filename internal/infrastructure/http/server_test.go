@@ -1,9 +1,25 @@
 package http
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/mx-scribe/scribe/internal/application/queries"
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+	"github.com/mx-scribe/scribe/internal/infrastructure/http/handlers"
 	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
 )
 
@@ -223,3 +239,810 @@ func TestServer_RoutesRegistered(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_BodyLimit(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	server.SetMaxBodyBytes(256)
+	oversized := `{"header":{"title":"` + strings.Repeat("x", 512) + `"}}`
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPost, "/api/logs", oversized},
+		{http.MethodDelete, "/api/logs", `{"ids":[` + strings.Repeat("1,", 200) + `1]}`},
+		{http.MethodPost, "/api/admin/cleanup", `{"retention_days":30,"pad":"` + strings.Repeat("x", 512) + `"}`},
+		{http.MethodPost, "/api/logs/text", strings.Repeat("x", 512)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+
+			server.router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusRequestEntityTooLarge {
+				t.Errorf("expected status 413, got %d: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_BodyLimit_UnderLimitAndDisabled(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	body := `{"header":{"title":"` + strings.Repeat("x", 512) + `"}}`
+
+	for _, limit := range []int64{4096, 0} {
+		server.SetMaxBodyBytes(limit)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/logs", bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+
+		server.router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusCreated {
+			t.Errorf("limit %d: expected status 201, got %d: %s", limit, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestServer_AdminAuth(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	server.SetAdminAuth("ops", "s3cret")
+
+	tests := []struct {
+		name       string
+		user       string
+		password   string
+		setAuth    bool
+		wantStatus int
+	}{
+		{"correct credentials", "ops", "s3cret", true, http.StatusOK},
+		{"wrong password", "ops", "guess", true, http.StatusUnauthorized},
+		{"wrong user", "root", "s3cret", true, http.StatusUnauthorized},
+		{"missing credentials", "", "", false, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/retention", nil)
+			if tt.setAuth {
+				req.SetBasicAuth(tt.user, tt.password)
+			}
+			rec := httptest.NewRecorder()
+
+			server.router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusUnauthorized {
+				if got := rec.Header().Get("WWW-Authenticate"); !strings.HasPrefix(got, "Basic ") {
+					t.Errorf("expected Basic WWW-Authenticate challenge, got %q", got)
+				}
+				if !strings.Contains(rec.Body.String(), `"code":"unauthorized"`) {
+					t.Errorf("expected unauthorized error code, got %s", rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestServer_ExportSigningRequiresAdminAuth(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	server.SetAdminAuth("ops", "s3cret")
+	server.SetExportSigningKey("shared-key")
+
+	sign := func(auth bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/export/sign", strings.NewReader(`{"format":"json"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if auth {
+			req.SetBasicAuth("ops", "s3cret")
+		}
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := sign(false); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 signing without credentials, got %d", rec.Code)
+	}
+	rec := sign(true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 signing as admin, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var signed struct {
+		URL string `json:"url"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&signed)
+
+	// The link works without credentials, and for another server sharing the key
+	other, otherDB := setupServerTest(t)
+	defer otherDB.Close()
+	other.SetExportSigningKey("shared-key")
+	for _, s := range []*Server{server, other} {
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, signed.URL, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status 200 from signed URL, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestServer_DeleteLogsByFilterRequiresAdminAuth(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	server.SetAdminAuth("ops", "s3cret")
+
+	repo := sqlite.NewLogRepository(db)
+	for _, severity := range []valueobjects.Severity{valueobjects.SeverityDebug, valueobjects.SeverityDebug, valueobjects.SeverityError} {
+		log := entities.NewLog(entities.LogHeader{Title: "Log", Severity: severity, Source: "test"}, nil)
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	deleteLogs := func(target string, auth bool, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth {
+			req.SetBasicAuth("ops", "s3cret")
+		}
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := deleteLogs("/api/logs?severity=debug&source=test&confirm=true", false, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 without credentials, got %d", rec.Code)
+	}
+	if rec := deleteLogs("/api/logs?severity=debug&source=test", true, ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 without confirm, got %d", rec.Code)
+	}
+	rec := deleteLogs("/api/logs?severity=debug&source=test&confirm=true", true, "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"deleted":2`) {
+		t.Fatalf("expected 2 deleted, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Deleting by ID stays open
+	if rec := deleteLogs("/api/logs?pretty=true", false, `{"ids":[3]}`); rec.Code != http.StatusOK {
+		t.Errorf("expected status 200 deleting by ID, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if count, _ := repo.Count(); count != 0 {
+		t.Errorf("expected no logs left, got %d", count)
+	}
+}
+
+func TestServer_AdminAuth_ScopedToAdminRoutes(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	server.SetAdminAuth("ops", "s3cret")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/logs", nil)
+	rec := httptest.NewRecorder()
+
+	server.router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected non-admin route to stay open, got %d", rec.Code)
+	}
+}
+
+func TestServer_AdminAuth_Unset(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	for _, creds := range [][2]string{{"", ""}, {"ops", ""}} {
+		server.SetAdminAuth(creds[0], creds[1])
+
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/retention", nil)
+		rec := httptest.NewRecorder()
+
+		server.router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("credentials %q: expected status 200, got %d", creds, rec.Code)
+		}
+	}
+}
+
+func TestServer_ServerTimingHeader(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/logs", nil)
+	rec := httptest.NewRecorder()
+
+	server.router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	header := rec.Header().Get("Server-Timing")
+	pattern := regexp.MustCompile(`^db;dur=(\d+\.\d{2}), total;dur=(\d+\.\d{2})$`)
+	matches := pattern.FindStringSubmatch(header)
+	if matches == nil {
+		t.Fatalf("malformed Server-Timing header %q", header)
+	}
+
+	dbDur, _ := strconv.ParseFloat(matches[1], 64)
+	totalDur, _ := strconv.ParseFloat(matches[2], 64)
+	if dbDur <= 0 {
+		t.Errorf("expected database time to be recorded for a listing, got %s", header)
+	}
+	if dbDur > totalDur {
+		t.Errorf("expected db time <= total time, got %s", header)
+	}
+}
+
+func TestServer_Timeouts(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	defaults := server.httpServer(":0")
+	if defaults.ReadTimeout != 15*time.Second || defaults.ReadHeaderTimeout != 5*time.Second ||
+		defaults.WriteTimeout != 15*time.Second || defaults.IdleTimeout != 60*time.Second {
+		t.Errorf("Expected default timeouts, got read=%v header=%v write=%v idle=%v",
+			defaults.ReadTimeout, defaults.ReadHeaderTimeout, defaults.WriteTimeout, defaults.IdleTimeout)
+	}
+
+	server.SetTimeouts(Timeouts{Read: 3 * time.Second, ReadHeader: time.Second, Write: 4 * time.Second, Idle: 9 * time.Second})
+	configured := server.httpServer(":0")
+	if configured.ReadTimeout != 3*time.Second || configured.ReadHeaderTimeout != time.Second ||
+		configured.WriteTimeout != 4*time.Second || configured.IdleTimeout != 9*time.Second {
+		t.Errorf("Expected configured timeouts, got read=%v header=%v write=%v idle=%v",
+			configured.ReadTimeout, configured.ReadHeaderTimeout, configured.WriteTimeout, configured.IdleTimeout)
+	}
+	if configured.Handler != server.router {
+		t.Error("Expected the server to serve the router")
+	}
+}
+
+func TestServer_OnListen(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	var status int
+	server.SetOnListen(func(addr net.Addr) {
+		// The listener is accepting by the time the callback runs
+		resp, err := http.Get("http://" + addr.String() + "/health")
+		if err == nil {
+			status = resp.StatusCode
+			resp.Body.Close()
+		}
+		_ = server.server.Close()
+	})
+
+	if err := server.Start(0); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if status != http.StatusOK {
+		t.Errorf("Expected /health to answer 200 from the listen callback, got %d", status)
+	}
+}
+
+func TestServer_OnListenNotCalledWhenListenFails(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	taken, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer taken.Close()
+
+	called := false
+	server.SetOnListen(func(net.Addr) { called = true })
+
+	if err := server.Start(taken.Addr().(*net.TCPAddr).Port); err == nil {
+		t.Fatal("Expected Start to fail on a port in use")
+	}
+	if called {
+		t.Error("Expected no listen callback when the listener fails")
+	}
+}
+
+// startTestHTTPServer serves server on a local port with its configured
+// timeouts and returns the address.
+func startTestHTTPServer(t *testing.T, server *Server) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	httpServer := server.httpServer(listener.Addr().String())
+	go func() { _ = httpServer.Serve(listener) }()
+	t.Cleanup(func() { _ = httpServer.Close() })
+	return listener.Addr().String()
+}
+
+func TestServer_SlowHeadersCutOff(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+	server.SetTimeouts(Timeouts{ReadHeader: 100 * time.Millisecond})
+	addr := startTestHTTPServer(t, server)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	// Start a request but never finish its headers
+	if _, err := conn.Write([]byte("GET /health HTTP/1.1\r\nHost: scribe\r\n")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	start := time.Now()
+	_, err = io.ReadAll(conn)
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		t.Fatal("Expected the server to close the slow connection")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected connection to be cut off after the header timeout, took %v", elapsed)
+	}
+}
+
+func TestServer_SSEExemptFromWriteTimeout(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+	server.SetTimeouts(Timeouts{Write: 100 * time.Millisecond})
+	addr := startTestHTTPServer(t, server)
+
+	resp, err := http.Get("http://" + addr + "/api/events")
+	if err != nil {
+		t.Fatalf("Failed to open event stream: %v", err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); !strings.HasPrefix(line, "event: connected") {
+		t.Fatalf("Expected connected event, got %q", line)
+	}
+
+	// Outlive the write timeout, then expect the stream to still deliver
+	time.Sleep(300 * time.Millisecond)
+	server.SSEHub().BroadcastLogDeleted(42)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Expected stream to stay open past the write timeout: %v", err)
+		}
+		if strings.HasPrefix(line, "event: log_deleted") {
+			return
+		}
+	}
+}
+
+func TestServer_LogStreamExemptFromWriteTimeout(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+	server.SetTimeouts(Timeouts{Write: 100 * time.Millisecond})
+	addr := startTestHTTPServer(t, server)
+
+	body, pw := io.Pipe()
+	go func() {
+		_, _ = io.WriteString(pw, `{"header":{"title":"First line"}}`+"\n")
+		// Outlive the write timeout before finishing the stream
+		time.Sleep(300 * time.Millisecond)
+		_, _ = io.WriteString(pw, `{"header":{"title":"Second line"}}`+"\n")
+		_ = pw.Close()
+	}()
+
+	resp, err := http.Post("http://"+addr+"/api/logs/stream", "application/x-ndjson", body)
+	if err != nil {
+		t.Fatalf("Failed to stream logs: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var summary handlers.StreamSummary
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		t.Fatalf("Expected the summary past the write timeout, got %v", err)
+	}
+	if summary.Created != 2 {
+		t.Errorf("Expected 2 created logs, got %+v", summary)
+	}
+}
+
+func TestServer_ExportExemptFromWriteTimeout(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	// Enough body data to fill the socket buffers, so the server blocks
+	// writing while the client is slow to read
+	const count = 2000
+	filler := strings.Repeat("x", 4096)
+	logs := make([]*entities.Log, count)
+	for i := range logs {
+		logs[i] = entities.NewLog(entities.LogHeader{Title: fmt.Sprintf("Export %d", i)}, map[string]any{"filler": filler})
+	}
+	if err := sqlite.NewLogRepository(db).CreateBatchContext(context.Background(), logs); err != nil {
+		t.Fatalf("Failed to create logs: %v", err)
+	}
+
+	server.SetPagination(queries.Pagination{Export: queries.PageSize{Default: count, Max: count}})
+	server.SetTimeouts(Timeouts{Write: 100 * time.Millisecond, Operation: time.Minute})
+	addr := startTestHTTPServer(t, server)
+
+	resp, err := http.Get("http://" + addr + "/api/export/json")
+	if err != nil {
+		t.Fatalf("Failed to start export: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Outlive the write timeout before reading the body
+	time.Sleep(300 * time.Millisecond)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Expected the export to outlive the write timeout, got %v after %d bytes", err, len(body))
+	}
+	var exported []json.RawMessage
+	if err := json.Unmarshal(body, &exported); err != nil {
+		t.Fatalf("Expected a complete JSON export, got %v", err)
+	}
+	if len(exported) != count {
+		t.Errorf("Expected %d exported logs, got %d", count, len(exported))
+	}
+}
+
+// tenantRequest serves a request sent with the given X-Tenant header, if any.
+func tenantRequest(t *testing.T, server *Server, method, target, tenant, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if tenant != "" {
+		req.Header.Set("X-Tenant", tenant)
+	}
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// tenantLogTitles lists the titles of the logs the tenant sees.
+func tenantLogTitles(t *testing.T, server *Server, tenant string) []string {
+	t.Helper()
+	rec := tenantRequest(t, server, http.MethodGet, "/api/logs", tenant, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 listing logs, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var list struct {
+		Logs []struct {
+			Header struct {
+				Title string `json:"title"`
+			} `json:"header"`
+		} `json:"logs"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&list)
+	titles := make([]string, len(list.Logs))
+	for i, log := range list.Logs {
+		titles[i] = log.Header.Title
+	}
+	return titles
+}
+
+func TestServer_TenantIsolation(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+	server.SetTenancy(true)
+
+	ids := map[string]int64{}
+	for _, log := range []struct{ tenant, title string }{
+		{"team-a", "A1"}, {"team-a", "A2"}, {"team-b", "B1"}, {"", "Default"},
+	} {
+		rec := tenantRequest(t, server, http.MethodPost, "/api/logs", log.tenant, `{"header":{"title":"`+log.title+`","severity":"error"}}`)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status 201 creating %s, got %d: %s", log.title, rec.Code, rec.Body.String())
+		}
+		var created struct {
+			ID int64 `json:"id"`
+		}
+		_ = json.NewDecoder(rec.Body).Decode(&created)
+		ids[log.title] = created.ID
+	}
+
+	for tenant, want := range map[string]string{"team-a": "[A2 A1]", "team-b": "[B1]", "": "[Default]"} {
+		if got := fmt.Sprint(tenantLogTitles(t, server, tenant)); got != want {
+			t.Errorf("tenant %q: expected logs %s, got %s", tenant, want, got)
+		}
+
+		rec := tenantRequest(t, server, http.MethodGet, "/api/stats", tenant, "")
+		var stats struct {
+			Total      int            `json:"total"`
+			BySeverity map[string]int `json:"by_severity"`
+		}
+		_ = json.NewDecoder(rec.Body).Decode(&stats)
+		if wantTotal := strings.Count(want, " ") + 1; stats.Total != wantTotal || stats.BySeverity["error"] != wantTotal {
+			t.Errorf("tenant %q: expected %d logs in stats, got %+v", tenant, wantTotal, stats)
+		}
+	}
+
+	// Another tenant's log is not found, and cannot be deleted
+	target := "/api/logs/" + strconv.FormatInt(ids["A1"], 10)
+	if rec := tenantRequest(t, server, http.MethodGet, target, "team-b", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 reading another tenant's log, got %d", rec.Code)
+	}
+	tenantRequest(t, server, http.MethodDelete, target, "team-b", "")
+	if rec := tenantRequest(t, server, http.MethodGet, target, "team-a", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected the log to survive another tenant's delete, got %d", rec.Code)
+	}
+
+	if rec := tenantRequest(t, server, http.MethodGet, "/api/logs", "../team-a", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid tenant, got %d", rec.Code)
+	}
+}
+
+func TestServer_TenancyDisabled(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	tenantRequest(t, server, http.MethodPost, "/api/logs", "team-a", `{"header":{"title":"Shared"}}`)
+
+	// Without tenancy the header is ignored and every log is in the default tenant
+	for _, tenant := range []string{"", "team-b", "../invalid"} {
+		if got := fmt.Sprint(tenantLogTitles(t, server, tenant)); got != "[Shared]" {
+			t.Errorf("tenant %q: expected the shared log, got %s", tenant, got)
+		}
+	}
+}
+
+func TestServer_TenantSignedExport(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+	server.SetTenancy(true)
+
+	tenantRequest(t, server, http.MethodPost, "/api/logs", "team-a", `{"header":{"title":"A1"}}`)
+	tenantRequest(t, server, http.MethodPost, "/api/logs", "", `{"header":{"title":"Default"}}`)
+
+	rec := tenantRequest(t, server, http.MethodPost, "/api/export/sign", "team-a", `{"format":"json"}`)
+	var signed struct {
+		URL string `json:"url"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&signed)
+
+	// The link exports the signing tenant's logs, whoever follows it
+	for _, tenant := range []string{"", "team-b"} {
+		rec := tenantRequest(t, server, http.MethodGet, signed.URL, tenant, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200 from signed URL, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if body := rec.Body.String(); !strings.Contains(body, `"A1"`) || strings.Contains(body, `"Default"`) {
+			t.Errorf("tenant %q: expected only team-a logs in export, got %s", tenant, body)
+		}
+	}
+}
+
+func TestServer_TenantLiveEvents(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+	server.SetTenancy(true)
+	addr := startTestHTTPServer(t, server)
+
+	subscribe := func(tenant string) *bufio.Reader {
+		req, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/api/events", nil)
+		req.Header.Set("X-Tenant", tenant)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to open event stream: %v", err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		reader := bufio.NewReader(resp.Body)
+		if line, _ := reader.ReadString('\n'); !strings.HasPrefix(line, "event: connected") {
+			t.Fatalf("Expected connected event, got %q", line)
+		}
+		return reader
+	}
+	streamA, streamB := subscribe("team-a"), subscribe("team-b")
+
+	// team-a's log is created first, so team-b's stream would see it first
+	for _, tenant := range []string{"team-a", "team-b"} {
+		rec := tenantRequest(t, server, http.MethodPost, "/api/logs", tenant, `{"header":{"title":"Log of `+tenant+`"}}`)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	for tenant, stream := range map[string]*bufio.Reader{"team-a": streamA, "team-b": streamB} {
+		for {
+			line, err := stream.ReadString('\n')
+			if err != nil {
+				t.Fatalf("tenant %s: stream ended: %v", tenant, err)
+			}
+			if !strings.HasPrefix(line, "data: ") || !strings.Contains(line, `"log_created"`) {
+				continue
+			}
+			if !strings.Contains(line, "Log of "+tenant) {
+				t.Errorf("tenant %s: expected its own log first, got %s", tenant, line)
+			}
+			break
+		}
+	}
+}
+
+func TestServer_StatsTimeRecency(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	// Received now, but the event happened three days ago
+	eventTime := time.Now().UTC().Add(-72 * time.Hour).Format(time.RFC3339)
+	body := fmt.Sprintf(`{"header":{"title":"Replayed failure","severity":"error","source":"api"},"timestamp":%q}`, eventTime)
+	if rec := tenantRequest(t, server, http.MethodPost, "/api/logs", "", body); rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	recent := func(target string, v any) {
+		t.Helper()
+		rec := tenantRequest(t, server, http.MethodGet, target, "", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", target, rec.Code, rec.Body.String())
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", target, err)
+		}
+	}
+
+	tests := []struct {
+		field sqlite.TimeField
+		want  int
+	}{
+		{sqlite.IngestedAt, 1},
+		{sqlite.EventTime, 0},
+	}
+
+	for _, tt := range tests {
+		server.SetTimeFields(tt.field, sqlite.EventTime)
+
+		var stats, retention struct {
+			Last24Hours int `json:"last_24_hours"`
+		}
+		recent("/api/stats", &stats)
+		recent("/api/admin/retention", &retention)
+
+		var topErrors struct {
+			Scanned int `json:"scanned"`
+		}
+		recent("/api/stats/top-errors?since=24h", &topErrors)
+
+		var sources []struct {
+			TotalCount int `json:"total_count"`
+		}
+		recent("/api/stats/errors-per-source?since=24h", &sources)
+		perSource := 0
+		for _, source := range sources {
+			perSource += source.TotalCount
+		}
+
+		got := map[string]int{
+			"stats":             stats.Last24Hours,
+			"retention":         retention.Last24Hours,
+			"top-errors":        topErrors.Scanned,
+			"errors-per-source": perSource,
+		}
+		for view, count := range got {
+			if count != tt.want {
+				t.Errorf("%s: expected %s to count %d recent logs, got %d", tt.field, view, tt.want, count)
+			}
+		}
+	}
+}
+
+func TestServer_ReadOnly(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	rec := tenantRequest(t, server, http.MethodPost, "/api/logs", "", `{"header":{"title":"before maintenance"},"body":{}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 creating a log, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	server.SetReadOnly(true)
+	if !server.ReadOnly() {
+		t.Fatal("expected ReadOnly true after SetReadOnly(true)")
+	}
+
+	blocked := []struct {
+		method, target, body string
+	}{
+		{http.MethodPost, "/api/logs", `{"header":{"title":"during maintenance"},"body":{}}`},
+		{http.MethodDelete, "/api/logs/1", ""},
+	}
+	for _, tc := range blocked {
+		rec := tenantRequest(t, server, tc.method, tc.target, "", tc.body)
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s %s: expected status 503, got %d: %s", tc.method, tc.target, rec.Code, rec.Body.String())
+			continue
+		}
+		if rec.Header().Get("Retry-After") == "" {
+			t.Errorf("%s %s: expected a Retry-After header", tc.method, tc.target)
+		}
+		if !strings.Contains(rec.Body.String(), "read-only") {
+			t.Errorf("%s %s: expected the error to mention read-only mode, got %s", tc.method, tc.target, rec.Body.String())
+		}
+	}
+
+	for _, target := range []string{"/api/logs", "/api/logs/1", "/api/export/csv"} {
+		rec := tenantRequest(t, server, http.MethodGet, target, "", "")
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET %s: expected status 200 in read-only mode, got %d: %s", target, rec.Code, rec.Body.String())
+		}
+	}
+	if titles := tenantLogTitles(t, server, ""); len(titles) != 1 || titles[0] != "before maintenance" {
+		t.Errorf("expected only the log created before maintenance, got %v", titles)
+	}
+
+	server.SetReadOnly(false)
+	rec = tenantRequest(t, server, http.MethodDelete, "/api/logs/1", "", "")
+	if rec.Code != http.StatusOK && rec.Code != http.StatusNoContent {
+		t.Errorf("expected DELETE to succeed after leaving read-only mode, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestServer_OperationTimeout(t *testing.T) {
+	server, db := setupServerTest(t)
+	defer db.Close()
+
+	rec := tenantRequest(t, server, http.MethodPost, "/api/logs", "", `{"header":{"title":"Short-lived"},"body":{},"expires_in":"1ms"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 creating a log, got %d: %s", rec.Code, rec.Body.String())
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// A deadline that has passed by the time the handler queries
+	server.SetTimeouts(Timeouts{Operation: time.Nanosecond})
+
+	for _, tc := range []struct {
+		method, target, body string
+	}{
+		{http.MethodGet, "/api/export/json", ""},
+		{http.MethodGet, "/api/export/csv", ""},
+		{http.MethodPost, "/api/admin/cleanup", `{"retention_days":30}`},
+		{http.MethodPost, "/api/admin/cleanup", `{"retention_days":30,"dry_run":true}`},
+	} {
+		rec := tenantRequest(t, server, tc.method, tc.target, "", tc.body)
+		if rec.Code != http.StatusGatewayTimeout {
+			t.Errorf("%s %s %s: expected status 504, got %d: %s", tc.method, tc.target, tc.body, rec.Code, rec.Body.String())
+			continue
+		}
+		var resp struct {
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Error.Code != "timeout" {
+			t.Errorf("%s %s: expected error code timeout, got %s", tc.method, tc.target, rec.Body.String())
+		}
+	}
+
+	rec = tenantRequest(t, server, http.MethodPost, "/api/admin/reanalyze", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the reanalysis stream to start, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "event: summary") || !strings.Contains(rec.Body.String(), "operation timeout") {
+		t.Errorf("expected a summary event reporting the timeout, got %s", rec.Body.String())
+	}
+
+	// The timed-out cleanup was rolled back; with time to finish it runs
+	if titles := tenantLogTitles(t, server, ""); len(titles) != 1 {
+		t.Fatalf("expected the expired log to survive the timed-out cleanup, got %v", titles)
+	}
+	server.SetTimeouts(DefaultTimeouts())
+	rec = tenantRequest(t, server, http.MethodPost, "/api/admin/cleanup", "", `{"retention_days":30}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"deleted_count":1`) {
+		t.Errorf("expected the cleanup to delete the expired log, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
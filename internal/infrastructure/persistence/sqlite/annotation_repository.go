@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+)
+
+// AnnotationRepository handles log annotation persistence operations.
+type AnnotationRepository struct {
+	db *Database
+}
+
+// NewAnnotationRepository creates a new annotation repository.
+func NewAnnotationRepository(db *Database) *AnnotationRepository {
+	return &AnnotationRepository{db: db}
+}
+
+// Create inserts a new annotation into the database.
+func (r *AnnotationRepository) Create(annotation *entities.Annotation) error {
+	return r.CreateContext(context.Background(), annotation)
+}
+
+// CreateContext inserts a new annotation into the database, honoring ctx cancellation.
+func (r *AnnotationRepository) CreateContext(ctx context.Context, annotation *entities.Annotation) error {
+	defer observeQuery(ctx, time.Now())
+
+	result, err := r.db.Conn().ExecContext(ctx,
+		"INSERT INTO log_annotations (log_id, author, text, created_at) VALUES (?, ?, ?, ?)",
+		annotation.LogID, annotation.Author, annotation.Text, annotation.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert annotation: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	annotation.ID = id
+	return nil
+}
+
+// FindByLogID retrieves all annotations for a log, oldest first.
+func (r *AnnotationRepository) FindByLogID(logID int64) ([]*entities.Annotation, error) {
+	return r.FindByLogIDContext(context.Background(), logID)
+}
+
+// FindByLogIDContext retrieves all annotations for a log, oldest first, honoring ctx cancellation.
+func (r *AnnotationRepository) FindByLogIDContext(ctx context.Context, logID int64) ([]*entities.Annotation, error) {
+	defer observeQuery(ctx, time.Now())
+
+	rows, err := r.db.Conn().QueryContext(ctx, `
+		SELECT id, log_id, author, text, created_at
+		FROM log_annotations
+		WHERE log_id = ?
+		ORDER BY created_at ASC, id ASC`,
+		logID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query annotations: %w", err)
+	}
+	defer rows.Close()
+
+	annotations := make([]*entities.Annotation, 0)
+	for rows.Next() {
+		var annotation entities.Annotation
+		if err := rows.Scan(
+			&annotation.ID,
+			&annotation.LogID,
+			&annotation.Author,
+			&annotation.Text,
+			&annotation.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan annotation: %w", err)
+		}
+		annotations = append(annotations, &annotation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate annotations: %w", err)
+	}
+
+	return annotations, nil
+}
@@ -0,0 +1,101 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+)
+
+func TestAnnotationRepository_CreateAndFind(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	logRepo := NewLogRepository(db)
+	repo := NewAnnotationRepository(db)
+
+	log := createTestLog("Payment failed", valueobjects.SeverityError)
+	if err := logRepo.Create(log); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+
+	for _, text := range []string{"Known issue, see JIRA-123", "Fixed in 1.4.2"} {
+		annotation := entities.NewAnnotation(log.ID, "alex", text)
+		if err := repo.Create(annotation); err != nil {
+			t.Fatalf("failed to create annotation: %v", err)
+		}
+		if annotation.ID == 0 {
+			t.Error("expected annotation ID to be set")
+		}
+	}
+
+	annotations, err := repo.FindByLogID(log.ID)
+	if err != nil {
+		t.Fatalf("failed to find annotations: %v", err)
+	}
+	if len(annotations) != 2 {
+		t.Fatalf("expected 2 annotations, got %d", len(annotations))
+	}
+	if annotations[0].Text != "Known issue, see JIRA-123" || annotations[0].Author != "alex" {
+		t.Errorf("unexpected first annotation: %+v", annotations[0])
+	}
+
+	found, err := logRepo.FindByID(log.ID)
+	if err != nil {
+		t.Fatalf("failed to find log: %v", err)
+	}
+	if found.AnnotationCount != 2 {
+		t.Errorf("expected annotation count 2, got %d", found.AnnotationCount)
+	}
+}
+
+func TestAnnotationRepository_FindByLogID_Empty(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	annotations, err := NewAnnotationRepository(db).FindByLogID(1)
+	if err != nil {
+		t.Fatalf("failed to find annotations: %v", err)
+	}
+	if annotations == nil || len(annotations) != 0 {
+		t.Errorf("expected empty slice, got %v", annotations)
+	}
+}
+
+func TestAnnotationRepository_Create_UnknownLog(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	err := NewAnnotationRepository(db).Create(entities.NewAnnotation(99999, "alex", "orphan"))
+	if err == nil {
+		t.Error("expected foreign key error for unknown log")
+	}
+}
+
+func TestAnnotationRepository_CascadeDelete(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	logRepo := NewLogRepository(db)
+	repo := NewAnnotationRepository(db)
+
+	log := createTestLog("Disk full", valueobjects.SeverityCritical)
+	if err := logRepo.Create(log); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+	if err := repo.Create(entities.NewAnnotation(log.ID, "sam", "Cleaned /tmp")); err != nil {
+		t.Fatalf("failed to create annotation: %v", err)
+	}
+
+	if err := logRepo.Delete(log.ID); err != nil {
+		t.Fatalf("failed to delete log: %v", err)
+	}
+
+	var count int
+	if err := db.Conn().QueryRow("SELECT COUNT(*) FROM log_annotations").Scan(&count); err != nil {
+		t.Fatalf("failed to count annotations: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected annotations to be cascade-deleted, got %d", count)
+	}
+}
@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql/driver"
+	"io"
+
+	moderncsqlite "modernc.org/sqlite"
+)
+
+const (
+	// bodyEncodingGzip marks a body stored gzip-compressed. Bodies with an
+	// empty encoding are stored as plain JSON text.
+	bodyEncodingGzip = "gzip"
+
+	// bodyCompressMinBytes is the smallest body compressed when compression
+	// is enabled; gzip does not shrink smaller ones enough to pay off.
+	bodyCompressMinBytes = 256
+
+	// bodyExpr reads a log's body as JSON text whatever its encoding, so
+	// queries and filters work on compressed and plain bodies alike.
+	bodyExpr = "scribe_body(body, body_encoding)"
+)
+
+func init() {
+	if err := moderncsqlite.RegisterDeterministicScalarFunction("scribe_body", 2, bodyFunction); err != nil {
+		panic(err)
+	}
+}
+
+// bodyFunction implements scribe_body(body, encoding), returning the body
+// decompressed when its encoding is gzip. A body that fails to decompress is
+// returned as stored, so the integrity check reports it.
+func bodyFunction(_ *moderncsqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	var stored []byte
+	switch v := args[0].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		stored = []byte(v)
+	case []byte:
+		stored = v
+	default:
+		return v, nil
+	}
+
+	if encoding, _ := args[1].(string); encoding == bodyEncodingGzip {
+		if body, err := decompressBody(stored); err == nil {
+			return string(body), nil
+		}
+	}
+	return string(stored), nil
+}
+
+// compressBody gzips a JSON body.
+func compressBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompressBody reverses compressBody.
+func decompressBody(stored []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(stored))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
@@ -11,8 +11,15 @@ import (
 type Database struct {
 	conn *sql.DB
 	path string
+
+	compressBodies bool
+	unknownSource  string
 }
 
+// DefaultUnknownSourceLabel is the source reported for logs with neither an
+// explicit nor a derived source.
+const DefaultUnknownSourceLabel = "unknown"
+
 // NewDatabase creates a new database connection with WAL mode.
 func NewDatabase(dbPath string) (*Database, error) {
 	// Connection string with pragmas for WAL mode
@@ -61,6 +68,29 @@ func (db *Database) Close() error {
 	return db.conn.Close()
 }
 
+// SetCompressBodies sets whether log bodies are stored gzip-compressed.
+// Bodies are compressed as they are written, so rows stored either way stay
+// readable; small bodies are always stored as plain text.
+func (db *Database) SetCompressBodies(enabled bool) {
+	db.compressBodies = enabled
+}
+
+// SetUnknownSourceLabel sets the source reported by source stats for logs
+// with neither an explicit nor a derived source; a source filter on it
+// matches those logs. An empty label restores DefaultUnknownSourceLabel.
+func (db *Database) SetUnknownSourceLabel(label string) {
+	db.unknownSource = label
+}
+
+// UnknownSourceLabel returns the source reported for logs with neither an
+// explicit nor a derived source.
+func (db *Database) UnknownSourceLabel() string {
+	if db.unknownSource == "" {
+		return DefaultUnknownSourceLabel
+	}
+	return db.unknownSource
+}
+
 // Path returns the database file path.
 func (db *Database) Path() string {
 	return db.path
@@ -0,0 +1,98 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// StoredEvent is a broadcast live event kept for replay.
+type StoredEvent struct {
+	ID        int64
+	Type      string
+	Data      json.RawMessage
+	CreatedAt time.Time
+}
+
+// EventRepository persists recent live events so clients reconnecting with
+// a Last-Event-ID can catch up, even across a server restart.
+type EventRepository struct {
+	db *Database
+}
+
+// NewEventRepository creates a new event repository.
+func NewEventRepository(db *Database) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+// AppendContext stores an event of the tenant of ctx and returns its ID. IDs
+// increase with every event and are never reused, honoring ctx cancellation.
+func (r *EventRepository) AppendContext(ctx context.Context, eventType string, data json.RawMessage, createdAt time.Time) (int64, error) {
+	defer observeQuery(ctx, time.Now())
+
+	result, err := r.db.Conn().ExecContext(ctx,
+		"INSERT INTO sse_events (type, data, created_at, tenant) VALUES (?, ?, ?, ?)",
+		eventType, string(data), createdAt, TenantFromContext(ctx),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert event: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	return id, nil
+}
+
+// SinceContext returns up to limit events of the tenant of ctx with an ID
+// above afterID, oldest first, honoring ctx cancellation.
+func (r *EventRepository) SinceContext(ctx context.Context, afterID int64, limit int) ([]StoredEvent, error) {
+	defer observeQuery(ctx, time.Now())
+
+	rows, err := r.db.Conn().QueryContext(ctx,
+		"SELECT id, type, data, created_at FROM sse_events WHERE tenant = ? AND id > ? ORDER BY id ASC LIMIT ?",
+		TenantFromContext(ctx), afterID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]StoredEvent, 0)
+	for rows.Next() {
+		var event StoredEvent
+		var data string
+		if err := rows.Scan(&event.ID, &event.Type, &data, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		event.Data = json.RawMessage(data)
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate events: %w", err)
+	}
+	return events, nil
+}
+
+// PruneContext deletes events created before cutoff, then all but the
+// newest maxRows events of all tenants, returning how many were deleted. It
+// honors ctx cancellation.
+func (r *EventRepository) PruneContext(ctx context.Context, cutoff time.Time, maxRows int) (int64, error) {
+	defer observeQuery(ctx, time.Now())
+
+	result, err := r.db.Conn().ExecContext(ctx,
+		"DELETE FROM sse_events WHERE created_at < ? OR id <= (SELECT COALESCE(MAX(id), 0) FROM sse_events) - ?",
+		cutoff, maxRows,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune events: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected, nil
+}
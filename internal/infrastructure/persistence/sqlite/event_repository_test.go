@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestEventRepository_AppendAndSince(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewEventRepository(db)
+	ctx := context.Background()
+
+	var ids []int64
+	for _, data := range []string{`{"id":1}`, `{"id":2}`, `{"id":3}`} {
+		id, err := repo.AppendContext(ctx, "log_deleted", json.RawMessage(data), time.Now())
+		if err != nil {
+			t.Fatalf("failed to append event: %v", err)
+		}
+		if len(ids) > 0 && id <= ids[len(ids)-1] {
+			t.Errorf("expected increasing event IDs, got %d after %d", id, ids[len(ids)-1])
+		}
+		ids = append(ids, id)
+	}
+
+	events, err := repo.SinceContext(ctx, ids[0], 10)
+	if err != nil {
+		t.Fatalf("failed to read events: %v", err)
+	}
+	if len(events) != 2 || events[0].ID != ids[1] || events[1].ID != ids[2] {
+		t.Fatalf("expected events %v, got %+v", ids[1:], events)
+	}
+	if events[0].Type != "log_deleted" || string(events[0].Data) != `{"id":2}` {
+		t.Errorf("unexpected event: %+v", events[0])
+	}
+
+	if events, _ := repo.SinceContext(ctx, 0, 1); len(events) != 1 || events[0].ID != ids[0] {
+		t.Errorf("expected limit to return the oldest event only, got %+v", events)
+	}
+}
+
+func TestEventRepository_Prune(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewEventRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	for i, age := range []time.Duration{time.Hour, 2 * time.Minute, time.Minute, 30 * time.Second, 0} {
+		data := json.RawMessage(fmt.Sprintf(`{"n":%d}`, i))
+		if _, err := repo.AppendContext(ctx, "stats_updated", data, now.Add(-age)); err != nil {
+			t.Fatalf("failed to append event: %v", err)
+		}
+	}
+
+	// The hour-old event has expired and only the newest 3 are kept
+	deleted, err := repo.PruneContext(ctx, now.Add(-10*time.Minute), 3)
+	if err != nil {
+		t.Fatalf("failed to prune events: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 events pruned, got %d", deleted)
+	}
+
+	events, _ := repo.SinceContext(ctx, 0, 10)
+	if len(events) != 3 || string(events[0].Data) != `{"n":2}` {
+		t.Errorf("expected the newest 3 events kept, got %+v", events)
+	}
+}
+
+func TestEventRepository_TenantScoped(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewEventRepository(db)
+	teamA := ContextWithTenant(context.Background(), "team-a")
+
+	if _, err := repo.AppendContext(teamA, "log_deleted", json.RawMessage(`{"id":1}`), time.Now()); err != nil {
+		t.Fatalf("failed to append event: %v", err)
+	}
+	if _, err := repo.AppendContext(context.Background(), "log_deleted", json.RawMessage(`{"id":2}`), time.Now()); err != nil {
+		t.Fatalf("failed to append event: %v", err)
+	}
+
+	events, err := repo.SinceContext(teamA, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to read events: %v", err)
+	}
+	if len(events) != 1 || string(events[0].Data) != `{"id":1}` {
+		t.Errorf("expected only team-a's event, got %+v", events)
+	}
+}
@@ -1,151 +1,781 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/mx-scribe/scribe/internal/domain/entities"
 	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
 )
 
-// LogRepository handles log persistence operations.
+// LogRepository handles log persistence operations. Every call is scoped to
+// the tenant of its context (see ContextWithTenant); calls without a context
+// use DefaultTenant.
 type LogRepository struct {
-	db *Database
+	db        *Database
+	timeField TimeField
 }
 
 // NewLogRepository creates a new log repository.
 func NewLogRepository(db *Database) *LogRepository {
-	return &LogRepository{db: db}
+	return &LogRepository{db: db, timeField: EventTime}
+}
+
+// TimeField selects which timestamp the age-based queries (CountLast24Hours,
+// FindErrorsSince, CountErrorsBySourceSince, CountOlderThan and
+// DeleteOlderThan) compare against.
+type TimeField string
+
+const (
+	// EventTime is when the logged event happened: the client-supplied
+	// timestamp, or the ingestion time when none was given.
+	EventTime TimeField = "event_time"
+
+	// IngestedAt is when the server received the log.
+	IngestedAt TimeField = "ingested_at"
+)
+
+// ParseTimeField parses a time field name. An empty name is EventTime.
+func ParseTimeField(name string) (TimeField, error) {
+	switch TimeField(name) {
+	case "", EventTime:
+		return EventTime, nil
+	case IngestedAt:
+		return IngestedAt, nil
+	}
+	return "", fmt.Errorf("unknown time field %q (want %s or %s)", name, EventTime, IngestedAt)
+}
+
+// column returns the logs column holding the timestamp.
+func (f TimeField) column() string {
+	if f == IngestedAt {
+		return "ingested_at"
+	}
+	return "created_at"
+}
+
+// WithTimeField returns a repository whose age-based queries use field.
+func (r *LogRepository) WithTimeField(field TimeField) *LogRepository {
+	return &LogRepository{db: r.db, timeField: field}
 }
 
 // LogFilters contains filter criteria for querying logs.
+// Search matches title, description or body; TitleSearch and BodySearch
+// target a single field and combine with Search. MinSeverity matches logs
+// whose effective severity ranks at or above it, and is ignored when an
+// explicit Severity is given. A nil Pinned matches both pinned and unpinned logs.
 type LogFilters struct {
-	Search   string
-	Severity string
-	Source   string
-	Color    string
-	FromDate string
-	ToDate   string
-	Limit    int
-	Offset   int
+	Search      string
+	TitleSearch string
+	BodySearch  string
+	Severity    string
+	MinSeverity string
+	Source      string
+	Color       string
+	Category    string
+	FromDate    string
+	ToDate      string
+	Pinned      *bool
+	Limit       int
+	Offset      int
+
+	// IncludeBody controls whether the body column is read. Nil includes it;
+	// list views that never render bodies can set it to false to skip reading
+	// and unmarshalling them, leaving Body as an empty map.
+	IncludeBody *bool
+
+	// BodyFields matches top-level body keys against values, compared as
+	// text so "120" matches both 120 and "120". Keys must satisfy
+	// ValidBodyField.
+	BodyFields map[string]string
+
+	// MaxID, when positive, matches only logs with an ID up to it.
+	MaxID int64
+
+	// After, when set, matches only logs older than it in the newest-first
+	// (created_at, id) order, so a listing can continue past its last log
+	// without an ever-growing Offset. It does not narrow the total count
+	// returned by FindAllContext.
+	After *LogCursor
+
+	// Or, when set, matches only logs matching at least one of its filters,
+	// in addition to the other filters. Each filter ANDs its own conditions;
+	// paging, body inclusion and nested Or are not allowed in them. At most
+	// MaxOrFilters filters can be given.
+	Or []LogFilters
+}
+
+// MaxOrFilters bounds LogFilters.Or so a query cannot grow without limit.
+const MaxOrFilters = 10
+
+// ValidBodyField reports whether key can be used in LogFilters.BodyFields:
+// a non-empty name of letters, digits, underscores and hyphens.
+func ValidBodyField(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// includeBody reports whether the body column should be selected.
+func (f LogFilters) includeBody() bool {
+	return f.IncludeBody == nil || *f.IncludeBody
 }
 
 // Create inserts a new log into the database.
 func (r *LogRepository) Create(log *entities.Log) error {
+	return r.CreateContext(context.Background(), log)
+}
+
+// insertLogQuery is the INSERT statement shared by single and batch creates.
+const insertLogQuery = `
+	INSERT INTO logs (
+		title, severity, source, color, description, body, body_encoding,
+		derived_severity, derived_source, derived_category, derived_color, created_at, ingested_at, expires_at, body_size, tenant
+	) VALUES (?, ?, NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?)`
+
+// CreateContext inserts a new log into the database for the tenant of ctx,
+// honoring ctx cancellation.
+func (r *LogRepository) CreateContext(ctx context.Context, log *entities.Log) error {
+	defer observeQuery(ctx, time.Now())
+
+	args, err := r.insertArgs(log, TenantFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Conn().ExecContext(ctx, insertLogQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to insert log: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	log.ID = id
+	return nil
+}
+
+// CreateBatchContext inserts several logs for the tenant of ctx in a single
+// transaction.
+// Either all logs are inserted and their IDs set, or none are.
+func (r *LogRepository) CreateBatchContext(ctx context.Context, logs []*entities.Log) error {
+	defer observeQuery(ctx, time.Now())
+
+	if len(logs) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, insertLogQuery)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	ids := make([]int64, len(logs))
+	for i, log := range logs {
+		args, err := r.insertArgs(log, TenantFromContext(ctx))
+		if err != nil {
+			return err
+		}
+
+		result, err := stmt.ExecContext(ctx, args...)
+		if err != nil {
+			return fmt.Errorf("failed to insert log: %w", err)
+		}
+
+		ids[i], err = result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get last insert id: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for i, log := range logs {
+		log.ID = ids[i]
+	}
+	return nil
+}
+
+// utcTime returns t in UTC, or nil when t is nil. Timestamps are stored and
+// compared as text, so every one bound to a query must share one zone.
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// insertArgs returns the insertLogQuery arguments for a log of tenant,
+// compressing its body when the database compresses bodies. It sets the
+// log's BodySize to the length of the serialized body, which is stored with
+// it.
+func (r *LogRepository) insertArgs(log *entities.Log, tenant string) ([]any, error) {
 	bodyJSON, err := json.Marshal(log.Body)
 	if err != nil {
-		return fmt.Errorf("failed to marshal body: %w", err)
+		return nil, fmt.Errorf("failed to marshal body: %w", err)
+	}
+	log.BodySize = int64(len(bodyJSON))
+
+	var body any = string(bodyJSON)
+	encoding := ""
+	if r.db.compressBodies && len(bodyJSON) >= bodyCompressMinBytes {
+		if body, err = compressBody(bodyJSON); err != nil {
+			return nil, fmt.Errorf("failed to compress body: %w", err)
+		}
+		encoding = bodyEncodingGzip
 	}
 
-	result, err := r.db.Conn().Exec(`
-		INSERT INTO logs (
-			title, severity, source, color, description, body,
-			derived_severity, derived_source, derived_category, created_at
-		) VALUES (?, ?, NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?, ?, ?)`,
+	return []any{
 		log.Header.Title,
 		log.Header.Severity.String(),
 		log.Header.Source,
 		log.Header.Color.String(),
 		log.Header.Description,
-		string(bodyJSON),
+		body,
+		encoding,
 		log.Metadata.DerivedSeverity,
 		log.Metadata.DerivedSource,
 		log.Metadata.DerivedCategory,
-		log.CreatedAt,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to insert log: %w", err)
-	}
+		log.Metadata.DerivedColor,
+		log.CreatedAt.UTC(),
+		log.IngestedAt.UTC(),
+		utcTime(log.ExpiresAt),
+		log.BodySize,
+		tenant,
+	}, nil
+}
 
-	id, err := result.LastInsertId()
+// Exists reports whether a log with the given ID exists.
+func (r *LogRepository) Exists(id int64) (bool, error) {
+	return r.ExistsContext(context.Background(), id)
+}
+
+// ExistsContext reports whether the tenant of ctx has a log with the given
+// ID, without reading the log, honoring ctx cancellation.
+func (r *LogRepository) ExistsContext(ctx context.Context, id int64) (bool, error) {
+	defer observeQuery(ctx, time.Now())
+
+	var exists int
+	err := r.db.Conn().QueryRowContext(ctx, "SELECT 1 FROM logs WHERE id = ? AND tenant = ? LIMIT 1", id, TenantFromContext(ctx)).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to get last insert id: %w", err)
+		return false, fmt.Errorf("failed to check log: %w", err)
 	}
-
-	log.ID = id
-	return nil
+	return true, nil
 }
 
 // FindByID retrieves a single log by ID.
 func (r *LogRepository) FindByID(id int64) (*entities.Log, error) {
+	return r.FindByIDContext(context.Background(), id)
+}
+
+// FindByIDContext retrieves a single log by ID, honoring ctx cancellation.
+func (r *LogRepository) FindByIDContext(ctx context.Context, id int64) (*entities.Log, error) {
+	defer observeQuery(ctx, time.Now())
+
 	query := `
-		SELECT id, title, severity, source, color, description, body, created_at,
-		       derived_severity, derived_source, derived_category
-		FROM logs WHERE id = ?`
+		SELECT id, title, severity, source, color, description, ` + bodyExpr + `, created_at,
+		       derived_severity, derived_source, derived_category, derived_color, pinned, ingested_at, expires_at, body_size,
+		       (SELECT COUNT(*) FROM log_annotations WHERE log_id = logs.id)
+		FROM logs WHERE id = ? AND tenant = ?`
 
-	row := r.db.Conn().QueryRow(query, id)
+	row := r.db.Conn().QueryRowContext(ctx, query, id, TenantFromContext(ctx))
 	return r.scanLogRow(row)
 }
 
+// FindByIDs retrieves the logs with the given IDs.
+func (r *LogRepository) FindByIDs(ids []int64) ([]*entities.Log, error) {
+	return r.FindByIDsContext(context.Background(), ids)
+}
+
+// FindByIDsContext retrieves the logs with the given IDs in a single query,
+// honoring ctx cancellation. Logs are returned in the order of ids; IDs
+// with no log are omitted and duplicates are returned once.
+func (r *LogRepository) FindByIDsContext(ctx context.Context, ids []int64) ([]*entities.Log, error) {
+	defer observeQuery(ctx, time.Now())
+
+	if len(ids) == 0 {
+		return []*entities.Log{}, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids), len(ids)+1)
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	args = append(args, TenantFromContext(ctx))
+
+	query := `
+		SELECT id, title, severity, source, color, description, ` + bodyExpr + `, created_at,
+		       derived_severity, derived_source, derived_category, derived_color, pinned, ingested_at, expires_at, body_size,
+		       (SELECT COUNT(*) FROM log_annotations WHERE log_id = logs.id)
+		FROM logs WHERE id IN (` + strings.Join(placeholders, ", ") + `) AND tenant = ?`
+
+	rows, err := r.db.Conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query logs: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[int64]*entities.Log, len(ids))
+	for rows.Next() {
+		log, err := r.scanLog(rows)
+		if err != nil {
+			continue // Skip malformed rows
+		}
+		byID[log.ID] = log
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate logs: %w", err)
+	}
+
+	logs := make([]*entities.Log, 0, len(byID))
+	for _, id := range ids {
+		if log, ok := byID[id]; ok {
+			logs = append(logs, log)
+			delete(byID, id)
+		}
+	}
+	return logs, nil
+}
+
 // FindAll retrieves logs with optional filters.
 func (r *LogRepository) FindAll(filters LogFilters) ([]*entities.Log, int, error) {
-	// Build dynamic SQL query
-	query := `
-		SELECT id, title, severity, source, color, description, body, created_at,
-		       derived_severity, derived_source, derived_category
-		FROM logs WHERE 1=1`
-	countQuery := "SELECT COUNT(*) FROM logs WHERE 1=1"
+	return r.FindAllContext(context.Background(), filters)
+}
+
+// FindAllContext retrieves logs with optional filters, honoring ctx cancellation.
+func (r *LogRepository) FindAllContext(ctx context.Context, filters LogFilters) ([]*entities.Log, int, error) {
+	defer observeQuery(ctx, time.Now())
+
+	where, args, err := filters.where(TenantFromContext(ctx), r.db.UnknownSourceLabel())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Get total count, ignoring the cursor
+	countWhere, countArgs := where, args
+	if filters.After != nil {
+		uncursored := filters
+		uncursored.After = nil
+		if countWhere, countArgs, err = uncursored.where(TenantFromContext(ctx), r.db.UnknownSourceLabel()); err != nil {
+			return nil, 0, err
+		}
+	}
+	totalCount, err := r.countMatching(ctx, countWhere, countArgs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	logs, err := r.findPage(ctx, filters, where, args)
+	if err != nil {
+		return nil, 0, err
+	}
+	return logs, totalCount, nil
+}
+
+// FindIDsContext returns the IDs of the logs matching filters, newest first
+// in the same order as FindAllContext, along with the total number of
+// matching logs. Only the id column is read. Limit and Offset page the IDs
+// as they page logs.
+func (r *LogRepository) FindIDsContext(ctx context.Context, filters LogFilters) ([]int64, int, error) {
+	defer observeQuery(ctx, time.Now())
+
+	where, args, err := filters.where(TenantFromContext(ctx), r.db.UnknownSourceLabel())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.countMatching(ctx, where, args)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT id FROM logs WHERE 1=1" + where + " ORDER BY created_at DESC, id DESC"
+	if filters.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filters.Limit)
+	}
+	if filters.Offset > 0 {
+		query += " OFFSET ?"
+		args = append(args, filters.Offset)
+	}
+
+	rows, err := r.db.Conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query log IDs: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan log ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate log IDs: %w", err)
+	}
+
+	return ids, total, nil
+}
+
+// eachBatchSize is the number of logs EachContext reads per query.
+const eachBatchSize = 1000
+
+// EachContext calls fn for every log matching filters, newest first in
+// (created_at, id) order, reading them in batches so memory stays constant
+// however many logs match. Logs created after the call starts are not
+// visited, so the result is consistent while new logs arrive. Offset is
+// ignored and a positive Limit caps the number of logs visited. It returns
+// the total number of logs matching filters, and stops at the first error
+// returned by fn.
+func (r *LogRepository) EachContext(ctx context.Context, filters LogFilters, fn func(*entities.Log) error) (int, error) {
+	// Pin the read to the logs that exist now
+	if filters.MaxID <= 0 {
+		maxID, err := r.MaxIDContext(ctx)
+		if err != nil {
+			return 0, err
+		}
+		filters.MaxID = maxID
+	}
+
+	where, args, err := filters.where(TenantFromContext(ctx), r.db.UnknownSourceLabel())
+	if err != nil {
+		return 0, err
+	}
+	total, err := r.countMatching(ctx, where, args)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := filters.Limit
+	if remaining <= 0 || remaining > total {
+		remaining = total
+	}
+	filters.Offset = 0
+
+	for remaining > 0 {
+		filters.Limit = min(eachBatchSize, remaining)
+		where, args, err := filters.where(TenantFromContext(ctx), r.db.UnknownSourceLabel())
+		if err != nil {
+			return total, err
+		}
+		logs, err := r.findPage(ctx, filters, where, args)
+		if err != nil {
+			return total, err
+		}
+		if len(logs) == 0 {
+			break
+		}
+		for _, log := range logs {
+			if err := fn(log); err != nil {
+				return total, err
+			}
+		}
+		remaining -= len(logs)
+
+		last := logs[len(logs)-1]
+		filters.After = &LogCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return total, nil
+}
+
+// MaxIDContext returns the highest log ID of the tenant of ctx, or 0 when it
+// has no logs. Setting it as LogFilters.MaxID pins later reads to the logs
+// that exist now.
+func (r *LogRepository) MaxIDContext(ctx context.Context) (int64, error) {
+	var maxID int64
+	if err := r.db.Conn().QueryRowContext(ctx, "SELECT COALESCE(MAX(id), 0) FROM logs WHERE tenant = ?", TenantFromContext(ctx)).Scan(&maxID); err != nil {
+		return 0, fmt.Errorf("failed to read max log ID: %w", err)
+	}
+	return maxID, nil
+}
+
+// FindNeighborsContext returns up to before logs immediately preceding log
+// and up to after logs immediately following it in (created_at, id) order,
+// both oldest first, honoring ctx cancellation. With sameSource, only logs
+// sharing the log's effective source are considered.
+func (r *LogRepository) FindNeighborsContext(ctx context.Context, log *entities.Log, before, after int, sameSource bool) ([]*entities.Log, []*entities.Log, error) {
+	defer observeQuery(ctx, time.Now())
+
+	var sourceWhere string
+	var sourceArgs []any
+	if sameSource {
+		sourceWhere = " AND " + effectiveSourceExpr + " = ?"
+		sourceArgs = []any{log.EffectiveSource()}
+	}
+
+	neighbors := func(position, order string, limit int) ([]*entities.Log, error) {
+		if limit <= 0 {
+			return []*entities.Log{}, nil
+		}
+		query := `
+			SELECT id, title, severity, source, color, description, ` + bodyExpr + `, created_at,
+			       derived_severity, derived_source, derived_category, derived_color, pinned, ingested_at, expires_at, body_size,
+			       (SELECT COUNT(*) FROM log_annotations WHERE log_id = logs.id)
+			FROM logs WHERE tenant = ? AND ` + position + sourceWhere + `
+			ORDER BY created_at ` + order + `, id ` + order + ` LIMIT ?`
+		args := append([]any{TenantFromContext(ctx), log.ID, log.ID, log.ID}, sourceArgs...)
+		rows, err := r.db.Conn().QueryContext(ctx, query, append(args, limit)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query neighboring logs: %w", err)
+		}
+		defer rows.Close()
+
+		logs := make([]*entities.Log, 0, limit)
+		for rows.Next() {
+			log, err := r.scanLog(rows)
+			if err != nil {
+				continue // Skip malformed rows
+			}
+			logs = append(logs, log)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to iterate neighboring logs: %w", err)
+		}
+		return logs, nil
+	}
+
+	// Positions compare against the stored created_at, which a scanned
+	// time.Time does not always render identically
+	const targetCreatedAt = "(SELECT created_at FROM logs WHERE id = ?)"
+	older, err := neighbors("(created_at < "+targetCreatedAt+" OR (created_at = "+targetCreatedAt+" AND id < ?))", "DESC", before)
+	if err != nil {
+		return nil, nil, err
+	}
+	slices.Reverse(older)
+
+	newer, err := neighbors("(created_at > "+targetCreatedAt+" OR (created_at = "+targetCreatedAt+" AND id > ?))", "ASC", after)
+	if err != nil {
+		return nil, nil, err
+	}
+	return older, newer, nil
+}
+
+// LogCursor is a position in the newest-first (created_at, id) order.
+type LogCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// likeEscape is the ESCAPE clause for LIKE patterns built by
+// containsPattern.
+const likeEscape = ` ESCAPE '\'`
+
+// likeEscaper escapes the LIKE wildcards and the escape character itself.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern returns a LIKE pattern, used with likeEscape, matching
+// values that contain term literally: % and _ in term are not wildcards.
+func containsPattern(term string) string {
+	return "%" + likeEscaper.Replace(term) + "%"
+}
+
+// where builds the WHERE conditions for filters on the logs of tenant, each
+// starting with " AND ". A Source equal to unknownSource also matches logs
+// without any source.
+func (f LogFilters) where(tenant, unknownSource string) (string, []any, error) {
+	conditions, args, err := f.conditions(unknownSource)
+	if err != nil {
+		return "", nil, err
+	}
+	return " AND tenant = ?" + conditions, append([]any{tenant}, args...), nil
+}
+
+// orCondition builds the condition matching any of filters, as an OR of
+// their parenthesized conditions.
+func orCondition(filters []LogFilters, unknownSource string) (string, []any, error) {
+	if len(filters) > MaxOrFilters {
+		return "", nil, fmt.Errorf("too many OR filters: %d, at most %d allowed", len(filters), MaxOrFilters)
+	}
+	groups := make([]string, 0, len(filters))
+	var args []any
+	for _, group := range filters {
+		if len(group.Or) > 0 || group.Limit != 0 || group.Offset != 0 || group.MaxID != 0 || group.After != nil || group.IncludeBody != nil {
+			return "", nil, errors.New("OR filters can only hold conditions")
+		}
+		conditions, groupArgs, err := group.conditions(unknownSource)
+		if err != nil {
+			return "", nil, err
+		}
+		groups = append(groups, "(1=1"+conditions+")")
+		args = append(args, groupArgs...)
+	}
+	return "(" + strings.Join(groups, " OR ") + ")", args, nil
+}
+
+// conditions builds the WHERE conditions for filters, each starting with
+// " AND ", leaving out the tenant.
+func (f LogFilters) conditions(unknownSource string) (string, []any, error) {
+	var where strings.Builder
 	var args []any
-	var countArgs []any
 
 	// Add search filter
-	if filters.Search != "" {
-		searchClause := " AND (title LIKE ? OR description LIKE ? OR body LIKE ?)"
-		searchTerm := "%" + filters.Search + "%"
-		query += searchClause
-		countQuery += searchClause
+	if f.Search != "" {
+		searchTerm := containsPattern(f.Search)
+		where.WriteString(" AND (title LIKE ?" + likeEscape + " OR description LIKE ?" + likeEscape + " OR " + bodyExpr + " LIKE ?" + likeEscape + ")")
 		args = append(args, searchTerm, searchTerm, searchTerm)
-		countArgs = append(countArgs, searchTerm, searchTerm, searchTerm)
+	}
+
+	// Add field-targeted search filters
+	if f.TitleSearch != "" {
+		where.WriteString(" AND title LIKE ?" + likeEscape)
+		args = append(args, containsPattern(f.TitleSearch))
+	}
+	if f.BodySearch != "" {
+		where.WriteString(" AND " + bodyExpr + " LIKE ?" + likeEscape)
+		args = append(args, containsPattern(f.BodySearch))
+	}
+
+	// Add body field filters, in key order so queries are stable
+	bodyKeys := make([]string, 0, len(f.BodyFields))
+	for key := range f.BodyFields {
+		bodyKeys = append(bodyKeys, key)
+	}
+	sort.Strings(bodyKeys)
+	for _, key := range bodyKeys {
+		if !ValidBodyField(key) {
+			return "", nil, fmt.Errorf("invalid body field %q", key)
+		}
+		where.WriteString(" AND CAST(json_extract(" + bodyExpr + ", ?) AS TEXT) = ?")
+		args = append(args, `$."`+key+`"`, f.BodyFields[key])
 	}
 
 	// Add severity filter
-	if filters.Severity != "" {
-		query += " AND severity = ?"
-		countQuery += " AND severity = ?"
-		args = append(args, filters.Severity)
-		countArgs = append(countArgs, filters.Severity)
+	if f.Severity != "" {
+		where.WriteString(" AND severity = ?")
+		args = append(args, f.Severity)
+	}
+
+	// Add minimum severity filter (an explicit severity takes precedence)
+	if f.Severity == "" && f.MinSeverity != "" {
+		condition, severityArgs := severitiesAtLeastCondition(valueobjects.Severity(f.MinSeverity))
+		where.WriteString(" AND " + condition)
+		args = append(args, severityArgs...)
 	}
 
 	// Add source filter
-	if filters.Source != "" {
-		query += " AND source = ?"
-		countQuery += " AND source = ?"
-		args = append(args, filters.Source)
-		countArgs = append(countArgs, filters.Source)
+	if f.Source == unknownSource {
+		where.WriteString(" AND (source = ? OR " + effectiveSourceExpr + " = '')")
+		args = append(args, f.Source)
+	} else if f.Source != "" {
+		where.WriteString(" AND source = ?")
+		args = append(args, f.Source)
 	}
 
-	// Add color filter
-	if filters.Color != "" {
-		query += " AND color = ?"
-		countQuery += " AND color = ?"
-		args = append(args, filters.Color)
-		countArgs = append(countArgs, filters.Color)
+	// Add color filter, matching a palette hex value as its color name
+	if f.Color != "" {
+		color := f.Color
+		if c := valueobjects.ParseColor(color); c != "" {
+			color = c.String()
+		}
+		where.WriteString(" AND " + colorExpr + " = ?")
+		args = append(args, color)
+	}
+
+	// Add category filter
+	if f.Category != "" {
+		where.WriteString(" AND derived_category = ?")
+		args = append(args, f.Category)
+	}
+
+	// Add pinned filter
+	if f.Pinned != nil {
+		where.WriteString(" AND pinned = ?")
+		args = append(args, *f.Pinned)
 	}
 
 	// Add date filters
-	if filters.FromDate != "" {
-		query += " AND created_at >= ?"
-		countQuery += " AND created_at >= ?"
-		args = append(args, filters.FromDate)
-		countArgs = append(countArgs, filters.FromDate)
+	if f.FromDate != "" {
+		where.WriteString(" AND created_at >= ?")
+		args = append(args, f.FromDate)
+	}
+	if f.ToDate != "" {
+		where.WriteString(" AND created_at <= ?")
+		args = append(args, f.ToDate)
+	}
+
+	// Add snapshot and keyset bounds
+	if f.MaxID > 0 {
+		where.WriteString(" AND id <= ?")
+		args = append(args, f.MaxID)
+	}
+	if f.After != nil {
+		where.WriteString(" AND (created_at < ? OR (created_at = ? AND id < ?))")
+		createdAt := f.After.CreatedAt.UTC()
+		args = append(args, createdAt, createdAt, f.After.ID)
 	}
-	if filters.ToDate != "" {
-		query += " AND created_at <= ?"
-		countQuery += " AND created_at <= ?"
-		args = append(args, filters.ToDate)
-		countArgs = append(countArgs, filters.ToDate)
+
+	// Add the OR'd filter groups
+	if len(f.Or) > 0 {
+		condition, orArgs, err := orCondition(f.Or, unknownSource)
+		if err != nil {
+			return "", nil, err
+		}
+		where.WriteString(" AND " + condition)
+		args = append(args, orArgs...)
+	}
+
+	return where.String(), args, nil
+}
+
+// countMatching counts the logs matching the where conditions.
+func (r *LogRepository) countMatching(ctx context.Context, where string, args []any) (int, error) {
+	var count int
+	if err := r.db.Conn().QueryRowContext(ctx, "SELECT COUNT(*) FROM logs WHERE 1=1"+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count logs: %w", err)
 	}
+	return count, nil
+}
 
-	// Get total count
-	var totalCount int
-	if err := r.db.Conn().QueryRow(countQuery, countArgs...).Scan(&totalCount); err != nil {
-		return nil, 0, fmt.Errorf("failed to count logs: %w", err)
+// findPage reads the page of logs selected by filters' Limit and Offset
+// among those matching the where conditions.
+func (r *LogRepository) findPage(ctx context.Context, filters LogFilters, where string, args []any) ([]*entities.Log, error) {
+	// An empty body scans as an empty map
+	bodyColumn := bodyExpr
+	if !filters.includeBody() {
+		bodyColumn = "''"
 	}
+	query := `
+		SELECT id, title, severity, source, color, description, ` + bodyColumn + `, created_at,
+		       derived_severity, derived_source, derived_category, derived_color, pinned, ingested_at, expires_at, body_size,
+		       (SELECT COUNT(*) FROM log_annotations WHERE log_id = logs.id)
+		FROM logs WHERE 1=1` + where
 
-	// Add ordering and pagination
-	query += " ORDER BY created_at DESC"
+	// Add ordering (id breaks created_at ties so pages are stable) and pagination
+	query += " ORDER BY created_at DESC, id DESC"
 	if filters.Limit > 0 {
 		query += " LIMIT ?"
 		args = append(args, filters.Limit)
@@ -156,9 +786,9 @@ func (r *LogRepository) FindAll(filters LogFilters) ([]*entities.Log, int, error
 	}
 
 	// Execute query
-	rows, err := r.db.Conn().Query(query, args...)
+	rows, err := r.db.Conn().QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to query logs: %w", err)
+		return nil, fmt.Errorf("failed to query logs: %w", err)
 	}
 	defer rows.Close()
 
@@ -171,26 +801,55 @@ func (r *LogRepository) FindAll(filters LogFilters) ([]*entities.Log, int, error
 		}
 		logs = append(logs, log)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate logs: %w", err)
+	}
 
-	return logs, totalCount, nil
+	return logs, nil
 }
 
 // Count returns the total number of logs.
 func (r *LogRepository) Count() (int, error) {
+	return r.CountContext(context.Background())
+}
+
+// CountContext returns the total number of logs, honoring ctx cancellation.
+func (r *LogRepository) CountContext(ctx context.Context) (int, error) {
+	defer observeQuery(ctx, time.Now())
+
 	var count int
-	err := r.db.Conn().QueryRow("SELECT COUNT(*) FROM logs").Scan(&count)
+	err := r.db.Conn().QueryRowContext(ctx, "SELECT COUNT(*) FROM logs WHERE tenant = ?", TenantFromContext(ctx)).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count logs: %w", err)
 	}
 	return count, nil
 }
 
+// CountMatchingContext returns the number of logs matching filters,
+// ignoring Limit and Offset.
+func (r *LogRepository) CountMatchingContext(ctx context.Context, filters LogFilters) (int, error) {
+	defer observeQuery(ctx, time.Now())
+
+	where, args, err := filters.where(TenantFromContext(ctx), r.db.UnknownSourceLabel())
+	if err != nil {
+		return 0, err
+	}
+	return r.countMatching(ctx, where, args)
+}
+
 // CountLast24Hours returns the number of logs from the last 24 hours.
 func (r *LogRepository) CountLast24Hours() (int, error) {
-	cutoff := time.Now().Add(-24 * time.Hour)
+	return r.CountLast24HoursContext(context.Background())
+}
+
+// CountLast24HoursContext returns the number of logs from the last 24 hours, honoring ctx cancellation.
+func (r *LogRepository) CountLast24HoursContext(ctx context.Context) (int, error) {
+	defer observeQuery(ctx, time.Now())
+
+	cutoff := time.Now().UTC().Add(-24 * time.Hour)
 	var count int
-	err := r.db.Conn().QueryRow(
-		"SELECT COUNT(*) FROM logs WHERE created_at >= ?", cutoff,
+	err := r.db.Conn().QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM logs WHERE tenant = ? AND "+r.timeField.column()+" >= ?", TenantFromContext(ctx), cutoff,
 	).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count recent logs: %w", err)
@@ -198,10 +857,71 @@ func (r *LogRepository) CountLast24Hours() (int, error) {
 	return count, nil
 }
 
+// CountOlderThanContext returns, for each cutoff, the number of logs created
+// before it, pinned ones included, so the counts add up to the total. Use
+// CountDeletableContext for what DeleteOlderThan would remove. All cutoffs
+// are counted in a single pass over the table.
+func (r *LogRepository) CountOlderThanContext(ctx context.Context, cutoffs []time.Time) ([]int, error) {
+	defer observeQuery(ctx, time.Now())
+
+	if len(cutoffs) == 0 {
+		return []int{}, nil
+	}
+
+	columns := make([]string, len(cutoffs))
+	args := make([]any, len(cutoffs))
+	for i, cutoff := range cutoffs {
+		columns[i] = "COUNT(CASE WHEN " + r.timeField.column() + " < ? THEN 1 END)"
+		args[i] = cutoff.UTC()
+	}
+
+	counts := make([]int, len(cutoffs))
+	dest := make([]any, len(cutoffs))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+
+	query := "SELECT " + strings.Join(columns, ", ") + " FROM logs WHERE tenant = ?"
+	args = append(args, TenantFromContext(ctx))
+	if err := r.db.Conn().QueryRowContext(ctx, query, args...).Scan(dest...); err != nil {
+		return nil, fmt.Errorf("failed to count logs by age: %w", err)
+	}
+	return counts, nil
+}
+
+// CountDeletableContext counts the logs DeleteOlderThanContext would delete
+// for cutoffDate.
+func (r *LogRepository) CountDeletableContext(ctx context.Context, cutoffDate time.Time) (int, error) {
+	defer observeQuery(ctx, time.Now())
+
+	where, args := r.retentionWhere(ctx, cutoffDate)
+	var count int
+	if err := r.db.Conn().QueryRowContext(ctx, "SELECT COUNT(*) FROM logs WHERE "+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count deletable logs: %w", err)
+	}
+	return count, nil
+}
+
+// retentionWhere returns the condition matching the unpinned logs of the
+// tenant of ctx that retention deletes for cutoffDate: those without an
+// expiry older than it, and those whose expiry has passed.
+func (r *LogRepository) retentionWhere(ctx context.Context, cutoffDate time.Time) (string, []any) {
+	where := "tenant = ? AND pinned = 0 AND ((expires_at IS NULL AND " + r.timeField.column() + " < ?) OR expires_at <= ?)"
+	return where, []any{TenantFromContext(ctx), cutoffDate.UTC(), time.Now().UTC()}
+}
+
 // CountBySeverity returns log counts grouped by effective severity (derived_severity if set, otherwise severity).
 func (r *LogRepository) CountBySeverity() (map[string]int, error) {
-	rows, err := r.db.Conn().Query(
-		"SELECT COALESCE(NULLIF(derived_severity, ''), severity) as effective_severity, COUNT(*) FROM logs GROUP BY effective_severity",
+	return r.CountBySeverityContext(context.Background())
+}
+
+// CountBySeverityContext is CountBySeverity honoring ctx cancellation.
+func (r *LogRepository) CountBySeverityContext(ctx context.Context) (map[string]int, error) {
+	defer observeQuery(ctx, time.Now())
+
+	rows, err := r.db.Conn().QueryContext(ctx,
+		"SELECT COALESCE(NULLIF(derived_severity, ''), severity) as effective_severity, COUNT(*) FROM logs WHERE tenant = ? GROUP BY effective_severity",
+		TenantFromContext(ctx),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count by severity: %w", err)
@@ -220,10 +940,27 @@ func (r *LogRepository) CountBySeverity() (map[string]int, error) {
 	return counts, nil
 }
 
-// CountBySource returns log counts grouped by source.
+// colorExpr is the SQL expression for a log's stored color: the explicit
+// color if set, otherwise the one derived from its severity on ingestion.
+const colorExpr = "COALESCE(NULLIF(color, ''), derived_color)"
+
+// effectiveSourceExpr is the SQL expression for a log's effective source: the
+// explicit source if set, otherwise the derived source, otherwise empty.
+const effectiveSourceExpr = "COALESCE(NULLIF(source, ''), NULLIF(derived_source, ''), '')"
+
+// CountBySource returns log counts grouped by explicit source; logs without
+// one are counted under the database's unknown source label.
 func (r *LogRepository) CountBySource() (map[string]int, error) {
-	rows, err := r.db.Conn().Query(
-		"SELECT COALESCE(source, 'unknown'), COUNT(*) FROM logs GROUP BY source",
+	return r.CountBySourceContext(context.Background())
+}
+
+// CountBySourceContext is CountBySource honoring ctx cancellation.
+func (r *LogRepository) CountBySourceContext(ctx context.Context) (map[string]int, error) {
+	defer observeQuery(ctx, time.Now())
+
+	rows, err := r.db.Conn().QueryContext(ctx,
+		"SELECT COALESCE(NULLIF(source, ''), ?) AS source_label, COUNT(*) FROM logs WHERE tenant = ? GROUP BY source_label",
+		r.db.UnknownSourceLabel(), TenantFromContext(ctx),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count by source: %w", err)
@@ -242,9 +979,376 @@ func (r *LogRepository) CountBySource() (map[string]int, error) {
 	return counts, nil
 }
 
+// CountByEffectiveSource returns log counts grouped by effective source: the
+// explicit source if set, otherwise the derived source, otherwise the
+// database's unknown source label.
+// Unlike CountBySource it includes logs whose source was only derived.
+func (r *LogRepository) CountByEffectiveSource() (map[string]int, error) {
+	return r.CountByEffectiveSourceContext(context.Background())
+}
+
+// CountByEffectiveSourceContext is CountByEffectiveSource honoring ctx
+// cancellation.
+func (r *LogRepository) CountByEffectiveSourceContext(ctx context.Context) (map[string]int, error) {
+	defer observeQuery(ctx, time.Now())
+
+	rows, err := r.db.Conn().QueryContext(ctx,
+		"SELECT COALESCE(NULLIF(source, ''), NULLIF(derived_source, ''), ?) as effective_source, COUNT(*) FROM logs WHERE tenant = ? GROUP BY effective_source",
+		r.db.UnknownSourceLabel(), TenantFromContext(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count by effective source: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var source string
+		var count int
+		if err := rows.Scan(&source, &count); err != nil {
+			continue
+		}
+		counts[source] = count
+	}
+	return counts, nil
+}
+
+// ErrInvalidFacet is returned when grouping by a field that is not facetable.
+var ErrInvalidFacet = errors.New("field is not facetable")
+
+// facetColumns maps facetable field names to the SQL expression grouped on.
+var facetColumns = map[string]string{
+	"severity": "COALESCE(NULLIF(derived_severity, ''), severity)",
+	"source":   "source",
+	"color":    colorExpr,
+	"category": "derived_category",
+}
+
+// FacetFields returns the names of the fields that can be faceted, sorted.
+func FacetFields() []string {
+	fields := make([]string, 0, len(facetColumns))
+	for field := range facetColumns {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// FacetValue is a distinct field value and the number of logs having it.
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// CountByFieldContext returns the distinct non-empty values of a facetable
+// field with their log counts, most frequent first.
+func (r *LogRepository) CountByFieldContext(ctx context.Context, field string) ([]FacetValue, error) {
+	defer observeQuery(ctx, time.Now())
+
+	column, ok := facetColumns[field]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFacet, field)
+	}
+
+	rows, err := r.db.Conn().QueryContext(ctx,
+		"SELECT "+column+" AS value, COUNT(*) AS count FROM logs"+
+			" WHERE tenant = ? AND "+column+" IS NOT NULL AND "+column+" != ''"+
+			" GROUP BY value ORDER BY count DESC, value ASC",
+		TenantFromContext(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count by %s: %w", field, err)
+	}
+	defer rows.Close()
+
+	values := make([]FacetValue, 0)
+	for rows.Next() {
+		var value FacetValue
+		if err := rows.Scan(&value.Value, &value.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan %s facet: %w", field, err)
+		}
+		values = append(values, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate %s facet: %w", field, err)
+	}
+	return values, nil
+}
+
+// severitiesAtLeastCondition returns a WHERE condition, with its arguments,
+// matching logs whose effective severity ranks at or above min. It matches
+// nothing when min has no rank.
+func severitiesAtLeastCondition(min valueobjects.Severity) (string, []any) {
+	severities := valueobjects.SeveritiesAtLeast(min)
+	if len(severities) == 0 {
+		return "0", nil
+	}
+	placeholders := make([]string, len(severities))
+	args := make([]any, len(severities))
+	for i, severity := range severities {
+		placeholders[i] = "?"
+		args[i] = severity.String()
+	}
+	return "COALESCE(NULLIF(derived_severity, ''), severity) IN (" + strings.Join(placeholders, ", ") + ")", args
+}
+
+// ErrorSample is a lightweight view of an error log used for aggregation.
+type ErrorSample struct {
+	ID        int64
+	Title     string
+	CreatedAt time.Time
+}
+
+// FindErrorsSinceContext returns error and critical logs (by effective severity)
+// whose time field is at or after since, newest first, capped at maxRows.
+func (r *LogRepository) FindErrorsSinceContext(ctx context.Context, since time.Time, maxRows int) ([]ErrorSample, error) {
+	defer observeQuery(ctx, time.Now())
+
+	column := r.timeField.column()
+	condition, args := severitiesAtLeastCondition(valueobjects.SeverityError)
+	rows, err := r.db.Conn().QueryContext(ctx, `
+		SELECT id, title, created_at FROM logs
+		WHERE tenant = ?
+		  AND `+condition+`
+		  AND `+column+` >= ?
+		ORDER BY `+column+` DESC, id DESC
+		LIMIT ?`,
+		append(append([]any{TenantFromContext(ctx)}, args...), since.UTC(), maxRows)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query error logs: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []ErrorSample
+	for rows.Next() {
+		var sample ErrorSample
+		if err := rows.Scan(&sample.ID, &sample.Title, &sample.CreatedAt); err != nil {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate error logs: %w", err)
+	}
+	return samples, nil
+}
+
+// SourceErrorCount is the number of logs, and of error and critical logs
+// among them, from one effective source.
+type SourceErrorCount struct {
+	Source     string
+	ErrorCount int
+	TotalCount int
+}
+
+// CountErrorsBySourceSinceContext counts logs whose time field is at or after
+// since per effective source (explicit, else derived, else the unknown source label),
+// along with how many of them are error or critical by effective severity.
+func (r *LogRepository) CountErrorsBySourceSinceContext(ctx context.Context, since time.Time) ([]SourceErrorCount, error) {
+	defer observeQuery(ctx, time.Now())
+
+	condition, args := severitiesAtLeastCondition(valueobjects.SeverityError)
+	rows, err := r.db.Conn().QueryContext(ctx, `
+		SELECT COALESCE(NULLIF(source, ''), NULLIF(derived_source, ''), ?) AS effective_source,
+		       SUM(CASE WHEN `+condition+` THEN 1 ELSE 0 END),
+		       COUNT(*)
+		FROM logs
+		WHERE tenant = ? AND `+r.timeField.column()+` >= ?
+		GROUP BY effective_source`,
+		append(append([]any{r.db.UnknownSourceLabel()}, args...), TenantFromContext(ctx), since.UTC())...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count errors by source: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []SourceErrorCount
+	for rows.Next() {
+		var count SourceErrorCount
+		if err := rows.Scan(&count.Source, &count.ErrorCount, &count.TotalCount); err != nil {
+			return nil, fmt.Errorf("failed to scan source error count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate source error counts: %w", err)
+	}
+	return counts, nil
+}
+
+// SetPinned pins or unpins a log by ID.
+func (r *LogRepository) SetPinned(id int64, pinned bool) error {
+	return r.SetPinnedContext(context.Background(), id, pinned)
+}
+
+// SetPinnedContext pins or unpins a log by ID, honoring ctx cancellation.
+func (r *LogRepository) SetPinnedContext(ctx context.Context, id int64, pinned bool) error {
+	defer observeQuery(ctx, time.Now())
+
+	result, err := r.db.Conn().ExecContext(ctx, "UPDATE logs SET pinned = ? WHERE id = ? AND tenant = ?", pinned, id, TenantFromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to update pinned state: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return entities.ErrLogNotFound
+	}
+
+	return nil
+}
+
+// RemapSeverity changes the severity of every log from source whose
+// effective severity is from to severity to, rewriting the derived severity
+// too when one is set so the change shows up in filters and stats. It
+// returns the number of logs updated.
+func (r *LogRepository) RemapSeverity(source, from, to string) (int64, error) {
+	return r.RemapSeverityContext(context.Background(), source, from, to)
+}
+
+// RemapSeverityContext changes the severity of every log from source whose
+// effective severity is from to severity to, honoring ctx cancellation.
+func (r *LogRepository) RemapSeverityContext(ctx context.Context, source, from, to string) (int64, error) {
+	defer observeQuery(ctx, time.Now())
+
+	result, err := r.db.Conn().ExecContext(ctx, `
+		UPDATE logs SET severity = ?,
+		       derived_severity = CASE WHEN COALESCE(derived_severity, '') = '' THEN derived_severity ELSE ? END
+		WHERE tenant = ? AND source = ? AND COALESCE(NULLIF(derived_severity, ''), severity) = ?`,
+		to, to, TenantFromContext(ctx), source, from,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remap severity: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// UpdateDerivedMetadataContext writes the derived metadata of logs to their
+// rows in a single transaction, honoring ctx cancellation. Either every row
+// is updated or none is.
+func (r *LogRepository) UpdateDerivedMetadataContext(ctx context.Context, logs []*entities.Log) error {
+	defer observeQuery(ctx, time.Now())
+
+	if len(logs) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		UPDATE logs SET derived_severity = ?, derived_source = ?, derived_category = ?, derived_color = NULLIF(?, '')
+		WHERE id = ? AND tenant = ?`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare update: %w", err)
+	}
+
+	tenant := TenantFromContext(ctx)
+	defer stmt.Close()
+
+	for _, log := range logs {
+		if _, err := stmt.ExecContext(ctx,
+			log.Metadata.DerivedSeverity,
+			log.Metadata.DerivedSource,
+			log.Metadata.DerivedCategory,
+			log.Metadata.DerivedColor,
+			log.ID,
+			tenant,
+		); err != nil {
+			return fmt.Errorf("failed to update log %d: %w", log.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// corruptBodyCondition matches rows whose stored body is not a JSON object,
+// which scanLog would otherwise read back as an empty body.
+const corruptBodyCondition = bodyExpr + " IS NOT NULL AND " + bodyExpr + " != '' AND " +
+	"CASE WHEN json_valid(" + bodyExpr + ") THEN json_type(" + bodyExpr + ") != 'object' ELSE 1 END"
+
+// BodyRawKey is the body key a corrupt stored body is quarantined under.
+const BodyRawKey = "body_raw"
+
+// FindCorruptBodyIDsContext returns, in ID order, the IDs of logs whose
+// stored body does not parse as a JSON object.
+func (r *LogRepository) FindCorruptBodyIDsContext(ctx context.Context) ([]int64, error) {
+	defer observeQuery(ctx, time.Now())
+
+	rows, err := r.db.Conn().QueryContext(ctx, "SELECT id FROM logs WHERE tenant = ? AND "+corruptBodyCondition+" ORDER BY id", TenantFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find corrupt bodies: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan log ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// QuarantineCorruptBodiesContext replaces every corrupt stored body with an
+// object holding the raw text under BodyRawKey, so it can be read and
+// recovered. It returns the IDs of the repaired logs in ID order.
+func (r *LogRepository) QuarantineCorruptBodiesContext(ctx context.Context) ([]int64, error) {
+	defer observeQuery(ctx, time.Now())
+
+	rows, err := r.db.Conn().QueryContext(ctx,
+		"UPDATE logs SET body = json_object('"+BodyRawKey+"', "+bodyExpr+"), body_encoding = '' WHERE tenant = ? AND "+corruptBodyCondition+" RETURNING id",
+		TenantFromContext(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to quarantine corrupt bodies: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan log ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to quarantine corrupt bodies: %w", err)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
 // Delete removes a log by ID.
 func (r *LogRepository) Delete(id int64) error {
-	result, err := r.db.Conn().Exec("DELETE FROM logs WHERE id = ?", id)
+	return r.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext removes a log by ID, honoring ctx cancellation.
+func (r *LogRepository) DeleteContext(ctx context.Context, id int64) error {
+	defer observeQuery(ctx, time.Now())
+
+	result, err := r.db.Conn().ExecContext(ctx, "DELETE FROM logs WHERE id = ? AND tenant = ?", id, TenantFromContext(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to delete log: %w", err)
 	}
@@ -261,11 +1365,58 @@ func (r *LogRepository) Delete(id int64) error {
 	return nil
 }
 
+// ErrNoFilter is returned by DeleteByFilter for filters without conditions,
+// which would delete every log.
+var ErrNoFilter = errors.New("at least one filter is required")
+
+// DeleteByFilter deletes the logs matching filters.
+func (r *LogRepository) DeleteByFilter(filters LogFilters) (int64, error) {
+	return r.DeleteByFilterContext(context.Background(), filters)
+}
+
+// DeleteByFilterContext deletes the logs FindAllContext would list for
+// filters, pinned ones included, and returns how many were deleted. Limit,
+// Offset and After only page listings and are ignored. Filters without
+// conditions are rejected with ErrNoFilter.
+func (r *LogRepository) DeleteByFilterContext(ctx context.Context, filters LogFilters) (int64, error) {
+	defer observeQuery(ctx, time.Now())
+
+	filters.Limit, filters.Offset, filters.After = 0, 0, nil
+	conditions, args, err := filters.conditions(r.db.UnknownSourceLabel())
+	if err != nil {
+		return 0, err
+	}
+	if conditions == "" {
+		return 0, ErrNoFilter
+	}
+
+	result, err := r.db.Conn().ExecContext(ctx, "DELETE FROM logs WHERE tenant = ?"+conditions, append([]any{TenantFromContext(ctx)}, args...)...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete logs: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
 // DeleteOlderThan deletes logs older than the specified date.
+// Pinned logs are never deleted by retention.
 func (r *LogRepository) DeleteOlderThan(cutoffDate time.Time) (int64, error) {
-	result, err := r.db.Conn().Exec(
-		"DELETE FROM logs WHERE created_at < ?", cutoffDate,
-	)
+	return r.DeleteOlderThanContext(context.Background(), cutoffDate)
+}
+
+// DeleteOlderThanContext deletes logs older than the specified date, honoring ctx cancellation.
+// Logs with an expiry are deleted once it has passed instead, however old
+// they are. Pinned logs are kept either way.
+func (r *LogRepository) DeleteOlderThanContext(ctx context.Context, cutoffDate time.Time) (int64, error) {
+	defer observeQuery(ctx, time.Now())
+
+	where, args := r.retentionWhere(ctx, cutoffDate)
+	result, err := r.db.Conn().ExecContext(ctx, "DELETE FROM logs WHERE "+where, args...)
 	if err != nil {
 		return 0, fmt.Errorf("failed to delete old logs: %w", err)
 	}
@@ -278,13 +1429,31 @@ func (r *LogRepository) DeleteOlderThan(cutoffDate time.Time) (int64, error) {
 	return rowsAffected, nil
 }
 
+// decodeBody parses a stored body, keeping numbers as json.Number so large
+// integers such as IDs round-trip without float64 precision loss. Bodies
+// that are empty or fail to parse are returned as an empty map.
+func decodeBody(bodyJSON string) map[string]any {
+	body := make(map[string]any)
+	if bodyJSON == "" {
+		return body
+	}
+	dec := json.NewDecoder(strings.NewReader(bodyJSON))
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil || dec.More() {
+		return make(map[string]any)
+	}
+	return body
+}
+
 // scanLog scans a row into a Log entity (for Rows).
 func (r *LogRepository) scanLog(rows *sql.Rows) (*entities.Log, error) {
 	var log entities.Log
 	var bodyJSON string
 	var severityStr string
 	var source, colorStr, description sql.NullString
-	var derivedSeverity, derivedSource, derivedCategory sql.NullString
+	var derivedSeverity, derivedSource, derivedCategory, derivedColor sql.NullString
+	var ingestedAt, expiresAt sql.NullTime
+	var bodySize sql.NullInt64
 
 	err := rows.Scan(
 		&log.ID,
@@ -298,6 +1467,12 @@ func (r *LogRepository) scanLog(rows *sql.Rows) (*entities.Log, error) {
 		&derivedSeverity,
 		&derivedSource,
 		&derivedCategory,
+		&derivedColor,
+		&log.Pinned,
+		&ingestedAt,
+		&expiresAt,
+		&bodySize,
+		&log.AnnotationCount,
 	)
 	if err != nil {
 		return nil, err
@@ -310,14 +1485,23 @@ func (r *LogRepository) scanLog(rows *sql.Rows) (*entities.Log, error) {
 	log.Metadata.DerivedSeverity = derivedSeverity.String
 	log.Metadata.DerivedSource = derivedSource.String
 	log.Metadata.DerivedCategory = derivedCategory.String
-
-	if bodyJSON != "" {
-		if err := json.Unmarshal([]byte(bodyJSON), &log.Body); err != nil {
-			log.Body = make(map[string]any)
-		}
-	} else {
-		log.Body = make(map[string]any)
+	log.Metadata.DerivedColor = derivedColor.String
+	log.IngestedAt = log.CreatedAt
+	if ingestedAt.Valid {
+		log.IngestedAt = ingestedAt.Time
+	}
+	if expiresAt.Valid {
+		log.ExpiresAt = &expiresAt.Time
 	}
+	log.BodySize = bodySize.Int64
+	if !bodySize.Valid {
+		// Stored before body sizes were recorded: fall back to the length of
+		// the JSON text read, which bodyExpr decodes whatever the encoding
+		// (0 when the body was not selected)
+		log.BodySize = int64(len(bodyJSON))
+	}
+
+	log.Body = decodeBody(bodyJSON)
 
 	return &log, nil
 }
@@ -328,7 +1512,9 @@ func (r *LogRepository) scanLogRow(row *sql.Row) (*entities.Log, error) {
 	var bodyJSON string
 	var severityStr string
 	var source, colorStr, description sql.NullString
-	var derivedSeverity, derivedSource, derivedCategory sql.NullString
+	var derivedSeverity, derivedSource, derivedCategory, derivedColor sql.NullString
+	var ingestedAt, expiresAt sql.NullTime
+	var bodySize sql.NullInt64
 
 	err := row.Scan(
 		&log.ID,
@@ -342,6 +1528,12 @@ func (r *LogRepository) scanLogRow(row *sql.Row) (*entities.Log, error) {
 		&derivedSeverity,
 		&derivedSource,
 		&derivedCategory,
+		&derivedColor,
+		&log.Pinned,
+		&ingestedAt,
+		&expiresAt,
+		&bodySize,
+		&log.AnnotationCount,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -357,14 +1549,23 @@ func (r *LogRepository) scanLogRow(row *sql.Row) (*entities.Log, error) {
 	log.Metadata.DerivedSeverity = derivedSeverity.String
 	log.Metadata.DerivedSource = derivedSource.String
 	log.Metadata.DerivedCategory = derivedCategory.String
-
-	if bodyJSON != "" {
-		if err := json.Unmarshal([]byte(bodyJSON), &log.Body); err != nil {
-			log.Body = make(map[string]any)
-		}
-	} else {
-		log.Body = make(map[string]any)
+	log.Metadata.DerivedColor = derivedColor.String
+	log.IngestedAt = log.CreatedAt
+	if ingestedAt.Valid {
+		log.IngestedAt = ingestedAt.Time
 	}
+	if expiresAt.Valid {
+		log.ExpiresAt = &expiresAt.Time
+	}
+	log.BodySize = bodySize.Int64
+	if !bodySize.Valid {
+		// Stored before body sizes were recorded: fall back to the length of
+		// the JSON text read, which bodyExpr decodes whatever the encoding
+		// (0 when the body was not selected)
+		log.BodySize = int64(len(bodyJSON))
+	}
+
+	log.Body = decodeBody(bodyJSON)
 
 	return &log, nil
 }
@@ -1,8 +1,14 @@
 package sqlite
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,7 +16,7 @@ import (
 	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
 )
 
-func setupTestDB(t *testing.T) (*Database, func()) {
+func setupTestDB(t testing.TB) (*Database, func()) {
 	t.Helper()
 
 	// Create temp directory for test database
@@ -70,6 +76,50 @@ func TestLogRepository_Create(t *testing.T) {
 	}
 }
 
+func TestLogRepository_BodySize(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	db.SetCompressBodies(true)
+
+	repo := NewLogRepository(db)
+
+	small := createTestLog("Small body", valueobjects.SeverityInfo)
+	small.Body["key"] = "value"
+	large := createTestLog("Large body", valueobjects.SeverityInfo)
+	large.Body["payload"] = strings.Repeat("x", 4096)
+	for _, log := range []*entities.Log{small, large} {
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+		serialized, _ := json.Marshal(log.Body)
+		if log.BodySize != int64(len(serialized)) {
+			t.Errorf("%s: expected BodySize %d after create, got %d", log.Header.Title, len(serialized), log.BodySize)
+		}
+
+		found, err := repo.FindByID(log.ID)
+		if err != nil {
+			t.Fatalf("failed to find log: %v", err)
+		}
+		if found.BodySize != log.BodySize {
+			t.Errorf("%s: expected stored BodySize %d, got %d", log.Header.Title, log.BodySize, found.BodySize)
+		}
+	}
+
+	// Logs stored before sizes were recorded are sized from their body
+	if _, err := db.Conn().Exec("UPDATE logs SET body_size = NULL"); err != nil {
+		t.Fatalf("failed to clear body sizes: %v", err)
+	}
+	for _, log := range []*entities.Log{small, large} {
+		found, err := repo.FindByID(log.ID)
+		if err != nil {
+			t.Fatalf("failed to find log: %v", err)
+		}
+		if found.BodySize != log.BodySize {
+			t.Errorf("%s: expected BodySize %d without a stored size, got %d", log.Header.Title, log.BodySize, found.BodySize)
+		}
+	}
+}
+
 func TestLogRepository_FindByID(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -305,6 +355,96 @@ func TestLogRepository_CountLast24Hours(t *testing.T) {
 	}
 }
 
+func TestLogRepository_TimeFields(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	// Imported today, but the event happened three days ago
+	imported := createTestLog("Imported", valueobjects.SeverityInfo)
+	imported.CreatedAt = time.Now().Add(-72 * time.Hour)
+	if err := repo.Create(imported); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+
+	found, err := repo.FindByID(imported.ID)
+	if err != nil {
+		t.Fatalf("failed to find log: %v", err)
+	}
+	if !found.CreatedAt.Equal(imported.CreatedAt) || !found.IngestedAt.Equal(imported.IngestedAt) {
+		t.Errorf("expected both timestamps to round-trip, got created %v ingested %v", found.CreatedAt, found.IngestedAt)
+	}
+	if found.IngestedAt.Sub(found.CreatedAt) < 71*time.Hour {
+		t.Errorf("expected ingested_at to be kept separate from created_at, got %v and %v", found.CreatedAt, found.IngestedAt)
+	}
+
+	byEvent := repo.WithTimeField(EventTime)
+	byIngest := repo.WithTimeField(IngestedAt)
+
+	if count, _ := byEvent.CountLast24Hours(); count != 0 {
+		t.Errorf("expected 0 logs in the last 24h by event time, got %d", count)
+	}
+	if count, _ := byIngest.CountLast24Hours(); count != 1 {
+		t.Errorf("expected 1 log in the last 24h by ingestion time, got %d", count)
+	}
+
+	cutoff := time.Now().Add(-24 * time.Hour)
+	if counts, _ := byEvent.CountOlderThanContext(context.Background(), []time.Time{cutoff}); counts[0] != 1 {
+		t.Errorf("expected 1 log older than a day by event time, got %d", counts[0])
+	}
+	if deleted, _ := byIngest.DeleteOlderThan(cutoff); deleted != 0 {
+		t.Errorf("expected nothing deleted by ingestion time, got %d", deleted)
+	}
+	if deleted, _ := byEvent.DeleteOlderThan(cutoff); deleted != 1 {
+		t.Errorf("expected the log deleted by event time, got %d", deleted)
+	}
+}
+
+func TestParseTimeField(t *testing.T) {
+	for name, want := range map[string]TimeField{"": EventTime, "event_time": EventTime, "ingested_at": IngestedAt} {
+		got, err := ParseTimeField(name)
+		if err != nil || got != want {
+			t.Errorf("ParseTimeField(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseTimeField("created_at"); err == nil {
+		t.Error("expected error for unknown time field")
+	}
+}
+
+func TestLogRepository_Exists(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	log := createTestLog("Present", valueobjects.SeverityInfo)
+	if err := repo.Create(log); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+
+	if exists, err := repo.Exists(log.ID); err != nil || !exists {
+		t.Errorf("expected log %d to exist, got %v, %v", log.ID, exists, err)
+	}
+	if exists, err := repo.Exists(log.ID + 1); err != nil || exists {
+		t.Errorf("expected log %d not to exist, got %v, %v", log.ID+1, exists, err)
+	}
+
+	// Logs of another tenant do not exist for it
+	other := ContextWithTenant(context.Background(), "team-b")
+	if exists, err := repo.ExistsContext(other, log.ID); err != nil || exists {
+		t.Errorf("expected log %d not to exist for another tenant, got %v, %v", log.ID, exists, err)
+	}
+
+	if err := repo.Delete(log.ID); err != nil {
+		t.Fatalf("failed to delete log: %v", err)
+	}
+	if exists, err := repo.Exists(log.ID); err != nil || exists {
+		t.Errorf("expected deleted log %d not to exist, got %v, %v", log.ID, exists, err)
+	}
+}
+
 func TestLogRepository_DeleteOlderThan(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -336,190 +476,1570 @@ func TestLogRepository_DeleteOlderThan(t *testing.T) {
 	}
 }
 
-func TestLogRepository_CountBySeverity(t *testing.T) {
+func TestLogRepository_DeleteByFilter(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
 	repo := NewLogRepository(db)
 
-	// Create logs with different severities
-	severities := []valueobjects.Severity{
-		valueobjects.SeverityError,
-		valueobjects.SeverityError,
-		valueobjects.SeverityWarning,
-		valueobjects.SeverityInfo,
-		valueobjects.SeverityInfo,
-		valueobjects.SeverityInfo,
-	}
-	for _, sev := range severities {
-		log := createTestLog("Log", sev)
+	for _, spec := range []struct {
+		severity valueobjects.Severity
+		source   string
+	}{
+		{valueobjects.SeverityDebug, "test-source"},
+		{valueobjects.SeverityDebug, "test-source"},
+		{valueobjects.SeverityInfo, "test-source"},
+		{valueobjects.SeverityDebug, "api"},
+		{valueobjects.SeverityError, "api"},
+	} {
+		log := createTestLog("Log", spec.severity)
+		log.Header.Source = spec.source
 		if err := repo.Create(log); err != nil {
 			t.Fatalf("failed to create log: %v", err)
 		}
 	}
 
-	counts, err := repo.CountBySeverity()
+	deleted, err := repo.DeleteByFilter(LogFilters{Severity: "debug", Source: "test-source", Limit: 1})
 	if err != nil {
-		t.Fatalf("failed to count by severity: %v", err)
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 deleted regardless of Limit, got %d", deleted)
 	}
 
-	if counts["error"] != 2 {
-		t.Errorf("expected 2 errors, got %d", counts["error"])
+	logs, total, _ := repo.FindAll(LogFilters{})
+	if total != 3 {
+		t.Fatalf("expected 3 logs remaining, got %d", total)
 	}
-	if counts["warning"] != 1 {
-		t.Errorf("expected 1 warning, got %d", counts["warning"])
+	for _, log := range logs {
+		if log.Header.Severity == valueobjects.SeverityDebug && log.Header.Source == "test-source" {
+			t.Errorf("expected matching log %d to be deleted", log.ID)
+		}
 	}
-	if counts["info"] != 3 {
-		t.Errorf("expected 3 info, got %d", counts["info"])
+
+	// OR groups delete like they list
+	deleted, err = repo.DeleteByFilter(LogFilters{Or: []LogFilters{{Severity: "error"}, {Source: "test-source"}}})
+	if err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 deleted, got %d", deleted)
+	}
+
+	if _, err := repo.DeleteByFilter(LogFilters{Limit: 10}); !errors.Is(err, ErrNoFilter) {
+		t.Errorf("expected ErrNoFilter without conditions, got %v", err)
+	}
+	if count, _ := repo.Count(); count != 1 {
+		t.Errorf("expected 1 log remaining, got %d", count)
+	}
+
+	// Other tenants' logs are untouched
+	ctx := ContextWithTenant(context.Background(), "acme")
+	deleted, err = repo.DeleteByFilterContext(ctx, LogFilters{Severity: "debug"})
+	if err != nil || deleted != 0 {
+		t.Errorf("expected nothing deleted for another tenant, got %d, %v", deleted, err)
 	}
 }
 
-func TestLogRepository_CountBySource(t *testing.T) {
+func TestLogRepository_DeleteOlderThan_SkipsPinned(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
 	repo := NewLogRepository(db)
 
-	// Create logs with different sources (empty values map to "unknown")
-	sources := []string{"api", "api", "database", "", ""}
-	for _, src := range sources {
+	var ids []int64
+	for i := 0; i < 3; i++ {
 		log := createTestLog("Log", valueobjects.SeverityInfo)
-		log.Header.Source = src
 		if err := repo.Create(log); err != nil {
 			t.Fatalf("failed to create log: %v", err)
 		}
+		ids = append(ids, log.ID)
 	}
 
-	counts, err := repo.CountBySource()
+	if err := repo.SetPinned(ids[1], true); err != nil {
+		t.Fatalf("failed to pin log: %v", err)
+	}
+
+	cutoff := time.Now().Add(1 * time.Hour)
+	deletable, err := repo.CountDeletableContext(context.Background(), cutoff)
 	if err != nil {
-		t.Fatalf("failed to count by source: %v", err)
+		t.Fatalf("failed to count: %v", err)
+	}
+	if deletable != 2 {
+		t.Errorf("expected 2 deletable logs, got %d", deletable)
 	}
 
-	if counts["api"] != 2 {
-		t.Errorf("expected 2 from api, got %d", counts["api"])
+	// Age counts include the pinned log, so they add up to the total
+	counts, err := repo.CountOlderThanContext(context.Background(), []time.Time{cutoff})
+	if err != nil {
+		t.Fatalf("failed to count: %v", err)
 	}
-	if counts["database"] != 1 {
-		t.Errorf("expected 1 from database, got %d", counts["database"])
+	if counts[0] != 3 {
+		t.Errorf("expected 3 logs older than the cutoff, got %d", counts[0])
 	}
-	if counts["unknown"] != 2 {
-		t.Errorf("expected 2 unknown, got %d", counts["unknown"])
+
+	deleted, err := repo.DeleteOlderThan(cutoff)
+	if err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 deleted, got %d", deleted)
+	}
+
+	log, err := repo.FindByID(ids[1])
+	if err != nil {
+		t.Fatalf("expected pinned log to survive: %v", err)
+	}
+	if !log.Pinned {
+		t.Error("expected log to still be pinned")
 	}
 }
 
-func TestLogRepository_FindAll_ColorFilter(t *testing.T) {
+func TestLogRepository_SetPinned(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
 	repo := NewLogRepository(db)
 
-	// Create logs with different colors
-	log1 := createTestLog("Red log", valueobjects.SeverityError)
-	log1.Header.Color = "red"
-	if err := repo.Create(log1); err != nil {
+	log := createTestLog("Log", valueobjects.SeverityInfo)
+	if err := repo.Create(log); err != nil {
 		t.Fatalf("failed to create log: %v", err)
 	}
 
-	log2 := createTestLog("Blue log", valueobjects.SeverityInfo)
-	log2.Header.Color = "blue"
-	if err := repo.Create(log2); err != nil {
-		t.Fatalf("failed to create log: %v", err)
+	if err := repo.SetPinned(log.ID, true); err != nil {
+		t.Fatalf("failed to pin log: %v", err)
+	}
+	found, _ := repo.FindByID(log.ID)
+	if !found.Pinned {
+		t.Error("expected log to be pinned")
 	}
 
-	log3 := createTestLog("Another red log", valueobjects.SeverityError)
-	log3.Header.Color = "red"
-	if err := repo.Create(log3); err != nil {
-		t.Fatalf("failed to create log: %v", err)
+	if err := repo.SetPinned(log.ID, false); err != nil {
+		t.Fatalf("failed to unpin log: %v", err)
+	}
+	found, _ = repo.FindByID(log.ID)
+	if found.Pinned {
+		t.Error("expected log to be unpinned")
 	}
 
-	// Filter by color
-	logs, total, err := repo.FindAll(LogFilters{Color: "red"})
+	if err := repo.SetPinned(99999, true); err != entities.ErrLogNotFound {
+		t.Errorf("expected ErrLogNotFound, got %v", err)
+	}
+}
+
+func TestLogRepository_RemapSeverity(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	seed := []struct {
+		title    string
+		severity valueobjects.Severity
+		derived  string
+		source   string
+	}{
+		{"Noisy 1", valueobjects.SeverityError, "", "crawler"},
+		{"Noisy 2", valueobjects.SeverityError, "", "crawler"},
+		{"Crawler warning", valueobjects.SeverityWarning, "", "crawler"},
+		{"Real error", valueobjects.SeverityError, "", "billing"},
+		// Matched and rewritten by effective severity
+		{"Derived error", valueobjects.SeverityInfo, "error", "crawler"},
+		{"Derived warning", valueobjects.SeverityError, "warning", "crawler"},
+	}
+	ids := make(map[string]int64)
+	for _, s := range seed {
+		log := createTestLog(s.title, s.severity)
+		log.Header.Source = s.source
+		log.Metadata.DerivedSeverity = s.derived
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+		ids[s.title] = log.ID
+	}
+
+	updated, err := repo.RemapSeverity("crawler", "error", "debug")
 	if err != nil {
-		t.Fatalf("failed to filter by color: %v", err)
+		t.Fatalf("failed to remap severity: %v", err)
+	}
+	if updated != 3 {
+		t.Errorf("expected 3 logs updated, got %d", updated)
+	}
+
+	want := map[string]struct {
+		severity valueobjects.Severity
+		derived  string
+	}{
+		"Noisy 1":         {valueobjects.SeverityDebug, ""},
+		"Noisy 2":         {valueobjects.SeverityDebug, ""},
+		"Crawler warning": {valueobjects.SeverityWarning, ""},
+		"Real error":      {valueobjects.SeverityError, ""},
+		"Derived error":   {valueobjects.SeverityDebug, "debug"},
+		"Derived warning": {valueobjects.SeverityError, "warning"},
+	}
+	for title, w := range want {
+		found, err := repo.FindByID(ids[title])
+		if err != nil {
+			t.Fatalf("failed to find log: %v", err)
+		}
+		if found.Header.Severity != w.severity || found.Metadata.DerivedSeverity != w.derived {
+			t.Errorf("%s: expected severity %s (derived %q), got %s (derived %q)",
+				title, w.severity, w.derived, found.Header.Severity, found.Metadata.DerivedSeverity)
+		}
 	}
-	if len(logs) != 2 || total != 2 {
-		t.Errorf("expected 2 red logs, got %d (total: %d)", len(logs), total)
+
+	updated, err = repo.RemapSeverity("unknown", "error", "debug")
+	if err != nil || updated != 0 {
+		t.Errorf("expected no updates for unknown source, got %d (%v)", updated, err)
 	}
 }
 
-func TestLogRepository_FindAll_DateFilters(t *testing.T) {
+func TestLogRepository_FindAll_PinnedFilter(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
 	repo := NewLogRepository(db)
 
-	// Create logs
-	log := createTestLog("Today's log", valueobjects.SeverityInfo)
-	if err := repo.Create(log); err != nil {
-		t.Fatalf("failed to create log: %v", err)
+	for i := 0; i < 3; i++ {
+		log := createTestLog("Log", valueobjects.SeverityInfo)
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+		if i == 0 {
+			if err := repo.SetPinned(log.ID, true); err != nil {
+				t.Fatalf("failed to pin log: %v", err)
+			}
+		}
 	}
 
-	// Filter with from date in the past (should include)
-	yesterday := time.Now().Add(-24 * time.Hour).Format("2006-01-02T15:04:05Z07:00")
-	logs, _, err := repo.FindAll(LogFilters{FromDate: yesterday})
+	pinned, unpinned := true, false
+
+	logs, total, err := repo.FindAll(LogFilters{Pinned: &pinned})
 	if err != nil {
-		t.Fatalf("failed to filter by from date: %v", err)
+		t.Fatalf("failed to find logs: %v", err)
 	}
-	if len(logs) != 1 {
-		t.Errorf("expected 1 log from yesterday filter, got %d", len(logs))
+	if total != 1 || len(logs) != 1 || !logs[0].Pinned {
+		t.Errorf("expected 1 pinned log, got total=%d len=%d", total, len(logs))
 	}
 
-	// Filter with from date in the future (should exclude)
-	tomorrow := time.Now().Add(24 * time.Hour).Format("2006-01-02T15:04:05Z07:00")
-	logs, _, err = repo.FindAll(LogFilters{FromDate: tomorrow})
-	if err != nil {
-		t.Fatalf("failed to filter by future from date: %v", err)
+	_, total, _ = repo.FindAll(LogFilters{Pinned: &unpinned})
+	if total != 2 {
+		t.Errorf("expected 2 unpinned logs, got %d", total)
 	}
-	if len(logs) != 0 {
-		t.Errorf("expected 0 logs from future filter, got %d", len(logs))
+
+	_, total, _ = repo.FindAll(LogFilters{})
+	if total != 3 {
+		t.Errorf("expected 3 logs without filter, got %d", total)
 	}
+}
 
-	// Filter with to date in the future (should include)
-	logs, _, err = repo.FindAll(LogFilters{ToDate: tomorrow})
+func TestLogRepository_FindAll_IncludeBody(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	log := createTestLog("Log with body", valueobjects.SeverityInfo)
+	log.Body["request_id"] = "abc-123"
+	if err := repo.Create(log); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+
+	include, exclude := true, false
+
+	for _, filters := range []LogFilters{{}, {IncludeBody: &include}} {
+		logs, _, err := repo.FindAll(filters)
+		if err != nil {
+			t.Fatalf("failed to find logs: %v", err)
+		}
+		if len(logs) != 1 || logs[0].Body["request_id"] != "abc-123" {
+			t.Errorf("expected body to be included, got %+v", logs)
+		}
+	}
+
+	logs, total, err := repo.FindAll(LogFilters{IncludeBody: &exclude})
 	if err != nil {
-		t.Fatalf("failed to filter by to date: %v", err)
+		t.Fatalf("failed to find logs: %v", err)
 	}
-	if len(logs) != 1 {
-		t.Errorf("expected 1 log from to date filter, got %d", len(logs))
+	if total != 1 || len(logs) != 1 {
+		t.Fatalf("expected 1 log, got total=%d len=%d", total, len(logs))
+	}
+	if logs[0].Body == nil || len(logs[0].Body) != 0 {
+		t.Errorf("expected empty body map, got %v", logs[0].Body)
+	}
+	if logs[0].Header.Title != "Log with body" {
+		t.Errorf("expected other columns to be read, got title %q", logs[0].Header.Title)
+	}
+
+	// Body filters still apply when the column is not selected
+	_, total, _ = repo.FindAll(LogFilters{BodySearch: "abc-123", IncludeBody: &exclude})
+	if total != 1 {
+		t.Errorf("expected body search to match without selecting body, got %d", total)
 	}
 }
 
-func TestLogRepository_FindAll_CombinedFilters(t *testing.T) {
+func TestLogRepository_FindAll_BodyFields(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
 	repo := NewLogRepository(db)
 
-	// Create various logs
-	log1 := createTestLog("API error", valueobjects.SeverityError)
-	log1.Header.Source = "api"
-	if err := repo.Create(log1); err != nil {
-		t.Fatalf("failed to create log: %v", err)
+	for _, body := range []map[string]any{
+		{"duration_ms": float64(120), "status_code": float64(500)},
+		{"duration_ms": "120", "status_code": float64(200)},
+		{"duration_ms": float64(80)},
+	} {
+		log := createTestLog("Request handled", valueobjects.SeverityInfo)
+		log.Body = body
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
 	}
 
-	log2 := createTestLog("API warning", valueobjects.SeverityWarning)
-	log2.Header.Source = "api"
-	if err := repo.Create(log2); err != nil {
-		t.Fatalf("failed to create log: %v", err)
+	tests := []struct {
+		fields map[string]string
+		want   int
+	}{
+		{map[string]string{"duration_ms": "120"}, 2},
+		{map[string]string{"duration_ms": "120", "status_code": "500"}, 1},
+		{map[string]string{"duration_ms": "80"}, 1},
+		{map[string]string{"missing": "1"}, 0},
+	}
+	for _, tt := range tests {
+		_, total, err := repo.FindAll(LogFilters{BodyFields: tt.fields})
+		if err != nil {
+			t.Fatalf("failed to find logs for %v: %v", tt.fields, err)
+		}
+		if total != tt.want {
+			t.Errorf("BodyFields %v: expected %d logs, got %d", tt.fields, tt.want, total)
+		}
 	}
 
-	log3 := createTestLog("DB error", valueobjects.SeverityError)
-	log3.Header.Source = "database"
-	if err := repo.Create(log3); err != nil {
-		t.Fatalf("failed to create log: %v", err)
+	if _, _, err := repo.FindAll(LogFilters{BodyFields: map[string]string{`a"b`: "1"}}); err == nil {
+		t.Error("expected error for invalid body field")
 	}
+}
 
-	// Combine source + severity
-	logs, total, err := repo.FindAll(LogFilters{
-		Source:   "api",
-		Severity: "error",
-	})
-	if err != nil {
-		t.Fatalf("failed with combined filters: %v", err)
+func TestLogRepository_FindAll_TieBreakByID(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	// A batch insert stamps every log with the same timestamp
+	createdAt := time.Now().UTC().Truncate(time.Millisecond)
+	batch := make([]*entities.Log, 10)
+	for i := range batch {
+		batch[i] = createTestLog(fmt.Sprintf("Batch log %d", i), valueobjects.SeverityInfo)
+		batch[i].CreatedAt = createdAt
 	}
-	if len(logs) != 1 || total != 1 {
-		t.Errorf("expected 1 api error, got %d (total: %d)", len(logs), total)
+	if err := repo.CreateBatchContext(context.Background(), batch); err != nil {
+		t.Fatalf("failed to create logs: %v", err)
 	}
-	if logs[0].Header.Title != "API error" {
-		t.Errorf("expected 'API error', got %q", logs[0].Header.Title)
+
+	var want []int64
+	for i := len(batch) - 1; i >= 0; i-- {
+		want = append(want, batch[i].ID)
+	}
+
+	for run := 0; run < 3; run++ {
+		logs, _, err := repo.FindAll(LogFilters{})
+		if err != nil {
+			t.Fatalf("failed to find logs: %v", err)
+		}
+		for i, log := range logs {
+			if log.ID != want[i] {
+				t.Fatalf("run %d: expected id %d at position %d, got %d", run, want[i], i, log.ID)
+			}
+		}
+	}
+
+	// Pages must neither repeat nor skip logs across boundaries
+	var paged []int64
+	for offset := 0; offset < len(batch); offset += 3 {
+		logs, _, err := repo.FindAll(LogFilters{Limit: 3, Offset: offset})
+		if err != nil {
+			t.Fatalf("failed to find page at offset %d: %v", offset, err)
+		}
+		for _, log := range logs {
+			paged = append(paged, log.ID)
+		}
+	}
+	if fmt.Sprint(paged) != fmt.Sprint(want) {
+		t.Errorf("expected paged ids %v, got %v", want, paged)
+	}
+}
+
+func BenchmarkLogRepository_FindAll_LargeBodies(b *testing.B) {
+	db, cleanup := setupTestDB(b)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	items := make([]any, 200)
+	for i := range items {
+		items[i] = map[string]any{"id": i, "name": fmt.Sprintf("item-%d", i), "tags": []string{"a", "b", "c"}}
+	}
+	for i := 0; i < 100; i++ {
+		log := createTestLog(fmt.Sprintf("Log %d", i), valueobjects.SeverityInfo)
+		log.Body["items"] = items
+		if err := repo.Create(log); err != nil {
+			b.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	for _, include := range []bool{true, false} {
+		b.Run(fmt.Sprintf("include_body=%v", include), func(b *testing.B) {
+			filters := LogFilters{Limit: 50, IncludeBody: &include}
+			for i := 0; i < b.N; i++ {
+				if _, _, err := repo.FindAll(filters); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestLogRepository_FindAllContext_Canceled(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	// Enough body data that an unmatched search scans for well over the
+	// cancellation delay below
+	filler := strings.Repeat("x", 2048)
+	logs := make([]*entities.Log, 5000)
+	for i := range logs {
+		logs[i] = createTestLog("Log", valueobjects.SeverityInfo)
+		logs[i].Body["filler"] = filler
+	}
+	if err := repo.CreateBatchContext(context.Background(), logs); err != nil {
+		t.Fatalf("failed to create logs: %v", err)
+	}
+
+	// Cancel the context while the query runs, as if the client
+	// disconnected mid-request
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	logs, _, err := repo.FindAllContext(ctx, LogFilters{Search: "no-such-term"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if logs != nil {
+		t.Errorf("expected no logs from canceled query, got %d", len(logs))
+	}
+}
+
+func TestLogRepository_DeleteOlderThanContext_Canceled(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	for i := 0; i < 3; i++ {
+		log := createTestLog("Log", valueobjects.SeverityInfo)
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := repo.DeleteOlderThanContext(ctx, time.Now().Add(1*time.Hour))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// Nothing should have been deleted
+	count, _ := repo.Count()
+	if count != 3 {
+		t.Errorf("expected 3 logs remaining, got %d", count)
+	}
+}
+
+func TestLogRepository_CountOlderThanContext(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	now := time.Now()
+	for _, age := range []time.Duration{time.Hour, 48 * time.Hour, 240 * time.Hour} {
+		log := createTestLog("Log", valueobjects.SeverityInfo)
+		log.CreatedAt = now.Add(-age)
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	counts, err := repo.CountOlderThanContext(context.Background(), []time.Time{
+		now,
+		now.Add(-24 * time.Hour),
+		now.Add(-168 * time.Hour),
+		now.Add(-720 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("failed to count: %v", err)
+	}
+
+	want := []int{3, 2, 1, 0}
+	for i := range want {
+		if counts[i] != want[i] {
+			t.Errorf("cutoff %d: expected %d, got %d", i, want[i], counts[i])
+		}
+	}
+}
+
+func TestLogRepository_CountBySeverity(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	// Create logs with different severities
+	severities := []valueobjects.Severity{
+		valueobjects.SeverityError,
+		valueobjects.SeverityError,
+		valueobjects.SeverityWarning,
+		valueobjects.SeverityInfo,
+		valueobjects.SeverityInfo,
+		valueobjects.SeverityInfo,
+	}
+	for _, sev := range severities {
+		log := createTestLog("Log", sev)
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	counts, err := repo.CountBySeverity()
+	if err != nil {
+		t.Fatalf("failed to count by severity: %v", err)
+	}
+
+	if counts["error"] != 2 {
+		t.Errorf("expected 2 errors, got %d", counts["error"])
+	}
+	if counts["warning"] != 1 {
+		t.Errorf("expected 1 warning, got %d", counts["warning"])
+	}
+	if counts["info"] != 3 {
+		t.Errorf("expected 3 info, got %d", counts["info"])
+	}
+}
+
+func TestLogRepository_CountBySource(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	// Create logs with different sources (empty values map to "unknown")
+	sources := []string{"api", "api", "database", "", ""}
+	for _, src := range sources {
+		log := createTestLog("Log", valueobjects.SeverityInfo)
+		log.Header.Source = src
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	counts, err := repo.CountBySource()
+	if err != nil {
+		t.Fatalf("failed to count by source: %v", err)
+	}
+
+	if counts["api"] != 2 {
+		t.Errorf("expected 2 from api, got %d", counts["api"])
+	}
+	if counts["database"] != 1 {
+		t.Errorf("expected 1 from database, got %d", counts["database"])
+	}
+	if counts["unknown"] != 2 {
+		t.Errorf("expected 2 unknown, got %d", counts["unknown"])
+	}
+}
+
+func TestLogRepository_CountByEffectiveSource(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	// Explicit sources win over derived ones; logs with neither are "unknown"
+	logs := []struct{ source, derived string }{
+		{"api", ""},
+		{"api", "worker"},
+		{"", "worker"},
+		{"", "worker"},
+		{"", "database"},
+		{"", ""},
+	}
+	for _, l := range logs {
+		log := createTestLog("Log", valueobjects.SeverityInfo)
+		log.Header.Source = l.source
+		log.Metadata.DerivedSource = l.derived
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	counts, err := repo.CountByEffectiveSource()
+	if err != nil {
+		t.Fatalf("failed to count by effective source: %v", err)
+	}
+
+	want := map[string]int{"api": 2, "worker": 2, "database": 1, "unknown": 1}
+	if len(counts) != len(want) {
+		t.Errorf("expected %d sources, got %v", len(want), counts)
+	}
+	for source, n := range want {
+		if counts[source] != n {
+			t.Errorf("expected %d from %s, got %d", n, source, counts[source])
+		}
+	}
+
+	// The raw breakdown still ignores derived sources
+	raw, err := repo.CountBySource()
+	if err != nil {
+		t.Fatalf("failed to count by source: %v", err)
+	}
+	if raw["worker"] != 0 {
+		t.Errorf("expected no raw worker count, got %d", raw["worker"])
+	}
+}
+
+func TestLogRepository_UnknownSourceLabel(t *testing.T) {
+	tests := []struct {
+		name  string
+		label string
+		// want maps each effective source to its count; the label's
+		// entry is also the number of logs a filter on it returns
+		want map[string]int
+	}{
+		{
+			name:  "default label merges with a real unknown source",
+			label: "",
+			want:  map[string]int{"api": 1, "worker": 1, "unknown": 3},
+		},
+		{
+			name:  "custom label",
+			label: "uncategorized",
+			want:  map[string]int{"api": 1, "worker": 1, "unknown": 1, "uncategorized": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, cleanup := setupTestDB(t)
+			defer cleanup()
+			db.SetUnknownSourceLabel(tt.label)
+
+			repo := NewLogRepository(db)
+			logs := []struct{ source, derived string }{
+				{"api", ""},
+				{"unknown", ""},
+				{"", "worker"},
+				{"", ""},
+				{"", ""},
+			}
+			for _, l := range logs {
+				log := createTestLog("Log", valueobjects.SeverityError)
+				log.Header.Source = l.source
+				log.Metadata.DerivedSource = l.derived
+				if err := repo.Create(log); err != nil {
+					t.Fatalf("failed to create log: %v", err)
+				}
+			}
+
+			label := db.UnknownSourceLabel()
+			if tt.label == "" && label != DefaultUnknownSourceLabel {
+				t.Errorf("expected default label %q, got %q", DefaultUnknownSourceLabel, label)
+			}
+
+			counts, err := repo.CountByEffectiveSource()
+			if err != nil {
+				t.Fatalf("failed to count by effective source: %v", err)
+			}
+			if len(counts) != len(tt.want) {
+				t.Errorf("expected %d sources, got %v", len(tt.want), counts)
+			}
+			for source, n := range tt.want {
+				if counts[source] != n {
+					t.Errorf("expected %d from %s, got %d", n, source, counts[source])
+				}
+			}
+
+			raw, err := repo.CountBySource()
+			if err != nil {
+				t.Fatalf("failed to count by source: %v", err)
+			}
+			if raw[label] != tt.want[label]+tt.want["worker"] {
+				t.Errorf("expected %d raw %s, got %v", tt.want[label]+tt.want["worker"], label, raw)
+			}
+
+			errorCounts, err := repo.CountErrorsBySourceSinceContext(context.Background(), time.Now().Add(-time.Hour))
+			if err != nil {
+				t.Fatalf("failed to count errors by source: %v", err)
+			}
+			if len(errorCounts) != len(tt.want) {
+				t.Errorf("expected %d sources with errors, got %v", len(tt.want), errorCounts)
+			}
+			for _, count := range errorCounts {
+				if count.ErrorCount != tt.want[count.Source] {
+					t.Errorf("expected %d errors from %s, got %d", tt.want[count.Source], count.Source, count.ErrorCount)
+				}
+			}
+
+			_, total, err := repo.FindAll(LogFilters{Source: label})
+			if err != nil {
+				t.Fatalf("failed to filter by label: %v", err)
+			}
+			if total != tt.want[label] {
+				t.Errorf("expected %d logs filtered by %s, got %d", tt.want[label], label, total)
+			}
+
+			_, total, err = repo.FindAll(LogFilters{Source: "api"})
+			if err != nil {
+				t.Fatalf("failed to filter by source: %v", err)
+			}
+			if total != 1 {
+				t.Errorf("expected 1 log filtered by api, got %d", total)
+			}
+		})
+	}
+}
+
+func TestLogRepository_FindAll_MinSeverity(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	for _, severity := range []valueobjects.Severity{
+		valueobjects.SeverityDebug,
+		valueobjects.SeverityInfo,
+		valueobjects.SeveritySuccess,
+		valueobjects.SeverityWarning,
+		valueobjects.SeverityError,
+		valueobjects.SeverityCritical,
+	} {
+		if err := repo.Create(createTestLog("Log", severity)); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	// Derived severity takes precedence over the header severity
+	derived := createTestLog("Log", valueobjects.SeverityInfo)
+	derived.Metadata.DerivedSeverity = "error"
+	if err := repo.Create(derived); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+
+	logs, total, err := repo.FindAll(LogFilters{MinSeverity: "warning"})
+	if err != nil {
+		t.Fatalf("failed to find logs: %v", err)
+	}
+	if total != 4 || len(logs) != 4 {
+		t.Errorf("expected 4 logs at warning or above, got total=%d len=%d", total, len(logs))
+	}
+	for _, log := range logs {
+		if !log.EffectiveSeverity().AtLeast(valueobjects.SeverityWarning) {
+			t.Errorf("unexpected severity %s in results", log.EffectiveSeverity())
+		}
+	}
+
+	// Unknown thresholds match nothing
+	_, total, err = repo.FindAll(LogFilters{MinSeverity: "custom"})
+	if err != nil {
+		t.Fatalf("failed to find logs: %v", err)
+	}
+	if total != 0 {
+		t.Errorf("expected 0 logs for unknown threshold, got %d", total)
+	}
+}
+
+func TestLogRepository_FindAll_FieldSearch(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	seed := []struct {
+		title    string
+		severity valueobjects.Severity
+		body     map[string]any
+	}{
+		{"Payment declined", valueobjects.SeverityError, map[string]any{"reason": "card declined"}},
+		{"Payment succeeded", valueobjects.SeveritySuccess, map[string]any{"reason": "ok"}},
+		{"Refund issued", valueobjects.SeverityInfo, map[string]any{"note": "payment declined earlier"}},
+		{"Payment retried", valueobjects.SeverityWarning, map[string]any{"reason": "declined, retrying"}},
+	}
+	for _, s := range seed {
+		log := createTestLog(s.title, s.severity)
+		log.Body = s.body
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		filters LogFilters
+		want    int
+	}{
+		{"title only", LogFilters{TitleSearch: "payment"}, 3},
+		{"body only", LogFilters{BodySearch: "declined"}, 3},
+		{"title and body", LogFilters{TitleSearch: "payment", BodySearch: "declined"}, 2},
+		{"title with severity", LogFilters{TitleSearch: "payment", Severity: "error"}, 1},
+		{"body with min severity", LogFilters{BodySearch: "declined", MinSeverity: "warning"}, 2},
+		{"combined with broad search", LogFilters{Search: "refund", BodySearch: "declined"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, total, err := repo.FindAll(tt.filters)
+			if err != nil {
+				t.Fatalf("failed to find logs: %v", err)
+			}
+			if total != tt.want || len(logs) != tt.want {
+				t.Errorf("expected %d logs, got total=%d len=%d", tt.want, total, len(logs))
+			}
+		})
+	}
+}
+
+func TestLogRepository_FindAll_LiteralWildcards(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	seed := []struct {
+		title string
+		body  map[string]any
+	}{
+		{"Disk 50% full", map[string]any{"path": `C:\data`}},
+		{"Disk 500 GB free", map[string]any{"path": "/data"}},
+		{"Key a_b rotated", map[string]any{"key": "a_b"}},
+		{"Key axb rotated", map[string]any{"key": "axb"}},
+	}
+	for _, s := range seed {
+		log := createTestLog(s.title, valueobjects.SeverityInfo)
+		log.Body = s.body
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		filters LogFilters
+		want    []string
+	}{
+		{"percent", LogFilters{Search: "50%"}, []string{"Disk 50% full"}},
+		{"underscore", LogFilters{Search: "a_b"}, []string{"Key a_b rotated"}},
+		{"title percent", LogFilters{TitleSearch: "0% f"}, []string{"Disk 50% full"}},
+		{"body underscore", LogFilters{BodySearch: "a_b"}, []string{"Key a_b rotated"}},
+		{"backslash", LogFilters{BodySearch: `C:\`}, []string{"Disk 50% full"}},
+		{"only wildcards", LogFilters{Search: "%_"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, _, err := repo.FindAll(tt.filters)
+			if err != nil {
+				t.Fatalf("failed to find logs: %v", err)
+			}
+			var titles []string
+			for _, log := range logs {
+				titles = append(titles, log.Header.Title)
+			}
+			if !slices.Equal(titles, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, titles)
+			}
+		})
+	}
+}
+
+func TestLogRepository_CountByFieldContext(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	for _, h := range []entities.LogHeader{
+		{Title: "A", Severity: valueobjects.SeverityError, Source: "api"},
+		{Title: "B", Severity: valueobjects.SeverityError, Source: "api"},
+		{Title: "C", Severity: valueobjects.SeverityInfo, Source: "worker"},
+		{Title: "D", Severity: valueobjects.SeverityInfo},
+	} {
+		if err := repo.Create(entities.NewLog(h, nil)); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	sources, err := repo.CountByFieldContext(context.Background(), "source")
+	if err != nil {
+		t.Fatalf("failed to count by source: %v", err)
+	}
+	want := []FacetValue{{Value: "api", Count: 2}, {Value: "worker", Count: 1}}
+	if len(sources) != len(want) {
+		t.Fatalf("expected %v, got %v", want, sources)
+	}
+	for i := range want {
+		if sources[i] != want[i] {
+			t.Errorf("expected %v at %d, got %v", want[i], i, sources[i])
+		}
+	}
+
+	severities, err := repo.CountByFieldContext(context.Background(), "severity")
+	if err != nil {
+		t.Fatalf("failed to count by severity: %v", err)
+	}
+	if len(severities) != 2 || severities[0].Count != 2 || severities[1].Count != 2 {
+		t.Errorf("unexpected severity facet: %v", severities)
+	}
+
+	if _, err := repo.CountByFieldContext(context.Background(), "title"); !errors.Is(err, ErrInvalidFacet) {
+		t.Errorf("expected ErrInvalidFacet, got %v", err)
+	}
+}
+
+func TestLogRepository_FindAll_ColorFilter(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	// Create logs with different colors
+	log1 := createTestLog("Red log", valueobjects.SeverityError)
+	log1.Header.Color = "red"
+	if err := repo.Create(log1); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+
+	log2 := createTestLog("Blue log", valueobjects.SeverityInfo)
+	log2.Header.Color = "blue"
+	if err := repo.Create(log2); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+
+	log3 := createTestLog("Another red log", valueobjects.SeverityError)
+	log3.Header.Color = "red"
+	if err := repo.Create(log3); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+
+	// Filter by color
+	logs, total, err := repo.FindAll(LogFilters{Color: "red"})
+	if err != nil {
+		t.Fatalf("failed to filter by color: %v", err)
+	}
+	if len(logs) != 2 || total != 2 {
+		t.Errorf("expected 2 red logs, got %d (total: %d)", len(logs), total)
+	}
+}
+
+func TestLogRepository_FindAll_OrFilters(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	for _, l := range []struct {
+		title    string
+		severity valueobjects.Severity
+		source   string
+		age      time.Duration
+	}{
+		{"Old error", valueobjects.SeverityError, "api", 72 * time.Hour},
+		{"Payment info", valueobjects.SeverityInfo, "payment-service", 0},
+		{"Worker error", valueobjects.SeverityError, "worker", 0},
+		{"API info", valueobjects.SeverityInfo, "api", 0},
+	} {
+		log := createTestLog(l.title, l.severity)
+		log.Header.Source = l.source
+		log.CreatedAt = log.CreatedAt.Add(-l.age)
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	titles := func(filters LogFilters) []string {
+		t.Helper()
+		logs, total, err := repo.FindAll(filters)
+		if err != nil {
+			t.Fatalf("FindAll failed: %v", err)
+		}
+		if total != len(logs) {
+			t.Errorf("expected total %d to match the %d logs found", total, len(logs))
+		}
+		var titles []string
+		for _, log := range logs {
+			titles = append(titles, log.Header.Title)
+		}
+		slices.Sort(titles)
+		return titles
+	}
+
+	or := []LogFilters{{Severity: "error"}, {Source: "payment-service"}}
+	if got, want := titles(LogFilters{Or: or}), []string{"Old error", "Payment info", "Worker error"}; !slices.Equal(got, want) {
+		t.Errorf("expected the union %v, got %v", want, got)
+	}
+
+	yesterday := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+	if got, want := titles(LogFilters{Or: or, FromDate: yesterday}), []string{"Payment info", "Worker error"}; !slices.Equal(got, want) {
+		t.Errorf("expected the union within the date range %v, got %v", want, got)
+	}
+
+	// Conditions within a group are ANDed
+	group := []LogFilters{{Severity: "info", Source: "api"}, {Source: "worker"}}
+	if got, want := titles(LogFilters{Or: group}), []string{"API info", "Worker error"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if _, _, err := repo.FindAll(LogFilters{Or: make([]LogFilters, MaxOrFilters+1)}); err == nil {
+		t.Error("expected too many OR filters to be rejected")
+	}
+	if _, _, err := repo.FindAll(LogFilters{Or: []LogFilters{{Or: or}}}); err == nil {
+		t.Error("expected nested OR filters to be rejected")
+	}
+}
+
+func TestLogRepository_FindAll_DateFilters(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	// Create logs
+	log := createTestLog("Today's log", valueobjects.SeverityInfo)
+	if err := repo.Create(log); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+
+	// Filter with from date in the past (should include)
+	yesterday := time.Now().Add(-24 * time.Hour).Format("2006-01-02T15:04:05Z07:00")
+	logs, _, err := repo.FindAll(LogFilters{FromDate: yesterday})
+	if err != nil {
+		t.Fatalf("failed to filter by from date: %v", err)
+	}
+	if len(logs) != 1 {
+		t.Errorf("expected 1 log from yesterday filter, got %d", len(logs))
+	}
+
+	// Filter with from date in the future (should exclude)
+	tomorrow := time.Now().Add(24 * time.Hour).Format("2006-01-02T15:04:05Z07:00")
+	logs, _, err = repo.FindAll(LogFilters{FromDate: tomorrow})
+	if err != nil {
+		t.Fatalf("failed to filter by future from date: %v", err)
+	}
+	if len(logs) != 0 {
+		t.Errorf("expected 0 logs from future filter, got %d", len(logs))
+	}
+
+	// Filter with to date in the future (should include)
+	logs, _, err = repo.FindAll(LogFilters{ToDate: tomorrow})
+	if err != nil {
+		t.Fatalf("failed to filter by to date: %v", err)
+	}
+	if len(logs) != 1 {
+		t.Errorf("expected 1 log from to date filter, got %d", len(logs))
+	}
+}
+
+func TestLogRepository_FindAll_CombinedFilters(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	// Create various logs
+	log1 := createTestLog("API error", valueobjects.SeverityError)
+	log1.Header.Source = "api"
+	if err := repo.Create(log1); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+
+	log2 := createTestLog("API warning", valueobjects.SeverityWarning)
+	log2.Header.Source = "api"
+	if err := repo.Create(log2); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+
+	log3 := createTestLog("DB error", valueobjects.SeverityError)
+	log3.Header.Source = "database"
+	if err := repo.Create(log3); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+
+	// Combine source + severity
+	logs, total, err := repo.FindAll(LogFilters{
+		Source:   "api",
+		Severity: "error",
+	})
+	if err != nil {
+		t.Fatalf("failed with combined filters: %v", err)
+	}
+	if len(logs) != 1 || total != 1 {
+		t.Errorf("expected 1 api error, got %d (total: %d)", len(logs), total)
+	}
+	if logs[0].Header.Title != "API error" {
+		t.Errorf("expected 'API error', got %q", logs[0].Header.Title)
+	}
+}
+
+func TestLogRepository_Each(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+	ctx := context.Background()
+
+	// Groups of logs sharing a timestamp span batch boundaries
+	const count = 2*eachBatchSize + 7
+	base := time.Now().UTC().Truncate(time.Millisecond)
+	batch := make([]*entities.Log, count)
+	for i := range batch {
+		batch[i] = createTestLog(fmt.Sprintf("Log %d", i), valueobjects.SeverityInfo)
+		batch[i].CreatedAt = base.Add(time.Duration(i/3) * time.Second)
+	}
+	if err := repo.CreateBatchContext(ctx, batch); err != nil {
+		t.Fatalf("failed to create logs: %v", err)
+	}
+
+	var visited []*entities.Log
+	total, err := repo.EachContext(ctx, LogFilters{}, func(log *entities.Log) error {
+		visited = append(visited, log)
+		// Logs arriving mid-read must not be visited
+		if len(visited) == 1 {
+			return repo.CreateContext(ctx, createTestLog("Late log", valueobjects.SeverityInfo))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EachContext failed: %v", err)
+	}
+	if total != count || len(visited) != count {
+		t.Fatalf("expected %d logs visited of %d, got %d of %d", count, count, len(visited), total)
+	}
+
+	seen := make(map[int64]bool, count)
+	for i, log := range visited {
+		if seen[log.ID] {
+			t.Fatalf("log %d visited twice", log.ID)
+		}
+		seen[log.ID] = true
+		if i == 0 {
+			continue
+		}
+		prev := visited[i-1]
+		if log.CreatedAt.After(prev.CreatedAt) || log.CreatedAt.Equal(prev.CreatedAt) && log.ID > prev.ID {
+			t.Fatalf("log %d out of (created_at, id) order after log %d", log.ID, prev.ID)
+		}
+	}
+}
+
+func TestLogRepository_Each_LimitAndFilters(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+	for i := 0; i < 10; i++ {
+		severity := valueobjects.SeverityInfo
+		if i%2 == 0 {
+			severity = valueobjects.SeverityError
+		}
+		if err := repo.Create(createTestLog(fmt.Sprintf("Log %d", i), severity)); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	visited := 0
+	total, err := repo.EachContext(context.Background(), LogFilters{Severity: "error", Limit: 3}, func(log *entities.Log) error {
+		visited++
+		if log.Header.Severity != valueobjects.SeverityError {
+			t.Errorf("expected only error logs, got %s", log.Header.Severity)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EachContext failed: %v", err)
+	}
+	if total != 5 || visited != 3 {
+		t.Errorf("expected 3 of 5 logs visited, got %d of %d", visited, total)
+	}
+
+	stop := errors.New("stop")
+	visited = 0
+	_, err = repo.EachContext(context.Background(), LogFilters{}, func(*entities.Log) error {
+		visited++
+		return stop
+	})
+	if !errors.Is(err, stop) || visited != 1 {
+		t.Errorf("expected iteration to stop at the first error, got %v after %d logs", err, visited)
+	}
+}
+
+func TestLogRepository_FindByIDs(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+
+	var ids []int64
+	for _, title := range []string{"First", "Second", "Third"} {
+		log := createTestLog(title, valueobjects.SeverityInfo)
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+		ids = append(ids, log.ID)
+	}
+
+	logs, err := repo.FindByIDs([]int64{ids[2], 999, ids[0], ids[2], ids[1]})
+	if err != nil {
+		t.Fatalf("FindByIDs failed: %v", err)
+	}
+
+	var titles []string
+	for _, log := range logs {
+		titles = append(titles, log.Header.Title)
+	}
+	if fmt.Sprint(titles) != "[Third First Second]" {
+		t.Errorf("expected existing logs in request order without duplicates, got %v", titles)
+	}
+
+	logs, err = repo.FindByIDs(nil)
+	if err != nil || len(logs) != 0 {
+		t.Errorf("expected no logs for no IDs, got %d (%v)", len(logs), err)
+	}
+}
+
+func TestLogRepository_CorruptBodies(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+	ctx := context.Background()
+
+	ids := make([]int64, 4)
+	for i := range ids {
+		log := createTestLog("Log", valueobjects.SeverityInfo)
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+		ids[i] = log.ID
+	}
+
+	// Corrupt two bodies: one unparseable, one valid JSON but not an object
+	for id, body := range map[int64]string{ids[1]: `{"user": "ada"`, ids[3]: `[1, 2]`} {
+		if _, err := db.Conn().Exec("UPDATE logs SET body = ? WHERE id = ?", body, id); err != nil {
+			t.Fatalf("failed to corrupt body: %v", err)
+		}
+	}
+
+	corrupt, err := repo.FindCorruptBodyIDsContext(ctx)
+	if err != nil {
+		t.Fatalf("failed to find corrupt bodies: %v", err)
+	}
+	if len(corrupt) != 2 || corrupt[0] != ids[1] || corrupt[1] != ids[3] {
+		t.Fatalf("expected corrupt bodies %v, got %v", []int64{ids[1], ids[3]}, corrupt)
+	}
+
+	repaired, err := repo.QuarantineCorruptBodiesContext(ctx)
+	if err != nil {
+		t.Fatalf("failed to quarantine corrupt bodies: %v", err)
+	}
+	if len(repaired) != 2 || repaired[0] != ids[1] || repaired[1] != ids[3] {
+		t.Fatalf("expected repaired %v, got %v", []int64{ids[1], ids[3]}, repaired)
+	}
+
+	log, err := repo.FindByID(ids[1])
+	if err != nil {
+		t.Fatalf("failed to find log: %v", err)
+	}
+	if log.Body[BodyRawKey] != `{"user": "ada"` {
+		t.Errorf("expected raw body quarantined, got %v", log.Body)
+	}
+
+	// Intact bodies are untouched and nothing is left to repair
+	log, _ = repo.FindByID(ids[0])
+	if _, ok := log.Body[BodyRawKey]; ok {
+		t.Errorf("expected intact body untouched, got %v", log.Body)
+	}
+	if corrupt, _ := repo.FindCorruptBodyIDsContext(ctx); len(corrupt) != 0 {
+		t.Errorf("expected no corrupt bodies after repair, got %v", corrupt)
+	}
+}
+
+func TestLogRepository_FindNeighbors(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+	ctx := context.Background()
+
+	// Logs 1 and 2 share a timestamp, so id breaks the tie; log 3 is created
+	// first but is newest in time
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	offsets := []time.Duration{0, time.Minute, time.Minute, 10 * time.Minute, 2 * time.Minute, 3 * time.Minute}
+	sources := []string{"api", "worker", "api", "api", "worker", "api"}
+	ids := make([]int64, len(offsets))
+	for _, i := range []int{3, 0, 1, 2, 4, 5} {
+		log := createTestLog(fmt.Sprintf("Log %d", i), valueobjects.SeverityInfo)
+		log.Header.Source = sources[i]
+		log.CreatedAt = base.Add(offsets[i])
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+		ids[i] = log.ID
+	}
+
+	titles := func(logs []*entities.Log) []string {
+		result := make([]string, len(logs))
+		for i, log := range logs {
+			result[i] = log.Header.Title
+		}
+		return result
+	}
+
+	target, _ := repo.FindByID(ids[2])
+	tests := []struct {
+		name          string
+		before, after int
+		sameSource    bool
+		wantOlder     []string
+		wantNewer     []string
+	}{
+		{"all neighbors", 10, 10, false, []string{"Log 0", "Log 1"}, []string{"Log 4", "Log 5", "Log 3"}},
+		{"limited", 1, 2, false, []string{"Log 1"}, []string{"Log 4", "Log 5"}},
+		{"none", 0, 0, false, []string{}, []string{}},
+		{"same source", 10, 10, true, []string{"Log 0"}, []string{"Log 5", "Log 3"}},
+	}
+	for _, tt := range tests {
+		older, newer, err := repo.FindNeighborsContext(ctx, target, tt.before, tt.after, tt.sameSource)
+		if err != nil {
+			t.Fatalf("%s: failed to find neighbors: %v", tt.name, err)
+		}
+		if got := titles(older); !slices.Equal(got, tt.wantOlder) {
+			t.Errorf("%s: expected older %v, got %v", tt.name, tt.wantOlder, got)
+		}
+		if got := titles(newer); !slices.Equal(got, tt.wantNewer) {
+			t.Errorf("%s: expected newer %v, got %v", tt.name, tt.wantNewer, got)
+		}
+	}
+}
+
+func TestLogRepository_CompressBodies(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+	ctx := context.Background()
+
+	large := createTestLog("Large body", valueobjects.SeverityInfo)
+	large.Body = map[string]any{
+		"request_id": "req-42",
+		"trace":      strings.Repeat("frame at handler.go:120\n", 40),
+	}
+	small := createTestLog("Small body", valueobjects.SeverityInfo)
+	small.Body = map[string]any{"request_id": "req-7"}
+
+	// A row written before compression was enabled stays plain text.
+	plain := createTestLog("Plain body", valueobjects.SeverityInfo)
+	plain.Body = map[string]any{
+		"request_id": "req-1",
+		"trace":      strings.Repeat("frame at worker.go:88\n", 40),
+	}
+	if err := repo.CreateContext(ctx, plain); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+
+	db.SetCompressBodies(true)
+	if err := repo.CreateBatchContext(ctx, []*entities.Log{large, small}); err != nil {
+		t.Fatalf("failed to create logs: %v", err)
+	}
+
+	encodings := map[int64]string{plain.ID: "", large.ID: bodyEncodingGzip, small.ID: ""}
+	for id, want := range encodings {
+		var encoding string
+		var stored []byte
+		err := db.Conn().QueryRowContext(ctx, "SELECT body_encoding, body FROM logs WHERE id = ?", id).Scan(&encoding, &stored)
+		if err != nil {
+			t.Fatalf("failed to read stored body: %v", err)
+		}
+		if encoding != want {
+			t.Errorf("log %d: expected encoding %q, got %q", id, want, encoding)
+		}
+		if want == bodyEncodingGzip && strings.Contains(string(stored), "request_id") {
+			t.Errorf("log %d: expected compressed body on disk, got %q", id, stored)
+		}
+	}
+
+	for _, want := range []*entities.Log{plain, large, small} {
+		got, err := repo.FindByIDContext(ctx, want.ID)
+		if err != nil {
+			t.Fatalf("failed to find log: %v", err)
+		}
+		if got.Body["request_id"] != want.Body["request_id"] || got.Body["trace"] != want.Body["trace"] {
+			t.Errorf("%s: body did not round-trip, got %v", want.Header.Title, got.Body)
+		}
+	}
+
+	logs, total, err := repo.FindAll(LogFilters{BodySearch: "handler.go"})
+	if err != nil {
+		t.Fatalf("failed to search bodies: %v", err)
+	}
+	if total != 1 || logs[0].ID != large.ID {
+		t.Errorf("expected body search to match the compressed log, got %d logs", total)
+	}
+
+	logs, total, err = repo.FindAll(LogFilters{BodyFields: map[string]string{"request_id": "req-42"}})
+	if err != nil {
+		t.Fatalf("failed to filter body fields: %v", err)
+	}
+	if total != 1 || logs[0].ID != large.ID {
+		t.Errorf("expected body field filter to match the compressed log, got %d logs", total)
+	}
+
+	ids, err := repo.FindCorruptBodyIDsContext(ctx)
+	if err != nil {
+		t.Fatalf("failed to check integrity: %v", err)
+	}
+	if len(ids) != 0 {
+		t.Errorf("expected compressed bodies to pass the integrity check, got %v", ids)
+	}
+}
+
+func TestLogRepository_FindIDsContext(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 6; i++ {
+		severity := valueobjects.SeverityInfo
+		if i%2 == 0 {
+			severity = valueobjects.SeverityError
+		}
+		log := createTestLog(fmt.Sprintf("Log %d", i), severity)
+		log.CreatedAt = base.Add(time.Duration(i%3) * time.Minute) // ties broken by id
+		if err := repo.CreateContext(ctx, log); err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+
+	for _, filters := range []LogFilters{
+		{},
+		{Severity: "error"},
+		{Limit: 2, Offset: 1},
+		{Search: "no such log"},
+	} {
+		ids, total, err := repo.FindIDsContext(ctx, filters)
+		if err != nil {
+			t.Fatalf("%+v: failed to find ids: %v", filters, err)
+		}
+		logs, wantTotal, err := repo.FindAllContext(ctx, filters)
+		if err != nil {
+			t.Fatalf("%+v: failed to find logs: %v", filters, err)
+		}
+
+		want := make([]int64, 0, len(logs))
+		for _, log := range logs {
+			want = append(want, log.ID)
+		}
+		if !slices.Equal(ids, want) || total != wantTotal {
+			t.Errorf("%+v: expected ids %v of %d, got %v of %d", filters, want, wantTotal, ids, total)
+		}
+	}
+}
+
+func TestLogRepository_TenantIsolation(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+	teamA := ContextWithTenant(context.Background(), "team-a")
+	teamB := ContextWithTenant(context.Background(), "team-b")
+
+	logA := createTestLog("Team A error", valueobjects.SeverityError)
+	logA.Header.Source = "api"
+	if err := repo.CreateContext(teamA, logA); err != nil {
+		t.Fatalf("CreateContext() error = %v", err)
+	}
+	logsB := []*entities.Log{
+		createTestLog("Team B info", valueobjects.SeverityInfo),
+		createTestLog("Team B warning", valueobjects.SeverityWarning),
+	}
+	if err := repo.CreateBatchContext(teamB, logsB); err != nil {
+		t.Fatalf("CreateBatchContext() error = %v", err)
+	}
+	if err := repo.Create(createTestLog("Default log", valueobjects.SeverityInfo)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		ctx   context.Context
+		total int
+	}{
+		{"team-a", teamA, 1},
+		{"team-b", teamB, 2},
+		{"default", context.Background(), 1},
+	} {
+		if _, total, _ := repo.FindAllContext(tt.ctx, LogFilters{}); total != tt.total {
+			t.Errorf("%s: expected %d logs listed, got %d", tt.name, tt.total, total)
+		}
+		if count, _ := repo.CountContext(tt.ctx); count != tt.total {
+			t.Errorf("%s: expected count %d, got %d", tt.name, tt.total, count)
+		}
+	}
+
+	if counts, _ := repo.CountBySeverityContext(teamB); counts["error"] != 0 || counts["info"] != 1 || counts["warning"] != 1 {
+		t.Errorf("expected team-b severities without team-a's error, got %v", counts)
+	}
+	if counts, _ := repo.CountBySourceContext(teamB); counts["api"] != 0 {
+		t.Errorf("expected team-b sources without team-a's, got %v", counts)
+	}
+
+	// Another tenant's log cannot be read, pinned or deleted
+	if _, err := repo.FindByIDContext(teamB, logA.ID); !errors.Is(err, entities.ErrLogNotFound) {
+		t.Errorf("expected ErrLogNotFound reading another tenant's log, got %v", err)
+	}
+	if err := repo.SetPinnedContext(teamB, logA.ID, true); !errors.Is(err, entities.ErrLogNotFound) {
+		t.Errorf("expected ErrLogNotFound pinning another tenant's log, got %v", err)
+	}
+	if err := repo.DeleteContext(teamB, logA.ID); !errors.Is(err, entities.ErrLogNotFound) {
+		t.Errorf("expected ErrLogNotFound deleting another tenant's log, got %v", err)
+	}
+	if deleted, _ := repo.DeleteOlderThanContext(teamB, time.Now().Add(time.Hour)); deleted != 2 {
+		t.Errorf("expected retention to delete team-b's 2 logs, got %d", deleted)
+	}
+	if _, err := repo.FindByIDContext(teamA, logA.ID); err != nil {
+		t.Errorf("expected team-a's log to survive, got %v", err)
+	}
+}
+
+func TestValidTenant(t *testing.T) {
+	for name, want := range map[string]bool{
+		"team-a":                true,
+		"Team_1.prod":           true,
+		"":                      false,
+		"-team":                 false,
+		"../team":               false,
+		"team a":                false,
+		strings.Repeat("a", 64): true,
+		strings.Repeat("a", 65): false,
+	} {
+		if got := ValidTenant(name); got != want {
+			t.Errorf("ValidTenant(%q) = %v, want %v", name, got, want)
+		}
 	}
 }
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE logs ADD COLUMN pinned INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_logs_pinned ON logs(pinned);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_logs_pinned;
+
+ALTER TABLE logs DROP COLUMN pinned;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS log_annotations (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    log_id     INTEGER NOT NULL REFERENCES logs(id) ON DELETE CASCADE,
+    author     TEXT NOT NULL DEFAULT '',
+    text       TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_log_annotations_log_id ON log_annotations(log_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS log_annotations;
+-- +goose StatementEnd
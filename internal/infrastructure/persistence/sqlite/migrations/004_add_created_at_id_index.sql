@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_logs_created_at_id ON logs(created_at DESC, id DESC);
+
+DROP INDEX IF EXISTS idx_logs_created_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_logs_created_at ON logs(created_at);
+
+DROP INDEX IF EXISTS idx_logs_created_at_id;
+-- +goose StatementEnd
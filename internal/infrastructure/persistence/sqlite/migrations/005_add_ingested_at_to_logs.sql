@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE logs ADD COLUMN ingested_at DATETIME;
+
+UPDATE logs SET ingested_at = created_at;
+
+CREATE INDEX IF NOT EXISTS idx_logs_ingested_at ON logs(ingested_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_logs_ingested_at;
+
+ALTER TABLE logs DROP COLUMN ingested_at;
+-- +goose StatementEnd
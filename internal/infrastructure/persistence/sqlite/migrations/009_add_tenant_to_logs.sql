@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE logs ADD COLUMN tenant TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_logs_tenant_created_at_id ON logs(tenant, created_at DESC, id DESC);
+
+ALTER TABLE sse_events ADD COLUMN tenant TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sse_events DROP COLUMN tenant;
+
+DROP INDEX IF EXISTS idx_logs_tenant_created_at_id;
+
+ALTER TABLE logs DROP COLUMN tenant;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE logs ADD COLUMN expires_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_logs_expires_at ON logs(expires_at) WHERE expires_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_logs_expires_at;
+
+ALTER TABLE logs DROP COLUMN expires_at;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE logs ADD COLUMN body_size INTEGER;
+
+-- Compressed bodies are sized from their decompressed text when read
+UPDATE logs SET body_size = length(CAST(body AS BLOB)) WHERE body_encoding = '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE logs DROP COLUMN body_size;
+-- +goose StatementEnd
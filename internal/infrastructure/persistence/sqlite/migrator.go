@@ -3,9 +3,11 @@ package sqlite
 import (
 	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"sync"
 
 	"github.com/pressly/goose/v3"
 )
@@ -13,18 +15,31 @@ import (
 //go:embed migrations/*.sql
 var embedMigrations embed.FS
 
+// ErrSchemaMismatch is returned when the applied schema version differs from
+// the latest migration embedded in the binary.
+var ErrSchemaMismatch = errors.New("database schema version mismatch")
+
 func init() {
 	// Silence goose logger by default
 	goose.SetLogger(log.New(io.Discard, "", 0))
 }
 
-// RunMigrations runs all pending database migrations.
-func RunMigrations(db *sql.DB) error {
+// setupGoose points goose at the embedded migrations. Goose keeps this in
+// package state, so it is configured once to keep concurrent readers safe.
+var setupGoose = sync.OnceValue(func() error {
 	goose.SetBaseFS(embedMigrations)
 
 	if err := goose.SetDialect("sqlite3"); err != nil {
 		return fmt.Errorf("failed to set goose dialect: %w", err)
 	}
+	return nil
+})
+
+// RunMigrations runs all pending database migrations.
+func RunMigrations(db *sql.DB) error {
+	if err := setupGoose(); err != nil {
+		return err
+	}
 
 	if err := goose.Up(db, "migrations"); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
@@ -32,3 +47,64 @@ func RunMigrations(db *sql.DB) error {
 
 	return nil
 }
+
+// SchemaStatus compares the applied schema version with the version the
+// binary expects.
+type SchemaStatus struct {
+	Current  int64 `json:"current"`
+	Expected int64 `json:"expected"`
+}
+
+// IsCurrent reports whether the applied schema matches the expected version.
+func (s SchemaStatus) IsCurrent() bool {
+	return s.Current == s.Expected
+}
+
+// Err returns ErrSchemaMismatch, with both versions, when the schema is not
+// current, and nil otherwise.
+func (s SchemaStatus) Err() error {
+	if s.IsCurrent() {
+		return nil
+	}
+	return fmt.Errorf("%w: database is at version %d, binary expects %d", ErrSchemaMismatch, s.Current, s.Expected)
+}
+
+// LatestMigrationVersion returns the version of the newest embedded migration.
+func LatestMigrationVersion() (int64, error) {
+	return latestMigrationVersion()
+}
+
+var latestMigrationVersion = sync.OnceValues(func() (int64, error) {
+	if err := setupGoose(); err != nil {
+		return 0, err
+	}
+
+	migrations, err := goose.CollectMigrations("migrations", 0, goose.MaxVersion)
+	if err != nil {
+		return 0, fmt.Errorf("failed to collect migrations: %w", err)
+	}
+	latest, err := migrations.Last()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find latest migration: %w", err)
+	}
+	return latest.Version, nil
+})
+
+// CheckSchema reports the applied schema version against the latest
+// embedded migration.
+func CheckSchema(db *sql.DB) (SchemaStatus, error) {
+	expected, err := LatestMigrationVersion()
+	if err != nil {
+		return SchemaStatus{}, err
+	}
+
+	if err := setupGoose(); err != nil {
+		return SchemaStatus{}, err
+	}
+	current, err := goose.GetDBVersion(db)
+	if err != nil {
+		return SchemaStatus{}, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	return SchemaStatus{Current: current, Expected: expected}, nil
+}
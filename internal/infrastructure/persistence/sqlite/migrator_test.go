@@ -0,0 +1,76 @@
+package sqlite
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pressly/goose/v3"
+)
+
+// rollBackSchemaVersion rolls back the latest applied migration,
+// simulating a database whose schema is behind the binary.
+func rollBackSchemaVersion(t *testing.T, db *Database) {
+	t.Helper()
+	if err := setupGoose(); err != nil {
+		t.Fatalf("failed to set up goose: %v", err)
+	}
+	if err := goose.Down(db.Conn(), "migrations"); err != nil {
+		t.Fatalf("failed to roll back schema version: %v", err)
+	}
+}
+
+func TestLatestMigrationVersion(t *testing.T) {
+	latest, err := LatestMigrationVersion()
+	if err != nil {
+		t.Fatalf("failed to get latest migration version: %v", err)
+	}
+	if latest < 1 {
+		t.Errorf("expected a positive latest version, got %d", latest)
+	}
+}
+
+func TestCheckSchema_Current(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	status, err := CheckSchema(db.Conn())
+	if err != nil {
+		t.Fatalf("failed to check schema: %v", err)
+	}
+	if !status.IsCurrent() {
+		t.Errorf("expected schema to be current after migrations, got %+v", status)
+	}
+	if err := status.Err(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestCheckSchema_Behind(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	rollBackSchemaVersion(t, db)
+
+	status, err := CheckSchema(db.Conn())
+	if err != nil {
+		t.Fatalf("failed to check schema: %v", err)
+	}
+	if status.IsCurrent() {
+		t.Fatalf("expected schema to be behind, got %+v", status)
+	}
+	if status.Current >= status.Expected {
+		t.Errorf("expected current < expected, got %+v", status)
+	}
+	if !errors.Is(status.Err(), ErrSchemaMismatch) {
+		t.Errorf("expected ErrSchemaMismatch, got %v", status.Err())
+	}
+
+	// Re-running migrations brings the schema back to current
+	if err := RunMigrations(db.Conn()); err != nil {
+		t.Fatalf("failed to rerun migrations: %v", err)
+	}
+	status, _ = CheckSchema(db.Conn())
+	if !status.IsCurrent() {
+		t.Errorf("expected schema to be current after rerunning migrations, got %+v", status)
+	}
+}
@@ -0,0 +1,41 @@
+package sqlite
+
+import "context"
+
+// DefaultTenant is the tenant of logs stored without one, and of every
+// repository call whose context carries no tenant.
+const DefaultTenant = ""
+
+// maxTenantLength caps the length of a tenant name.
+const maxTenantLength = 64
+
+type tenantKey struct{}
+
+// ContextWithTenant returns a context whose repository calls only see and
+// create data of tenant.
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant set by ContextWithTenant, or
+// DefaultTenant when there is none.
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// ValidTenant reports whether name can be used as a tenant: up to 64
+// letters, digits, underscores, hyphens and dots, starting with a letter or
+// digit.
+func ValidTenant(name string) bool {
+	if name == "" || len(name) > maxTenantLength {
+		return false
+	}
+	for i, c := range name {
+		alnum := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+		if !alnum && (i == 0 || c != '_' && c != '-' && c != '.') {
+			return false
+		}
+	}
+	return true
+}
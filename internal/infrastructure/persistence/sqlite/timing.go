@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSlowQueryThreshold is the repository call duration above which a
+// timer set up with LogSlowQueries logs a warning by default.
+const DefaultSlowQueryThreshold = 200 * time.Millisecond
+
+// QueryTimer accumulates the time spent in repository calls made with a
+// context returned by WithQueryTimer. It is safe for concurrent use.
+type QueryTimer struct {
+	mu      sync.Mutex
+	total   time.Duration
+	queries int
+
+	// slowThreshold and slowLogger are set by LogSlowQueries
+	slowThreshold time.Duration
+	slowLogger    *slog.Logger
+}
+
+type queryTimerKey struct{}
+
+// WithQueryTimer returns a context that records repository call durations
+// into the returned timer.
+func WithQueryTimer(ctx context.Context) (context.Context, *QueryTimer) {
+	timer := &QueryTimer{}
+	return context.WithValue(ctx, queryTimerKey{}, timer), timer
+}
+
+// LogSlowQueries makes the timer log a warning to logger for every
+// repository call taking longer than threshold, with the call's label and
+// duration. A logger carrying request attributes ties the warning to its
+// request; nil uses slog.Default. A threshold of zero disables the warnings.
+func (t *QueryTimer) LogSlowQueries(threshold time.Duration, logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	t.mu.Lock()
+	t.slowThreshold = threshold
+	t.slowLogger = logger
+	t.mu.Unlock()
+}
+
+// Total returns the accumulated repository time and the number of calls.
+func (t *QueryTimer) Total() (time.Duration, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.total, t.queries
+}
+
+// observeQuery records the time since start on the context's timer, if any.
+// Repository context methods call it as defer observeQuery(ctx, time.Now()).
+func observeQuery(ctx context.Context, start time.Time) {
+	timer, ok := ctx.Value(queryTimerKey{}).(*QueryTimer)
+	if !ok {
+		return
+	}
+
+	elapsed := time.Since(start)
+	timer.mu.Lock()
+	timer.total += elapsed
+	timer.queries++
+	threshold, logger := timer.slowThreshold, timer.slowLogger
+	timer.mu.Unlock()
+
+	if threshold > 0 && elapsed > threshold {
+		// The caller is the repository method that deferred observeQuery
+		logger.WarnContext(ctx, "slow query",
+			"query", callerLabel(2),
+			"duration_ms", elapsed.Milliseconds(),
+			"threshold_ms", threshold.Milliseconds())
+	}
+}
+
+// callerLabel names the function skip frames above callerLabel, without
+// its package path, e.g. "LogRepository.FindAllContext".
+func callerLabel(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return "unknown"
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+
+	name := fn.Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	if _, rest, ok := strings.Cut(name, "."); ok {
+		name = rest
+	}
+	return strings.NewReplacer("(*", "", ")", "").Replace(name)
+}
@@ -0,0 +1,102 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/mx-scribe/scribe/internal/domain/valueobjects"
+)
+
+func TestQueryTimer_RecordsRepositoryCalls(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewLogRepository(db)
+	ctx, timer := WithQueryTimer(context.Background())
+
+	if err := repo.CreateContext(ctx, createTestLog("Timed", valueobjects.SeverityInfo)); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+	if _, _, err := repo.FindAllContext(ctx, LogFilters{Limit: 10}); err != nil {
+		t.Fatalf("failed to find logs: %v", err)
+	}
+
+	total, queries := timer.Total()
+	if queries != 2 {
+		t.Errorf("expected 2 recorded calls, got %d", queries)
+	}
+	if total <= 0 {
+		t.Errorf("expected positive recorded time, got %s", total)
+	}
+
+	// Calls without a timer in the context are not recorded anywhere
+	if _, err := repo.CountContext(context.Background()); err != nil {
+		t.Fatalf("failed to count logs: %v", err)
+	}
+	if _, queries := timer.Total(); queries != 2 {
+		t.Errorf("expected untimed call to be ignored, got %d calls", queries)
+	}
+}
+
+// slowCount stands in for a repository method whose query takes longer
+// than the slow query threshold.
+func (r *LogRepository) slowCount(ctx context.Context) {
+	defer observeQuery(ctx, time.Now().Add(-300*time.Millisecond))
+}
+
+func TestQueryTimer_LogsSlowQueries(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil)).With("request_id", "req-1")
+
+	repo := NewLogRepository(db)
+	ctx, timer := WithQueryTimer(context.Background())
+	timer.LogSlowQueries(DefaultSlowQueryThreshold, logger)
+
+	// A fast query stays quiet
+	if _, err := repo.CountContext(ctx); err != nil {
+		t.Fatalf("failed to count logs: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected no slow query log, got %s", buf.String())
+	}
+
+	repo.slowCount(ctx)
+
+	var entry struct {
+		Level      string `json:"level"`
+		Msg        string `json:"msg"`
+		Query      string `json:"query"`
+		DurationMS int64  `json:"duration_ms"`
+		RequestID  string `json:"request_id"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected one JSON log entry, got %q: %v", buf.String(), err)
+	}
+	if entry.Level != "WARN" || entry.Msg != "slow query" {
+		t.Errorf("expected a slow query warning, got %s %q", entry.Level, entry.Msg)
+	}
+	if entry.Query != "LogRepository.slowCount" {
+		t.Errorf("expected query label LogRepository.slowCount, got %q", entry.Query)
+	}
+	if entry.DurationMS < 300 {
+		t.Errorf("expected duration of at least 300ms, got %dms", entry.DurationMS)
+	}
+	if entry.RequestID != "req-1" {
+		t.Errorf("expected the logger's request attributes, got request_id %q", entry.RequestID)
+	}
+
+	// A zero threshold disables the warnings
+	buf.Reset()
+	timer.LogSlowQueries(0, logger)
+	repo.slowCount(ctx)
+	if buf.Len() != 0 {
+		t.Errorf("expected no log with a zero threshold, got %s", buf.String())
+	}
+}
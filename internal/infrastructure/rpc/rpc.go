@@ -0,0 +1,37 @@
+// Package rpc serves the gRPC ingestion service defined in
+// api/ingest/v1/ingest.proto. It is only available in binaries built with
+// -tags grpc; other builds report ErrGRPCUnsupported.
+package rpc
+
+import (
+	"errors"
+
+	"github.com/mx-scribe/scribe/internal/infrastructure/http/handlers"
+)
+
+// ErrGRPCUnsupported is returned by Start when the binary was built without
+// the grpc tag.
+var ErrGRPCUnsupported = errors.New("gRPC ingestion requires a build with -tags grpc")
+
+// tenantMetadata is the gRPC metadata key selecting the tenant of a call,
+// the counterpart of the X-Tenant header of the HTTP API.
+const tenantMetadata = "x-tenant"
+
+// Options configures the ingestion service like the HTTP server it runs
+// alongside.
+type Options struct {
+	// Ingest holds the ingestion defaults and policies shared with
+	// POST /api/logs. Nil uses the defaults.
+	Ingest *handlers.IngestOptions
+
+	// Hub receives log_created events for new logs. Nil disables
+	// broadcasting.
+	Hub *handlers.SSEHub
+
+	// Tenancy scopes calls to the tenant named in their x-tenant metadata.
+	Tenancy bool
+
+	// ReadOnly, when it reports true, makes calls fail with UNAVAILABLE,
+	// as writes do in the HTTP server's maintenance mode.
+	ReadOnly func() bool
+}
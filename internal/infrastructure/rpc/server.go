@@ -0,0 +1,211 @@
+//go:build grpc
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	ingestv1 "github.com/mx-scribe/scribe/api/ingest/v1"
+	"github.com/mx-scribe/scribe/internal/application/commands"
+	"github.com/mx-scribe/scribe/internal/domain/entities"
+	"github.com/mx-scribe/scribe/internal/infrastructure/http/handlers"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+const (
+	// maxBatchSize is the maximum number of logs of a CreateLogBatch call.
+	maxBatchSize = 1000
+
+	// maxBatchErrors caps how many failed logs a batch response reports.
+	maxBatchErrors = 100
+)
+
+// Service implements the IngestService of api/ingest/v1, storing logs in db
+// the way POST /api/logs does.
+type Service struct {
+	ingestv1.UnimplementedIngestServiceServer
+
+	db   *sqlite.Database
+	opts Options
+}
+
+// NewService returns the ingestion service for db.
+func NewService(db *sqlite.Database, opts Options) *Service {
+	return &Service{db: db, opts: opts}
+}
+
+// NewServer returns a gRPC server serving the ingestion service for db,
+// scoping calls to their tenant and rejecting them in read-only mode.
+func NewServer(db *sqlite.Database, opts Options) *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(opts.intercept))
+	ingestv1.RegisterIngestServiceServer(server, NewService(db, opts))
+	return server
+}
+
+// Start serves the ingestion service for db on addr in the background. The
+// returned stop function stops it gracefully.
+func Start(addr string, db *sqlite.Database, opts Options) (stop func(), err error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("grpc server error: %w", err)
+	}
+
+	server := NewServer(db, opts)
+	go func() { _ = server.Serve(listener) }()
+
+	return server.GracefulStop, nil
+}
+
+// intercept rejects calls while the server is read-only and scopes the
+// others to the tenant in their metadata when tenancy is enabled.
+func (opts Options) intercept(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if opts.ReadOnly != nil && opts.ReadOnly() {
+		return nil, status.Error(codes.Unavailable, "server is in read-only maintenance mode")
+	}
+
+	if opts.Tenancy {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get(tenantMetadata); len(values) > 0 && values[0] != "" {
+			if !sqlite.ValidTenant(values[0]) {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid %s metadata", tenantMetadata)
+			}
+			ctx = sqlite.ContextWithTenant(ctx, values[0])
+		}
+	}
+
+	return handler(ctx, req)
+}
+
+// CreateLog stores a single log.
+func (s *Service) CreateLog(ctx context.Context, req *ingestv1.CreateLogRequest) (*ingestv1.CreateLogResponse, error) {
+	handler := commands.NewCreateLogHandler(sqlite.NewLogRepository(s.db))
+
+	log, err := s.buildLog(handler, req.GetLog(), req.Analyze)
+	var ingestErr *handlers.IngestError
+	if errors.As(err, &ingestErr) && ingestErr.Dropped {
+		return &ingestv1.CreateLogResponse{Dropped: true}, nil
+	}
+	if err != nil {
+		return nil, ingestStatus(err)
+	}
+
+	if err := s.opts.Ingest.SaveLogs(ctx, s.db, s.opts.Hub, []*entities.Log{log}); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &ingestv1.CreateLogResponse{
+		Id:        log.ID,
+		Title:     log.Header.Title,
+		Severity:  log.EffectiveSeverity().String(),
+		CreatedAt: timestamppb.New(log.CreatedAt),
+	}, nil
+}
+
+// CreateLogBatch stores the valid logs of a batch in one transaction. Logs
+// turned away are counted as dropped or reported as failed by index.
+func (s *Service) CreateLogBatch(ctx context.Context, req *ingestv1.CreateLogBatchRequest) (*ingestv1.CreateLogBatchResponse, error) {
+	if len(req.GetLogs()) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch holds %d logs, at most %d allowed", len(req.GetLogs()), maxBatchSize)
+	}
+
+	handler := commands.NewCreateLogHandler(sqlite.NewLogRepository(s.db))
+	resp := &ingestv1.CreateLogBatchResponse{Received: int32(len(req.GetLogs()))}
+
+	batch := make([]*entities.Log, 0, len(req.GetLogs()))
+	for i, pbLog := range req.GetLogs() {
+		log, err := s.buildLog(handler, pbLog, req.Analyze)
+		var ingestErr *handlers.IngestError
+		switch {
+		case errors.As(err, &ingestErr) && ingestErr.Dropped:
+			resp.Dropped++
+		case err != nil:
+			resp.Failed++
+			if len(resp.Errors) < maxBatchErrors {
+				resp.Errors = append(resp.Errors, &ingestv1.BatchItemError{
+					Index:   int32(i),
+					Code:    string(errorCode(err)),
+					Message: err.Error(),
+				})
+			}
+		default:
+			batch = append(batch, log)
+		}
+	}
+
+	if err := s.opts.Ingest.SaveLogs(ctx, s.db, s.opts.Hub, batch); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp.Created = int32(len(batch))
+	for _, log := range batch {
+		resp.Ids = append(resp.Ids, log.ID)
+	}
+	return resp, nil
+}
+
+// buildLog builds a log from its protobuf form through the ingestion path
+// of POST /api/logs. A nil analyze falls back to the configured default.
+func (s *Service) buildLog(handler *commands.CreateLogHandler, pbLog *ingestv1.Log, analyze *bool) (*entities.Log, error) {
+	var req handlers.CreateLogRequest
+	req.Header.Title = pbLog.GetHeader().GetTitle()
+	req.Header.Severity = pbLog.GetHeader().GetSeverity()
+	req.Header.Source = pbLog.GetHeader().GetSource()
+	req.Header.Color = pbLog.GetHeader().GetColor()
+	req.Header.Description = pbLog.GetHeader().GetDescription()
+	req.Body = pbLog.GetBody().AsMap()
+	if pbLog.GetTimestamp() != nil {
+		timestamp := pbLog.GetTimestamp().AsTime()
+		req.Timestamp = &timestamp
+	}
+	req.ExpiresIn = pbLog.GetExpiresIn()
+
+	if req.Header.Title == "" {
+		return nil, errors.New("title is required")
+	}
+
+	input, err := req.Input()
+	if err != nil {
+		return nil, err
+	}
+
+	input.SkipAnalysis = !handlers.DefaultIngestOptions().AutoAnalyze
+	if s.opts.Ingest != nil {
+		input.SkipAnalysis = !s.opts.Ingest.AutoAnalyze
+	}
+	if analyze != nil {
+		input.SkipAnalysis = !*analyze
+	}
+
+	return s.opts.Ingest.BuildLog(handler, input)
+}
+
+// errorCode returns the API error code of a log that failed to build.
+func errorCode(err error) handlers.ErrorCode {
+	var ingestErr *handlers.IngestError
+	if errors.As(err, &ingestErr) {
+		return ingestErr.Code
+	}
+	return handlers.CodeInvalidRequest
+}
+
+// ingestStatus maps an error building a log to a gRPC status.
+func ingestStatus(err error) error {
+	switch errorCode(err) {
+	case handlers.CodeRateLimited:
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case handlers.CodeEventTooOld, handlers.CodeBelowMinimum:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case handlers.CodeSourceDenied:
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
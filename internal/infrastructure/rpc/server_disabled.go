@@ -0,0 +1,10 @@
+//go:build !grpc
+
+package rpc
+
+import "github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+
+// Start reports ErrGRPCUnsupported in builds without the grpc tag.
+func Start(_ string, _ *sqlite.Database, _ Options) (stop func(), err error) {
+	return nil, ErrGRPCUnsupported
+}
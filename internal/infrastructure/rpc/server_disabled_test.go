@@ -0,0 +1,15 @@
+//go:build !grpc
+
+package rpc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStart_RequiresBuildTag(t *testing.T) {
+	_, err := Start(":0", nil, Options{})
+	if !errors.Is(err, ErrGRPCUnsupported) {
+		t.Errorf("expected ErrGRPCUnsupported, got %v", err)
+	}
+}
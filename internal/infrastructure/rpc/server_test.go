@@ -0,0 +1,181 @@
+//go:build grpc
+
+package rpc
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	ingestv1 "github.com/mx-scribe/scribe/api/ingest/v1"
+	"github.com/mx-scribe/scribe/internal/infrastructure/http/handlers"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+	"github.com/mx-scribe/scribe/internal/testutil"
+)
+
+// dialService serves the ingestion service for db over an in-memory
+// connection and returns a client for it.
+func dialService(t *testing.T, db *sqlite.Database, opts Options) ingestv1.IngestServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(db, opts)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return ingestv1.NewIngestServiceClient(conn)
+}
+
+func TestService_CreateLog(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, db)
+
+	ingest := handlers.DefaultIngestOptions()
+	client := dialService(t, db, Options{Ingest: &ingest})
+
+	body, _ := structpb.NewStruct(map[string]any{"query": "SELECT * FROM orders", "attempt": 2})
+	resp, err := client.CreateLog(context.Background(), &ingestv1.CreateLogRequest{
+		Log: &ingestv1.Log{
+			Header: &ingestv1.LogHeader{Title: "Deadlock detected", Source: "orders"},
+			Body:   body,
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateLog failed: %v", err)
+	}
+	if resp.GetId() == 0 || resp.GetDropped() {
+		t.Fatalf("expected a stored log, got %+v", resp)
+	}
+
+	log, err := sqlite.NewLogRepository(db).FindByID(resp.GetId())
+	if err != nil {
+		t.Fatalf("failed to find log: %v", err)
+	}
+	if log.Header.Title != "Deadlock detected" || log.Header.Source != "orders" {
+		t.Errorf("expected the sent header, got %+v", log.Header)
+	}
+	if log.Body["query"] != "SELECT * FROM orders" {
+		t.Errorf("expected the sent body, got %v", log.Body)
+	}
+	if log.Metadata.DerivedCategory != "database" || log.Metadata.DerivedSeverity != "critical" {
+		t.Errorf("expected derived database/critical metadata, got %+v", log.Metadata)
+	}
+	if resp.GetSeverity() != "critical" {
+		t.Errorf("expected the effective severity critical in the response, got %q", resp.GetSeverity())
+	}
+
+	// Analysis can be skipped per call, as with ?analyze=false
+	analyze := false
+	resp, err = client.CreateLog(context.Background(), &ingestv1.CreateLogRequest{
+		Log:     &ingestv1.Log{Header: &ingestv1.LogHeader{Title: "Deadlock detected"}},
+		Analyze: &analyze,
+	})
+	if err != nil {
+		t.Fatalf("CreateLog failed: %v", err)
+	}
+	if log, _ := sqlite.NewLogRepository(db).FindByID(resp.GetId()); log == nil || log.Metadata.DerivedCategory != "" {
+		t.Errorf("expected no derived metadata with analyze=false, got %+v", log)
+	}
+
+	_, err = client.CreateLog(context.Background(), &ingestv1.CreateLogRequest{Log: &ingestv1.Log{}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument without a title, got %v", err)
+	}
+}
+
+func TestService_CreateLogBatch(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, db)
+
+	ingest := handlers.DefaultIngestOptions()
+	ingest.SeverityFloor = handlers.NewSeverityFloor("info", true)
+	hub := handlers.NewSSEHub()
+	client := dialService(t, db, Options{Ingest: &ingest, Hub: hub})
+
+	resp, err := client.CreateLogBatch(context.Background(), &ingestv1.CreateLogBatchRequest{
+		Logs: []*ingestv1.Log{
+			{Header: &ingestv1.LogHeader{Title: "Unauthorized access attempt", Source: "gateway"}},
+			{Header: &ingestv1.LogHeader{Title: ""}},
+			{Header: &ingestv1.LogHeader{Title: "Cache probe", Severity: "debug"}},
+			{Header: &ingestv1.LogHeader{Title: "Job finished", Severity: "info"}, ExpiresIn: "soon"},
+			{Header: &ingestv1.LogHeader{Title: "Job started", Severity: "info"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateLogBatch failed: %v", err)
+	}
+
+	if resp.GetReceived() != 5 || resp.GetCreated() != 2 || resp.GetDropped() != 1 || resp.GetFailed() != 2 {
+		t.Fatalf("expected 5 received, 2 created, 1 dropped and 2 failed, got %+v", resp)
+	}
+	if len(resp.GetErrors()) != 2 || resp.GetErrors()[0].GetIndex() != 1 || resp.GetErrors()[1].GetIndex() != 3 {
+		t.Errorf("expected errors for logs 1 and 3, got %v", resp.GetErrors())
+	}
+	if len(resp.GetIds()) != 2 {
+		t.Fatalf("expected 2 IDs, got %v", resp.GetIds())
+	}
+
+	repo := sqlite.NewLogRepository(db)
+	security, err := repo.FindByID(resp.GetIds()[0])
+	if err != nil {
+		t.Fatalf("failed to find log: %v", err)
+	}
+	if security.Metadata.DerivedCategory != "security" || security.EffectiveSeverity() != "critical" {
+		t.Errorf("expected derived security/critical metadata, got %+v", security.Metadata)
+	}
+	if count, _ := repo.Count(); count != 2 {
+		t.Errorf("expected 2 stored logs, got %d", count)
+	}
+}
+
+func TestService_TenancyAndReadOnly(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, db)
+
+	var readOnly atomic.Bool
+	client := dialService(t, db, Options{Tenancy: true, ReadOnly: readOnly.Load})
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), tenantMetadata, "acme")
+	resp, err := client.CreateLog(ctx, &ingestv1.CreateLogRequest{
+		Log: &ingestv1.Log{Header: &ingestv1.LogHeader{Title: "Tenant log"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateLog failed: %v", err)
+	}
+
+	repo := sqlite.NewLogRepository(db)
+	if _, err := repo.FindByID(resp.GetId()); err == nil {
+		t.Error("expected the log to be hidden from the default tenant")
+	}
+	if _, err := repo.FindByIDContext(sqlite.ContextWithTenant(context.Background(), "acme"), resp.GetId()); err != nil {
+		t.Errorf("expected the log in tenant acme, got %v", err)
+	}
+
+	readOnly.Store(true)
+	_, err = client.CreateLog(ctx, &ingestv1.CreateLogRequest{
+		Log: &ingestv1.Log{Header: &ingestv1.LogHeader{Title: "Rejected"}},
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expected Unavailable in read-only mode, got %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+package rules
+
+// CategorySeverityOverrides maps a category to patterns that fix the severity
+// of logs already classified into that category. An override takes precedence
+// over the category's own patterns and over keyword detection, but never
+// applies to logs of other categories. Security logs are not overridden.
+var CategorySeverityOverrides = map[string]map[string]string{
+	"database": {
+		"replica lag":     "warning",
+		"replication lag": "warning",
+	},
+}
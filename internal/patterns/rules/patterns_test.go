@@ -474,3 +474,26 @@ func TestBusinessPatterns(t *testing.T) {
 		}
 	}
 }
+
+func TestCategorySeverityOverrides_Valid(t *testing.T) {
+	validSeverities := map[string]bool{
+		"debug": true, "info": true, "success": true, "warning": true, "error": true, "critical": true,
+	}
+	validCategories := map[string]bool{
+		"http": true, "database": true, "performance": true, "business": true, "system": true, "general": true,
+	}
+
+	for category, overrides := range CategorySeverityOverrides {
+		if !validCategories[category] {
+			t.Errorf("override category %q is not a known non-security category", category)
+		}
+		for pattern, severity := range overrides {
+			if pattern != strings.ToLower(pattern) {
+				t.Errorf("override pattern %q should be lowercase", pattern)
+			}
+			if !validSeverities[severity] {
+				t.Errorf("override %q in %q has invalid severity %q", pattern, category, severity)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package scribe embeds the SCRIBE log server in another program.
+//
+// New opens a SCRIBE database and returns an http.Handler serving the same
+// /api routes as `scribe serve`, for a host program to mount on its own
+// server:
+//
+//	s, err := scribe.New("/var/lib/app/logs.db", nil)
+//	if err != nil {
+//		return err
+//	}
+//	defer s.Close()
+//	mux.Handle("/scribe/", http.StripPrefix("/scribe", s.Handler()))
+//
+// The dashboard is not served; the host owns listening, TLS and shutdown.
+package scribe
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mx-scribe/scribe/internal/infrastructure/cli"
+	"github.com/mx-scribe/scribe/internal/infrastructure/persistence/sqlite"
+)
+
+// Config is the SCRIBE configuration, the same settings as the config file
+// and SCRIBE_* environment variables of the CLI.
+type Config = cli.Config
+
+// DefaultConfig returns the default configuration.
+func DefaultConfig() *Config {
+	return cli.DefaultConfig()
+}
+
+// LoadConfig loads the configuration from a JSON file, then applies SCRIBE_*
+// environment variables. An empty path uses the CLI's default locations.
+func LoadConfig(path string) (*Config, error) {
+	return cli.LoadConfig(path)
+}
+
+// Scribe is an embedded SCRIBE server.
+type Scribe struct {
+	db      *sqlite.Database
+	handler http.Handler
+}
+
+// New opens, creating and migrating it if needed, the database at dbPath and
+// wires the repository, pattern matcher and routes configured by config. A
+// nil config uses DefaultConfig. New fails if the database schema was written
+// by a newer version of SCRIBE.
+func New(dbPath string, config *Config) (*Scribe, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	db, schema, err := cli.OpenDatabase(config, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := schema.Err(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	server, err := cli.NewServer(db, config)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Scribe{db: db, handler: server.Router()}, nil
+}
+
+// Handler returns the handler serving the SCRIBE routes, rooted at "/".
+// Mount it under a prefix with http.StripPrefix.
+func (s *Scribe) Handler() http.Handler {
+	return s.handler
+}
+
+// Close closes the database. The handler must not be used afterwards.
+func (s *Scribe) Close() error {
+	return s.db.Close()
+}
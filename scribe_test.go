@@ -0,0 +1,89 @@
+package scribe_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mx-scribe/scribe"
+)
+
+func TestNew_EmbeddedHandler(t *testing.T) {
+	s, err := scribe.New(filepath.Join(t.TempDir(), "data", "scribe.db"), nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer s.Close()
+
+	// Mount under a prefix on the host's own mux
+	mux := http.NewServeMux()
+	mux.Handle("/scribe/", http.StripPrefix("/scribe", s.Handler()))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "host")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/scribe/api/logs", "application/json",
+		strings.NewReader(`{"header":{"title":"Embedded log","severity":"warning","source":"host"}}`))
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/scribe/api/logs?source=host")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var list struct {
+		Logs []struct {
+			Header struct {
+				Title    string `json:"title"`
+				Severity string `json:"severity"`
+			} `json:"header"`
+		} `json:"logs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(list.Logs) != 1 || list.Logs[0].Header.Title != "Embedded log" || list.Logs[0].Header.Severity != "warning" {
+		t.Errorf("expected the created log, got %+v", list.Logs)
+	}
+}
+
+func TestNew_Config(t *testing.T) {
+	config := scribe.DefaultConfig()
+	config.Server.ReadOnly = true
+
+	s, err := scribe.New(filepath.Join(t.TempDir(), "scribe.db"), config)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer s.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(`{"header":{"title":"Rejected"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 in read-only mode, got %d", rec.Code)
+	}
+
+	config = scribe.DefaultConfig()
+	config.Metrics.Prefix = "not a prefix!"
+	if _, err := scribe.New(filepath.Join(t.TempDir(), "scribe.db"), config); err == nil {
+		t.Error("expected an invalid config to be rejected")
+	}
+}
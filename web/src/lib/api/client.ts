@@ -69,8 +69,10 @@ export class ScribeClient {
 			clearTimeout(timeoutId);
 
 			if (!response.ok) {
-				const error: ApiError = await response.json().catch(() => ({ error: 'Unknown error' }));
-				throw new ScribeApiError(response.status, error.error);
+				const error: ApiError = await response
+					.json()
+					.catch(() => ({ error: { code: 'unknown', message: 'Unknown error' } }));
+				throw new ScribeApiError(response.status, error.error?.message ?? 'Unknown error');
 			}
 
 			return response.json();
@@ -23,6 +23,11 @@ export interface SSELogCreatedData {
 	created_at: string;
 }
 
+export interface SSELogsCreatedBatchData {
+	count: number;
+	logs: SSELogCreatedData[];
+}
+
 export interface SSELogDeletedData {
 	id: number;
 }
@@ -97,6 +102,17 @@ export class SSEClient {
 			}
 		});
 
+		// Sent instead of log_created when the server is under a burst of writes
+		this.eventSource.addEventListener('logs_created_batch', (event: MessageEvent) => {
+			try {
+				const parsed = JSON.parse(event.data) as SSEEvent;
+				const batch = parsed.data as SSELogsCreatedBatchData;
+				batch.logs.forEach((log) => this.handlers.onLogCreated?.(log));
+			} catch {
+				// Ignore parse errors
+			}
+		});
+
 		this.eventSource.addEventListener('log_deleted', (event: MessageEvent) => {
 			try {
 				const parsed = JSON.parse(event.data) as SSEEvent;
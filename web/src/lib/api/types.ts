@@ -80,5 +80,10 @@ export interface HealthResponse {
 
 // API Error
 export interface ApiError {
-	error: string;
+	error: {
+		code: string;
+		message: string;
+		details?: unknown;
+		request_id?: string;
+	};
 }